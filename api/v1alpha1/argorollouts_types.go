@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // RolloutManagerSpec defines the desired state of Argo Rollouts
@@ -30,8 +31,24 @@ type RolloutManagerSpec struct {
 	// Extra Command arguments that would append to the Rollouts
 	// ExtraCommandArgs will not be added, if one of these commands is already part of the Rollouts command
 	// with same or different value.
+	// The arguments added by the operator (such as '--namespaced') always come first, followed by ExtraCommandArgs in the order they are specified.
 	ExtraCommandArgs []string `json:"extraCommandArgs,omitempty"`
 
+	// ArgsOverrideMode lets you specify how ExtraCommandArgs are combined with the arguments added by the operator:
+	// 'append' (the default) appends ExtraCommandArgs to the arguments added by the operator, while 'replace' uses ExtraCommandArgs as the only arguments.
+	// +kubebuilder:validation:Enum=append;replace
+	ArgsOverrideMode ArgsOverrideMode `json:"argsOverrideMode,omitempty"`
+
+	// ScopeArgsOverride lets you replace the arguments which restrict the Rollouts controller of a namespace-scoped RolloutManager to its namespace ('--namespaced', by default), for forks or versions of the Rollouts controller whose flag differs (for example, '--namespace', 'my-namespace').
+	// Like the default, they are the first arguments of the Rollouts controller. They are not used by cluster-scoped RolloutManagers, nor if ArgsOverrideMode is 'replace'.
+	ScopeArgsOverride []string `json:"scopeArgsOverride,omitempty"`
+
+	// Command overrides the entrypoint of the Rollouts controller container (optional). If not specified, the entrypoint of the container image is used.
+	Command []string `json:"command,omitempty"`
+
+	// FileMounts lets you specify Secrets and ConfigMaps, in the namespace of the RolloutManager, that should be mounted as files into the Rollouts controller container: for example, notification templates or provider certificates that must be file-based rather than environment-based.
+	FileMounts []RolloutManagerFileMount `json:"fileMounts,omitempty"`
+
 	// Image defines Argo Rollouts controller image (optional)
 	Image string `json:"image,omitempty"`
 
@@ -41,10 +58,13 @@ type RolloutManagerSpec struct {
 	// Version defines Argo Rollouts controller tag (optional)
 	Version string `json:"version,omitempty"`
 
+	// VerifyImage lets you specify if the operator should verify that the Rollouts controller image exists in its registry (using the image pull secrets of the Rollouts controller ServiceAccount, and the proxy of the operator) before it updates the Rollouts controller Deployment to it. If the registry reports that the image does not exist, the RolloutManager fails with reason ImageNotFound, rather than the Rollouts controller pod failing to pull the image.
+	VerifyImage bool `json:"verifyImage,omitempty"`
+
 	// NamespaceScoped lets you specify if RolloutManager has to watch a namespace or the whole cluster
 	NamespaceScoped bool `json:"namespaceScoped,omitempty"`
 
-	// Metadata to apply to the generated resources
+	// Metadata to apply to the generated resources. Values may contain the template variables {{ .Name }} and {{ .Namespace }} (of the RolloutManager) and {{ .Version }} (the tag of the Rollouts controller image).
 	AdditionalMetadata *ResourceMetadata `json:"additionalMetadata,omitempty"`
 
 	// Resources requests/limits for Argo Rollout controller
@@ -52,6 +72,498 @@ type RolloutManagerSpec struct {
 
 	// SkipNotificationSecretDeployment lets you specify if the argo notification secret should be deployed
 	SkipNotificationSecretDeployment bool `json:"skipNotificationSecretDeployment,omitempty"`
+
+	// SkipRecommendedLabels lets you specify that the 'app.kubernetes.io/managed-by' and 'app.kubernetes.io/version' labels should not be set on the resources of the RolloutManager, for environments with conflicting labelling conventions
+	SkipRecommendedLabels bool `json:"skipRecommendedLabels,omitempty"`
+
+	// Secrets lets you configure the type and immutability of the Secrets created by the operator (the notification Secret and the backup Secret).
+	Secrets *RolloutManagerSecretsSpec `json:"secrets,omitempty"`
+
+	// Backup lets you configure periodic backups of the user-authored Rollouts configuration (the notification ConfigMap/Secret and the argo-rollouts-config ConfigMap)
+	Backup *RolloutManagerBackupSpec `json:"backup,omitempty"`
+
+	// ServiceMesh lets you specify if the Rollouts controller pods should be injected with a service mesh sidecar
+	ServiceMesh *RolloutManagerServiceMeshSpec `json:"serviceMesh,omitempty"`
+
+	// InjectedFields lets you specify fields of the Rollouts controller Deployment that are modified by admission webhooks, and should not be reverted by the operator.
+	// Well-known containers and volumes injected by Istio, Linkerd and the Vault agent are always ignored.
+	InjectedFields *RolloutManagerInjectedFieldsSpec `json:"injectedFields,omitempty"`
+
+	// DisruptionAlerts lets you configure alerting when a Rollouts controller pod is deleted or evicted outside of an update of the Rollouts controller Deployment (for example, on node drains), so that these can be distinguished from the actions of the operator.
+	DisruptionAlerts *RolloutManagerDisruptionAlertsSpec `json:"disruptionAlerts,omitempty"`
+
+	// CloudIdentity lets you specify the cloud workload identity that the Rollouts controller uses to access cloud provider APIs (for example, for AWS ALB traffic routing, or the CloudWatch, Stackdriver or Azure Monitor analysis providers), so that the operator can verify that it is configured on the Rollouts controller ServiceAccount.
+	CloudIdentity *RolloutManagerCloudIdentitySpec `json:"cloudIdentity,omitempty"`
+
+	// MetricsService lets you configure the Service which exposes the metrics of the Rollouts controller.
+	MetricsService *RolloutManagerServiceSpec `json:"metricsService,omitempty"`
+
+	// Monitoring lets you configure how the metrics of the Rollouts controller are scraped, via the metrics Service and the ServiceMonitor.
+	Monitoring *RolloutManagerMonitoringSpec `json:"monitoring,omitempty"`
+
+	// VPA lets you specify if a VerticalPodAutoscaler should be created for the Rollouts controller Deployment.
+	// While the VerticalPodAutoscaler manages the resource requests/limits of the Rollouts controller, they are not reverted by the operator.
+	VPA *RolloutManagerVPASpec `json:"vpa,omitempty"`
+
+	// RolloutUserRole lets you specify if a Role, which grants the permissions required to view and promote Rollouts, should be created in each namespace watched by the Rollouts controller.
+	RolloutUserRole *RolloutManagerRolloutUserRoleSpec `json:"rolloutUserRole,omitempty"`
+
+	// HostNetwork lets you specify if the Rollouts controller pod should use the network of its node (for example, on edge or bare-metal clusters where controllers run on host networking). The DNS policy of the pod is set to ClusterFirstWithHostNet, so that cluster Services can still be resolved.
+	// Host networking is not allowed by the 'baseline' and 'restricted' Pod Security Standards.
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+
+	// RunOnControlPlane lets you specify if the Rollouts controller pod should be scheduled onto the control-plane nodes (for example, on small dedicated management clusters): the tolerations of the control-plane taints are added to those of .spec.nodePlacement, and the pod is required to run on a node with the 'node-role.kubernetes.io/control-plane' (or legacy 'node-role.kubernetes.io/master') label.
+	RunOnControlPlane bool `json:"runOnControlPlane,omitempty"`
+
+	// HA lets you run multiple replicas of the Rollouts controller, for high availability: one of them is elected as the leader, and the others take over if it fails.
+	HA *RolloutManagerHASpec `json:"ha,omitempty"`
+
+	// Dashboard lets you deploy the Argo Rollouts dashboard alongside the Rollouts controller, and optionally expose it via an Ingress.
+	Dashboard *RolloutManagerDashboardSpec `json:"dashboard,omitempty"`
+
+	// Ports lets you change the ports on which the Rollouts controller serves its health checks and metrics (for example, to avoid conflicts with other processes on the node, when HostNetwork is enabled).
+	Ports *RolloutManagerPortsSpec `json:"ports,omitempty"`
+
+	// ContainerName lets you override the name of the Rollouts controller container (for example, to match admission policies or log pipelines that select containers by name). Defaults to 'argo-rollouts'.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	ContainerName string `json:"containerName,omitempty"`
+
+	// Lifecycle lets you specify postStart and preStop hooks of the Rollouts controller container (for example, to register and deregister the Rollouts controller with an external system). A PreStopCommand specified in DisruptionAlerts takes precedence over the preStop hook.
+	Lifecycle *corev1.Lifecycle `json:"lifecycle,omitempty"`
+
+	// StartupProbe lets you configure the startup probe of the Rollouts controller container, which holds off its liveness probe until the Rollouts controller is healthy, so that a Rollouts controller which takes minutes to sync a large number of Rollouts on a cold start is not restarted. By default, the Rollouts controller is given 5 minutes to start.
+	StartupProbe *RolloutManagerStartupProbeSpec `json:"startupProbe,omitempty"`
+
+	// ExtraPorts lets you declare additional ports of the Rollouts controller container (for example, for endpoints exposed by plugins, or debug ports), and optionally expose them via a Service.
+	ExtraPorts []RolloutManagerExtraPort `json:"extraPorts,omitempty"`
+
+	// RestartBudget lets you limit how often the Rollouts controller is restarted by updates of its Deployment, so that frequent changes of the RolloutManager do not thrash the Rollouts controller. Updates which are deferred are recorded as Events on the RolloutManager.
+	RestartBudget *RolloutManagerRestartBudgetSpec `json:"restartBudget,omitempty"`
+
+	// Debug lets you temporarily enable the profiling endpoints of the Rollouts controller, for short-lived debugging in production.
+	Debug *RolloutManagerDebugSpec `json:"debug,omitempty"`
+
+	// SkipResources lets you specify kinds of resources which the operator should not manage for the RolloutManager, because they are managed elsewhere (for example, via GitOps).
+	// A skipped resource which was created by the operator for the RolloutManager is deleted, while a resource which the operator did not create is left untouched.
+	SkipResources []RolloutManagerSkippableResource `json:"skipResources,omitempty"`
+
+	// FlowControl lets you assign the requests of the Rollouts controller to the API server to a dedicated priority level of API Priority and Fairness, for large clusters where the Rollouts controller is throttled.
+	FlowControl *RolloutManagerFlowControlSpec `json:"flowControl,omitempty"`
+
+	// KubeClient lets you tune the client-side rate limit of the requests of the Rollouts controller to the API server, for large clusters.
+	KubeClient *RolloutManagerKubeClientSpec `json:"kubeClient,omitempty"`
+
+	// ControllerTuning lets you tune the number of worker threads and the resync period of the Rollouts controller, for clusters with many Rollouts. The rate limit of its requests to the API server is configured via KubeClient.
+	ControllerTuning *RolloutManagerControllerTuningSpec `json:"controllerTuning,omitempty"`
+
+	// ClusterProxy lets you configure how the cluster-wide proxy of OpenShift (the 'cluster' Proxy of config.openshift.io) is applied to the Rollouts controller.
+	// By default, on OpenShift, its HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables and its trusted CA bundle are injected into the Rollouts controller. Environment variables specified in .spec.env take precedence.
+	ClusterProxy *RolloutManagerClusterProxySpec `json:"clusterProxy,omitempty"`
+
+	// TrafficRouting lets you specify the traffic routing providers used by the Rollouts, so that the Rollouts controller is granted the read permissions required by their status checks (for example, the verification of the weights of an ALB Ingress, or the status of an Istio VirtualService), which are not granted by default.
+	TrafficRouting *RolloutManagerTrafficRoutingSpec `json:"trafficRouting,omitempty"`
+
+	// TargetNamespace lets you run the Rollouts controller of a cluster-scoped RolloutManager in a dedicated namespace, which is created and managed by the operator (along with its labels and resource quota), rather than in the namespace of the RolloutManager.
+	// The operator creates a RolloutManager with the same spec in that namespace, which deploys the Rollouts controller there: its phase and condition are reported on this RolloutManager. The namespace must be listed in the CLUSTER_SCOPED_ARGO_ROLLOUTS_NAMESPACES environment variable of the operator.
+	// The namespace, and everything in it, is deleted when this RolloutManager is deleted, or no longer targets it.
+	TargetNamespace *RolloutManagerTargetNamespaceSpec `json:"targetNamespace,omitempty"`
+}
+
+// RolloutManagerTargetNamespaceSpec is used to configure the dedicated namespace of the Rollouts controller
+type RolloutManagerTargetNamespaceSpec struct {
+	// Name is the name of the namespace. A namespace which already exists, and was not created by the operator for this RolloutManager, is left untouched: the RolloutManager fails with reason InvalidTargetNamespace instead.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Labels to set on the namespace (for example, the Pod Security Standard enforced on it). Labels which are removed from this field are removed from the namespace.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Quota lets you limit the resources of the namespace, via a ResourceQuota with these hard limits (for example, 'requests.cpu' or 'pods').
+	Quota corev1.ResourceList `json:"quota,omitempty"`
+}
+
+// RolloutManagerTrafficRoutingSpec is used to grant the Rollouts controller the read permissions required by the status checks of traffic routing providers
+type RolloutManagerTrafficRoutingSpec struct {
+	// ALB grants read access to Ingresses and their status, Endpoints and TargetGroupBindings, as required to verify the weights of the AWS Load Balancer Controller
+	ALB bool `json:"alb,omitempty"`
+	// Nginx grants read access to Ingresses and their status
+	Nginx bool `json:"nginx,omitempty"`
+	// Istio grants read access to VirtualServices, DestinationRules and their status
+	Istio bool `json:"istio,omitempty"`
+	// SMI grants read access to TrafficSplits
+	SMI bool `json:"smi,omitempty"`
+	// AppMesh grants read access to the VirtualServices, VirtualRouters and VirtualNodes of AWS App Mesh, and their status
+	AppMesh bool `json:"appMesh,omitempty"`
+	// Traefik grants read access to TraefikServices
+	Traefik bool `json:"traefik,omitempty"`
+	// APISIX grants read access to ApisixRoutes and their status
+	APISIX bool `json:"apisix,omitempty"`
+}
+
+// RolloutManagerStartupProbeSpec is used to configure the startup probe of the Rollouts controller container
+type RolloutManagerStartupProbeSpec struct {
+	// PeriodSeconds is how often (in seconds) the startup probe is performed. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=300
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// FailureThreshold is the number of consecutive failures of the startup probe after which the Rollouts controller container is restarted, so that the Rollouts controller is given PeriodSeconds * FailureThreshold seconds to start. Defaults to 30.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// RolloutManagerClusterProxySpec is used to configure how the cluster-wide proxy of OpenShift is applied to the Rollouts controller
+type RolloutManagerClusterProxySpec struct {
+	// Disabled lets you specify that the cluster-wide proxy should not be injected into the Rollouts controller
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// RolloutManagerKubeClientSpec is used to configure the client-side rate limit of the Rollouts controller
+type RolloutManagerKubeClientSpec struct {
+	// QPS is the maximum number of queries per second of the Rollouts controller to the API server. Defaults to the default of the Rollouts controller (40).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	QPS int32 `json:"qps,omitempty"`
+	// Burst is the maximum burst of queries of the Rollouts controller to the API server, which must not be lower than QPS. Defaults to the default of the Rollouts controller (80).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20000
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// RolloutManagerControllerTuningSpec is used to configure the worker threads and the resync period of the Rollouts controller. Unset values default to the defaults of the Rollouts controller.
+type RolloutManagerControllerTuningSpec struct {
+	// RolloutThreads is the number of worker threads of the Rollout controller. Defaults to the default of the Rollouts controller (10).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	RolloutThreads int32 `json:"rolloutThreads,omitempty"`
+	// ExperimentThreads is the number of worker threads of the Experiment controller. Defaults to the default of the Rollouts controller (10).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	ExperimentThreads int32 `json:"experimentThreads,omitempty"`
+	// AnalysisThreads is the number of worker threads of the AnalysisRun controller. Defaults to the default of the Rollouts controller (30).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	AnalysisThreads int32 `json:"analysisThreads,omitempty"`
+	// ServiceThreads is the number of worker threads of the Service controller. Defaults to the default of the Rollouts controller (10).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	ServiceThreads int32 `json:"serviceThreads,omitempty"`
+	// IngressThreads is the number of worker threads of the Ingress controller. Defaults to the default of the Rollouts controller (10).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	IngressThreads int32 `json:"ingressThreads,omitempty"`
+	// EphemeralMetadataThreads is the number of worker threads which update the ephemeral metadata of the pods. Requires Argo Rollouts v1.7.0 or later. Defaults to the default of the Rollouts controller (10).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	EphemeralMetadataThreads int32 `json:"ephemeralMetadataThreads,omitempty"`
+	// RolloutResyncSeconds is the period (in seconds) after which all the Rollouts are reconciled again, even if they did not change. Defaults to the default of the Rollouts controller (900).
+	// +kubebuilder:validation:Minimum=1
+	RolloutResyncSeconds int32 `json:"rolloutResyncSeconds,omitempty"`
+}
+
+// RolloutManagerFlowControlSpec is used to create a FlowSchema which matches the requests of the ServiceAccount of the Rollouts controller
+type RolloutManagerFlowControlSpec struct {
+	// PriorityLevel is the name of the PriorityLevelConfiguration that the requests of the Rollouts controller are assigned to (for example "workload-high", or a PriorityLevelConfiguration created by the cluster administrator).
+	// +kubebuilder:validation:MinLength=1
+	PriorityLevel string `json:"priorityLevel"`
+	// MatchingPrecedence is the matching precedence of the FlowSchema: a lower value takes precedence over the other FlowSchemas which match the requests. Defaults to 1000, which takes precedence over the default 'service-accounts' FlowSchema.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	MatchingPrecedence int32 `json:"matchingPrecedence,omitempty"`
+}
+
+// RolloutManagerSkippableResource is a kind of resource, managed by the operator for a RolloutManager, which can be skipped via .spec.skipResources
+// +kubebuilder:validation:Enum=MetricsService;ServiceMonitor;AggregateClusterRoles
+type RolloutManagerSkippableResource string
+
+const (
+	// SkippableResourceMetricsService is the argo-rollouts-metrics Service, which exposes the metrics of the Rollouts controller
+	SkippableResourceMetricsService RolloutManagerSkippableResource = "MetricsService"
+	// SkippableResourceServiceMonitor is the ServiceMonitor which scrapes the metrics of the Rollouts controller, when the Prometheus operator is installed
+	SkippableResourceServiceMonitor RolloutManagerSkippableResource = "ServiceMonitor"
+	// SkippableResourceAggregateClusterRoles are the ClusterRoles which aggregate the permissions on Rollouts resources to the admin, edit and view ClusterRoles
+	SkippableResourceAggregateClusterRoles RolloutManagerSkippableResource = "AggregateClusterRoles"
+)
+
+// RolloutManagerDebugSpec is used to enable the profiling endpoints of the Rollouts controller
+type RolloutManagerDebugSpec struct {
+	// Pprof lets you specify if the Rollouts controller should serve its pprof profiling endpoints. They are exposed via the argo-rollouts-extra-ports Service, and are disabled again once the TTL has elapsed.
+	Pprof bool `json:"pprof,omitempty"`
+	// Port is the port of the profiling endpoints of the Rollouts controller. Defaults to 6060.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// TTL is the time for which the profiling endpoints are enabled, expressed as a duration string (for example "30m"). Defaults to "1h". To enable them again once they expired, set Pprof to false, and then back to true.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// RolloutManagerRestartBudgetSpec is used to limit the rate of updates of the Rollouts controller Deployment which restart the Rollouts controller (that is, updates of its pod template)
+type RolloutManagerRestartBudgetSpec struct {
+	// CoalesceWindow is the time for which an update that restarts the Rollouts controller is deferred after it is first detected, expressed as a duration string (for example "30s" or "5m"), so that further changes made within the window are applied by the same restart.
+	CoalesceWindow string `json:"coalesceWindow,omitempty"`
+	// MinInterval is the minimum interval between two restarts of the Rollouts controller by the operator, expressed as a duration string (for example "10m"). Updates made within the interval are deferred until it has elapsed, and then applied together.
+	MinInterval string `json:"minInterval,omitempty"`
+}
+
+// RolloutManagerPortsSpec is used to configure the ports of the Rollouts controller container
+type RolloutManagerPortsSpec struct {
+	// Healthz is the port of the health check endpoint of the Rollouts controller. Defaults to 8080.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Healthz int32 `json:"healthz,omitempty"`
+	// Metrics is the port of the metrics endpoint of the Rollouts controller. Defaults to 8090.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Metrics int32 `json:"metrics,omitempty"`
+}
+
+// RolloutManagerExtraPort is an additional port of the Rollouts controller container
+type RolloutManagerExtraPort struct {
+	// Name is the name of the port. It must be an IANA service name (at most 15 lowercase alphanumeric characters or '-'), unique among the ExtraPorts, and must not be 'healthz' or 'metrics'.
+	Name string `json:"name"`
+	// ContainerPort is the port on which the Rollouts controller container listens.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ContainerPort int32 `json:"containerPort"`
+	// Protocol is the protocol of the port. Defaults to TCP.
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+	// Expose lets you specify a Service which exposes the port: 'MetricsService' adds it to the argo-rollouts-metrics Service, while 'Service' adds it to the argo-rollouts-extra-ports Service, which is only created for such ports. If not specified, the port is not exposed.
+	// +kubebuilder:validation:Enum=MetricsService;Service
+	Expose RolloutManagerExtraPortExpose `json:"expose,omitempty"`
+}
+
+// RolloutManagerExtraPortExpose is the Service which exposes an extra port of the Rollouts controller container
+type RolloutManagerExtraPortExpose string
+
+const (
+	// ExtraPortExposeMetricsService exposes the port via the argo-rollouts-metrics Service
+	ExtraPortExposeMetricsService RolloutManagerExtraPortExpose = "MetricsService"
+	// ExtraPortExposeService exposes the port via the argo-rollouts-extra-ports Service
+	ExtraPortExposeService RolloutManagerExtraPortExpose = "Service"
+)
+
+// RolloutManagerFileMount is used to mount a Secret or ConfigMap as files into the Rollouts controller container. Exactly one of Secret or ConfigMap must be specified.
+type RolloutManagerFileMount struct {
+	// Name is the name of the volume of the Rollouts controller Deployment. It must be a DNS label, and unique among the FileMounts.
+	Name string `json:"name"`
+	// MountPath is the absolute path in the Rollouts controller container at which the files are mounted. The files are mounted read-only.
+	MountPath string `json:"mountPath"`
+	// Secret is the name of the Secret to mount
+	Secret string `json:"secret,omitempty"`
+	// ConfigMap is the name of the ConfigMap to mount
+	ConfigMap string `json:"configMap,omitempty"`
+	// Items lets you specify the keys that are mounted, and their paths relative to MountPath (optional). If not specified, each key is mounted as a file named after the key.
+	Items []corev1.KeyToPath `json:"items,omitempty"`
+}
+
+// RolloutManagerRolloutUserRoleSpec is used to configure the Role that can be bound to the users of Rollouts (for example, application teams)
+type RolloutManagerRolloutUserRoleSpec struct {
+	// Enabled lets you specify if the Role should be created in each namespace watched by the Rollouts controller
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// RolloutManagerDisruptionAlertsSpec is used to configure alerting when a Rollouts controller pod is deleted or evicted outside of an update of the Rollouts controller Deployment
+type RolloutManagerDisruptionAlertsSpec struct {
+	// Enabled lets you specify if a Warning Event should be recorded on the RolloutManager whenever a Rollouts controller pod is deleted or evicted outside of an update of the Rollouts controller Deployment
+	Enabled bool `json:"enabled,omitempty"`
+	// PreStopCommand, if specified, is registered as a preStop hook of the Rollouts controller container, and is run whenever a Rollouts controller pod is terminated (for example, to notify an external alerting system). The command must be available in the Rollouts controller image.
+	PreStopCommand []string `json:"preStopCommand,omitempty"`
+}
+
+// CloudIdentityProvider is the cloud provider of a workload identity
+type CloudIdentityProvider string
+
+const (
+	// CloudIdentityProviderAWS is IAM Roles for Service Accounts (IRSA) on Amazon EKS
+	CloudIdentityProviderAWS CloudIdentityProvider = "AWS"
+	// CloudIdentityProviderGCP is Workload Identity on Google Kubernetes Engine
+	CloudIdentityProviderGCP CloudIdentityProvider = "GCP"
+	// CloudIdentityProviderAzure is Microsoft Entra Workload ID on Azure Kubernetes Service
+	CloudIdentityProviderAzure CloudIdentityProvider = "Azure"
+)
+
+// RolloutManagerCloudIdentitySpec is used to specify the cloud workload identity of the Rollouts controller
+type RolloutManagerCloudIdentitySpec struct {
+	// Provider is the cloud provider of the workload identity: AWS (IAM Roles for Service Accounts), GCP (GKE Workload Identity) or Azure (Microsoft Entra Workload ID).
+	// +kubebuilder:validation:Enum=AWS;GCP;Azure
+	Provider CloudIdentityProvider `json:"provider"`
+}
+
+// RolloutManagerServiceSpec is used to configure a Service created by the operator
+type RolloutManagerServiceSpec struct {
+	// IPFamilyPolicy is the IP family policy of the Service: one of SingleStack, PreferDualStack or RequireDualStack. If not specified, it is defaulted by the API server (to SingleStack, unless IPFamilies specifies two families).
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	IPFamilyPolicy *corev1.IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
+	// IPFamilies are the IP families (IPv4 or IPv6) of the Service, in order: the first one is the primary IP family of the Service. If not specified, they are defaulted by the API server from the configuration of the cluster and IPFamilyPolicy.
+	// Changing the primary IP family recreates the Service.
+	// +kubebuilder:validation:MaxItems=2
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+	// Type is the type of the Service: one of ClusterIP, LoadBalancer or Headless (a ClusterIP Service without a cluster IP, whose DNS name resolves to the IPs of the pods, for example for scraping via a service mesh). Defaults to ClusterIP.
+	// Switching to or from Headless recreates the Service, as the cluster IP of a Service cannot be changed.
+	// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer;Headless
+	Type RolloutManagerServiceType `json:"type,omitempty"`
+	// Annotations are added to the Service, for example the annotations which request an internal load balancer from the cloud provider, for a Service of type LoadBalancer.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// RolloutManagerMonitoringSpec is used to configure how the metrics of the Rollouts controller are scraped
+type RolloutManagerMonitoringSpec struct {
+	// PortName is the name of the metrics port of the metrics Service, which is also the port scraped by the ServiceMonitor, for Prometheus setups which select the ports to scrape by name (for example, 'http-metrics'). It must be an IANA service name (at most 15 lowercase alphanumeric characters or '-'), and must not be the name of an extra port exposed via the metrics Service. Defaults to 'metrics'.
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	PortName string `json:"portName,omitempty"`
+}
+
+// RolloutManagerServiceType is the type of a Service created by the operator
+type RolloutManagerServiceType string
+
+const (
+	// RolloutManagerServiceTypeClusterIP is a Service with a cluster IP, which is only reachable from within the cluster
+	RolloutManagerServiceTypeClusterIP RolloutManagerServiceType = "ClusterIP"
+	// RolloutManagerServiceTypeLoadBalancer is a Service which is exposed via a load balancer of the cloud provider
+	RolloutManagerServiceTypeLoadBalancer RolloutManagerServiceType = "LoadBalancer"
+	// RolloutManagerServiceTypeHeadless is a ClusterIP Service without a cluster IP
+	RolloutManagerServiceTypeHeadless RolloutManagerServiceType = "Headless"
+)
+
+// RolloutManagerHASpec is used to configure the high availability of the Rollouts controller
+type RolloutManagerHASpec struct {
+	// Enabled lets you specify if multiple replicas of the Rollouts controller should be run, with leader election, and spread across nodes
+	Enabled bool `json:"enabled,omitempty"`
+	// Replicas is the number of replicas of the Rollouts controller, when Enabled is true. Defaults to 2.
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=10
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+// RolloutManagerDashboardSpec is used to configure the Argo Rollouts dashboard
+type RolloutManagerDashboardSpec struct {
+	// Enabled lets you specify if the Argo Rollouts dashboard should be deployed
+	Enabled bool `json:"enabled,omitempty"`
+	// Image is the image of the dashboard. Defaults to 'quay.io/argoproj/kubectl-argo-rollouts'.
+	Image string `json:"image,omitempty"`
+	// Version is the tag of the dashboard image. Defaults to the version of the Rollouts controller.
+	Version string `json:"version,omitempty"`
+	// Resources are the resource requests/limits of the dashboard container
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Ingress lets you expose the dashboard outside of the cluster via an Ingress
+	Ingress *RolloutManagerDashboardIngressSpec `json:"ingress,omitempty"`
+}
+
+// RolloutManagerDashboardIngressSpec is used to configure the Ingress of the Argo Rollouts dashboard
+type RolloutManagerDashboardIngressSpec struct {
+	// Enabled lets you specify if an Ingress should be created for the dashboard
+	Enabled bool `json:"enabled,omitempty"`
+	// Host is the host name on which the dashboard is served. If it is not specified, the dashboard is served on all hosts of the Ingress controller.
+	Host string `json:"host,omitempty"`
+	// IngressClassName is the name of the IngressClass of the Ingress. If it is not specified, the default IngressClass of the cluster is used.
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Annotations are added to the Ingress (for example, to configure the Ingress controller)
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// TLS lets you serve the dashboard over HTTPS, with a certificate from a Secret, optionally issued by cert-manager
+	TLS *RolloutManagerDashboardIngressTLSSpec `json:"tls,omitempty"`
+}
+
+// RolloutManagerDashboardIngressTLSSpec is used to configure the TLS of the Ingress of the Argo Rollouts dashboard
+type RolloutManagerDashboardIngressTLSSpec struct {
+	// Enabled lets you specify if the Ingress should terminate TLS for the dashboard
+	Enabled bool `json:"enabled,omitempty"`
+	// SecretName is the name of the Secret, in the namespace of the RolloutManager, that contains the TLS certificate and key. Defaults to 'argo-rollouts-dashboard-tls'.
+	SecretName string `json:"secretName,omitempty"`
+	// CertManager lets you request the TLS certificate from cert-manager: a cert-manager Certificate is created for the host of the Ingress, which writes the certificate to the Secret. The Ingress is only created once the Certificate is ready.
+	CertManager *RolloutManagerDashboardCertManagerSpec `json:"certManager,omitempty"`
+}
+
+// RolloutManagerDashboardCertManagerSpec is used to configure the cert-manager Certificate of the Argo Rollouts dashboard
+type RolloutManagerDashboardCertManagerSpec struct {
+	// IssuerRef references the cert-manager issuer of the certificate
+	IssuerRef RolloutManagerCertManagerIssuerRef `json:"issuerRef"`
+}
+
+// RolloutManagerCertManagerIssuerRef references a cert-manager Issuer, ClusterIssuer or external issuer
+type RolloutManagerCertManagerIssuerRef struct {
+	// Name is the name of the issuer
+	Name string `json:"name"`
+	// Kind is the kind of the issuer. Defaults to 'Issuer', in the namespace of the RolloutManager.
+	Kind string `json:"kind,omitempty"`
+	// Group is the API group of the issuer. Defaults to 'cert-manager.io'.
+	Group string `json:"group,omitempty"`
+}
+
+// RolloutManagerVPASpec is used to configure the VerticalPodAutoscaler of the Rollouts controller
+type RolloutManagerVPASpec struct {
+	// Enabled lets you specify if a VerticalPodAutoscaler should be created for the Rollouts controller
+	Enabled bool `json:"enabled,omitempty"`
+	// Mode is the update mode of the VerticalPodAutoscaler: one of Off, Initial, Recreate or Auto. Defaults to Auto.
+	// +kubebuilder:validation:Enum=Off;Initial;Recreate;Auto
+	Mode string `json:"mode,omitempty"`
+	// ReportRecommendation lets you specify if the recommendation of a VerticalPodAutoscaler targeting the Rollouts controller Deployment (whether or not it is created by the operator) should be reported in .status.controllerResourcesRecommendation, as a sizing hint for ControllerResources. This does not require Enabled.
+	ReportRecommendation bool `json:"reportRecommendation,omitempty"`
+}
+
+// RolloutManagerResourcesRecommendation is the resource recommendation of a VerticalPodAutoscaler for the Rollouts controller container
+type RolloutManagerResourcesRecommendation struct {
+	// VerticalPodAutoscaler is the name of the VerticalPodAutoscaler which computed the recommendation
+	VerticalPodAutoscaler string `json:"verticalPodAutoscaler"`
+	// Target is the recommended resource requests of the Rollouts controller container
+	Target corev1.ResourceList `json:"target,omitempty"`
+	// LowerBound is the minimum recommended resource requests of the Rollouts controller container
+	LowerBound corev1.ResourceList `json:"lowerBound,omitempty"`
+	// UpperBound is the maximum recommended resource requests of the Rollouts controller container
+	UpperBound corev1.ResourceList `json:"upperBound,omitempty"`
+}
+
+// RolloutManagerInjectedFieldsSpec is used to specify fields of the Rollouts controller Deployment that are modified by admission webhooks
+type RolloutManagerInjectedFieldsSpec struct {
+	// Containers is a list of names of containers that are injected into the Rollouts controller Deployment by admission webhooks
+	Containers []string `json:"containers,omitempty"`
+	// Volumes is a list of names of volumes that are injected into the Rollouts controller Deployment by admission webhooks. Volume mounts of these volumes are ignored as well.
+	Volumes []string `json:"volumes,omitempty"`
+	// Resources lets you specify that the resource requests/limits of the Rollouts controller container are managed by admission webhooks (for example, by the Vertical Pod Autoscaler)
+	Resources bool `json:"resources,omitempty"`
+}
+
+// RolloutManagerServiceMeshSpec is used to configure service mesh sidecar injection for the Rollouts controller pods
+type RolloutManagerServiceMeshSpec struct {
+	// Istio lets you configure Istio sidecar injection
+	Istio *RolloutManagerIstioSpec `json:"istio,omitempty"`
+	// Linkerd lets you configure Linkerd proxy injection
+	Linkerd *RolloutManagerLinkerdSpec `json:"linkerd,omitempty"`
+}
+
+// RolloutManagerIstioSpec is used to configure Istio sidecar injection for the Rollouts controller pods
+type RolloutManagerIstioSpec struct {
+	// Inject lets you specify if the Istio sidecar should be injected
+	Inject bool `json:"inject,omitempty"`
+	// Revision is the Istio control plane revision to use for injection (optional). If not specified, the default revision is used.
+	Revision string `json:"revision,omitempty"`
+}
+
+// RolloutManagerLinkerdSpec is used to configure Linkerd proxy injection for the Rollouts controller pods
+type RolloutManagerLinkerdSpec struct {
+	// Inject lets you specify if the Linkerd proxy should be injected
+	Inject bool `json:"inject,omitempty"`
+}
+
+// RolloutManagerSecretsSpec is used to configure the Secrets created by the operator
+type RolloutManagerSecretsSpec struct {
+	// Type is the type of the Secrets. Defaults to Opaque.
+	// The type of a Secret cannot be changed, so the Secrets are replaced (deleted and recreated, with the same data) when it changes.
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Immutable lets you specify if the Secrets should be created as immutable, so that their data cannot be changed once they are created. The operator replaces an immutable Secret when it needs to change its data (for example, for each backup), and the data of the notification Secret can only be changed by recreating it.
+	Immutable bool `json:"immutable,omitempty"`
+}
+
+// RolloutManagerBackupSpec is used to configure periodic backups of the Rollouts configuration
+type RolloutManagerBackupSpec struct {
+	// Enabled lets you specify if the Rollouts configuration should be backed up
+	Enabled bool `json:"enabled,omitempty"`
+	// Schedule is the interval between two backups, expressed as a duration string (for example "30m" or "12h"). Defaults to 24h.
+	Schedule string `json:"schedule,omitempty"`
+	// TargetSecret is the name of the Secret, in the namespace of the RolloutManager, that the backup is written to. Defaults to argo-rollouts-config-backup.
+	TargetSecret string `json:"targetSecret,omitempty"`
 }
 
 // ArgoRolloutsNodePlacementSpec is used to specify NodeSelector and Tolerations for Rollouts workloads
@@ -65,33 +577,135 @@ type RolloutsNodePlacementSpec struct {
 // RolloutManagerStatus defines the observed state of RolloutManager
 type RolloutManagerStatus struct {
 	// RolloutController is a simple, high-level summary of where the RolloutController component is in its lifecycle.
-	// There are three possible RolloutController values:
-	// Pending: The RolloutController component has been accepted by the Kubernetes system, but one or more of the required resources have not been created.
-	// Running: All of the required Pods for the RolloutController component are in a Ready state.
-	// Unknown: The state of the RolloutController component could not be obtained.
+	// It has the same values as Phase.
 	RolloutController RolloutControllerPhase `json:"rolloutController,omitempty"`
 	// Phase is a simple, high-level summary of where the RolloutManager is in its lifecycle.
-	// There are three possible phase values:
-	// Pending: The RolloutManager has been accepted by the Kubernetes system, but one or more of the required resources have not been created.
-	// Available: All of the resources for the RolloutManager are ready.
+	// The possible phase values are:
+	// Pending: The Rollouts controller Deployment was created, and its first pods are starting.
+	// Progressing: The Rollouts controller Deployment is rolling out an update of its pods, or is scaling.
+	// Available: All of the resources for the RolloutManager are ready, and all of the Rollouts controller pods are up to date.
+	// Degraded: The Rollouts controller pods cannot become ready without an intervention (for example, they cannot be scheduled, or their image cannot be pulled). The reason is reported by the ControllerDegraded condition.
+	// Failure: The Rollouts controller Deployment does not exist, or the RolloutManager is invalid.
 	// Unknown: The state of the RolloutManager phase could not be obtained.
 	Phase RolloutControllerPhase `json:"phase,omitempty"`
 
 	// Conditions is an array of the RolloutManager's status conditions
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the most recent generation of the RolloutManager that was reconciled: the conditions reflect this generation.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// RelatedImages contains the container images that are deployed for the RolloutManager, by component.
+	RelatedImages []RelatedImage `json:"relatedImages,omitempty"`
+
+	// LastAppliedSpec is a snapshot of the .spec of the RolloutManager that was last successfully applied by the operator, as normalized JSON, so that external tooling can compute what changed between reconciliations.
+	LastAppliedSpec string `json:"lastAppliedSpec,omitempty"`
+
+	// LastAppliedTime is the time at which LastAppliedSpec was first successfully applied.
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// PprofExpirationTime is the time at which the profiling endpoints of the Rollouts controller, enabled via .spec.debug.pprof, are disabled again.
+	PprofExpirationTime *metav1.Time `json:"pprofExpirationTime,omitempty"`
+
+	// ControllerResourcesRecommendation is the resource recommendation of the VerticalPodAutoscaler targeting the Rollouts controller Deployment, if .spec.vpa.reportRecommendation is enabled and a recommendation is available. It can be used as a sizing hint for .spec.controllerResources.
+	ControllerResourcesRecommendation *RolloutManagerResourcesRecommendation `json:"controllerResourcesRecommendation,omitempty"`
+
+	// Components contains the UIDs of the resources managed by the operator for the RolloutManager, as last observed, so that their deletion and recreation by other actors (controllers or humans) can be detected.
+	Components []RolloutManagerComponentStatus `json:"components,omitempty"`
+
+	// FailureCount is the number of consecutive reconciliations of the RolloutManager that failed with an error (and were retried). It is reset to 0 by the next successful reconciliation.
+	FailureCount int32 `json:"failureCount,omitempty"`
+
+	// LastFailureTime is the time of the last failed reconciliation of the RolloutManager, since the last successful one.
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// LastError is the error of the last failed reconciliation of the RolloutManager, since the last successful one.
+	LastError string `json:"lastError,omitempty"`
 }
 
+// RolloutManagerComponentStatus is a resource managed by the operator for a RolloutManager
+type RolloutManagerComponentStatus struct {
+	// Kind is the kind of the resource (e.g. 'Deployment')
+	Kind string `json:"kind"`
+
+	// Name is the name of the resource. Namespaced resources are in the namespace of the RolloutManager.
+	Name string `json:"name"`
+
+	// UID is the UID of the resource, as last observed by the operator
+	UID types.UID `json:"uid"`
+}
+
+// RelatedImage is a container image that is deployed for a RolloutManager
+type RelatedImage struct {
+	// Name is the name of the component that uses the image (e.g. 'argo-rollouts' for the Rollouts controller)
+	Name string `json:"name"`
+
+	// Image is the reference of the container image
+	Image string `json:"image"`
+}
+
+// ArgsOverrideMode defines how the extra command arguments of the Rollouts controller are combined with the arguments added by the operator
+type ArgsOverrideMode string
+
+const (
+	ArgsOverrideModeAppend  ArgsOverrideMode = "append"
+	ArgsOverrideModeReplace ArgsOverrideMode = "replace"
+)
+
 type RolloutControllerPhase string
 
 const (
-	PhaseAvailable RolloutControllerPhase = "Available"
-	PhasePending   RolloutControllerPhase = "Pending"
-	PhaseUnknown   RolloutControllerPhase = "Unknown"
-	PhaseFailure   RolloutControllerPhase = "Failure"
+	PhaseAvailable   RolloutControllerPhase = "Available"
+	PhasePending     RolloutControllerPhase = "Pending"
+	PhaseProgressing RolloutControllerPhase = "Progressing"
+	PhaseDegraded    RolloutControllerPhase = "Degraded"
+	PhaseUnknown     RolloutControllerPhase = "Unknown"
+	PhaseFailure     RolloutControllerPhase = "Failure"
 )
 
 const (
 	RolloutManagerConditionType = "Reconciled"
+
+	// RolloutManagerReadyConditionType, RolloutManagerReconcilingConditionType and RolloutManagerStalledConditionType follow the kstatus conventions (https://github.com/kubernetes-sigs/cli-utils/tree/master/pkg/kstatus), so that readiness is interpreted correctly by tools such as 'kubectl wait', Flux and Argo CD.
+	// Ready is always set, while Reconciling and Stalled are only present when they are True.
+	RolloutManagerReadyConditionType       = "Ready"
+	RolloutManagerReconcilingConditionType = "Reconciling"
+	RolloutManagerStalledConditionType     = "Stalled"
+
+	// RolloutManagerConflictingInstallationConditionType is True when Argo Rollouts resources which are not managed by the operator (e.g. a Helm or kubectl install) may conflict with the Rollouts controller of the RolloutManager. It is only present when True.
+	RolloutManagerConflictingInstallationConditionType = "ConflictingInstallationDetected"
+
+	// RolloutManagerPodSecurityViolationConditionType is True when the Rollouts controller pod violates the Pod Security Standard enforced on the namespace of the RolloutManager (for example, because of user-specified overrides), so that it would be rejected by the Pod Security admission controller. It is only present when True.
+	RolloutManagerPodSecurityViolationConditionType = "PodSecurityViolation"
+
+	// RolloutManagerCloudIdentityNotConfiguredConditionType is True when a CloudIdentity is specified, but the Rollouts controller ServiceAccount is not configured for it, so that requests of the Rollouts controller to the cloud provider APIs would fail. It is only present when True.
+	RolloutManagerCloudIdentityNotConfiguredConditionType = "CloudIdentityNotConfigured"
+
+	// RolloutManagerDegradedConditionType is True when resources rendered for the RolloutManager violate the resource policies of the operator, so that they were not applied. It is only present when True.
+	RolloutManagerDegradedConditionType = "Degraded"
+
+	// RolloutManagerControllerCrashingConditionType is True when the Rollouts controller container is crashing: its message includes an excerpt of the termination message (or of the last lines of the logs) of the container. It is only present when True.
+	RolloutManagerControllerCrashingConditionType = "ControllerCrashing"
+
+	// RolloutManagerControllerDegradedConditionType is True when the phase of the RolloutManager is Degraded: its reason is the first cause for which the Rollouts controller pods cannot become ready (for example, ImagePullBackOff or Unschedulable), and its message names all of them. It is only present when True.
+	RolloutManagerControllerDegradedConditionType = "ControllerDegraded"
+
+	// RolloutManagerRBACEscalationDeniedConditionType is True when the operator is not allowed to grant the rules of a Role or ClusterRole of the RolloutManager, as it does not hold them itself, so that the Role or ClusterRole was not created or updated: its message names the rules. It is only present when True.
+	RolloutManagerRBACEscalationDeniedConditionType = "RBACEscalationDenied"
+
+	// RolloutManagerRBACRulesChangedConditionType is True when the operator changed the permissions granted by a Role or ClusterRole of the RolloutManager, for example, after it was upgraded: its message names the permissions that were added and removed. It is only present once the permissions changed, and is replaced on the next change.
+	RolloutManagerRBACRulesChangedConditionType = "RBACRulesChanged"
+
+	// RolloutManagerClusterAPIRequiredConditionType is True when the operator runs in strict namespace-scoped mode (via the STRICT_NAMESPACE_SCOPED environment variable), so that it makes no request to cluster-scoped APIs, but features configured by the RolloutManager require cluster-scoped resources, so that they are not reconciled: its message names the features. It is only present when True.
+	RolloutManagerClusterAPIRequiredConditionType = "ClusterAPIRequired"
+
+	// RolloutManagerRBACReconciledConditionType, RolloutManagerConfigReconciledConditionType and RolloutManagerMonitoringReconciledConditionType report whether the RBAC, config and monitoring resources of the RolloutManager were successfully reconciled, when they are reconciled by their own controllers (via the ComponentControllers feature gate of the operator). They are only present when the feature is enabled.
+	RolloutManagerRBACReconciledConditionType       = "RBACReconciled"
+	RolloutManagerConfigReconciledConditionType     = "ConfigReconciled"
+	RolloutManagerMonitoringReconciledConditionType = "MonitoringReconciled"
+
+	// RolloutManagerDashboardCertificateNotReadyConditionType is True when the TLS certificate of the dashboard is requested from cert-manager, but the cert-manager Certificate is not ready (or cert-manager is not installed), so that the dashboard Ingress is not yet created: its message names the problem. It is only present when True.
+	RolloutManagerDashboardCertificateNotReadyConditionType = "DashboardCertificateNotReady"
 )
 
 const (
@@ -100,6 +714,29 @@ const (
 	RolloutManagerReasonMultipleClusterScopedRolloutManager = "MultipleClusterScopedRolloutManager"
 	RolloutManagerReasonInvalidScoped                       = "InvalidRolloutManagerScope"
 	RolloutManagerReasonInvalidNamespace                    = "InvalidRolloutManagerNamespace"
+	RolloutManagerReasonUnsupportedCommandArgs              = "UnsupportedCommandArgs"
+	RolloutManagerReasonUnsupportedImage                    = "UnsupportedImage"
+	RolloutManagerReasonConflictingInstallation             = "ConflictingInstallation"
+	RolloutManagerReasonInvalidFileMounts                   = "InvalidFileMounts"
+	RolloutManagerReasonPodSecurityViolation                = "PodSecurityViolation"
+	RolloutManagerReasonCloudIdentityNotConfigured          = "CloudIdentityNotConfigured"
+	RolloutManagerReasonPolicyViolation                     = "PolicyViolation"
+	RolloutManagerReasonInvalidAdditionalMetadata           = "InvalidAdditionalMetadata"
+	RolloutManagerReasonInvalidPorts                        = "InvalidPorts"
+	RolloutManagerReasonNamespaceTerminating                = "NamespaceTerminating"
+	RolloutManagerReasonInvalidDebug                        = "InvalidDebug"
+	RolloutManagerReasonControllerCrashed                   = "ControllerCrashed"
+	RolloutManagerReasonRBACEscalationDenied                = "RBACEscalationDenied"
+	RolloutManagerReasonInvalidKubeClient                   = "InvalidKubeClient"
+	RolloutManagerReasonInvalidControllerTuning             = "InvalidControllerTuning"
+	RolloutManagerReasonConflictingCommandArgs              = "ConflictingCommandArgs"
+	RolloutManagerReasonRBACRulesChanged                    = "RBACRulesChanged"
+	RolloutManagerReasonImageNotFound                       = "ImageNotFound"
+	RolloutManagerReasonClusterAPIRequired                  = "ClusterAPIRequired"
+	RolloutManagerReasonInvalidHA                           = "InvalidHA"
+	RolloutManagerReasonInvalidDashboard                    = "InvalidDashboard"
+	RolloutManagerReasonDashboardCertificateNotReady        = "DashboardCertificateNotReady"
+	RolloutManagerReasonInvalidTargetNamespace              = "InvalidTargetNamespace"
 )
 
 type ResourceMetadata struct {
@@ -26,6 +26,21 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelatedImage) DeepCopyInto(out *RelatedImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelatedImage.
+func (in *RelatedImage) DeepCopy() *RelatedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(RelatedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceMetadata) DeepCopyInto(out *ResourceMetadata) {
 	*out = *in
@@ -82,6 +97,346 @@ func (in *RolloutManager) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerBackupSpec) DeepCopyInto(out *RolloutManagerBackupSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerBackupSpec.
+func (in *RolloutManagerBackupSpec) DeepCopy() *RolloutManagerBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerCertManagerIssuerRef) DeepCopyInto(out *RolloutManagerCertManagerIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerCertManagerIssuerRef.
+func (in *RolloutManagerCertManagerIssuerRef) DeepCopy() *RolloutManagerCertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerCertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerCloudIdentitySpec) DeepCopyInto(out *RolloutManagerCloudIdentitySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerCloudIdentitySpec.
+func (in *RolloutManagerCloudIdentitySpec) DeepCopy() *RolloutManagerCloudIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerCloudIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerClusterProxySpec) DeepCopyInto(out *RolloutManagerClusterProxySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerClusterProxySpec.
+func (in *RolloutManagerClusterProxySpec) DeepCopy() *RolloutManagerClusterProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerClusterProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerComponentStatus) DeepCopyInto(out *RolloutManagerComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerComponentStatus.
+func (in *RolloutManagerComponentStatus) DeepCopy() *RolloutManagerComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerControllerTuningSpec) DeepCopyInto(out *RolloutManagerControllerTuningSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerControllerTuningSpec.
+func (in *RolloutManagerControllerTuningSpec) DeepCopy() *RolloutManagerControllerTuningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerControllerTuningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerDashboardCertManagerSpec) DeepCopyInto(out *RolloutManagerDashboardCertManagerSpec) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerDashboardCertManagerSpec.
+func (in *RolloutManagerDashboardCertManagerSpec) DeepCopy() *RolloutManagerDashboardCertManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerDashboardCertManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerDashboardIngressSpec) DeepCopyInto(out *RolloutManagerDashboardIngressSpec) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(RolloutManagerDashboardIngressTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerDashboardIngressSpec.
+func (in *RolloutManagerDashboardIngressSpec) DeepCopy() *RolloutManagerDashboardIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerDashboardIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerDashboardIngressTLSSpec) DeepCopyInto(out *RolloutManagerDashboardIngressTLSSpec) {
+	*out = *in
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(RolloutManagerDashboardCertManagerSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerDashboardIngressTLSSpec.
+func (in *RolloutManagerDashboardIngressTLSSpec) DeepCopy() *RolloutManagerDashboardIngressTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerDashboardIngressTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerDashboardSpec) DeepCopyInto(out *RolloutManagerDashboardSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(RolloutManagerDashboardIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerDashboardSpec.
+func (in *RolloutManagerDashboardSpec) DeepCopy() *RolloutManagerDashboardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerDashboardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerDebugSpec) DeepCopyInto(out *RolloutManagerDebugSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerDebugSpec.
+func (in *RolloutManagerDebugSpec) DeepCopy() *RolloutManagerDebugSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerDebugSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerDisruptionAlertsSpec) DeepCopyInto(out *RolloutManagerDisruptionAlertsSpec) {
+	*out = *in
+	if in.PreStopCommand != nil {
+		in, out := &in.PreStopCommand, &out.PreStopCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerDisruptionAlertsSpec.
+func (in *RolloutManagerDisruptionAlertsSpec) DeepCopy() *RolloutManagerDisruptionAlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerDisruptionAlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerFileMount) DeepCopyInto(out *RolloutManagerFileMount) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1.KeyToPath, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerFileMount.
+func (in *RolloutManagerFileMount) DeepCopy() *RolloutManagerFileMount {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerFileMount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerFlowControlSpec) DeepCopyInto(out *RolloutManagerFlowControlSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerFlowControlSpec.
+func (in *RolloutManagerFlowControlSpec) DeepCopy() *RolloutManagerFlowControlSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerFlowControlSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerHASpec) DeepCopyInto(out *RolloutManagerHASpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerHASpec.
+func (in *RolloutManagerHASpec) DeepCopy() *RolloutManagerHASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerHASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerInjectedFieldsSpec) DeepCopyInto(out *RolloutManagerInjectedFieldsSpec) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerInjectedFieldsSpec.
+func (in *RolloutManagerInjectedFieldsSpec) DeepCopy() *RolloutManagerInjectedFieldsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerInjectedFieldsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerIstioSpec) DeepCopyInto(out *RolloutManagerIstioSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerIstioSpec.
+func (in *RolloutManagerIstioSpec) DeepCopy() *RolloutManagerIstioSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerIstioSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerKubeClientSpec) DeepCopyInto(out *RolloutManagerKubeClientSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerKubeClientSpec.
+func (in *RolloutManagerKubeClientSpec) DeepCopy() *RolloutManagerKubeClientSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerKubeClientSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerLinkerdSpec) DeepCopyInto(out *RolloutManagerLinkerdSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerLinkerdSpec.
+func (in *RolloutManagerLinkerdSpec) DeepCopy() *RolloutManagerLinkerdSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerLinkerdSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutManagerList) DeepCopyInto(out *RolloutManagerList) {
 	*out = *in
@@ -114,6 +469,189 @@ func (in *RolloutManagerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerMonitoringSpec) DeepCopyInto(out *RolloutManagerMonitoringSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerMonitoringSpec.
+func (in *RolloutManagerMonitoringSpec) DeepCopy() *RolloutManagerMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerPortsSpec) DeepCopyInto(out *RolloutManagerPortsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerPortsSpec.
+func (in *RolloutManagerPortsSpec) DeepCopy() *RolloutManagerPortsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerPortsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerResourcesRecommendation) DeepCopyInto(out *RolloutManagerResourcesRecommendation) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.LowerBound != nil {
+		in, out := &in.LowerBound, &out.LowerBound
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.UpperBound != nil {
+		in, out := &in.UpperBound, &out.UpperBound
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerResourcesRecommendation.
+func (in *RolloutManagerResourcesRecommendation) DeepCopy() *RolloutManagerResourcesRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerResourcesRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerRestartBudgetSpec) DeepCopyInto(out *RolloutManagerRestartBudgetSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerRestartBudgetSpec.
+func (in *RolloutManagerRestartBudgetSpec) DeepCopy() *RolloutManagerRestartBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerRestartBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerExtraPort) DeepCopyInto(out *RolloutManagerExtraPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerExtraPort.
+func (in *RolloutManagerExtraPort) DeepCopy() *RolloutManagerExtraPort {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerExtraPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerRolloutUserRoleSpec) DeepCopyInto(out *RolloutManagerRolloutUserRoleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerRolloutUserRoleSpec.
+func (in *RolloutManagerRolloutUserRoleSpec) DeepCopy() *RolloutManagerRolloutUserRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerRolloutUserRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerSecretsSpec) DeepCopyInto(out *RolloutManagerSecretsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerSecretsSpec.
+func (in *RolloutManagerSecretsSpec) DeepCopy() *RolloutManagerSecretsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerSecretsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerServiceMeshSpec) DeepCopyInto(out *RolloutManagerServiceMeshSpec) {
+	*out = *in
+	if in.Istio != nil {
+		in, out := &in.Istio, &out.Istio
+		*out = new(RolloutManagerIstioSpec)
+		**out = **in
+	}
+	if in.Linkerd != nil {
+		in, out := &in.Linkerd, &out.Linkerd
+		*out = new(RolloutManagerLinkerdSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerServiceMeshSpec.
+func (in *RolloutManagerServiceMeshSpec) DeepCopy() *RolloutManagerServiceMeshSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerServiceMeshSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerServiceSpec) DeepCopyInto(out *RolloutManagerServiceSpec) {
+	*out = *in
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(v1.IPFamilyPolicy)
+		**out = **in
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]v1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerServiceSpec.
+func (in *RolloutManagerServiceSpec) DeepCopy() *RolloutManagerServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutManagerSpec) DeepCopyInto(out *RolloutManagerSpec) {
 	*out = *in
@@ -129,6 +667,23 @@ func (in *RolloutManagerSpec) DeepCopyInto(out *RolloutManagerSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ScopeArgsOverride != nil {
+		in, out := &in.ScopeArgsOverride, &out.ScopeArgsOverride
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FileMounts != nil {
+		in, out := &in.FileMounts, &out.FileMounts
+		*out = make([]RolloutManagerFileMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.NodePlacement != nil {
 		in, out := &in.NodePlacement, &out.NodePlacement
 		*out = new(RolloutsNodePlacementSpec)
@@ -144,6 +699,131 @@ func (in *RolloutManagerSpec) DeepCopyInto(out *RolloutManagerSpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = new(RolloutManagerSecretsSpec)
+		**out = **in
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(RolloutManagerBackupSpec)
+		**out = **in
+	}
+	if in.ServiceMesh != nil {
+		in, out := &in.ServiceMesh, &out.ServiceMesh
+		*out = new(RolloutManagerServiceMeshSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InjectedFields != nil {
+		in, out := &in.InjectedFields, &out.InjectedFields
+		*out = new(RolloutManagerInjectedFieldsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisruptionAlerts != nil {
+		in, out := &in.DisruptionAlerts, &out.DisruptionAlerts
+		*out = new(RolloutManagerDisruptionAlertsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudIdentity != nil {
+		in, out := &in.CloudIdentity, &out.CloudIdentity
+		*out = new(RolloutManagerCloudIdentitySpec)
+		**out = **in
+	}
+	if in.MetricsService != nil {
+		in, out := &in.MetricsService, &out.MetricsService
+		*out = new(RolloutManagerServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(RolloutManagerMonitoringSpec)
+		**out = **in
+	}
+	if in.VPA != nil {
+		in, out := &in.VPA, &out.VPA
+		*out = new(RolloutManagerVPASpec)
+		**out = **in
+	}
+	if in.RolloutUserRole != nil {
+		in, out := &in.RolloutUserRole, &out.RolloutUserRole
+		*out = new(RolloutManagerRolloutUserRoleSpec)
+		**out = **in
+	}
+	if in.HA != nil {
+		in, out := &in.HA, &out.HA
+		*out = new(RolloutManagerHASpec)
+		**out = **in
+	}
+	if in.Dashboard != nil {
+		in, out := &in.Dashboard, &out.Dashboard
+		*out = new(RolloutManagerDashboardSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = new(RolloutManagerPortsSpec)
+		**out = **in
+	}
+	if in.RestartBudget != nil {
+		in, out := &in.RestartBudget, &out.RestartBudget
+		*out = new(RolloutManagerRestartBudgetSpec)
+		**out = **in
+	}
+	if in.ExtraPorts != nil {
+		in, out := &in.ExtraPorts, &out.ExtraPorts
+		*out = make([]RolloutManagerExtraPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(v1.Lifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(RolloutManagerStartupProbeSpec)
+		**out = **in
+	}
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(RolloutManagerDebugSpec)
+		**out = **in
+	}
+	if in.SkipResources != nil {
+		in, out := &in.SkipResources, &out.SkipResources
+		*out = make([]RolloutManagerSkippableResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.FlowControl != nil {
+		in, out := &in.FlowControl, &out.FlowControl
+		*out = new(RolloutManagerFlowControlSpec)
+		**out = **in
+	}
+	if in.KubeClient != nil {
+		in, out := &in.KubeClient, &out.KubeClient
+		*out = new(RolloutManagerKubeClientSpec)
+		**out = **in
+	}
+	if in.ControllerTuning != nil {
+		in, out := &in.ControllerTuning, &out.ControllerTuning
+		*out = new(RolloutManagerControllerTuningSpec)
+		**out = **in
+	}
+	if in.ClusterProxy != nil {
+		in, out := &in.ClusterProxy, &out.ClusterProxy
+		*out = new(RolloutManagerClusterProxySpec)
+		**out = **in
+	}
+	if in.TrafficRouting != nil {
+		in, out := &in.TrafficRouting, &out.TrafficRouting
+		*out = new(RolloutManagerTrafficRoutingSpec)
+		**out = **in
+	}
+	if in.TargetNamespace != nil {
+		in, out := &in.TargetNamespace, &out.TargetNamespace
+		*out = new(RolloutManagerTargetNamespaceSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerSpec.
@@ -156,6 +836,21 @@ func (in *RolloutManagerSpec) DeepCopy() *RolloutManagerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerStartupProbeSpec) DeepCopyInto(out *RolloutManagerStartupProbeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerStartupProbeSpec.
+func (in *RolloutManagerStartupProbeSpec) DeepCopy() *RolloutManagerStartupProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerStartupProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutManagerStatus) DeepCopyInto(out *RolloutManagerStatus) {
 	*out = *in
@@ -166,6 +861,33 @@ func (in *RolloutManagerStatus) DeepCopyInto(out *RolloutManagerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RelatedImages != nil {
+		in, out := &in.RelatedImages, &out.RelatedImages
+		*out = make([]RelatedImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PprofExpirationTime != nil {
+		in, out := &in.PprofExpirationTime, &out.PprofExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ControllerResourcesRecommendation != nil {
+		in, out := &in.ControllerResourcesRecommendation, &out.ControllerResourcesRecommendation
+		*out = new(RolloutManagerResourcesRecommendation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]RolloutManagerComponentStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerStatus.
@@ -178,6 +900,65 @@ func (in *RolloutManagerStatus) DeepCopy() *RolloutManagerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerTargetNamespaceSpec) DeepCopyInto(out *RolloutManagerTargetNamespaceSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerTargetNamespaceSpec.
+func (in *RolloutManagerTargetNamespaceSpec) DeepCopy() *RolloutManagerTargetNamespaceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerTargetNamespaceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerTrafficRoutingSpec) DeepCopyInto(out *RolloutManagerTrafficRoutingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerTrafficRoutingSpec.
+func (in *RolloutManagerTrafficRoutingSpec) DeepCopy() *RolloutManagerTrafficRoutingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerTrafficRoutingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutManagerVPASpec) DeepCopyInto(out *RolloutManagerVPASpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutManagerVPASpec.
+func (in *RolloutManagerVPASpec) DeepCopy() *RolloutManagerVPASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutManagerVPASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RolloutsNodePlacementSpec) DeepCopyInto(out *RolloutsNodePlacementSpec) {
 	*out = *in
@@ -17,18 +17,30 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -51,6 +63,8 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(rolloutsmanagerv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(monitoringv1.AddToScheme(scheme))
+	utilruntime.Must(crdv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -58,11 +72,106 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var metricsSecure bool
+	var metricsCertDir string
+	var featureGatesValue string
+	var gracefulShutdownTimeout time.Duration
+	var blockOwnerDeletion bool
+	var kubeAPIQPS, statusKubeAPIQPS float64
+	var kubeAPIBurst, statusKubeAPIBurst int
+	var driftReport string
+	var exportBundle, exportBundleDir string
+	var resourceTransformerURL, resourceTransformerCAFile string
+	var resourceTransformerTimeout time.Duration
+	var resourcePolicyFile string
+	var lifecycleWebhookURLs, lifecycleWebhookCAFile string
+	var lifecycleWebhookTimeout time.Duration
+	var imageVerificationTimeout time.Duration
+	var telemetryURL string
+	var telemetryInterval time.Duration
+	var strictNamespaceScoped bool
+	var watchNamespaces string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.BoolVar(&metricsSecure, "metrics-secure", false,
+		"Serve the metrics endpoint via HTTPS, and require that requests are authenticated and authorized by the Kubernetes API server. "+
+			"This is an alternative to serving metrics via the kube-rbac-proxy sidecar.")
+	flag.StringVar(&metricsCertDir, "metrics-cert-dir", "",
+		"The directory that contains the certificate (tls.crt) and key (tls.key) of the metrics endpoint, if --metrics-secure is set. "+
+			"If not set, a self-signed certificate is generated.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", getEnvFloat(controllers.KubeAPIQPSEnvName, controllers.DefaultKubeAPIQPS),
+		"Maximum queries per second from the operator to the Kubernetes API server, for reads and resource writes. "+
+			"Can also be set via the "+controllers.KubeAPIQPSEnvName+" environment variable.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", getEnvInt(controllers.KubeAPIBurstEnvName, controllers.DefaultKubeAPIBurst),
+		"Maximum burst of queries from the operator to the Kubernetes API server, for reads and resource writes. "+
+			"Can also be set via the "+controllers.KubeAPIBurstEnvName+" environment variable.")
+	flag.Float64Var(&statusKubeAPIQPS, "status-kube-api-qps", getEnvFloat(controllers.StatusKubeAPIQPSEnvName, controllers.DefaultStatusKubeAPIQPS),
+		"Maximum queries per second from the operator to the Kubernetes API server, for writes of RolloutManager status. "+
+			"Can also be set via the "+controllers.StatusKubeAPIQPSEnvName+" environment variable.")
+	flag.IntVar(&statusKubeAPIBurst, "status-kube-api-burst", getEnvInt(controllers.StatusKubeAPIBurstEnvName, controllers.DefaultStatusKubeAPIBurst),
+		"Maximum burst of queries from the operator to the Kubernetes API server, for writes of RolloutManager status. "+
+			"Can also be set via the "+controllers.StatusKubeAPIBurstEnvName+" environment variable.")
+	flag.StringVar(&featureGatesValue, "feature-gates", os.Getenv(controllers.FeatureGatesEnvName),
+		"A comma-separated list of 'Feature=true|false' pairs, which enable or disable features of the operator. "+
+			"Can also be set via the "+controllers.FeatureGatesEnvName+" environment variable.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", getEnvDuration(controllers.GracefulShutdownTimeoutEnvName, controllers.DefaultGracefulShutdownTimeout),
+		"How long to wait, on SIGTERM, for in-flight reconciliations to complete before exiting. "+
+			"This should be lower than the terminationGracePeriodSeconds of the operator Pod. "+
+			"Can also be set via the "+controllers.GracefulShutdownTimeoutEnvName+" environment variable.")
+	flag.BoolVar(&blockOwnerDeletion, "block-owner-deletion", getEnvBool(controllers.BlockOwnerDeletionEnvName, true),
+		"Set blockOwnerDeletion on the owner references from the resources created by the operator to their RolloutManager. "+
+			"Disable this where foreground deletion of RolloutManagers or namespaces hangs. "+
+			"Can also be set via the "+controllers.BlockOwnerDeletionEnvName+" environment variable.")
+	flag.StringVar(&resourceTransformerURL, "resource-transformer-url", os.Getenv(controllers.ResourceTransformerURLEnvName),
+		"The URL of a webhook which mutates the resources rendered by the operator before they are applied. "+
+			"Can also be set via the "+controllers.ResourceTransformerURLEnvName+" environment variable.")
+	flag.StringVar(&resourceTransformerCAFile, "resource-transformer-ca-file", os.Getenv(controllers.ResourceTransformerCAFileEnvName),
+		"The file containing the CA certificates used to verify the certificate of the resource transformer webhook. If not set, the CA certificates of the system are used. "+
+			"Can also be set via the "+controllers.ResourceTransformerCAFileEnvName+" environment variable.")
+	flag.DurationVar(&resourceTransformerTimeout, "resource-transformer-timeout", getEnvDuration(controllers.ResourceTransformerTimeoutEnvName, controllers.DefaultResourceTransformerTimeout),
+		"The timeout of requests to the resource transformer webhook. "+
+			"Can also be set via the "+controllers.ResourceTransformerTimeoutEnvName+" environment variable.")
+	flag.StringVar(&lifecycleWebhookURLs, "lifecycle-webhook-urls", os.Getenv(controllers.LifecycleWebhookURLsEnvName),
+		"A comma-separated list of URLs of webhooks which are notified of the lifecycle events of RolloutManagers (created, upgraded, degraded and deleted). "+
+			"Can also be set via the "+controllers.LifecycleWebhookURLsEnvName+" environment variable.")
+	flag.StringVar(&lifecycleWebhookCAFile, "lifecycle-webhook-ca-file", os.Getenv(controllers.LifecycleWebhookCAFileEnvName),
+		"The file containing the CA certificates used to verify the certificates of the lifecycle webhooks. If not set, the CA certificates of the system are used. "+
+			"Can also be set via the "+controllers.LifecycleWebhookCAFileEnvName+" environment variable.")
+	flag.DurationVar(&lifecycleWebhookTimeout, "lifecycle-webhook-timeout", getEnvDuration(controllers.LifecycleWebhookTimeoutEnvName, controllers.DefaultLifecycleWebhookTimeout),
+		"The timeout of requests to the lifecycle webhooks. "+
+			"Can also be set via the "+controllers.LifecycleWebhookTimeoutEnvName+" environment variable.")
+	flag.DurationVar(&imageVerificationTimeout, "image-verification-timeout", getEnvDuration(controllers.ImageVerificationTimeoutEnvName, controllers.DefaultImageVerificationTimeout),
+		"The timeout of requests to container registries, when verifying that the Rollouts controller image exists (for RolloutManagers which set verifyImage). "+
+			"Can also be set via the "+controllers.ImageVerificationTimeoutEnvName+" environment variable.")
+	flag.StringVar(&telemetryURL, "telemetry-url", os.Getenv(controllers.TelemetryURLEnvName),
+		"The URL that anonymized usage reports (the versions of the operator and of the Rollouts controllers, the number of namespace-scoped and cluster-scoped RolloutManagers, "+
+			"and the features that they use) are sent to. Telemetry is disabled unless it is set. "+
+			"Can also be set via the "+controllers.TelemetryURLEnvName+" environment variable.")
+	flag.DurationVar(&telemetryInterval, "telemetry-interval", getEnvDuration(controllers.TelemetryIntervalEnvName, controllers.DefaultTelemetryInterval),
+		"The interval between usage reports, if --telemetry-url is set. "+
+			"Can also be set via the "+controllers.TelemetryIntervalEnvName+" environment variable.")
+	flag.BoolVar(&strictNamespaceScoped, "strict-namespace-scoped", getEnvBool(controllers.StrictNamespaceScopedEnvName, false),
+		"If true, the operator makes no requests to cluster-scoped APIs, so that it only requires permissions in the namespaces that it watches (see --watch-namespaces): "+
+			"the aggregate ClusterRoles are not managed, and features of RolloutManagers which require cluster-scoped resources are reported by their ClusterAPIRequired condition. "+
+			"Requires the "+controllers.NamespaceScopedArgoRolloutsController+" environment variable to be 'true'. "+
+			"Can also be set via the "+controllers.StrictNamespaceScopedEnvName+" environment variable.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", os.Getenv(controllers.WatchNamespacesEnvName),
+		"A comma-separated list of the namespaces whose RolloutManagers are reconciled, if --strict-namespace-scoped is true. Defaults to the namespace of the operator. "+
+			"Can also be set via the "+controllers.WatchNamespacesEnvName+" environment variable.")
+	flag.StringVar(&resourcePolicyFile, "resource-policy-file", os.Getenv(controllers.ResourcePolicyFileEnvName),
+		"A YAML file containing policies that the resources rendered by the operator must satisfy: resources which violate them are not applied. "+
+			"Can also be set via the "+controllers.ResourcePolicyFileEnvName+" environment variable.")
+	flag.StringVar(&driftReport, "drift-report", "",
+		"Instead of running the operator, print the resources of the RolloutManager '<namespace>/<name>' which differ from their desired state "+
+			"(the fields that the operator would update, and the resources it would create or delete), without modifying them, and exit.")
+	flag.StringVar(&exportBundle, "export-bundle", "",
+		"Instead of running the operator, write the resources of the RolloutManager '<namespace>/<name>', as the operator would create them, "+
+			"as a Kustomize-compatible bundle in the --export-bundle-dir directory, and exit.")
+	flag.StringVar(&exportBundleDir, "export-bundle-dir", "rolloutmanager-bundle",
+		"The directory that the bundle of --export-bundle is written to. It is created if it does not exist.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -71,10 +180,84 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme: scheme,
-		Metrics: server.Options{
-			BindAddress: metricsAddr,
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.QPS = float32(kubeAPIQPS)
+	restConfig.Burst = kubeAPIBurst
+
+	// Status writes use a separate client, with its own rate limit, so that they do not compete with resource writes
+	statusRestConfig := rest.CopyConfig(restConfig)
+	statusRestConfig.QPS = float32(statusKubeAPIQPS)
+	statusRestConfig.Burst = statusKubeAPIBurst
+
+	setupLog.Info("Kubernetes API client rate limits", "qps", kubeAPIQPS, "burst", kubeAPIBurst, "statusQPS", statusKubeAPIQPS, "statusBurst", statusKubeAPIBurst)
+
+	metricsServerOptions := server.Options{
+		BindAddress: metricsAddr,
+	}
+	if metricsSecure {
+		setupLog.Info("Serving metrics via HTTPS, with authentication and authorization")
+		metricsServerOptions.SecureServing = true
+		metricsServerOptions.CertDir = metricsCertDir
+		metricsServerOptions.FilterProvider = controllers.WithMetricsAuthenticationAndAuthorization
+	}
+
+	isNamespaceScoped := strings.ToLower(os.Getenv(controllers.NamespaceScopedArgoRolloutsController)) == "true"
+
+	if isNamespaceScoped {
+		setupLog.Info("Running in namespaced-scoped mode")
+	} else {
+		setupLog.Info("Running in cluster-scoped mode")
+	}
+
+	// In strict namespace-scoped mode, the cache only watches the given namespaces, as the operator is not allowed to list resources across all namespaces
+	var cacheNamespaces map[string]cache.Config
+	if strictNamespaceScoped {
+		if !isNamespaceScoped {
+			setupLog.Error(nil, "--strict-namespace-scoped requires the "+controllers.NamespaceScopedArgoRolloutsController+" environment variable to be 'true'")
+			os.Exit(1)
+		}
+
+		if telemetryURL != "" {
+			// Usage reports read the kube-system Namespace, and count the RolloutManagers of all namespaces
+			setupLog.Error(nil, "--telemetry-url is not supported with --strict-namespace-scoped")
+			os.Exit(1)
+		}
+
+		var namespaces []string
+		for _, namespace := range strings.Split(watchNamespaces, ",") {
+			if namespace = strings.TrimSpace(namespace); namespace != "" {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+		if len(namespaces) == 0 {
+			if namespace := getOperatorNamespace(); namespace != "" {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+		if len(namespaces) == 0 {
+			setupLog.Error(nil, "--strict-namespace-scoped requires --watch-namespaces, when the namespace of the operator cannot be determined")
+			os.Exit(1)
+		}
+
+		cacheNamespaces = map[string]cache.Config{}
+		for _, namespace := range namespaces {
+			cacheNamespaces[namespace] = cache.Config{}
+		}
+		setupLog.Info("Running in strict namespace-scoped mode: no requests are made to cluster-scoped APIs", "watchNamespaces", namespaces)
+	}
+
+	// Only the pods (and ReplicaSets) of Rollouts controllers are used by the operator (to alert on disruptions), so the cache is restricted to these, rather than caching every pod of the cluster.
+	rolloutsControllerSelector := labels.SelectorFromSet(labels.Set{controllers.DefaultRolloutsSelectorKey: controllers.DefaultArgoRolloutsResourceName})
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsServerOptions,
+		Cache: cache.Options{
+			DefaultNamespaces: cacheNamespaces,
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}:        {Label: rolloutsControllerSelector},
+				&appsv1.ReplicaSet{}: {Label: rolloutsControllerSelector},
+			},
 		},
 		WebhookServer: webhook.NewServer(webhook.Options{
 			Port: 9443,
@@ -88,11 +271,12 @@ func main() {
 		// speeds up voluntary leader transitions as the new leader don't have to wait
 		// LeaseDuration time first.
 		//
-		// In the default scaffold provided, the program ends immediately after
-		// the manager stops, so would be fine to enable this option. However,
-		// if you are doing or is intended to do any operation such as perform cleanups
-		// after the manager stops then its usage might be unsafe.
-		// LeaderElectionReleaseOnCancel: true,
+		// The program ends immediately after the manager stops (see below), so this is safe.
+		LeaderElectionReleaseOnCancel: true,
+		// On SIGTERM, the manager stops the controllers first: they stop accepting new work from their workqueue, and wait
+		// for in-flight reconciliations (including their final status update) to complete. Only then are the informers and
+		// the webhook server stopped. (On startup, the reverse order is used: the webhook server is started before the informers.)
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -105,35 +289,123 @@ func main() {
 		openShiftRoutePluginLocation = controllers.DefaultOpenShiftRoutePluginURL
 	}
 
-	isNamespaceScoped := strings.ToLower(os.Getenv(controllers.NamespaceScopedArgoRolloutsController)) == "true"
+	if !blockOwnerDeletion {
+		setupLog.Info("Owner references of the resources created by the operator will not set blockOwnerDeletion")
+	}
 
-	if isNamespaceScoped {
-		setupLog.Info("Running in namespaced-scoped mode")
-	} else {
-		setupLog.Info("Running in cluster-scoped mode")
+	featureGates, err := controllers.ParseFeatureGates(featureGatesValue)
+	if err != nil {
+		setupLog.Error(err, "unable to parse feature gates")
+		os.Exit(1)
+	}
+	setupLog.Info("Feature gates", "featureGates", featureGates.String())
+
+	relatedImages, err := controllers.ResolveRelatedImages()
+	if err != nil {
+		setupLog.Error(err, "unable to resolve related images")
+		os.Exit(1)
+	}
+	for _, relatedImage := range relatedImages {
+		setupLog.Info("Using related image", "name", relatedImage.Name, "image", relatedImage.Image)
+	}
+
+	commonLabels, err := controllers.ResolveCommonLabels()
+	if err != nil {
+		setupLog.Error(err, "unable to resolve common labels")
+		os.Exit(1)
+	}
+	if len(commonLabels) > 0 {
+		setupLog.Info("Setting common labels on all resources", "labels", commonLabels)
+	}
+
+	var resourceTransformer controllers.ResourceTransformer
+	if resourceTransformerURL != "" {
+		webhookResourceTransformer, err := controllers.NewWebhookResourceTransformer(resourceTransformerURL, resourceTransformerCAFile, resourceTransformerTimeout)
+		if err != nil {
+			setupLog.Error(err, "unable to create resource transformer")
+			os.Exit(1)
+		}
+		resourceTransformer = webhookResourceTransformer
+		setupLog.Info("Transforming the resources of RolloutManagers via webhook", "url", resourceTransformerURL)
 	}
 
-	if err := monitoringv1.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
+	var lifecycleNotifier controllers.LifecycleNotifier
+	if urls, err := controllers.ParseLifecycleWebhookURLs(lifecycleWebhookURLs); err != nil {
+		setupLog.Error(err, "unable to parse lifecycle webhook URLs")
 		os.Exit(1)
+	} else if len(urls) > 0 {
+		webhookLifecycleNotifier, err := controllers.NewWebhookLifecycleNotifier(urls, lifecycleWebhookCAFile, lifecycleWebhookTimeout)
+		if err != nil {
+			setupLog.Error(err, "unable to create lifecycle notifier")
+			os.Exit(1)
+		}
+		lifecycleNotifier = webhookLifecycleNotifier
+		setupLog.Info("Notifying the lifecycle events of RolloutManagers via webhooks", "urls", urls)
+	}
+
+	var resourcePolicies []controllers.ResourcePolicy
+	if resourcePolicyFile != "" {
+		if resourcePolicies, err = controllers.LoadResourcePolicies(resourcePolicyFile); err != nil {
+			setupLog.Error(err, "unable to load resource policies")
+			os.Exit(1)
+		}
+		for _, policy := range resourcePolicies {
+			setupLog.Info("Checking resources against resource policy", "name", policy.Name)
+		}
 	}
 
-	if err := crdv1.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
+	statusClient, err := client.New(statusRestConfig, client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		setupLog.Error(err, "unable to create status client")
 		os.Exit(1)
 	}
 
-	if err = (&controllers.RolloutManagerReconciler{
+	reconciler := &controllers.RolloutManagerReconciler{
 		Client:                                mgr.GetClient(),
+		StatusClient:                          statusClient,
 		Scheme:                                mgr.GetScheme(),
 		OpenShiftRoutePluginLocation:          openShiftRoutePluginLocation,
 		NamespaceScopedArgoRolloutsController: isNamespaceScoped,
-	}).SetupWithManager(mgr); err != nil {
+		FeatureGates:                          featureGates,
+		Recorder:                              mgr.GetEventRecorderFor(controllers.EventRecorderName),
+		DisableBlockOwnerDeletion:             !blockOwnerDeletion,
+		ResourceTransformer:                   resourceTransformer,
+		ResourcePolicies:                      resourcePolicies,
+		LifecycleNotifier:                     lifecycleNotifier,
+		AccessReviewer:                        &controllers.SelfSubjectAccessReviewer{Client: mgr.GetClient()},
+		ImageVerifier:                         controllers.NewRegistryImageVerifier(imageVerificationTimeout),
+		ClusterScopedLockNamespace:            getOperatorNamespace(),
+		StrictNamespaceScoped:                 strictNamespaceScoped,
+	}
+
+	if driftReport != "" {
+		os.Exit(runDriftReport(restConfig, reconciler, driftReport))
+	}
+
+	if exportBundle != "" {
+		os.Exit(runExportBundle(restConfig, reconciler, exportBundle, exportBundleDir))
+	}
+
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RolloutManager")
 		os.Exit(1)
 	}
 	//+kubebuilder:scaffold:builder
 
+	if telemetryURL != "" {
+		// The API reader is used, so that the namespace which identifies the installation is read without being cached
+		telemetryReporter, err := controllers.NewTelemetryReporter(mgr.GetAPIReader(), telemetryURL, telemetryInterval, featureGates)
+		if err != nil {
+			setupLog.Error(err, "unable to create telemetry reporter")
+			os.Exit(1)
+		}
+		if err := mgr.Add(telemetryReporter); err != nil {
+			setupLog.Error(err, "unable to set up telemetry reporter")
+			os.Exit(1)
+		}
+		setupLog.Info("Sending anonymized usage reports", "url", telemetryURL, "interval", telemetryInterval, "operatorVersion", controllers.OperatorVersion)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -143,9 +415,124 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupLog.Info("starting manager")
+	setupLog.Info("starting manager", "gracefulShutdownTimeout", gracefulShutdownTimeout)
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+	setupLog.Info("manager stopped")
+}
+
+// runDriftReport prints the drift of the resources of the RolloutManager '<namespace>/<name>' (see RolloutManagerReconciler.DetectDrift), and returns the exit code.
+// The manager is not started, so the resources are read directly from the API server rather than from the cache.
+func runDriftReport(restConfig *rest.Config, reconciler *controllers.RolloutManagerReconciler, rolloutManager string) int {
+
+	namespace, name, found := strings.Cut(rolloutManager, "/")
+	if !found || namespace == "" || name == "" {
+		setupLog.Error(fmt.Errorf("'%s' is not of the form '<namespace>/<name>'", rolloutManager), "invalid --drift-report value")
+		return 2
+	}
+
+	directClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		return 1
+	}
+	reconciler.Client = directClient
+
+	drifts, err := reconciler.DetectDrift(context.Background(), types.NamespacedName{Namespace: namespace, Name: name})
+
+	for _, drift := range drifts {
+		fmt.Println(drift.String())
+	}
+	if err != nil {
+		setupLog.Error(err, "drift report is incomplete: the reconciliation of the RolloutManager failed")
+		return 1
+	}
+	if len(drifts) == 0 {
+		fmt.Printf("No drift: the resources of RolloutManager %s match their desired state\n", rolloutManager)
+	}
+
+	return 0
+}
+
+// runExportBundle writes the resources of the RolloutManager '<namespace>/<name>' as a Kustomize-compatible bundle in the directory (see RolloutManagerReconciler.ExportBundle), and returns the exit code.
+// As for the drift report, the manager is not started, so the RolloutManager is read directly from the API server rather than from the cache.
+func runExportBundle(restConfig *rest.Config, reconciler *controllers.RolloutManagerReconciler, rolloutManager string, dir string) int {
+
+	namespace, name, found := strings.Cut(rolloutManager, "/")
+	if !found || namespace == "" || name == "" {
+		setupLog.Error(fmt.Errorf("'%s' is not of the form '<namespace>/<name>'", rolloutManager), "invalid --export-bundle value")
+		return 2
+	}
+
+	directClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		return 1
+	}
+	reconciler.Client = directClient
+
+	files, err := reconciler.ExportBundle(context.Background(), types.NamespacedName{Namespace: namespace, Name: name})
+	if err != nil {
+		setupLog.Error(err, "unable to export the resources of the RolloutManager")
+		return 1
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		setupLog.Error(err, "unable to create the bundle directory", "directory", dir)
+		return 1
+	}
+	for fileName, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, fileName), data, 0644); err != nil {
+			setupLog.Error(err, "unable to write the bundle", "file", fileName)
+			return 1
+		}
+	}
+
+	fmt.Printf("Exported %d resources of RolloutManager %s to %s\n", len(files)-1, rolloutManager, dir)
+	return 0
+}
+
+// getOperatorNamespace returns the namespace of the operator: the value of the OPERATOR_NAMESPACE environment variable, or else the namespace of the ServiceAccount of the operator pod. It returns an empty string if the operator runs outside of the cluster.
+func getOperatorNamespace() string {
+	if namespace := os.Getenv(controllers.OperatorNamespaceEnvName); namespace != "" {
+		return namespace
+	}
+	if namespace, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
+		return strings.TrimSpace(string(namespace))
+	}
+	return ""
+}
+
+// getEnvFloat returns the value of the environment variable as a float, or the default value if it is not set or invalid.
+func getEnvFloat(name string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(name), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration returns the value of the environment variable as a duration (e.g. '30s'), or the default value if it is not set or invalid.
+func getEnvDuration(name string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool returns the value of the environment variable as a bool, or the default value if it is not set or invalid.
+func getEnvBool(name string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt returns the value of the environment variable as an int, or the default value if it is not set or invalid.
+func getEnvInt(name string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return value
+	}
+	return defaultValue
 }
@@ -0,0 +1,165 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// adoptableResourceLists returns (empty) lists of the kinds of namespaced resources which the operator creates for a RolloutManager, and which carry the operator's labels.
+func adoptableResourceLists() []client.ObjectList {
+	return []client.ObjectList{
+		&corev1.ServiceAccountList{},
+		&corev1.ServiceList{},
+		&corev1.SecretList{},
+		&corev1.ConfigMapList{},
+		&appsv1.DeploymentList{},
+		&rbacv1.RoleList{},
+		&rbacv1.RoleBindingList{},
+	}
+}
+
+// hasOperatorLabels returns true if the object has the labels that the operator sets on the resources that it creates.
+func hasOperatorLabels(obj client.Object) bool {
+	labels := obj.GetLabels()
+	return labels["app.kubernetes.io/part-of"] == DefaultArgoRolloutsResourceName &&
+		labels[rolloutsComponentLabel] == DefaultArgoRolloutsResourceName
+}
+
+// isUserAuthoredResource returns true if the resource is authored by users (for example, via GitOps), rather than created by the operator, even though it may carry the operator's labels: the notification ConfigMap of the Rollouts controller is never created by the operator.
+// The backup Secret, and the resources restored from it, are not owned by the RolloutManager either (see DoNotAdoptLabel). Such resources are not adopted, so that they are not garbage collected along with the RolloutManager.
+func isUserAuthoredResource(cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) bool {
+	if obj.GetLabels()[DoNotAdoptLabel] == "true" {
+		return true
+	}
+
+	switch obj.(type) {
+	case *corev1.ConfigMap:
+		return obj.GetName() == DefaultRolloutsNotificationConfigMapName
+	case *corev1.Secret:
+		// The backup Secret may have been written before it was labelled
+		return obj.GetName() == getBackupSecretName(cr)
+	}
+	return false
+}
+
+// isOrphanedResource returns true if the resource was created by the operator, but is no longer owned by an existing RolloutManager. This is the case if:
+// - it has the operator's labels, but no controller owner reference (e.g. the RolloutManager was deleted with the 'orphan' propagation policy), or
+// - its controller owner reference is to a RolloutManager which no longer exists (e.g. the RolloutManager was deleted and recreated while the operator was not running).
+func (r *RolloutManagerReconciler) isOrphanedResource(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) (bool, error) {
+
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil {
+		return hasOperatorLabels(obj), nil
+	}
+
+	if owner.UID == cr.UID {
+		return false, nil
+	}
+
+	if owner.Kind != "RolloutManager" {
+		// Owned by something else, so leave it alone
+		return false, nil
+	}
+
+	var ownerRM rolloutsmanagerv1alpha1.RolloutManager
+	if err := fetchObject(ctx, r.Client, obj.GetNamespace(), owner.Name, &ownerRM); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get the owner of %s: %w", obj.GetName(), err)
+	}
+
+	// Still owned by another RolloutManager, if it is the same instance
+	return ownerRM.UID != owner.UID, nil
+}
+
+// adoptResource replaces the (dangling) RolloutManager owner references of the resource with a controller reference to the RolloutManager, and updates it.
+func (r *RolloutManagerReconciler) adoptResource(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error {
+
+	var ownerReferences []metav1.OwnerReference
+	for _, ownerReference := range obj.GetOwnerReferences() {
+		if ownerReference.Kind != "RolloutManager" {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+	obj.SetOwnerReferences(ownerReferences)
+
+	if err := r.setControllerReference(&cr, obj); err != nil {
+		return err
+	}
+
+	log.Info("Adopting orphaned resource", "kind", fmt.Sprintf("%T", obj), "namespace", obj.GetNamespace(), "name", obj.GetName())
+
+	return r.Client.Update(ctx, obj)
+}
+
+// adoptOrphanedResources re-adopts the resources in the namespace of the RolloutManager which were created by the operator, but whose owner no longer exists (see isOrphanedResource), so that they are managed (and garbage collected) as if they were created for this RolloutManager.
+func (r *RolloutManagerReconciler) adoptOrphanedResources(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	for _, list := range adoptableResourceLists() {
+
+		if err := r.Client.List(ctx, list, client.InNamespace(cr.Namespace), client.MatchingLabels{rolloutsComponentLabel: DefaultArgoRolloutsResourceName}); err != nil {
+			return fmt.Errorf("failed to list %T: %w", list, err)
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+
+			obj, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+
+			if isUserAuthoredResource(cr, obj) {
+				continue
+			}
+
+			if orphaned, err := r.isOrphanedResource(ctx, cr, obj); err != nil {
+				return err
+			} else if !orphaned {
+				continue
+			}
+
+			if err := r.adoptResource(ctx, cr, obj); err != nil {
+				return fmt.Errorf("failed to adopt %s: %w", obj.GetName(), err)
+			}
+		}
+	}
+
+	// The ServiceMonitor does not have the operator's labels, so it is only adopted if its owner no longer exists
+	serviceMonitor := &monitoringv1.ServiceMonitor{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, serviceMonitor); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the ServiceMonitor %s: %w", DefaultArgoRolloutsResourceName, err)
+	}
+
+	if metav1.GetControllerOf(serviceMonitor) == nil {
+		return nil
+	}
+
+	if orphaned, err := r.isOrphanedResource(ctx, cr, serviceMonitor); err != nil {
+		return err
+	} else if orphaned {
+		if err := r.adoptResource(ctx, cr, serviceMonitor); err != nil {
+			return fmt.Errorf("failed to adopt %s: %w", serviceMonitor.GetName(), err)
+		}
+	}
+
+	return nil
+}
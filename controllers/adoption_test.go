@@ -0,0 +1,194 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Orphaned resource adoption tests", func() {
+
+	var (
+		ctx context.Context
+		rm  *rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	danglingOwnerReference := func() metav1.OwnerReference {
+		return metav1.OwnerReference{
+			APIVersion: rolloutsmanagerv1alpha1.GroupVersion.String(),
+			Kind:       "RolloutManager",
+			Name:       "deleted-rollouts-manager",
+			UID:        "deleted-uid",
+			Controller: &[]bool{true}[0],
+		}
+	}
+
+	expectOwnedByRolloutManager := func(obj client.Object) {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+		owner := metav1.GetControllerOf(obj)
+		Expect(owner).ToNot(BeNil())
+		Expect(owner.UID).To(Equal(rm.UID))
+		Expect(obj.GetOwnerReferences()).To(HaveLen(1))
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		rm = makeTestRolloutManager()
+		rm.UID = "rollouts-manager-uid"
+		r = makeTestReconciler(rm)
+		Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+	})
+
+	It("should adopt a resource with the operator's labels, but no owner", func() {
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: rm.Namespace}}
+		setRolloutsLabelsAndAnnotationsToObject(&sa.ObjectMeta, *rm)
+		Expect(r.Client.Create(ctx, sa)).To(Succeed())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+		expectOwnedByRolloutManager(sa)
+	})
+
+	It("should adopt a resource whose owner RolloutManager no longer exists", func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: rm.Namespace}}
+		setRolloutsLabelsAndAnnotationsToObject(&deployment.ObjectMeta, *rm)
+		deployment.OwnerReferences = []metav1.OwnerReference{danglingOwnerReference()}
+		Expect(r.Client.Create(ctx, deployment)).To(Succeed())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+		expectOwnedByRolloutManager(deployment)
+	})
+
+	It("should adopt the ServiceMonitor if its owner RolloutManager no longer exists", func() {
+		serviceMonitor := &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{
+			Name:            DefaultArgoRolloutsResourceName,
+			Namespace:       rm.Namespace,
+			OwnerReferences: []metav1.OwnerReference{danglingOwnerReference()},
+		}}
+		Expect(r.Client.Create(ctx, serviceMonitor)).To(Succeed())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+		expectOwnedByRolloutManager(serviceMonitor)
+	})
+
+	It("should not adopt a resource without the operator's labels", func() {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "user-config-map", Namespace: rm.Namespace,
+			Labels: map[string]string{rolloutsComponentLabel: DefaultArgoRolloutsResourceName}}}
+		Expect(r.Client.Create(ctx, configMap)).To(Succeed())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)).To(Succeed())
+		Expect(configMap.OwnerReferences).To(BeEmpty())
+	})
+
+	It("should not adopt the notification ConfigMap, which is authored by users, even with the operator's labels", func() {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationConfigMapName, Namespace: rm.Namespace}}
+		setRolloutsLabelsAndAnnotationsToObject(&configMap.ObjectMeta, *rm)
+		Expect(r.Client.Create(ctx, configMap)).To(Succeed())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)).To(Succeed())
+		Expect(configMap.OwnerReferences).To(BeEmpty())
+	})
+
+	It("should not adopt a resource which is owned by another RolloutManager that exists", func() {
+		otherRM := makeTestRolloutManager()
+		otherRM.Name = "other-rollouts-manager"
+		otherRM.UID = "other-uid"
+		otherRM.ResourceVersion = ""
+		Expect(r.Client.Create(ctx, otherRM)).To(Succeed())
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(otherRM), otherRM)).To(Succeed())
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationSecretName, Namespace: rm.Namespace}}
+		setRolloutsLabelsAndAnnotationsToObject(&secret.ObjectMeta, *rm)
+		ownerReference := danglingOwnerReference()
+		ownerReference.Name = otherRM.Name
+		ownerReference.UID = otherRM.UID
+		secret.OwnerReferences = []metav1.OwnerReference{ownerReference}
+		Expect(r.Client.Create(ctx, secret)).To(Succeed())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+		Expect(metav1.GetControllerOf(secret).UID).To(Equal(otherRM.UID))
+	})
+
+	It("should resume management of orphaned resources on reconcile", func() {
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+		defer os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: rm.Namespace}}
+		setRolloutsLabelsAndAnnotationsToObject(&sa.ObjectMeta, *rm)
+		sa.OwnerReferences = []metav1.OwnerReference{danglingOwnerReference()}
+		Expect(r.Client.Create(ctx, sa)).To(Succeed())
+
+		res, err := r.reconcileRolloutsManager(ctx, *rm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess))
+
+		expectOwnedByRolloutManager(sa)
+	})
+
+	It("should not adopt the backup Secret, which must survive deletion of the RolloutManager", func() {
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+		defer os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+
+		rm.Spec.Backup = &rolloutsmanagerv1alpha1.RolloutManagerBackupSpec{Enabled: true}
+
+		res, err := r.reconcileRolloutsManager(ctx, *rm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess))
+
+		backupSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsBackupSecretName, Namespace: rm.Namespace}}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(backupSecret), backupSecret)).To(Succeed())
+		Expect(hasOperatorLabels(backupSecret)).To(BeTrue())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(backupSecret), backupSecret)).To(Succeed())
+		Expect(backupSecret.OwnerReferences).To(BeEmpty())
+
+		By("removing the do-not-adopt label, as on a backup Secret written by a previous version of the operator")
+		delete(backupSecret.Labels, DoNotAdoptLabel)
+		Expect(r.Client.Update(ctx, backupSecret)).To(Succeed())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(backupSecret), backupSecret)).To(Succeed())
+		Expect(backupSecret.OwnerReferences).To(BeEmpty())
+	})
+
+	It("should not adopt a resource restored from the backup", func() {
+		rm.Spec.Backup = &rolloutsmanagerv1alpha1.RolloutManagerBackupSpec{Enabled: true}
+
+		notificationSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationSecretName, Namespace: rm.Namespace},
+			Data:       map[string][]byte{"slack-token": []byte("my-token")},
+		}
+		Expect(r.Client.Create(ctx, notificationSecret)).To(Succeed())
+
+		_, err := r.reconcileBackup(ctx, *rm)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("deleting the notification Secret, and restoring it from the backup")
+		Expect(r.Client.Delete(ctx, notificationSecret)).To(Succeed())
+		rm.Annotations = map[string]string{RestoreBackupAnnotation: "1"}
+		Expect(r.restoreBackupIfRequested(ctx, *rm)).To(Succeed())
+
+		restoredSecret := &corev1.Secret{}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(notificationSecret), restoredSecret)).To(Succeed())
+		Expect(hasOperatorLabels(restoredSecret)).To(BeTrue())
+
+		Expect(r.adoptOrphanedResources(ctx, *rm)).To(Succeed())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(notificationSecret), restoredSecret)).To(Succeed())
+		Expect(restoredSecret.OwnerReferences).To(BeEmpty())
+	})
+})
@@ -0,0 +1,190 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// applyResource creates the expected resource if it does not exist, or otherwise updates the live resource so that it matches the expected state. It returns whether the resource was created, updated, or left unchanged.
+//   - 'expected' must have been prepared with prepareResource, and is created as is (with the SpecHashAnnotation).
+//   - 'live' is an empty object of the same type as 'expected': it receives the resource as it exists on the cluster once the function returns.
+//   - 'owned' sets a controller reference to the RolloutManager on creation. Cluster-scoped resources, and resources in other namespaces, cannot be owned by the RolloutManager.
+//   - 'updateFields' (optional) copies the fields that are specific to the type of the resource from 'expected' to 'live', and returns true if they did not match. Labels/annotations and the SpecHashAnnotation are handled by applyResource.
+//
+// Roles and ClusterRoles are only created or updated if the operator is allowed to grant their rules (see checkRBACEscalation).
+// An update which conflicts with another writer is retried against the latest version of the resource, and a resource which was created concurrently is updated instead.
+func (r *RolloutManagerReconciler) applyResource(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, expected client.Object, live client.Object, owned bool, updateFields func() bool) (controllerutil.OperationResult, error) {
+
+	description := r.describeResource(cr, expected)
+	specHash := computeSpecHash(expected)
+
+	result := controllerutil.OperationResultNone
+
+	err := retry.OnError(retry.DefaultRetry, isRetriableApplyError, func() error {
+
+		resetObject(live)
+		if err := fetchObject(ctx, r.Client, expected.GetNamespace(), expected.GetName(), live); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get the %s: %w", description, err)
+			}
+
+			created, ok := expected.DeepCopyObject().(client.Object)
+			if !ok {
+				return fmt.Errorf("unable to copy the %s", description)
+			}
+
+			if owned {
+				if err := r.setControllerReference(&cr, created); err != nil {
+					return err
+				}
+			}
+
+			if err := r.checkRBACEscalation(ctx, cr, created); err != nil {
+				return err
+			}
+
+			setSpecHashAnnotationOfObject(created, specHash)
+			log.Info(fmt.Sprintf("Creating %s", description))
+			if err := r.Client.Create(ctx, created); err != nil {
+				return err
+			}
+
+			copyObject(live, created)
+			result = controllerutil.OperationResultCreated
+			return nil
+		}
+
+		// The live resource was last updated by the operator to the expected state, so any difference was introduced outside of the operator
+		drifted := specHashMatches(labelsAndAnnotationsOf(live), specHash)
+
+		updateNeeded := false
+
+		if updateFields != nil && updateFields() {
+			updateNeeded = true
+		}
+
+		if !hasExpectedLabelsAndAnnotations(labelsAndAnnotationsOf(live), labelsAndAnnotationsOf(expected)) {
+			updateNeeded = true
+			log.Info(fmt.Sprintf("Labels/Annotations of %s do not match the expected state, hence updating it", description))
+
+			live.SetLabels(combineStringMaps(live.GetLabels(), expected.GetLabels()))
+			live.SetAnnotations(combineStringMaps(live.GetAnnotations(), expected.GetAnnotations()))
+		}
+
+		if !specHashMatches(labelsAndAnnotationsOf(live), specHash) {
+			updateNeeded = true
+		}
+
+		if !updateNeeded {
+			return nil
+		}
+
+		if err := r.checkRBACEscalation(ctx, cr, live); err != nil {
+			return err
+		}
+
+		setSpecHashAnnotationOfObject(live, specHash)
+		if err := r.Client.Update(ctx, live); err != nil {
+			return err
+		}
+		if drifted {
+			r.recordDriftCorrection(cr, r.resourceKind(live))
+		}
+		result = controllerutil.OperationResultUpdated
+		return nil
+	})
+
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	return result, nil
+}
+
+// isRetriableApplyError returns true if the error is caused by a concurrent writer of the resource, in which case applyResource starts over from the latest version of the resource.
+func isRetriableApplyError(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+}
+
+// describeResource returns the kind and name of the resource, for log and error messages. The namespace is only included if the resource is not in the namespace of the RolloutManager.
+func (r *RolloutManagerReconciler) describeResource(cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) string {
+
+	kind := r.resourceKind(obj)
+	if obj.GetNamespace() != "" && obj.GetNamespace() != cr.Namespace {
+		return fmt.Sprintf("%s %s in namespace %s", kind, obj.GetName(), obj.GetNamespace())
+	}
+	return fmt.Sprintf("%s %s", kind, obj.GetName())
+}
+
+// resourceKind returns the kind of the resource, from the scheme of the operator.
+func (r *RolloutManagerReconciler) resourceKind(obj client.Object) string {
+	if gvk, err := apiutil.GVKForObject(obj, r.Scheme); err == nil {
+		return gvk.Kind
+	}
+	return reflect.TypeOf(obj).Elem().Name()
+}
+
+// labelsAndAnnotationsOf returns the labels and annotations of the resource, for use with the helpers which compare ObjectMeta.
+func labelsAndAnnotationsOf(obj client.Object) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Labels: obj.GetLabels(), Annotations: obj.GetAnnotations()}
+}
+
+// setSpecHashAnnotationOfObject sets the SpecHashAnnotation of the resource to the given hash.
+func setSpecHashAnnotationOfObject(obj client.Object, hash string) {
+	meta := labelsAndAnnotationsOf(obj)
+	setSpecHashAnnotation(&meta, hash)
+	obj.SetAnnotations(meta.Annotations)
+}
+
+// equalNormalized returns true if the expected and the live value of a field are equal once serialized, so that values which only differ in their representation (for example, nil and empty slices of fields which are omitted when empty) are considered equal.
+func equalNormalized(expected interface{}, live interface{}) bool {
+	if reflect.DeepEqual(expected, live) || (isEmptyValue(expected) && isEmptyValue(live)) {
+		return true
+	}
+
+	expectedBytes, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	liveBytes, err := json.Marshal(live)
+	if err != nil {
+		return false
+	}
+	return string(expectedBytes) == string(liveBytes)
+}
+
+// isEmptyValue returns true if the value is nil, or an empty slice or map.
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	case reflect.Ptr:
+		return value.IsNil()
+	}
+	return false
+}
+
+// resetObject sets obj (a pointer to a resource) to the zero value of its type, so that fields of a previous version of the resource do not leak into the next one fetched into it.
+func resetObject(obj client.Object) {
+	value := reflect.ValueOf(obj).Elem()
+	value.Set(reflect.Zero(value.Type()))
+}
+
+// copyObject copies src into dst, which must both be pointers to resources of the same type.
+func copyObject(dst client.Object, src client.Object) {
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(src).Elem())
+}
@@ -0,0 +1,141 @@
+package rollouts
+
+import (
+	"context"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("applyResource tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+	})
+
+	expectedRole := func() *rbacv1.Role {
+		role := &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DefaultArgoRolloutsResourceName,
+				Namespace: cr.Namespace,
+			},
+			Rules: GetPolicyRules(),
+		}
+		setRolloutsLabelsAndAnnotationsToObject(&role.ObjectMeta, cr)
+		return role
+	}
+
+	applyRole := func(expected *rbacv1.Role) (*rbacv1.Role, controllerutil.OperationResult, error) {
+		live := &rbacv1.Role{}
+		result, err := r.applyResource(ctx, cr, expected, live, true, func() bool {
+			return updatePolicyRules(&live.Rules, expected.Rules, r.describeResource(cr, live))
+		})
+		return live, result, err
+	}
+
+	It("should create the resource, and then leave it unchanged", func() {
+		live, result, err := applyRole(expectedRole())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(controllerutil.OperationResultCreated))
+		Expect(live.ResourceVersion).ToNot(BeEmpty())
+		Expect(live.Annotations).To(HaveKey(SpecHashAnnotation))
+		Expect(metav1.GetControllerOf(live).Name).To(Equal(cr.Name))
+
+		_, result, err = applyRole(expectedRole())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(controllerutil.OperationResultNone))
+	})
+
+	It("should update the modified fields of the resource, and keep the labels added by users", func() {
+		_, _, err := applyRole(expectedRole())
+		Expect(err).ToNot(HaveOccurred())
+
+		role := &rbacv1.Role{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, role)).To(Succeed())
+		role.Rules = []rbacv1.PolicyRule{}
+		role.Labels["my-label"] = "my-value"
+		delete(role.Labels, "app.kubernetes.io/name")
+		Expect(r.Client.Update(ctx, role)).To(Succeed())
+
+		live, result, err := applyRole(expectedRole())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(controllerutil.OperationResultUpdated))
+		Expect(live.Rules).To(Equal(GetPolicyRules()))
+		Expect(live.Labels).To(HaveKeyWithValue("my-label", "my-value"))
+		Expect(live.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", DefaultArgoRolloutsResourceName))
+	})
+
+	It("should retry an update which conflicts with another writer", func() {
+		conflicts := 0
+		r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(&cr).WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if conflicts == 0 {
+					conflicts++
+					return apierrors.NewConflict(schema.GroupResource{Resource: "roles"}, obj.GetName(), nil)
+				}
+				return client.Update(ctx, obj, opts...)
+			},
+		}).Build()
+
+		expected := expectedRole()
+		_, _, err := applyRole(expected)
+		Expect(err).ToNot(HaveOccurred())
+
+		expected.Rules = expected.Rules[1:]
+		live, result, err := applyRole(expected)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conflicts).To(Equal(1))
+		Expect(result).To(Equal(controllerutil.OperationResultUpdated))
+		Expect(live.Rules).To(Equal(expected.Rules))
+	})
+
+	It("should update a resource which was created concurrently", func() {
+		created := false
+		r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(&cr).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if !created {
+					created = true
+					// Another writer creates the resource in the meantime
+					Expect(client.Create(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: obj.GetName(), Namespace: obj.GetNamespace()}})).To(Succeed())
+					return apierrors.NewAlreadyExists(schema.GroupResource{Resource: "serviceaccounts"}, obj.GetName())
+				}
+				return client.Create(ctx, obj, opts...)
+			},
+		}).Build()
+
+		expected := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: cr.Namespace}}
+		setRolloutsLabelsAndAnnotationsToObject(&expected.ObjectMeta, cr)
+
+		live := &corev1.ServiceAccount{}
+		result, err := r.applyResource(ctx, cr, expected, live, true, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(controllerutil.OperationResultUpdated))
+		Expect(live.Labels).To(Equal(expected.Labels))
+	})
+
+	It("equalNormalized should ignore differences in the representation of empty values", func() {
+		Expect(equalNormalized([]rbacv1.PolicyRule{}, []rbacv1.PolicyRule(nil))).To(BeTrue())
+		Expect(equalNormalized(
+			[]rbacv1.PolicyRule{{Verbs: []string{"get"}, ResourceNames: []string{}}},
+			[]rbacv1.PolicyRule{{Verbs: []string{"get"}}})).To(BeTrue())
+		Expect(equalNormalized(map[string]string{"a": "b"}, map[string]string{})).To(BeFalse())
+	})
+})
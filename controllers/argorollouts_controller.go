@@ -18,20 +18,25 @@ package rollouts
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logr "sigs.k8s.io/controller-runtime/pkg/log"
@@ -51,6 +56,48 @@ type RolloutManagerReconciler struct {
 	// NamespaceScopedArgoRolloutsController is used to configure scope of Argo Rollouts controller
 	// If value is true then deploy namespace-scoped Argo Rollouts controller else cluster-scoped
 	NamespaceScopedArgoRolloutsController bool
+
+	// StatusClient, if set, is used to write the status of RolloutManagers, so that status writes can be rate limited separately from resource writes. Otherwise, Client is used.
+	StatusClient client.Client
+
+	// FeatureGates contains the features of the operator that were enabled or disabled via the --feature-gates flag (or FEATURE_GATES environment variable). If nil, all features use their default.
+	FeatureGates FeatureGates
+
+	// DisableBlockOwnerDeletion, if true, prevents the owner references from the resources created by the operator to their RolloutManager from setting blockOwnerDeletion. See setControllerReference.
+	DisableBlockOwnerDeletion bool
+
+	// Recorder, if set, is used to record Events about the actions of the operator. See recordTargetNamespaceEvent.
+	Recorder record.EventRecorder
+
+	// ResourcePolicies are checked against the resources rendered for each RolloutManager (after the ResourceTransformer, if any): resources which violate them are not applied. See ResourcePolicy.
+	ResourcePolicies []ResourcePolicy
+
+	// ResourceTransformer, if set, mutates the resources rendered for each RolloutManager before they are applied. See ResourceTransformer.
+	ResourceTransformer ResourceTransformer
+
+	// AccessReviewer, if set, is used to verify that the operator is allowed to grant the rules of the Roles and ClusterRoles that it creates or updates. See checkRBACEscalation.
+	AccessReviewer AccessReviewer
+
+	// LifecycleNotifier, if set, is notified of the lifecycle events of RolloutManagers (created, upgraded, degraded and deleted). See notifyLifecycleEvents.
+	LifecycleNotifier LifecycleNotifier
+
+	// ClusterScopedLockNamespace is the namespace of the Lease which is held by the single cluster-scoped RolloutManager that is reconciled (usually the namespace of the operator). If empty, DefaultClusterScopedLockNamespace is used. See acquireClusterScopedLock.
+	ClusterScopedLockNamespace string
+
+	// StrictNamespaceScoped, if true (and NamespaceScopedArgoRolloutsController is true), prevents the operator from making any request to cluster-scoped APIs, so that it only requires permissions in the namespaces of its RolloutManagers: the aggregate ClusterRoles are not managed, and features which require cluster-scoped resources are reported by the ClusterAPIRequired condition, rather than reconciled. See clusterAPIDisabled.
+	StrictNamespaceScoped bool
+
+	// ImageVerifier, if set, is used to verify that the Rollouts controller image exists before the Rollouts controller Deployment is updated to it, for RolloutManagers which set VerifyImage. See verifyRolloutsImage.
+	ImageVerifier ImageVerifier
+
+	// ignoreRestartBudget, if true, applies updates of the Rollouts controller Deployment without deferring them by the RestartBudget of the RolloutManager (for example, when detecting drift). See deferControllerRestart.
+	ignoreRestartBudget bool
+
+	// dryRun, if true, indicates that the writes of the reconciliation are not performed (for example, when detecting drift), so they are not reported in the metrics of the operator. See recordDriftCorrection.
+	dryRun bool
+
+	// faultInjector, if set (only by tests), makes steps of the reconciliation fail with the errors that it returns. See injectFault.
+	faultInjector faultInjector
 }
 
 var log = logr.Log.WithName("rollouts-controller")
@@ -64,7 +111,7 @@ const (
 //+kubebuilder:rbac:groups=argoproj.io,resources=rolloutmanagers/finalizers,verbs=update
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups="",resources=configmaps;endpoints;events;pods;namespaces;secrets;serviceaccounts;services;services/finalizers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps;endpoints;events;pods;namespaces;resourcequotas;secrets;serviceaccounts;services;services/finalizers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=podtemplates;deployments;replicasets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=deployments/finalizers,verbs=update
 //+kubebuilder:rbac:groups="",resources=deployments,verbs=get;list;watch
@@ -72,24 +119,34 @@ const (
 //+kubebuilder:rbac:groups="",resources=podtemplates,verbs=get;list;watch
 //+kubebuilder:rbac:groups="appmesh.k8s.aws",resources=virtualnodes;virtualrouters,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups="appmesh.k8s.aws",resources=virtualservices,verbs=get;list;watch
+//+kubebuilder:rbac:groups="appmesh.k8s.aws",resources=virtualnodes/status;virtualrouters/status;virtualservices/status,verbs=get;list;watch
 //+kubebuilder:rbac:groups="argoproj.io",resources=analysisruns;analysisruns/finalizers;experiments;experiments/finalizers,verbs=create;get;list;watch;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups="argoproj.io",resources=analysistemplates,verbs=create;get;list;watch;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups="argoproj.io",resources=clusteranalysistemplates,verbs=create;get;list;watch;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups="argoproj.io",resources=rollouts;rollouts/finalizers;rollouts/status;rollouts/scale,verbs=create;get;list;watch;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups="batch",resources=jobs,verbs=create;get;list;watch;update;patch;delete
-//+kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs=create;get;update
-//+kubebuilder:rbac:groups="elbv2.k8s.aws",resources=targetgroupbindings,verbs=list;get
+//+kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs=create;get;list;watch;update;delete
+//+kubebuilder:rbac:groups="elbv2.k8s.aws",resources=targetgroupbindings,verbs=list;get;watch
 //+kubebuilder:rbac:groups="extensions",resources=ingresses,verbs=create;get;list;watch;patch
+//+kubebuilder:rbac:groups="extensions",resources=ingresses/status,verbs=get;list;watch
 //+kubebuilder:rbac:groups="getambassador.io",resources=ambassadormappings;mappings,verbs=create;watch;get;update;list;delete
 //+kubebuilder:rbac:groups="networking.istio.io",resources=destinationrules;virtualservices,verbs=watch;get;update;patch;list
-//+kubebuilder:rbac:groups="networking.k8s.io",resources=ingresses,verbs=create;watch;get;update;patch;list
-//+kubebuilder:rbac:groups="split.smi-spec.io",resources=trafficsplits,verbs=create;watch;get;update;patch
-//+kubebuilder:rbac:groups="traefik.containo.us",resources=traefikservices,verbs=watch;get;update
+//+kubebuilder:rbac:groups="networking.istio.io",resources=destinationrules/status;virtualservices/status,verbs=get;list;watch
+//+kubebuilder:rbac:groups="networking.k8s.io",resources=ingresses,verbs=create;watch;get;update;patch;list;delete
+//+kubebuilder:rbac:groups="networking.k8s.io",resources=ingresses/status,verbs=get;list;watch
+//+kubebuilder:rbac:groups="split.smi-spec.io",resources=trafficsplits,verbs=create;watch;get;update;patch;list
+//+kubebuilder:rbac:groups="traefik.containo.us",resources=traefikservices,verbs=watch;get;update;list
+//+kubebuilder:rbac:groups="traefik.io",resources=traefikservices,verbs=watch;get;list
 //+kubebuilder:rbac:groups="x.getambassador.io",resources=ambassadormappings;mappings,verbs=create;watch;get;update;list;delete
-//+kubebuilder:rbac:groups="apisix.apache.org",resources=apisixroutes,verbs=watch;get;update
+//+kubebuilder:rbac:groups="apisix.apache.org",resources=apisixroutes,verbs=watch;get;update;list
+//+kubebuilder:rbac:groups="apisix.apache.org",resources=apisixroutes/status,verbs=get;list;watch
 //+kubebuilder:rbac:groups="route.openshift.io",resources=routes,verbs=create;watch;get;update;patch;list
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=create;watch;get;update;patch;list
 //+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=create;watch;get;update;patch;list;delete
+//+kubebuilder:rbac:groups=flowcontrol.apiserver.k8s.io,resources=flowschemas,verbs=create;watch;get;update;patch;list;delete
+//+kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=create;watch;get;update;patch;list;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -101,24 +158,47 @@ func (r *RolloutManagerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	reqLogger.Info("Reconciling RolloutManager")
 
 	// First retrieve the Namespace of the request: if it's being deleted, no more work for us.
-	rolloutManagerNamespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: req.Namespace}}
-	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(&rolloutManagerNamespace), &rolloutManagerNamespace); err != nil {
-		if apierrors.IsNotFound(err) { // If Namespace doesn't exist, our work is done
-			reqLogger.Info("Skipping reconciliation of RolloutManager as request Namespace no longer exists")
-
-			// Ensure that any cluster-scoped resources are removed, since the RolloutManager was deleted.
-			if err := r.removeClusterScopedResourcesIfApplicable(ctx); err != nil {
-				reqLogger.Error(err, "unable to remove cluster scoped resources for non-existing Namespace")
-				return ctrl.Result{}, err
+	// Namespaces are cluster-scoped: when the cluster API is disabled, a deleted Namespace is detected via its RolloutManager (which no longer exists), and a Namespace being deleted via the errors of the writes into it.
+	if !r.clusterAPIDisabled() {
+		rolloutManagerNamespace, err := fetchObjectMetadata(ctx, r.Client, namespaceGVK, "", req.Namespace)
+		if err != nil {
+			if apierrors.IsNotFound(err) { // If Namespace doesn't exist, our work is done
+				reqLogger.Info("Skipping reconciliation of RolloutManager as request Namespace no longer exists")
+
+				deleteRolloutManagerMetrics(req.Namespace, req.Name)
+
+				// Ensure that any cluster-scoped resources are removed, since the RolloutManager was deleted.
+				if err := r.removeClusterScopedResourcesIfApplicable(ctx); err != nil {
+					reqLogger.Error(err, "unable to remove cluster scoped resources for non-existing Namespace")
+					return ctrl.Result{}, err
+				}
+
+				if err := r.removeRolloutUserRoles(ctx, req.Namespace); err != nil {
+					reqLogger.Error(err, "unable to remove rollout-user Roles for non-existing Namespace")
+					return ctrl.Result{}, err
+				}
+
+				if err := r.removeRolloutsFlowSchema(ctx, req.Namespace); err != nil {
+					reqLogger.Error(err, "unable to remove FlowSchema for non-existing Namespace")
+					return ctrl.Result{}, err
+				}
+
+				if err := r.removeTargetNamespaces(ctx, req.Namespace, req.Name, ""); err != nil {
+					reqLogger.Error(err, "unable to remove target namespaces for non-existing Namespace")
+					return ctrl.Result{}, err
+				}
+
+				r.notifyRolloutManagerDeleted(ctx, req.Namespace, req.Name)
+
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{}, err // Any other error, return it
+		} else {
+			// If the Namespace is in the process of being deleted, no more work required for us: resources can no longer be created in it, so only the status of the RolloutManager is updated.
+			if rolloutManagerNamespace.DeletionTimestamp != nil {
+				reqLogger.Info("Skipping reconciliation of RolloutManager as request Namespace is being deleted")
+				return ctrl.Result{}, r.setNamespaceTerminatingStatus(ctx, req)
 			}
-
-			return ctrl.Result{}, nil
-		}
-		return ctrl.Result{}, err // Any other error, return it
-	} else {
-		// If the Namespace is in the process of being deleted, no more work required for us.
-		if rolloutManagerNamespace.DeletionTimestamp != nil {
-			return ctrl.Result{}, nil
 		}
 	}
 
@@ -134,6 +214,28 @@ func (r *RolloutManagerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 				return ctrl.Result{}, err
 			}
 
+			// rollout-user Roles in other namespaces are not owned by the RolloutManager, so they are also deleted manually.
+			if err := r.removeRolloutUserRoles(ctx, req.Namespace); err != nil {
+				reqLogger.Error(err, "unable to remove rollout-user Roles for non-existing RolloutManager")
+				return ctrl.Result{}, err
+			}
+
+			// The FlowSchema is cluster-scoped, so it is also deleted manually.
+			if err := r.removeRolloutsFlowSchema(ctx, req.Namespace); err != nil {
+				reqLogger.Error(err, "unable to remove FlowSchema for non-existing RolloutManager")
+				return ctrl.Result{}, err
+			}
+
+			// Target namespaces are cluster-scoped, so they are also deleted manually, along with the RolloutManager and the Rollouts controller in them.
+			if err := r.removeTargetNamespaces(ctx, req.Namespace, req.Name, ""); err != nil {
+				reqLogger.Error(err, "unable to remove target namespaces for non-existing RolloutManager")
+				return ctrl.Result{}, err
+			}
+
+			deleteRolloutManagerMetrics(req.Namespace, req.Name)
+
+			r.notifyRolloutManagerDeleted(ctx, req.Namespace, req.Name)
+
 			// Return and don't requeue
 			return reconcile.Result{}, nil
 		}
@@ -143,25 +245,101 @@ func (r *RolloutManagerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	res, reconcileErr := r.reconcileRolloutsManager(ctx, *rolloutManager)
 
+	var policyErr *resourcePolicyViolationError
+	if errors.As(reconcileErr, &policyErr) {
+		// Policy violations are not resolved by retrying: the RolloutManager (or the resource policies of the operator) must be changed
+		res.condition = createCondition(reconcileErr.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonPolicyViolation)
+		res.policyViolations = policyErr.violations
+		reconcileErr = nil
+	}
+
+	var escalationErr *rbacEscalationDeniedError
+	if errors.As(reconcileErr, &escalationErr) {
+		// The error is still returned, so that the reconciliation is retried: the operator may be granted the missing permissions at any time
+		res.condition = createCondition(reconcileErr.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonRBACEscalationDenied)
+		res.rbacEscalationDenials = escalationErr.deniedRules
+	}
+
+	if apierrors.HasStatusCause(reconcileErr, corev1.NamespaceTerminatingCause) {
+		// The namespace started being deleted during the reconciliation: retrying would only fail again, until the namespace is gone
+		res.condition = createCondition(reconcileErr.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonNamespaceTerminating)
+		reconcileErr = nil
+	}
+
+	// The consecutive failures of the reconciliation are recorded on the status, and reset once it succeeds
+	res.recordFailure = true
+	res.reconcileErr = reconcileErr
+
+	// When the components are not reconciled by their own controllers, their conditions (if any, from when they were) no longer apply
+	res.removeComponentConditions = !r.FeatureGates.Enabled(ComponentControllers)
+
 	// Set the condition/phase on the RolloutManager status  (before we check the error from reconcileRolloutManager, below)
-	if err := updateStatusConditionOfRolloutManager(ctx, res, rolloutManager, r.Client, log); err != nil {
+	// - The status is written even if the operator is shutting down, so that it is not left partially written.
+	previousStatus := *rolloutManager.Status.DeepCopy()
+	statusCtx, cancel := statusUpdateContext(ctx)
+	defer cancel()
+	if err := r.injectFault(reconcileStepStatus, *rolloutManager); err != nil {
 		log.Error(err, "unable to update status of RolloutManager")
 		return reconcile.Result{}, err
 	}
+	if err := updateStatusConditionOfRolloutManager(statusCtx, res, rolloutManager, r.statusClient(), log); err != nil {
+		log.Error(err, "unable to update status of RolloutManager")
+		return reconcile.Result{}, err
+	}
+
+	updateRolloutManagerMetrics(*rolloutManager)
+
+	r.notifyLifecycleEvents(ctx, previousStatus, *rolloutManager)
 
 	// Next return the reconcileErr if applicable
 	if reconcileErr != nil {
 		return reconcile.Result{}, reconcileErr
 	}
 
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: nextRequeueAfter(rolloutManager.Status.Phase, res.requeueAfter)}, nil
+}
+
+// statusClient returns the client that is used to write the status of RolloutManagers.
+func (r *RolloutManagerReconciler) statusClient() client.Client {
+	if r.StatusClient != nil {
+		return r.StatusClient
+	}
+	return r.Client
+}
+
+// setNamespaceTerminatingStatus sets the NamespaceTerminating reason on the status of a RolloutManager whose namespace is being deleted, instead of reconciling its resources (which would be rejected by the API server). The status is only written if it changed, so that the RolloutManager is not updated repeatedly while the namespace is deleted.
+func (r *RolloutManagerReconciler) setNamespaceTerminatingStatus(ctx context.Context, req ctrl.Request) error {
+
+	rolloutManager := &rolloutsmanagerv1alpha1.RolloutManager{}
+	if err := r.Client.Get(ctx, req.NamespacedName, rolloutManager); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	res := reconcileStatusResult{
+		condition: createCondition(fmt.Sprintf("Namespace %s is being deleted: the resources of the RolloutManager are no longer reconciled", req.Namespace), rolloutsmanagerv1alpha1.RolloutManagerReasonNamespaceTerminating),
+	}
+
+	statusCtx, cancel := statusUpdateContext(ctx)
+	defer cancel()
+	if err := updateStatusConditionOfRolloutManager(statusCtx, res, rolloutManager, r.statusClient(), log); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *RolloutManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	bld := ctrl.NewControllerManagedBy(mgr)
 
-	bld.For(&rolloutsmanagerv1alpha1.RolloutManager{})
+	// Updates which record the failure of a reconciliation (including the phase/conditions set by the same reconciliation) are ignored, so that the reconciliation is retried with the backoff below. Changes of the generation or metadata are never ignored (see isReconcileFailureUpdate).
+	bld.For(&rolloutsmanagerv1alpha1.RolloutManager{}, builder.WithPredicates(predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !isReconcileFailureUpdate(e)
+		},
+	}))
+
+	// Failed reconciliations are retried with an exponential backoff, with jitter (see newFailureRateLimiter)
+	bld.WithOptions(controller.Options{RateLimiter: newFailureRateLimiter()})
 
 	// If the .spec of any RolloutManager changes (or a RM is created/deleted), inform the other RolloutManagers on the cluster
 	bld.Watches(
@@ -169,35 +347,129 @@ func (r *RolloutManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		handler.EnqueueRequestsFromMapFunc(r.enqueueOtherRolloutManagersExceptObj),
 		builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, createdOrDeletedPredicate())))
 
-	// Watch for changes to ConfigMap sub-resources owned by RolloutManager.
-	bld.Owns(&corev1.ConfigMap{})
+	// When a RolloutManager in the target namespace of another RolloutManager changes (including its status), or is deleted, inform the latter, which reports its phase and condition, or recreates it (see reconcileTargetNamespace)
+	bld.Watches(
+		&rolloutsmanagerv1alpha1.RolloutManager{},
+		handler.EnqueueRequestsFromMapFunc(enqueueTargetNamespaceOwner))
+
+	// When the ComponentControllers feature is enabled, the resources of the RBAC, config and monitoring components are watched by their own controllers (see setupComponentControllers)
+	componentControllers := r.FeatureGates.Enabled(ComponentControllers)
 
-	// Watch for changes to Secret sub-resources owned by RolloutManager.
-	bld.Owns(&corev1.Secret{})
+	if !componentControllers {
+		// Watch for changes to ConfigMap sub-resources owned by RolloutManager.
+		bld.Owns(&corev1.ConfigMap{})
+
+		// Watch for changes to Secret sub-resources owned by RolloutManager.
+		bld.Owns(&corev1.Secret{})
+	}
 
-	// Watch for changes to Service sub-resources owned by RolloutManager.
-	bld.Owns(&corev1.Service{})
+	// When a Secret or ConfigMap that is referenced by a RolloutManager (via .spec.env or .spec.fileMounts) changes, reconcile that RolloutManager, so that the Rollouts controller is restarted to pick up the change (see ReferencedObjectsHashAnnotation).
+	bld.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.enqueueReferencingRolloutManagers))
+	bld.Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.enqueueReferencingRolloutManagers))
+
+	if !componentControllers && r.FeatureGates.Enabled(NotificationSecretOnDemand) {
+		// When the notification ConfigMap is created or deleted, the notification Secret is created, or deleted if empty (see isNotificationSecretNeeded)
+		bld.Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.enqueueRolloutManagersInNamespace), builder.WithPredicates(predicate.And(predicate.NewPredicateFuncs(isNotificationConfigMap), createdOrDeletedPredicate())))
+	}
+
+	if !componentControllers {
+		// Watch for changes to Service sub-resources owned by RolloutManager.
+		bld.Owns(&corev1.Service{})
+	}
+
+	// Watch for changes to ServiceAccount sub-resources owned by RolloutManager.
+	bld.Owns(&corev1.ServiceAccount{})
 
 	// Watch for changes to Deployment sub-resources owned by RolloutManager.
 	bld.Owns(&appsv1.Deployment{})
 
-	// Watch for changes to Role sub-resources owned by RolloutManager.
-	bld.Owns(&rbacv1.Role{})
+	// Watch for changes to the Ingress of the dashboard owned by RolloutManager.
+	bld.Owns(&networkingv1.Ingress{})
 
-	// Watch for changes to RoleBinding sub-resources owned by RolloutManager.
-	bld.Owns(&rbacv1.RoleBinding{})
+	if !componentControllers {
+		// Watch for changes to Role sub-resources owned by RolloutManager.
+		bld.Owns(&rbacv1.Role{})
+
+		// Watch for changes to RoleBinding sub-resources owned by RolloutManager.
+		bld.Owns(&rbacv1.RoleBinding{})
+	}
 
-	// We can't use Owns for ClusterRole/ClusterRoleBinding, because namespace-scoped resources like RolloutManager cannot own cluster-scoped resources like ClusterRole/ClusterRoleBinding.
-	// Instead, we watch all ClusterRoles/ClusterRoleBindings with the name DefaultArgoRolloutsResourceName, and when they change, we inform all RolloutManagers
-	bld.Watches(&rbacv1.ClusterRole{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
-		return object.GetName() == DefaultArgoRolloutsResourceName
-	})))
+	// When the cluster API is disabled, cluster-scoped resources are neither watched, nor reconciled (see clusterAPIDisabled)
+	clusterAPIDisabled := r.clusterAPIDisabled()
 
-	bld.Watches(&rbacv1.ClusterRoleBinding{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
-		return object.GetName() == DefaultArgoRolloutsResourceName
-	})))
+	if !componentControllers && !clusterAPIDisabled {
+		// We can't use Owns for ClusterRole/ClusterRoleBinding, because namespace-scoped resources like RolloutManager cannot own cluster-scoped resources like ClusterRole/ClusterRoleBinding.
+		// Instead, we watch all ClusterRoles/ClusterRoleBindings with the name DefaultArgoRolloutsResourceName (or DefaultArgoRolloutsDashboardResourceName), and when they change, we inform all RolloutManagers
+		bld.Watches(&rbacv1.ClusterRole{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			return object.GetName() == DefaultArgoRolloutsResourceName || object.GetName() == DefaultArgoRolloutsDashboardResourceName
+		})))
 
-	if crdExists, err := r.doesCRDExist(mgr.GetConfig(), serviceMonitorsCRDName); err != nil {
+		bld.Watches(&rbacv1.ClusterRoleBinding{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			return object.GetName() == DefaultArgoRolloutsResourceName || object.GetName() == DefaultArgoRolloutsDashboardResourceName
+		})))
+	}
+
+	// When a Rollouts controller Deployment which is not managed by the operator is created/deleted, inform all RolloutManagers, so that conflicting installations are reported.
+	bld.Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(
+		predicate.NewPredicateFuncs(isNonOperatorRolloutsDeployment), createdOrDeletedPredicate()))
+
+	// When a Namespace is created, inform all RolloutManagers, so that the rollout-user Role can be created in the new Namespace (unless the rbac component has its own controller).
+	// When a Namespace starts being deleted, inform all RolloutManagers, so that they stop reconciling resources into it, and delete their rollout-user Roles from it (see removeStaleRolloutUserRoles).
+	// When the Pod Security Standard enforced on a Namespace changes, inform all RolloutManagers, so that the Rollouts controller pod is updated to comply with it (see applyPodSecurityLevel).
+	// Only the metadata of Namespaces is watched, as that is all the operator uses.
+	if !clusterAPIDisabled {
+		bld.WatchesMetadata(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(createEvent event.CreateEvent) bool {
+				return !componentControllers
+			},
+			DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+				return false
+			},
+			GenericFunc: func(genericEvent event.GenericEvent) bool {
+				return false
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return e.ObjectOld.GetLabels()[PodSecurityEnforceLabel] != e.ObjectNew.GetLabels()[PodSecurityEnforceLabel] ||
+					(e.ObjectOld.GetDeletionTimestamp() == nil) != (e.ObjectNew.GetDeletionTimestamp() == nil)
+			},
+		}))
+	}
+
+	// When a Rollouts controller pod is deleted or evicted, record an Event on its RolloutManager if it was not replaced by the operator (see recordControllerPodDisruption).
+	bld.Watches(&corev1.Pod{}, r.controllerPodDisruptionHandler())
+
+	// When the Rollouts controller container is restarted, report the crash on its RolloutManager (see detectControllerCrashes).
+	bld.Watches(&corev1.Pod{}, r.controllerCrashHandler())
+
+	// When a Rollouts controller pod is deleted, clean up the leader election Lease if it held it (see reconcileRolloutsLeaderElectionLease).
+	bld.Watches(&corev1.Pod{}, r.controllerPodDeletedHandler())
+
+	if !clusterAPIDisabled {
+		if crdExists, err := r.doesCRDExist(mgr.GetConfig(), clusterProxyGVR); err != nil {
+			return err
+		} else if crdExists {
+			// When the cluster-wide proxy of OpenShift changes, inform all RolloutManagers, so that it is applied to their Rollouts controllers (see reconcileClusterProxy).
+			bld.Watches(newClusterProxy(), handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				return object.GetName() == ClusterProxyName
+			})))
+		}
+	}
+
+	if crdExists, err := r.doesCRDExist(mgr.GetConfig(), certificateGVR); err != nil {
+		return err
+	} else if crdExists {
+		// When the Certificate of the dashboard is issued, the dashboard Ingress is created (see reconcileDashboardCertificate). We only attempt to own Certificates if cert-manager is installed on startup.
+		bld.Owns(newCertificate())
+	}
+
+	if componentControllers {
+		if err := bld.Complete(r); err != nil {
+			return err
+		}
+		return r.setupComponentControllers(mgr)
+	}
+
+	if crdExists, err := r.doesCRDExist(mgr.GetConfig(), monitoringv1.SchemeGroupVersion.WithResource(monitoringv1.ServiceMonitorName)); err != nil {
 		return err
 	} else if crdExists {
 		// We only attempt to own ServiceMonitor if it exists on the cluster on startup
@@ -276,10 +548,30 @@ func (r *RolloutManagerReconciler) enqueueAllRolloutManagers(ctx context.Context
 
 }
 
-// doesCRDExist checks if a CRD is present in the cluster, by using the discovery client.
+// enqueueRolloutManagersInNamespace returns the RolloutManagers in the namespace of the object, so that they are reconciled when an object that they do not own, but depend on, changes.
+func (r *RolloutManagerReconciler) enqueueRolloutManagersInNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+
+	var rolloutManagerList rolloutsmanagerv1alpha1.RolloutManagerList
+
+	if err := r.Client.List(ctx, &rolloutManagerList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.Error(err, "Unable to list RolloutManagers in enqueueRolloutManagersInNamespace")
+		return []reconcile.Request{}
+	}
+
+	var res []reconcile.Request
+
+	for idx := range rolloutManagerList.Items {
+		rm := rolloutManagerList.Items[idx]
+		res = append(res, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&rm)})
+	}
+
+	return res
+}
+
+// doesCRDExist checks if the resource of a CRD (for example, 'proxies' of 'config.openshift.io/v1') is served by the cluster, by using the discovery client.
 //
 // NOTE: this function should only be called from SetupWithManager. There are more efficient methods to determine this, elsewhere.
-func (r *RolloutManagerReconciler) doesCRDExist(cfg *rest.Config, crdName string) (bool, error) {
+func (r *RolloutManagerReconciler) doesCRDExist(cfg *rest.Config, gvr schema.GroupVersionResource) (bool, error) {
 
 	// Idealy we would use client.Client to retrieve the CRD, here, but since the manager has not yet started, we don't have access to the client from the manager. We would need to convert the rest.Config into a client.Client, and it's easier to use
 
@@ -287,7 +579,12 @@ func (r *RolloutManagerReconciler) doesCRDExist(cfg *rest.Config, crdName string
 	if err != nil {
 		return false, err
 	}
-	apiResources, err := discoveryClient.ServerResourcesForGroupVersion("monitoring.coreos.com/v1")
+	return isResourceServed(discoveryClient, gvr)
+}
+
+// isResourceServed returns true if the discovery client reports the (plural) resource in its group/version.
+func isResourceServed(discoveryClient discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (bool, error) {
+	apiResources, err := discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return false, nil
@@ -295,7 +592,7 @@ func (r *RolloutManagerReconciler) doesCRDExist(cfg *rest.Config, crdName string
 		return false, err
 	}
 	for _, resource := range apiResources.APIResources {
-		if resource.Name == crdName {
+		if resource.Name == gvr.Resource {
 			return true, nil
 		}
 	}
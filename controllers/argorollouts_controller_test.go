@@ -14,9 +14,11 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -227,14 +229,14 @@ var _ = Describe("RolloutManagerReconciler tests", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(res2.Requeue).Should(BeFalse(), "reconcile should not requeue request")
 
-			By("2nd RM: Check if RolloutManager's Status.Conditions are set.")
+			By("2nd RM: Check if RolloutManager's Status.Conditions are set, naming the 1st RolloutManager.")
 			Expect(r.Client.Get(ctx, types.NamespacedName{Name: rm2.Name, Namespace: rm2.Namespace}, rm2)).To(Succeed())
 			Expect(rm2.Status.Conditions[0].Type == rolloutsmanagerv1alpha1.RolloutManagerConditionType &&
 				rm2.Status.Conditions[0].Reason == rolloutsmanagerv1alpha1.RolloutManagerReasonMultipleClusterScopedRolloutManager &&
-				rm2.Status.Conditions[0].Message == UnsupportedRolloutManagerConfiguration &&
+				rm2.Status.Conditions[0].Message == MultipleClusterScopedRolloutManagersMessage(types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}) &&
 				rm2.Status.Conditions[0].Status == metav1.ConditionFalse).To(BeTrue())
 
-			By("1st RM: Reconcile 1st RolloutManager's once again and check it is also failed.")
+			By("1st RM: Reconcile 1st RolloutManager's once again and check it still works, as it holds the cluster-scoped lock.")
 			res, err = r.Reconcile(ctx, req)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(res.Requeue).Should(BeFalse(), "reconcile should not requeue request")
@@ -242,9 +244,9 @@ var _ = Describe("RolloutManagerReconciler tests", func() {
 			By("1st RM: Check if RolloutManager's Status.Conditions are set.")
 			Expect(r.Client.Get(ctx, types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}, rm)).To(Succeed())
 			Expect(rm.Status.Conditions[0].Type == rolloutsmanagerv1alpha1.RolloutManagerConditionType &&
-				rm.Status.Conditions[0].Reason == rolloutsmanagerv1alpha1.RolloutManagerReasonMultipleClusterScopedRolloutManager &&
-				rm.Status.Conditions[0].Message == UnsupportedRolloutManagerConfiguration &&
-				rm.Status.Conditions[0].Status == metav1.ConditionFalse).To(BeTrue())
+				rm.Status.Conditions[0].Reason == rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess &&
+				rm.Status.Conditions[0].Message == "" &&
+				rm.Status.Conditions[0].Status == metav1.ConditionTrue).To(BeTrue())
 
 			By("1st RM: Delete 1st RolloutManager")
 			Expect(r.Client.Delete(ctx, rm)).To(Succeed())
@@ -761,6 +763,7 @@ func validateService(k8sClient client.Client, rolloutsManager *rolloutsmanagerv1
 	Expect(service.Labels["app.kubernetes.io/name"]).To(Equal(DefaultArgoRolloutsMetricsServiceName))
 	Expect(service.Labels["app.kubernetes.io/part-of"]).To(Equal(DefaultArgoRolloutsResourceName))
 	Expect(service.Labels["app.kubernetes.io/component"]).To(Equal("server"))
+	Expect(service.Labels[RolloutManagerLabel]).To(Equal(rolloutsManager.Name))
 
 	By("Verify that Service has correct Ports.")
 	Expect(service.Spec.Ports).To(Equal([]corev1.ServicePort{
@@ -810,7 +813,11 @@ func validateDeployment(k8sClient client.Client, rolloutsManager *rolloutsmanage
 		}}))
 
 	By("Verify that Deployment Template has correct Template.")
-	Expect(depl.Spec.Template.Labels).To(Equal(map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName}))
+	Expect(depl.Spec.Template.Labels).To(Equal(map[string]string{
+		DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName,
+		ManagedByLabel:             ManagedByLabelValue,
+		VersionLabel:               DefaultArgoRolloutsVersion,
+	}))
 
 	By("Verify that Deployment Template has correct NodeSelector.")
 	Expect(depl.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
@@ -827,17 +834,211 @@ func validateDeployment(k8sClient client.Client, rolloutsManager *rolloutsmanage
 
 func ensureLabels(object *metav1.ObjectMeta) {
 	GinkgoHelper()
-	Expect(len(object.Labels)).To(Equal(3))
+	Expect(len(object.Labels)).To(Equal(5))
 	Expect(object.Labels["app.kubernetes.io/name"]).To(Equal(DefaultArgoRolloutsResourceName))
 	Expect(object.Labels["app.kubernetes.io/part-of"]).To(Equal(DefaultArgoRolloutsResourceName))
 	Expect(object.Labels["app.kubernetes.io/component"]).To(Equal(DefaultArgoRolloutsResourceName))
+	Expect(object.Labels[ManagedByLabel]).To(Equal(ManagedByLabelValue))
+	Expect(object.Labels[VersionLabel]).To(Equal(DefaultArgoRolloutsVersion))
 }
 
 func ensureAggregateLabels(object *metav1.ObjectMeta, aggregationType string) {
 	GinkgoHelper()
-	Expect(len(object.Labels)).To(Equal(4))
+	Expect(len(object.Labels)).To(Equal(6))
 	Expect(object.Labels["app.kubernetes.io/name"]).To(Equal(object.Name))
 	Expect(object.Labels["app.kubernetes.io/part-of"]).To(Equal(DefaultArgoRolloutsResourceName))
 	Expect(object.Labels["app.kubernetes.io/component"]).To(Equal("aggregate-cluster-role"))
 	Expect(object.Labels["rbac.authorization.k8s.io/"+aggregationType]).To(Equal("true"))
+	Expect(object.Labels[ManagedByLabel]).To(Equal(ManagedByLabelValue))
+	Expect(object.Labels[VersionLabel]).To(Equal(DefaultArgoRolloutsVersion))
 }
+
+var _ = Describe("RolloutManagerReconciler StatusClient tests", func() {
+
+	It("should write the status of the RolloutManager via StatusClient, if set", func() {
+		ctx := context.Background()
+		rm := makeTestRolloutManager()
+
+		r := makeTestReconciler(rm)
+		Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+		defer os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+
+		statusUpdates := 0
+		r.StatusClient = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				statusUpdates++
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		})
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(statusUpdates).To(Equal(1))
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(rm), rm)).To(Succeed())
+		Expect(rm.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess))
+	})
+})
+
+var _ = Describe("RolloutManagerReconciler spec hash tests", func() {
+
+	var (
+		ctx     context.Context
+		rm      *rolloutsmanagerv1alpha1.RolloutManager
+		r       *RolloutManagerReconciler
+		req     reconcile.Request
+		updates []string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		rm = makeTestRolloutManager()
+
+		r = makeTestReconciler(rm)
+		Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		updates = nil
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				updates = append(updates, fmt.Sprintf("%T %s", obj, obj.GetName()))
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+
+		req = reconcile.Request{NamespacedName: types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should set the spec hash annotation on the resources it creates, and not update them when reconciling an unchanged RolloutManager", func() {
+
+		for _, obj := range []client.Object{
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: rm.Namespace}},
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName}},
+			&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: rm.Namespace}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsMetricsServiceName, Namespace: rm.Namespace}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationSecretName, Namespace: rm.Namespace}},
+		} {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+			Expect(obj.GetAnnotations()).To(HaveKey(SpecHashAnnotation), "%T %s", obj, obj.GetName())
+		}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(BeEmpty())
+	})
+
+	It("should revert a resource that was modified by another actor, even though its spec hash is unchanged", func() {
+
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: rm.Namespace}}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(sa), sa)).To(Succeed())
+		sa.Labels["app.kubernetes.io/name"] = "modified"
+		Expect(r.Client.Update(ctx, sa)).To(Succeed())
+		updates = nil
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(Equal([]string{"*v1.ServiceAccount " + DefaultArgoRolloutsResourceName}))
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(sa), sa)).To(Succeed())
+		Expect(sa.Labels["app.kubernetes.io/name"]).To(Equal(DefaultArgoRolloutsResourceName))
+	})
+
+	It("should update the resources, and their spec hash annotation, when the expected state changes", func() {
+
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: rm.Namespace}}
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(sa), sa)).To(Succeed())
+		oldHash := sa.Annotations[SpecHashAnnotation]
+
+		Expect(r.Client.Get(ctx, req.NamespacedName, rm)).To(Succeed())
+		rm.Spec.AdditionalMetadata = &rolloutsmanagerv1alpha1.ResourceMetadata{Labels: map[string]string{"my-key": "my-value"}}
+		Expect(r.Client.Update(ctx, rm)).To(Succeed())
+		updates = nil
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(ContainElement("*v1.ServiceAccount " + DefaultArgoRolloutsResourceName))
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(sa), sa)).To(Succeed())
+		Expect(sa.Labels).To(HaveKeyWithValue("my-key", "my-value"))
+		Expect(sa.Annotations[SpecHashAnnotation]).ToNot(Equal(oldHash))
+	})
+})
+
+var _ = Describe("RolloutManagerReconciler terminating namespace tests", func() {
+
+	var (
+		ctx context.Context
+		rm  *rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+		req reconcile.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		rm = makeTestRolloutManager()
+		r = makeTestReconciler(rm)
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}}
+	})
+
+	It("should not create resources in a namespace that is being deleted, and set the NamespaceTerminating reason once", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: rm.Namespace, Finalizers: []string{"example.com/finalizer"}}}
+		Expect(r.Client.Create(ctx, namespace)).To(Succeed())
+		Expect(r.Client.Delete(ctx, namespace)).To(Succeed())
+
+		statusUpdates := 0
+		r.StatusClient = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				statusUpdates++
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		})
+
+		for i := 0; i < 2; i++ {
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeZero())
+		}
+		Expect(statusUpdates).To(Equal(1))
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(rm), rm)).To(Succeed())
+		Expect(rm.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonNamespaceTerminating))
+		Expect(rm.Status.Conditions[0].Status).To(Equal(metav1.ConditionFalse))
+
+		err := fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should set the NamespaceTerminating reason, rather than return an error, if the namespace starts being deleted during the reconciliation", func() {
+		Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, isDeployment := obj.(*appsv1.Deployment); isDeployment {
+					err := errors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, obj.GetName(), nil)
+					err.ErrStatus.Details.Causes = []metav1.StatusCause{{Type: corev1.NamespaceTerminatingCause}}
+					return err
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(rm), rm)).To(Succeed())
+		Expect(rm.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonNamespaceTerminating))
+	})
+})
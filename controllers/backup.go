@@ -0,0 +1,414 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	backupKindConfigMap = "configmap"
+	backupKindSecret    = "secret"
+
+	// backupGenerationPrefix is the prefix of the keys under which previous generations of the backup are stored in the backup Secret, e.g. 'previous-1.configmap.argo-rollouts-config'
+	backupGenerationPrefix = "previous-"
+)
+
+// backupItem identifies a resource whose data is included in the backup of the Rollouts configuration.
+type backupItem struct {
+	kind string
+	name string
+}
+
+// key returns the key under which the data of the resource is stored in the backup Secret.
+func (b backupItem) key() string {
+	return b.kind + "." + b.name
+}
+
+// getBackupItems returns the user-authored resources that are included in a backup.
+func getBackupItems() []backupItem {
+	return []backupItem{
+		{kind: backupKindConfigMap, name: DefaultRolloutsConfigMapName},
+		{kind: backupKindConfigMap, name: DefaultRolloutsNotificationConfigMapName},
+		{kind: backupKindSecret, name: DefaultRolloutsNotificationSecretName},
+	}
+}
+
+// generationKey returns the key under which the data of the resource is stored in the given generation of the backup: 0 is the most recent backup, 1 the backup before it, and so on.
+func (b backupItem) generationKey(generation int) string {
+	if generation == 0 {
+		return b.key()
+	}
+	return backupGenerationPrefix + strconv.Itoa(generation) + "." + b.key()
+}
+
+// parseBackupKey is the inverse of backupItem.generationKey()
+func parseBackupKey(key string) (backupItem, int, error) {
+	generation := 0
+	if strings.HasPrefix(key, backupGenerationPrefix) {
+		generationString, rest, _ := strings.Cut(strings.TrimPrefix(key, backupGenerationPrefix), ".")
+		var err error
+		if generation, err = strconv.Atoi(generationString); err != nil || generation <= 0 {
+			return backupItem{}, 0, fmt.Errorf("unexpected key in backup Secret: %s", key)
+		}
+		key = rest
+	}
+
+	kind, name, found := strings.Cut(key, ".")
+	if !found || name == "" || (kind != backupKindConfigMap && kind != backupKindSecret) {
+		return backupItem{}, 0, fmt.Errorf("unexpected key in backup Secret: %s", key)
+	}
+	return backupItem{kind: kind, name: name}, generation, nil
+}
+
+// getBackupGeneration returns the data of the given generation of the backup, keyed by backupItem.key(). Keys which are not valid backup keys are ignored.
+func getBackupGeneration(data map[string][]byte, generation int) map[string][]byte {
+	res := map[string][]byte{}
+	for key, value := range data {
+		item, itemGeneration, err := parseBackupKey(key)
+		if err != nil || itemGeneration != generation {
+			continue
+		}
+		res[item.key()] = value
+	}
+	return res
+}
+
+// rotateBackupData returns the new contents of the backup Secret: 'backupData' becomes the most recent generation, and the previous generations of 'liveData' are kept, up to DefaultRolloutsBackupGenerations generations in total.
+// If 'backupData' is identical to the most recent generation, the generations are not rotated, so that an unchanged configuration does not push older backups out.
+func rotateBackupData(liveData map[string][]byte, backupData map[string][]byte) map[string][]byte {
+
+	res := map[string][]byte{}
+
+	shift := 1
+	if reflect.DeepEqual(getBackupGeneration(liveData, 0), backupData) {
+		shift = 0
+	}
+
+	for generation := 0; generation < DefaultRolloutsBackupGenerations; generation++ {
+
+		generationData := backupData
+		if generation > 0 {
+			generationData = getBackupGeneration(liveData, generation-shift)
+		}
+
+		for key, value := range generationData {
+			item, _, err := parseBackupKey(key)
+			if err != nil {
+				continue
+			}
+			res[item.generationKey(generation)] = value
+		}
+	}
+
+	return res
+}
+
+func getBackupSecretName(cr rolloutsmanagerv1alpha1.RolloutManager) string {
+	if cr.Spec.Backup != nil && cr.Spec.Backup.TargetSecret != "" {
+		return cr.Spec.Backup.TargetSecret
+	}
+	return DefaultRolloutsBackupSecretName
+}
+
+// getBackupSchedule returns the interval between two backups.
+func getBackupSchedule(cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error) {
+	if cr.Spec.Backup == nil || cr.Spec.Backup.Schedule == "" {
+		return DefaultRolloutsBackupSchedule, nil
+	}
+
+	schedule, err := time.ParseDuration(cr.Spec.Backup.Schedule)
+	if err != nil {
+		return 0, fmt.Errorf("invalid backup schedule '%s': %w", cr.Spec.Backup.Schedule, err)
+	}
+	if schedule <= 0 {
+		return 0, fmt.Errorf("invalid backup schedule '%s': schedule must be positive", cr.Spec.Backup.Schedule)
+	}
+	return schedule, nil
+}
+
+// reconcileBackup writes a new backup of the Rollouts configuration to the backup Secret, if backups are enabled and the last backup is older than the backup schedule.
+// The returned duration is the time until the next backup is due (or zero, if backups are disabled).
+func (r *RolloutManagerReconciler) reconcileBackup(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error) {
+
+	if cr.Spec.Backup == nil || !cr.Spec.Backup.Enabled {
+		return 0, nil
+	}
+
+	schedule, err := getBackupSchedule(cr)
+	if err != nil {
+		return 0, err
+	}
+
+	liveSecret := &corev1.Secret{}
+	liveSecretExists := true
+	if err := fetchObject(ctx, r.Client, cr.Namespace, getBackupSecretName(cr), liveSecret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to get the backup Secret %s: %w", getBackupSecretName(cr), err)
+		}
+		liveSecretExists = false
+	}
+
+	if liveSecretExists {
+		// If the last backup is recent enough, there is nothing to do until the next backup is due.
+		if lastBackupTime, err := time.Parse(time.RFC3339, liveSecret.Annotations[LastBackupTimeAnnotation]); err == nil {
+			if elapsed := time.Since(lastBackupTime); elapsed < schedule {
+				return schedule - elapsed, nil
+			}
+		}
+	}
+
+	backupData, err := r.generateBackupData(ctx, cr, getBackupGeneration(liveSecret.Data, 0))
+	if err != nil {
+		return 0, err
+	}
+	backupData = rotateBackupData(liveSecret.Data, backupData)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	expectedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getBackupSecretName(cr),
+			Namespace: cr.Namespace,
+		},
+		Data: backupData,
+	}
+	setManagedSecretOptions(expectedSecret, cr)
+	setRolloutsLabelsAndAnnotationsToObject(&expectedSecret.ObjectMeta, cr)
+	expectedSecret.Labels[DoNotAdoptLabel] = "true"
+	expectedSecret.Annotations[LastBackupTimeAnnotation] = now
+
+	if !liveSecretExists {
+		// The backup Secret is intentionally not owned by the RolloutManager: the backup should survive deletion of the RolloutManager.
+		log.Info(fmt.Sprintf("Creating backup Secret %s", expectedSecret.Name))
+		return schedule, r.Client.Create(ctx, expectedSecret)
+	}
+
+	// The data of an immutable Secret, and the type of any Secret, cannot be updated in place
+	if isSecretImmutable(liveSecret) || !secretOptionsMatch(liveSecret, expectedSecret) {
+		expectedSecret.Labels = combineStringMaps(liveSecret.Labels, expectedSecret.Labels)
+		expectedSecret.Annotations = combineStringMaps(liveSecret.Annotations, expectedSecret.Annotations)
+		return schedule, r.replaceSecret(ctx, liveSecret, expectedSecret)
+	}
+
+	if liveSecret.Labels == nil {
+		liveSecret.Labels = map[string]string{}
+	}
+	liveSecret.Labels[DoNotAdoptLabel] = "true"
+	if liveSecret.Annotations == nil {
+		liveSecret.Annotations = map[string]string{}
+	}
+	liveSecret.Annotations[LastBackupTimeAnnotation] = now
+	liveSecret.Data = backupData
+
+	log.Info(fmt.Sprintf("Updating backup Secret %s", liveSecret.Name))
+	return schedule, r.Client.Update(ctx, liveSecret)
+}
+
+// generateBackupData returns the most recent generation of the backup: the data of each backupItem, serialized as JSON.
+// If a backupItem does not exist (for example, because it was deleted by mistake), its data from 'previousBackupData' is kept, so that it can still be restored.
+func (r *RolloutManagerReconciler) generateBackupData(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, previousBackupData map[string][]byte) (map[string][]byte, error) {
+
+	res := map[string][]byte{}
+
+	for _, item := range getBackupItems() {
+
+		var data any
+
+		switch item.kind {
+		case backupKindConfigMap:
+			cm := &corev1.ConfigMap{}
+			if err := fetchObject(ctx, r.Client, cr.Namespace, item.name, cm); err != nil {
+				if apierrors.IsNotFound(err) {
+					if previous, exists := previousBackupData[item.key()]; exists {
+						res[item.key()] = previous
+					}
+					continue
+				}
+				return nil, fmt.Errorf("failed to get the ConfigMap %s for backup: %w", item.name, err)
+			}
+			data = cm.Data
+
+		case backupKindSecret:
+			secret := &corev1.Secret{}
+			if err := fetchObject(ctx, r.Client, cr.Namespace, item.name, secret); err != nil {
+				if apierrors.IsNotFound(err) {
+					if previous, exists := previousBackupData[item.key()]; exists {
+						res[item.key()] = previous
+					}
+					continue
+				}
+				return nil, fmt.Errorf("failed to get the Secret %s for backup: %w", item.name, err)
+			}
+			data = secret.Data
+		}
+
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal backup data of %s: %w", item.name, err)
+		}
+		res[item.key()] = jsonData
+	}
+
+	return res, nil
+}
+
+// getRestoreBackupGeneration returns the generation of the backup that is restored, from the RestoreBackupGenerationAnnotation of the RolloutManager (by default, the most recent generation).
+func getRestoreBackupGeneration(cr rolloutsmanagerv1alpha1.RolloutManager) (int, error) {
+	value, exists := cr.Annotations[RestoreBackupGenerationAnnotation]
+	if !exists || value == "" {
+		return 0, nil
+	}
+
+	generation, err := strconv.Atoi(value)
+	if err != nil || generation < 0 || generation >= DefaultRolloutsBackupGenerations {
+		return 0, fmt.Errorf("invalid backup generation '%s': generation must be between 0 and %d", value, DefaultRolloutsBackupGenerations-1)
+	}
+	return generation, nil
+}
+
+// restoreBackupIfRequested restores the Rollouts configuration from the backup Secret, if the RestoreBackupAnnotation of the RolloutManager has a value that has not yet been restored.
+func (r *RolloutManagerReconciler) restoreBackupIfRequested(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	restoreRequest := cr.Annotations[RestoreBackupAnnotation]
+	if restoreRequest == "" {
+		return nil
+	}
+
+	backupSecret := &corev1.Secret{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, getBackupSecretName(cr), backupSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to restore backup: backup Secret %s does not exist", getBackupSecretName(cr))
+		}
+		return fmt.Errorf("failed to get the backup Secret %s: %w", getBackupSecretName(cr), err)
+	}
+
+	if backupSecret.Annotations[LastRestoreAnnotation] == restoreRequest {
+		// This restore request has already been handled.
+		return nil
+	}
+
+	generation, err := getRestoreBackupGeneration(cr)
+	if err != nil {
+		return fmt.Errorf("unable to restore backup: %w", err)
+	}
+
+	restored := false
+
+	for key, jsonData := range backupSecret.Data {
+
+		item, itemGeneration, err := parseBackupKey(key)
+		if err != nil {
+			return err
+		}
+		if itemGeneration != generation {
+			continue
+		}
+
+		if !restored {
+			log.Info(fmt.Sprintf("Restoring Rollouts configuration from generation %d of backup Secret %s", generation, backupSecret.Name))
+			restored = true
+		}
+
+		if err := r.restoreBackupItem(ctx, cr, item, jsonData); err != nil {
+			return err
+		}
+	}
+
+	if !restored {
+		return fmt.Errorf("unable to restore backup: backup Secret %s does not contain generation %d", backupSecret.Name, generation)
+	}
+
+	if backupSecret.Annotations == nil {
+		backupSecret.Annotations = map[string]string{}
+	}
+	backupSecret.Annotations[LastRestoreAnnotation] = restoreRequest
+
+	return r.Client.Update(ctx, backupSecret)
+}
+
+// restoreBackupItem creates the resource identified by 'item' (or updates it, if it already exists) with the data from the backup.
+func (r *RolloutManagerReconciler) restoreBackupItem(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, item backupItem, jsonData []byte) error {
+
+	switch item.kind {
+	case backupKindConfigMap:
+		var data map[string]string
+		if err := json.Unmarshal(jsonData, &data); err != nil {
+			return fmt.Errorf("unable to unmarshal backup data of ConfigMap %s: %w", item.name, err)
+		}
+
+		liveConfigMap := &corev1.ConfigMap{}
+		if err := fetchObject(ctx, r.Client, cr.Namespace, item.name, liveConfigMap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get the ConfigMap %s: %w", item.name, err)
+			}
+
+			restoredConfigMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      item.name,
+					Namespace: cr.Namespace,
+				},
+				Data: data,
+			}
+			setRolloutsLabelsAndAnnotationsToObject(&restoredConfigMap.ObjectMeta, cr)
+			restoredConfigMap.Labels[DoNotAdoptLabel] = "true"
+
+			log.Info(fmt.Sprintf("Restoring ConfigMap %s from backup", item.name))
+			return r.Client.Create(ctx, restoredConfigMap)
+		}
+
+		liveConfigMap.Data = data
+		log.Info(fmt.Sprintf("Restoring ConfigMap %s from backup", item.name))
+		return r.Client.Update(ctx, liveConfigMap)
+
+	case backupKindSecret:
+		var data map[string][]byte
+		if err := json.Unmarshal(jsonData, &data); err != nil {
+			return fmt.Errorf("unable to unmarshal backup data of Secret %s: %w", item.name, err)
+		}
+
+		liveSecret := &corev1.Secret{}
+		if err := fetchObject(ctx, r.Client, cr.Namespace, item.name, liveSecret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get the Secret %s: %w", item.name, err)
+			}
+
+			restoredSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      item.name,
+					Namespace: cr.Namespace,
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: data,
+			}
+			setRolloutsLabelsAndAnnotationsToObject(&restoredSecret.ObjectMeta, cr)
+			restoredSecret.Labels[DoNotAdoptLabel] = "true"
+
+			log.Info(fmt.Sprintf("Restoring Secret %s from backup", item.name))
+			return r.Client.Create(ctx, restoredSecret)
+		}
+
+		log.Info(fmt.Sprintf("Restoring Secret %s from backup", item.name))
+
+		// The data of an immutable Secret cannot be updated in place
+		if isSecretImmutable(liveSecret) {
+			replacement := liveSecret.DeepCopy()
+			replacement.Data = data
+			return r.replaceSecret(ctx, liveSecret, replacement)
+		}
+
+		liveSecret.Data = data
+		return r.Client.Update(ctx, liveSecret)
+	}
+
+	return fmt.Errorf("unexpected backup item kind: %s", item.kind)
+}
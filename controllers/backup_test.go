@@ -0,0 +1,260 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Backup and restore tests", func() {
+	var ctx context.Context
+	var a v1alpha1.RolloutManager
+	var r *RolloutManagerReconciler
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		a = *makeTestRolloutManager()
+		a.Spec.Backup = &v1alpha1.RolloutManagerBackupSpec{
+			Enabled: true,
+		}
+
+		r = makeTestReconciler(&a)
+		Expect(createNamespace(r, a.Namespace)).To(Succeed())
+
+		notificationConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DefaultRolloutsNotificationConfigMapName,
+				Namespace: a.Namespace,
+			},
+			Data: map[string]string{
+				"template.my-template": "message: Rollout {{.rollout.metadata.name}} has been updated",
+			},
+		}
+		Expect(r.Client.Create(ctx, notificationConfigMap)).To(Succeed())
+
+		notificationSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DefaultRolloutsNotificationSecretName,
+				Namespace: a.Namespace,
+			},
+			Data: map[string][]byte{
+				"slack-token": []byte("my-token"),
+			},
+		}
+		Expect(r.Client.Create(ctx, notificationSecret)).To(Succeed())
+	})
+
+	It("should not create a backup Secret if backups are not enabled", func() {
+		a.Spec.Backup = nil
+
+		requeueAfter, err := r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requeueAfter).To(BeZero())
+
+		backupSecret := &corev1.Secret{}
+		err = fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsBackupSecretName, backupSecret)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should back up the existing configuration resources to the backup Secret", func() {
+
+		By("calling reconcileBackup")
+		requeueAfter, err := r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requeueAfter).To(Equal(DefaultRolloutsBackupSchedule))
+
+		By("verifying the backup Secret contains the notification ConfigMap and Secret, but not the missing argo-rollouts-config ConfigMap")
+		backupSecret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+		Expect(backupSecret.Data).To(HaveLen(2))
+		Expect(backupSecret.Data).To(HaveKey("configmap." + DefaultRolloutsNotificationConfigMapName))
+		Expect(backupSecret.Data).To(HaveKey("secret." + DefaultRolloutsNotificationSecretName))
+		Expect(backupSecret.Annotations).To(HaveKey(LastBackupTimeAnnotation))
+
+		By("verifying the backup Secret is not owned by the RolloutManager")
+		Expect(backupSecret.OwnerReferences).To(BeEmpty())
+
+		By("calling reconcileBackup again, which should not update the backup, since it is not yet due")
+		lastBackupTime := backupSecret.Annotations[LastBackupTimeAnnotation]
+		requeueAfter, err = r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requeueAfter).To(BeNumerically(">", 0))
+		Expect(requeueAfter).To(BeNumerically("<=", DefaultRolloutsBackupSchedule))
+
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+		Expect(backupSecret.Annotations[LastBackupTimeAnnotation]).To(Equal(lastBackupTime))
+	})
+
+	It("should update the backup once the previous backup is older than the schedule", func() {
+		a.Spec.Backup.Schedule = "1h"
+		a.Spec.Backup.TargetSecret = "my-backup"
+
+		_, err := r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("marking the backup as older than the schedule, and modifying the notification ConfigMap")
+		backupSecret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, "my-backup", backupSecret)).To(Succeed())
+		backupSecret.Annotations[LastBackupTimeAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		Expect(r.Client.Update(ctx, backupSecret)).To(Succeed())
+
+		notificationConfigMap := &corev1.ConfigMap{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationConfigMapName, notificationConfigMap)).To(Succeed())
+		notificationConfigMap.Data["template.other-template"] = "message: hello"
+		Expect(r.Client.Update(ctx, notificationConfigMap)).To(Succeed())
+
+		By("calling reconcileBackup, which should take a new backup")
+		requeueAfter, err := r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requeueAfter).To(Equal(time.Hour))
+
+		Expect(fetchObject(ctx, r.Client, a.Namespace, "my-backup", backupSecret)).To(Succeed())
+		Expect(string(backupSecret.Data["configmap."+DefaultRolloutsNotificationConfigMapName])).To(ContainSubstring("template.other-template"))
+	})
+
+	It("should return an error if the backup schedule is invalid", func() {
+		a.Spec.Backup.Schedule = "every day"
+
+		_, err := r.reconcileBackup(ctx, a)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should restore the configuration from the backup Secret when the restore annotation is set, and only once per annotation value", func() {
+
+		By("taking a backup")
+		_, err := r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("deleting the notification ConfigMap and Secret")
+		Expect(r.Client.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationConfigMapName, Namespace: a.Namespace}})).To(Succeed())
+		Expect(r.Client.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationSecretName, Namespace: a.Namespace}})).To(Succeed())
+
+		By("calling restoreBackupIfRequested without the restore annotation, which should not restore anything")
+		Expect(r.restoreBackupIfRequested(ctx, a)).To(Succeed())
+		err = fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationConfigMapName, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("setting the restore annotation and calling restoreBackupIfRequested")
+		a.Annotations = map[string]string{RestoreBackupAnnotation: "1"}
+		Expect(r.restoreBackupIfRequested(ctx, a)).To(Succeed())
+
+		restoredConfigMap := &corev1.ConfigMap{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationConfigMapName, restoredConfigMap)).To(Succeed())
+		Expect(restoredConfigMap.Data).To(HaveKeyWithValue("template.my-template", "message: Rollout {{.rollout.metadata.name}} has been updated"))
+
+		restoredSecret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationSecretName, restoredSecret)).To(Succeed())
+		Expect(restoredSecret.Data).To(HaveKeyWithValue("slack-token", []byte("my-token")))
+
+		backupSecret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+		Expect(backupSecret.Annotations).To(HaveKeyWithValue(LastRestoreAnnotation, "1"))
+
+		By("modifying the restored ConfigMap, then calling restoreBackupIfRequested with the same annotation value, which should not restore again")
+		restoredConfigMap.Data["template.my-template"] = "message: modified"
+		Expect(r.Client.Update(ctx, restoredConfigMap)).To(Succeed())
+
+		Expect(r.restoreBackupIfRequested(ctx, a)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationConfigMapName, restoredConfigMap)).To(Succeed())
+		Expect(restoredConfigMap.Data).To(HaveKeyWithValue("template.my-template", "message: modified"))
+
+		By("changing the restore annotation value, which should restore the backup again")
+		a.Annotations[RestoreBackupAnnotation] = "2"
+		Expect(r.restoreBackupIfRequested(ctx, a)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationConfigMapName, restoredConfigMap)).To(Succeed())
+		Expect(restoredConfigMap.Data).To(HaveKeyWithValue("template.my-template", "message: Rollout {{.rollout.metadata.name}} has been updated"))
+	})
+
+	It("should keep the backup of a resource which no longer exists, so that it can still be restored", func() {
+
+		By("taking a backup")
+		_, err := r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("deleting the notification ConfigMap")
+		Expect(r.Client.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationConfigMapName, Namespace: a.Namespace}})).To(Succeed())
+
+		By("marking the backup as older than the schedule, and calling reconcileBackup, which should take a new backup")
+		backupSecret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+		backupSecret.Annotations[LastBackupTimeAnnotation] = time.Now().Add(-2 * DefaultRolloutsBackupSchedule).UTC().Format(time.RFC3339)
+		Expect(r.Client.Update(ctx, backupSecret)).To(Succeed())
+
+		_, err = r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+		Expect(backupSecret.Annotations[LastBackupTimeAnnotation]).ToNot(BeEmpty())
+		Expect(backupSecret.Data).To(HaveKey("configmap." + DefaultRolloutsNotificationConfigMapName))
+
+		By("restoring the backup, which should recover the original data of the notification ConfigMap")
+		a.Annotations = map[string]string{RestoreBackupAnnotation: "1"}
+		Expect(r.restoreBackupIfRequested(ctx, a)).To(Succeed())
+
+		restoredConfigMap := &corev1.ConfigMap{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationConfigMapName, restoredConfigMap)).To(Succeed())
+		Expect(restoredConfigMap.Data).To(HaveKeyWithValue("template.my-template", "message: Rollout {{.rollout.metadata.name}} has been updated"))
+	})
+
+	It("should keep previous generations of the backup, which can be restored with the restore generation annotation", func() {
+
+		By("taking a backup")
+		_, err := r.reconcileBackup(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+
+		takeBackup := func(templateMessage string) {
+			notificationConfigMap := &corev1.ConfigMap{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationConfigMapName, notificationConfigMap)).To(Succeed())
+			notificationConfigMap.Data["template.my-template"] = templateMessage
+			Expect(r.Client.Update(ctx, notificationConfigMap)).To(Succeed())
+
+			backupSecret := &corev1.Secret{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+			backupSecret.Annotations[LastBackupTimeAnnotation] = time.Now().Add(-2 * DefaultRolloutsBackupSchedule).UTC().Format(time.RFC3339)
+			Expect(r.Client.Update(ctx, backupSecret)).To(Succeed())
+
+			_, err := r.reconcileBackup(ctx, a)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		By("modifying the notification ConfigMap, and taking more backups than the number of generations which are kept")
+		for i := 1; i <= DefaultRolloutsBackupGenerations; i++ {
+			takeBackup(fmt.Sprintf("message: version %d", i))
+		}
+
+		By("verifying that only DefaultRolloutsBackupGenerations generations are kept")
+		backupSecret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+		Expect(backupSecret.Data).To(HaveLen(2 * DefaultRolloutsBackupGenerations))
+		Expect(backupSecret.Data).To(HaveKey("previous-1.configmap." + DefaultRolloutsNotificationConfigMapName))
+		Expect(backupSecret.Data).ToNot(HaveKey(fmt.Sprintf("previous-%d.configmap.%s", DefaultRolloutsBackupGenerations, DefaultRolloutsNotificationConfigMapName)))
+
+		By("taking a backup of an unchanged configuration, which should not rotate the generations")
+		takeBackup(fmt.Sprintf("message: version %d", DefaultRolloutsBackupGenerations))
+
+		By("restoring the previous generation of the backup")
+		a.Annotations = map[string]string{RestoreBackupAnnotation: "1", RestoreBackupGenerationAnnotation: "1"}
+		Expect(r.restoreBackupIfRequested(ctx, a)).To(Succeed())
+
+		restoredConfigMap := &corev1.ConfigMap{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultRolloutsNotificationConfigMapName, restoredConfigMap)).To(Succeed())
+		Expect(restoredConfigMap.Data).To(HaveKeyWithValue("template.my-template", fmt.Sprintf("message: version %d", DefaultRolloutsBackupGenerations-1)))
+
+		By("requesting a restore of a generation which is not kept, which should return an error")
+		a.Annotations = map[string]string{RestoreBackupAnnotation: "2", RestoreBackupGenerationAnnotation: strconv.Itoa(DefaultRolloutsBackupGenerations)}
+		Expect(r.restoreBackupIfRequested(ctx, a)).ToNot(Succeed())
+	})
+
+	It("should return an error if a restore is requested, but the backup Secret does not exist", func() {
+		a.Annotations = map[string]string{RestoreBackupAnnotation: "1"}
+		Expect(r.restoreBackupIfRequested(ctx, a)).ToNot(Succeed())
+	})
+})
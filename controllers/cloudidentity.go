@@ -0,0 +1,83 @@
+package rollouts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AWSRoleARNAnnotation is the ServiceAccount annotation which sets the IAM role assumed by the pods of the ServiceAccount, with IAM Roles for Service Accounts (IRSA).
+	AWSRoleARNAnnotation = "eks.amazonaws.com/role-arn"
+
+	// GCPServiceAccountAnnotation is the ServiceAccount annotation which sets the IAM service account impersonated by the pods of the ServiceAccount, with GKE Workload Identity.
+	GCPServiceAccountAnnotation = "iam.gke.io/gcp-service-account"
+
+	// AzureClientIDAnnotation is the ServiceAccount annotation which sets the client ID of the Microsoft Entra application or managed identity used by the pods of the ServiceAccount, with Microsoft Entra Workload ID.
+	AzureClientIDAnnotation = "azure.workload.identity/client-id"
+
+	// AzureUseLabel is the pod label which requests that the Microsoft Entra Workload ID webhook injects the federated token into the pod.
+	AzureUseLabel = "azure.workload.identity/use"
+)
+
+var (
+	awsRoleARNRegexp        = regexp.MustCompile(`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`)
+	gcpServiceAccountRegexp = regexp.MustCompile(`^[a-z0-9-]+@[a-z0-9-]+\.iam\.gserviceaccount\.com$`)
+	azureClientIDRegexp     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// setCloudIdentityLabels adds the pod labels that are required by the cloud workload identity of the RolloutManager, to the given pod template labels.
+func setCloudIdentityLabels(cr rolloutsmanagerv1alpha1.RolloutManager, labels map[string]string) {
+	if cr.Spec.CloudIdentity != nil && cr.Spec.CloudIdentity.Provider == rolloutsmanagerv1alpha1.CloudIdentityProviderAzure {
+		labels[AzureUseLabel] = "true"
+	}
+}
+
+// validateCloudIdentity verifies that the Rollouts controller ServiceAccount is configured for the cloud workload identity of the RolloutManager, if any, and returns the problems that were found.
+// Only the configuration within the cluster is verified: the configuration of the cloud provider (for example, the trust policy of an AWS IAM role, or the IAM policy binding of a GCP service account) is not visible to the operator.
+func validateCloudIdentity(cr rolloutsmanagerv1alpha1.RolloutManager, sa corev1.ServiceAccount) []string {
+
+	res := []string{}
+
+	if cr.Spec.CloudIdentity == nil {
+		return res
+	}
+
+	var annotation string
+	var annotationRegexp *regexp.Regexp
+	var expectedFormat string
+
+	switch cr.Spec.CloudIdentity.Provider {
+	case rolloutsmanagerv1alpha1.CloudIdentityProviderAWS:
+		annotation, annotationRegexp, expectedFormat = AWSRoleARNAnnotation, awsRoleARNRegexp, "an IAM role ARN, such as 'arn:aws:iam::123456789012:role/argo-rollouts'"
+	case rolloutsmanagerv1alpha1.CloudIdentityProviderGCP:
+		annotation, annotationRegexp, expectedFormat = GCPServiceAccountAnnotation, gcpServiceAccountRegexp, "the email of an IAM service account, such as 'argo-rollouts@my-project.iam.gserviceaccount.com'"
+	case rolloutsmanagerv1alpha1.CloudIdentityProviderAzure:
+		annotation, annotationRegexp, expectedFormat = AzureClientIDAnnotation, azureClientIDRegexp, "the client ID (a UUID) of a Microsoft Entra application or managed identity"
+	default:
+		return append(res, fmt.Sprintf("unsupported provider '%s'", cr.Spec.CloudIdentity.Provider))
+	}
+
+	value, exists := sa.Annotations[annotation]
+	if !exists {
+		res = append(res, fmt.Sprintf("ServiceAccount %s is missing the '%s' annotation, which should be set to %s", sa.Name, annotation, expectedFormat))
+	} else if !annotationRegexp.MatchString(strings.TrimSpace(value)) {
+		res = append(res, fmt.Sprintf("the '%s' annotation of ServiceAccount %s is '%s', but it should be %s", annotation, sa.Name, value, expectedFormat))
+	}
+
+	return res
+}
+
+// createCloudIdentityNotConfiguredCondition returns the CloudIdentityNotConfigured condition for the given problems.
+func createCloudIdentityNotConfiguredCondition(cloudIdentityProblems []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerCloudIdentityNotConfiguredConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonCloudIdentityNotConfigured,
+		Message: CloudIdentityNotConfiguredMessage + strings.Join(cloudIdentityProblems, ", "),
+	}
+}
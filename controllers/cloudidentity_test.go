@@ -0,0 +1,94 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Cloud identity tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	DescribeTable("validateCloudIdentity should verify the annotation of the ServiceAccount", func(provider rolloutsmanagerv1alpha1.CloudIdentityProvider, annotations map[string]string, expectedProblem string) {
+		cr.Spec.CloudIdentity = &rolloutsmanagerv1alpha1.RolloutManagerCloudIdentitySpec{Provider: provider}
+		sa := corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Annotations: annotations}}
+
+		problems := validateCloudIdentity(cr, sa)
+		if expectedProblem == "" {
+			Expect(problems).To(BeEmpty())
+		} else {
+			Expect(problems).To(ConsistOf(ContainSubstring(expectedProblem)))
+		}
+	},
+		Entry("AWS, configured", rolloutsmanagerv1alpha1.CloudIdentityProviderAWS, map[string]string{AWSRoleARNAnnotation: "arn:aws:iam::123456789012:role/argo-rollouts"}, ""),
+		Entry("AWS GovCloud, configured", rolloutsmanagerv1alpha1.CloudIdentityProviderAWS, map[string]string{AWSRoleARNAnnotation: "arn:aws-us-gov:iam::123456789012:role/argo-rollouts"}, ""),
+		Entry("AWS, missing annotation", rolloutsmanagerv1alpha1.CloudIdentityProviderAWS, nil, "is missing the 'eks.amazonaws.com/role-arn' annotation"),
+		Entry("AWS, invalid annotation", rolloutsmanagerv1alpha1.CloudIdentityProviderAWS, map[string]string{AWSRoleARNAnnotation: "argo-rollouts"}, "the 'eks.amazonaws.com/role-arn' annotation of ServiceAccount argo-rollouts is 'argo-rollouts'"),
+		Entry("GCP, configured", rolloutsmanagerv1alpha1.CloudIdentityProviderGCP, map[string]string{GCPServiceAccountAnnotation: "argo-rollouts@my-project.iam.gserviceaccount.com"}, ""),
+		Entry("GCP, AWS annotation", rolloutsmanagerv1alpha1.CloudIdentityProviderGCP, map[string]string{AWSRoleARNAnnotation: "arn:aws:iam::123456789012:role/argo-rollouts"}, "is missing the 'iam.gke.io/gcp-service-account' annotation"),
+		Entry("Azure, configured", rolloutsmanagerv1alpha1.CloudIdentityProviderAzure, map[string]string{AzureClientIDAnnotation: "00000000-1111-2222-3333-444444444444"}, ""),
+		Entry("Azure, invalid annotation", rolloutsmanagerv1alpha1.CloudIdentityProviderAzure, map[string]string{AzureClientIDAnnotation: "my-identity"}, "should be the client ID"),
+	)
+
+	It("validateCloudIdentity should not report problems if no cloud identity is specified", func() {
+		Expect(validateCloudIdentity(cr, corev1.ServiceAccount{})).To(BeEmpty())
+	})
+
+	It("should add the Azure Workload ID label to the pod template, but not to the selector", func() {
+		cr.Spec.CloudIdentity = &rolloutsmanagerv1alpha1.RolloutManagerCloudIdentitySpec{Provider: rolloutsmanagerv1alpha1.CloudIdentityProviderAzure}
+
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue(AzureUseLabel, "true"))
+		Expect(deployment.Spec.Selector.MatchLabels).ToNot(HaveKey(AzureUseLabel))
+	})
+
+	It("should set the CloudIdentityNotConfigured condition until the ServiceAccount is annotated", func() {
+		ctx := context.Background()
+		cr.Spec.CloudIdentity = &rolloutsmanagerv1alpha1.RolloutManagerCloudIdentitySpec{Provider: rolloutsmanagerv1alpha1.CloudIdentityProviderAWS}
+		r := makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		Expect(cr.Status.Conditions).To(ContainElement(And(
+			HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerCloudIdentityNotConfiguredConditionType),
+			HaveField("Status", metav1.ConditionTrue),
+			HaveField("Reason", rolloutsmanagerv1alpha1.RolloutManagerReasonCloudIdentityNotConfigured),
+			HaveField("Message", HavePrefix(CloudIdentityNotConfiguredMessage)),
+		)))
+
+		By("verifying that the RolloutManager is still reconciled")
+		Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess))
+
+		By("annotating the ServiceAccount, and verifying that the condition is removed")
+		sa := &corev1.ServiceAccount{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+		sa.Annotations[AWSRoleARNAnnotation] = "arn:aws:iam::123456789012:role/argo-rollouts"
+		Expect(r.Client.Update(ctx, sa)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		Expect(cr.Status.Conditions).ToNot(ContainElement(HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerCloudIdentityNotConfiguredConditionType)))
+	})
+})
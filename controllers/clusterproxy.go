@@ -0,0 +1,213 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	clusterProxiesCRDName = "proxies.config.openshift.io"
+
+	// ClusterProxyName is the name of the cluster-wide Proxy of OpenShift
+	ClusterProxyName = "cluster"
+
+	// TrustedCABundleConfigMapName is the name of the ConfigMap, in the namespace of the RolloutManager, into which OpenShift injects the trusted CA bundle of the cluster-wide proxy. It is mounted into the Rollouts controller container.
+	TrustedCABundleConfigMapName = "argo-rollouts-trusted-ca-bundle"
+
+	// InjectTrustedCABundleLabel is set on the TrustedCABundleConfigMapName ConfigMap, so that OpenShift injects the trusted CA bundle into it.
+	InjectTrustedCABundleLabel = "config.openshift.io/inject-trusted-cabundle"
+
+	// trustedCABundleKey is the key of the trusted CA bundle in the ConfigMap, as injected by OpenShift
+	trustedCABundleKey = "ca-bundle.crt"
+
+	trustedCABundleVolumeName = "trusted-ca-bundle"
+
+	// trustedCABundleMountPath is the directory in which the trusted CA bundle is mounted: it replaces the system CA bundle of RHEL-based images, and is pointed to by SSL_CERT_FILE for the others.
+	trustedCABundleMountPath = "/etc/pki/ca-trust/extracted/pem"
+	trustedCABundleFileName  = "tls-ca-bundle.pem"
+)
+
+// clusterProxyGVK is the GroupVersionKind of the cluster-wide Proxy of OpenShift. The Proxy only exists on OpenShift, so it is handled as an unstructured object.
+var clusterProxyGVK = schema.GroupVersionKind{
+	Group:   "config.openshift.io",
+	Version: "v1",
+	Kind:    "Proxy",
+}
+
+// clusterProxyGVR is the GroupVersionResource of the cluster-wide Proxy of OpenShift, as reported by the discovery client.
+var clusterProxyGVR = clusterProxyGVK.GroupVersion().WithResource("proxies")
+
+// clusterProxy is the configuration of the cluster-wide proxy of OpenShift, as read from the 'cluster' Proxy.
+type clusterProxy struct {
+	// httpProxy, httpsProxy and noProxy are the effective values of the proxy, from the status of the Proxy
+	httpProxy  string
+	httpsProxy string
+	noProxy    string
+	// trustedCA is true if the Proxy references a ConfigMap of additional CA certificates, which OpenShift merges into the trusted CA bundle
+	trustedCA bool
+}
+
+// isClusterProxyEnabled returns true if the cluster-wide proxy should be injected into the Rollouts controller of the RolloutManager.
+func isClusterProxyEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.ClusterProxy == nil || !cr.Spec.ClusterProxy.Disabled
+}
+
+func newClusterProxy() *unstructured.Unstructured {
+	proxy := &unstructured.Unstructured{}
+	proxy.SetGroupVersionKind(clusterProxyGVK)
+	return proxy
+}
+
+// getClusterProxy returns the configuration of the cluster-wide proxy, or nil if the cluster is not OpenShift (the Proxy CRD is not installed), or the Proxy does not exist.
+// If the cluster API is disabled, nil is returned, as the Proxy is cluster-scoped.
+func (r *RolloutManagerReconciler) getClusterProxy(ctx context.Context) (*clusterProxy, error) {
+
+	if r.clusterAPIDisabled() {
+		return nil, nil
+	}
+
+	if _, err := fetchObjectMetadata(ctx, r.Client, customResourceDefinitionGVK, "", clusterProxiesCRDName); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get the CustomResourceDefinition %s: %w", clusterProxiesCRDName, err)
+		}
+		return nil, nil
+	}
+
+	proxy := newClusterProxy()
+	if err := fetchObject(ctx, r.Client, "", ClusterProxyName, proxy); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get the Proxy %s: %w", ClusterProxyName, err)
+		}
+		return nil, nil
+	}
+
+	res := &clusterProxy{}
+	res.httpProxy, _, _ = unstructured.NestedString(proxy.Object, "status", "httpProxy")
+	res.httpsProxy, _, _ = unstructured.NestedString(proxy.Object, "status", "httpsProxy")
+	res.noProxy, _, _ = unstructured.NestedString(proxy.Object, "status", "noProxy")
+	trustedCAName, _, _ := unstructured.NestedString(proxy.Object, "spec", "trustedCA", "name")
+	res.trustedCA = trustedCAName != ""
+
+	return res, nil
+}
+
+// clusterProxyEnvVars returns the proxy environment variables of the cluster-wide proxy.
+func clusterProxyEnvVars(proxy clusterProxy) []corev1.EnvVar {
+	var res []corev1.EnvVar
+	for _, env := range []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: proxy.httpProxy},
+		{Name: "HTTPS_PROXY", Value: proxy.httpsProxy},
+		{Name: "NO_PROXY", Value: proxy.noProxy},
+	} {
+		if env.Value != "" {
+			res = append(res, env)
+		}
+	}
+	return res
+}
+
+// trustedCABundleFileMount returns the FileMount of the trusted CA bundle of the cluster-wide proxy.
+func trustedCABundleFileMount() rolloutsmanagerv1alpha1.RolloutManagerFileMount {
+	return rolloutsmanagerv1alpha1.RolloutManagerFileMount{
+		Name:      trustedCABundleVolumeName,
+		MountPath: trustedCABundleMountPath,
+		ConfigMap: TrustedCABundleConfigMapName,
+		Items:     []corev1.KeyToPath{{Key: trustedCABundleKey, Path: trustedCABundleFileName}},
+	}
+}
+
+// withClusterProxy returns a copy of the RolloutManager to which the cluster-wide proxy is applied, from which the Rollouts controller Deployment is generated:
+//   - the proxy environment variables are added to .spec.env, unless they are already specified there. They take precedence over the proxy environment variables of the operator (see proxyEnvVars), which are only updated when the operator is restarted.
+//   - if trustedCABundle is true, the trusted CA bundle is added to .spec.fileMounts, unless its name or mount path is already used by a FileMount of the RolloutManager.
+func withClusterProxy(cr rolloutsmanagerv1alpha1.RolloutManager, proxy *clusterProxy, trustedCABundle bool) rolloutsmanagerv1alpha1.RolloutManager {
+
+	if proxy == nil || !isClusterProxyEnabled(cr) {
+		return cr
+	}
+
+	res := *cr.DeepCopy()
+	res.Spec.Env = envMerge(res.Spec.Env, clusterProxyEnvVars(*proxy), false)
+
+	if trustedCABundle {
+		withTrustedCABundle := *res.DeepCopy()
+		withTrustedCABundle.Spec.FileMounts = append(withTrustedCABundle.Spec.FileMounts, trustedCABundleFileMount())
+		withTrustedCABundle.Spec.Env = envMerge(withTrustedCABundle.Spec.Env, []corev1.EnvVar{{Name: "SSL_CERT_FILE", Value: trustedCABundleMountPath + "/" + trustedCABundleFileName}}, false)
+
+		if err := validateFileMounts(withTrustedCABundle); err != nil {
+			log.Info("the trusted CA bundle of the cluster-wide proxy is not mounted into the Rollouts controller, as it conflicts with the FileMounts of the RolloutManager", "err", err.Error())
+		} else {
+			res = withTrustedCABundle
+		}
+	}
+
+	return res
+}
+
+// generateDesiredTrustedCABundleConfigMap returns the (empty) ConfigMap into which OpenShift injects the trusted CA bundle.
+func generateDesiredTrustedCABundleConfigMap(cr rolloutsmanagerv1alpha1.RolloutManager) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TrustedCABundleConfigMapName,
+			Namespace: cr.Namespace,
+		},
+	}
+	setRolloutsLabelsAndAnnotationsToObject(&configMap.ObjectMeta, cr)
+	configMap.Labels[InjectTrustedCABundleLabel] = "true"
+	return configMap
+}
+
+// reconcileClusterProxy applies the cluster-wide proxy of OpenShift to the RolloutManager (see withClusterProxy), and returns the RolloutManager from which the Rollouts controller Deployment is generated.
+// If the Proxy references additional trusted CA certificates, the ConfigMap into which OpenShift injects the trusted CA bundle is created, and the bundle is mounted once it has been injected. Otherwise, the ConfigMap is deleted.
+func (r *RolloutManagerReconciler) reconcileClusterProxy(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (rolloutsmanagerv1alpha1.RolloutManager, error) {
+
+	proxy, err := r.getClusterProxy(ctx)
+	if err != nil {
+		return cr, err
+	}
+
+	if proxy == nil || !proxy.trustedCA || !isClusterProxyEnabled(cr) {
+		return withClusterProxy(cr, proxy, false), r.removeTrustedCABundleConfigMap(ctx, cr)
+	}
+
+	expectedConfigMap := generateDesiredTrustedCABundleConfigMap(cr)
+	if err := r.prepareResource(ctx, cr, expectedConfigMap); err != nil {
+		return cr, err
+	}
+
+	// The data of the ConfigMap is managed by OpenShift
+	liveConfigMap := &corev1.ConfigMap{}
+	if _, err := r.applyResource(ctx, cr, expectedConfigMap, liveConfigMap, true, nil); err != nil {
+		return cr, err
+	}
+
+	return withClusterProxy(cr, proxy, liveConfigMap.Data[trustedCABundleKey] != ""), nil
+}
+
+// removeTrustedCABundleConfigMap deletes the trusted CA bundle ConfigMap of the RolloutManager, if it was created by the operator.
+func (r *RolloutManagerReconciler) removeTrustedCABundleConfigMap(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	configMap := &corev1.ConfigMap{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, TrustedCABundleConfigMapName, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the ConfigMap %s: %w", TrustedCABundleConfigMapName, err)
+	}
+
+	if !metav1.IsControlledBy(configMap, &cr) {
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("Deleting ConfigMap %s, as the trusted CA bundle of the cluster-wide proxy is no longer needed", TrustedCABundleConfigMapName))
+	if err := r.Client.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the ConfigMap %s: %w", TrustedCABundleConfigMapName, err)
+	}
+	return nil
+}
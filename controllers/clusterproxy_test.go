@@ -0,0 +1,186 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Cluster-wide proxy tests", func() {
+	var ctx context.Context
+	var a v1alpha1.RolloutManager
+	var r *RolloutManagerReconciler
+	var req reconcile.Request
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		a = *makeTestRolloutManager()
+
+		r = makeTestReconciler(&a)
+		Expect(createNamespace(r, a.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, a.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&a)}
+	})
+
+	// reconcileAndGetContainer reconciles the RolloutManager, and returns the Rollouts controller container
+	reconcileAndGetContainer := func() corev1.Container {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+		return deployment.Spec.Template.Spec.Containers[0]
+	}
+
+	It("should detect the Proxy resource of OpenShift via the discovery client, so that the Proxy is watched", func() {
+		discoveryClient := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+		Expect(isResourceServed(discoveryClient, clusterProxyGVR)).To(BeFalse())
+
+		discoveryClient.Resources = []*metav1.APIResourceList{{
+			GroupVersion: "monitoring.coreos.com/v1",
+			APIResources: []metav1.APIResource{{Name: "servicemonitors", Kind: "ServiceMonitor"}},
+		}}
+		Expect(isResourceServed(discoveryClient, clusterProxyGVR)).To(BeFalse())
+
+		discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+			GroupVersion: "config.openshift.io/v1",
+			APIResources: []metav1.APIResource{{Name: "proxies", Kind: "Proxy"}},
+		})
+		Expect(isResourceServed(discoveryClient, clusterProxyGVR)).To(BeTrue())
+	})
+
+	It("should not inject a proxy if the cluster is not OpenShift", func() {
+		container := reconcileAndGetContainer()
+		Expect(container.Env).ToNot(ContainElement(HaveField("Name", "HTTPS_PROXY")))
+		Expect(fetchObject(ctx, r.Client, a.Namespace, TrustedCABundleConfigMapName, &corev1.ConfigMap{})).ToNot(Succeed())
+	})
+
+	When("the cluster is OpenShift, with a cluster-wide proxy", func() {
+
+		var proxy *unstructured.Unstructured
+
+		BeforeEach(func() {
+			proxyCRD := &crdv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: clusterProxiesCRDName,
+				},
+			}
+			Expect(r.Client.Create(ctx, proxyCRD)).To(Succeed())
+
+			proxy = newClusterProxy()
+			proxy.SetName(ClusterProxyName)
+			proxy.Object["spec"] = map[string]interface{}{
+				"httpsProxy": "http://proxy.example.com:3128",
+			}
+			proxy.Object["status"] = map[string]interface{}{
+				"httpsProxy": "http://proxy.example.com:3128",
+				"noProxy":    ".cluster.local,.svc,10.0.0.0/16",
+			}
+			Expect(r.Client.Create(ctx, proxy)).To(Succeed())
+		})
+
+		It("should inject the proxy environment variables, and keep them updated when the Proxy changes", func() {
+			container := reconcileAndGetContainer()
+			Expect(container.Env).To(ContainElements(
+				corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+				corev1.EnvVar{Name: "NO_PROXY", Value: ".cluster.local,.svc,10.0.0.0/16"},
+			))
+			Expect(container.Env).ToNot(ContainElement(HaveField("Name", "HTTP_PROXY")))
+
+			By("updating the Proxy")
+			Expect(unstructured.SetNestedField(proxy.Object, "http://proxy2.example.com:3128", "status", "httpsProxy")).To(Succeed())
+			Expect(r.Client.Update(ctx, proxy)).To(Succeed())
+
+			container = reconcileAndGetContainer()
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy2.example.com:3128"}))
+		})
+
+		It("should give precedence to the environment variables of the RolloutManager", func() {
+			a.Spec.Env = []corev1.EnvVar{{Name: "HTTPS_PROXY", Value: "http://override.example.com:8080"}}
+			Expect(r.Client.Update(ctx, &a)).To(Succeed())
+
+			container := reconcileAndGetContainer()
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://override.example.com:8080"}))
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "NO_PROXY", Value: ".cluster.local,.svc,10.0.0.0/16"}))
+		})
+
+		It("should not inject the proxy if it is disabled in the RolloutManager", func() {
+			a.Spec.ClusterProxy = &v1alpha1.RolloutManagerClusterProxySpec{Disabled: true}
+			Expect(r.Client.Update(ctx, &a)).To(Succeed())
+
+			container := reconcileAndGetContainer()
+			Expect(container.Env).ToNot(ContainElement(HaveField("Name", "HTTPS_PROXY")))
+			Expect(container.Env).ToNot(ContainElement(HaveField("Name", "NO_PROXY")))
+		})
+
+		When("the Proxy references additional trusted CA certificates", func() {
+
+			BeforeEach(func() {
+				Expect(unstructured.SetNestedField(proxy.Object, "user-ca-bundle", "spec", "trustedCA", "name")).To(Succeed())
+				Expect(r.Client.Update(ctx, proxy)).To(Succeed())
+			})
+
+			It("should mount the trusted CA bundle once it has been injected by OpenShift", func() {
+				container := reconcileAndGetContainer()
+				Expect(container.VolumeMounts).ToNot(ContainElement(HaveField("Name", trustedCABundleVolumeName)))
+
+				configMap := &corev1.ConfigMap{}
+				Expect(fetchObject(ctx, r.Client, a.Namespace, TrustedCABundleConfigMapName, configMap)).To(Succeed())
+				Expect(configMap.Labels).To(HaveKeyWithValue(InjectTrustedCABundleLabel, "true"))
+				Expect(metav1.IsControlledBy(configMap, &a)).To(BeTrue())
+
+				By("injecting the trusted CA bundle, as OpenShift would")
+				configMap.Data = map[string]string{trustedCABundleKey: "-----BEGIN CERTIFICATE-----"}
+				Expect(r.Client.Update(ctx, configMap)).To(Succeed())
+				Expect(referencesObject(a, configMap)).To(BeTrue())
+
+				container = reconcileAndGetContainer()
+				Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: trustedCABundleVolumeName, MountPath: trustedCABundleMountPath, ReadOnly: true}))
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "SSL_CERT_FILE", Value: trustedCABundleMountPath + "/" + trustedCABundleFileName}))
+
+				Expect(fetchObject(ctx, r.Client, a.Namespace, TrustedCABundleConfigMapName, configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKey(trustedCABundleKey))
+
+				By("removing the trusted CA certificates from the Proxy")
+				unstructured.RemoveNestedField(proxy.Object, "spec", "trustedCA")
+				Expect(r.Client.Update(ctx, proxy)).To(Succeed())
+
+				container = reconcileAndGetContainer()
+				Expect(container.VolumeMounts).ToNot(ContainElement(HaveField("Name", trustedCABundleVolumeName)))
+				Expect(fetchObject(ctx, r.Client, a.Namespace, TrustedCABundleConfigMapName, configMap)).ToNot(Succeed())
+			})
+
+			It("should not mount the trusted CA bundle if its mount path is used by a FileMount of the RolloutManager", func() {
+				a.Spec.FileMounts = []v1alpha1.RolloutManagerFileMount{{Name: "my-ca", MountPath: trustedCABundleMountPath, ConfigMap: "my-ca"}}
+				Expect(r.Client.Update(ctx, &a)).To(Succeed())
+
+				reconcileAndGetContainer()
+				configMap := &corev1.ConfigMap{}
+				Expect(fetchObject(ctx, r.Client, a.Namespace, TrustedCABundleConfigMapName, configMap)).To(Succeed())
+				configMap.Data = map[string]string{trustedCABundleKey: "-----BEGIN CERTIFICATE-----"}
+				Expect(r.Client.Update(ctx, configMap)).To(Succeed())
+
+				container := reconcileAndGetContainer()
+				Expect(container.VolumeMounts).To(ContainElement(HaveField("Name", "my-ca")))
+				Expect(container.VolumeMounts).ToNot(ContainElement(HaveField("Name", trustedCABundleVolumeName)))
+				Expect(container.Env).To(ContainElement(HaveField("Name", "HTTPS_PROXY")))
+			})
+		})
+	})
+})
@@ -0,0 +1,196 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ClusterScopedLockLeaseName is the name of the Lease which is held by the single cluster-scoped RolloutManager that is allowed to deploy a Rollouts controller, in the namespace of the operator (see ClusterScopedLockNamespace).
+	ClusterScopedLockLeaseName = "argo-rollouts-manager-cluster-scoped-lock"
+
+	// OperatorNamespaceEnvName is the environment variable which contains the namespace of the operator. If it is not set, the namespace of the ServiceAccount of the operator pod is used.
+	OperatorNamespaceEnvName = "OPERATOR_NAMESPACE"
+
+	// DefaultClusterScopedLockNamespace is the namespace of the cluster-scoped lock, if the namespace of the operator is not known (for example, when the operator runs outside of the cluster).
+	DefaultClusterScopedLockNamespace = "default"
+
+	// clusterScopedLockHolderUIDAnnotation is the annotation of the cluster-scoped lock which contains the UID of the RolloutManager that holds it, so that a RolloutManager which was recreated with the same name does not inherit the lock.
+	clusterScopedLockHolderUIDAnnotation = "argo-rollouts.argoproj.io/holder-uid"
+)
+
+// clusterScopedLockNamespace returns the namespace of the cluster-scoped lock.
+func (r *RolloutManagerReconciler) clusterScopedLockNamespace() string {
+	if r.ClusterScopedLockNamespace != "" {
+		return r.ClusterScopedLockNamespace
+	}
+	return DefaultClusterScopedLockNamespace
+}
+
+// MultipleClusterScopedRolloutManagersMessage returns the message of the status of a cluster-scoped RolloutManager which did not acquire the cluster-scoped lock, as it is held by the given RolloutManager.
+func MultipleClusterScopedRolloutManagersMessage(holder types.NamespacedName) string {
+	return fmt.Sprintf("%s; RolloutManager '%s' in namespace '%s' holds the lock of the cluster-scoped Rollouts controller (Lease '%s')", UnsupportedRolloutManagerConfiguration, holder.Name, holder.Namespace, ClusterScopedLockLeaseName)
+}
+
+// acquireClusterScopedLock acquires the cluster-scoped lock for the cluster-scoped RolloutManager, unless it is held by another RolloutManager. It returns the RolloutManager which holds the lock, or nil if it is held by cr.
+// The lock is a Lease, which is created (or taken over, if its holder no longer exists or is no longer a valid cluster-scoped RolloutManager) with optimistic concurrency: when cluster-scoped RolloutManagers are created concurrently, exactly one of them acquires it.
+// The lock does not expire, so that it is not renewed on each reconciliation: it is released when its holder is deleted.
+func (r *RolloutManagerReconciler) acquireClusterScopedLock(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (*types.NamespacedName, error) {
+
+	lockNamespace := r.clusterScopedLockNamespace()
+
+	lease := &coordinationv1.Lease{}
+	if err := fetchObject(ctx, r.Client, lockNamespace, ClusterScopedLockLeaseName, lease); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get the Lease %s: %w", ClusterScopedLockLeaseName, err)
+		}
+
+		// The lock is not written in a dry run (for example, when detecting drift)
+		if r.dryRun {
+			return nil, nil
+		}
+
+		lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: ClusterScopedLockLeaseName, Namespace: lockNamespace}}
+		setClusterScopedLockHolder(lease, cr)
+
+		err := r.Client.Create(ctx, lease)
+		if err == nil {
+			log.Info(fmt.Sprintf("RolloutManager %s acquired the cluster-scoped lock", client.ObjectKeyFromObject(&cr)))
+			return nil, nil
+		}
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create the Lease %s: %w", ClusterScopedLockLeaseName, err)
+		}
+
+		// The lock was acquired by another RolloutManager in the meantime
+		lease = &coordinationv1.Lease{}
+		if err := fetchObject(ctx, r.Client, lockNamespace, ClusterScopedLockLeaseName, lease); err != nil {
+			return nil, fmt.Errorf("failed to get the Lease %s: %w", ClusterScopedLockLeaseName, err)
+		}
+	}
+
+	if isClusterScopedLockHeldBy(lease, cr) {
+		return nil, nil
+	}
+
+	holder, held, err := r.isClusterScopedLockHeld(ctx, lease)
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		return holder, nil
+	}
+
+	if r.dryRun {
+		return nil, nil
+	}
+
+	// The holder no longer exists, or is no longer a valid cluster-scoped RolloutManager: the lock is taken over. On conflict, it was updated by another RolloutManager in the meantime, which is reported on the next reconciliation.
+	setClusterScopedLockHolder(lease, cr)
+	if err := r.Client.Update(ctx, lease); err != nil {
+		return nil, fmt.Errorf("failed to take over the Lease %s: %w", ClusterScopedLockLeaseName, err)
+	}
+	log.Info(fmt.Sprintf("RolloutManager %s took over the cluster-scoped lock, as it is no longer held", client.ObjectKeyFromObject(&cr)))
+
+	return nil, nil
+}
+
+// isClusterScopedLockHeld returns the RolloutManager which holds the cluster-scoped lock, and whether it still holds it: that is, it still exists (with the same UID), is not being deleted, and is a valid cluster-scoped RolloutManager.
+// A holder which has since failed validation (see isInvalidRolloutManager), or which now runs its Rollouts controller in a target namespace (see reconcileTargetNamespace), no longer holds the lock, as it no longer deploys a Rollouts controller.
+func (r *RolloutManagerReconciler) isClusterScopedLockHeld(ctx context.Context, lease *coordinationv1.Lease) (*types.NamespacedName, bool, error) {
+
+	if lease.Spec.HolderIdentity == nil {
+		return nil, false, nil
+	}
+
+	holder, err := parseClusterScopedLockHolder(*lease.Spec.HolderIdentity)
+	if err != nil {
+		log.Info(fmt.Sprintf("Ignoring the holder of the Lease %s: %v", ClusterScopedLockLeaseName, err))
+		return nil, false, nil
+	}
+
+	holderRolloutManager := &rolloutsmanagerv1alpha1.RolloutManager{}
+	if err := r.Client.Get(ctx, holder, holderRolloutManager); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &holder, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get the RolloutManager %s which holds the Lease %s: %w", holder, ClusterScopedLockLeaseName, err)
+	}
+
+	held := string(holderRolloutManager.UID) == lease.Annotations[clusterScopedLockHolderUIDAnnotation] &&
+		holderRolloutManager.DeletionTimestamp == nil &&
+		!holderRolloutManager.Spec.NamespaceScoped &&
+		allowedClusterScopedNamespace(*holderRolloutManager) &&
+		!isTargetNamespaceEnabled(*holderRolloutManager) &&
+		!isInvalidRolloutManager(*holderRolloutManager)
+
+	return &holder, held, nil
+}
+
+// isInvalidRolloutManager returns true if the current spec of the RolloutManager failed validation (see validateRolloutManager), from its status: it is in the Failure phase, and the reason of its condition (for its current generation) is neither a success nor an error.
+func isInvalidRolloutManager(rm rolloutsmanagerv1alpha1.RolloutManager) bool {
+
+	if rm.Status.Phase != rolloutsmanagerv1alpha1.PhaseFailure {
+		return false
+	}
+
+	condition := meta.FindStatusCondition(rm.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerConditionType)
+	if condition == nil || condition.ObservedGeneration != rm.Generation {
+		return false
+	}
+
+	switch condition.Reason {
+	case rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess, rolloutsmanagerv1alpha1.RolloutManagerReasonErrorOccurred:
+		return false
+	}
+	return true
+}
+
+// isClusterScopedLockHeldBy returns true if the cluster-scoped lock is held by the RolloutManager.
+func isClusterScopedLockHeldBy(lease *coordinationv1.Lease, cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == client.ObjectKeyFromObject(&cr).String() &&
+		lease.Annotations[clusterScopedLockHolderUIDAnnotation] == string(cr.UID)
+}
+
+// setClusterScopedLockHolder sets the RolloutManager as the holder of the cluster-scoped lock.
+func setClusterScopedLockHolder(lease *coordinationv1.Lease, cr rolloutsmanagerv1alpha1.RolloutManager) {
+
+	holderIdentity := client.ObjectKeyFromObject(&cr).String()
+	now := metav1.NewMicroTime(time.Now())
+
+	if lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != "" {
+		transitions := int32(1)
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions + 1
+		}
+		lease.Spec.LeaseTransitions = &transitions
+	}
+
+	lease.Spec.HolderIdentity = &holderIdentity
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[clusterScopedLockHolderUIDAnnotation] = string(cr.UID)
+}
+
+// parseClusterScopedLockHolder returns the RolloutManager of the holder identity of the cluster-scoped lock, which is of the form '<namespace>/<name>'.
+func parseClusterScopedLockHolder(holderIdentity string) (types.NamespacedName, error) {
+	namespace, name, found := strings.Cut(holderIdentity, "/")
+	if !found || namespace == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid holder identity '%s'", holderIdentity)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
@@ -0,0 +1,93 @@
+package rollouts
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ManagedByLabel and VersionLabel are recommended labels of Kubernetes (see https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/), which are set on the resources of a RolloutManager, in addition to the 'name', 'part-of' and 'component' labels, unless .spec.skipRecommendedLabels is true.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	VersionLabel   = "app.kubernetes.io/version"
+
+	// ManagedByLabelValue is the value of the ManagedByLabel
+	ManagedByLabelValue = "argo-rollouts-manager"
+)
+
+// reservedCommonLabelKeys are the labels that are set by the operator itself, which may not be overridden via COMMON_LABELS.
+var reservedCommonLabelKeys = []string{
+	"app.kubernetes.io/name",
+	"app.kubernetes.io/part-of",
+	"app.kubernetes.io/component",
+}
+
+// getRecommendedLabels returns the ManagedByLabel and VersionLabel of the resources of the RolloutManager, or nil if they are skipped via .spec.skipRecommendedLabels. The version is the tag of the Rollouts controller image: it is omitted if the image is pinned by digest.
+func getRecommendedLabels(cr rolloutsmanagerv1alpha1.RolloutManager) map[string]string {
+
+	if cr.Spec.SkipRecommendedLabels {
+		return nil
+	}
+
+	labels := map[string]string{
+		ManagedByLabel: ManagedByLabelValue,
+	}
+	if version := newMetadataTemplateData(cr).Version; version != "" && len(validation.IsValidLabelValue(version)) == 0 {
+		labels[VersionLabel] = version
+	}
+	return labels
+}
+
+// getCommonLabels returns the labels that are set via the COMMON_LABELS environment variable, or nil if it is not set or is invalid (see ResolveCommonLabels).
+func getCommonLabels() map[string]string {
+	labels, err := parseCommonLabels(os.Getenv(CommonLabelsEnvName))
+	if err != nil {
+		return nil
+	}
+	return labels
+}
+
+// ResolveCommonLabels returns the labels that are set via the COMMON_LABELS environment variable, on all resources created by the operator.
+// This is called when the operator starts: an error is returned if a label is invalid, or would override a label set by the operator.
+func ResolveCommonLabels() (map[string]string, error) {
+	return parseCommonLabels(os.Getenv(CommonLabelsEnvName))
+}
+
+// parseCommonLabels parses a comma-separated list of 'key=value' pairs (e.g. 'team=payments,cost-center=1234').
+func parseCommonLabels(value string) (map[string]string, error) {
+
+	var res map[string]string
+
+	for _, pair := range splitList(value) {
+		if pair == "" {
+			continue
+		}
+
+		key, labelValue, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid label %q in %s: expected 'key=value'", pair, CommonLabelsEnvName)
+		}
+		key = strings.TrimSpace(key)
+		labelValue = strings.TrimSpace(labelValue)
+
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key %q in %s: %s", key, CommonLabelsEnvName, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(labelValue); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid value of label %q in %s: %s", key, CommonLabelsEnvName, strings.Join(errs, "; "))
+		}
+		if contains(reservedCommonLabelKeys, key) {
+			return nil, fmt.Errorf("label %q in %s is set by the operator, and may not be overridden", key, CommonLabelsEnvName)
+		}
+
+		if res == nil {
+			res = map[string]string{}
+		}
+		res[key] = labelValue
+	}
+
+	return res, nil
+}
@@ -0,0 +1,155 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Common labels tests", func() {
+
+	DescribeTable("parseCommonLabels should parse valid labels", func(value string, expected map[string]string) {
+		labels, err := parseCommonLabels(value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(labels).To(Equal(expected))
+	},
+		Entry("empty", "", nil),
+		Entry("single label", "team=payments", map[string]string{"team": "payments"}),
+		Entry("multiple labels, with whitespace", "team = payments, example.com/cost-center=1234 ,", map[string]string{"team": "payments", "example.com/cost-center": "1234"}),
+		Entry("empty value", "team=", map[string]string{"team": ""}),
+	)
+
+	DescribeTable("parseCommonLabels should return an error for invalid labels", func(value string, expectedError string) {
+		_, err := parseCommonLabels(value)
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+	},
+		Entry("missing value", "team", `invalid label "team"`),
+		Entry("invalid key", "my team=payments", `invalid label key "my team"`),
+		Entry("invalid value", "team=pay ments", `invalid value of label "team"`),
+		Entry("label set by the operator", "app.kubernetes.io/name=other", `label "app.kubernetes.io/name" in COMMON_LABELS is set by the operator`),
+	)
+
+	DescribeTable("getRecommendedLabels should derive the version from the Rollouts controller image", func(version string, expectedVersion string) {
+		cr := *makeTestRolloutManager()
+		cr.Spec.Version = version
+
+		labels := getRecommendedLabels(cr)
+		Expect(labels).To(HaveKeyWithValue(ManagedByLabel, ManagedByLabelValue))
+		if expectedVersion == "" {
+			Expect(labels).ToNot(HaveKey(VersionLabel))
+		} else {
+			Expect(labels).To(HaveKeyWithValue(VersionLabel, expectedVersion))
+		}
+	},
+		Entry("default version", "", DefaultArgoRolloutsVersion),
+		Entry("version specified in the RolloutManager", "v1.6.0", "v1.6.0"),
+		Entry("image pinned by digest", "sha256:0c0b7ce6d2f0b7c6a3b3e3e5a4f4d1b2c3a4e5f60718293a4b5c6d7e8f901234", ""),
+	)
+
+	It("getRecommendedLabels should return no labels if they are skipped", func() {
+		cr := *makeTestRolloutManager()
+		cr.Spec.SkipRecommendedLabels = true
+		Expect(getRecommendedLabels(cr)).To(BeNil())
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			rm  *rolloutsmanagerv1alpha1.RolloutManager
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			rm = makeTestRolloutManager()
+			rm.Spec.AdditionalMetadata = &rolloutsmanagerv1alpha1.ResourceMetadata{
+				Labels: map[string]string{"team": "overridden", "app": "rollouts"},
+			}
+			r = makeTestReconciler(rm)
+			Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+			os.Setenv(CommonLabelsEnvName, "team=payments,cost-center=1234")
+			DeferCleanup(os.Unsetenv, CommonLabelsEnvName)
+
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}}
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		expectedLabels := map[string]string{"team": "payments", "cost-center": "1234", "app": "rollouts"}
+
+		It("should set the common labels on all resources, taking precedence over the labels of .spec.additionalMetadata", func() {
+
+			sa := &corev1.ServiceAccount{}
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+			Expect(sa.Labels).To(Equal(combineStringMaps(sa.Labels, expectedLabels)))
+
+			clusterRole := &rbacv1.ClusterRole{}
+			Expect(fetchObject(ctx, r.Client, "", fmt.Sprintf("%s-%s", DefaultArgoRolloutsResourceName, "aggregate-to-admin"), clusterRole)).To(Succeed())
+			Expect(clusterRole.Labels).To(Equal(combineStringMaps(clusterRole.Labels, expectedLabels)))
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Labels).To(Equal(combineStringMaps(deployment.Labels, expectedLabels)))
+
+			By("verifying that the common labels are set on the pods, but not in the selector")
+			Expect(deployment.Spec.Template.Labels).To(Equal(combineStringMaps(deployment.Spec.Template.Labels, expectedLabels)))
+			Expect(deployment.Spec.Selector.MatchLabels).ToNot(HaveKey("cost-center"))
+		})
+
+		It("should set the recommended labels on all resources, and on the pods, but not in the selector", func() {
+
+			recommendedLabels := map[string]string{ManagedByLabel: ManagedByLabelValue, VersionLabel: DefaultArgoRolloutsVersion}
+
+			sa := &corev1.ServiceAccount{}
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+			Expect(sa.Labels).To(Equal(combineStringMaps(sa.Labels, recommendedLabels)))
+
+			clusterRole := &rbacv1.ClusterRole{}
+			Expect(fetchObject(ctx, r.Client, "", fmt.Sprintf("%s-%s", DefaultArgoRolloutsResourceName, "aggregate-to-admin"), clusterRole)).To(Succeed())
+			Expect(clusterRole.Labels).To(Equal(combineStringMaps(clusterRole.Labels, recommendedLabels)))
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Labels).To(Equal(combineStringMaps(deployment.Labels, recommendedLabels)))
+			Expect(deployment.Spec.Template.Labels).To(Equal(combineStringMaps(deployment.Spec.Template.Labels, recommendedLabels)))
+			Expect(deployment.Spec.Selector.MatchLabels).ToNot(HaveKey(ManagedByLabel))
+			Expect(deployment.Spec.Selector.MatchLabels).ToNot(HaveKey(VersionLabel))
+
+			By("updating the version label when the version changes")
+			Expect(r.Client.Get(ctx, req.NamespacedName, rm)).To(Succeed())
+			rm.Spec.Version = "v1.6.0"
+			Expect(r.Client.Update(ctx, rm)).To(Succeed())
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+			Expect(sa.Labels).To(HaveKeyWithValue(VersionLabel, "v1.6.0"))
+		})
+
+		It("should restore the common labels if they are removed from a resource", func() {
+			sa := &corev1.ServiceAccount{}
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+			delete(sa.Labels, "cost-center")
+			Expect(r.Client.Update(ctx, sa)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+			Expect(sa.Labels).To(HaveKeyWithValue("cost-center", "1234"))
+		})
+	})
+})
@@ -0,0 +1,286 @@
+package rollouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// rolloutsComponent is a part of the resources of RolloutManagers which, when the ComponentControllers feature is enabled, is reconciled by its own controller, with its own watches and workqueue: a change of (or a failure to reconcile) its resources only causes the component to be reconciled again, rather than all resources of the RolloutManager.
+// The other resources (the ServiceAccount, Deployment and VerticalPodAutoscaler of the Rollouts controller) and the status of the RolloutManager are reconciled by the RolloutManager controller.
+type rolloutsComponent struct {
+
+	// name of the component, which is used to name its controller
+	name string
+
+	// conditionType is the type of the condition of the RolloutManager which reports whether the resources of the component were successfully reconciled
+	conditionType string
+
+	// reconcile reconciles the resources of the component, for a RolloutManager which passed validation. It returns the duration after which the component should be reconciled again, if any.
+	reconcile func(ctx context.Context, r *RolloutManagerReconciler, cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error)
+
+	// watches adds the watches of the resources of the component to the builder of its controller, in addition to RolloutManagers.
+	watches func(bld *builder.Builder, r *RolloutManagerReconciler, mgr ctrl.Manager) error
+}
+
+// rolloutsComponents are the components of RolloutManagers which are reconciled by their own controllers, when the ComponentControllers feature is enabled.
+var rolloutsComponents = []rolloutsComponent{
+	{
+		name:          "rbac",
+		conditionType: rolloutsmanagerv1alpha1.RolloutManagerRBACReconciledConditionType,
+		reconcile: func(ctx context.Context, r *RolloutManagerReconciler, cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error) {
+
+			// The ServiceAccount is reconciled by the RolloutManager controller: as it is watched, its creation triggers this component again
+			sa := &corev1.ServiceAccount{}
+			if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, sa); err != nil {
+				if apierrors.IsNotFound(err) {
+					return 0, fmt.Errorf("waiting for the ServiceAccount %s of the Rollouts controller to be created", DefaultArgoRolloutsResourceName)
+				}
+				return 0, fmt.Errorf("failed to get the ServiceAccount %s: %w", DefaultArgoRolloutsResourceName, err)
+			}
+
+			// The changes of the permissions are reported by Events and metrics: the RBACRulesChanged condition is only set by the RolloutManager controller
+			_, err := r.reconcileRolloutsRBAC(ctx, cr, sa)
+			return 0, err
+		},
+		watches: func(bld *builder.Builder, r *RolloutManagerReconciler, _ ctrl.Manager) error {
+			bld.Owns(&corev1.ServiceAccount{})
+			bld.Owns(&rbacv1.Role{})
+			bld.Owns(&rbacv1.RoleBinding{})
+
+			if r.clusterAPIDisabled() {
+				return nil
+			}
+
+			// See SetupWithManager: ClusterRoles/ClusterRoleBindings cannot be owned by RolloutManagers
+			bld.Watches(&rbacv1.ClusterRole{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				return object.GetName() == DefaultArgoRolloutsResourceName
+			})))
+			bld.Watches(&rbacv1.ClusterRoleBinding{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				return object.GetName() == DefaultArgoRolloutsResourceName
+			})))
+
+			// When a Namespace is created, or starts being deleted, the rollout-user Roles are created in it, or deleted from it
+			bld.WatchesMetadata(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllRolloutManagers), builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(createEvent event.CreateEvent) bool {
+					return true
+				},
+				DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+					return false
+				},
+				GenericFunc: func(genericEvent event.GenericEvent) bool {
+					return false
+				},
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return (e.ObjectOld.GetDeletionTimestamp() == nil) != (e.ObjectNew.GetDeletionTimestamp() == nil)
+				},
+			}))
+			return nil
+		},
+	},
+	{
+		name:          "config",
+		conditionType: rolloutsmanagerv1alpha1.RolloutManagerConfigReconciledConditionType,
+		reconcile: func(ctx context.Context, r *RolloutManagerReconciler, cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error) {
+			return r.reconcileRolloutsConfig(ctx, cr)
+		},
+		watches: func(bld *builder.Builder, r *RolloutManagerReconciler, _ ctrl.Manager) error {
+			bld.Owns(&corev1.ConfigMap{})
+			bld.Owns(&corev1.Secret{})
+
+			if r.FeatureGates.Enabled(NotificationSecretOnDemand) {
+				// See SetupWithManager
+				bld.Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.enqueueRolloutManagersInNamespace), builder.WithPredicates(predicate.And(predicate.NewPredicateFuncs(isNotificationConfigMap), createdOrDeletedPredicate())))
+			}
+			return nil
+		},
+	},
+	{
+		name:          "monitoring",
+		conditionType: rolloutsmanagerv1alpha1.RolloutManagerMonitoringReconciledConditionType,
+		reconcile: func(ctx context.Context, r *RolloutManagerReconciler, cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error) {
+			return 0, r.reconcileRolloutsMonitoring(ctx, cr)
+		},
+		watches: func(bld *builder.Builder, r *RolloutManagerReconciler, mgr ctrl.Manager) error {
+			bld.Owns(&corev1.Service{})
+
+			if crdExists, err := r.doesCRDExist(mgr.GetConfig(), monitoringv1.SchemeGroupVersion.WithResource(monitoringv1.ServiceMonitorName)); err != nil {
+				return err
+			} else if crdExists {
+				// We only attempt to own ServiceMonitor if it exists on the cluster on startup
+				bld.Owns(&monitoringv1.ServiceMonitor{})
+			}
+			return nil
+		},
+	},
+}
+
+// rolloutsComponentReconciler reconciles a component of RolloutManagers (see rolloutsComponent).
+type rolloutsComponentReconciler struct {
+	*RolloutManagerReconciler
+
+	component rolloutsComponent
+}
+
+// blank assignment to verify that rolloutsComponentReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &rolloutsComponentReconciler{}
+
+// Reconcile reconciles the resources of the component for a RolloutManager, and reports the result in the condition of the component.
+func (c *rolloutsComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	reqLogger := logr.FromContext(ctx, "Request.Namespace", req.Namespace, "Request.Name", req.Name, "Component", c.component.name)
+
+	// Deleted RolloutManagers, and namespaces which no longer exist or are being deleted, are handled by the RolloutManager controller
+	if !c.clusterAPIDisabled() {
+		rolloutManagerNamespace, err := fetchObjectMetadata(ctx, c.Client, namespaceGVK, "", req.Namespace)
+		if err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if rolloutManagerNamespace.DeletionTimestamp != nil {
+			return ctrl.Result{}, nil
+		}
+	}
+
+	rolloutManager := &rolloutsmanagerv1alpha1.RolloutManager{}
+	if err := c.Client.Get(ctx, req.NamespacedName, rolloutManager); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	cr := *rolloutManager.DeepCopy()
+	now := time.Now()
+	invalid, pprofExpirationTime, err := c.validateRolloutManager(ctx, &cr, now)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if invalid != nil {
+		// The reason is reported by the RolloutManager controller
+		reqLogger.Info("Skipping reconciliation of component, as the RolloutManager is invalid")
+		return ctrl.Result{}, nil
+	}
+
+	reqLogger.Info("Reconciling component of RolloutManager")
+	requeueAfter, reconcileErr := c.component.reconcile(ctx, c.RolloutManagerReconciler, cr)
+
+	condition := createCondition("")
+	if reconcileErr != nil {
+		condition = createCondition(reconcileErr.Error())
+	}
+
+	var policyErr *resourcePolicyViolationError
+	if errors.As(reconcileErr, &policyErr) {
+		// Policy violations are not resolved by retrying: the RolloutManager (or the resource policies of the operator) must be changed
+		condition = createCondition(reconcileErr.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonPolicyViolation)
+		reconcileErr = nil
+	}
+
+	var escalationErr *rbacEscalationDeniedError
+	if errors.As(reconcileErr, &escalationErr) {
+		condition = createCondition(reconcileErr.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonRBACEscalationDenied)
+	}
+
+	if apierrors.HasStatusCause(reconcileErr, corev1.NamespaceTerminatingCause) {
+		// The namespace started being deleted during the reconciliation: retrying would only fail again, until the namespace is gone
+		condition = createCondition(reconcileErr.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonNamespaceTerminating)
+		reconcileErr = nil
+	}
+
+	condition.Type = c.component.conditionType
+
+	statusCtx, cancel := statusUpdateContext(ctx)
+	defer cancel()
+	if err := setComponentCondition(statusCtx, rolloutManager, condition, c.statusClient()); err != nil {
+		reqLogger.Error(err, "unable to update status of RolloutManager")
+		return ctrl.Result{}, err
+	}
+
+	if reconcileErr != nil {
+		return ctrl.Result{}, reconcileErr
+	}
+
+	return ctrl.Result{RequeueAfter: nextRequeueAfter(rolloutManager.Status.Phase, minRequeueAfter(requeueAfter, pprofRequeueAfter(pprofExpirationTime, now)))}, nil
+}
+
+// setComponentCondition sets the condition of a component on the status of the RolloutManager (along with the readiness conditions, which reflect it), and updates the status if it changed.
+func setComponentCondition(ctx context.Context, rm *rolloutsmanagerv1alpha1.RolloutManager, condition metav1.Condition, k8sClient client.Client) error {
+
+	condition.ObservedGeneration = rm.Generation
+
+	changed, newConditions := insertOrUpdateConditionsInSlice(condition, rm.Status.Conditions)
+	rm.Status.Conditions = newConditions
+
+	if setReadinessConditions(rm) {
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return k8sClient.Status().Update(ctx, rm)
+}
+
+// isComponentConditionType returns true if the condition type is that of a component which is reconciled by its own controller (see rolloutsComponents).
+func isComponentConditionType(conditionType string) bool {
+	for _, component := range rolloutsComponents {
+		if component.conditionType == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// removeComponentConditions removes the conditions of the components which are reconciled by their own controllers from the RolloutManager, for example once the ComponentControllers feature is disabled. It returns true if the conditions of the RolloutManager changed.
+func removeComponentConditions(rm *rolloutsmanagerv1alpha1.RolloutManager) bool {
+	var changed bool
+	for _, component := range rolloutsComponents {
+		var removed bool
+		removed, rm.Status.Conditions = removeConditionFromSlice(component.conditionType, rm.Status.Conditions)
+		changed = changed || removed
+	}
+	return changed
+}
+
+// setupComponentControllers sets up a controller for each of the components of RolloutManagers, with the Manager.
+func (r *RolloutManagerReconciler) setupComponentControllers(mgr ctrl.Manager) error {
+
+	for _, component := range rolloutsComponents {
+
+		bld := ctrl.NewControllerManagedBy(mgr).Named("rolloutmanager-" + component.name)
+
+		// Only changes of the .spec of RolloutManagers require the component to be reconciled again, while its resources are watched separately
+		bld.For(&rolloutsmanagerv1alpha1.RolloutManager{}, builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+
+		bld.WithOptions(controller.Options{RateLimiter: newFailureRateLimiter()})
+
+		// Whether a RolloutManager is valid may depend on the other RolloutManagers on the cluster (see acquireClusterScopedLock)
+		bld.Watches(
+			&rolloutsmanagerv1alpha1.RolloutManager{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueOtherRolloutManagersExceptObj),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, createdOrDeletedPredicate())))
+
+		if err := component.watches(bld, r, mgr); err != nil {
+			return err
+		}
+
+		if err := bld.Complete(&rolloutsComponentReconciler{RolloutManagerReconciler: r, component: component}); err != nil {
+			return fmt.Errorf("unable to set up the controller of component %s: %w", component.name, err)
+		}
+	}
+
+	return nil
+}
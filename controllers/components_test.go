@@ -0,0 +1,143 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Component controller tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+		req reconcile.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+
+		r = makeTestReconciler(&cr)
+		r.FeatureGates = FeatureGates{ComponentControllers: true}
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+	})
+
+	getComponent := func(name string) rolloutsComponent {
+		for _, component := range rolloutsComponents {
+			if component.name == name {
+				return component
+			}
+		}
+		Fail("unknown component " + name)
+		return rolloutsComponent{}
+	}
+
+	reconcileComponent := func(name string) (reconcile.Result, error) {
+		componentReconciler := &rolloutsComponentReconciler{RolloutManagerReconciler: r, component: getComponent(name)}
+		return componentReconciler.Reconcile(ctx, req)
+	}
+
+	getCondition := func(conditionType string) *metav1.Condition {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		for i := range cr.Status.Conditions {
+			if cr.Status.Conditions[i].Type == conditionType {
+				return &cr.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	It("should only reconcile the resources of the RolloutManager controller, and leave the components to their own controllers", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &corev1.ServiceAccount{})).To(Succeed())
+
+		err = fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsConfigMapName, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, &corev1.Service{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		err = fetchObject(ctx, r.Client, "", DefaultArgoRolloutsResourceName, &rbacv1.ClusterRoleBinding{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("reconciling each component")
+		for _, component := range rolloutsComponents {
+			_, err := reconcileComponent(component.name)
+			Expect(err).ToNot(HaveOccurred())
+
+			condition := getCondition(component.conditionType)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.ObservedGeneration).To(Equal(cr.Generation))
+		}
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsConfigMapName, &corev1.ConfigMap{})).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, &corev1.Service{})).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, "", DefaultArgoRolloutsResourceName, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+
+		By("removing the conditions of the components once the feature is disabled")
+		r.FeatureGates = nil
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, component := range rolloutsComponents {
+			Expect(getCondition(component.conditionType)).To(BeNil())
+		}
+	})
+
+	It("should report a failure of a component in its condition, and in the readiness of the RolloutManager", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Delete(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: cr.Namespace}})).To(Succeed())
+
+		_, err = reconcileComponent("rbac")
+		Expect(err).To(HaveOccurred())
+
+		condition := getCondition(rolloutsmanagerv1alpha1.RolloutManagerRBACReconciledConditionType)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonErrorOccurred))
+		Expect(condition.Message).To(ContainSubstring("waiting for the ServiceAccount"))
+
+		ready := getCondition(rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType)
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(getCondition(rolloutsmanagerv1alpha1.RolloutManagerReconcilingConditionType)).ToNot(BeNil())
+
+		By("verifying that the other components are not affected")
+		_, err = reconcileComponent("config")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getCondition(rolloutsmanagerv1alpha1.RolloutManagerConfigReconciledConditionType).Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should not reconcile the components of an invalid RolloutManager", func() {
+		cr.Spec.Debug = &rolloutsmanagerv1alpha1.RolloutManagerDebugSpec{Pprof: true, TTL: "soon"}
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+		_, err := reconcileComponent("config")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsConfigMapName, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(getCondition(rolloutsmanagerv1alpha1.RolloutManagerConfigReconciledConditionType)).To(BeNil())
+	})
+})
@@ -52,12 +52,19 @@ func (r *RolloutManagerReconciler) reconcileConfigMap(ctx context.Context, cr ro
 	if err := fetchObject(ctx, r.Client, cr.Namespace, desiredConfigMap.Name, actualConfigMap); err != nil {
 		if errors.IsNotFound(err) {
 			// ConfigMap is not present, create default config map
+			if err := r.setControllerReference(&cr, desiredConfigMap); err != nil {
+				return err
+			}
 			log.Info("configMap not found, creating default configmap with openshift route plugin information")
 			return r.Client.Create(ctx, desiredConfigMap)
 		}
 		return fmt.Errorf("failed to get the serviceAccount associated with %s: %w", desiredConfigMap.Name, err)
 	}
 
+	if actualConfigMap.Data == nil {
+		actualConfigMap.Data = map[string]string{}
+	}
+
 	var actualTrafficRouterPlugins []pluginItem
 	if err = yaml.Unmarshal([]byte(actualConfigMap.Data[TrafficRouterPluginConfigMapKey]), &actualTrafficRouterPlugins); err != nil {
 		return fmt.Errorf("failed to unmarshal traffic router plugins from ConfigMap: %s", err)
@@ -73,9 +80,8 @@ func (r *RolloutManagerReconciler) reconcileConfigMap(ctx context.Context, cr ro
 					return fmt.Errorf("error marshalling trafficRouterPlugin to string %s", err)
 				}
 
-				actualConfigMap.Data = map[string]string{
-					TrafficRouterPluginConfigMapKey: string(pluginBytes),
-				}
+				// Only replace the plugins: the other keys of the ConfigMap may contain user configuration
+				actualConfigMap.Data[TrafficRouterPluginConfigMapKey] = string(pluginBytes)
 
 				return r.Client.Update(ctx, actualConfigMap)
 			} else {
@@ -43,6 +43,10 @@ var _ = Describe("ConfigMap Test", func() {
 		Expect(fetchedConfigMap.Data[TrafficRouterPluginConfigMapKey]).To(ContainSubstring(OpenShiftRolloutPluginName))
 		Expect(fetchedConfigMap.Data[TrafficRouterPluginConfigMapKey]).To(ContainSubstring(r.OpenShiftRoutePluginLocation))
 
+		By("Verify that the ConfigMap is owned by the RolloutManager, so that changes to it are watched")
+		Expect(fetchedConfigMap.OwnerReferences).To(HaveLen(1))
+		Expect(fetchedConfigMap.OwnerReferences[0].Name).To(Equal(a.Name))
+
 		By("Call reconcileConfigMap again")
 		Expect(r.reconcileConfigMap(ctx, a)).To(Succeed())
 
@@ -114,6 +118,10 @@ var _ = Describe("ConfigMap Test", func() {
 		Expect(fetchedConfigMap.Data[TrafficRouterPluginConfigMapKey]).To(ContainSubstring(OpenShiftRolloutPluginName))
 		Expect(fetchedConfigMap.Data[TrafficRouterPluginConfigMapKey]).To(ContainSubstring(r.OpenShiftRoutePluginLocation))
 
+		By("adding another key to the ConfigMap")
+		fetchedConfigMap.Data["metricProviderPlugins"] = "- name: test/metric-plugin\n  location: https://test-metric-path\n"
+		Expect(r.Client.Update(ctx, fetchedConfigMap)).To(Succeed())
+
 		// overriding this value with new test url to verify whether it updated the existing configMap with the new url
 		r.OpenShiftRoutePluginLocation = "test-updated-url"
 
@@ -125,5 +133,8 @@ var _ = Describe("ConfigMap Test", func() {
 		Expect(fetchedConfigMap.Data[TrafficRouterPluginConfigMapKey]).To(ContainSubstring(OpenShiftRolloutPluginName))
 		Expect(fetchedConfigMap.Data[TrafficRouterPluginConfigMapKey]).To(ContainSubstring("test-updated-url"))
 
+		By("verifying that the other key of the ConfigMap is preserved")
+		Expect(fetchedConfigMap.Data).To(HaveKeyWithValue("metricProviderPlugins", ContainSubstring("test/metric-plugin")))
+
 	})
 })
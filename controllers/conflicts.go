@@ -0,0 +1,109 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// rolloutsNameLabel is set to 'argo-rollouts' on the Rollouts controller Deployment and ClusterRole, by the operator as well as the upstream install manifests and Helm chart.
+	rolloutsNameLabel = "app.kubernetes.io/name"
+
+	// rolloutsComponentLabel is set to DefaultArgoRolloutsResourceName by the operator, while other installs use a different value (e.g. 'rollouts-controller').
+	rolloutsComponentLabel = "app.kubernetes.io/component"
+
+	// namespacedArg is the argument of the Rollouts controller that restricts it to its own namespace
+	namespacedArg = "--namespaced"
+)
+
+// isNonOperatorRolloutsDeployment returns true if the Deployment is a Rollouts controller which was not created by this operator (e.g. installed via Helm or kubectl).
+func isNonOperatorRolloutsDeployment(obj client.Object) bool {
+
+	if obj.GetLabels()[rolloutsNameLabel] != DefaultArgoRolloutsResourceName {
+		return false
+	}
+
+	owner := metav1.GetControllerOf(obj)
+	return owner == nil || owner.Kind != "RolloutManager" || !strings.HasPrefix(owner.APIVersion, rolloutsmanagerv1alpha1.GroupVersion.Group+"/")
+}
+
+// isNonOperatorRolloutsClusterRole returns true if the ClusterRole is for a Rollouts controller, but was not created by this operator.
+func isNonOperatorRolloutsClusterRole(clusterRole rbacv1.ClusterRole) bool {
+	return clusterRole.Labels[rolloutsNameLabel] == DefaultArgoRolloutsResourceName &&
+		clusterRole.Labels[rolloutsComponentLabel] != DefaultArgoRolloutsResourceName
+}
+
+// isNamespacedRolloutsDeployment returns true if the Rollouts controller of the Deployment only watches its own namespace.
+func isNamespacedRolloutsDeployment(deployment appsv1.Deployment) bool {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if slices.Contains(container.Args, namespacedArg) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectConflictingInstallations returns the Argo Rollouts resources which were not created by this operator, but which may conflict with the Rollouts controller of the RolloutManager, as '(kind) (namespace)/(name)' strings:
+// - for a namespace-scoped RolloutManager: Rollouts controller Deployments in its namespace, or cluster-scoped Rollouts controller Deployments in any namespace.
+// - for a cluster-scoped RolloutManager: any Rollouts controller Deployment, and any Rollouts controller ClusterRole.
+// Two Rollouts controllers that reconcile the same Rollouts cause nondeterministic behaviour.
+func (r *RolloutManagerReconciler) detectConflictingInstallations(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) ([]string, error) {
+
+	res := []string{}
+
+	var deployments appsv1.DeploymentList
+	if err := r.Client.List(ctx, &deployments, append(r.namespaceListOptions(cr.Namespace), client.MatchingLabels{rolloutsNameLabel: DefaultArgoRolloutsResourceName})...); err != nil {
+		return nil, fmt.Errorf("unable to list Deployments: %w", err)
+	}
+
+	for _, deployment := range deployments.Items {
+
+		if !isNonOperatorRolloutsDeployment(&deployment) {
+			continue
+		}
+
+		if cr.Spec.NamespaceScoped && deployment.Namespace != cr.Namespace && isNamespacedRolloutsDeployment(deployment) {
+			// A namespace-scoped Rollouts controller in another namespace does not watch the namespace of the RolloutManager
+			continue
+		}
+
+		res = append(res, "Deployment "+deployment.Namespace+"/"+deployment.Name)
+	}
+
+	if !cr.Spec.NamespaceScoped {
+
+		var clusterRoles rbacv1.ClusterRoleList
+		if err := r.Client.List(ctx, &clusterRoles, client.MatchingLabels{rolloutsNameLabel: DefaultArgoRolloutsResourceName}); err != nil {
+			return nil, fmt.Errorf("unable to list ClusterRoles: %w", err)
+		}
+
+		for _, clusterRole := range clusterRoles.Items {
+			if isNonOperatorRolloutsClusterRole(clusterRole) {
+				res = append(res, "ClusterRole /"+clusterRole.Name)
+			}
+		}
+	}
+
+	sort.Strings(res)
+
+	return res, nil
+}
+
+// createConflictingInstallationCondition returns the ConflictingInstallationDetected condition for the given conflicting resources.
+func createConflictingInstallationCondition(conflictingInstallations []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerConflictingInstallationConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonConflictingInstallation,
+		Message: ConflictingInstallationMessage + strings.Join(conflictingInstallations, ", "),
+	}
+}
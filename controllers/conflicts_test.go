@@ -0,0 +1,173 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Conflicting installation tests", func() {
+
+	var (
+		ctx context.Context
+		rm  *rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	// makeHelmRolloutsDeployment returns a Rollouts controller Deployment, as created by the Argo Rollouts Helm chart
+	makeHelmRolloutsDeployment := func(namespace string, args ...string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "argo-rollouts",
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "argo-rollouts",
+					"app.kubernetes.io/component": "rollouts-controller",
+					"app.kubernetes.io/part-of":   "argo-rollouts",
+				},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "argo-rollouts", Args: args}},
+					},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		rm = makeTestRolloutManager()
+		r = makeTestReconciler(rm)
+		Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+	})
+
+	Context("isNonOperatorRolloutsDeployment", func() {
+
+		It("should return false for the Deployment created by the operator", func() {
+			deployment := makeHelmRolloutsDeployment(rm.Namespace)
+			setRolloutsLabelsAndAnnotationsToObject(&deployment.ObjectMeta, *rm)
+			deployment.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: rolloutsmanagerv1alpha1.GroupVersion.String(),
+				Kind:       "RolloutManager",
+				Name:       rm.Name,
+				Controller: &[]bool{true}[0],
+			}}
+			Expect(isNonOperatorRolloutsDeployment(deployment)).To(BeFalse())
+		})
+
+		It("should return true for a Rollouts controller Deployment created by Helm", func() {
+			Expect(isNonOperatorRolloutsDeployment(makeHelmRolloutsDeployment(rm.Namespace))).To(BeTrue())
+		})
+
+		It("should return false for an unrelated Deployment", func() {
+			deployment := makeHelmRolloutsDeployment(rm.Namespace)
+			deployment.Labels = map[string]string{"app.kubernetes.io/name": "argo-rollouts-dashboard"}
+			Expect(isNonOperatorRolloutsDeployment(deployment)).To(BeFalse())
+		})
+	})
+
+	Context("detectConflictingInstallations", func() {
+
+		It("should not report the resources created by the operator", func() {
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+			defer os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+
+			_, err := r.reconcileRolloutsManager(ctx, *rm)
+			Expect(err).ToNot(HaveOccurred())
+
+			conflicts, err := r.detectConflictingInstallations(ctx, *rm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(BeEmpty())
+		})
+
+		It("should report Rollouts controller Deployments and ClusterRoles that are not managed by the operator, for a cluster-scoped RolloutManager", func() {
+			Expect(r.Client.Create(ctx, makeHelmRolloutsDeployment("other-namespace", namespacedArg))).To(Succeed())
+			Expect(r.Client.Create(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "helm-argo-rollouts",
+					Labels: map[string]string{
+						"app.kubernetes.io/name":      "argo-rollouts",
+						"app.kubernetes.io/component": "rollouts-controller",
+					},
+				},
+			})).To(Succeed())
+
+			conflicts, err := r.detectConflictingInstallations(ctx, *rm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(Equal([]string{
+				"ClusterRole /helm-argo-rollouts",
+				"Deployment other-namespace/argo-rollouts",
+			}))
+		})
+
+		It("should only report Deployments that watch the namespace of a namespace-scoped RolloutManager", func() {
+			rm.Spec.NamespaceScoped = true
+
+			Expect(r.Client.Create(ctx, makeHelmRolloutsDeployment("namespaced-install", namespacedArg))).To(Succeed())
+			Expect(r.Client.Create(ctx, makeHelmRolloutsDeployment("cluster-install"))).To(Succeed())
+
+			conflicts, err := r.detectConflictingInstallations(ctx, *rm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(Equal([]string{"Deployment cluster-install/argo-rollouts"}))
+
+			By("adding a namespace-scoped install to the namespace of the RolloutManager")
+			sameNamespaceDeployment := makeHelmRolloutsDeployment(rm.Namespace, namespacedArg)
+			sameNamespaceDeployment.Name = "my-argo-rollouts"
+			Expect(r.Client.Create(ctx, sameNamespaceDeployment)).To(Succeed())
+
+			conflicts, err = r.detectConflictingInstallations(ctx, *rm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(Equal([]string{
+				"Deployment cluster-install/argo-rollouts",
+				"Deployment " + rm.Namespace + "/my-argo-rollouts",
+			}))
+		})
+	})
+
+	Context("Reconcile", func() {
+
+		It("should set the ConflictingInstallationDetected condition while a conflicting installation exists", func() {
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+			defer os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+
+			helmDeployment := makeHelmRolloutsDeployment("helm-namespace")
+			Expect(r.Client.Create(ctx, helmDeployment)).To(Succeed())
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}}
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, rm)).To(Succeed())
+			Expect(rm.Status.Conditions).To(ContainElement(And(
+				HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerConflictingInstallationConditionType),
+				HaveField("Status", metav1.ConditionTrue),
+				HaveField("Reason", rolloutsmanagerv1alpha1.RolloutManagerReasonConflictingInstallation),
+				HaveField("Message", ConflictingInstallationMessage+"Deployment helm-namespace/argo-rollouts"),
+			)))
+
+			By("verifying that the RolloutManager is still reconciled")
+			Expect(rm.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess))
+
+			By("deleting the conflicting installation, and verifying that the condition is removed")
+			Expect(r.Client.Delete(ctx, helmDeployment)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, rm)).To(Succeed())
+			Expect(rm.Status.Conditions).ToNot(ContainElement(HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerConflictingInstallationConditionType)))
+		})
+	})
+})
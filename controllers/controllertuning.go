@@ -0,0 +1,97 @@
+package rollouts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// controllerTuningArg is a command argument of the Rollouts controller which is set from a field of .spec.controllerTuning
+type controllerTuningArg struct {
+	// field is the name of the field in .spec.controllerTuning
+	field string
+	// flag is the command line flag of the Rollouts controller
+	flag string
+	// value is the value of the field, or 0 if it is not set
+	value int32
+}
+
+// getControllerTuningArgs returns the command arguments of .spec.controllerTuning, in a stable order, including those which are not set.
+func getControllerTuningArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []controllerTuningArg {
+
+	tuning := cr.Spec.ControllerTuning
+	if tuning == nil {
+		return nil
+	}
+
+	return []controllerTuningArg{
+		{field: "rolloutThreads", flag: "--rollout-threads", value: tuning.RolloutThreads},
+		{field: "experimentThreads", flag: "--experiment-threads", value: tuning.ExperimentThreads},
+		{field: "analysisThreads", flag: "--analysis-threads", value: tuning.AnalysisThreads},
+		{field: "serviceThreads", flag: "--service-threads", value: tuning.ServiceThreads},
+		{field: "ingressThreads", flag: "--ingress-threads", value: tuning.IngressThreads},
+		{field: "ephemeralMetadataThreads", flag: "--ephemeral-metadata-threads", value: tuning.EphemeralMetadataThreads},
+		{field: "rolloutResyncSeconds", flag: "--rollout-resync", value: tuning.RolloutResyncSeconds},
+	}
+}
+
+// getRolloutsControllerTuningArgs returns the command arguments which tune the worker threads and the resync period of the Rollouts controller. Arguments are only returned for the values which are set in the RolloutManager, so that the defaults of the Rollouts controller apply otherwise.
+func getRolloutsControllerTuningArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	args := []string{}
+	for _, arg := range getControllerTuningArgs(cr) {
+		if arg.value != 0 {
+			args = append(args, fmt.Sprintf("%s=%d", arg.flag, arg.value))
+		}
+	}
+	return args
+}
+
+// validateRolloutsControllerTuning verifies that the values of .spec.controllerTuning are positive, and that their flags are supported by the selected Argo Rollouts version (see rolloutsControllerFlagMinimumVersions).
+// The version is not verified if it cannot be determined. Validation is skipped if .spec.argsOverrideMode is 'replace', as the tuning arguments are then not used.
+func validateRolloutsControllerTuning(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	tuningArgs := getControllerTuningArgs(cr)
+	if len(tuningArgs) == 0 || cr.Spec.ArgsOverrideMode == rolloutsmanagerv1alpha1.ArgsOverrideModeReplace {
+		return nil
+	}
+
+	for _, arg := range tuningArgs {
+		if arg.value < 0 {
+			return fmt.Errorf("controllerTuning.%s must not be negative: %d", arg.field, arg.value)
+		}
+	}
+
+	selectedVersion := getRolloutsControllerVersion(cr)
+	if selectedVersion == nil {
+		return nil
+	}
+
+	var unsupportedFields []string
+
+	for _, arg := range tuningArgs {
+
+		if arg.value == 0 {
+			continue
+		}
+
+		minimumVersion, exists := rolloutsControllerFlagMinimumVersions[arg.flag]
+		if !exists {
+			continue
+		}
+
+		if !selectedVersion.AtLeast(version.MustParseSemantic(minimumVersion)) {
+			unsupportedFields = append(unsupportedFields, fmt.Sprintf("controllerTuning.%s (requires %s)", arg.field, minimumVersion))
+		}
+	}
+
+	if len(unsupportedFields) == 0 {
+		return nil
+	}
+
+	sort.Strings(unsupportedFields)
+
+	return fmt.Errorf("the following fields are not supported by Argo Rollouts v%s: %s", selectedVersion.String(), strings.Join(unsupportedFields, ", "))
+}
@@ -0,0 +1,124 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Controller tuning tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	It("should only pass the tuning values which are set to the Rollouts controller", func() {
+		Expect(getRolloutsControllerTuningArgs(cr)).To(BeEmpty())
+
+		cr.Spec.ControllerTuning = &rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{}
+		Expect(getRolloutsControllerTuningArgs(cr)).To(BeEmpty())
+
+		cr.Spec.ControllerTuning = &rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{
+			RolloutThreads:           20,
+			AnalysisThreads:          60,
+			EphemeralMetadataThreads: 5,
+			RolloutResyncSeconds:     300,
+		}
+		Expect(getRolloutsControllerTuningArgs(cr)).To(Equal([]string{"--rollout-threads=20", "--analysis-threads=60", "--ephemeral-metadata-threads=5", "--rollout-resync=300"}))
+
+		By("verifying that the tuning arguments follow the client rate limit arguments, and precede the extra command arguments")
+		cr.Spec.ControllerTuning = &rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{ExperimentThreads: 2, ServiceThreads: 3, IngressThreads: 4}
+		cr.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{QPS: 100, Burst: 200}
+		cr.Spec.ExtraCommandArgs = []string{"--loglevel", "debug"}
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{"--qps=100", "--burst=200", "--experiment-threads=2", "--service-threads=3", "--ingress-threads=4", "--loglevel", "debug"}))
+	})
+
+	DescribeTable("validateRolloutsControllerTuning", func(version string, tuning *rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec, expectedErr string) {
+		cr.Spec.Version = version
+		cr.Spec.ControllerTuning = tuning
+
+		err := validateRolloutsControllerTuning(cr)
+		if expectedErr == "" {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(MatchError(expectedErr))
+		}
+	},
+		Entry("no tuning", "v1.6.0", nil, ""),
+		Entry("thread counts supported by all versions", "v1.0.0",
+			&rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{RolloutThreads: 20, RolloutResyncSeconds: 60}, ""),
+		Entry("ephemeral metadata threads on a supported version", "v1.7.0",
+			&rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{EphemeralMetadataThreads: 20}, ""),
+		Entry("ephemeral metadata threads on an older version", "v1.6.6",
+			&rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{EphemeralMetadataThreads: 20},
+			"the following fields are not supported by Argo Rollouts v1.6.6: controllerTuning.ephemeralMetadataThreads (requires v1.7.0)"),
+		Entry("version that cannot be determined", "sha256:abc",
+			&rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{EphemeralMetadataThreads: 20}, ""),
+		Entry("negative value", "v1.7.0",
+			&rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{RolloutResyncSeconds: -1},
+			"controllerTuning.rolloutResyncSeconds must not be negative: -1"),
+	)
+
+	It("should not validate the tuning when the operator command arguments are replaced", func() {
+		cr.Spec.Version = "v1.6.6"
+		cr.Spec.ControllerTuning = &rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{EphemeralMetadataThreads: 20}
+		Expect(validateRolloutsControllerTuning(cr)).To(HaveOccurred())
+
+		cr.Spec.ArgsOverrideMode = rolloutsmanagerv1alpha1.ArgsOverrideModeReplace
+		Expect(validateRolloutsControllerTuning(cr)).To(Succeed())
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		})
+
+		It("should set the tuning arguments of the Rollouts controller Deployment", func() {
+			cr.Spec.ControllerTuning = &rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{RolloutThreads: 20, RolloutResyncSeconds: 300}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--rollout-threads=20", "--rollout-resync=300"))
+		})
+
+		It("should set the phase to Failure if a tuning value is not supported by the Argo Rollouts version", func() {
+			cr.Spec.Version = "v1.6.6"
+			cr.Spec.ControllerTuning = &rolloutsmanagerv1alpha1.RolloutManagerControllerTuningSpec{EphemeralMetadataThreads: 20}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidControllerTuning))
+		})
+	})
+})
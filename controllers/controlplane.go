@@ -0,0 +1,85 @@
+package rollouts
+
+import (
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// controlPlaneNodeRoleLabel is the label (and taint) of control-plane nodes
+	controlPlaneNodeRoleLabel = "node-role.kubernetes.io/control-plane"
+
+	// masterNodeRoleLabel is the legacy label (and taint) of control-plane nodes, which is still used by some distributions (for example, OpenShift)
+	masterNodeRoleLabel = "node-role.kubernetes.io/master"
+)
+
+// controlPlaneTolerations returns the tolerations of the taints of control-plane nodes.
+func controlPlaneTolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{
+			Key:      controlPlaneNodeRoleLabel,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+		{
+			Key:      masterNodeRoleLabel,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}
+}
+
+// getRolloutsTolerations returns the tolerations of the Rollouts controller pod: those of .spec.nodePlacement, followed by the tolerations of the control-plane taints if .spec.runOnControlPlane is true (unless these taints are already tolerated).
+func getRolloutsTolerations(cr rolloutsmanagerv1alpha1.RolloutManager) []corev1.Toleration {
+
+	var res []corev1.Toleration
+	if cr.Spec.NodePlacement != nil {
+		res = cr.Spec.NodePlacement.Tolerations
+	}
+
+	if !cr.Spec.RunOnControlPlane {
+		return res
+	}
+
+	res = append([]corev1.Toleration{}, res...)
+	for _, toleration := range controlPlaneTolerations() {
+		taint := &corev1.Taint{Key: toleration.Key, Effect: toleration.Effect}
+		tolerated := false
+		for idx := range res {
+			if res[idx].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			res = append(res, toleration)
+		}
+	}
+	return res
+}
+
+// getRolloutsAffinity returns the affinity of the Rollouts controller pod: if .spec.runOnControlPlane is true, the pod is required to be scheduled onto a control-plane node, identified by either its current or its legacy node role label.
+func getRolloutsAffinity(cr rolloutsmanagerv1alpha1.RolloutManager) *corev1.Affinity {
+
+	if !cr.Spec.RunOnControlPlane {
+		return nil
+	}
+
+	var terms []corev1.NodeSelectorTerm
+	for _, label := range []string{controlPlaneNodeRoleLabel, masterNodeRoleLabel} {
+		terms = append(terms, corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{
+				Key:      label,
+				Operator: corev1.NodeSelectorOpExists,
+			}},
+		})
+	}
+
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: terms,
+			},
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Control-plane scheduling tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	It("should not tolerate the control-plane taints, nor require a control-plane node, by default", func() {
+		podSpec := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{}).Spec.Template.Spec
+		Expect(podSpec.Tolerations).To(BeEmpty())
+		Expect(podSpec.Affinity).To(BeNil())
+	})
+
+	It("should tolerate the control-plane taints, and require a control-plane node, if runOnControlPlane is true", func() {
+		cr.Spec.RunOnControlPlane = true
+
+		podSpec := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{}).Spec.Template.Spec
+		Expect(podSpec.Tolerations).To(Equal(controlPlaneTolerations()))
+
+		Expect(podSpec.Affinity).ToNot(BeNil())
+		terms := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		Expect(terms).To(HaveLen(2))
+		Expect(terms[0].MatchExpressions).To(Equal([]corev1.NodeSelectorRequirement{{Key: controlPlaneNodeRoleLabel, Operator: corev1.NodeSelectorOpExists}}))
+		Expect(terms[1].MatchExpressions).To(Equal([]corev1.NodeSelectorRequirement{{Key: masterNodeRoleLabel, Operator: corev1.NodeSelectorOpExists}}))
+
+		By("keeping the default node selector")
+		Expect(podSpec.NodeSelector).To(Equal(map[string]string{corev1.LabelOSStable: "linux"}))
+	})
+
+	It("should add the control-plane tolerations to those of nodePlacement, without duplicates", func() {
+		cr.Spec.RunOnControlPlane = true
+		cr.Spec.NodePlacement = &rolloutsmanagerv1alpha1.RolloutsNodePlacementSpec{
+			Tolerations: []corev1.Toleration{
+				{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "management", Effect: corev1.TaintEffectNoSchedule},
+				{Key: controlPlaneNodeRoleLabel, Operator: corev1.TolerationOpExists},
+			},
+		}
+
+		tolerations := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{}).Spec.Template.Spec.Tolerations
+		Expect(tolerations).To(Equal([]corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "management", Effect: corev1.TaintEffectNoSchedule},
+			{Key: controlPlaneNodeRoleLabel, Operator: corev1.TolerationOpExists},
+			{Key: masterNodeRoleLabel, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		}))
+		Expect(cr.Spec.NodePlacement.Tolerations).To(HaveLen(2))
+	})
+
+	It("should update the Rollouts controller Deployment when runOnControlPlane is changed", func() {
+		ctx := context.Background()
+		r := makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		getPodSpec := func() corev1.PodSpec {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			return deployment.Spec.Template.Spec
+		}
+
+		Expect(getPodSpec().Affinity).To(BeNil())
+
+		Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+		cr.Spec.RunOnControlPlane = true
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+		podSpec := getPodSpec()
+		Expect(podSpec.Affinity).To(Equal(getRolloutsAffinity(cr)))
+		Expect(podSpec.Tolerations).To(Equal(controlPlaneTolerations()))
+
+		Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+		cr.Spec.RunOnControlPlane = false
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+		podSpec = getPodSpec()
+		Expect(podSpec.Affinity).To(BeNil())
+		Expect(podSpec.Tolerations).To(BeEmpty())
+	})
+})
@@ -0,0 +1,184 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// EventReasonControllerCrashed is the reason of the Warning Events recorded on a RolloutManager when the Rollouts controller container crashes.
+	EventReasonControllerCrashed = "ControllerCrashed"
+
+	// ControllerCrashingMessage is the prefix of the message of the ControllerCrashing condition, which is followed by the crashes of each Rollouts controller pod.
+	ControllerCrashingMessage = "The Rollouts controller container is crashing: "
+
+	// maxCrashExcerptLength is the maximum length of the excerpt of the termination message of a crashed container that is reported on the RolloutManager. The end of the message is kept, as it usually contains the cause of the crash.
+	maxCrashExcerptLength = 512
+)
+
+// detectControllerCrashes returns a description of the last crash of the Rollouts controller container, for each Rollouts controller pod of the RolloutManager whose container is crashing.
+// The description includes an excerpt of the termination message of the container: since the container uses the FallbackToLogsOnError termination message policy, this is the end of its logs, if the controller did not write a termination message.
+func (r *RolloutManagerReconciler) detectControllerCrashes(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) ([]string, error) {
+
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, client.InNamespace(cr.Namespace), client.MatchingLabels{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName}); err != nil {
+		return nil, fmt.Errorf("failed to list the Rollouts controller pods: %w", err)
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	res := []string{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		containerStatus := getContainerStatus(pod, getRolloutsContainerName(cr))
+		if containerStatus == nil {
+			continue
+		}
+
+		if terminated := getContainerCrash(*containerStatus); terminated != nil {
+			res = append(res, fmt.Sprintf("pod %s %s", pod.Name, describeContainerCrash(terminated)))
+		}
+	}
+
+	return res, nil
+}
+
+// getContainerStatus returns the status of the container of the pod with the given name, or nil if it has none.
+func getContainerStatus(pod *corev1.Pod, containerName string) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == containerName {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+// getContainerCrash returns the termination state of the last crash of the container, if it is crashing: that is, it exited with an error, and it is not ready since it was restarted. Otherwise, it returns nil.
+func getContainerCrash(containerStatus corev1.ContainerStatus) *corev1.ContainerStateTerminated {
+
+	if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode != 0 {
+		return containerStatus.State.Terminated
+	}
+
+	lastTerminated := containerStatus.LastTerminationState.Terminated
+	if !containerStatus.Ready && lastTerminated != nil && lastTerminated.ExitCode != 0 {
+		return lastTerminated
+	}
+
+	return nil
+}
+
+// describeContainerCrash returns a description of the crash of the container: its exit code and reason, followed by an excerpt of its termination message, if any.
+func describeContainerCrash(terminated *corev1.ContainerStateTerminated) string {
+
+	description := fmt.Sprintf("exited with code %d", terminated.ExitCode)
+	if terminated.Reason != "" {
+		description += fmt.Sprintf(" (%s)", terminated.Reason)
+	}
+
+	if excerpt := getCrashExcerpt(terminated.Message); excerpt != "" {
+		description += ": " + excerpt
+	}
+
+	return description
+}
+
+// getCrashExcerpt returns the end of the termination message of a crashed container, on a single line, truncated to maxCrashExcerptLength.
+func getCrashExcerpt(message string) string {
+
+	excerpt := strings.Join(strings.Fields(message), " ")
+
+	if len(excerpt) > maxCrashExcerptLength {
+		excerpt = "..." + excerpt[len(excerpt)-maxCrashExcerptLength:]
+	}
+
+	return excerpt
+}
+
+// createControllerCrashingCondition returns the ControllerCrashing condition for the given crashes.
+func createControllerCrashingCondition(crashes []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerControllerCrashingConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonControllerCrashed,
+		Message: ControllerCrashingMessage + strings.Join(crashes, ", "),
+	}
+}
+
+// recordControllerCrashEvent records a Warning Event on the RolloutManager for the given crashes of the Rollouts controller container, unless they are already reported by its ControllerCrashing condition.
+func (r *RolloutManagerReconciler) recordControllerCrashEvent(cr *rolloutsmanagerv1alpha1.RolloutManager, crashes []string) {
+
+	if r.Recorder == nil || len(crashes) == 0 {
+		return
+	}
+
+	condition := createControllerCrashingCondition(crashes)
+	for _, existing := range cr.Status.Conditions {
+		if existing.Type == condition.Type && existing.Message == condition.Message {
+			return
+		}
+	}
+
+	r.Recorder.Event(cr, corev1.EventTypeWarning, EventReasonControllerCrashed, condition.Message)
+}
+
+// controllerContainerRestarted returns true if the Rollouts controller container of the pod was restarted in the update from oldPod to newPod.
+func controllerContainerRestarted(oldPod *corev1.Pod, newPod *corev1.Pod) bool {
+
+	for _, newStatus := range newPod.Status.ContainerStatuses {
+		oldRestartCount := int32(0)
+		for _, oldStatus := range oldPod.Status.ContainerStatuses {
+			if oldStatus.Name == newStatus.Name {
+				oldRestartCount = oldStatus.RestartCount
+			}
+		}
+
+		if newStatus.RestartCount > oldRestartCount {
+			return true
+		}
+	}
+
+	return false
+}
+
+// controllerCrashHandler returns an event handler for Rollouts controller pods, which enqueues the RolloutManagers of the namespace of a pod when one of its containers is restarted, so that the crash is reported on their status (see detectControllerCrashes).
+// The readiness of the pod is reflected by the Rollouts controller Deployment, which is already watched: this handles the restarts that do not change it (for example, of a container that crashes before it becomes ready).
+func (r *RolloutManagerReconciler) controllerCrashHandler() handler.EventHandler {
+	return handler.Funcs{
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldPod, oldOK := e.ObjectOld.(*corev1.Pod)
+			newPod, newOK := e.ObjectNew.(*corev1.Pod)
+			if !oldOK || !newOK || !isRolloutsControllerPod(newPod) || !controllerContainerRestarted(oldPod, newPod) {
+				return
+			}
+
+			var rolloutManagers rolloutsmanagerv1alpha1.RolloutManagerList
+			if err := r.Client.List(ctx, &rolloutManagers, client.InNamespace(newPod.Namespace)); err != nil {
+				log.Error(err, "unable to list RolloutManagers", "namespace", newPod.Namespace)
+				return
+			}
+
+			for _, rolloutManager := range rolloutManagers.Items {
+				q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: rolloutManager.Namespace, Name: rolloutManager.Name}})
+			}
+		},
+	}
+}
@@ -0,0 +1,151 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Rollouts controller crash tests", func() {
+
+	var (
+		ctx      context.Context
+		cr       rolloutsmanagerv1alpha1.RolloutManager
+		r        *RolloutManagerReconciler
+		recorder *namespacedEventRecorder
+		req      reconcile.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+
+		r = makeTestReconciler(&cr)
+		recorder = &namespacedEventRecorder{}
+		r.Recorder = recorder
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+	})
+
+	// createControllerPod creates a Rollouts controller pod with the given status of its container
+	createControllerPod := func(name string, containerStatus corev1.ContainerStatus) *corev1.Pod {
+		containerStatus.Name = DefaultArgoRolloutsResourceName
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cr.Namespace,
+				Labels:    map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{containerStatus}},
+		}
+		ExpectWithOffset(1, r.Client.Create(ctx, pod)).To(Succeed())
+		return pod
+	}
+
+	crashLoopStatus := corev1.ContainerStatus{
+		RestartCount: 3,
+		State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+		},
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 1,
+				Reason:   "Error",
+				Message:  "time=\"2024-01-01T10:00:00Z\" level=fatal msg=\"Error creating client: unable to load in-cluster configuration\"\n",
+			},
+		},
+	}
+
+	getCondition := func(conditionType string) *metav1.Condition {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		for i := range cr.Status.Conditions {
+			if cr.Status.Conditions[i].Type == conditionType {
+				return &cr.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	It("should report the crash of the Rollouts controller in a condition and an Event, until the container is running again", func() {
+		pod := createControllerPod(DefaultArgoRolloutsResourceName+"-abcde", crashLoopStatus)
+		createControllerPod(DefaultArgoRolloutsResourceName+"-fghij", corev1.ContainerStatus{Ready: true, RestartCount: 1})
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		condition := getCondition(rolloutsmanagerv1alpha1.RolloutManagerControllerCrashingConditionType)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonControllerCrashed))
+		Expect(condition.Message).To(Equal(ControllerCrashingMessage + "pod argo-rollouts-abcde exited with code 1 (Error): " +
+			"time=\"2024-01-01T10:00:00Z\" level=fatal msg=\"Error creating client: unable to load in-cluster configuration\""))
+		Expect(recorder.events).To(ConsistOf(cr.Namespace + "/*v1alpha1.RolloutManager " + EventReasonControllerCrashed))
+
+		ready := getCondition(rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType)
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonControllerCrashed))
+
+		By("not recording another Event for a crash that is already reported")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.events).To(HaveLen(1))
+
+		By("removing the condition once the container is running again")
+		Expect(r.Client.Delete(ctx, pod)).To(Succeed())
+		running := *crashLoopStatus.DeepCopy()
+		running.Ready = true
+		running.State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+		createControllerPod(pod.Name, running)
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getCondition(rolloutsmanagerv1alpha1.RolloutManagerControllerCrashingConditionType)).To(BeNil())
+	})
+
+	It("getCrashExcerpt should keep the end of the termination message, on a single line", func() {
+		Expect(getCrashExcerpt("")).To(BeEmpty())
+		Expect(getCrashExcerpt("line 1\nline 2\n")).To(Equal("line 1 line 2"))
+
+		excerpt := getCrashExcerpt(strings.Repeat("a", 1000) + " the cause")
+		Expect(excerpt).To(HaveLen(maxCrashExcerptLength + 3))
+		Expect(excerpt).To(HavePrefix("..."))
+		Expect(excerpt).To(HaveSuffix("the cause"))
+	})
+
+	It("getContainerCrash should only report containers which exited with an error and are not ready", func() {
+		Expect(getContainerCrash(crashLoopStatus)).ToNot(BeNil())
+
+		recovered := *crashLoopStatus.DeepCopy()
+		recovered.Ready = true
+		Expect(getContainerCrash(recovered)).To(BeNil())
+
+		completed := *crashLoopStatus.DeepCopy()
+		completed.LastTerminationState.Terminated.ExitCode = 0
+		Expect(getContainerCrash(completed)).To(BeNil())
+	})
+
+	It("controllerContainerRestarted should detect containers whose restart count increased", func() {
+		oldPod := &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: DefaultArgoRolloutsResourceName, RestartCount: 2}}}}
+		newPod := oldPod.DeepCopy()
+		Expect(controllerContainerRestarted(oldPod, newPod)).To(BeFalse())
+
+		newPod.Status.ContainerStatuses[0].RestartCount = 3
+		Expect(controllerContainerRestarted(oldPod, newPod)).To(BeTrue())
+	})
+
+	It("should use the end of the logs as the termination message of the Rollouts controller container", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(deployment.Spec.Template.Spec.Containers[0].TerminationMessagePolicy).To(Equal(corev1.TerminationMessageFallbackToLogsOnError))
+	})
+})
@@ -0,0 +1,568 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultArgoRolloutsDashboardResourceName is the name of the resources of the Argo Rollouts dashboard: its Deployment, Service, Ingress, ServiceAccount and (Cluster)Role/(Cluster)RoleBinding.
+	DefaultArgoRolloutsDashboardResourceName = "argo-rollouts-dashboard"
+
+	// DefaultArgoRolloutsDashboardImage is the default image of the Argo Rollouts dashboard, which is served by the kubectl plugin of Argo Rollouts.
+	DefaultArgoRolloutsDashboardImage = "quay.io/argoproj/kubectl-argo-rollouts"
+
+	// DefaultArgoRolloutsDashboardPort is the port on which the Argo Rollouts dashboard is served.
+	DefaultArgoRolloutsDashboardPort = 3100
+
+	dashboardPortName = "dashboard"
+
+	// EventReasonDashboardResourceConflict is the reason of the Event which is recorded on a RolloutManager when a cluster-scoped resource of its dashboard already exists, and was not created by the operator.
+	EventReasonDashboardResourceConflict = "DashboardResourceConflict"
+)
+
+// isDashboardEnabled returns true if the RolloutManager requests the Argo Rollouts dashboard.
+func isDashboardEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.Dashboard != nil && cr.Spec.Dashboard.Enabled
+}
+
+// isDashboardIngressEnabled returns true if the RolloutManager requests an Ingress for the Argo Rollouts dashboard. The Ingress is only created if the dashboard is enabled.
+func isDashboardIngressEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return isDashboardEnabled(cr) && cr.Spec.Dashboard.Ingress != nil && cr.Spec.Dashboard.Ingress.Enabled
+}
+
+// getDashboardContainerImage returns the container image of the Argo Rollouts dashboard. Its version defaults to the version of the Rollouts controller, as the dashboard and the controller are released together.
+func getDashboardContainerImage(cr rolloutsmanagerv1alpha1.RolloutManager) string {
+
+	img := cr.Spec.Dashboard.Image
+	if img == "" {
+		img = applyImageRegistryMirror(DefaultArgoRolloutsDashboardImage)
+	}
+
+	tag := cr.Spec.Dashboard.Version
+	if tag == "" {
+		tag = cr.Spec.Version
+	}
+	if tag == "" {
+		tag = DefaultArgoRolloutsVersion
+	}
+
+	return combineImageTag(img, tag)
+}
+
+// getDashboardArgs returns the command arguments of the Argo Rollouts dashboard: the dashboard of a namespace-scoped RolloutManager is restricted to its namespace, as it is only granted permissions in it.
+func getDashboardArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	args := []string{"dashboard", fmt.Sprintf("--port=%d", DefaultArgoRolloutsDashboardPort)}
+	if cr.Spec.NamespaceScoped {
+		args = append(args, "--namespace="+cr.Namespace)
+	}
+	return args
+}
+
+// getDashboardSelectorLabels returns the labels which select the pods of the Argo Rollouts dashboard. They differ from those of the Rollouts controller, so that the dashboard pods are not mistaken for Rollouts controller pods.
+func getDashboardSelectorLabels() map[string]string {
+	return map[string]string{
+		DefaultRolloutsSelectorKey: DefaultArgoRolloutsDashboardResourceName,
+	}
+}
+
+// setDashboardLabelsAndAnnotationsToObject sets the labels and annotations of the resources of the Argo Rollouts dashboard.
+func setDashboardLabelsAndAnnotationsToObject(obj *metav1.ObjectMeta, cr rolloutsmanagerv1alpha1.RolloutManager) {
+	setRolloutsLabelsAndAnnotationsToObject(obj, cr)
+	obj.Labels[DefaultRolloutsSelectorKey] = DefaultArgoRolloutsDashboardResourceName
+	obj.Labels["app.kubernetes.io/component"] = "dashboard"
+	obj.Labels[RolloutManagerLabel] = cr.Name
+}
+
+// getDashboardPolicyRules returns the permissions of the Argo Rollouts dashboard: it views Rollouts and their related resources, and promotes, aborts, retries and restarts Rollouts.
+func getDashboardPolicyRules(cr rolloutsmanagerv1alpha1.RolloutManager) []rbacv1.PolicyRule {
+
+	analysisResources := []string{"analysisruns", "analysistemplates", "experiments"}
+	if !cr.Spec.NamespaceScoped {
+		analysisResources = append(analysisResources, "clusteranalysistemplates")
+	}
+
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"argoproj.io"},
+			Resources: []string{"rollouts", "rollouts/status", "rollouts/finalizers"},
+			Verbs:     []string{"get", "list", "watch", "update", "patch"},
+		},
+		{
+			APIGroups: []string{"argoproj.io"},
+			Resources: analysisResources,
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{"apps"},
+			Resources: []string{"replicasets", "deployments"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+	}
+}
+
+// generateDesiredDashboardDeployment returns the Deployment of the Argo Rollouts dashboard.
+func generateDesiredDashboardDeployment(cr rolloutsmanagerv1alpha1.RolloutManager) *appsv1.Deployment {
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultArgoRolloutsDashboardResourceName,
+			Namespace: cr.Namespace,
+		},
+	}
+	setDashboardLabelsAndAnnotationsToObject(&deployment.ObjectMeta, cr)
+
+	podLabels := combineStringMaps(getRecommendedLabels(cr), getDashboardSelectorLabels(), getCommonLabels())
+
+	runAsNonRoot := true
+	deployment.Spec = appsv1.DeploymentSpec{
+		Selector: &metav1.LabelSelector{
+			MatchLabels: getDashboardSelectorLabels(),
+		},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: podLabels,
+			},
+			Spec: corev1.PodSpec{
+				// The dashboard image is only built for Linux, like the Rollouts controller image
+				NodeSelector: map[string]string{
+					corev1.LabelOSStable: "linux",
+				},
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsNonRoot: &runAsNonRoot,
+				},
+				ServiceAccountName: DefaultArgoRolloutsDashboardResourceName,
+				Containers: []corev1.Container{{
+					Name:  DefaultArgoRolloutsDashboardResourceName,
+					Image: getDashboardContainerImage(cr),
+					Args:  getDashboardArgs(cr),
+					Ports: []corev1.ContainerPort{{
+						Name:          dashboardPortName,
+						ContainerPort: DefaultArgoRolloutsDashboardPort,
+						Protocol:      corev1.ProtocolTCP,
+					}},
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: boolPtr(false),
+						ReadOnlyRootFilesystem:   boolPtr(true),
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"ALL"},
+						},
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
+				}},
+			},
+		},
+	}
+
+	if cr.Spec.NodePlacement != nil {
+		deployment.Spec.Template.Spec.NodeSelector = appendStringMap(deployment.Spec.Template.Spec.NodeSelector, cr.Spec.NodePlacement.NodeSelector)
+		deployment.Spec.Template.Spec.Tolerations = cr.Spec.NodePlacement.Tolerations
+	}
+
+	if cr.Spec.Dashboard.Resources != nil {
+		deployment.Spec.Template.Spec.Containers[0].Resources = *cr.Spec.Dashboard.Resources
+	}
+
+	return deployment
+}
+
+// generateDesiredDashboardIngress returns the Ingress which exposes the Service of the Argo Rollouts dashboard.
+func generateDesiredDashboardIngress(cr rolloutsmanagerv1alpha1.RolloutManager) *networkingv1.Ingress {
+
+	ingressSpec := cr.Spec.Dashboard.Ingress
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultArgoRolloutsDashboardResourceName,
+			Namespace: cr.Namespace,
+		},
+	}
+	setDashboardLabelsAndAnnotationsToObject(&ingress.ObjectMeta, cr)
+	ingress.Annotations = combineStringMaps(ingress.Annotations, ingressSpec.Annotations)
+
+	pathType := networkingv1.PathTypePrefix
+	ingress.Spec = networkingv1.IngressSpec{
+		IngressClassName: ingressSpec.IngressClassName,
+		TLS:              getDashboardIngressTLS(cr),
+		Rules: []networkingv1.IngressRule{{
+			Host: ingressSpec.Host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: DefaultArgoRolloutsDashboardResourceName,
+								Port: networkingv1.ServiceBackendPort{Name: dashboardPortName},
+							},
+						},
+					}},
+				},
+			},
+		}},
+	}
+
+	return ingress
+}
+
+// reconcileRolloutsDashboard creates/updates the resources of the Argo Rollouts dashboard if it is enabled, and deletes them otherwise.
+// It returns the reasons why the TLS certificate of the dashboard Ingress, requested from cert-manager, is not ready (see reconcileDashboardCertificate): the Ingress is only created once there are none.
+func (r *RolloutManagerReconciler) reconcileRolloutsDashboard(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) ([]string, error) {
+
+	if !isDashboardEnabled(cr) {
+		return []string{}, r.removeDashboardResources(ctx, cr)
+	}
+
+	log.Info("reconciling Rollouts dashboard ServiceAccount")
+	expectedServiceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultArgoRolloutsDashboardResourceName,
+			Namespace: cr.Namespace,
+		},
+	}
+	setDashboardLabelsAndAnnotationsToObject(&expectedServiceAccount.ObjectMeta, cr)
+	if err := r.prepareResource(ctx, cr, expectedServiceAccount); err != nil {
+		return nil, err
+	}
+	if _, err := r.applyResource(ctx, cr, expectedServiceAccount, &corev1.ServiceAccount{}, true, nil); err != nil {
+		return nil, fmt.Errorf("failed to reconcile the dashboard ServiceAccount: %w", err)
+	}
+
+	log.Info("reconciling Rollouts dashboard RBAC")
+	if err := r.reconcileDashboardRBAC(ctx, cr); err != nil {
+		return nil, err
+	}
+
+	log.Info("reconciling Rollouts dashboard Deployment")
+	expectedDeployment := generateDesiredDashboardDeployment(cr)
+	if err := r.prepareResource(ctx, cr, expectedDeployment); err != nil {
+		return nil, err
+	}
+	liveDeployment := &appsv1.Deployment{}
+	if _, err := r.applyResource(ctx, cr, expectedDeployment, liveDeployment, true, func() bool {
+		return updateDashboardDeployment(liveDeployment, expectedDeployment)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile the dashboard Deployment: %w", err)
+	}
+
+	log.Info("reconciling Rollouts dashboard Service")
+	expectedService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultArgoRolloutsDashboardResourceName,
+			Namespace: cr.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       dashboardPortName,
+				Port:       DefaultArgoRolloutsDashboardPort,
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.FromString(dashboardPortName),
+			}},
+			Selector: getDashboardSelectorLabels(),
+		},
+	}
+	setDashboardLabelsAndAnnotationsToObject(&expectedService.ObjectMeta, cr)
+	if err := r.prepareResource(ctx, cr, expectedService); err != nil {
+		return nil, err
+	}
+	liveService := &corev1.Service{}
+	if _, err := r.applyResource(ctx, cr, expectedService, liveService, true, func() bool {
+		updateNeeded := updateServicePorts(liveService, expectedService, r.describeResource(cr, liveService))
+		if !equalNormalized(expectedService.Spec.Selector, liveService.Spec.Selector) {
+			updateNeeded = true
+			log.Info(fmt.Sprintf("Selector of Service %s does not match the expected state, hence updating it", liveService.Name))
+			liveService.Spec.Selector = expectedService.Spec.Selector
+		}
+		return updateNeeded
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile the dashboard Service: %w", err)
+	}
+
+	log.Info("reconciling Rollouts dashboard Certificate")
+	certificateNotReadyReasons, err := r.reconcileDashboardCertificate(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("reconciling Rollouts dashboard Ingress")
+	if !isDashboardIngressEnabled(cr) {
+		return certificateNotReadyReasons, r.deleteDashboardResource(ctx, cr, &networkingv1.Ingress{})
+	}
+	if len(certificateNotReadyReasons) > 0 {
+		// The dashboard is not exposed before its certificate is issued. An existing Ingress is left as it is, until the certificate is ready.
+		log.Info("the TLS certificate of the dashboard is not ready: skipping the reconciliation of the dashboard Ingress", "reasons", certificateNotReadyReasons)
+		return certificateNotReadyReasons, nil
+	}
+	expectedIngress := generateDesiredDashboardIngress(cr)
+	if err := r.prepareResource(ctx, cr, expectedIngress); err != nil {
+		return nil, err
+	}
+	liveIngress := &networkingv1.Ingress{}
+	if _, err := r.applyResource(ctx, cr, expectedIngress, liveIngress, true, func() bool {
+		if equalNormalized(expectedIngress.Spec, liveIngress.Spec) {
+			return false
+		}
+		log.Info(fmt.Sprintf("Spec of Ingress %s does not match the expected state, hence updating it", liveIngress.Name))
+		liveIngress.Spec = expectedIngress.Spec
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile the dashboard Ingress: %w", err)
+	}
+
+	return certificateNotReadyReasons, nil
+}
+
+// reconcileDashboardRBAC reconciles the permissions of the Argo Rollouts dashboard: a Role and RoleBinding for a namespace-scoped RolloutManager, and a ClusterRole and ClusterRoleBinding otherwise.
+func (r *RolloutManagerReconciler) reconcileDashboardRBAC(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      DefaultArgoRolloutsDashboardResourceName,
+		Namespace: cr.Namespace,
+	}}
+
+	if cr.Spec.NamespaceScoped {
+		expectedRole := &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DefaultArgoRolloutsDashboardResourceName,
+				Namespace: cr.Namespace,
+			},
+			Rules: getDashboardPolicyRules(cr),
+		}
+		setDashboardLabelsAndAnnotationsToObject(&expectedRole.ObjectMeta, cr)
+		if err := r.prepareResource(ctx, cr, expectedRole); err != nil {
+			return err
+		}
+		liveRole := &rbacv1.Role{}
+		if _, err := r.applyResource(ctx, cr, expectedRole, liveRole, true, func() bool {
+			return updatePolicyRules(&liveRole.Rules, expectedRole.Rules, r.describeResource(cr, liveRole))
+		}); err != nil {
+			return fmt.Errorf("failed to reconcile the dashboard Role: %w", err)
+		}
+
+		expectedRoleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DefaultArgoRolloutsDashboardResourceName,
+				Namespace: cr.Namespace,
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     DefaultArgoRolloutsDashboardResourceName,
+			},
+			Subjects: subjects,
+		}
+		setDashboardLabelsAndAnnotationsToObject(&expectedRoleBinding.ObjectMeta, cr)
+		if err := r.prepareResource(ctx, cr, expectedRoleBinding); err != nil {
+			return err
+		}
+		// The RoleRef of a RoleBinding is immutable, hence only the Subjects are updated
+		liveRoleBinding := &rbacv1.RoleBinding{}
+		if _, err := r.applyResource(ctx, cr, expectedRoleBinding, liveRoleBinding, true, func() bool {
+			return updateSubjects(&liveRoleBinding.Subjects, expectedRoleBinding.Subjects, r.describeResource(cr, liveRoleBinding))
+		}); err != nil {
+			return fmt.Errorf("failed to reconcile the dashboard RoleBinding: %w", err)
+		}
+		return nil
+	}
+
+	expectedClusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: DefaultArgoRolloutsDashboardResourceName,
+		},
+		Rules: getDashboardPolicyRules(cr),
+	}
+	setDashboardLabelsAndAnnotationsToObject(&expectedClusterRole.ObjectMeta, cr)
+	if err := r.prepareResource(ctx, cr, expectedClusterRole); err != nil {
+		return err
+	}
+	// A cluster-scoped resource cannot be owned by the (namespace-scoped) RolloutManager. The ClusterRole is not bound to the dashboard if it was not created by the operator.
+	if conflict, err := r.hasDashboardResourceConflict(ctx, cr, &rbacv1.ClusterRole{}); err != nil || conflict {
+		return err
+	}
+	liveClusterRole := &rbacv1.ClusterRole{}
+	if _, err := r.applyResource(ctx, cr, expectedClusterRole, liveClusterRole, false, func() bool {
+		return updatePolicyRules(&liveClusterRole.Rules, expectedClusterRole.Rules, r.describeResource(cr, liveClusterRole))
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile the dashboard ClusterRole: %w", err)
+	}
+
+	expectedClusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: DefaultArgoRolloutsDashboardResourceName,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     DefaultArgoRolloutsDashboardResourceName,
+		},
+		Subjects: subjects,
+	}
+	setDashboardLabelsAndAnnotationsToObject(&expectedClusterRoleBinding.ObjectMeta, cr)
+	if err := r.prepareResource(ctx, cr, expectedClusterRoleBinding); err != nil {
+		return err
+	}
+	if conflict, err := r.hasDashboardResourceConflict(ctx, cr, &rbacv1.ClusterRoleBinding{}); err != nil || conflict {
+		return err
+	}
+	liveClusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+	if _, err := r.applyResource(ctx, cr, expectedClusterRoleBinding, liveClusterRoleBinding, false, func() bool {
+		return updateSubjects(&liveClusterRoleBinding.Subjects, expectedClusterRoleBinding.Subjects, r.describeResource(cr, liveClusterRoleBinding))
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile the dashboard ClusterRoleBinding: %w", err)
+	}
+
+	return nil
+}
+
+// updateDashboardDeployment sets the fields of the live Deployment of the Argo Rollouts dashboard which are managed by the operator to their expected values, and returns true if they did not match.
+func updateDashboardDeployment(live *appsv1.Deployment, expected *appsv1.Deployment) bool {
+
+	livePodSpec := &live.Spec.Template.Spec
+	expectedPodSpec := expected.Spec.Template.Spec
+
+	if len(livePodSpec.Containers) != 1 {
+		live.Spec.Template = expected.Spec.Template
+		return true
+	}
+
+	liveContainer := &livePodSpec.Containers[0]
+	expectedContainer := expectedPodSpec.Containers[0]
+
+	if liveContainer.Name == expectedContainer.Name &&
+		liveContainer.Image == expectedContainer.Image &&
+		equalNormalized(expectedContainer.Args, liveContainer.Args) &&
+		equalNormalized(expectedContainer.Ports, liveContainer.Ports) &&
+		equalNormalized(expectedContainer.Resources, liveContainer.Resources) &&
+		equalNormalized(expectedContainer.SecurityContext, liveContainer.SecurityContext) &&
+		livePodSpec.ServiceAccountName == expectedPodSpec.ServiceAccountName &&
+		equalNormalized(expectedPodSpec.NodeSelector, livePodSpec.NodeSelector) &&
+		equalNormalized(expectedPodSpec.Tolerations, livePodSpec.Tolerations) &&
+		equalNormalized(expectedPodSpec.SecurityContext, livePodSpec.SecurityContext) &&
+		equalNormalized(expected.Spec.Template.Labels, live.Spec.Template.Labels) {
+		return false
+	}
+
+	log.Info(fmt.Sprintf("Deployment %s does not match the expected state, hence updating it", live.Name))
+
+	liveContainer.Name = expectedContainer.Name
+	liveContainer.Image = expectedContainer.Image
+	liveContainer.Args = expectedContainer.Args
+	liveContainer.Ports = expectedContainer.Ports
+	liveContainer.Resources = expectedContainer.Resources
+	liveContainer.SecurityContext = expectedContainer.SecurityContext
+	livePodSpec.ServiceAccountName = expectedPodSpec.ServiceAccountName
+	livePodSpec.NodeSelector = expectedPodSpec.NodeSelector
+	livePodSpec.Tolerations = expectedPodSpec.Tolerations
+	livePodSpec.SecurityContext = expectedPodSpec.SecurityContext
+	live.Spec.Template.Labels = expected.Spec.Template.Labels
+
+	return true
+}
+
+// isOperatorDashboardResource returns true if the resource was created by the operator for the dashboard of a RolloutManager, from its labels. It is used for the cluster-scoped resources of the dashboard, which cannot be owned by the RolloutManager.
+func isOperatorDashboardResource(obj client.Object) bool {
+	labels := obj.GetLabels()
+	return labels[RolloutManagerLabel] != "" && labels[DefaultRolloutsSelectorKey] == DefaultArgoRolloutsDashboardResourceName
+}
+
+// hasDashboardResourceConflict returns true if the given cluster-scoped resource of the dashboard (an empty resource of its kind) already exists, but was not created by the operator (see isOperatorDashboardResource): for example, from the upstream dashboard manifests, which use the same name.
+// Such a resource is neither updated nor deleted by the operator: a Warning Event is recorded on the RolloutManager instead.
+func (r *RolloutManagerReconciler) hasDashboardResourceConflict(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) (bool, error) {
+
+	if err := fetchObject(ctx, r.Client, "", DefaultArgoRolloutsDashboardResourceName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get the dashboard %s: %w", r.resourceKind(obj), err)
+	}
+
+	if isOperatorDashboardResource(obj) {
+		return false, nil
+	}
+
+	message := fmt.Sprintf("%s already exists, and was not created by the operator: it is left unchanged, and the dashboard may lack permissions", r.describeResource(cr, obj))
+	log.Info(message)
+	if r.Recorder != nil {
+		r.Recorder.Event(&cr, corev1.EventTypeWarning, EventReasonDashboardResourceConflict, message)
+	}
+	return true, nil
+}
+
+// removeDashboardResources deletes the resources of the Argo Rollouts dashboard of the RolloutManager, if they exist.
+func (r *RolloutManagerReconciler) removeDashboardResources(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	resources := []client.Object{
+		&networkingv1.Ingress{},
+		&corev1.Service{},
+		&appsv1.Deployment{},
+		&rbacv1.RoleBinding{},
+		&rbacv1.Role{},
+		&corev1.ServiceAccount{},
+	}
+	if !cr.Spec.NamespaceScoped {
+		resources = append(resources, &rbacv1.ClusterRoleBinding{}, &rbacv1.ClusterRole{})
+	}
+
+	for _, obj := range resources {
+		if err := r.deleteDashboardResource(ctx, cr, obj); err != nil {
+			return err
+		}
+	}
+
+	// The Certificate is only deleted if it is owned by the RolloutManager. The TLS Secret written by cert-manager is left alone.
+	_, err := r.reconcileDashboardCertificate(ctx, cr)
+	return err
+}
+
+// deleteDashboardResource deletes the given resource of the Argo Rollouts dashboard (an empty resource of the kind to delete), if it exists. Namespaced resources are only deleted if they are owned by the RolloutManager, and cluster-scoped resources only if they carry its labels (see isOperatorDashboardResource): they are never deleted when the cluster API is disabled.
+func (r *RolloutManagerReconciler) deleteDashboardResource(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error {
+
+	namespace := cr.Namespace
+	switch obj.(type) {
+	case *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding:
+		if r.clusterAPIDisabled() {
+			return nil
+		}
+		namespace = ""
+	}
+
+	if err := fetchObject(ctx, r.Client, namespace, DefaultArgoRolloutsDashboardResourceName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the dashboard %s: %w", r.resourceKind(obj), err)
+	}
+
+	// Resources of the same name which were not created by the operator for this RolloutManager (for example, from the upstream dashboard manifests) are left alone
+	if namespace != "" && !metav1.IsControlledBy(obj, &cr) {
+		return nil
+	}
+	if namespace == "" && (!isOperatorDashboardResource(obj) || obj.GetLabels()[RolloutManagerLabel] != cr.Name) {
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("Deleting %s, as the dashboard is no longer enabled", r.describeResource(cr, obj)))
+	if err := r.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the dashboard %s: %w", r.resourceKind(obj), err)
+	}
+	return nil
+}
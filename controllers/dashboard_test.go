@@ -0,0 +1,407 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Dashboard tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	DescribeTable("getDashboardContainerImage", func(image string, version string, controllerVersion string, expectedImage string) {
+		cr.Spec.Version = controllerVersion
+		cr.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{Enabled: true, Image: image, Version: version}
+		Expect(getDashboardContainerImage(cr)).To(Equal(expectedImage))
+	},
+		Entry("defaults", "", "", "", DefaultArgoRolloutsDashboardImage+":"+DefaultArgoRolloutsVersion),
+		Entry("version of the Rollouts controller", "", "", "v1.6.0", DefaultArgoRolloutsDashboardImage+":v1.6.0"),
+		Entry("version of the dashboard", "", "v1.7.0", "v1.6.0", DefaultArgoRolloutsDashboardImage+":v1.7.0"),
+		Entry("image of the dashboard", "example.com/dashboard", "", "", "example.com/dashboard:"+DefaultArgoRolloutsVersion),
+		Entry("digest", "example.com/dashboard", "sha256:abc", "", "example.com/dashboard@sha256:abc"),
+	)
+
+	It("should restrict the dashboard of a namespace-scoped RolloutManager to its namespace", func() {
+		Expect(getDashboardArgs(cr)).To(Equal([]string{"dashboard", "--port=3100"}))
+
+		cr.Spec.NamespaceScoped = true
+		Expect(getDashboardArgs(cr)).To(Equal([]string{"dashboard", "--port=3100", "--namespace=" + cr.Namespace}))
+		Expect(getDashboardPolicyRules(cr)[1].Resources).ToNot(ContainElement("clusteranalysistemplates"))
+	})
+
+	It("should select the dashboard pods with labels which differ from those of the Rollouts controller", func() {
+		cr.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{Enabled: true}
+
+		deployment := generateDesiredDashboardDeployment(cr)
+		Expect(deployment.Spec.Selector.MatchLabels).To(Equal(map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsDashboardResourceName}))
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue(DefaultRolloutsSelectorKey, DefaultArgoRolloutsDashboardResourceName))
+		Expect(deployment.Labels).To(HaveKeyWithValue(DefaultRolloutsSelectorKey, DefaultArgoRolloutsDashboardResourceName))
+		Expect(isNonOperatorRolloutsDeployment(deployment)).To(BeFalse())
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		})
+
+		expectDashboardResources := func(exist bool, objs ...client.Object) {
+			for _, obj := range objs {
+				namespace := cr.Namespace
+				switch obj.(type) {
+				case *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding:
+					namespace = ""
+				}
+				err := fetchObject(ctx, r.Client, namespace, DefaultArgoRolloutsDashboardResourceName, obj)
+				if exist {
+					Expect(err).ToNot(HaveOccurred(), r.resourceKind(obj))
+				} else {
+					Expect(apierrors.IsNotFound(err)).To(BeTrue(), r.resourceKind(obj))
+				}
+			}
+		}
+
+		It("should not create the dashboard when it is not enabled", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectDashboardResources(false, &appsv1.Deployment{}, &corev1.Service{}, &corev1.ServiceAccount{}, &rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{}, &networkingv1.Ingress{})
+		})
+
+		It("should create the dashboard and its Ingress when they are enabled, and delete them once they are disabled", func() {
+			className := "nginx"
+			cr.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{
+				Enabled: true,
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+				},
+				Ingress: &rolloutsmanagerv1alpha1.RolloutManagerDashboardIngressSpec{
+					Enabled:          true,
+					Host:             "rollouts.example.com",
+					IngressClassName: &className,
+					Annotations:      map[string]string{"nginx.ingress.kubernetes.io/ssl-redirect": "true"},
+				},
+			}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, deployment)).To(Succeed())
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal(DefaultArgoRolloutsDashboardImage + ":" + DefaultArgoRolloutsVersion))
+			Expect(container.Args).To(Equal([]string{"dashboard", "--port=3100"}))
+			Expect(container.Resources.Requests.Cpu().String()).To(Equal("50m"))
+			Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(Equal(DefaultArgoRolloutsDashboardResourceName))
+
+			service := &corev1.Service{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, service)).To(Succeed())
+			Expect(service.Spec.Ports).To(HaveLen(1))
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(DefaultArgoRolloutsDashboardPort)))
+			Expect(service.Spec.Selector).To(Equal(deployment.Spec.Selector.MatchLabels))
+
+			ingress := &networkingv1.Ingress{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, ingress)).To(Succeed())
+			Expect(*ingress.Spec.IngressClassName).To(Equal(className))
+			Expect(ingress.Annotations).To(HaveKeyWithValue("nginx.ingress.kubernetes.io/ssl-redirect", "true"))
+			Expect(ingress.Spec.Rules).To(HaveLen(1))
+			Expect(ingress.Spec.Rules[0].Host).To(Equal("rollouts.example.com"))
+			Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name).To(Equal(service.Name))
+
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			Expect(fetchObject(ctx, r.Client, "", DefaultArgoRolloutsDashboardResourceName, clusterRoleBinding)).To(Succeed())
+			Expect(clusterRoleBinding.RoleRef.Name).To(Equal(DefaultArgoRolloutsDashboardResourceName))
+			Expect(clusterRoleBinding.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: DefaultArgoRolloutsDashboardResourceName, Namespace: cr.Namespace}}))
+			expectDashboardResources(true, &corev1.ServiceAccount{}, &rbacv1.ClusterRole{})
+
+			By("verifying that the Rollouts controller is unaffected")
+			controllerService := &corev1.Service{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, controllerService)).To(Succeed())
+			Expect(controllerService.Spec.Selector).To(Equal(map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName}))
+
+			By("changing the version of the dashboard, and disabling its Ingress")
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			cr.Spec.Dashboard.Version = "v1.6.0"
+			cr.Spec.Dashboard.Ingress.Enabled = false
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(DefaultArgoRolloutsDashboardImage + ":v1.6.0"))
+			expectDashboardResources(false, &networkingv1.Ingress{})
+
+			By("disabling the dashboard")
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			cr.Spec.Dashboard.Enabled = false
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectDashboardResources(false, &appsv1.Deployment{}, &corev1.Service{}, &corev1.ServiceAccount{}, &rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{})
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).To(Succeed())
+		})
+
+		It("should request the TLS certificate of the dashboard from cert-manager, and only create the Ingress once the certificate is ready", func() {
+			cr.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{
+				Enabled: true,
+				Ingress: &rolloutsmanagerv1alpha1.RolloutManagerDashboardIngressSpec{
+					Enabled: true,
+					Host:    "rollouts.example.com",
+					TLS: &rolloutsmanagerv1alpha1.RolloutManagerDashboardIngressTLSSpec{
+						Enabled: true,
+						CertManager: &rolloutsmanagerv1alpha1.RolloutManagerDashboardCertManagerSpec{
+							IssuerRef: rolloutsmanagerv1alpha1.RolloutManagerCertManagerIssuerRef{Name: "letsencrypt", Kind: "ClusterIssuer"},
+						},
+					},
+				},
+			}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			expectCertificateNotReadyCondition := func(messageSubstring string) {
+				Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+				if messageSubstring == "" {
+					Expect(cr.Status.Conditions).ToNot(ContainElement(HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerDashboardCertificateNotReadyConditionType)))
+					return
+				}
+				Expect(cr.Status.Conditions).To(ContainElement(And(
+					HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerDashboardCertificateNotReadyConditionType),
+					HaveField("Status", metav1.ConditionTrue),
+					HaveField("Reason", rolloutsmanagerv1alpha1.RolloutManagerReasonDashboardCertificateNotReady),
+					HaveField("Message", And(HavePrefix(DashboardCertificateNotReadyMessage), ContainSubstring(messageSubstring))),
+				)))
+			}
+
+			By("reconciling while cert-manager is not installed, which should not create the Ingress")
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			expectDashboardResources(true, &appsv1.Deployment{}, &corev1.Service{})
+			expectDashboardResources(false, &networkingv1.Ingress{})
+			expectCertificateNotReadyCondition("cert-manager is not installed")
+
+			By("installing cert-manager, which should create the Certificate, but not yet the Ingress")
+			Expect(r.Client.Create(ctx, &crdv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: certificatesCRDName}})).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			certificate := newCertificate()
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, certificate)).To(Succeed())
+			Expect(metav1.IsControlledBy(certificate, &cr)).To(BeTrue())
+			secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+			Expect(secretName).To(Equal(DefaultArgoRolloutsDashboardTLSSecretName))
+			dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+			Expect(dnsNames).To(Equal([]string{"rollouts.example.com"}))
+			issuerRef, _, _ := unstructured.NestedStringMap(certificate.Object, "spec", "issuerRef")
+			Expect(issuerRef).To(Equal(map[string]string{"name": "letsencrypt", "kind": "ClusterIssuer", "group": "cert-manager.io"}))
+
+			expectDashboardResources(false, &networkingv1.Ingress{})
+			expectCertificateNotReadyCondition("has not been issued yet")
+
+			By("reporting the message of the Ready condition of the Certificate while it is not ready")
+			Expect(unstructured.SetNestedSlice(certificate.Object, []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "message": "Issuing certificate as Secret does not exist"},
+			}, "status", "conditions")).To(Succeed())
+			Expect(r.Client.Update(ctx, certificate)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			expectDashboardResources(false, &networkingv1.Ingress{})
+			expectCertificateNotReadyCondition("Issuing certificate as Secret does not exist")
+
+			By("marking the Certificate as ready, which should create the Ingress with TLS, and remove the condition")
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, certificate)).To(Succeed())
+			Expect(unstructured.SetNestedSlice(certificate.Object, []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			}, "status", "conditions")).To(Succeed())
+			Expect(r.Client.Update(ctx, certificate)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			ingress := &networkingv1.Ingress{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, ingress)).To(Succeed())
+			Expect(ingress.Spec.TLS).To(Equal([]networkingv1.IngressTLS{{Hosts: []string{"rollouts.example.com"}, SecretName: DefaultArgoRolloutsDashboardTLSSecretName}}))
+			expectCertificateNotReadyCondition("")
+
+			By("using an existing TLS Secret instead of cert-manager, which should delete the Certificate")
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			cr.Spec.Dashboard.Ingress.TLS.CertManager = nil
+			cr.Spec.Dashboard.Ingress.TLS.SecretName = "my-tls"
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, newCertificate())
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, ingress)).To(Succeed())
+			Expect(ingress.Spec.TLS).To(Equal([]networkingv1.IngressTLS{{Hosts: []string{"rollouts.example.com"}, SecretName: "my-tls"}}))
+		})
+
+		It("should set the RolloutManager to the Failure phase if the TLS certificate of the dashboard is requested from cert-manager without a host", func() {
+			cr.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{
+				Enabled: true,
+				Ingress: &rolloutsmanagerv1alpha1.RolloutManagerDashboardIngressSpec{
+					Enabled: true,
+					TLS: &rolloutsmanagerv1alpha1.RolloutManagerDashboardIngressTLSSpec{
+						Enabled: true,
+						CertManager: &rolloutsmanagerv1alpha1.RolloutManagerDashboardCertManagerSpec{
+							IssuerRef: rolloutsmanagerv1alpha1.RolloutManagerCertManagerIssuerRef{Name: "letsencrypt"},
+						},
+					},
+				},
+			}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions).To(ContainElement(HaveField("Reason", rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidDashboard)))
+		})
+
+		It("should grant the dashboard of a namespace-scoped RolloutManager permissions in its namespace only", func() {
+			os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+			r.NamespaceScopedArgoRolloutsController = true
+			cr.Spec.NamespaceScoped = true
+			cr.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{Enabled: true}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, roleBinding)).To(Succeed())
+			Expect(roleBinding.RoleRef.Kind).To(Equal("Role"))
+			expectDashboardResources(true, &rbacv1.Role{}, &appsv1.Deployment{})
+			expectDashboardResources(false, &rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{})
+
+			By("disabling the dashboard")
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			cr.Spec.Dashboard = nil
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectDashboardResources(false, &rbacv1.Role{}, &rbacv1.RoleBinding{}, &appsv1.Deployment{})
+		})
+
+		It("should delete the ClusterRole and ClusterRoleBinding of the dashboard once the RolloutManager is deleted", func() {
+			cr.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{Enabled: true}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			expectDashboardResources(true, &rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{})
+
+			Expect(r.Client.Delete(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			expectDashboardResources(false, &rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{})
+		})
+
+		It("should not delete a ClusterRole and ClusterRoleBinding of the dashboard which were not created by the operator", func() {
+			By("creating them, as the upstream dashboard manifests do")
+			upstreamLabels := map[string]string{"app.kubernetes.io/component": "rollouts-dashboard", "app.kubernetes.io/name": "argo-rollouts-dashboard", "app.kubernetes.io/part-of": "argo-rollouts"}
+			Expect(r.Client.Create(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsDashboardResourceName, Labels: upstreamLabels},
+			})).To(Succeed())
+			Expect(r.Client.Create(ctx, &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsDashboardResourceName, Labels: upstreamLabels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: DefaultArgoRolloutsDashboardResourceName},
+			})).To(Succeed())
+
+			By("reconciling a RolloutManager whose dashboard is not enabled")
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			expectDashboardResources(true, &rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{})
+
+			By("deleting the RolloutManager")
+			Expect(r.Client.Delete(ctx, &cr)).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			expectDashboardResources(true, &rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{})
+		})
+
+		It("should not update a ClusterRole and ClusterRoleBinding of the dashboard which were not created by the operator", func() {
+			recorder := &namespacedEventRecorder{}
+			r.Recorder = recorder
+
+			By("creating them, as the upstream dashboard manifests do")
+			upstreamLabels := map[string]string{"app.kubernetes.io/component": "rollouts-dashboard", "app.kubernetes.io/name": "argo-rollouts-dashboard", "app.kubernetes.io/part-of": "argo-rollouts"}
+			upstreamRules := []rbacv1.PolicyRule{{APIGroups: []string{"argoproj.io"}, Resources: []string{"rollouts"}, Verbs: []string{"get"}}}
+			upstreamSubjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: DefaultArgoRolloutsDashboardResourceName, Namespace: "argo-rollouts"}}
+			Expect(r.Client.Create(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsDashboardResourceName, Labels: upstreamLabels},
+				Rules:      upstreamRules,
+			})).To(Succeed())
+			Expect(r.Client.Create(ctx, &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsDashboardResourceName, Labels: upstreamLabels},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: DefaultArgoRolloutsDashboardResourceName},
+				Subjects:   upstreamSubjects,
+			})).To(Succeed())
+
+			By("reconciling a RolloutManager whose dashboard is enabled")
+			cr.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{Enabled: true}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			clusterRole := &rbacv1.ClusterRole{}
+			Expect(fetchObject(ctx, r.Client, "", DefaultArgoRolloutsDashboardResourceName, clusterRole)).To(Succeed())
+			Expect(clusterRole.Rules).To(Equal(upstreamRules))
+			Expect(clusterRole.Labels).To(Equal(upstreamLabels))
+
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			Expect(fetchObject(ctx, r.Client, "", DefaultArgoRolloutsDashboardResourceName, clusterRoleBinding)).To(Succeed())
+			Expect(clusterRoleBinding.Subjects).To(Equal(upstreamSubjects))
+			Expect(clusterRoleBinding.Labels).To(Equal(upstreamLabels))
+
+			Expect(recorder.events).To(ContainElement(cr.Namespace + "/*v1alpha1.RolloutManager " + EventReasonDashboardResourceConflict))
+
+			By("deleting the upstream ClusterRoleBinding, which is not recreated for the dashboard, as it would bind the ClusterRole which was not created by the operator")
+			Expect(r.Client.Delete(ctx, clusterRoleBinding)).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			expectDashboardResources(false, &rbacv1.ClusterRoleBinding{})
+		})
+	})
+})
@@ -0,0 +1,240 @@
+package rollouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// DefaultArgoRolloutsDashboardTLSSecretName is the default name of the Secret that contains the TLS certificate and key of the dashboard Ingress.
+	DefaultArgoRolloutsDashboardTLSSecretName = "argo-rollouts-dashboard-tls"
+
+	certificatesCRDName = "certificates.cert-manager.io"
+
+	defaultCertManagerIssuerKind  = "Issuer"
+	defaultCertManagerIssuerGroup = "cert-manager.io"
+)
+
+// certificateGVK is the GroupVersionKind of the cert-manager Certificate. cert-manager is an optional add-on, so the Certificate is handled as an unstructured object.
+var certificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+// certificateGVR is the GroupVersionResource of the cert-manager Certificate, used to detect whether cert-manager is installed on startup.
+var certificateGVR = certificateGVK.GroupVersion().WithResource("certificates")
+
+func newCertificate() *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	return certificate
+}
+
+// isDashboardTLSEnabled returns true if the RolloutManager requests TLS for the Ingress of the Argo Rollouts dashboard.
+func isDashboardTLSEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return isDashboardIngressEnabled(cr) && cr.Spec.Dashboard.Ingress.TLS != nil && cr.Spec.Dashboard.Ingress.TLS.Enabled
+}
+
+// isDashboardCertManagerEnabled returns true if the RolloutManager requests the TLS certificate of the dashboard Ingress from cert-manager.
+func isDashboardCertManagerEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return isDashboardTLSEnabled(cr) && cr.Spec.Dashboard.Ingress.TLS.CertManager != nil
+}
+
+// getDashboardTLSSecretName returns the name of the Secret that contains the TLS certificate and key of the dashboard Ingress.
+func getDashboardTLSSecretName(cr rolloutsmanagerv1alpha1.RolloutManager) string {
+	if cr.Spec.Dashboard.Ingress.TLS.SecretName != "" {
+		return cr.Spec.Dashboard.Ingress.TLS.SecretName
+	}
+	return DefaultArgoRolloutsDashboardTLSSecretName
+}
+
+// getDashboardIngressTLS returns the TLS of the dashboard Ingress, or nil if TLS is not enabled.
+func getDashboardIngressTLS(cr rolloutsmanagerv1alpha1.RolloutManager) []networkingv1.IngressTLS {
+	if !isDashboardTLSEnabled(cr) {
+		return nil
+	}
+
+	tls := networkingv1.IngressTLS{SecretName: getDashboardTLSSecretName(cr)}
+	if cr.Spec.Dashboard.Ingress.Host != "" {
+		tls.Hosts = []string{cr.Spec.Dashboard.Ingress.Host}
+	}
+	return []networkingv1.IngressTLS{tls}
+}
+
+// validateDashboard verifies that the TLS of the dashboard Ingress can be configured: a certificate from cert-manager is issued for the host of the Ingress, by the referenced issuer.
+func validateDashboard(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if !isDashboardCertManagerEnabled(cr) {
+		return nil
+	}
+
+	if cr.Spec.Dashboard.Ingress.Host == "" {
+		return errors.New("the TLS certificate of the dashboard can only be requested from cert-manager if the host of the dashboard Ingress is specified")
+	}
+	if cr.Spec.Dashboard.Ingress.TLS.CertManager.IssuerRef.Name == "" {
+		return errors.New("the issuer of the TLS certificate of the dashboard must be specified via .spec.dashboard.ingress.tls.certManager.issuerRef.name")
+	}
+	return nil
+}
+
+// generateDesiredDashboardCertificate returns the cert-manager Certificate of the dashboard Ingress, which writes the TLS certificate and key to the TLS Secret of the Ingress.
+func generateDesiredDashboardCertificate(cr rolloutsmanagerv1alpha1.RolloutManager) *unstructured.Unstructured {
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      DefaultArgoRolloutsDashboardResourceName,
+		Namespace: cr.Namespace,
+	}
+	setDashboardLabelsAndAnnotationsToObject(&objectMeta, cr)
+
+	certificate := newCertificate()
+	certificate.SetName(objectMeta.Name)
+	certificate.SetNamespace(objectMeta.Namespace)
+	certificate.SetLabels(objectMeta.Labels)
+	certificate.SetAnnotations(objectMeta.Annotations)
+
+	issuerRef := cr.Spec.Dashboard.Ingress.TLS.CertManager.IssuerRef
+	if issuerRef.Kind == "" {
+		issuerRef.Kind = defaultCertManagerIssuerKind
+	}
+	if issuerRef.Group == "" {
+		issuerRef.Group = defaultCertManagerIssuerGroup
+	}
+
+	certificate.Object["spec"] = map[string]interface{}{
+		"secretName": getDashboardTLSSecretName(cr),
+		"dnsNames":   []interface{}{cr.Spec.Dashboard.Ingress.Host},
+		"issuerRef": map[string]interface{}{
+			"name":  issuerRef.Name,
+			"kind":  issuerRef.Kind,
+			"group": issuerRef.Group,
+		},
+	}
+
+	return certificate
+}
+
+// isCertificateInstalled returns true if cert-manager is installed on the cluster, by checking the CustomResourceDefinition of the Certificate (or, if the cluster API is disabled, by listing Certificates in the namespace)
+func (r *RolloutManagerReconciler) isCertificateInstalled(ctx context.Context, namespace string) (bool, error) {
+	if r.clusterAPIDisabled() {
+		certificateList := &unstructured.UnstructuredList{}
+		certificateList.SetGroupVersionKind(certificateGVK.GroupVersion().WithKind(certificateGVK.Kind + "List"))
+		served, err := r.isAPIServedInNamespace(ctx, certificateList, namespace)
+		if err != nil {
+			return false, fmt.Errorf("failed to list Certificates in namespace %s: %w", namespace, err)
+		}
+		return served, nil
+	}
+
+	if _, err := fetchObjectMetadata(ctx, r.Client, customResourceDefinitionGVK, "", certificatesCRDName); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get the CustomResourceDefinition %s: %w", certificatesCRDName, err)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// reconcileDashboardCertificate creates/updates the cert-manager Certificate of the dashboard Ingress if it is requested, and deletes it otherwise. Only the Certificate owned by the RolloutManager is updated or deleted.
+// It returns the reasons why the certificate is not ready (for example, because cert-manager has not issued it yet, or is not installed): the dashboard Ingress should not be exposed until there are none.
+func (r *RolloutManagerReconciler) reconcileDashboardCertificate(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) ([]string, error) {
+
+	installed, err := r.isCertificateInstalled(ctx, cr.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !installed {
+		if isDashboardCertManagerEnabled(cr) {
+			return []string{fmt.Sprintf("cert-manager is not installed on the cluster (the CustomResourceDefinition %s does not exist)", certificatesCRDName)}, nil
+		}
+		return []string{}, nil
+	}
+
+	liveCertificate := newCertificate()
+	liveCertificateExists := true
+	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsDashboardResourceName, liveCertificate); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get the dashboard Certificate: %w", err)
+		}
+		liveCertificateExists = false
+	}
+
+	if !isDashboardCertManagerEnabled(cr) {
+		if liveCertificateExists && metav1.IsControlledBy(liveCertificate, &cr) {
+			log.Info(fmt.Sprintf("Deleting Certificate %s, as the TLS certificate of the dashboard is no longer requested from cert-manager", liveCertificate.GetName()))
+			if err := r.Client.Delete(ctx, liveCertificate); err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to delete the dashboard Certificate: %w", err)
+			}
+		}
+		return []string{}, nil
+	}
+
+	expectedCertificate := generateDesiredDashboardCertificate(cr)
+
+	if !liveCertificateExists {
+		if err := r.setControllerReference(&cr, expectedCertificate); err != nil {
+			return nil, err
+		}
+
+		log.Info(fmt.Sprintf("Creating Certificate %s", expectedCertificate.GetName()))
+		if err := r.Client.Create(ctx, expectedCertificate); err != nil {
+			return nil, fmt.Errorf("failed to create the dashboard Certificate: %w", err)
+		}
+		return []string{fmt.Sprintf("Certificate %s was created, and has not been issued yet", expectedCertificate.GetName())}, nil
+	}
+
+	// A Certificate of the same name which was not created by the operator is not updated, but the Ingress still waits for it to be ready
+	if !metav1.IsControlledBy(liveCertificate, &cr) {
+		log.Info(fmt.Sprintf("Certificate %s already exists, and is not managed by the operator: skipping its reconciliation", liveCertificate.GetName()))
+
+	} else if !reflect.DeepEqual(liveCertificate.Object["spec"], expectedCertificate.Object["spec"]) {
+		log.Info(fmt.Sprintf("Spec of Certificate %s does not match the expected state, hence updating it", liveCertificate.GetName()))
+		liveCertificate.Object["spec"] = expectedCertificate.Object["spec"]
+		if err := r.Client.Update(ctx, liveCertificate); err != nil {
+			return nil, fmt.Errorf("failed to update the dashboard Certificate: %w", err)
+		}
+		return []string{fmt.Sprintf("Certificate %s was updated, and has not been issued yet", liveCertificate.GetName())}, nil
+	}
+
+	return getCertificateNotReadyReasons(liveCertificate), nil
+}
+
+// getCertificateNotReadyReasons returns the reason why the cert-manager Certificate is not ready, from its Ready condition, or none if it is ready.
+func getCertificateNotReadyReasons(certificate *unstructured.Unstructured) []string {
+
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		if condition["status"] == string(metav1.ConditionTrue) {
+			return []string{}
+		}
+		if message, _ := condition["message"].(string); strings.TrimSpace(message) != "" {
+			return []string{fmt.Sprintf("Certificate %s is not ready: %s", certificate.GetName(), message)}
+		}
+	}
+
+	return []string{fmt.Sprintf("Certificate %s has not been issued yet", certificate.GetName())}
+}
+
+// createDashboardCertificateNotReadyCondition returns the DashboardCertificateNotReady condition for the given reasons.
+func createDashboardCertificateNotReadyCondition(reasons []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerDashboardCertificateNotReadyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonDashboardCertificateNotReady,
+		Message: DashboardCertificateNotReadyMessage + strings.Join(reasons, ", "),
+	}
+}
@@ -0,0 +1,94 @@
+package rollouts
+
+import (
+	"fmt"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultRolloutsPprofPort is the default port of the profiling endpoints of the Rollouts controller
+	DefaultRolloutsPprofPort int32 = 6060
+
+	// DefaultRolloutsPprofTTL is the default time for which the profiling endpoints of the Rollouts controller are enabled
+	DefaultRolloutsPprofTTL = time.Hour
+
+	// rolloutsPprofPortName is the name of the container port (and Service port) of the profiling endpoints of the Rollouts controller
+	rolloutsPprofPortName = "pprof"
+)
+
+// isPprofRequested returns true if the RolloutManager requests the profiling endpoints of the Rollouts controller, regardless of whether they expired.
+func isPprofRequested(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.Debug != nil && cr.Spec.Debug.Pprof
+}
+
+// getRolloutsPprofPort returns the port of the profiling endpoints of the Rollouts controller, from .spec.debug.port.
+func getRolloutsPprofPort(cr rolloutsmanagerv1alpha1.RolloutManager) int32 {
+	if cr.Spec.Debug != nil && cr.Spec.Debug.Port != 0 {
+		return cr.Spec.Debug.Port
+	}
+	return DefaultRolloutsPprofPort
+}
+
+// getPprofTTL returns the time for which the profiling endpoints of the Rollouts controller are enabled, from .spec.debug.ttl.
+func getPprofTTL(cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error) {
+	if cr.Spec.Debug == nil || cr.Spec.Debug.TTL == "" {
+		return DefaultRolloutsPprofTTL, nil
+	}
+	ttl, err := time.ParseDuration(cr.Spec.Debug.TTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid debug ttl '%s': %w", cr.Spec.Debug.TTL, err)
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid debug ttl '%s': must be positive", cr.Spec.Debug.TTL)
+	}
+	return ttl, nil
+}
+
+// getPprofExpirationTime returns the time at which the profiling endpoints of the Rollouts controller are disabled again, or nil if they are not requested: the expiration time recorded in the status of the RolloutManager, if any, otherwise the TTL from now.
+func getPprofExpirationTime(cr rolloutsmanagerv1alpha1.RolloutManager, now time.Time) (*metav1.Time, error) {
+
+	if !isPprofRequested(cr) {
+		return nil, nil
+	}
+
+	ttl, err := getPprofTTL(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	if cr.Status.PprofExpirationTime != nil {
+		return cr.Status.PprofExpirationTime.DeepCopy(), nil
+	}
+
+	expirationTime := metav1.NewTime(now.Add(ttl).Truncate(time.Second))
+	return &expirationTime, nil
+}
+
+// getRolloutsDebugArgs returns the command arguments which enable the profiling endpoints of the Rollouts controller, if they are enabled.
+func getRolloutsDebugArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	if !isPprofRequested(cr) {
+		return []string{}
+	}
+	return []string{fmt.Sprintf("--pprof-port=%d", getRolloutsPprofPort(cr))}
+}
+
+// setPprofExpirationTime sets the expiration time of the profiling endpoints of the Rollouts controller on the status of the RolloutManager, or removes it if they are no longer requested. It returns true if the status changed.
+func setPprofExpirationTime(rm *rolloutsmanagerv1alpha1.RolloutManager, expirationTime *metav1.Time) bool {
+
+	if !isPprofRequested(*rm) {
+		if rm.Status.PprofExpirationTime == nil {
+			return false
+		}
+		rm.Status.PprofExpirationTime = nil
+		return true
+	}
+
+	if expirationTime == nil || (rm.Status.PprofExpirationTime != nil && rm.Status.PprofExpirationTime.Equal(expirationTime)) {
+		return false
+	}
+	rm.Status.PprofExpirationTime = expirationTime
+	return true
+}
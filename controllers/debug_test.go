@@ -0,0 +1,165 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Rollouts controller profiling tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+		cr.Spec.Debug = &rolloutsmanagerv1alpha1.RolloutManagerDebugSpec{Pprof: true}
+	})
+
+	It("should add the profiling port and argument to the Rollouts controller container", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+
+		container := deployment.Spec.Template.Spec.Containers[0]
+		Expect(container.Args).To(Equal([]string{"--pprof-port=6060"}))
+		Expect(container.Ports).To(ContainElement(corev1.ContainerPort{Name: "pprof", ContainerPort: 6060, Protocol: corev1.ProtocolTCP}))
+
+		normalized, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalized).To(Equal(deployment))
+
+		By("using the port of the RolloutManager")
+		cr.Spec.Debug.Port = 7070
+		Expect(getRolloutsDebugArgs(cr)).To(Equal([]string{"--pprof-port=7070"}))
+		Expect(getExtraServicePorts(cr, rolloutsmanagerv1alpha1.ExtraPortExposeService)[0].Port).To(Equal(int32(7070)))
+
+		By("verifying that nothing is added when profiling is not requested")
+		cr.Spec.Debug.Pprof = false
+		Expect(getRolloutsDebugArgs(cr)).To(BeEmpty())
+		Expect(getRolloutsExtraPorts(cr)).To(BeEmpty())
+	})
+
+	It("getPprofExpirationTime should use the recorded expiration time, or the TTL from now", func() {
+		now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		expirationTime, err := getPprofExpirationTime(cr, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expirationTime.Time).To(Equal(now.Add(time.Hour)))
+
+		cr.Spec.Debug.TTL = "15m"
+		expirationTime, err = getPprofExpirationTime(cr, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expirationTime.Time).To(Equal(now.Add(15 * time.Minute)))
+
+		recorded := metav1.NewTime(now.Add(5 * time.Minute))
+		cr.Status.PprofExpirationTime = &recorded
+		expirationTime, err = getPprofExpirationTime(cr, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expirationTime.Time).To(Equal(recorded.Time))
+
+		cr.Spec.Debug.TTL = "-1m"
+		_, err = getPprofExpirationTime(cr, now)
+		Expect(err).To(MatchError(ContainSubstring("must be positive")))
+
+		cr.Spec.Debug.Pprof = false
+		Expect(getPprofExpirationTime(cr, now)).To(BeNil())
+	})
+
+	It("setPprofExpirationTime should set the expiration time, and remove it once profiling is no longer requested", func() {
+		expirationTime := metav1.NewTime(time.Now().Truncate(time.Second))
+
+		Expect(setPprofExpirationTime(&cr, &expirationTime)).To(BeTrue())
+		Expect(setPprofExpirationTime(&cr, &expirationTime)).To(BeFalse())
+		Expect(setPprofExpirationTime(&cr, nil)).To(BeFalse())
+		Expect(cr.Status.PprofExpirationTime).To(Equal(&expirationTime))
+
+		cr.Spec.Debug.Pprof = false
+		Expect(setPprofExpirationTime(&cr, nil)).To(BeTrue())
+		Expect(cr.Status.PprofExpirationTime).To(BeNil())
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		})
+
+		It("should enable the profiling endpoints until they expire", func() {
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.PprofExpirationTime).ToNot(BeNil())
+			Expect(cr.Status.PprofExpirationTime.Time).To(BeTemporally("~", time.Now().Add(time.Hour), 5*time.Second))
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--pprof-port=6060"))
+
+			service := &corev1.Service{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsExtraPortsServiceName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Name).To(Equal("pprof"))
+
+			By("expiring the profiling endpoints")
+			expired := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+			cr.Status.PprofExpirationTime = &expired
+			Expect(r.Client.Status().Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Args).ToNot(ContainElement("--pprof-port=6060"))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports).To(HaveLen(2))
+
+			err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsExtraPortsServiceName, service)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.PprofExpirationTime.Time).To(Equal(expired.Time))
+
+			By("disabling profiling, which removes the expiration time so that it can be enabled again")
+			cr.Spec.Debug.Pprof = false
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.PprofExpirationTime).To(BeNil())
+		})
+
+		It("should set the phase to Failure if the TTL is invalid", func() {
+			cr.Spec.Debug.TTL = "soon"
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidDebug))
+		})
+	})
+})
@@ -1,10 +1,29 @@
 package rollouts
 
+import "time"
+
 const (
 	// ArgoRolloutsImageEnvName is an environment variable that can be used to deploy a
 	// Custom Image of rollouts controller.
 	ArgoRolloutsImageEnvName = "ARGO_ROLLOUTS_IMAGE"
 
+	// RelatedImageEnvNamePrefix is the prefix of the environment variables which declare the related images of the operator (the images that it deploys, or that are part of its installation), as set by OLM from the relatedImages of the ClusterServiceVersion.
+	RelatedImageEnvNamePrefix = "RELATED_IMAGE_"
+
+	// RelatedImageRolloutsEnvName is an environment variable that can be used to set the default image of the Rollouts controller, e.g. to an image pinned by digest. ARGO_ROLLOUTS_IMAGE takes precedence over it.
+	RelatedImageRolloutsEnvName = RelatedImageEnvNamePrefix + "ARGO_ROLLOUTS"
+
+	// DigestOnlyImagesEnvName is an environment variable that can be set to 'true' to only allow images which are pinned by digest (for example, in disconnected environments, where images are mirrored by digest).
+	DigestOnlyImagesEnvName = "DIGEST_ONLY_IMAGES"
+
+	// DefaultImageRegistryMirrorEnvName is an environment variable that can be used to pull the default images from a mirror registry (for example, in disconnected environments), without setting the image in each RolloutManager.
+	// The registry of each default image is replaced with the value, e.g. 'mirror.example.com/quay' rewrites 'quay.io/argoproj/argo-rollouts' to 'mirror.example.com/quay/argoproj/argo-rollouts'.
+	DefaultImageRegistryMirrorEnvName = "DEFAULT_IMAGE_REGISTRY_MIRROR"
+
+	// CommonLabelsEnvName is an environment variable that can be used to set labels on all resources created by the operator, for all RolloutManagers (for example, organization-mandated cost allocation labels), as a comma-separated list of 'key=value' pairs.
+	// These take precedence over the labels of .spec.additionalMetadata.
+	CommonLabelsEnvName = "COMMON_LABELS"
+
 	// DefaultArgoRolloutsMetricsServiceName is the default name for rollouts metrics Service.
 	DefaultArgoRolloutsMetricsServiceName = "argo-rollouts-metrics"
 
@@ -38,4 +57,61 @@ const (
 
 	// ClusterScopedArgoRolloutsNamespaces is an environment variable that can be used to configure namespaces that are allowed to host cluster-scoped Argo Rollouts
 	ClusterScopedArgoRolloutsNamespaces = "CLUSTER_SCOPED_ARGO_ROLLOUTS_NAMESPACES"
+
+	// DefaultRolloutsNotificationConfigMapName is the name of the ConfigMap that contains the Rollouts notification templates and triggers
+	DefaultRolloutsNotificationConfigMapName = "argo-rollouts-notification-configmap"
+
+	// DefaultRolloutsBackupSecretName is the default name of the Secret that backups of the Rollouts configuration are written to
+	DefaultRolloutsBackupSecretName = "argo-rollouts-config-backup"
+
+	// DefaultRolloutsBackupSchedule is the default interval between two backups of the Rollouts configuration
+	DefaultRolloutsBackupSchedule = 24 * time.Hour
+
+	// DefaultRolloutsBackupGenerations is the number of generations of the backup that are kept in the backup Secret, including the most recent one
+	DefaultRolloutsBackupGenerations = 3
+
+	// LastBackupTimeAnnotation is set on the backup Secret, and contains the time at which the last backup was taken
+	LastBackupTimeAnnotation = "argo-rollouts.argoproj.io/last-backup-time"
+
+	// RestoreBackupAnnotation can be set on a RolloutManager to request that the Rollouts configuration is restored from the backup Secret.
+	// A restore is performed each time the value of the annotation changes.
+	RestoreBackupAnnotation = "argo-rollouts.argoproj.io/restore-backup"
+
+	// RestoreBackupGenerationAnnotation can be set on a RolloutManager, together with RestoreBackupAnnotation, to restore a previous generation of the backup: 0 (the default) is the most recent backup, 1 the backup before it, and so on.
+	RestoreBackupGenerationAnnotation = "argo-rollouts.argoproj.io/restore-backup-generation"
+
+	// DoNotAdoptLabel is set (to 'true') on the resources which are written by the operator, but which must not be owned by a RolloutManager: the backup Secret, and the resources restored from it. They are never adopted (see adoptOrphanedResources), so that they survive the deletion of the RolloutManager.
+	DoNotAdoptLabel = "argo-rollouts.argoproj.io/do-not-adopt"
+
+	// LastRestoreAnnotation is set on the backup Secret, and contains the value of RestoreBackupAnnotation for which the last restore was performed
+	LastRestoreAnnotation = "argo-rollouts.argoproj.io/last-restore"
+
+	// SpecHashAnnotation is set on the resources managed by the operator, and contains a hash of the expected state that the operator last applied to the resource.
+	// A resource is only updated if the hash of its expected state has changed, or if it was modified by another actor.
+	SpecHashAnnotation = "argo-rollouts.argoproj.io/spec-hash"
+
+	// RolloutManagerLabel is set on the metrics Service of the Rollouts controller, and contains the name of its RolloutManager.
+	RolloutManagerLabel = "argo-rollouts.argoproj.io/rolloutmanager"
+
+	// RolloutManagerMetricsLabel is the label which is added to the Rollouts controller metrics scraped via the ServiceMonitor, and contains the name of the RolloutManager, so that the metrics of multiple Rollouts controllers can be distinguished.
+	RolloutManagerMetricsLabel = "rolloutmanager"
+
+	// rolloutManagerServiceLabelMeta is the Prometheus service discovery meta label of the RolloutManagerLabel of a Service (its name, with invalid characters replaced by underscores).
+	rolloutManagerServiceLabelMeta = "__meta_kubernetes_service_label_argo_rollouts_argoproj_io_rolloutmanager"
+
+	// KubeAPIQPSEnvName and KubeAPIBurstEnvName are environment variables that can be used to set the client-side rate limit of the operator's requests to the Kubernetes API server (reads and resource writes), instead of the --kube-api-qps/--kube-api-burst flags.
+	KubeAPIQPSEnvName   = "KUBE_API_QPS"
+	KubeAPIBurstEnvName = "KUBE_API_BURST"
+
+	// StatusKubeAPIQPSEnvName and StatusKubeAPIBurstEnvName are environment variables that can be used to set the client-side rate limit of the operator's writes of RolloutManager status, instead of the --status-kube-api-qps/--status-kube-api-burst flags.
+	StatusKubeAPIQPSEnvName   = "STATUS_KUBE_API_QPS"
+	StatusKubeAPIBurstEnvName = "STATUS_KUBE_API_BURST"
+
+	// DefaultKubeAPIQPS and DefaultKubeAPIBurst are the default client-side rate limit of the operator's requests to the Kubernetes API server (the defaults of controller-runtime)
+	DefaultKubeAPIQPS   = 20
+	DefaultKubeAPIBurst = 30
+
+	// DefaultStatusKubeAPIQPS and DefaultStatusKubeAPIBurst are the default client-side rate limit of the operator's writes of RolloutManager status
+	DefaultStatusKubeAPIQPS   = 5
+	DefaultStatusKubeAPIBurst = 10
 )
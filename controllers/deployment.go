@@ -3,7 +3,6 @@ package rollouts
 import (
 	"context"
 	"fmt"
-	"os"
 	"reflect"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
@@ -13,7 +12,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 func generateDesiredRolloutsDeployment(cr rolloutsmanagerv1alpha1.RolloutManager, sa corev1.ServiceAccount) appsv1.Deployment {
@@ -33,28 +31,30 @@ func generateDesiredRolloutsDeployment(cr rolloutsmanagerv1alpha1.RolloutManager
 	labels := map[string]string{
 		DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName,
 	}
-	annotations := map[string]string{}
-	if cr.Spec.AdditionalMetadata != nil {
-		for k, v := range cr.Spec.AdditionalMetadata.Labels {
-			labels[k] = v
-		}
-		for k, v := range cr.Spec.AdditionalMetadata.Annotations {
-			annotations[k] = v
-		}
+	additionalLabels, annotations := getAdditionalMetadata(cr)
+	for k, v := range additionalLabels {
+		labels[k] = v
 	}
 
+	// Recommended labels, service mesh labels, cloud identity labels and common labels are only added to the pod template, not to the selector.
+	podLabels := combineStringMaps(getRecommendedLabels(cr), labels, getCommonLabels())
+	setServiceMeshLabelsAndAnnotations(cr, podLabels, annotations)
+	setCloudIdentityLabels(cr, podLabels)
+
 	desiredDeployment.Spec = appsv1.DeploymentSpec{
 		Selector: &metav1.LabelSelector{
 			MatchLabels: labels,
 		},
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
-				Labels:      labels,
+				Labels:      podLabels,
 				Annotations: annotations,
 			},
 			Spec: corev1.PodSpec{
+				// The Rollouts controller image is only built for Linux: without this, the controller may be scheduled onto (and crashloop on) Windows nodes of mixed-OS clusters.
+				// It can be overridden via .spec.nodePlacement.nodeSelector.
 				NodeSelector: map[string]string{
-					"kubernetes.io/os": "linux",
+					corev1.LabelOSStable: "linux",
 				},
 			},
 		},
@@ -66,8 +66,16 @@ func generateDesiredRolloutsDeployment(cr rolloutsmanagerv1alpha1.RolloutManager
 	if cr.Spec.NodePlacement != nil {
 		desiredDeployment.Spec.Template.Spec.NodeSelector = appendStringMap(
 			desiredDeployment.Spec.Template.Spec.NodeSelector, cr.Spec.NodePlacement.NodeSelector)
-		desiredDeployment.Spec.Template.Spec.Tolerations = cr.Spec.NodePlacement.Tolerations
 	}
+	desiredDeployment.Spec.Template.Spec.Tolerations = getRolloutsTolerations(cr)
+	desiredDeployment.Spec.Template.Spec.Affinity = getRolloutsAffinity(cr)
+	if podAntiAffinity := getRolloutsPodAntiAffinity(cr); podAntiAffinity != nil {
+		if desiredDeployment.Spec.Template.Spec.Affinity == nil {
+			desiredDeployment.Spec.Template.Spec.Affinity = &corev1.Affinity{}
+		}
+		desiredDeployment.Spec.Template.Spec.Affinity.PodAntiAffinity = podAntiAffinity
+	}
+	desiredDeployment.Spec.Replicas = getRolloutsReplicas(cr)
 
 	desiredPodSpec := &desiredDeployment.Spec.Template.Spec
 
@@ -78,6 +86,9 @@ func generateDesiredRolloutsDeployment(cr rolloutsmanagerv1alpha1.RolloutManager
 
 	desiredPodSpec.ServiceAccountName = sa.ObjectMeta.Name
 
+	desiredPodSpec.HostNetwork = cr.Spec.HostNetwork
+	desiredPodSpec.DNSPolicy = getRolloutsDNSPolicy(cr)
+
 	desiredPodSpec.Containers = []corev1.Container{
 		rolloutsContainer(cr),
 	}
@@ -96,6 +107,7 @@ func generateDesiredRolloutsDeployment(cr rolloutsmanagerv1alpha1.RolloutManager
 			},
 		},
 	}
+	desiredPodSpec.Volumes = append(desiredPodSpec.Volumes, fileMountVolumes(cr)...)
 
 	return desiredDeployment
 }
@@ -103,7 +115,27 @@ func generateDesiredRolloutsDeployment(cr rolloutsmanagerv1alpha1.RolloutManager
 // Reconcile the Rollouts controller deployment.
 func (r *RolloutManagerReconciler) reconcileRolloutsDeployment(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, sa corev1.ServiceAccount) error {
 
+	if err := r.injectFault(reconcileStepDeployment, cr); err != nil {
+		return err
+	}
+
+	podSecurityLevel, err := r.getPodSecurityLevel(ctx, cr.Namespace)
+	if err != nil {
+		return err
+	}
+
 	desiredDeployment := generateDesiredRolloutsDeployment(cr, sa)
+	applyPodSecurityLevel(&desiredDeployment.Spec.Template.Spec, podSecurityLevel)
+
+	referencedObjectsHash, err := r.computeReferencedObjectsHash(ctx, cr)
+	if err != nil {
+		return err
+	}
+	if referencedObjectsHash != "" {
+		desiredDeployment.Spec.Template.Annotations = combineStringMaps(desiredDeployment.Spec.Template.Annotations, map[string]string{
+			ReferencedObjectsHashAnnotation: referencedObjectsHash,
+		})
+	}
 
 	normalizedDesiredDeployment, err := normalizeDeployment(desiredDeployment, cr)
 	if err != nil {
@@ -119,6 +151,19 @@ func (r *RolloutManagerReconciler) reconcileRolloutsDeployment(ctx context.Conte
 		// We intentionally continue without returning, as the error is non-fatal at runtime
 	}
 
+	if err := r.prepareResource(ctx, cr, &desiredDeployment); err != nil {
+		return err
+	}
+	specHash := computeSpecHash(&desiredDeployment)
+
+	// If the resource transformer changed the Deployment beyond what normalizeDeployment supports (for example, by adding a container), the live Deployment is only compared via its spec hash
+	compareSpecHashOnly := false
+	if !reflect.DeepEqual(normalizedDesiredDeployment, desiredDeployment) {
+		if normalizedDesiredDeployment, err = normalizeDeployment(desiredDeployment, cr); err != nil {
+			compareSpecHashOnly = true
+		}
+	}
+
 	// If the deployment for rollouts does not exist, create one.
 	actualDeployment := &appsv1.Deployment{}
 
@@ -127,18 +172,43 @@ func (r *RolloutManagerReconciler) reconcileRolloutsDeployment(ctx context.Conte
 			return fmt.Errorf("failed to get the Deployment %s: %w", DefaultArgoRolloutsResourceName, err)
 		}
 
-		return r.createNewRolloutsDeployment(ctx, cr, desiredDeployment)
+		return r.createNewRolloutsDeployment(ctx, cr, desiredDeployment, specHash)
 	}
 
 	normalizedActualDeployment, err := normalizeDeployment(*actualDeployment, cr)
 
-	if err != nil || !reflect.DeepEqual(normalizedActualDeployment, normalizedDesiredDeployment) {
+	if err == nil && ignoreInjectedResources(cr) && len(normalizedDesiredDeployment.Spec.Template.Spec.Containers) == 1 {
+		// Resource requests/limits may be modified by admission webhooks (for example, by the Vertical Pod Autoscaler), so they are not compared.
+		normalizedActualDeployment.Spec.Template.Spec.Containers[0].Resources = normalizedDesiredDeployment.Spec.Template.Spec.Containers[0].Resources
+	}
+
+	deploymentChanged := err != nil || !reflect.DeepEqual(normalizedActualDeployment, normalizedDesiredDeployment)
+	if compareSpecHashOnly {
+		deploymentChanged = !specHashMatches(actualDeployment.ObjectMeta, specHash)
+	}
+
+	if deploymentChanged {
 
 		deploymentsDifferent := identifyDeploymentDifference(normalizedActualDeployment, normalizedDesiredDeployment)
+		selectorChanged := !reflect.DeepEqual(normalizedActualDeployment.Spec.Selector, normalizedDesiredDeployment.Spec.Selector)
+
+		if compareSpecHashOnly {
+			deploymentsDifferent = "spec hash of the transformed Deployment"
+			selectorChanged = actualDeployment.Spec.Selector == nil || !reflect.DeepEqual(normalizeMap(actualDeployment.Spec.Selector.MatchLabels), normalizeMap(desiredDeployment.Spec.Selector.MatchLabels))
+		}
+
+		// Updates of the pod template (or the selector) restart the Rollouts controller, so they are subject to the restart budget
+		restartsController := compareSpecHashOnly || selectorChanged || !reflect.DeepEqual(normalizedActualDeployment.Spec.Template, normalizedDesiredDeployment.Spec.Template)
+		if restartsController {
+			deferred, err := r.deferControllerRestart(ctx, cr, actualDeployment)
+			if err != nil || deferred {
+				return err
+			}
+		}
 
 		log.Info("updating Deployment due to detected difference: " + deploymentsDifferent)
 
-		if !reflect.DeepEqual(normalizedActualDeployment.Spec.Selector, normalizedDesiredDeployment.Spec.Selector) {
+		if selectorChanged {
 			// delete and recreate the Deployment if the .spec.selector field changes: this field is immutable.
 
 			log.Info("deleting and recreating Deployment, as the .spec.selector field of the Deployment has changed. Since this field is immutable, the Deployment needs to be recreated.")
@@ -147,7 +217,12 @@ func (r *RolloutManagerReconciler) reconcileRolloutsDeployment(ctx context.Conte
 				return fmt.Errorf("unable to delete Rollouts Deployment after .spec.selector change: %w", err)
 			}
 
-			return r.createNewRolloutsDeployment(ctx, cr, desiredDeployment)
+			setReplacedUIDAnnotation(&desiredDeployment.ObjectMeta, actualDeployment.UID)
+			return r.createNewRolloutsDeployment(ctx, cr, desiredDeployment, specHash)
+		}
+
+		if restartsController {
+			setControllerRestartAnnotations(cr, &actualDeployment.ObjectMeta)
 		}
 
 		if deploymentsDifferent == "" {
@@ -155,7 +230,16 @@ func (r *RolloutManagerReconciler) reconcileRolloutsDeployment(ctx context.Conte
 			// this error is a warning, only. Continue.
 		}
 
+		livePodSpec := actualDeployment.Spec.Template.Spec.DeepCopy()
+
 		actualDeployment.Spec.Strategy = desiredDeployment.Spec.Strategy
+		// The replicas are only managed when HA is enabled: once it is disabled (and the pod anti-affinity of HA is removed), the Deployment is scaled back to a single replica
+		if desiredDeployment.Spec.Replicas != nil {
+			actualDeployment.Spec.Replicas = desiredDeployment.Spec.Replicas
+		} else if livePodSpec.Affinity != nil && livePodSpec.Affinity.PodAntiAffinity != nil {
+			replicas := int32(1)
+			actualDeployment.Spec.Replicas = &replicas
+		}
 		actualDeployment.Spec.Template.Spec.Containers = desiredDeployment.Spec.Template.Spec.Containers
 		actualDeployment.Spec.Template.Spec.ServiceAccountName = desiredDeployment.Spec.Template.Spec.ServiceAccountName
 
@@ -167,15 +251,44 @@ func (r *RolloutManagerReconciler) reconcileRolloutsDeployment(ctx context.Conte
 		actualDeployment.Spec.Selector = desiredDeployment.Spec.Selector
 		actualDeployment.Spec.Template.Spec.NodeSelector = desiredDeployment.Spec.Template.Spec.NodeSelector
 		actualDeployment.Spec.Template.Spec.Tolerations = desiredDeployment.Spec.Template.Spec.Tolerations
+		actualDeployment.Spec.Template.Spec.Affinity = desiredDeployment.Spec.Template.Spec.Affinity
 		actualDeployment.Spec.Template.Spec.SecurityContext = desiredDeployment.Spec.Template.Spec.SecurityContext
 		actualDeployment.Spec.Template.Spec.Volumes = desiredDeployment.Spec.Template.Spec.Volumes
+		actualDeployment.Spec.Template.Spec.HostNetwork = desiredDeployment.Spec.Template.Spec.HostNetwork
+		actualDeployment.Spec.Template.Spec.DNSPolicy = desiredDeployment.Spec.Template.Spec.DNSPolicy
+
+		// Don't revert the fields that were injected by admission webhooks
+		preserveInjectedFields(cr, *livePodSpec, &actualDeployment.Spec.Template.Spec)
+
+		// The Deployment was last updated by the operator to the expected state, so the difference was introduced outside of the operator
+		drifted := specHashMatches(actualDeployment.ObjectMeta, specHash)
+
+		setSpecHashAnnotation(&actualDeployment.ObjectMeta, specHash)
+		if err := r.Client.Update(ctx, actualDeployment); err != nil {
+			return err
+		}
+		if drifted {
+			r.recordDriftCorrection(cr, "Deployment")
+		}
+		return nil
+	}
+
+	_, restartPending := actualDeployment.Annotations[PendingControllerRestartAnnotation]
+
+	if !specHashMatches(actualDeployment.ObjectMeta, specHash) || restartPending {
+		// The Deployment is in the expected state, but it was last updated for a different expected state (for example, by a previous version of the operator), or an update that was deferred by the restart budget is no longer needed
+		delete(actualDeployment.Annotations, PendingControllerRestartAnnotation)
+		setSpecHashAnnotation(&actualDeployment.ObjectMeta, specHash)
 		return r.Client.Update(ctx, actualDeployment)
 	}
+
 	return nil
 }
 
-func (r *RolloutManagerReconciler) createNewRolloutsDeployment(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, desiredDeployment appsv1.Deployment) error {
-	if err := controllerutil.SetControllerReference(&cr, &desiredDeployment, r.Scheme); err != nil {
+func (r *RolloutManagerReconciler) createNewRolloutsDeployment(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, desiredDeployment appsv1.Deployment, specHash string) error {
+	setSpecHashAnnotation(&desiredDeployment.ObjectMeta, specHash)
+	setControllerRestartAnnotations(cr, &desiredDeployment.ObjectMeta)
+	if err := r.setControllerReference(&cr, &desiredDeployment); err != nil {
 		return err
 	}
 	log.Info(fmt.Sprintf("Creating Deployment %s", DefaultArgoRolloutsResourceName))
@@ -200,6 +313,10 @@ func identifyDeploymentDifference(x appsv1.Deployment, y appsv1.Deployment) stri
 		return ".Spec.Strategy"
 	}
 
+	if !reflect.DeepEqual(x.Spec.Replicas, y.Spec.Replicas) {
+		return ".Spec.Replicas"
+	}
+
 	if !reflect.DeepEqual(x.Labels, y.Labels) {
 		return "Labels"
 	}
@@ -228,6 +345,10 @@ func identifyDeploymentDifference(x appsv1.Deployment, y appsv1.Deployment) stri
 		return "Spec.Template.Spec.Tolerations"
 	}
 
+	if !reflect.DeepEqual(x.Spec.Template.Spec.Affinity, y.Spec.Template.Spec.Affinity) {
+		return "Spec.Template.Spec.Affinity"
+	}
+
 	if !reflect.DeepEqual(xPodSpec.SecurityContext, yPodSpec.SecurityContext) {
 		return "Spec.Template.Spec.SecurityContext"
 	}
@@ -236,6 +357,14 @@ func identifyDeploymentDifference(x appsv1.Deployment, y appsv1.Deployment) stri
 		return "Spec.Template.Spec.Volumes"
 	}
 
+	if xPodSpec.HostNetwork != yPodSpec.HostNetwork {
+		return "Spec.Template.Spec.HostNetwork"
+	}
+
+	if xPodSpec.DNSPolicy != yPodSpec.DNSPolicy {
+		return "Spec.Template.Spec.DNSPolicy"
+	}
+
 	return ""
 }
 
@@ -266,9 +395,11 @@ func rolloutsContainer(cr rolloutsmanagerv1alpha1.RolloutManager) corev1.Contain
 
 	return corev1.Container{
 		Args:            getRolloutsCommandArgs(cr),
+		Command:         getRolloutsCommand(cr),
 		Env:             rolloutsEnv,
 		Image:           getRolloutsContainerImage(cr),
 		ImagePullPolicy: corev1.PullAlways,
+		Lifecycle:       rolloutsContainerLifecycle(cr),
 		LivenessProbe: &corev1.Probe{
 			FailureThreshold: 3,
 			ProbeHandler: corev1.ProbeHandler{
@@ -282,17 +413,17 @@ func rolloutsContainer(cr rolloutsmanagerv1alpha1.RolloutManager) corev1.Contain
 			SuccessThreshold:    int32(1),
 			TimeoutSeconds:      int32(10),
 		},
-		Name: "argo-rollouts",
-		Ports: []corev1.ContainerPort{
+		Name: getRolloutsContainerName(cr),
+		Ports: append([]corev1.ContainerPort{
 			{
-				ContainerPort: 8080,
+				ContainerPort: getRolloutsHealthzPort(cr),
 				Name:          "healthz",
 			},
 			{
-				ContainerPort: 8090,
+				ContainerPort: getRolloutsMetricsPort(cr),
 				Name:          "metrics",
 			},
-		},
+		}, getRolloutsExtraContainerPorts(cr)...),
 		ReadinessProbe: &corev1.Probe{
 			FailureThreshold: int32(5),
 			ProbeHandler: corev1.ProbeHandler{
@@ -319,17 +450,11 @@ func rolloutsContainer(cr rolloutsmanagerv1alpha1.RolloutManager) corev1.Contain
 				Type: corev1.SeccompProfileTypeRuntimeDefault,
 			},
 		},
-		VolumeMounts: []corev1.VolumeMount{
-			{
-				MountPath: "/home/argo-rollouts/plugin-bin",
-				Name:      "plugin-bin",
-			},
-			{
-				MountPath: "/tmp",
-				Name:      "tmp",
-			},
-		},
-		Resources: *containerResources,
+		StartupProbe: rolloutsContainerStartupProbe(cr),
+		// The last lines of the logs are used as the termination message when the controller crashes, so that they can be reported on the RolloutManager (see detectControllerCrashes)
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		VolumeMounts:             append(defaultVolumeMounts(), fileMountVolumeMounts(cr)...),
+		Resources:                *containerResources,
 	}
 
 }
@@ -354,8 +479,8 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        input.ObjectMeta.Name,
 			Namespace:   input.ObjectMeta.Namespace,
-			Labels:      input.ObjectMeta.Labels,
-			Annotations: input.ObjectMeta.Annotations,
+			Labels:      normalizeMap(input.ObjectMeta.Labels),
+			Annotations: normalizeMap(input.ObjectMeta.Annotations),
 		},
 	}
 
@@ -378,16 +503,28 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 		return appsv1.Deployment{}, fmt.Errorf("missing .spec.selector")
 	}
 
+	// Containers/volumes that were injected by admission webhooks (for example, by a service mesh) are not managed by the operator, so they are excluded from the normalized form.
+	input.Spec.Template.Spec.Containers, _ = splitInjectedContainers(cr, input.Spec.Template.Spec.Containers)
+	input.Spec.Template.Spec.Volumes, _ = splitInjectedVolumes(cr, input.Spec.Template.Spec.Volumes)
+	for idx := range input.Spec.Template.Spec.Containers {
+		input.Spec.Template.Spec.Containers[idx].VolumeMounts, _ = splitInjectedVolumeMounts(cr, input.Spec.Template.Spec.Containers[idx].VolumeMounts)
+	}
+
 	inputSpecSecurityContext := input.Spec.Template.Spec.SecurityContext
 
 	if inputSpecSecurityContext == nil {
 		return appsv1.Deployment{}, fmt.Errorf("missing .spec.template.spec.securityContext")
 	}
 
+	// The 2 default volumes, followed by the volumes of the FileMounts
 	inputSpecVolumes := input.Spec.Template.Spec.Volumes
-	if inputSpecVolumes == nil || len(inputSpecVolumes) != 2 {
+	if inputSpecVolumes == nil || len(inputSpecVolumes) != 2+len(cr.Spec.FileMounts) {
 		return appsv1.Deployment{}, fmt.Errorf("missing .spec.template.spec.volumes")
 	}
+	normalizedVolumes := []corev1.Volume{inputSpecVolumes[0], inputSpecVolumes[1]}
+	for _, volume := range inputSpecVolumes[2:] {
+		normalizedVolumes = append(normalizedVolumes, normalizeFileMountVolume(volume))
+	}
 
 	res.Spec = appsv1.DeploymentSpec{
 		Selector: &metav1.LabelSelector{
@@ -401,11 +538,14 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 			Spec: corev1.PodSpec{
 				NodeSelector:       input.Spec.Template.Spec.NodeSelector,
 				Tolerations:        input.Spec.Template.Spec.Tolerations,
+				Affinity:           input.Spec.Template.Spec.Affinity,
 				ServiceAccountName: input.Spec.Template.Spec.ServiceAccountName,
+				HostNetwork:        input.Spec.Template.Spec.HostNetwork,
 				SecurityContext: &corev1.PodSecurityContext{
-					RunAsNonRoot: input.Spec.Template.Spec.SecurityContext.RunAsNonRoot,
+					RunAsNonRoot:   input.Spec.Template.Spec.SecurityContext.RunAsNonRoot,
+					SeccompProfile: input.Spec.Template.Spec.SecurityContext.SeccompProfile,
 				},
-				Volumes: []corev1.Volume{inputSpecVolumes[0], inputSpecVolumes[1]},
+				Volumes: normalizedVolumes,
 			},
 		},
 		Strategy: appsv1.DeploymentStrategy{
@@ -414,6 +554,16 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 		},
 	}
 
+	// The replicas are only managed when HA is enabled
+	if isHAEnabled(cr) {
+		res.Spec.Replicas = input.Spec.Replicas
+	}
+
+	// The DNS policy defaults to ClusterFirst
+	if input.Spec.Template.Spec.DNSPolicy != "" && input.Spec.Template.Spec.DNSPolicy != corev1.DNSClusterFirst {
+		res.Spec.Template.Spec.DNSPolicy = input.Spec.Template.Spec.DNSPolicy
+	}
+
 	if len(input.Spec.Template.Spec.Containers) != 1 {
 		return appsv1.Deployment{}, fmt.Errorf("incorrect number of .spec.template.spec.containers")
 	}
@@ -441,7 +591,7 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 		return appsv1.Deployment{}, fmt.Errorf("incorrect http get in readiness probe")
 	}
 
-	if inputPorts == nil || len(inputPorts) != 2 {
+	if inputPorts == nil || len(inputPorts) != 2+len(getRolloutsExtraPorts(cr)) {
 		return appsv1.Deployment{}, fmt.Errorf("incorrect input ports")
 	}
 
@@ -449,9 +599,27 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 		return appsv1.Deployment{}, fmt.Errorf("incorrect security context")
 	}
 
-	if inputVolumeMounts == nil || len(inputVolumeMounts) != 2 {
+	if inputVolumeMounts == nil || len(inputVolumeMounts) != 2+len(cr.Spec.FileMounts) {
 		return appsv1.Deployment{}, fmt.Errorf("incorrect volume mounts")
 	}
+	// The protocol of the extra ports is kept, as it is set explicitly by the operator (the host port defaulted by the API server, when HostNetwork is enabled, is ignored)
+	var normalizedExtraPorts []corev1.ContainerPort
+	for _, port := range inputPorts[2:] {
+		normalizedExtraPorts = append(normalizedExtraPorts, corev1.ContainerPort{
+			ContainerPort: port.ContainerPort,
+			Name:          port.Name,
+			Protocol:      port.Protocol,
+		})
+	}
+
+	var normalizedVolumeMounts []corev1.VolumeMount
+	for _, volumeMount := range inputVolumeMounts {
+		normalizedVolumeMounts = append(normalizedVolumeMounts, corev1.VolumeMount{
+			Name:      volumeMount.Name,
+			MountPath: volumeMount.MountPath,
+			ReadOnly:  volumeMount.ReadOnly,
+		})
+	}
 
 	// Nil string slices need to be converted to empty string slices, because  reflect.DeepEqual(nil, []string{}) is false, despite being functionally the same, here.
 	if len(inputContainer.Args) == 0 {
@@ -462,11 +630,18 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 		inputContainer.Env = make([]corev1.EnvVar, 0)
 	}
 
+	// An empty command is equivalent to no command (the entrypoint of the image is used)
+	if len(inputContainer.Command) == 0 {
+		inputContainer.Command = nil
+	}
+
 	res.Spec.Template.Spec.Containers = []corev1.Container{{
 		Args:            inputContainer.Args,
+		Command:         inputContainer.Command,
 		Env:             inputContainer.Env,
 		Image:           inputContainer.Image,
 		ImagePullPolicy: inputContainer.ImagePullPolicy,
+		Lifecycle:       normalizeLifecycle(inputContainer.Lifecycle),
 		LivenessProbe: &corev1.Probe{
 			FailureThreshold: inputLivenessProbe.FailureThreshold,
 			ProbeHandler: corev1.ProbeHandler{
@@ -481,7 +656,7 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 			TimeoutSeconds:      inputLivenessProbe.TimeoutSeconds,
 		},
 		Name: inputContainer.Name,
-		Ports: []corev1.ContainerPort{
+		Ports: append([]corev1.ContainerPort{
 			{
 				ContainerPort: inputPorts[0].ContainerPort,
 				Name:          inputPorts[0].Name,
@@ -490,7 +665,7 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 				ContainerPort: inputPorts[1].ContainerPort,
 				Name:          inputPorts[1].Name,
 			},
-		},
+		}, normalizedExtraPorts...),
 		ReadinessProbe: &corev1.Probe{
 			FailureThreshold: inputReadinessProbe.FailureThreshold,
 			ProbeHandler: corev1.ProbeHandler{
@@ -514,16 +689,9 @@ func normalizeDeployment(inputParam appsv1.Deployment, cr rolloutsmanagerv1alpha
 			RunAsNonRoot:             inputSecurityContext.RunAsNonRoot,
 			SeccompProfile:           inputSecurityContext.SeccompProfile,
 		},
-		VolumeMounts: []corev1.VolumeMount{
-			{
-				Name:      inputVolumeMounts[0].Name,
-				MountPath: inputVolumeMounts[0].MountPath,
-			},
-			{
-				Name:      inputVolumeMounts[1].Name,
-				MountPath: inputVolumeMounts[1].MountPath,
-			},
-		},
+		StartupProbe:             normalizeStartupProbe(inputContainer.StartupProbe),
+		TerminationMessagePolicy: inputContainer.TerminationMessagePolicy,
+		VolumeMounts:             normalizedVolumeMounts,
 	}}
 
 	return res, nil
@@ -552,7 +720,7 @@ func getRolloutsContainerImage(cr rolloutsmanagerv1alpha1.RolloutManager) string
 
 	// If spec is empty, use the defaults
 	if img == "" {
-		img = DefaultArgoRolloutsImage
+		img = applyImageRegistryMirror(DefaultArgoRolloutsImage)
 		defaultImg = true
 	}
 	if tag == "" {
@@ -560,21 +728,59 @@ func getRolloutsContainerImage(cr rolloutsmanagerv1alpha1.RolloutManager) string
 		defaultTag = true
 	}
 
-	// If an env var is specified then use that, but don't override the spec values (if they are present)
-	if e := os.Getenv(ArgoRolloutsImageEnvName); e != "" && (defaultTag && defaultImg) {
+	// If an env var (ARGO_ROLLOUTS_IMAGE or RELATED_IMAGE_ARGO_ROLLOUTS) is specified then use that, but don't override the spec values (if they are present)
+	if e := getRolloutsImageFromEnv(); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 	return combineImageTag(img, tag)
 }
 
-// getRolloutsCommand will return the command for the Rollouts controller component.
-func getRolloutsCommandArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+// getRolloutsCommand returns the command (entrypoint) of the Rollouts controller container, or nil if the entrypoint of the image should be used.
+func getRolloutsCommand(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	if len(cr.Spec.Command) == 0 {
+		return nil
+	}
+	return append([]string{}, cr.Spec.Command...)
+}
+
+// getOperatorCommandArgs returns the command arguments of the Rollouts controller which are added by the operator, from the fields of the RolloutManager.
+func getOperatorCommandArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
 	args := make([]string, 0)
 
-	if cr.Spec.NamespaceScoped {
-		args = append(args, "--namespaced")
+	args = append(args, getRolloutsScopeArgs(cr)...)
+	args = append(args, getRolloutsPortArgs(cr)...)
+	args = append(args, getRolloutsDebugArgs(cr)...)
+	args = append(args, getRolloutsKubeClientArgs(cr)...)
+	args = append(args, getRolloutsControllerTuningArgs(cr)...)
+	args = append(args, getRolloutsHAArgs(cr)...)
+
+	return args
+}
+
+// getRolloutsScopeArgs returns the arguments which restrict the Rollouts controller of a namespace-scoped RolloutManager to its namespace: .spec.scopeArgsOverride if it is specified, otherwise namespacedArg.
+func getRolloutsScopeArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+
+	if !cr.Spec.NamespaceScoped {
+		return nil
+	}
+
+	if len(cr.Spec.ScopeArgsOverride) > 0 {
+		return append(make([]string, 0), cr.Spec.ScopeArgsOverride...)
+	}
+
+	return []string{namespacedArg}
+}
+
+// getRolloutsCommandArgs will return the command arguments for the Rollouts controller component.
+// The arguments added by the operator always come first, followed by .spec.extraCommandArgs (unless .spec.argsOverrideMode is 'replace', in which case only .spec.extraCommandArgs are used).
+func getRolloutsCommandArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+
+	if cr.Spec.ArgsOverrideMode == rolloutsmanagerv1alpha1.ArgsOverrideModeReplace {
+		return append(make([]string, 0), cr.Spec.ExtraCommandArgs...)
 	}
 
+	args := getOperatorCommandArgs(cr)
+
 	extraArgs := cr.Spec.ExtraCommandArgs
 	err := isMergable(extraArgs, args)
 	if err != nil {
@@ -298,7 +298,11 @@ var _ = Describe("Deployment Test", func() {
 
 			res := reflect.DeepEqual(xRes, yRes)
 
-			// Sanity test that identifyDeploymentDifference gives the same result as reflect.DeepEqual
+			// Sanity test that identifyDeploymentDifference gives the same result as reflect.DeepEqual. The ReplacedUIDAnnotation, set when the Deployment is recreated by the operator, is not part of the expected state.
+			if _, exists := x.Annotations[ReplacedUIDAnnotation]; exists {
+				x.Annotations = combineStringMaps(x.Annotations)
+				delete(x.Annotations, ReplacedUIDAnnotation)
+			}
 			deploymentDiff := identifyDeploymentDifference(x, y)
 			ExpectWithOffset(0, res == (deploymentDiff == "")).To(BeTrue())
 
@@ -368,6 +372,9 @@ var _ = Describe("Deployment Test", func() {
 			Entry(".spec.template.spec.containers.args", func(deployment *appsv1.Deployment) {
 				deployment.Spec.Template.Spec.Containers[0].Args = []string{"new", "args"}
 			}),
+			Entry(".spec.template.spec.containers.command", func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].Command = []string{"/bin/sh"}
+			}),
 			Entry(".spec.template.spec.containers.env", func(deployment *appsv1.Deployment) {
 				deployment.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
 					{Name: "my-env", Value: "my-env-value"}}
@@ -491,6 +498,14 @@ var _ = Describe("generateDesiredRolloutsDeployment tests", func() {
 			Expect(deployment.Spec.Template.Spec.Tolerations).To(BeNil())
 		})
 
+		It("should allow the default OS node selector to be overridden by NodePlacement", func() {
+			cr.Spec.NodePlacement = &v1alpha1.RolloutsNodePlacementSpec{
+				NodeSelector: map[string]string{"kubernetes.io/os": "custom-os"},
+			}
+			deployment := generateDesiredRolloutsDeployment(cr, sa)
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "custom-os"}))
+		})
+
 		It("should set the service account name", func() {
 			deployment := generateDesiredRolloutsDeployment(cr, sa)
 			Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(Equal(sa.ObjectMeta.Name))
@@ -659,6 +674,29 @@ var _ = Describe("getRolloutsContainerImage tests", func() {
 		})
 	})
 
+	When("the default image registry mirror is set", func() {
+
+		AfterEach(func() {
+			os.Unsetenv(DefaultImageRegistryMirrorEnvName)
+		})
+
+		It("returns the default image from the mirror, when the spec Image is empty", func() {
+			os.Setenv(DefaultImageRegistryMirrorEnvName, "mirror.example.com")
+			a.Spec.Version = "custom-tag"
+			Expect(getRolloutsContainerImage(a)).To(Equal("mirror.example.com/argoproj/argo-rollouts:custom-tag"))
+		})
+
+		It("does not modify the spec Image, or the image of the environment variable", func() {
+			os.Setenv(DefaultImageRegistryMirrorEnvName, "mirror.example.com")
+			a.Spec.Image = "quay.io/custom/image"
+			Expect(getRolloutsContainerImage(a)).To(Equal("quay.io/custom/image:" + DefaultArgoRolloutsVersion))
+
+			a.Spec.Image = ""
+			os.Setenv("ARGO_ROLLOUTS_IMAGE", "quay.io/custom/env-image")
+			Expect(getRolloutsContainerImage(a)).To(Equal("quay.io/custom/env-image"))
+		})
+	})
+
 	When("the environment variable is set but spec is not empty", func() {
 		It("returns the custom image and tag ignoring the environment variable", func() {
 			a.Spec.Image = "custom-image"
@@ -736,9 +774,9 @@ func deploymentCR(name string, namespace string, rolloutsSelectorLabel string, v
 		},
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
-				Labels: map[string]string{
+				Labels: combineStringMaps(getRecommendedLabels(rolloutManager), map[string]string{
 					DefaultRolloutsSelectorKey: rolloutsSelectorLabel,
-				},
+				}),
 				Annotations: make(map[string]string, 0),
 			},
 			Spec: corev1.PodSpec{
@@ -773,3 +811,53 @@ func deploymentCR(name string, namespace string, rolloutsSelectorLabel string, v
 	return deploymentCR
 
 }
+
+var _ = Describe("getRolloutsCommandArgs and getRolloutsCommand tests", func() {
+	var a v1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		a = *makeTestRolloutManager()
+	})
+
+	It("should place the arguments added by the operator before the extra command arguments, in the order they were specified", func() {
+		a.Spec.NamespaceScoped = true
+		a.Spec.ExtraCommandArgs = []string{"--loglevel", "debug", "--logformat=json"}
+
+		Expect(getRolloutsCommandArgs(a)).To(Equal([]string{"--namespaced", "--loglevel", "debug", "--logformat=json"}))
+	})
+
+	It("should replace the --namespaced argument by the scope arguments override, as the first arguments", func() {
+		a.Spec.NamespaceScoped = true
+		a.Spec.ScopeArgsOverride = []string{"--namespace", a.Namespace}
+		a.Spec.KubeClient = &v1alpha1.RolloutManagerKubeClientSpec{QPS: 100}
+		a.Spec.ExtraCommandArgs = []string{"--loglevel", "debug"}
+
+		Expect(getRolloutsCommandArgs(a)).To(Equal([]string{"--namespace", a.Namespace, "--qps=100", "--loglevel", "debug"}))
+
+		By("verifying that the override is not used by a cluster-scoped RolloutManager")
+		a.Spec.NamespaceScoped = false
+		Expect(getRolloutsCommandArgs(a)).To(Equal([]string{"--qps=100", "--loglevel", "debug"}))
+	})
+
+	It("should ignore the extra command arguments if one of them is already added by the operator", func() {
+		a.Spec.NamespaceScoped = true
+		a.Spec.ExtraCommandArgs = []string{"--namespaced", "--loglevel", "debug"}
+
+		Expect(getRolloutsCommandArgs(a)).To(Equal([]string{"--namespaced"}))
+	})
+
+	It("should only use the extra command arguments if the args override mode is 'replace'", func() {
+		a.Spec.NamespaceScoped = true
+		a.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+		a.Spec.ExtraCommandArgs = []string{"--loglevel", "debug"}
+
+		Expect(getRolloutsCommandArgs(a)).To(Equal([]string{"--loglevel", "debug"}))
+	})
+
+	It("should use the entrypoint of the image if no command is specified, and the specified command otherwise", func() {
+		Expect(rolloutsContainer(a).Command).To(BeNil())
+
+		a.Spec.Command = []string{"/bin/rollouts-controller", "--some-flag"}
+		Expect(rolloutsContainer(a).Command).To(Equal([]string{"/bin/rollouts-controller", "--some-flag"}))
+	})
+})
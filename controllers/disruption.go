@@ -0,0 +1,135 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+const (
+	// EventReasonControllerPodDisrupted is the reason of the Warning Events recorded on a RolloutManager when a Rollouts controller pod is deleted or evicted outside of an update of the Rollouts controller Deployment.
+	EventReasonControllerPodDisrupted = "ControllerPodDisrupted"
+
+	// deploymentRevisionAnnotation is set by the Deployment controller on Deployments and their ReplicaSets: the ReplicaSet with the same revision as its Deployment is the current one.
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+	// podEvictedReason is the reason of pods that were evicted by the kubelet (for example, on node pressure).
+	podEvictedReason = "Evicted"
+)
+
+func isDisruptionAlertsEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.DisruptionAlerts != nil && cr.Spec.DisruptionAlerts.Enabled
+}
+
+// isRolloutsControllerPod returns true if the object is a pod of a Rollouts controller Deployment.
+func isRolloutsControllerPod(obj metav1.Object) bool {
+	return obj.GetLabels()[DefaultRolloutsSelectorKey] == DefaultArgoRolloutsResourceName
+}
+
+// controllerPodTerminated returns true if the pod started terminating (it is being deleted, or it was evicted by the kubelet) in the update from oldPod to newPod.
+func controllerPodTerminated(oldPod *corev1.Pod, newPod *corev1.Pod) bool {
+	deleted := oldPod.DeletionTimestamp == nil && newPod.DeletionTimestamp != nil
+	evicted := oldPod.Status.Reason != podEvictedReason && newPod.Status.Reason == podEvictedReason
+	return deleted || evicted
+}
+
+// controllerPodDisruptionHandler returns an event handler for Rollouts controller pods, which records a disruption Event (see recordControllerPodDisruption) when a pod starts terminating.
+// The handler never enqueues RolloutManagers: the changes to the Rollouts controller Deployment that follow are already watched.
+func (r *RolloutManagerReconciler) controllerPodDisruptionHandler() handler.EventHandler {
+	return handler.Funcs{
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, _ workqueue.RateLimitingInterface) {
+			oldPod, oldOK := e.ObjectOld.(*corev1.Pod)
+			newPod, newOK := e.ObjectNew.(*corev1.Pod)
+			if oldOK && newOK && isRolloutsControllerPod(newPod) && controllerPodTerminated(oldPod, newPod) {
+				r.recordControllerPodDisruption(ctx, newPod)
+			}
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+			// Pods which were terminating were already handled by UpdateFunc: this handles pods that were force-deleted
+			pod, ok := e.Object.(*corev1.Pod)
+			if ok && isRolloutsControllerPod(pod) && pod.DeletionTimestamp == nil && pod.Status.Reason != podEvictedReason {
+				r.recordControllerPodDisruption(ctx, pod)
+			}
+		},
+	}
+}
+
+// recordControllerPodDisruption records a Warning Event on the RolloutManager of a terminating Rollouts controller pod, if DisruptionAlerts are enabled, and the pod is not being replaced as part of an update of the Rollouts controller Deployment (or a scale down).
+// This allows distinguishing disruptions (such as node drains, evictions, or manual deletions) from the actions of the operator.
+func (r *RolloutManagerReconciler) recordControllerPodDisruption(ctx context.Context, pod *corev1.Pod) {
+
+	if r.Recorder == nil {
+		return
+	}
+
+	replicaSetRef := metav1.GetControllerOf(pod)
+	if replicaSetRef == nil || replicaSetRef.Kind != "ReplicaSet" {
+		return
+	}
+	var replicaSet appsv1.ReplicaSet
+	if err := fetchObject(ctx, r.Client, pod.Namespace, replicaSetRef.Name, &replicaSet); err != nil {
+		// If the ReplicaSet no longer exists, the pod is deleted along with it
+		return
+	}
+
+	deploymentRef := metav1.GetControllerOf(&replicaSet)
+	if deploymentRef == nil || deploymentRef.Kind != "Deployment" {
+		return
+	}
+	var deployment appsv1.Deployment
+	if err := fetchObject(ctx, r.Client, pod.Namespace, deploymentRef.Name, &deployment); err != nil || deployment.DeletionTimestamp != nil {
+		return
+	}
+
+	rolloutManagerRef := metav1.GetControllerOf(&deployment)
+	if rolloutManagerRef == nil || rolloutManagerRef.Kind != "RolloutManager" {
+		return
+	}
+	var rolloutManager rolloutsmanagerv1alpha1.RolloutManager
+	if err := fetchObject(ctx, r.Client, pod.Namespace, rolloutManagerRef.Name, &rolloutManager); err != nil || !isDisruptionAlertsEnabled(rolloutManager) {
+		return
+	}
+
+	// Pods of a previous ReplicaSet are replaced as part of an update of the Deployment
+	if replicaSet.Annotations[deploymentRevisionAnnotation] != deployment.Annotations[deploymentRevisionAnnotation] {
+		return
+	}
+
+	// Pods of the current ReplicaSet are deleted when it is scaled down
+	if replicaSet.Spec.Replicas != nil && *replicaSet.Spec.Replicas < replicaSet.Status.Replicas {
+		return
+	}
+
+	message := fmt.Sprintf("Rollouts controller pod %s on node %s was terminated outside of an update of the Rollouts controller Deployment: %s", pod.Name, pod.Spec.NodeName, podDisruptionCause(pod))
+	log.Info(message, "namespace", pod.Namespace)
+	r.Recorder.Event(&rolloutManager, corev1.EventTypeWarning, EventReasonControllerPodDisrupted, message)
+}
+
+// podDisruptionCause returns a description of why the pod is terminating, based on its DisruptionTarget condition (set on evictions, preemptions and taint-based deletions) or its status.
+func podDisruptionCause(pod *corev1.Pod) string {
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.DisruptionTarget && condition.Status == corev1.ConditionTrue {
+			if condition.Message != "" {
+				return fmt.Sprintf("%s (%s)", condition.Reason, condition.Message)
+			}
+			return condition.Reason
+		}
+	}
+
+	if pod.Status.Reason != "" {
+		if pod.Status.Message != "" {
+			return fmt.Sprintf("%s (%s)", pod.Status.Reason, pod.Status.Message)
+		}
+		return pod.Status.Reason
+	}
+
+	return "deleted"
+}
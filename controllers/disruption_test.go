@@ -0,0 +1,156 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Disruption alert tests", func() {
+
+	var (
+		ctx        context.Context
+		cr         rolloutsmanagerv1alpha1.RolloutManager
+		r          *RolloutManagerReconciler
+		recorder   *namespacedEventRecorder
+		deployment *appsv1.Deployment
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		cr.Spec.DisruptionAlerts = &rolloutsmanagerv1alpha1.RolloutManagerDisruptionAlertsSpec{
+			Enabled: true,
+		}
+
+		r = makeTestReconciler(&cr)
+		recorder = &namespacedEventRecorder{}
+		r.Recorder = recorder
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		By("simulating the Deployment controller, which sets the revision of the Deployment")
+		deployment = &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		deployment.Annotations[deploymentRevisionAnnotation] = "2"
+		Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+	})
+
+	// createControllerPod creates a ReplicaSet of the Rollouts controller Deployment with the given revision, and returns a pod of that ReplicaSet
+	createControllerPod := func(revision string) *corev1.Pod {
+		replicas := int32(1)
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        DefaultArgoRolloutsResourceName + "-" + revision,
+				Namespace:   cr.Namespace,
+				Labels:      map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName},
+				Annotations: map[string]string{deploymentRevisionAnnotation: revision},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+				},
+			},
+			Spec:   appsv1.ReplicaSetSpec{Replicas: &replicas},
+			Status: appsv1.ReplicaSetStatus{Replicas: replicas},
+		}
+		ExpectWithOffset(1, r.Client.Create(ctx, replicaSet)).To(Succeed())
+
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      replicaSet.Name + "-abcde",
+				Namespace: cr.Namespace,
+				Labels:    map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(replicaSet, appsv1.SchemeGroupVersion.WithKind("ReplicaSet")),
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+		}
+	}
+
+	It("should record a Warning Event when a pod of the current ReplicaSet is evicted", func() {
+		pod := createControllerPod("2")
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:   corev1.DisruptionTarget,
+			Status: corev1.ConditionTrue,
+			Reason: "EvictionByEvictionAPI",
+		}}
+
+		r.recordControllerPodDisruption(ctx, pod)
+		Expect(recorder.events).To(Equal([]string{cr.Namespace + "/*v1alpha1.RolloutManager " + EventReasonControllerPodDisrupted}))
+		Expect(podDisruptionCause(pod)).To(Equal("EvictionByEvictionAPI"))
+	})
+
+	It("should not record an Event when a pod of a previous ReplicaSet is replaced by an update of the Deployment", func() {
+		r.recordControllerPodDisruption(ctx, createControllerPod("1"))
+		Expect(recorder.events).To(BeEmpty())
+	})
+
+	It("should not record an Event when the current ReplicaSet is scaled down", func() {
+		pod := createControllerPod("2")
+
+		replicaSet := &appsv1.ReplicaSet{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName+"-2", replicaSet)).To(Succeed())
+		replicas := int32(0)
+		replicaSet.Spec.Replicas = &replicas
+		Expect(r.Client.Update(ctx, replicaSet)).To(Succeed())
+
+		r.recordControllerPodDisruption(ctx, pod)
+		Expect(recorder.events).To(BeEmpty())
+	})
+
+	It("should not record an Event when disruption alerts are disabled", func() {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		cr.Spec.DisruptionAlerts.Enabled = false
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+		r.recordControllerPodDisruption(ctx, createControllerPod("2"))
+		Expect(recorder.events).To(BeEmpty())
+	})
+
+	It("controllerPodTerminated should detect pods that start being deleted or are evicted", func() {
+		pod := &corev1.Pod{}
+
+		deletedPod := pod.DeepCopy()
+		deletedPod.DeletionTimestamp = &metav1.Time{}
+		Expect(controllerPodTerminated(pod, deletedPod)).To(BeTrue())
+		Expect(controllerPodTerminated(deletedPod, deletedPod)).To(BeFalse())
+
+		evictedPod := pod.DeepCopy()
+		evictedPod.Status.Reason = podEvictedReason
+		Expect(controllerPodTerminated(pod, evictedPod)).To(BeTrue())
+		Expect(controllerPodTerminated(evictedPod, evictedPod)).To(BeFalse())
+
+		Expect(controllerPodTerminated(pod, pod)).To(BeFalse())
+	})
+
+	It("should register the preStop command as a lifecycle hook of the Rollouts controller container", func() {
+		Expect(rolloutsContainerLifecycle(cr)).To(BeNil())
+
+		cr.Spec.DisruptionAlerts.PreStopCommand = []string{"/bin/sh", "-c", "echo terminating"}
+		desired := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(desired.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal(cr.Spec.DisruptionAlerts.PreStopCommand))
+
+		By("verifying that the normalized form ignores fields defaulted by the API server")
+		normalizedDesired, err := normalizeDeployment(desired, cr)
+		Expect(err).ToNot(HaveOccurred())
+
+		desired.Spec.Template.Spec.Containers[0].Lifecycle.PostStart = &corev1.LifecycleHandler{}
+		normalizedLive, err := normalizeDeployment(desired, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalizedLive).To(Equal(normalizedDesired))
+	})
+})
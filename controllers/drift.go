@@ -0,0 +1,272 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// DriftActionCreate, DriftActionUpdate and DriftActionDelete are the actions that the operator would take on a resource, as reported in a ResourceDrift.
+	DriftActionCreate = "create"
+	DriftActionUpdate = "update"
+	DriftActionDelete = "delete"
+)
+
+// ResourceDrift is a resource of a RolloutManager which differs from its desired state, and the action that the operator would take to correct it.
+type ResourceDrift struct {
+	Action    string
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Fields are the fields of the live resource which differ from the desired state. Only set for the 'update' action.
+	Fields []FieldDrift
+}
+
+// FieldDrift is a field of a live resource which differs from the desired state. Live and Desired are JSON values, or '<unset>'.
+type FieldDrift struct {
+	Path    string
+	Live    string
+	Desired string
+}
+
+// String returns a human-readable description of the drift of the resource, with one field per line.
+func (d ResourceDrift) String() string {
+	var sb strings.Builder
+
+	name := d.Name
+	if d.Namespace != "" {
+		name = d.Namespace + "/" + d.Name
+	}
+	fmt.Fprintf(&sb, "%s %s %s", d.Action, d.Kind, name)
+
+	for _, field := range d.Fields {
+		fmt.Fprintf(&sb, "\n  %s: %s -> %s", field.Path, field.Live, field.Desired)
+	}
+
+	return sb.String()
+}
+
+// ignoredDriftPaths are the fields which are managed by the API server (or by other controllers), and are never corrected by the operator.
+var ignoredDriftPaths = map[string]bool{
+	"apiVersion":                 true,
+	"kind":                       true,
+	"metadata.creationTimestamp": true,
+	"metadata.generation":        true,
+	"metadata.managedFields":     true,
+	"metadata.resourceVersion":   true,
+	"metadata.uid":               true,
+	"status":                     true,
+}
+
+// DetectDrift runs a reconciliation of the RolloutManager without modifying the cluster, and returns the resources that the operator would create, update or delete, along with the fields of the live resources which differ from the desired state.
+// This answers the question 'why does the operator keep updating my resource?': for example, a mutating admission webhook or another controller that modifies a field which is managed by the operator.
+// If the reconciliation fails, the drift that was detected until the failure is returned along with the error. Reconciliation steps that depend on a resource being created (for example, the ServiceMonitor of a new metrics Service) may fail, since it is not.
+func (r *RolloutManagerReconciler) DetectDrift(ctx context.Context, key types.NamespacedName) ([]ResourceDrift, error) {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+	if err := r.Client.Get(ctx, key, &cr); err != nil {
+		return nil, fmt.Errorf("failed to get RolloutManager %s: %w", key, err)
+	}
+
+	driftClient := &driftDetectionClient{Client: r.Client}
+
+	dryRunReconciler := *r
+	dryRunReconciler.Client = driftClient
+	dryRunReconciler.StatusClient = driftClient
+	dryRunReconciler.Recorder = nil
+	// The drift is reported even if the update of the Rollouts controller Deployment would be deferred
+	dryRunReconciler.ignoreRestartBudget = true
+	dryRunReconciler.dryRun = true
+
+	if _, err := dryRunReconciler.reconcileRolloutsManager(ctx, cr); err != nil {
+		return driftClient.drifts, err
+	}
+
+	return driftClient.drifts, nil
+}
+
+// driftDetectionClient is a client which reads from the cluster, but records the writes of the operator as ResourceDrifts, rather than performing them. See DetectDrift.
+type driftDetectionClient struct {
+	client.Client
+
+	drifts []ResourceDrift
+}
+
+func (c *driftDetectionClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.record(DriftActionCreate, obj, nil)
+	return nil
+}
+
+func (c *driftDetectionClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+
+	// The operator modifies the live object in place, so the live object is retrieved again to compare it
+	gvk, err := apiutil.GVKForObject(obj, c.Client.Scheme())
+	if err != nil {
+		return err
+	}
+	newObj, err := c.Client.Scheme().New(gvk)
+	if err != nil {
+		return err
+	}
+	live, ok := newObj.(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", newObj)
+	}
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		return err
+	}
+
+	fields, err := diffObjects(live, obj)
+	if err != nil {
+		return err
+	}
+
+	c.record(DriftActionUpdate, obj, fields)
+	return nil
+}
+
+func (c *driftDetectionClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.record(DriftActionUpdate, obj, nil)
+	return nil
+}
+
+func (c *driftDetectionClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.record(DriftActionDelete, obj, nil)
+	return nil
+}
+
+func (c *driftDetectionClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.record(DriftActionDelete, obj, nil)
+	return nil
+}
+
+// Status returns a writer which ignores writes: the status of resources is not part of their desired state.
+func (c *driftDetectionClient) Status() client.SubResourceWriter {
+	return noopSubResourceWriter{}
+}
+
+func (c *driftDetectionClient) record(action string, obj client.Object, fields []FieldDrift) {
+
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := apiutil.GVKForObject(obj, c.Client.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+
+	c.drifts = append(c.drifts, ResourceDrift{
+		Action:    action,
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Fields:    fields,
+	})
+}
+
+// noopSubResourceWriter is a client.SubResourceWriter which ignores writes.
+type noopSubResourceWriter struct{}
+
+func (noopSubResourceWriter) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return nil
+}
+
+func (noopSubResourceWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return nil
+}
+
+func (noopSubResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return nil
+}
+
+// diffObjects returns the fields of the live object which differ from the desired object, sorted by path.
+func diffObjects(live client.Object, desired client.Object) ([]FieldDrift, error) {
+
+	liveMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return nil, err
+	}
+	desiredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []FieldDrift{}
+	diffValues("", liveMap, desiredMap, &res)
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+
+	return res, nil
+}
+
+// diffValues compares the live and desired values at the given path, recursing into maps, and into lists of the same length.
+func diffValues(path string, live interface{}, desired interface{}, res *[]FieldDrift) {
+
+	if ignoredDriftPaths[path] {
+		return
+	}
+
+	switch liveValue := live.(type) {
+	case map[string]interface{}:
+		if desiredValue, ok := desired.(map[string]interface{}); ok {
+			keys := map[string]bool{}
+			for key := range liveValue {
+				keys[key] = true
+			}
+			for key := range desiredValue {
+				keys[key] = true
+			}
+			for key := range keys {
+				diffValues(fieldPath(path, key), liveValue[key], desiredValue[key], res)
+			}
+			return
+		}
+	case []interface{}:
+		if desiredValue, ok := desired.([]interface{}); ok && len(liveValue) == len(desiredValue) {
+			for i := range liveValue {
+				diffValues(fmt.Sprintf("%s[%d]", path, i), liveValue[i], desiredValue[i], res)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(live, desired) {
+		*res = append(*res, FieldDrift{
+			Path:    path,
+			Live:    formatDriftValue(live),
+			Desired: formatDriftValue(desired),
+		})
+	}
+}
+
+// fieldPath appends the key to the path: keys which contain dots or slashes (such as labels and annotations) are quoted.
+func fieldPath(path string, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func formatDriftValue(value interface{}) string {
+	if value == nil {
+		return "<unset>"
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
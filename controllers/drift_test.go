@@ -0,0 +1,130 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Drift detection tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+		key types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		key = types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}
+	})
+
+	It("should report the resources that would be created, without creating them", func() {
+		drifts, err := r.DetectDrift(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drifts).To(ContainElement(ResourceDrift{Action: DriftActionCreate, Kind: "Deployment", Namespace: cr.Namespace, Name: DefaultArgoRolloutsResourceName}))
+		Expect(drifts).To(ContainElement(ResourceDrift{Action: DriftActionCreate, Kind: "ServiceAccount", Namespace: cr.Namespace, Name: DefaultArgoRolloutsResourceName}))
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).ToNot(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &corev1.ServiceAccount{})).ToNot(Succeed())
+	})
+
+	It("should report no drift after a reconciliation", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		drifts, err := r.DetectDrift(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drifts).To(BeEmpty())
+	})
+
+	It("should report the fields of live resources which differ from the desired state, without correcting them", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		deployment.Spec.Template.Spec.Containers[0].Image = "quay.io/example/argo-rollouts:modified"
+		Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+		sa := &corev1.ServiceAccount{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+		sa.Labels[DefaultRolloutsSelectorKey] = "modified"
+		Expect(r.Client.Update(ctx, sa)).To(Succeed())
+
+		drifts, err := r.DetectDrift(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		var deploymentDrift, saDrift *ResourceDrift
+		for i := range drifts {
+			switch drifts[i].Kind {
+			case "Deployment":
+				deploymentDrift = &drifts[i]
+			case "ServiceAccount":
+				saDrift = &drifts[i]
+			}
+		}
+
+		Expect(deploymentDrift).ToNot(BeNil())
+		Expect(deploymentDrift.Action).To(Equal(DriftActionUpdate))
+		Expect(deploymentDrift.Fields).To(ContainElement(FieldDrift{
+			Path:    "spec.template.spec.containers[0].image",
+			Live:    `"quay.io/example/argo-rollouts:modified"`,
+			Desired: `"` + getRolloutsContainerImage(cr) + `"`,
+		}))
+
+		Expect(saDrift).ToNot(BeNil())
+		Expect(saDrift.Fields).To(Equal([]FieldDrift{{
+			Path:    `metadata.labels["app.kubernetes.io/name"]`,
+			Live:    `"modified"`,
+			Desired: `"` + DefaultArgoRolloutsResourceName + `"`,
+		}}))
+
+		By("verifying that the live resources were not corrected")
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("quay.io/example/argo-rollouts:modified"))
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+		Expect(sa.Labels[DefaultRolloutsSelectorKey]).To(Equal("modified"))
+	})
+
+	It("should describe the drift of a resource with one field per line", func() {
+		drift := ResourceDrift{
+			Action:    DriftActionUpdate,
+			Kind:      "Service",
+			Namespace: "argo-rollouts",
+			Name:      DefaultArgoRolloutsMetricsServiceName,
+			Fields:    []FieldDrift{{Path: "spec.type", Live: `"NodePort"`, Desired: "<unset>"}},
+		}
+		Expect(drift.String()).To(Equal("update Service argo-rollouts/argo-rollouts-metrics\n  spec.type: \"NodePort\" -> <unset>"))
+	})
+
+	It("should return an error if the RolloutManager does not exist", func() {
+		_, err := r.DetectDrift(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: "does-not-exist"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("diffObjects should ignore fields managed by the API server", func() {
+		live := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", ResourceVersion: "2", Generation: 3}, Data: map[string]string{"k": "v"}}
+		desired := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Data: map[string]string{"k": "v", "added": "x"}}
+
+		fields, err := diffObjects(live, desired)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fields).To(Equal([]FieldDrift{{Path: "data.added", Live: "<unset>", Desired: `"x"`}}))
+	})
+})
@@ -0,0 +1,38 @@
+package rollouts
+
+import (
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventRecorderName is the source of the Events that are recorded by the operator.
+	EventRecorderName = "argo-rollouts-manager"
+
+	// EventReasonResourceCreated is the reason of Events recorded when the operator creates a resource.
+	EventReasonResourceCreated = "ResourceCreated"
+
+	// EventReasonResourceUpdated is the reason of Events recorded when the operator updates a resource.
+	EventReasonResourceUpdated = "ResourceUpdated"
+
+	// EventReasonResourceDeleted is the reason of Events recorded when the operator deletes a resource.
+	EventReasonResourceDeleted = "ResourceDeleted"
+)
+
+// recordTargetNamespaceEvent records an Event about an action of the operator on a resource in a namespace watched by the Rollouts controller.
+//
+// The Event is recorded on the RolloutManager and, if the resource is in a different namespace, also on the resource itself, so that the teams owning that namespace can see the actions of the operator that affect them, without access to the namespace of the RolloutManager.
+func (r *RolloutManagerReconciler) recordTargetNamespaceEvent(cr *rolloutsmanagerv1alpha1.RolloutManager, obj client.Object, reason string, message string) {
+	if r.Recorder == nil {
+		return
+	}
+
+	if cr != nil {
+		r.Recorder.Event(cr, corev1.EventTypeNormal, reason, message)
+	}
+
+	if cr == nil || obj.GetNamespace() != cr.Namespace {
+		r.Recorder.Event(obj, corev1.EventTypeNormal, reason, message)
+	}
+}
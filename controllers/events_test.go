@@ -0,0 +1,95 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// namespacedEventRecorder is an EventRecorder which records the namespace, kind and reason of each Event, as 'namespace/kind reason'.
+type namespacedEventRecorder struct {
+	events []string
+}
+
+var _ record.EventRecorder = &namespacedEventRecorder{}
+
+func (n *namespacedEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	obj := object.(client.Object)
+	n.events = append(n.events, fmt.Sprintf("%s/%T %s", obj.GetNamespace(), obj, reason))
+}
+
+func (n *namespacedEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	n.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (n *namespacedEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	n.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+var _ = Describe("Event tests", func() {
+	var ctx context.Context
+	var a v1alpha1.RolloutManager
+	var r *RolloutManagerReconciler
+	var recorder *namespacedEventRecorder
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		a = *makeTestRolloutManager()
+		a.Spec.RolloutUserRole = &v1alpha1.RolloutManagerRolloutUserRoleSpec{
+			Enabled: true,
+		}
+
+		r = makeTestReconciler(&a)
+		recorder = &namespacedEventRecorder{}
+		r.Recorder = recorder
+		Expect(createNamespace(r, a.Namespace)).To(Succeed())
+		Expect(createNamespace(r, "team-a")).To(Succeed())
+	})
+
+	It("should record Events about resources in watched namespaces both in those namespaces and in the namespace of the RolloutManager", func() {
+
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+		Expect(recorder.events).To(ConsistOf(
+			a.Namespace+"/*v1alpha1.RolloutManager "+EventReasonResourceCreated,
+			a.Namespace+"/*v1alpha1.RolloutManager "+EventReasonResourceCreated,
+			"team-a/*v1.Role "+EventReasonResourceCreated,
+		))
+
+		By("verifying that no Events are recorded if nothing changed")
+		recorder.events = nil
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+		Expect(recorder.events).To(BeEmpty())
+
+		By("modifying the Role in the watched namespace")
+		role := &rbacv1.Role{}
+		Expect(fetchObject(ctx, r.Client, "team-a", DefaultRolloutUserRoleName, role)).To(Succeed())
+		role.Rules = nil
+		Expect(r.Client.Update(ctx, role)).To(Succeed())
+
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+		Expect(recorder.events).To(ConsistOf(
+			a.Namespace+"/*v1alpha1.RolloutManager "+EventReasonResourceUpdated,
+			"team-a/*v1.Role "+EventReasonResourceUpdated,
+		))
+	})
+
+	It("should record Events in the watched namespaces when deleting resources of a RolloutManager that no longer exists", func() {
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+		recorder.events = nil
+
+		Expect(r.removeRolloutUserRoles(ctx, a.Namespace)).To(Succeed())
+		Expect(recorder.events).To(ConsistOf("team-a/*v1.Role " + EventReasonResourceDeleted))
+	})
+
+	It("should not record Events if no Recorder is set", func() {
+		r.Recorder = nil
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+	})
+})
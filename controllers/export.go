@@ -0,0 +1,176 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// KustomizationFileName is the name of the kustomization file of a bundle exported by ExportBundle.
+const KustomizationFileName = "kustomization.yaml"
+
+// exportedObjectFields are the fields which are removed from the exported resources: they are set by the API server, or only make sense for resources managed by the operator.
+var exportedObjectFields = [][]string{
+	{"metadata", "creationTimestamp"},
+	{"spec", "template", "metadata", "creationTimestamp"},
+	{"metadata", "ownerReferences"},
+	{"metadata", "annotations", SpecHashAnnotation},
+	{"status"},
+}
+
+// ExportBundle renders the resources of the RolloutManager, as the operator would create them, without reading or modifying the resources on the cluster, and returns them as a Kustomize-compatible bundle: the content of each file, by file name, including the kustomization file which lists the resources.
+// The bundle allows the resources to be managed by another tool (for example, a GitOps tool) rather than the operator, without reverse-engineering the live resources. Conversely, when the operator takes over the resources which were applied from the bundle, it adopts the namespaced ones, as they carry its labels (see adoptOrphanedResources).
+// Data which the operator only initializes (for example, the content of the notification Secret) is exported as it would be on creation. Owner references to the RolloutManager, and the SpecHashAnnotation, are not exported.
+func (r *RolloutManagerReconciler) ExportBundle(ctx context.Context, key types.NamespacedName) (map[string][]byte, error) {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+	if err := r.Client.Get(ctx, key, &cr); err != nil {
+		return nil, fmt.Errorf("failed to get RolloutManager %s: %w", key, err)
+	}
+
+	exportClient := &exportClient{Client: r.Client}
+
+	exportReconciler := *r
+	exportReconciler.Client = exportClient
+	exportReconciler.StatusClient = exportClient
+	exportReconciler.Recorder = nil
+	exportReconciler.LifecycleNotifier = nil
+	// The resources are rendered for the bundle, which is applied by another tool (with its own permissions)
+	exportReconciler.AccessReviewer = nil
+	exportReconciler.ignoreRestartBudget = true
+	exportReconciler.dryRun = true
+
+	rr, err := exportReconciler.reconcileRolloutsManager(ctx, cr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the resources of RolloutManager %s: %w", key, err)
+	}
+	if rr.condition.Reason != rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess {
+		return nil, fmt.Errorf("RolloutManager %s is invalid: %s", key, rr.condition.Message)
+	}
+
+	files := map[string][]byte{}
+	resources := []string{}
+	for _, obj := range exportClient.objects {
+		fileName := getExportedFileName(cr, obj)
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if _, exists := files[fileName]; !exists {
+			resources = append(resources, fileName)
+		}
+		files[fileName] = data
+	}
+
+	kustomization, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	})
+	if err != nil {
+		return nil, err
+	}
+	files[KustomizationFileName] = kustomization
+
+	return files, nil
+}
+
+// getExportedFileName returns the name of the file of an exported resource, such as 'deployment-argo-rollouts.yaml'. Resources in other namespaces than the namespace of the RolloutManager (for example, the rollout-user Roles) are prefixed with their namespace.
+func getExportedFileName(cr rolloutsmanagerv1alpha1.RolloutManager, obj *unstructured.Unstructured) string {
+	name := strings.ToLower(obj.GetKind()) + "-" + obj.GetName()
+	if obj.GetNamespace() != "" && obj.GetNamespace() != cr.Namespace {
+		name = obj.GetNamespace() + "-" + name
+	}
+	return name + ".yaml"
+}
+
+// exportedReadGVKs are the resources which are read from the cluster when the resources of a RolloutManager are exported: the RolloutManagers themselves, and the Namespaces and CRDs which determine the resources to render.
+var exportedReadGVKs = map[schema.GroupVersionKind]bool{
+	rolloutsmanagerv1alpha1.GroupVersion.WithKind("RolloutManager"):     true,
+	rolloutsmanagerv1alpha1.GroupVersion.WithKind("RolloutManagerList"): true,
+	namespaceGVK: true,
+	namespaceGVK.GroupVersion().WithKind("NamespaceList"): true,
+	customResourceDefinitionGVK:                           true,
+}
+
+// exportClient is a client for which the resources managed by the operator do not exist, so that the operator renders all of them as it would create them, and which records the created resources rather than creating them. See ExportBundle.
+type exportClient struct {
+	client.Client
+
+	objects []*unstructured.Unstructured
+}
+
+func (c *exportClient) isRead(obj runtime.Object) bool {
+	gvk, err := apiutil.GVKForObject(obj, c.Client.Scheme())
+	return err == nil && exportedReadGVKs[gvk]
+}
+
+func (c *exportClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if !c.isRead(obj) {
+		gvk, _ := apiutil.GVKForObject(obj, c.Client.Scheme())
+		return apierrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind)}, key.Name)
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *exportClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if !c.isRead(list) {
+		return nil
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *exportClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+
+	gvk, err := apiutil.GVKForObject(obj, c.Client.Scheme())
+	if err != nil {
+		return err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	exported := &unstructured.Unstructured{Object: content}
+	exported.SetGroupVersionKind(gvk)
+	for _, field := range exportedObjectFields {
+		unstructured.RemoveNestedField(exported.Object, field...)
+	}
+	if len(exported.GetAnnotations()) == 0 {
+		unstructured.RemoveNestedField(exported.Object, "metadata", "annotations")
+	}
+
+	c.objects = append(c.objects, exported)
+	return nil
+}
+
+func (c *exportClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+
+func (c *exportClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+
+func (c *exportClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return nil
+}
+
+func (c *exportClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+// Status returns a writer which ignores writes: the status of resources is not exported.
+func (c *exportClient) Status() client.SubResourceWriter {
+	return noopSubResourceWriter{}
+}
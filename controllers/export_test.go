@@ -0,0 +1,99 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Bundle export tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+		key types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		key = types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}
+	})
+
+	// getKustomizationResources returns the resources listed by the kustomization file of the bundle
+	getKustomizationResources := func(files map[string][]byte) []string {
+		kustomization := struct {
+			Kind      string   `json:"kind"`
+			Resources []string `json:"resources"`
+		}{}
+		Expect(yaml.Unmarshal(files[KustomizationFileName], &kustomization)).To(Succeed())
+		Expect(kustomization.Kind).To(Equal("Kustomization"))
+		return kustomization.Resources
+	}
+
+	It("should export the resources as the operator would create them, without creating them", func() {
+		files, err := r.ExportBundle(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		resources := getKustomizationResources(files)
+		Expect(resources).To(ContainElements(
+			"serviceaccount-argo-rollouts.yaml",
+			"clusterrole-argo-rollouts.yaml",
+			"clusterrolebinding-argo-rollouts.yaml",
+			"deployment-argo-rollouts.yaml",
+			"service-argo-rollouts-metrics.yaml",
+		))
+		Expect(files).To(HaveLen(len(resources) + 1))
+		for _, resource := range resources {
+			Expect(files).To(HaveKey(resource))
+		}
+
+		deployment := &appsv1.Deployment{}
+		Expect(yaml.Unmarshal(files["deployment-argo-rollouts.yaml"], deployment)).To(Succeed())
+		Expect(deployment.APIVersion).To(Equal("apps/v1"))
+		Expect(deployment.Kind).To(Equal("Deployment"))
+		Expect(deployment.Namespace).To(Equal(cr.Namespace))
+		Expect(deployment.OwnerReferences).To(BeEmpty())
+		Expect(deployment.Annotations).ToNot(HaveKey(SpecHashAnnotation))
+		Expect(deployment.Spec.Template.Spec.Containers).ToNot(BeEmpty())
+		Expect(string(files["deployment-argo-rollouts.yaml"])).ToNot(ContainSubstring("status:"))
+		Expect(string(files["deployment-argo-rollouts.yaml"])).ToNot(ContainSubstring("creationTimestamp"))
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).ToNot(Succeed())
+	})
+
+	It("should export all resources regardless of the live resources", func() {
+		before, err := r.ExportBundle(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		after, err := r.ExportBundle(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(after).To(Equal(before))
+	})
+
+	It("should not export the resources of an invalid RolloutManager", func() {
+		cr.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{QPS: 100, Burst: 10}
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+		_, err := r.ExportBundle(ctx, key)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is invalid"))
+	})
+})
@@ -0,0 +1,153 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultArgoRolloutsExtraPortsServiceName is the name of the Service which exposes the extra ports of the Rollouts controller container that are exposed with 'Service'.
+const DefaultArgoRolloutsExtraPortsServiceName = "argo-rollouts-extra-ports"
+
+// getExtraPortProtocol returns the protocol of an extra port, defaulting to TCP.
+func getExtraPortProtocol(port rolloutsmanagerv1alpha1.RolloutManagerExtraPort) corev1.Protocol {
+	if port.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return port.Protocol
+}
+
+// getRolloutsExtraPorts returns the extra ports of the Rollouts controller container: those of .spec.extraPorts, followed by the port of the profiling endpoints, if they are enabled (see isPprofRequested).
+func getRolloutsExtraPorts(cr rolloutsmanagerv1alpha1.RolloutManager) []rolloutsmanagerv1alpha1.RolloutManagerExtraPort {
+	res := append([]rolloutsmanagerv1alpha1.RolloutManagerExtraPort{}, cr.Spec.ExtraPorts...)
+	if isPprofRequested(cr) {
+		res = append(res, rolloutsmanagerv1alpha1.RolloutManagerExtraPort{
+			Name:          rolloutsPprofPortName,
+			ContainerPort: getRolloutsPprofPort(cr),
+			Expose:        rolloutsmanagerv1alpha1.ExtraPortExposeService,
+		})
+	}
+	return res
+}
+
+// getRolloutsExtraContainerPorts returns the container ports of the Rollouts controller container for the extra ports, which follow the healthz and metrics ports.
+func getRolloutsExtraContainerPorts(cr rolloutsmanagerv1alpha1.RolloutManager) []corev1.ContainerPort {
+	var res []corev1.ContainerPort
+	for _, port := range getRolloutsExtraPorts(cr) {
+		res = append(res, corev1.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: port.ContainerPort,
+			Protocol:      getExtraPortProtocol(port),
+		})
+	}
+	return res
+}
+
+// getExtraServicePorts returns the Service ports for the extra ports of the Rollouts controller container that are exposed via the given Service.
+func getExtraServicePorts(cr rolloutsmanagerv1alpha1.RolloutManager, expose rolloutsmanagerv1alpha1.RolloutManagerExtraPortExpose) []corev1.ServicePort {
+	var res []corev1.ServicePort
+	for _, port := range getRolloutsExtraPorts(cr) {
+		if port.Expose != expose {
+			continue
+		}
+		res = append(res, corev1.ServicePort{
+			Name:       port.Name,
+			Port:       port.ContainerPort,
+			Protocol:   getExtraPortProtocol(port),
+			TargetPort: intstr.FromString(port.Name),
+		})
+	}
+	return res
+}
+
+// validateRolloutsExtraPorts verifies that the extra ports of the Rollouts controller container have valid and unique names, and do not conflict with each other or with the healthz and metrics ports.
+func validateRolloutsExtraPorts(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	names := map[string]bool{"healthz": true, "metrics": true}
+	ports := map[string]string{
+		fmt.Sprintf("%d/%s", getRolloutsHealthzPort(cr), corev1.ProtocolTCP): "healthz",
+		fmt.Sprintf("%d/%s", getRolloutsMetricsPort(cr), corev1.ProtocolTCP): "metrics",
+	}
+
+	for _, port := range getRolloutsExtraPorts(cr) {
+
+		if errs := validation.IsValidPortName(port.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid name of extra port '%s': %s", port.Name, strings.Join(errs, ", "))
+		}
+		if names[port.Name] {
+			return fmt.Errorf("the name '%s' of an extra port is already used by another port of the Rollouts controller", port.Name)
+		}
+		names[port.Name] = true
+
+		key := fmt.Sprintf("%d/%s", port.ContainerPort, getExtraPortProtocol(port))
+		if other, exists := ports[key]; exists {
+			return fmt.Errorf("the extra port '%s' uses %s, which is already used by the port '%s'", port.Name, key, other)
+		}
+		ports[key] = port.Name
+	}
+
+	return nil
+}
+
+// reconcileRolloutsExtraPortsService creates/updates the Service which exposes the extra ports of the Rollouts controller container that are exposed with 'Service', and deletes it if there are none.
+func (r *RolloutManagerReconciler) reconcileRolloutsExtraPortsService(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	liveService := &corev1.Service{}
+	liveServiceExists := true
+	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsExtraPortsServiceName, liveService); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the Service %s: %w", DefaultArgoRolloutsExtraPortsServiceName, err)
+		}
+		liveServiceExists = false
+	}
+
+	servicePorts := getExtraServicePorts(cr, rolloutsmanagerv1alpha1.ExtraPortExposeService)
+	if len(servicePorts) == 0 {
+		if liveServiceExists {
+			log.Info(fmt.Sprintf("Deleting Service %s, as no extra ports are exposed with it", liveService.Name))
+			if err := r.Client.Delete(ctx, liveService); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete the Service %s: %w", liveService.Name, err)
+			}
+		}
+		return nil
+	}
+
+	expectedSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultArgoRolloutsExtraPortsServiceName,
+			Namespace: cr.Namespace,
+		},
+	}
+	setRolloutsLabelsAndAnnotationsToObject(&expectedSvc.ObjectMeta, cr)
+	expectedSvc.ObjectMeta.Labels["app.kubernetes.io/name"] = DefaultArgoRolloutsExtraPortsServiceName
+	expectedSvc.ObjectMeta.Labels["app.kubernetes.io/component"] = "server"
+	expectedSvc.ObjectMeta.Labels[RolloutManagerLabel] = cr.Name
+
+	expectedSvc.Spec.Ports = servicePorts
+	expectedSvc.Spec.Selector = map[string]string{
+		DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName,
+	}
+	if err := r.prepareResource(ctx, cr, expectedSvc); err != nil {
+		return err
+	}
+
+	liveService = &corev1.Service{}
+	_, err := r.applyResource(ctx, cr, expectedSvc, liveService, true, func() bool {
+		updateNeeded := updateServicePorts(liveService, expectedSvc, r.describeResource(cr, liveService))
+
+		if !equalNormalized(expectedSvc.Spec.Selector, liveService.Spec.Selector) {
+			updateNeeded = true
+			log.Info(fmt.Sprintf("Selector of Service %s does not match the expected state, hence updating it", liveService.Name))
+			liveService.Spec.Selector = expectedSvc.Spec.Selector
+		}
+		return updateNeeded
+	})
+	return err
+}
@@ -0,0 +1,155 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Extra port tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+		cr.Spec.ExtraPorts = []rolloutsmanagerv1alpha1.RolloutManagerExtraPort{
+			{Name: "plugin", ContainerPort: 9000, Expose: rolloutsmanagerv1alpha1.ExtraPortExposeMetricsService},
+			{Name: "debug", ContainerPort: 6060, Protocol: corev1.ProtocolUDP, Expose: rolloutsmanagerv1alpha1.ExtraPortExposeService},
+			{Name: "internal", ContainerPort: 9100},
+		}
+	})
+
+	It("should add the extra ports to the Rollouts controller container, after the healthz and metrics ports", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+
+		Expect(deployment.Spec.Template.Spec.Containers[0].Ports).To(Equal([]corev1.ContainerPort{
+			{ContainerPort: 8080, Name: "healthz"},
+			{ContainerPort: 8090, Name: "metrics"},
+			{ContainerPort: 9000, Name: "plugin", Protocol: corev1.ProtocolTCP},
+			{ContainerPort: 6060, Name: "debug", Protocol: corev1.ProtocolUDP},
+			{ContainerPort: 9100, Name: "internal", Protocol: corev1.ProtocolTCP},
+		}))
+
+		By("verifying that the normalized form is consistent with the desired Deployment")
+		normalized, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalized).To(Equal(deployment))
+
+		By("verifying that a removed extra port is detected as a difference")
+		live := *deployment.DeepCopy()
+		live.Spec.Template.Spec.Containers[0].Ports = live.Spec.Template.Spec.Containers[0].Ports[:4]
+		_, err = normalizeDeployment(live, cr)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("getExtraServicePorts should only return the ports exposed via the given Service", func() {
+		Expect(getExtraServicePorts(cr, rolloutsmanagerv1alpha1.ExtraPortExposeMetricsService)).To(Equal([]corev1.ServicePort{
+			{Name: "plugin", Port: 9000, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("plugin")},
+		}))
+		Expect(getExtraServicePorts(cr, rolloutsmanagerv1alpha1.ExtraPortExposeService)).To(Equal([]corev1.ServicePort{
+			{Name: "debug", Port: 6060, Protocol: corev1.ProtocolUDP, TargetPort: intstr.FromString("debug")},
+		}))
+	})
+
+	DescribeTable("validateRolloutsPorts should reject invalid extra ports",
+		func(extraPort rolloutsmanagerv1alpha1.RolloutManagerExtraPort, expectedErr string) {
+			cr.Spec.ExtraPorts = append(cr.Spec.ExtraPorts, extraPort)
+			Expect(validateRolloutsPorts(cr)).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("invalid name", rolloutsmanagerv1alpha1.RolloutManagerExtraPort{Name: "Not_Valid", ContainerPort: 9200}, "invalid name of extra port 'Not_Valid'"),
+		Entry("reserved name", rolloutsmanagerv1alpha1.RolloutManagerExtraPort{Name: "metrics", ContainerPort: 9200}, "the name 'metrics' of an extra port is already used"),
+		Entry("duplicate name", rolloutsmanagerv1alpha1.RolloutManagerExtraPort{Name: "plugin", ContainerPort: 9200}, "the name 'plugin' of an extra port is already used"),
+		Entry("port of the healthz endpoint", rolloutsmanagerv1alpha1.RolloutManagerExtraPort{Name: "other", ContainerPort: 8080}, "uses 8080/TCP, which is already used by the port 'healthz'"),
+		Entry("duplicate port", rolloutsmanagerv1alpha1.RolloutManagerExtraPort{Name: "other", ContainerPort: 6060, Protocol: corev1.ProtocolUDP}, "uses 6060/UDP, which is already used by the port 'debug'"),
+	)
+
+	It("validateRolloutsPorts should allow the same port number with different protocols", func() {
+		cr.Spec.ExtraPorts = append(cr.Spec.ExtraPorts, rolloutsmanagerv1alpha1.RolloutManagerExtraPort{Name: "debug-tcp", ContainerPort: 6060})
+		Expect(validateRolloutsPorts(cr)).To(Succeed())
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		})
+
+		It("should expose the extra ports via the metrics Service and the extra ports Service, and delete the latter when no longer needed", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			metricsService := &corev1.Service{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, metricsService)).To(Succeed())
+			Expect(metricsService.Spec.Ports).To(HaveLen(2))
+			Expect(metricsService.Spec.Ports[1].Name).To(Equal("plugin"))
+
+			extraPortsService := &corev1.Service{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsExtraPortsServiceName, extraPortsService)).To(Succeed())
+			Expect(extraPortsService.Spec.Ports).To(Equal(getExtraServicePorts(cr, rolloutsmanagerv1alpha1.ExtraPortExposeService)))
+			Expect(extraPortsService.Spec.Selector).To(Equal(map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName}))
+			Expect(extraPortsService.OwnerReferences).To(HaveLen(1))
+
+			By("verifying that the extra ports are restored after being removed from the Deployment")
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			deployment.Spec.Template.Spec.Containers[0].Ports = deployment.Spec.Template.Spec.Containers[0].Ports[:2]
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports).To(HaveLen(5))
+
+			By("removing the extra ports")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			cr.Spec.ExtraPorts = nil
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsExtraPortsServiceName, extraPortsService)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, metricsService)).To(Succeed())
+			Expect(metricsService.Spec.Ports).To(HaveLen(1))
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports).To(HaveLen(2))
+		})
+
+		It("should set the phase to Failure if an extra port is invalid", func() {
+			cr.Spec.ExtraPorts = append(cr.Spec.ExtraPorts, rolloutsmanagerv1alpha1.RolloutManagerExtraPort{Name: "healthz", ContainerPort: 9200})
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidPorts))
+		})
+	})
+})
@@ -0,0 +1,40 @@
+package rollouts
+
+import (
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+)
+
+// reconcileStep identifies a step of the reconciliation of a RolloutManager, at which a fault can be injected (see faultInjector).
+type reconcileStep string
+
+const (
+	reconcileStepServiceAccount      reconcileStep = "ServiceAccount"
+	reconcileStepRBAC                reconcileStep = "RBAC"
+	reconcileStepConfig              reconcileStep = "Config"
+	reconcileStepDeployment          reconcileStep = "Deployment"
+	reconcileStepLeaderElectionLease reconcileStep = "LeaderElectionLease"
+	reconcileStepMonitoring          reconcileStep = "Monitoring"
+	reconcileStepStatus              reconcileStep = "Status"
+)
+
+// faultInjector is implemented by the unit tests of this package, to make a step of the reconciliation fail with an error (for example, a conflict, forbidden or timeout error of the API server) before it makes any request. This allows the retry, backoff and condition behaviour of the reconciler to be tested deterministically.
+// It can only be set within this package, via the faultInjector field of RolloutManagerReconciler: the operator never sets it.
+type faultInjector interface {
+	// injectFault returns the error that the step should fail with, for the RolloutManager, or nil if the step should proceed.
+	injectFault(step reconcileStep, cr rolloutsmanagerv1alpha1.RolloutManager) error
+}
+
+// injectFault returns the fault injected at the given step of the reconciliation of the RolloutManager, if any.
+func (r *RolloutManagerReconciler) injectFault(step reconcileStep, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if r.faultInjector == nil {
+		return nil
+	}
+
+	if err := r.faultInjector.injectFault(step, cr); err != nil {
+		log.Info("Injecting fault in reconciliation step", "step", step, "namespace", cr.Namespace, "name", cr.Name, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
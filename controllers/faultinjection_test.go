@@ -0,0 +1,137 @@
+package rollouts
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// stepFaultInjector fails each step with the errors of its queue, in order: once the queue of a step is empty, the step proceeds. It records the steps that were reached.
+type stepFaultInjector struct {
+	faults  map[reconcileStep][]error
+	reached []reconcileStep
+}
+
+func (f *stepFaultInjector) injectFault(step reconcileStep, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+	f.reached = append(f.reached, step)
+
+	if len(f.faults[step]) == 0 {
+		return nil
+	}
+	err := f.faults[step][0]
+	f.faults[step] = f.faults[step][1:]
+	return err
+}
+
+var _ = Describe("Fault injection tests", func() {
+
+	var (
+		ctx      context.Context
+		cr       rolloutsmanagerv1alpha1.RolloutManager
+		r        *RolloutManagerReconciler
+		req      reconcile.Request
+		injector *stepFaultInjector
+	)
+
+	reconciledCondition := func() *metav1.Condition {
+		Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+		return meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerConditionType)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		injector = &stepFaultInjector{faults: map[reconcileStep][]error{}}
+		r.faultInjector = injector
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+	})
+
+	DescribeTable("should report the error of a step in the Reconciled condition, and recover on the next reconciliation", func(step reconcileStep, fault error) {
+		injector.faults[step] = []error{fault}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(MatchError(fault))
+
+		condition := reconciledCondition()
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(ContainSubstring(fault.Error()))
+
+		By("verifying that the steps after the failed step were not reached")
+		Expect(injector.reached[len(injector.reached)-1]).To(Equal(reconcileStepStatus))
+		Expect(injector.reached[len(injector.reached)-2]).To(Equal(step))
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		condition = reconciledCondition()
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess))
+	},
+		Entry("conflict when reconciling the ServiceAccount", reconcileStepServiceAccount,
+			apierrors.NewConflict(schema.GroupResource{Resource: "serviceaccounts"}, DefaultArgoRolloutsResourceName, errors.New("the object has been modified"))),
+		Entry("forbidden when reconciling the RBAC resources", reconcileStepRBAC,
+			apierrors.NewForbidden(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}, DefaultArgoRolloutsResourceName, errors.New("not allowed"))),
+		Entry("server timeout when reconciling the config resources", reconcileStepConfig,
+			apierrors.NewServerTimeout(schema.GroupResource{Resource: "configmaps"}, "update", 1)),
+		Entry("timeout when reconciling the Deployment", reconcileStepDeployment,
+			apierrors.NewTimeoutError("request did not complete within the allowed duration", 1)),
+		Entry("conflict when reconciling the leader election Lease", reconcileStepLeaderElectionLease,
+			apierrors.NewConflict(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, RolloutsLeaderElectionLeaseName, errors.New("the object has been modified"))),
+		Entry("forbidden when reconciling the monitoring resources", reconcileStepMonitoring,
+			apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, DefaultArgoRolloutsMetricsServiceName, errors.New("not allowed"))),
+	)
+
+	It("should keep failing while the fault persists, without creating the resources of the later steps", func() {
+		fault := apierrors.NewTimeoutError("request did not complete within the allowed duration", 1)
+		injector.faults[reconcileStepDeployment] = []error{fault, fault, fault}
+
+		for i := 0; i < 3; i++ {
+			_, err := r.Reconcile(ctx, req)
+			Expect(apierrors.IsTimeout(err)).To(BeTrue())
+			Expect(reconciledCondition().Status).To(Equal(metav1.ConditionFalse))
+		}
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &corev1.ServiceAccount{})).To(Succeed())
+		Expect(apierrors.IsNotFound(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{}))).To(BeTrue())
+		Expect(injector.reached).ToNot(ContainElement(reconcileStepLeaderElectionLease))
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).To(Succeed())
+	})
+
+	It("should return the error of the status update, without writing the status", func() {
+		fault := apierrors.NewConflict(schema.GroupResource{Group: "argoproj.io", Resource: "rolloutmanagers"}, cr.Name, errors.New("the object has been modified"))
+		injector.faults[reconcileStepStatus] = []error{fault}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(reconciledCondition()).To(BeNil())
+
+		By("verifying that the resources were reconciled before the status update failed")
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reconciledCondition().Status).To(Equal(metav1.ConditionTrue))
+	})
+})
@@ -0,0 +1,87 @@
+package rollouts
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FeatureGatesEnvName is an environment variable that can be used to enable or disable features of the operator, as a comma-separated list of 'Feature=true|false' pairs, instead of the --feature-gates flag.
+const FeatureGatesEnvName = "FEATURE_GATES"
+
+// Feature is the name of a feature of the operator that can be enabled or disabled via a feature gate.
+type Feature string
+
+const (
+	// ComponentControllers reconciles the RBAC, config and monitoring components of RolloutManagers with their own controllers, each with its own watches and workqueue, instead of as part of the reconciliation of the RolloutManager (see rolloutsComponents).
+	ComponentControllers Feature = "ComponentControllers"
+
+	// NotificationSecretOnDemand only creates the notification Secret of the Rollouts controller when notifications are configured, rather than an empty Secret for every RolloutManager (see isNotificationSecretNeeded).
+	NotificationSecretOnDemand Feature = "NotificationSecretOnDemand"
+)
+
+// defaultFeatureGates contains the features of the operator that can be toggled via a feature gate, and whether they are enabled by default.
+// New subsystems should be added here disabled by default, and only be enabled by default once they are stable.
+var defaultFeatureGates = map[Feature]bool{
+	ComponentControllers:       false,
+	NotificationSecretOnDemand: false,
+}
+
+// FeatureGates contains the features of the operator that were explicitly enabled or disabled. Features that are not set use their default (see defaultFeatureGates).
+type FeatureGates map[Feature]bool
+
+// Enabled returns true if the feature is enabled, either explicitly or by default.
+func (f FeatureGates) Enabled(feature Feature) bool {
+	if enabled, exists := f[feature]; exists {
+		return enabled
+	}
+	return defaultFeatureGates[feature]
+}
+
+// String returns the state of all known features, as a comma-separated list of 'Feature=true|false' pairs, sorted by feature.
+func (f FeatureGates) String() string {
+	var res []string
+	for feature := range defaultFeatureGates {
+		res = append(res, fmt.Sprintf("%s=%t", feature, f.Enabled(feature)))
+	}
+	sort.Strings(res)
+	return strings.Join(res, ",")
+}
+
+// ParseFeatureGates parses a comma-separated list of 'Feature=true|false' pairs (e.g. 'FeatureA=true,FeatureB=false'), as passed via the --feature-gates flag or the FEATURE_GATES environment variable. An error is returned for unknown features or invalid values.
+func ParseFeatureGates(value string) (FeatureGates, error) {
+	return parseFeatureGates(value, defaultFeatureGates)
+}
+
+func parseFeatureGates(value string, knownFeatures map[Feature]bool) (FeatureGates, error) {
+
+	res := FeatureGates{}
+
+	for _, pair := range strings.Split(value, ",") {
+
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, enabledValue, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("missing bool value for feature gate %q", pair)
+		}
+
+		feature := Feature(strings.TrimSpace(name))
+		if _, exists := knownFeatures[feature]; !exists {
+			return nil, fmt.Errorf("unrecognized feature gate %q", feature)
+		}
+
+		enabled, err := strconv.ParseBool(strings.TrimSpace(enabledValue))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of feature gate %q: %w", feature, err)
+		}
+
+		res[feature] = enabled
+	}
+
+	return res, nil
+}
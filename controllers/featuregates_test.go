@@ -0,0 +1,54 @@
+package rollouts
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Feature gate tests", func() {
+
+	const (
+		disabledFeature Feature = "DisabledByDefault"
+		enabledFeature  Feature = "EnabledByDefault"
+	)
+
+	BeforeEach(func() {
+		originalFeatureGates := defaultFeatureGates
+		defaultFeatureGates = map[Feature]bool{
+			disabledFeature: false,
+			enabledFeature:  true,
+		}
+		DeferCleanup(func() {
+			defaultFeatureGates = originalFeatureGates
+		})
+	})
+
+	It("should use the defaults of features that are not set", func() {
+		var featureGates FeatureGates
+		Expect(featureGates.Enabled(disabledFeature)).To(BeFalse())
+		Expect(featureGates.Enabled(enabledFeature)).To(BeTrue())
+
+		featureGates, err := ParseFeatureGates("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(featureGates.Enabled(disabledFeature)).To(BeFalse())
+		Expect(featureGates.Enabled(enabledFeature)).To(BeTrue())
+		Expect(featureGates.String()).To(Equal("DisabledByDefault=false,EnabledByDefault=true"))
+	})
+
+	It("should enable and disable the features that are set", func() {
+		featureGates, err := ParseFeatureGates("DisabledByDefault=true, EnabledByDefault=false")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(featureGates.Enabled(disabledFeature)).To(BeTrue())
+		Expect(featureGates.Enabled(enabledFeature)).To(BeFalse())
+		Expect(featureGates.String()).To(Equal("DisabledByDefault=true,EnabledByDefault=false"))
+	})
+
+	DescribeTable("should return an error for invalid feature gates", func(value string, expectedError string) {
+		_, err := ParseFeatureGates(value)
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+	},
+		Entry("unknown feature", "UnknownFeature=true", `unrecognized feature gate "UnknownFeature"`),
+		Entry("invalid value", "DisabledByDefault=yes", `invalid value of feature gate "DisabledByDefault"`),
+		Entry("missing value", "DisabledByDefault", `missing bool value for feature gate "DisabledByDefault"`),
+	)
+})
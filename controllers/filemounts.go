@@ -0,0 +1,130 @@
+package rollouts
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// defaultVolumeMounts returns the volume mounts of the Rollouts controller container which are always added by the operator.
+func defaultVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			MountPath: "/home/argo-rollouts/plugin-bin",
+			Name:      "plugin-bin",
+		},
+		{
+			MountPath: "/tmp",
+			Name:      "tmp",
+		},
+	}
+}
+
+// validateFileMounts verifies that the FileMounts of the RolloutManager can be added to the Rollouts controller Deployment.
+func validateFileMounts(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	names := map[string]bool{}
+	mountPaths := map[string]bool{}
+	for _, volumeMount := range defaultVolumeMounts() {
+		names[volumeMount.Name] = true
+		mountPaths[volumeMount.MountPath] = true
+	}
+
+	var errs []string
+
+	for _, fileMount := range cr.Spec.FileMounts {
+
+		if msgs := validation.IsDNS1123Label(fileMount.Name); len(msgs) > 0 {
+			errs = append(errs, fmt.Sprintf("name '%s' is invalid: %s", fileMount.Name, strings.Join(msgs, "; ")))
+		} else if names[fileMount.Name] {
+			errs = append(errs, fmt.Sprintf("name '%s' is already used", fileMount.Name))
+		}
+		names[fileMount.Name] = true
+
+		mountPath := path.Clean(fileMount.MountPath)
+		if !path.IsAbs(fileMount.MountPath) {
+			errs = append(errs, fmt.Sprintf("mountPath '%s' of '%s' is not an absolute path", fileMount.MountPath, fileMount.Name))
+		} else if mountPaths[mountPath] {
+			errs = append(errs, fmt.Sprintf("mountPath '%s' of '%s' is already used", fileMount.MountPath, fileMount.Name))
+		}
+		mountPaths[mountPath] = true
+
+		if (fileMount.Secret == "") == (fileMount.ConfigMap == "") {
+			errs = append(errs, fmt.Sprintf("exactly one of secret or configMap must be specified for '%s'", fileMount.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid fileMounts: %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// fileMountVolumes returns the volumes of the Rollouts controller Deployment for the FileMounts of the RolloutManager.
+func fileMountVolumes(cr rolloutsmanagerv1alpha1.RolloutManager) []corev1.Volume {
+
+	var res []corev1.Volume
+
+	for _, fileMount := range cr.Spec.FileMounts {
+		volume := corev1.Volume{Name: fileMount.Name}
+
+		if fileMount.Secret != "" {
+			volume.Secret = &corev1.SecretVolumeSource{
+				SecretName: fileMount.Secret,
+				Items:      fileMount.Items,
+			}
+		} else {
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: fileMount.ConfigMap},
+				Items:                fileMount.Items,
+			}
+		}
+
+		res = append(res, volume)
+	}
+
+	return res
+}
+
+// fileMountVolumeMounts returns the (read-only) volume mounts of the Rollouts controller container for the FileMounts of the RolloutManager.
+func fileMountVolumeMounts(cr rolloutsmanagerv1alpha1.RolloutManager) []corev1.VolumeMount {
+
+	var res []corev1.VolumeMount
+
+	for _, fileMount := range cr.Spec.FileMounts {
+		res = append(res, corev1.VolumeMount{
+			Name:      fileMount.Name,
+			MountPath: fileMount.MountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	return res
+}
+
+// normalizeFileMountVolume returns the fields of a FileMount volume that are set by the operator, discarding those defaulted by the API server (such as defaultMode).
+func normalizeFileMountVolume(volume corev1.Volume) corev1.Volume {
+
+	res := corev1.Volume{Name: volume.Name}
+
+	if volume.Secret != nil {
+		res.Secret = &corev1.SecretVolumeSource{
+			SecretName: volume.Secret.SecretName,
+			Items:      volume.Secret.Items,
+		}
+	}
+
+	if volume.ConfigMap != nil {
+		res.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: volume.ConfigMap.LocalObjectReference,
+			Items:                volume.ConfigMap.Items,
+		}
+	}
+
+	return res
+}
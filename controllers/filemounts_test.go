@@ -0,0 +1,141 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("File mount tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+		cr.Spec.FileMounts = []rolloutsmanagerv1alpha1.RolloutManagerFileMount{
+			{
+				Name:      "notification-templates",
+				MountPath: "/etc/notification-templates",
+				ConfigMap: "my-templates",
+			},
+			{
+				Name:      "provider-certs",
+				MountPath: "/etc/provider-certs",
+				Secret:    "my-certs",
+				Items:     []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.pem"}},
+			},
+		}
+	})
+
+	DescribeTable("validateFileMounts should reject invalid file mounts", func(modify func(*rolloutsmanagerv1alpha1.RolloutManagerFileMount), expectedError string) {
+		modify(&cr.Spec.FileMounts[1])
+		Expect(validateFileMounts(cr)).To(MatchError(ContainSubstring(expectedError)))
+	},
+		Entry("invalid name", func(fm *rolloutsmanagerv1alpha1.RolloutManagerFileMount) { fm.Name = "Provider_Certs" }, "name 'Provider_Certs' is invalid"),
+		Entry("duplicate name", func(fm *rolloutsmanagerv1alpha1.RolloutManagerFileMount) { fm.Name = "notification-templates" }, "name 'notification-templates' is already used"),
+		Entry("name of a default volume", func(fm *rolloutsmanagerv1alpha1.RolloutManagerFileMount) { fm.Name = "tmp" }, "name 'tmp' is already used"),
+		Entry("relative mount path", func(fm *rolloutsmanagerv1alpha1.RolloutManagerFileMount) { fm.MountPath = "etc/certs" }, "is not an absolute path"),
+		Entry("duplicate mount path", func(fm *rolloutsmanagerv1alpha1.RolloutManagerFileMount) {
+			fm.MountPath = "/etc/notification-templates/"
+		}, "mountPath '/etc/notification-templates/' of 'provider-certs' is already used"),
+		Entry("mount path of a default volume", func(fm *rolloutsmanagerv1alpha1.RolloutManagerFileMount) { fm.MountPath = "/tmp" }, "is already used"),
+		Entry("both secret and configMap", func(fm *rolloutsmanagerv1alpha1.RolloutManagerFileMount) { fm.ConfigMap = "other" }, "exactly one of secret or configMap"),
+		Entry("neither secret nor configMap", func(fm *rolloutsmanagerv1alpha1.RolloutManagerFileMount) { fm.Secret = "" }, "exactly one of secret or configMap"),
+	)
+
+	It("validateFileMounts should accept valid file mounts", func() {
+		Expect(validateFileMounts(cr)).To(Succeed())
+	})
+
+	It("should add the volumes and read-only volume mounts of the file mounts to the desired Deployment", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+
+		volumes := deployment.Spec.Template.Spec.Volumes
+		Expect(volumes).To(HaveLen(4))
+		Expect(volumes[2].Name).To(Equal("notification-templates"))
+		Expect(volumes[2].ConfigMap.Name).To(Equal("my-templates"))
+		Expect(volumes[3].Name).To(Equal("provider-certs"))
+		Expect(volumes[3].Secret.SecretName).To(Equal("my-certs"))
+		Expect(volumes[3].Secret.Items).To(Equal([]corev1.KeyToPath{{Key: "ca.crt", Path: "ca.pem"}}))
+
+		volumeMounts := deployment.Spec.Template.Spec.Containers[0].VolumeMounts
+		Expect(volumeMounts).To(HaveLen(4))
+		Expect(volumeMounts[2:]).To(Equal([]corev1.VolumeMount{
+			{Name: "notification-templates", MountPath: "/etc/notification-templates", ReadOnly: true},
+			{Name: "provider-certs", MountPath: "/etc/provider-certs", ReadOnly: true},
+		}))
+
+		By("verifying that the normalized form ignores fields defaulted by the API server")
+		normalizedDesired, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+
+		defaultMode := int32(420)
+		deployment.Spec.Template.Spec.Volumes[2].ConfigMap.DefaultMode = &defaultMode
+		deployment.Spec.Template.Spec.Volumes[3].Secret.DefaultMode = &defaultMode
+		normalizedLive, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalizedLive).To(Equal(normalizedDesired))
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+		})
+
+		It("should add and remove the file mounts of the Rollouts controller Deployment", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(4))
+			Expect(deployment.Spec.Template.Spec.Containers[0].VolumeMounts).To(HaveLen(4))
+
+			By("removing the file mounts")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			cr.Spec.FileMounts = nil
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(2))
+			Expect(deployment.Spec.Template.Spec.Containers[0].VolumeMounts).To(Equal(defaultVolumeMounts()))
+		})
+
+		It("should set the phase to Failure if the file mounts are invalid", func() {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			cr.Spec.FileMounts[1].MountPath = "relative"
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidFileMounts))
+		})
+	})
+})
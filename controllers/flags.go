@@ -0,0 +1,174 @@
+package rollouts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// rolloutsControllerFlagMinimumVersions contains the Argo Rollouts controller command line flags that were introduced after the first Argo Rollouts release, mapped to the release that introduced them.
+// Flags that are not listed here are assumed to be supported by all versions of the Argo Rollouts controller.
+var rolloutsControllerFlagMinimumVersions = map[string]string{
+	"--aws-verify-target-group":           "v1.0.0",
+	"--appmesh-crd-version":               "v1.1.0",
+	"--leader-elect":                      "v1.2.0",
+	"--leader-election-lease-duration":    "v1.2.0",
+	"--leader-election-renew-deadline":    "v1.2.0",
+	"--leader-election-retry-period":      "v1.2.0",
+	"--logformat":                         "v1.4.0",
+	"--apisix-api-version":                "v1.5.0",
+	"--ephemeral-metadata-threads":        "v1.7.0",
+	"--self-service-notification-enabled": "v1.7.0",
+}
+
+// namespacedArgMinimumVersion is the first Argo Rollouts release whose controller can be restricted to its own namespace, via namespacedArg
+const namespacedArgMinimumVersion = "v0.9.0"
+
+// getRolloutsControllerVersion returns the Argo Rollouts version that will be deployed for the RolloutManager, or nil if the version cannot be determined (for example, a digest or a custom image was specified).
+func getRolloutsControllerVersion(cr rolloutsmanagerv1alpha1.RolloutManager) *version.Version {
+
+	tag := cr.Spec.Version
+	if tag == "" {
+		// If the image is overridden by environment variable, we don't know which version is used.
+		if cr.Spec.Image == "" && getRolloutsImageFromEnv() != "" {
+			return nil
+		}
+		tag = DefaultArgoRolloutsVersion
+	}
+
+	// Digests can't be mapped to a version
+	if strings.Contains(tag, ":") {
+		return nil
+	}
+
+	v, err := version.ParseSemantic(tag)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// validateRolloutsCommandArgs verifies that the command arguments of the Rollouts controller only contain flags that are supported by the selected Argo Rollouts version.
+// Validation is skipped if the version cannot be determined.
+func validateRolloutsCommandArgs(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	selectedVersion := getRolloutsControllerVersion(cr)
+	if selectedVersion == nil {
+		return nil
+	}
+
+	var unsupportedFlags []string
+
+	for _, arg := range getRolloutsCommandArgs(cr) {
+
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+
+		// Flags may be specified as either '--flag value' or '--flag=value'
+		flag, _, _ := strings.Cut(arg, "=")
+
+		minimumVersion, exists := rolloutsControllerFlagMinimumVersions[flag]
+		if !exists {
+			continue
+		}
+
+		if !selectedVersion.AtLeast(version.MustParseSemantic(minimumVersion)) {
+			unsupportedFlags = append(unsupportedFlags, fmt.Sprintf("%s (requires %s)", flag, minimumVersion))
+		}
+	}
+
+	if len(unsupportedFlags) == 0 {
+		return nil
+	}
+
+	sort.Strings(unsupportedFlags)
+
+	return fmt.Errorf("the following command arguments are not supported by Argo Rollouts v%s: %s", selectedVersion.String(), strings.Join(unsupportedFlags, ", "))
+}
+
+// validateRolloutsScopeArgs verifies the arguments which restrict the Rollouts controller of a namespace-scoped RolloutManager to its namespace (see getRolloutsScopeArgs): .spec.scopeArgsOverride must not contain empty arguments, and must be specified for Argo Rollouts releases older than namespacedArgMinimumVersion, which do not support namespacedArg.
+// If .spec.argsOverrideMode is 'replace', the scope arguments are not added by the operator, hence .spec.extraCommandArgs must contain them: otherwise, the Rollouts controller would watch the whole cluster, with only the Roles of its namespace.
+// The version is not verified if it cannot be determined.
+func validateRolloutsScopeArgs(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if !cr.Spec.NamespaceScoped {
+		return nil
+	}
+
+	for _, arg := range cr.Spec.ScopeArgsOverride {
+		if strings.TrimSpace(arg) == "" {
+			return fmt.Errorf("scopeArgsOverride must not contain empty arguments")
+		}
+	}
+
+	if len(cr.Spec.ScopeArgsOverride) == 0 {
+		selectedVersion := getRolloutsControllerVersion(cr)
+		if selectedVersion != nil && !selectedVersion.AtLeast(version.MustParseSemantic(namespacedArgMinimumVersion)) {
+			return fmt.Errorf("the %s argument is not supported by Argo Rollouts v%s (requires %s): specify the arguments which restrict the Rollouts controller to its namespace in scopeArgsOverride", namespacedArg, selectedVersion.String(), namespacedArgMinimumVersion)
+		}
+	}
+
+	if cr.Spec.ArgsOverrideMode == rolloutsmanagerv1alpha1.ArgsOverrideModeReplace {
+		scopeArgs := getRolloutsScopeArgs(cr)
+		if !containsArgs(cr.Spec.ExtraCommandArgs, scopeArgs) {
+			return fmt.Errorf("extraCommandArgs must contain the arguments which restrict the Rollouts controller of a namespace-scoped RolloutManager to its namespace ('%s'), when argsOverrideMode is 'replace'", strings.Join(scopeArgs, " "))
+		}
+	}
+
+	return nil
+}
+
+// containsArgs returns true if 'args' contains 'subArgs', consecutively and in order.
+func containsArgs(args []string, subArgs []string) bool {
+	for i := 0; i+len(subArgs) <= len(args); i++ {
+		if reflect.DeepEqual(args[i:i+len(subArgs)], subArgs) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRolloutsCommandArgConflicts verifies that .spec.extraCommandArgs do not set a flag which is already added by the operator (for example, '--qps' when .spec.kubeClient.qps is set), whatever its value: the Rollouts controller would otherwise receive the flag twice, and the extra command arguments would be ignored (see getRolloutsCommandArgs).
+// Flags are compared by name, so '--flag value' and '--flag=value' conflict with both forms. Validation is skipped if .spec.argsOverrideMode is 'replace', as only the extra command arguments are then used.
+func validateRolloutsCommandArgConflicts(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if cr.Spec.ArgsOverrideMode == rolloutsmanagerv1alpha1.ArgsOverrideModeReplace {
+		return nil
+	}
+
+	operatorArgs := map[string]string{}
+	for _, arg := range getOperatorCommandArgs(cr) {
+		flag, _, _ := strings.Cut(arg, "=")
+		operatorArgs[flag] = arg
+	}
+
+	conflicts := map[string]bool{}
+	for _, arg := range cr.Spec.ExtraCommandArgs {
+
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+
+		flag, _, _ := strings.Cut(arg, "=")
+		if operatorArg, exists := operatorArgs[flag]; exists {
+			conflicts[fmt.Sprintf("%s (added by the operator as '%s')", flag, operatorArg)] = true
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	conflictingFlags := []string{}
+	for conflict := range conflicts {
+		conflictingFlags = append(conflictingFlags, conflict)
+	}
+	sort.Strings(conflictingFlags)
+
+	return fmt.Errorf("the following command arguments are already added by the operator, from the fields of the RolloutManager, and must be removed from extraCommandArgs: %s", strings.Join(conflictingFlags, ", "))
+}
@@ -0,0 +1,236 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var _ = Describe("Rollouts controller flag validation tests", func() {
+
+	DescribeTable("validateRolloutsCommandArgs should only reject flags that are not supported by the selected version",
+		func(version string, image string, extraArgs []string, expectErr bool) {
+			cr := *makeTestRolloutManager()
+			cr.Spec.Version = version
+			cr.Spec.Image = image
+			cr.Spec.ExtraCommandArgs = extraArgs
+
+			err := validateRolloutsCommandArgs(cr)
+			if expectErr {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+		},
+		Entry("no extra args", "", "", nil, false),
+		Entry("flags that are supported by all versions", "v1.0.0", "", []string{"--loglevel", "debug", "--rollout-threads=20"}, false),
+		Entry("a new flag with the default version", "", "", []string{"--self-service-notification-enabled"}, false),
+		Entry("a new flag with a recent version", "v1.7.2", "", []string{"--ephemeral-metadata-threads=5"}, false),
+		Entry("a new flag with an old version", "v1.6.6", "", []string{"--self-service-notification-enabled"}, true),
+		Entry("a new flag, in '--flag=value' format, with an old version", "v1.6.6", "", []string{"--ephemeral-metadata-threads=5"}, true),
+		Entry("a new flag with an old version, without the 'v' prefix", "1.3.0", "", []string{"--logformat", "json"}, true),
+		Entry("a new flag with a digest, which can't be validated", "sha256:0123456789abcdef", "", []string{"--self-service-notification-enabled"}, false),
+		Entry("a new flag with a non-semantic version, which can't be validated", "latest", "", []string{"--self-service-notification-enabled"}, false),
+	)
+
+	It("should report all unsupported flags, and the version, in the error", func() {
+		cr := *makeTestRolloutManager()
+		cr.Spec.Version = "v1.1.0"
+		cr.Spec.ExtraCommandArgs = []string{"--logformat", "json", "--leader-elect=false"}
+
+		err := validateRolloutsCommandArgs(cr)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("v1.1.0"))
+		Expect(err.Error()).To(ContainSubstring("--logformat (requires v1.4.0)"))
+		Expect(err.Error()).To(ContainSubstring("--leader-elect (requires v1.2.0)"))
+	})
+
+	It("should skip validation when the default image is overridden by environment variable", func() {
+		prevImageVal := os.Getenv(ArgoRolloutsImageEnvName)
+		Expect(os.Setenv(ArgoRolloutsImageEnvName, "quay.io/my-org/my-rollouts:my-tag")).To(Succeed())
+		defer func() {
+			Expect(os.Setenv(ArgoRolloutsImageEnvName, prevImageVal)).To(Succeed())
+		}()
+
+		cr := *makeTestRolloutManager()
+		cr.Spec.ExtraCommandArgs = []string{"--some-unknown-flag"}
+
+		Expect(getRolloutsControllerVersion(cr)).To(BeNil())
+		Expect(validateRolloutsCommandArgs(cr)).To(Succeed())
+	})
+
+	DescribeTable("validateRolloutsCommandArgConflicts should reject extra command arguments which set a flag added by the operator, whatever their form and value",
+		func(mutate func(cr *v1alpha1.RolloutManager), extraArgs []string, expectedErr string) {
+			cr := *makeTestRolloutManager()
+			mutate(&cr)
+			cr.Spec.ExtraCommandArgs = extraArgs
+
+			err := validateRolloutsCommandArgConflicts(cr)
+			if expectedErr == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+			}
+		},
+		Entry("no conflicting flags", func(cr *v1alpha1.RolloutManager) { cr.Spec.NamespaceScoped = true }, []string{"--loglevel", "debug"}, ""),
+		Entry("a duplicated flag", func(cr *v1alpha1.RolloutManager) { cr.Spec.NamespaceScoped = true }, []string{"--namespaced"}, "--namespaced (added by the operator as '--namespaced')"),
+		Entry("a conflicting flag, in '--flag=value' format", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.KubeClient = &v1alpha1.RolloutManagerKubeClientSpec{QPS: 100}
+		}, []string{"--qps=50"}, "--qps (added by the operator as '--qps=100')"),
+		Entry("a conflicting flag, in '--flag value' format", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.Ports = &v1alpha1.RolloutManagerPortsSpec{Metrics: 18090}
+		}, []string{"--metricsport", "9000"}, "--metricsport (added by the operator as '--metricsport=18090')"),
+		Entry("a flag which is only added by the operator for non-default values", func(cr *v1alpha1.RolloutManager) {}, []string{"--metricsport=9000", "--qps=50"}, ""),
+		Entry("a conflicting flag with the 'replace' args override mode, in which only the extra command arguments are used", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.NamespaceScoped = true
+			cr.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+		}, []string{"--namespaced"}, ""),
+	)
+
+	It("should report each conflicting flag once, in a deterministic order", func() {
+		cr := *makeTestRolloutManager()
+		cr.Spec.KubeClient = &v1alpha1.RolloutManagerKubeClientSpec{QPS: 100, Burst: 200}
+		cr.Spec.ExtraCommandArgs = []string{"--qps=50", "--burst", "100", "--qps", "60"}
+
+		err := validateRolloutsCommandArgConflicts(cr)
+		Expect(err).To(MatchError(HaveSuffix("extraCommandArgs: --burst (added by the operator as '--burst=200'), --qps (added by the operator as '--qps=100')")))
+	})
+
+	It("should set a failure condition, and not create the Deployment, when an extra command argument conflicts with the operator", func() {
+		ctx := context.Background()
+
+		cr := *makeTestRolloutManager()
+		cr.Spec.NamespaceScoped = true
+		cr.Spec.ExtraCommandArgs = []string{"--namespaced", "--loglevel", "debug"}
+
+		r := makeTestReconciler(&cr)
+		r.NamespaceScopedArgoRolloutsController = true
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		res, err := r.reconcileRolloutsManager(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.condition.Reason).To(Equal(v1alpha1.RolloutManagerReasonConflictingCommandArgs))
+		Expect(res.condition.Message).To(ContainSubstring("--namespaced"))
+		Expect(*res.phase).To(Equal(v1alpha1.PhaseFailure))
+
+		err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should set a failure condition, and not create the Deployment, when an unsupported flag is used", func() {
+		ctx := context.Background()
+
+		cr := *makeTestRolloutManager()
+		cr.Spec.Version = "v1.6.0"
+		cr.Spec.ExtraCommandArgs = []string{"--self-service-notification-enabled"}
+
+		r := makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		defer os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+
+		res, err := r.reconcileRolloutsManager(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.condition.Reason).To(Equal(v1alpha1.RolloutManagerReasonUnsupportedCommandArgs))
+		Expect(*res.phase).To(Equal(v1alpha1.PhaseFailure))
+		Expect(*res.rolloutController).To(Equal(v1alpha1.PhaseFailure))
+
+		err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	DescribeTable("validateRolloutsScopeArgs should require the scope arguments override for versions which do not support --namespaced",
+		func(mutate func(cr *v1alpha1.RolloutManager), expectedErr string) {
+			cr := *makeTestRolloutManager()
+			cr.Spec.NamespaceScoped = true
+			mutate(&cr)
+
+			err := validateRolloutsScopeArgs(cr)
+			if expectedErr == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+			}
+		},
+		Entry("the default version", func(cr *v1alpha1.RolloutManager) {}, ""),
+		Entry("the first version which supports --namespaced", func(cr *v1alpha1.RolloutManager) { cr.Spec.Version = "v0.9.0" }, ""),
+		Entry("a version which does not support --namespaced", func(cr *v1alpha1.RolloutManager) { cr.Spec.Version = "v0.8.3" },
+			"the --namespaced argument is not supported by Argo Rollouts v0.8.3 (requires v0.9.0)"),
+		Entry("a version which does not support --namespaced, with a scope arguments override", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.Version = "v0.8.3"
+			cr.Spec.ScopeArgsOverride = []string{"--namespace", cr.Namespace}
+		}, ""),
+		Entry("a version which does not support --namespaced, for a cluster-scoped RolloutManager", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.Version = "v0.8.3"
+			cr.Spec.NamespaceScoped = false
+		}, ""),
+		Entry("a version which does not support --namespaced, with the 'replace' args override mode", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.Version = "v0.8.3"
+			cr.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+			cr.Spec.ExtraCommandArgs = []string{"--namespaced"}
+		}, "the --namespaced argument is not supported by Argo Rollouts v0.8.3 (requires v0.9.0)"),
+		Entry("a version which does not support --namespaced, with the 'replace' args override mode and a scope arguments override", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.Version = "v0.8.3"
+			cr.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+			cr.Spec.ScopeArgsOverride = []string{"--namespace", cr.Namespace}
+			cr.Spec.ExtraCommandArgs = []string{"--loglevel", "debug", "--namespace", cr.Namespace}
+		}, ""),
+		Entry("the 'replace' args override mode, with --namespaced", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+			cr.Spec.ExtraCommandArgs = []string{"--namespaced", "--loglevel", "debug"}
+		}, ""),
+		Entry("the 'replace' args override mode, without --namespaced", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+			cr.Spec.ExtraCommandArgs = []string{"--loglevel", "debug"}
+		}, "extraCommandArgs must contain the arguments which restrict the Rollouts controller of a namespace-scoped RolloutManager to its namespace ('--namespaced')"),
+		Entry("the 'replace' args override mode, without the scope arguments override", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+			cr.Spec.ScopeArgsOverride = []string{"--namespace", cr.Namespace}
+			cr.Spec.ExtraCommandArgs = []string{"--namespaced", "--namespace"}
+		}, "('--namespace "+testNamespace+"')"),
+		Entry("the 'replace' args override mode, for a cluster-scoped RolloutManager", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.NamespaceScoped = false
+			cr.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+		}, ""),
+		Entry("a scope arguments override with an empty argument", func(cr *v1alpha1.RolloutManager) {
+			cr.Spec.ScopeArgsOverride = []string{"--namespace", " "}
+		}, "scopeArgsOverride must not contain empty arguments"),
+	)
+
+	It("should set a failure condition, and not create the Deployment, when the 'replace' args override mode drops the scope arguments of a namespace-scoped RolloutManager", func() {
+		ctx := context.Background()
+
+		cr := *makeTestRolloutManager()
+		cr.Spec.NamespaceScoped = true
+		cr.Spec.ArgsOverrideMode = v1alpha1.ArgsOverrideModeReplace
+		cr.Spec.ExtraCommandArgs = []string{"--loglevel", "debug"}
+
+		r := makeTestReconciler(&cr)
+		r.NamespaceScopedArgoRolloutsController = true
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		res, err := r.reconcileRolloutsManager(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.condition.Reason).To(Equal(v1alpha1.RolloutManagerReasonUnsupportedCommandArgs))
+		Expect(res.condition.Message).To(ContainSubstring("--namespaced"))
+		Expect(*res.phase).To(Equal(v1alpha1.PhaseFailure))
+
+		err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should reject extra command arguments which set a flag of the scope arguments override", func() {
+		cr := *makeTestRolloutManager()
+		cr.Spec.NamespaceScoped = true
+		cr.Spec.ScopeArgsOverride = []string{"--namespace=" + cr.Namespace}
+		cr.Spec.ExtraCommandArgs = []string{"--namespace", "other"}
+
+		Expect(validateRolloutsCommandArgConflicts(cr)).To(MatchError(ContainSubstring("--namespace (added by the operator as '--namespace=" + cr.Namespace + "')")))
+	})
+})
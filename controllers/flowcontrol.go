@@ -0,0 +1,141 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	flowcontrolv1beta3 "k8s.io/api/flowcontrol/v1beta3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultFlowSchemaMatchingPrecedence is the matching precedence of the FlowSchema of the Rollouts controller, if none is specified in the RolloutManager. It takes precedence over the default 'service-accounts' FlowSchema (9000).
+	DefaultFlowSchemaMatchingPrecedence = 1000
+
+	// FlowSchemaOwnerLabel is set on the FlowSchema of the Rollouts controller, and contains the namespace of the RolloutManager that created it.
+	// FlowSchemas are cluster-scoped, so they can't be owned by the RolloutManager, and this label is used to clean them up.
+	FlowSchemaOwnerLabel = "argo-rollouts.argoproj.io/flowschema-owner"
+)
+
+// getFlowSchemaName returns the name of the FlowSchema of the Rollouts controller of the RolloutManager in the given namespace.
+func getFlowSchemaName(namespace string) string {
+	return fmt.Sprintf("%s-%s", DefaultArgoRolloutsResourceName, namespace)
+}
+
+// getFlowSchemaMatchingPrecedence returns the matching precedence of the FlowSchema of the Rollouts controller.
+func getFlowSchemaMatchingPrecedence(cr rolloutsmanagerv1alpha1.RolloutManager) int32 {
+	if cr.Spec.FlowControl == nil || cr.Spec.FlowControl.MatchingPrecedence == 0 {
+		return DefaultFlowSchemaMatchingPrecedence
+	}
+	return cr.Spec.FlowControl.MatchingPrecedence
+}
+
+// generateDesiredFlowSchema returns the FlowSchema which assigns all the requests of the ServiceAccount of the Rollouts controller to the priority level of the RolloutManager.
+func generateDesiredFlowSchema(cr rolloutsmanagerv1alpha1.RolloutManager) *flowcontrolv1beta3.FlowSchema {
+
+	flowSchema := &flowcontrolv1beta3.FlowSchema{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: getFlowSchemaName(cr.Namespace),
+		},
+		Spec: flowcontrolv1beta3.FlowSchemaSpec{
+			PriorityLevelConfiguration: flowcontrolv1beta3.PriorityLevelConfigurationReference{
+				Name: cr.Spec.FlowControl.PriorityLevel,
+			},
+			MatchingPrecedence: getFlowSchemaMatchingPrecedence(cr),
+			// Requests are distinguished by user, as for the default 'service-accounts' FlowSchema
+			DistinguisherMethod: &flowcontrolv1beta3.FlowDistinguisherMethod{
+				Type: flowcontrolv1beta3.FlowDistinguisherMethodByUserType,
+			},
+			Rules: []flowcontrolv1beta3.PolicyRulesWithSubjects{
+				{
+					Subjects: []flowcontrolv1beta3.Subject{
+						{
+							Kind: flowcontrolv1beta3.SubjectKindServiceAccount,
+							ServiceAccount: &flowcontrolv1beta3.ServiceAccountSubject{
+								Namespace: cr.Namespace,
+								Name:      DefaultArgoRolloutsResourceName,
+							},
+						},
+					},
+					ResourceRules: []flowcontrolv1beta3.ResourcePolicyRule{
+						{
+							Verbs:        []string{flowcontrolv1beta3.VerbAll},
+							APIGroups:    []string{flowcontrolv1beta3.APIGroupAll},
+							Resources:    []string{flowcontrolv1beta3.ResourceAll},
+							ClusterScope: true,
+							Namespaces:   []string{flowcontrolv1beta3.NamespaceEvery},
+						},
+					},
+					NonResourceRules: []flowcontrolv1beta3.NonResourcePolicyRule{
+						{
+							Verbs:           []string{flowcontrolv1beta3.VerbAll},
+							NonResourceURLs: []string{flowcontrolv1beta3.NonResourceAll},
+						},
+					},
+				},
+			},
+		},
+	}
+	setRolloutsLabelsAndAnnotationsToObject(&flowSchema.ObjectMeta, cr)
+	flowSchema.Labels[FlowSchemaOwnerLabel] = cr.Namespace
+
+	return flowSchema
+}
+
+// reconcileRolloutsFlowSchema creates/updates the FlowSchema of the Rollouts controller if the RolloutManager configures its flow control, and deletes it otherwise.
+func (r *RolloutManagerReconciler) reconcileRolloutsFlowSchema(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if r.clusterAPIDisabled() {
+		// FlowSchemas are cluster-scoped: the flow control of the RolloutManager is reported by the ClusterAPIRequired condition instead (see getClusterAPIRequirements)
+		return nil
+	}
+
+	if cr.Spec.FlowControl == nil {
+		return r.removeRolloutsFlowSchema(ctx, cr.Namespace)
+	}
+
+	expectedFlowSchema := generateDesiredFlowSchema(cr)
+	if err := r.prepareResource(ctx, cr, expectedFlowSchema); err != nil {
+		return err
+	}
+
+	liveFlowSchema := &flowcontrolv1beta3.FlowSchema{}
+	_, err := r.applyResource(ctx, cr, expectedFlowSchema, liveFlowSchema, false, func() bool {
+		if equality.Semantic.DeepEqual(liveFlowSchema.Spec, expectedFlowSchema.Spec) {
+			return false
+		}
+		log.Info(fmt.Sprintf("Spec of %s does not match the expected state, hence updating it", r.describeResource(cr, liveFlowSchema)))
+		liveFlowSchema.Spec = expectedFlowSchema.Spec
+		return true
+	})
+	return err
+}
+
+// removeRolloutsFlowSchema deletes the FlowSchema that was created for the Rollouts controller of the RolloutManager in the given namespace, if any. A FlowSchema of the same name which was not created by the operator is left untouched.
+func (r *RolloutManagerReconciler) removeRolloutsFlowSchema(ctx context.Context, rolloutManagerNamespace string) error {
+
+	if r.clusterAPIDisabled() {
+		return nil
+	}
+
+	flowSchema := &flowcontrolv1beta3.FlowSchema{}
+	if err := fetchObject(ctx, r.Client, "", getFlowSchemaName(rolloutManagerNamespace), flowSchema); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the FlowSchema %s: %w", getFlowSchemaName(rolloutManagerNamespace), err)
+	}
+
+	if flowSchema.Labels[FlowSchemaOwnerLabel] != rolloutManagerNamespace {
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("Deleting FlowSchema %s of the Rollouts controller", flowSchema.Name))
+	if err := r.Client.Delete(ctx, flowSchema); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the FlowSchema %s: %w", flowSchema.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	flowcontrolv1beta3 "k8s.io/api/flowcontrol/v1beta3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("FlowSchema tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+		req reconcile.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		cr.Spec.FlowControl = &rolloutsmanagerv1alpha1.RolloutManagerFlowControlSpec{PriorityLevel: "workload-high"}
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+	})
+
+	It("should create a FlowSchema matching the ServiceAccount of the Rollouts controller, update it, and delete it once flow control is no longer configured", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		flowSchema := &flowcontrolv1beta3.FlowSchema{}
+		Expect(fetchObject(ctx, r.Client, "", getFlowSchemaName(cr.Namespace), flowSchema)).To(Succeed())
+		Expect(flowSchema.Labels).To(HaveKeyWithValue(FlowSchemaOwnerLabel, cr.Namespace))
+		Expect(flowSchema.Spec.PriorityLevelConfiguration.Name).To(Equal("workload-high"))
+		Expect(flowSchema.Spec.MatchingPrecedence).To(Equal(int32(DefaultFlowSchemaMatchingPrecedence)))
+		Expect(flowSchema.Spec.Rules).To(HaveLen(1))
+		Expect(flowSchema.Spec.Rules[0].Subjects).To(Equal([]flowcontrolv1beta3.Subject{{
+			Kind:           flowcontrolv1beta3.SubjectKindServiceAccount,
+			ServiceAccount: &flowcontrolv1beta3.ServiceAccountSubject{Namespace: cr.Namespace, Name: DefaultArgoRolloutsResourceName},
+		}}))
+
+		By("updating the FlowSchema when the priority level changes")
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		cr.Spec.FlowControl = &rolloutsmanagerv1alpha1.RolloutManagerFlowControlSpec{PriorityLevel: "argo-rollouts", MatchingPrecedence: 500}
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, "", getFlowSchemaName(cr.Namespace), flowSchema)).To(Succeed())
+		Expect(flowSchema.Spec.PriorityLevelConfiguration.Name).To(Equal("argo-rollouts"))
+		Expect(flowSchema.Spec.MatchingPrecedence).To(Equal(int32(500)))
+
+		By("deleting the FlowSchema once flow control is no longer configured")
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		cr.Spec.FlowControl = nil
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fetchObject(ctx, r.Client, "", getFlowSchemaName(cr.Namespace), flowSchema)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should delete the FlowSchema once the RolloutManager is deleted, but not a FlowSchema of the same name which was not created by the operator", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Delete(ctx, &cr)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fetchObject(ctx, r.Client, "", getFlowSchemaName(cr.Namespace), &flowcontrolv1beta3.FlowSchema{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		userFlowSchema := &flowcontrolv1beta3.FlowSchema{ObjectMeta: metav1.ObjectMeta{Name: getFlowSchemaName(cr.Namespace)}}
+		Expect(r.Client.Create(ctx, userFlowSchema)).To(Succeed())
+		Expect(r.removeRolloutsFlowSchema(ctx, cr.Namespace)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, "", userFlowSchema.Name, &flowcontrolv1beta3.FlowSchema{})).To(Succeed())
+	})
+})
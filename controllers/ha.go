@@ -0,0 +1,89 @@
+package rollouts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultRolloutsHAReplicas is the default number of replicas of the Rollouts controller, when .spec.ha is enabled
+	DefaultRolloutsHAReplicas int32 = 2
+
+	// leaderElectFlag enables the leader election of the Rollouts controller, so that a single replica reconciles Rollouts at a time
+	leaderElectFlag = "--leader-elect"
+)
+
+// isHAEnabled returns true if multiple replicas of the Rollouts controller should be run, via .spec.ha.
+func isHAEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.HA != nil && cr.Spec.HA.Enabled
+}
+
+// getRolloutsReplicas returns the number of replicas of the Rollouts controller Deployment, from .spec.ha, or nil if HA is not enabled: the replicas are then not managed by the operator (and are defaulted to 1 by the API server).
+func getRolloutsReplicas(cr rolloutsmanagerv1alpha1.RolloutManager) *int32 {
+
+	if !isHAEnabled(cr) {
+		return nil
+	}
+
+	replicas := DefaultRolloutsHAReplicas
+	if cr.Spec.HA.Replicas != 0 {
+		replicas = cr.Spec.HA.Replicas
+	}
+	return &replicas
+}
+
+// getRolloutsHAArgs returns the command arguments which enable the leader election of the Rollouts controller, when HA is enabled. The Rollouts controller enables it by default, but the argument is added explicitly, as the replicas would otherwise all reconcile the same Rollouts.
+func getRolloutsHAArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	if !isHAEnabled(cr) {
+		return nil
+	}
+	return []string{leaderElectFlag + "=true"}
+}
+
+// getRolloutsPodAntiAffinity returns the pod anti-affinity which spreads the replicas of the Rollouts controller across nodes, when HA is enabled, so that the failure of a node does not stop all of them.
+// The anti-affinity is preferred rather than required, so that all of the replicas can still be scheduled on clusters with fewer nodes than replicas.
+func getRolloutsPodAntiAffinity(cr rolloutsmanagerv1alpha1.RolloutManager) *corev1.PodAntiAffinity {
+
+	if !isHAEnabled(cr) {
+		return nil
+	}
+
+	return &corev1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName,
+					},
+				},
+				TopologyKey: corev1.LabelHostname,
+			},
+		}},
+	}
+}
+
+// validateRolloutsHA verifies that the leader election of the Rollouts controller is not disabled via .spec.extraCommandArgs when HA is enabled (whatever .spec.argsOverrideMode is), as its replicas would then all reconcile the same Rollouts.
+func validateRolloutsHA(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if !isHAEnabled(cr) {
+		return nil
+	}
+
+	for _, arg := range cr.Spec.ExtraCommandArgs {
+		flag, value, hasValue := strings.Cut(arg, "=")
+		if flag != leaderElectFlag || !hasValue {
+			continue
+		}
+		if enabled, err := strconv.ParseBool(value); err == nil && !enabled {
+			return fmt.Errorf("the leader election of the Rollouts controller must not be disabled via extraCommandArgs ('%s') when ha is enabled", arg)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("High availability tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	It("should not manage the replicas, or enable leader election, when HA is not enabled", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(deployment.Spec.Replicas).To(BeNil())
+		Expect(deployment.Spec.Template.Spec.Affinity).To(BeNil())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(BeEmpty())
+
+		cr.Spec.HA = &rolloutsmanagerv1alpha1.RolloutManagerHASpec{Replicas: 3}
+		Expect(generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})).To(Equal(deployment))
+	})
+
+	It("should run multiple replicas of the Rollouts controller, with leader election, spread across nodes, when HA is enabled", func() {
+		cr.Spec.HA = &rolloutsmanagerv1alpha1.RolloutManagerHASpec{Enabled: true}
+		cr.Spec.ExtraCommandArgs = []string{"--loglevel", "debug"}
+
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(*deployment.Spec.Replicas).To(Equal(DefaultRolloutsHAReplicas))
+		Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{"--leader-elect=true", "--loglevel", "debug"}))
+
+		affinity := deployment.Spec.Template.Spec.Affinity
+		Expect(affinity).ToNot(BeNil())
+		Expect(affinity.NodeAffinity).To(BeNil())
+		Expect(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(BeEmpty())
+		Expect(affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+		term := affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+		Expect(term.TopologyKey).To(Equal(corev1.LabelHostname))
+		Expect(term.LabelSelector.MatchLabels).To(Equal(deployment.Spec.Selector.MatchLabels))
+
+		By("verifying that the node affinity of the control plane is kept")
+		cr.Spec.HA.Replicas = 3
+		cr.Spec.RunOnControlPlane = true
+		deployment = generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+		Expect(deployment.Spec.Template.Spec.Affinity.NodeAffinity).To(Equal(getRolloutsAffinity(cr).NodeAffinity))
+		Expect(deployment.Spec.Template.Spec.Affinity.PodAntiAffinity).To(Equal(getRolloutsPodAntiAffinity(cr)))
+
+		By("verifying that the normalized form is consistent with the desired Deployment")
+		normalized, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalized).To(Equal(deployment))
+	})
+
+	DescribeTable("validateRolloutsHA", func(enabled bool, extraCommandArgs []string, expectedErr string) {
+		cr.Spec.HA = &rolloutsmanagerv1alpha1.RolloutManagerHASpec{Enabled: enabled}
+		cr.Spec.ExtraCommandArgs = extraCommandArgs
+
+		err := validateRolloutsHA(cr)
+		if expectedErr == "" {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(MatchError(expectedErr))
+		}
+	},
+		Entry("HA not enabled", false, []string{"--leader-elect=false"}, ""),
+		Entry("no extra command arguments", true, nil, ""),
+		Entry("leader election enabled", true, []string{"--leader-elect=true", "--leader-election-lease-duration=30s"}, ""),
+		Entry("leader election disabled", true, []string{"--leader-elect=false"},
+			"the leader election of the Rollouts controller must not be disabled via extraCommandArgs ('--leader-elect=false') when ha is enabled"),
+	)
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		})
+
+		It("should manage the replicas of the Rollouts controller Deployment while HA is enabled, and scale it back to a single replica once it is disabled", func() {
+			cr.Spec.HA = &rolloutsmanagerv1alpha1.RolloutManagerHASpec{Enabled: true, Replicas: 3}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--leader-elect=true"))
+
+			By("scaling the Deployment outside of the operator, and verifying that the replicas are restored")
+			replicas := int32(1)
+			deployment.Spec.Replicas = &replicas
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+
+			By("disabling HA")
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			cr.Spec.HA = nil
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+			Expect(deployment.Spec.Template.Spec.Affinity).To(BeNil())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Args).ToNot(ContainElement("--leader-elect=true"))
+		})
+
+		It("should not manage the replicas of the Rollouts controller Deployment when HA is not enabled", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			replicas := int32(0)
+			deployment.Spec.Replicas = &replicas
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(BeZero())
+		})
+
+		DescribeTable("should set the phase to Failure if the leader election of the Rollouts controller cannot be enabled", func(version string, argsOverrideMode rolloutsmanagerv1alpha1.ArgsOverrideMode, extraCommandArgs []string, expectedReason string) {
+			cr.Spec.HA = &rolloutsmanagerv1alpha1.RolloutManagerHASpec{Enabled: true}
+			cr.Spec.Version = version
+			cr.Spec.ArgsOverrideMode = argsOverrideMode
+			cr.Spec.ExtraCommandArgs = extraCommandArgs
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions[0].Reason).To(Equal(expectedReason))
+		},
+			Entry("version without leader election", "v1.1.0", rolloutsmanagerv1alpha1.ArgsOverrideModeAppend, nil,
+				rolloutsmanagerv1alpha1.RolloutManagerReasonUnsupportedCommandArgs),
+			Entry("leader election disabled via the extra command arguments", "", rolloutsmanagerv1alpha1.ArgsOverrideModeAppend, []string{"--leader-elect=false"},
+				rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidHA),
+			Entry("leader election disabled via the replaced command arguments", "", rolloutsmanagerv1alpha1.ArgsOverrideModeReplace, []string{"--leader-elect=false"},
+				rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidHA),
+		)
+	})
+})
@@ -0,0 +1,343 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// ImageVerificationTimeoutEnvName is an environment variable that can be used to set the timeout of requests to container registries, when verifying the Rollouts controller image, instead of the --image-verification-timeout flag.
+	ImageVerificationTimeoutEnvName = "IMAGE_VERIFICATION_TIMEOUT"
+
+	// DefaultImageVerificationTimeout is the default timeout of requests to container registries, when verifying the Rollouts controller image.
+	DefaultImageVerificationTimeout = 10 * time.Second
+
+	// dockerHubRegistry is the registry of images which do not name one, and dockerHubRegistryHost is the host which serves its API.
+	dockerHubRegistry     = "docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+)
+
+// ErrImageNotFound is returned (wrapped) by an ImageVerifier when the registry of an image reports that it does not exist.
+var ErrImageNotFound = errors.New("image not found")
+
+var authChallengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// manifestMediaTypes are the media types of the image manifests and indexes which are accepted when verifying an image, so that the registry does not report an existing image as missing because of its format.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// ImageVerifier verifies that a container image exists, before it is deployed.
+type ImageVerifier interface {
+	// VerifyImage returns an error wrapping ErrImageNotFound if the image does not exist. The pull secrets are the Secrets (of type kubernetes.io/dockerconfigjson or kubernetes.io/dockercfg) that the kubelet would use to pull the image.
+	VerifyImage(ctx context.Context, image string, pullSecrets []corev1.Secret) error
+}
+
+// RegistryImageVerifier is an ImageVerifier which requests the manifest of the image from its registry, via the Docker Registry HTTP API V2 (which is implemented by all OCI registries).
+type RegistryImageVerifier struct {
+	HTTPClient *http.Client
+}
+
+// NewRegistryImageVerifier returns a RegistryImageVerifier whose requests use the proxy of the operator (the HTTPS_PROXY and NO_PROXY environment variables), and time out after the given duration.
+func NewRegistryImageVerifier(timeout time.Duration) *RegistryImageVerifier {
+	return &RegistryImageVerifier{
+		HTTPClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// imageReference is a container image, split into its registry, repository, and tag or digest.
+type imageReference struct {
+	registry   string
+	repository string
+	reference  string
+}
+
+// parseImageReference splits the image into its registry, repository and reference, with the defaults of the container runtimes: images without a registry are pulled from Docker Hub, and images without a tag or digest use the 'latest' tag.
+func parseImageReference(image string) (imageReference, error) {
+
+	name, reference := image, "latest"
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		name, reference = image[:idx], image[idx+1:]
+	} else if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		name, reference = image[:idx], image[idx+1:]
+	}
+
+	res := imageReference{registry: dockerHubRegistry, repository: name, reference: reference}
+
+	// The first component of the name is a registry if it is a hostname, rather than a Docker Hub user or organization
+	if idx := strings.Index(name, "/"); idx >= 0 {
+		if host := name[:idx]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			res.registry, res.repository = host, name[idx+1:]
+		}
+	}
+
+	if res.registry == dockerHubRegistry && !strings.Contains(res.repository, "/") {
+		res.repository = "library/" + res.repository
+	}
+
+	if res.repository == "" || res.reference == "" {
+		return imageReference{}, fmt.Errorf("invalid image '%s'", image)
+	}
+
+	return res, nil
+}
+
+// registryHost returns the host which serves the API of the registry.
+func registryHost(registry string) string {
+	if registry == dockerHubRegistry {
+		return dockerHubRegistryHost
+	}
+	return registry
+}
+
+// normalizeRegistry returns the registry of a key of a Docker config file, which may be a URL (such as 'https://index.docker.io/v1/'), and uses one of the aliases of Docker Hub.
+func normalizeRegistry(key string) string {
+	registry := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	if idx := strings.Index(registry, "/"); idx >= 0 {
+		registry = registry[:idx]
+	}
+	switch registry {
+	case "index.docker.io", "registry.hub.docker.com", dockerHubRegistryHost:
+		return dockerHubRegistry
+	}
+	return registry
+}
+
+// registryCredentials are the username and password of a registry, from a pull secret.
+type registryCredentials struct {
+	username string
+	password string
+}
+
+// dockerConfigEntry is an entry of the 'auths' of a Docker config file.
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// findRegistryCredentials returns the credentials of the registry from the first pull secret which has an entry for it, or nil if there are none.
+func findRegistryCredentials(registry string, pullSecrets []corev1.Secret) *registryCredentials {
+
+	for _, secret := range pullSecrets {
+
+		auths := map[string]dockerConfigEntry{}
+		if data, exists := secret.Data[corev1.DockerConfigJsonKey]; exists {
+			var config struct {
+				Auths map[string]dockerConfigEntry `json:"auths"`
+			}
+			if err := json.Unmarshal(data, &config); err != nil {
+				log.Info(fmt.Sprintf("Ignoring image pull secret %s, as it is not a valid Docker config file", secret.Name))
+				continue
+			}
+			auths = config.Auths
+		} else if data, exists := secret.Data[corev1.DockerConfigKey]; exists {
+			if err := json.Unmarshal(data, &auths); err != nil {
+				log.Info(fmt.Sprintf("Ignoring image pull secret %s, as it is not a valid Docker config file", secret.Name))
+				continue
+			}
+		}
+
+		for key, entry := range auths {
+			if normalizeRegistry(key) != registry {
+				continue
+			}
+			if entry.Auth != "" {
+				if decoded, err := base64.StdEncoding.DecodeString(entry.Auth); err == nil {
+					if username, password, found := strings.Cut(string(decoded), ":"); found {
+						return &registryCredentials{username: username, password: password}
+					}
+				}
+			}
+			if entry.Username != "" {
+				return &registryCredentials{username: entry.Username, password: entry.Password}
+			}
+		}
+	}
+
+	return nil
+}
+
+// VerifyImage requests the manifest of the image from its registry, authenticating with the pull secrets if the registry requires it. Only a 404 response means that the image does not exist: registries such as Docker Hub deny access to repositories which do not exist, rather than reporting them as missing.
+func (v *RegistryImageVerifier) VerifyImage(ctx context.Context, image string, pullSecrets []corev1.Secret) error {
+
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return err
+	}
+
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registryHost(ref.registry), ref.repository, ref.reference)
+
+	statusCode, challenge, err := v.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return err
+	}
+
+	if statusCode == http.StatusUnauthorized {
+		authorization, err := v.authorize(ctx, challenge, ref, findRegistryCredentials(ref.registry, pullSecrets))
+		if err != nil {
+			return fmt.Errorf("unable to authenticate with registry %s: %w", ref.registry, err)
+		}
+		if statusCode, _, err = v.headManifest(ctx, manifestURL, authorization); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case statusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: registry %s reports that '%s' does not exist in repository %s", ErrImageNotFound, ref.registry, ref.reference, ref.repository)
+	case statusCode < 200 || statusCode > 299:
+		return fmt.Errorf("registry %s returned status %d for image %s", ref.registry, statusCode, image)
+	}
+
+	return nil
+}
+
+// headManifest requests the manifest of an image, and returns the status code of the response, and its authentication challenge, if any.
+func (v *RegistryImageVerifier) headManifest(ctx context.Context, manifestURL string, authorization string) (int, string, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, resp.Header.Get("WWW-Authenticate"), nil
+}
+
+// authorize returns the Authorization header for the authentication challenge of the registry: the credentials themselves for Basic authentication, or a token for the repository, requested from the token server of the registry, for Bearer authentication. Anonymous tokens are requested if there are no credentials.
+func (v *RegistryImageVerifier) authorize(ctx context.Context, challenge string, ref imageReference, credentials *registryCredentials) (string, error) {
+
+	scheme, _, _ := strings.Cut(challenge, " ")
+	params := map[string]string{}
+	for _, match := range authChallengeParamRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if credentials == nil {
+			return "", errors.New("the registry requires credentials, but no image pull secret has credentials for it")
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials.username+":"+credentials.password)), nil
+
+	case "bearer":
+		tokenURL, err := url.Parse(params["realm"])
+		if err != nil || tokenURL.Scheme == "" {
+			return "", fmt.Errorf("invalid token realm '%s'", params["realm"])
+		}
+		query := tokenURL.Query()
+		if service := params["service"]; service != "" {
+			query.Set("service", service)
+		}
+		query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.repository))
+		tokenURL.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		if credentials != nil {
+			req.SetBasicAuth(credentials.username, credentials.password)
+		}
+
+		resp, err := v.HTTPClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return "", fmt.Errorf("token server returned status %d", resp.StatusCode)
+		}
+
+		var tokenResponse struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokenResponse); err != nil {
+			return "", fmt.Errorf("invalid response of the token server: %w", err)
+		}
+		if tokenResponse.Token != "" {
+			return "Bearer " + tokenResponse.Token, nil
+		}
+		if tokenResponse.AccessToken != "" {
+			return "Bearer " + tokenResponse.AccessToken, nil
+		}
+		return "", errors.New("the token server did not return a token")
+	}
+
+	return "", fmt.Errorf("unsupported authentication scheme '%s'", scheme)
+}
+
+// verifyRolloutsImage verifies that the Rollouts controller image exists, via the ImageVerifier of the operator, if VerifyImage is set on the RolloutManager. Only an image which is not yet deployed is verified, so that the registry is only queried when the image changes.
+// It returns an error wrapping ErrImageNotFound if the image does not exist. Other failures of the verification (for example, if the registry is unreachable) are logged, and do not prevent the Deployment from being updated, as the kubelet may still be able to pull the image.
+func (r *RolloutManagerReconciler) verifyRolloutsImage(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, sa corev1.ServiceAccount) error {
+
+	if !cr.Spec.VerifyImage || r.ImageVerifier == nil || r.dryRun {
+		return nil
+	}
+
+	image := getRolloutsContainerImage(cr)
+
+	liveDeployment := &appsv1.Deployment{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, liveDeployment); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the Deployment %s: %w", DefaultArgoRolloutsResourceName, err)
+		}
+	} else {
+		for _, container := range liveDeployment.Spec.Template.Spec.Containers {
+			if container.Image == image {
+				return nil
+			}
+		}
+	}
+
+	var pullSecrets []corev1.Secret
+	for _, pullSecretRef := range sa.ImagePullSecrets {
+		secret := corev1.Secret{}
+		if err := fetchObject(ctx, r.Client, cr.Namespace, pullSecretRef.Name, &secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get the image pull secret %s: %w", pullSecretRef.Name, err)
+			}
+			continue
+		}
+		pullSecrets = append(pullSecrets, secret)
+	}
+
+	log.Info(fmt.Sprintf("Verifying that the Rollouts controller image %s exists", image))
+	if err := r.ImageVerifier.VerifyImage(ctx, image, pullSecrets); err != nil {
+		if errors.Is(err, ErrImageNotFound) {
+			return err
+		}
+		log.Info(fmt.Sprintf("Unable to verify that the Rollouts controller image %s exists, hence updating the Deployment regardless: %v", image, err))
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeImageVerifier is an ImageVerifier which reports the images of its list as missing, and records the images that it verified.
+type fakeImageVerifier struct {
+	missingImages  []string
+	verifiedImages []string
+}
+
+func (v *fakeImageVerifier) VerifyImage(ctx context.Context, image string, pullSecrets []corev1.Secret) error {
+	v.verifiedImages = append(v.verifiedImages, image)
+	for _, missingImage := range v.missingImages {
+		if image == missingImage {
+			return fmt.Errorf("%w: %s", ErrImageNotFound, image)
+		}
+	}
+	return nil
+}
+
+func makeTestPullSecret(name string, registry string, username string, password string) corev1.Secret {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths":{"%s":{"auth":"%s"}}}`, registry, auth)),
+		},
+	}
+}
+
+var _ = Describe("Image verification tests", func() {
+
+	It("parseImageReference should apply the defaults of the container runtimes", func() {
+		for image, expected := range map[string]imageReference{
+			"quay.io/argoproj/argo-rollouts:v1.7.0":       {registry: "quay.io", repository: "argoproj/argo-rollouts", reference: "v1.7.0"},
+			"quay.io/argoproj/argo-rollouts":              {registry: "quay.io", repository: "argoproj/argo-rollouts", reference: "latest"},
+			"quay.io/argoproj/argo-rollouts@sha256:1234":  {registry: "quay.io", repository: "argoproj/argo-rollouts", reference: "sha256:1234"},
+			"localhost:5000/argo-rollouts:v1.7.0":         {registry: "localhost:5000", repository: "argo-rollouts", reference: "v1.7.0"},
+			"argoproj/argo-rollouts:v1.7.0":               {registry: "docker.io", repository: "argoproj/argo-rollouts", reference: "v1.7.0"},
+			"argo-rollouts":                               {registry: "docker.io", repository: "library/argo-rollouts", reference: "latest"},
+			"registry.example.com:8443/team/rollouts:dev": {registry: "registry.example.com:8443", repository: "team/rollouts", reference: "dev"},
+		} {
+			Expect(parseImageReference(image)).To(Equal(expected), image)
+		}
+	})
+
+	It("findRegistryCredentials should find the credentials of the registry in the pull secrets", func() {
+		pullSecrets := []corev1.Secret{
+			makeTestPullSecret("other", "quay.io", "other", "other-password"),
+			makeTestPullSecret("docker-hub", "https://index.docker.io/v1/", "user", "password"),
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "legacy"},
+				Data:       map[string][]byte{corev1.DockerConfigKey: []byte(`{"registry.example.com":{"username":"legacy","password":"legacy-password"}}`)},
+			},
+		}
+
+		Expect(findRegistryCredentials("docker.io", pullSecrets)).To(Equal(&registryCredentials{username: "user", password: "password"}))
+		Expect(findRegistryCredentials("registry.example.com", pullSecrets)).To(Equal(&registryCredentials{username: "legacy", password: "legacy-password"}))
+		Expect(findRegistryCredentials("ghcr.io", pullSecrets)).To(BeNil())
+	})
+
+	Context("RegistryImageVerifier", func() {
+
+		var (
+			server   *httptest.Server
+			verifier *RegistryImageVerifier
+			registry string
+		)
+
+		BeforeEach(func() {
+			// A registry which requires a token, issued to 'user', to access the v1.7.0 tag of argoproj/argo-rollouts
+			mux := http.NewServeMux()
+			mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+				if username, password, ok := req.BasicAuth(); !ok || username != "user" || password != "password" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if req.URL.Query().Get("scope") != "repository:argoproj/argo-rollouts:pull" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				_, _ = w.Write([]byte(`{"token":"my-token"}`))
+			})
+			mux.HandleFunc("/v2/", func(w http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("Authorization") != "Bearer my-token" {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, server.URL))
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if req.URL.Path != "/v2/argoproj/argo-rollouts/manifests/v1.7.0" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			server = httptest.NewTLSServer(mux)
+			DeferCleanup(server.Close)
+
+			verifier = &RegistryImageVerifier{HTTPClient: server.Client()}
+			registry = strings.TrimPrefix(server.URL, "https://")
+		})
+
+		It("should verify an existing image, with the credentials of the pull secrets", func() {
+			pullSecrets := []corev1.Secret{makeTestPullSecret("registry", registry, "user", "password")}
+			Expect(verifier.VerifyImage(context.Background(), registry+"/argoproj/argo-rollouts:v1.7.0", pullSecrets)).To(Succeed())
+		})
+
+		It("should report an image which does not exist", func() {
+			pullSecrets := []corev1.Secret{makeTestPullSecret("registry", registry, "user", "password")}
+			err := verifier.VerifyImage(context.Background(), registry+"/argoproj/argo-rollouts:v9.9.9", pullSecrets)
+			Expect(errors.Is(err, ErrImageNotFound)).To(BeTrue())
+		})
+
+		It("should not report the image as missing if the credentials are rejected", func() {
+			err := verifier.VerifyImage(context.Background(), registry+"/argoproj/argo-rollouts:v9.9.9", nil)
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, ErrImageNotFound)).To(BeFalse())
+		})
+	})
+
+	Context("reconciliation", func() {
+
+		var (
+			ctx      context.Context
+			cr       rolloutsmanagerv1alpha1.RolloutManager
+			r        *RolloutManagerReconciler
+			req      reconcile.Request
+			verifier *fakeImageVerifier
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			cr = *makeTestRolloutManager()
+			cr.Spec.VerifyImage = true
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			verifier = &fakeImageVerifier{}
+			r.ImageVerifier = verifier
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+		})
+
+		It("should only verify the image when it changes", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(verifier.verifiedImages).To(Equal([]string{getRolloutsContainerImage(cr)}))
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(verifier.verifiedImages).To(HaveLen(1))
+		})
+
+		It("should set the phase to Failure, and keep the Deployment, if the image does not exist", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			previousImage := getRolloutsContainerImage(cr)
+			cr.Spec.Version = "v9.9.9"
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+			verifier.missingImages = []string{getRolloutsContainerImage(cr)}
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonImageNotFound))
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(previousImage))
+		})
+
+		It("should not verify the image unless it is requested", func() {
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			cr.Spec.VerifyImage = false
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(verifier.verifiedImages).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,121 @@
+package rollouts
+
+import (
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultInjectedContainerNames are the names of well-known containers that are added to pod templates by admission webhooks (Istio, Linkerd, Vault agent).
+var defaultInjectedContainerNames = []string{
+	"istio-proxy",
+	"linkerd-proxy",
+	"vault-agent",
+}
+
+// defaultInjectedVolumeNames are the names of well-known volumes that are added to pod templates by admission webhooks (Istio, Linkerd, Vault agent).
+var defaultInjectedVolumeNames = []string{
+	"istio-envoy",
+	"istio-data",
+	"istio-podinfo",
+	"istio-token",
+	"istiod-ca-cert",
+	"workload-socket",
+	"credential-socket",
+	"workload-certs",
+	"linkerd-proxy-init-xtables-lock",
+	"linkerd-identity-end-entity",
+	"linkerd-identity-token",
+	"home-init",
+	"home-sidecar",
+	"vault-secrets",
+}
+
+// getInjectedContainerNames returns the names of the containers that are injected by admission webhooks, and thus are not managed by the operator: the well-known containers, plus any specified in the RolloutManager.
+func getInjectedContainerNames(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	res := append([]string{}, defaultInjectedContainerNames...)
+	if cr.Spec.InjectedFields != nil {
+		res = append(res, cr.Spec.InjectedFields.Containers...)
+	}
+	return res
+}
+
+// getInjectedVolumeNames returns the names of the volumes that are injected by admission webhooks, and thus are not managed by the operator: the well-known volumes, plus any specified in the RolloutManager.
+func getInjectedVolumeNames(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	res := append([]string{}, defaultInjectedVolumeNames...)
+	if cr.Spec.InjectedFields != nil {
+		res = append(res, cr.Spec.InjectedFields.Volumes...)
+	}
+	return res
+}
+
+// ignoreInjectedResources returns true if the resource requests/limits of the Rollouts controller container may be modified outside of the operator (by an admission webhook, or by the VerticalPodAutoscaler).
+func ignoreInjectedResources(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return (cr.Spec.InjectedFields != nil && cr.Spec.InjectedFields.Resources) || isVPAManagingResources(cr)
+}
+
+// splitInjectedContainers splits the given containers into those that were not injected by an admission webhook, and those that were.
+func splitInjectedContainers(cr rolloutsmanagerv1alpha1.RolloutManager, containers []corev1.Container) ([]corev1.Container, []corev1.Container) {
+	injectedNames := getInjectedContainerNames(cr)
+
+	var others, injected []corev1.Container
+	for _, container := range containers {
+		if contains(injectedNames, container.Name) {
+			injected = append(injected, container)
+		} else {
+			others = append(others, container)
+		}
+	}
+	return others, injected
+}
+
+// splitInjectedVolumes splits the given volumes into those that were not injected by an admission webhook, and those that were.
+func splitInjectedVolumes(cr rolloutsmanagerv1alpha1.RolloutManager, volumes []corev1.Volume) ([]corev1.Volume, []corev1.Volume) {
+	injectedNames := getInjectedVolumeNames(cr)
+
+	var others, injected []corev1.Volume
+	for _, volume := range volumes {
+		if contains(injectedNames, volume.Name) {
+			injected = append(injected, volume)
+		} else {
+			others = append(others, volume)
+		}
+	}
+	return others, injected
+}
+
+// splitInjectedVolumeMounts splits the given volume mounts into those that do not mount an injected volume, and those that do.
+func splitInjectedVolumeMounts(cr rolloutsmanagerv1alpha1.RolloutManager, volumeMounts []corev1.VolumeMount) ([]corev1.VolumeMount, []corev1.VolumeMount) {
+	injectedNames := getInjectedVolumeNames(cr)
+
+	var others, injected []corev1.VolumeMount
+	for _, volumeMount := range volumeMounts {
+		if contains(injectedNames, volumeMount.Name) {
+			injected = append(injected, volumeMount)
+		} else {
+			others = append(others, volumeMount)
+		}
+	}
+	return others, injected
+}
+
+// preserveInjectedFields copies the fields of 'live' that were injected by admission webhooks into 'updated'. 'live' is the pod spec of the Deployment on the cluster, and 'updated' is the pod spec that will replace it.
+func preserveInjectedFields(cr rolloutsmanagerv1alpha1.RolloutManager, live corev1.PodSpec, updated *corev1.PodSpec) {
+
+	liveContainers, injectedContainers := splitInjectedContainers(cr, live.Containers)
+	_, injectedVolumes := splitInjectedVolumes(cr, live.Volumes)
+
+	// Copy the containers, to avoid modifying the slice of the desired Deployment
+	updated.Containers = append([]corev1.Container{}, updated.Containers...)
+
+	if len(liveContainers) == 1 && len(updated.Containers) == 1 {
+		_, injectedVolumeMounts := splitInjectedVolumeMounts(cr, liveContainers[0].VolumeMounts)
+		updated.Containers[0].VolumeMounts = append(append([]corev1.VolumeMount{}, updated.Containers[0].VolumeMounts...), injectedVolumeMounts...)
+
+		if ignoreInjectedResources(cr) {
+			updated.Containers[0].Resources = liveContainers[0].Resources
+		}
+	}
+
+	updated.Containers = append(updated.Containers, injectedContainers...)
+	updated.Volumes = append(append([]corev1.Volume{}, updated.Volumes...), injectedVolumes...)
+}
@@ -0,0 +1,172 @@
+package rollouts
+
+import (
+	"context"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Webhook-injected Deployment fields tests", func() {
+	var ctx context.Context
+	var a v1alpha1.RolloutManager
+	var r *RolloutManagerReconciler
+	var sa *corev1.ServiceAccount
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		a = *makeTestRolloutManager()
+
+		r = makeTestReconciler(&a)
+		Expect(createNamespace(r, a.Namespace)).To(Succeed())
+
+		sa = &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DefaultArgoRolloutsResourceName,
+				Namespace: a.Namespace,
+			},
+		}
+		Expect(r.Client.Create(ctx, sa)).To(Succeed())
+	})
+
+	// injectFields simulates an admission webhook that injects a container, a volume, and a volume mount into the Deployment
+	injectFields := func(containerName string, volumeName string) {
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+
+		podSpec := &deployment.Spec.Template.Spec
+		podSpec.Containers = append(podSpec.Containers, corev1.Container{Name: containerName, Image: "injected-image"})
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{Name: volumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: volumeName, MountPath: "/injected"})
+		podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{Name: containerName + "-init", Image: "injected-image"})
+
+		Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+	}
+
+	expectInjectedFieldsPreserved := func(containerName string, volumeName string) {
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.Containers).To(HaveLen(2))
+		Expect(podSpec.Containers[1].Name).To(Equal(containerName))
+		Expect(podSpec.Volumes).To(HaveLen(3))
+		Expect(podSpec.Volumes[2].Name).To(Equal(volumeName))
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: volumeName, MountPath: "/injected"}))
+		Expect(podSpec.InitContainers).To(HaveLen(1))
+	}
+
+	It("should not revert well-known injected containers, volumes and volume mounts", func() {
+		Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+		injectFields("vault-agent", "vault-secrets")
+
+		By("verifying the injected fields are ignored by normalizeDeployment")
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+
+		normalizedActual, err := normalizeDeployment(*deployment, a)
+		Expect(err).ToNot(HaveOccurred())
+		normalizedDesired, err := normalizeDeployment(generateDesiredRolloutsDeployment(a, *sa), a)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalizedActual.Spec).To(Equal(normalizedDesired.Spec))
+
+		Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+		expectInjectedFieldsPreserved("vault-agent", "vault-secrets")
+	})
+
+	It("should preserve containers and volumes from the RolloutManager's allowlist, when another field is reverted", func() {
+		a.Spec.InjectedFields = &v1alpha1.RolloutManagerInjectedFieldsSpec{
+			Containers: []string{"my-sidecar"},
+			Volumes:    []string{"my-volume"},
+		}
+
+		Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+		injectFields("my-sidecar", "my-volume")
+
+		By("modifying a field that is managed by the operator")
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		deployment.Spec.Template.Spec.ServiceAccountName = "other-service-account"
+		Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+		Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(Equal(sa.Name))
+		expectInjectedFieldsPreserved("my-sidecar", "my-volume")
+	})
+
+	It("should remove containers that are not in the allowlist", func() {
+		Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+		injectFields("unknown-sidecar", "unknown-volume")
+
+		Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+		Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(2))
+		Expect(deployment.Spec.Template.Spec.Containers[0].VolumeMounts).To(HaveLen(2))
+	})
+
+	When("the resources of the Rollouts controller container are modified outside of the operator", func() {
+
+		modifyResources := func() corev1.ResourceRequirements {
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+
+			modifiedResources := corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("250m"),
+				},
+			}
+			deployment.Spec.Template.Spec.Containers[0].Resources = modifiedResources
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+			return modifiedResources
+		}
+
+		It("should revert the resources by default", func() {
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+			modifyResources()
+
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Resources).To(Equal(defaultRolloutsContainerResources()))
+		})
+
+		It("should not revert the resources if .spec.injectedFields.resources is true, even when another field is reverted", func() {
+			a.Spec.InjectedFields = &v1alpha1.RolloutManagerInjectedFieldsSpec{
+				Resources: true,
+			}
+
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+			modifiedResources := modifyResources()
+
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Resources).To(Equal(modifiedResources))
+
+			By("modifying a field that is managed by the operator")
+			deployment.Spec.Template.Spec.Containers[0].Image = "my-image"
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(getRolloutsContainerImage(a)))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Resources).To(Equal(modifiedResources))
+		})
+	})
+})
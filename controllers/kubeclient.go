@@ -0,0 +1,54 @@
+package rollouts
+
+import (
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+)
+
+const (
+	// DefaultRolloutsKubeClientQPS is the default maximum number of queries per second of the Rollouts controller to the API server, as set by the Rollouts controller itself
+	DefaultRolloutsKubeClientQPS int32 = 40
+
+	// DefaultRolloutsKubeClientBurst is the default maximum burst of queries of the Rollouts controller to the API server, as set by the Rollouts controller itself
+	DefaultRolloutsKubeClientBurst int32 = 80
+)
+
+// getRolloutsKubeClientQPS returns the maximum number of queries per second of the Rollouts controller to the API server, from .spec.kubeClient.qps.
+func getRolloutsKubeClientQPS(cr rolloutsmanagerv1alpha1.RolloutManager) int32 {
+	if cr.Spec.KubeClient != nil && cr.Spec.KubeClient.QPS != 0 {
+		return cr.Spec.KubeClient.QPS
+	}
+	return DefaultRolloutsKubeClientQPS
+}
+
+// getRolloutsKubeClientBurst returns the maximum burst of queries of the Rollouts controller to the API server, from .spec.kubeClient.burst.
+func getRolloutsKubeClientBurst(cr rolloutsmanagerv1alpha1.RolloutManager) int32 {
+	if cr.Spec.KubeClient != nil && cr.Spec.KubeClient.Burst != 0 {
+		return cr.Spec.KubeClient.Burst
+	}
+	return DefaultRolloutsKubeClientBurst
+}
+
+// getRolloutsKubeClientArgs returns the command arguments which configure the client-side rate limit of the Rollouts controller. Arguments are only returned for the values which are set in the RolloutManager, so that the defaults of the Rollouts controller apply otherwise.
+func getRolloutsKubeClientArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	args := []string{}
+	if cr.Spec.KubeClient == nil {
+		return args
+	}
+	if cr.Spec.KubeClient.QPS != 0 {
+		args = append(args, fmt.Sprintf("--qps=%d", cr.Spec.KubeClient.QPS))
+	}
+	if cr.Spec.KubeClient.Burst != 0 {
+		args = append(args, fmt.Sprintf("--burst=%d", cr.Spec.KubeClient.Burst))
+	}
+	return args
+}
+
+// validateRolloutsKubeClient verifies that the burst of the Rollouts controller is not lower than its QPS (either of which may be the default of the Rollouts controller), as the burst would then limit the QPS.
+func validateRolloutsKubeClient(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+	if qps, burst := getRolloutsKubeClientQPS(cr), getRolloutsKubeClientBurst(cr); burst < qps {
+		return fmt.Errorf("the burst of the Rollouts controller (%d) must not be lower than its QPS (%d)", burst, qps)
+	}
+	return nil
+}
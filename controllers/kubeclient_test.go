@@ -0,0 +1,91 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Kube client rate limit tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	It("should only pass the QPS and burst which are set to the Rollouts controller", func() {
+		Expect(getRolloutsKubeClientArgs(cr)).To(BeEmpty())
+
+		cr.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{Burst: 200}
+		Expect(getRolloutsKubeClientArgs(cr)).To(Equal([]string{"--burst=200"}))
+
+		cr.Spec.KubeClient.QPS = 100
+		cr.Spec.ExtraCommandArgs = []string{"--loglevel", "debug"}
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{"--qps=100", "--burst=200", "--loglevel", "debug"}))
+	})
+
+	It("validateRolloutsKubeClient should reject a burst lower than the QPS, including the defaults of the Rollouts controller", func() {
+		Expect(validateRolloutsKubeClient(cr)).To(Succeed())
+
+		cr.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{QPS: 100, Burst: 200}
+		Expect(validateRolloutsKubeClient(cr)).To(Succeed())
+
+		cr.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{QPS: 100}
+		Expect(validateRolloutsKubeClient(cr)).To(MatchError(ContainSubstring("burst of the Rollouts controller (80) must not be lower than its QPS (100)")))
+
+		cr.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{Burst: 20}
+		Expect(validateRolloutsKubeClient(cr)).To(HaveOccurred())
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		})
+
+		It("should set the QPS and burst of the Rollouts controller Deployment", func() {
+			cr.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{QPS: 100, Burst: 200}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--qps=100", "--burst=200"))
+		})
+
+		It("should set the phase to Failure if the burst is lower than the QPS", func() {
+			cr.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{QPS: 100, Burst: 50}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidKubeClient))
+		})
+	})
+})
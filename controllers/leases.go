@@ -0,0 +1,143 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// RolloutsLeaderElectionLeaseName is the name of the Lease which the Rollouts controller acquires for leader election, in the namespace of its RolloutManager.
+const RolloutsLeaderElectionLeaseName = "argo-rollouts-controller-lock"
+
+// leaseRenewDeadline is the default renew deadline of the leader election of the Rollouts controller (--leader-election-renew-deadline): a leader which could not renew the Lease for that long stops leading.
+// A Lease which was renewed more recently is considered held by a live Rollouts controller pod, even if the pod is not (yet) known to the operator.
+const leaseRenewDeadline = 10 * time.Second
+
+// reconcileRolloutsLeaderElectionLease cleans up the leader election Lease of the Rollouts controller, which is created by the Rollouts controller rather than the operator:
+//   - the RolloutManager is added to the owners of the Lease, so that it is garbage collected along with the RolloutManager.
+//   - if the Lease is held by a pod which no longer runs (for example, after a scale down, or after the Rollouts controller Deployment was deleted), and was not renewed since, it is deleted, so that a new Rollouts controller pod does not wait for it to expire before it becomes the leader. The Lease held by a running replica (see RolloutManagerHASpec) is never deleted.
+func (r *RolloutManagerReconciler) reconcileRolloutsLeaderElectionLease(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if err := r.injectFault(reconcileStepLeaderElectionLease, cr); err != nil {
+		return err
+	}
+
+	lease := &coordinationv1.Lease{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, RolloutsLeaderElectionLeaseName, lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the Lease %s: %w", RolloutsLeaderElectionLeaseName, err)
+	}
+
+	stale, err := r.isLeaseHolderGone(ctx, lease)
+	if err != nil {
+		return err
+	}
+
+	if stale {
+		log.Info(fmt.Sprintf("Deleting Lease %s, as it is held by Rollouts controller pod %s, which no longer runs", RolloutsLeaderElectionLeaseName, *lease.Spec.HolderIdentity))
+		// The preconditions ensure that the Lease is not deleted if it was acquired by a new Rollouts controller pod in the meantime
+		if err := r.Client.Delete(ctx, lease, client.Preconditions{UID: &lease.UID, ResourceVersion: &lease.ResourceVersion}); err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+			return fmt.Errorf("failed to delete the Lease %s: %w", RolloutsLeaderElectionLeaseName, err)
+		}
+		return nil
+	}
+
+	ownerReferences := append([]metav1.OwnerReference{}, lease.OwnerReferences...)
+	if err := controllerutil.SetOwnerReference(&cr, lease, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set the owner reference of the Lease %s: %w", RolloutsLeaderElectionLeaseName, err)
+	}
+	if reflect.DeepEqual(ownerReferences, lease.OwnerReferences) {
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("Adding the owner reference of the RolloutManager to Lease %s", RolloutsLeaderElectionLeaseName))
+	if err := r.Client.Update(ctx, lease); err != nil && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to update the Lease %s: %w", RolloutsLeaderElectionLeaseName, err)
+	}
+	// On conflict, the Lease was renewed by the Rollouts controller: the owner reference is added on the next reconciliation
+
+	return nil
+}
+
+// isLeaseHolderGone returns true if the Lease is held by a Rollouts controller pod which no longer runs, and was not renewed within leaseRenewDeadline. A Lease which was released (without a holder) is not stale.
+func (r *RolloutManagerReconciler) isLeaseHolderGone(ctx context.Context, lease *coordinationv1.Lease) (bool, error) {
+
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		return false, nil
+	}
+	holder := getLeaseHolderHostname(*lease.Spec.HolderIdentity)
+
+	// A recently renewed Lease is held by a live leader, whose pod may not be in the cache of the operator yet
+	lastRenewTime := lease.Spec.RenewTime
+	if lastRenewTime == nil {
+		lastRenewTime = lease.Spec.AcquireTime
+	}
+	if lastRenewTime != nil && time.Since(lastRenewTime.Time) < leaseRenewDeadline {
+		return false, nil
+	}
+
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, client.InNamespace(lease.Namespace), client.MatchingLabels{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName}); err != nil {
+		return false, fmt.Errorf("failed to list the Rollouts controller pods: %w", err)
+	}
+
+	for _, pod := range pods.Items {
+		// A pod whose containers have all terminated can no longer renew the Lease
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		// The hostname of a pod is its name, unless it uses the network of its node
+		if pod.Name == holder || (pod.Spec.HostNetwork && pod.Spec.NodeName == holder) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// getLeaseHolderHostname returns the hostname of the holder of the leader election Lease of the Rollouts controller, whose identity is of the form '<hostname>_<uuid>'.
+func getLeaseHolderHostname(holderIdentity string) string {
+	if idx := strings.LastIndex(holderIdentity, "_"); idx > 0 {
+		return holderIdentity[:idx]
+	}
+	return holderIdentity
+}
+
+// controllerPodDeletedHandler returns a handler which informs the RolloutManagers of the namespace of a deleted Rollouts controller pod, so that the leader election Lease that it held is cleaned up (see reconcileRolloutsLeaderElectionLease).
+func (r *RolloutManagerReconciler) controllerPodDeletedHandler() handler.EventHandler {
+	return handler.Funcs{
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			pod, ok := e.Object.(*corev1.Pod)
+			if !ok || !isRolloutsControllerPod(pod) {
+				return
+			}
+
+			var rolloutManagers rolloutsmanagerv1alpha1.RolloutManagerList
+			if err := r.Client.List(ctx, &rolloutManagers, client.InNamespace(pod.Namespace)); err != nil {
+				log.Error(err, "unable to list RolloutManagers", "namespace", pod.Namespace)
+				return
+			}
+
+			for _, rolloutManager := range rolloutManagers.Items {
+				q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: rolloutManager.Namespace, Name: rolloutManager.Name}})
+			}
+		},
+	}
+}
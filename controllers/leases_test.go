@@ -0,0 +1,157 @@
+package rollouts
+
+import (
+	"context"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Leader election Lease tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+	})
+
+	createLease := func(holderIdentity string) *coordinationv1.Lease {
+		leaseDurationSeconds := int32(15)
+		lease := &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      RolloutsLeaderElectionLeaseName,
+				Namespace: cr.Namespace,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &holderIdentity,
+				LeaseDurationSeconds: &leaseDurationSeconds,
+			},
+		}
+		Expect(r.Client.Create(ctx, lease)).To(Succeed())
+		return lease
+	}
+
+	createControllerPod := func(name string, modify func(*corev1.Pod)) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cr.Namespace,
+				Labels:    map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName},
+			},
+		}
+		if modify != nil {
+			modify(pod)
+		}
+		Expect(r.Client.Create(ctx, pod)).To(Succeed())
+	}
+
+	It("should do nothing if the Rollouts controller did not create the Lease", func() {
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+	})
+
+	It("should delete the Lease if it is held by a pod which no longer exists", func() {
+		createControllerPod("argo-rollouts-5c8f7d9b6-new", nil)
+		lease := createLease("argo-rollouts-5c8f7d9b6-old_7a6b8a1e-3c0f-4f0e-9a3b-2d6a6d1f0b2c")
+
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, &coordinationv1.Lease{})).ToNot(Succeed())
+	})
+
+	It("should keep the Lease, and add the RolloutManager to its owners, if it is held by an existing pod", func() {
+		createControllerPod("argo-rollouts-5c8f7d9b6-abcde", nil)
+		lease := createLease("argo-rollouts-5c8f7d9b6-abcde_7a6b8a1e-3c0f-4f0e-9a3b-2d6a6d1f0b2c")
+
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, lease)).To(Succeed())
+		Expect(lease.OwnerReferences).To(HaveLen(1))
+		Expect(lease.OwnerReferences[0].Kind).To(Equal("RolloutManager"))
+		Expect(lease.OwnerReferences[0].Name).To(Equal(cr.Name))
+		Expect(lease.OwnerReferences[0].Controller).To(BeNil())
+
+		By("not updating the Lease again")
+		resourceVersion := lease.ResourceVersion
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, lease)).To(Succeed())
+		Expect(lease.ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should only delete the Lease of two replicas once its holder no longer runs, and has not renewed it", func() {
+		createControllerPod("argo-rollouts-5c8f7d9b6-abcde", nil)
+		createControllerPod("argo-rollouts-5c8f7d9b6-fghij", nil)
+		lease := createLease("argo-rollouts-5c8f7d9b6-abcde_7a6b8a1e-3c0f-4f0e-9a3b-2d6a6d1f0b2c")
+
+		setRenewTime := func(renewTime time.Time) {
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, lease)).To(Succeed())
+			lease.Spec.RenewTime = &metav1.MicroTime{Time: renewTime}
+			Expect(r.Client.Update(ctx, lease)).To(Succeed())
+		}
+
+		By("keeping the Lease held by the leader, even if it was not renewed recently, while the leader runs")
+		setRenewTime(time.Now().Add(-time.Minute))
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, lease)).To(Succeed())
+
+		By("keeping the Lease once the pod of the leader is gone, while the Lease was renewed recently")
+		Expect(r.Client.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "argo-rollouts-5c8f7d9b6-abcde", Namespace: cr.Namespace}})).To(Succeed())
+		setRenewTime(time.Now())
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, lease)).To(Succeed())
+
+		By("deleting the Lease once it was not renewed within the renew deadline, so that the other replica becomes the leader")
+		setRenewTime(time.Now().Add(-2 * leaseRenewDeadline))
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, &coordinationv1.Lease{})).ToNot(Succeed())
+
+		By("keeping the Lease acquired by the other replica")
+		lease = createLease("argo-rollouts-5c8f7d9b6-fghij_9c1d2e3f-3c0f-4f0e-9a3b-2d6a6d1f0b2c")
+		setRenewTime(time.Now().Add(-2 * leaseRenewDeadline))
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, lease)).To(Succeed())
+	})
+
+	It("should delete the Lease if the pod which holds it has terminated", func() {
+		createControllerPod("argo-rollouts-5c8f7d9b6-abcde", func(pod *corev1.Pod) {
+			pod.Status.Phase = corev1.PodFailed
+		})
+		lease := createLease("argo-rollouts-5c8f7d9b6-abcde_7a6b8a1e-3c0f-4f0e-9a3b-2d6a6d1f0b2c")
+
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, &coordinationv1.Lease{})).ToNot(Succeed())
+	})
+
+	It("should keep a Lease which was released", func() {
+		lease := createLease("")
+
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, lease)).To(Succeed())
+	})
+
+	It("should identify the holder of the Lease by its node, if the Rollouts controller uses host networking", func() {
+		createControllerPod("argo-rollouts-5c8f7d9b6-abcde", func(pod *corev1.Pod) {
+			pod.Spec.HostNetwork = true
+			pod.Spec.NodeName = "worker-1"
+		})
+		lease := createLease("worker-1_7a6b8a1e-3c0f-4f0e-9a3b-2d6a6d1f0b2c")
+
+		Expect(r.reconcileRolloutsLeaderElectionLease(ctx, cr)).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, lease.Name, lease)).To(Succeed())
+	})
+
+	It("getLeaseHolderHostname should remove the unique suffix of the identity of the holder", func() {
+		Expect(getLeaseHolderHostname("argo-rollouts-5c8f7d9b6-abcde_7a6b8a1e")).To(Equal("argo-rollouts-5c8f7d9b6-abcde"))
+		Expect(getLeaseHolderHostname("host_name_7a6b8a1e")).To(Equal("host_name"))
+		Expect(getLeaseHolderHostname("argo-rollouts")).To(Equal("argo-rollouts"))
+	})
+})
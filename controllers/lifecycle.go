@@ -0,0 +1,99 @@
+package rollouts
+
+import (
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultRolloutsContainerName is the name of the Rollouts controller container, unless it is overridden by .spec.containerName.
+const DefaultRolloutsContainerName = "argo-rollouts"
+
+// getRolloutsContainerName returns the name of the Rollouts controller container.
+func getRolloutsContainerName(cr rolloutsmanagerv1alpha1.RolloutManager) string {
+	if cr.Spec.ContainerName != "" {
+		return cr.Spec.ContainerName
+	}
+	return DefaultRolloutsContainerName
+}
+
+// rolloutsContainerLifecycle returns the lifecycle hooks of the Rollouts controller container: the hooks of .spec.lifecycle, with the preStop hook replaced by the PreStopCommand of the DisruptionAlerts of the RolloutManager, if specified.
+func rolloutsContainerLifecycle(cr rolloutsmanagerv1alpha1.RolloutManager) *corev1.Lifecycle {
+
+	var res *corev1.Lifecycle
+	if cr.Spec.Lifecycle != nil && (cr.Spec.Lifecycle.PostStart != nil || cr.Spec.Lifecycle.PreStop != nil) {
+		res = cr.Spec.Lifecycle.DeepCopy()
+	}
+
+	if cr.Spec.DisruptionAlerts != nil && len(cr.Spec.DisruptionAlerts.PreStopCommand) > 0 {
+		if res == nil {
+			res = &corev1.Lifecycle{}
+		}
+		res.PreStop = &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: cr.Spec.DisruptionAlerts.PreStopCommand,
+			},
+		}
+	}
+
+	return normalizeLifecycle(res)
+}
+
+// normalizeLifecycle returns the fields of the lifecycle hooks of the Rollouts controller container which are set by the operator (see rolloutsContainerLifecycle), with the defaults of the API server applied.
+func normalizeLifecycle(lifecycle *corev1.Lifecycle) *corev1.Lifecycle {
+
+	if lifecycle == nil {
+		return nil
+	}
+
+	res := &corev1.Lifecycle{
+		PostStart: normalizeLifecycleHandler(lifecycle.PostStart),
+		PreStop:   normalizeLifecycleHandler(lifecycle.PreStop),
+	}
+	if res.PostStart == nil && res.PreStop == nil {
+		return nil
+	}
+	return res
+}
+
+// normalizeLifecycleHandler returns the fields of a lifecycle hook which are set by the operator, or nil if the hook has no action.
+func normalizeLifecycleHandler(handler *corev1.LifecycleHandler) *corev1.LifecycleHandler {
+
+	if handler == nil {
+		return nil
+	}
+
+	res := &corev1.LifecycleHandler{}
+
+	if handler.Exec != nil {
+		res.Exec = &corev1.ExecAction{Command: handler.Exec.Command}
+	}
+
+	if handler.HTTPGet != nil {
+		res.HTTPGet = &corev1.HTTPGetAction{
+			Path:        handler.HTTPGet.Path,
+			Port:        handler.HTTPGet.Port,
+			Host:        handler.HTTPGet.Host,
+			Scheme:      handler.HTTPGet.Scheme,
+			HTTPHeaders: handler.HTTPGet.HTTPHeaders,
+		}
+		// The scheme is defaulted by the API server
+		if res.HTTPGet.Scheme == "" {
+			res.HTTPGet.Scheme = corev1.URISchemeHTTP
+		}
+		if len(res.HTTPGet.HTTPHeaders) == 0 {
+			res.HTTPGet.HTTPHeaders = nil
+		}
+	}
+
+	if handler.TCPSocket != nil {
+		res.TCPSocket = &corev1.TCPSocketAction{
+			Port: handler.TCPSocket.Port,
+			Host: handler.TCPSocket.Host,
+		}
+	}
+
+	if res.Exec == nil && res.HTTPGet == nil && res.TCPSocket == nil {
+		return nil
+	}
+	return res
+}
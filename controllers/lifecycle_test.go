@@ -0,0 +1,111 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Rollouts controller container name and lifecycle tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	It("should use the default container name and no lifecycle hooks if not specified", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+
+		Expect(deployment.Spec.Template.Spec.Containers[0].Name).To(Equal(DefaultRolloutsContainerName))
+		Expect(deployment.Spec.Template.Spec.Containers[0].Lifecycle).To(BeNil())
+
+		By("verifying that empty lifecycle hooks are ignored")
+		cr.Spec.Lifecycle = &corev1.Lifecycle{PostStart: &corev1.LifecycleHandler{}}
+		Expect(rolloutsContainerLifecycle(cr)).To(BeNil())
+	})
+
+	It("should override the container name and set the lifecycle hooks", func() {
+		cr.Spec.ContainerName = "controller"
+		cr.Spec.Lifecycle = &corev1.Lifecycle{
+			PostStart: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{Command: []string{"/bin/register"}},
+			},
+			PreStop: &corev1.LifecycleHandler{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/deregister", Port: intstr.FromString("healthz")},
+			},
+		}
+
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+
+		container := deployment.Spec.Template.Spec.Containers[0]
+		Expect(container.Name).To(Equal("controller"))
+		Expect(container.Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/register"}))
+		Expect(container.Lifecycle.PreStop.HTTPGet.Path).To(Equal("/deregister"))
+
+		By("verifying that the normalized form is consistent with the desired Deployment")
+		normalized, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalized).To(Equal(deployment))
+
+		By("verifying that the scheme defaulted by the API server is ignored")
+		Expect(container.Lifecycle.PreStop.HTTPGet.Scheme).To(Equal(corev1.URISchemeHTTP))
+		live := *deployment.DeepCopy()
+		live.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.HTTPGet.Scheme = corev1.URISchemeHTTP
+		normalizedLive, err := normalizeDeployment(live, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalizedLive).To(Equal(normalized))
+
+		By("verifying that the original spec is not modified")
+		Expect(cr.Spec.Lifecycle.PreStop.HTTPGet.Scheme).To(BeEmpty())
+	})
+
+	It("should give precedence to the preStop command of the disruption alerts", func() {
+		cr.Spec.Lifecycle = &corev1.Lifecycle{
+			PostStart: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/register"}}},
+			PreStop:   &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/deregister"}}},
+		}
+		cr.Spec.DisruptionAlerts = &rolloutsmanagerv1alpha1.RolloutManagerDisruptionAlertsSpec{PreStopCommand: []string{"/bin/alert"}}
+
+		Expect(rolloutsContainerLifecycle(cr)).To(Equal(&corev1.Lifecycle{
+			PostStart: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/register"}}},
+			PreStop:   &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/alert"}}},
+		}))
+		Expect(cr.Spec.Lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/deregister"}))
+	})
+
+	It("should restore the lifecycle hooks when they are removed from the Deployment", func() {
+		ctx := context.Background()
+		cr.Spec.Lifecycle = &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/deregister"}}},
+		}
+
+		r := makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		deployment.Spec.Template.Spec.Containers[0].Lifecycle = nil
+		Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/deregister"}))
+	})
+})
@@ -0,0 +1,116 @@
+package rollouts
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// metadataTemplateData contains the variables that can be used in the values of the AdditionalMetadata of a RolloutManager, such as '{{ .Name }}'.
+type metadataTemplateData struct {
+	// Name and Namespace are the name and namespace of the RolloutManager
+	Name      string
+	Namespace string
+
+	// Version is the tag of the Rollouts controller image (for example, 'v1.7.1'), or empty if the image is pinned by digest
+	Version string
+}
+
+func newMetadataTemplateData(cr rolloutsmanagerv1alpha1.RolloutManager) metadataTemplateData {
+
+	version := cr.Spec.Version
+	if version == "" {
+		image := getRolloutsContainerImage(cr)
+		if !strings.Contains(image, "@") {
+			// The tag follows the last colon, unless the colon is part of the registry host (for example, 'registry.example.com:5000/argo-rollouts')
+			if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+				version = image[idx+1:]
+			}
+		}
+	} else if strings.Contains(version, ":") {
+		// A digest was specified as the version
+		version = ""
+	}
+
+	return metadataTemplateData{
+		Name:      cr.Name,
+		Namespace: cr.Namespace,
+		Version:   version,
+	}
+}
+
+// expandMetadataTemplate returns the value with its template variables (see metadataTemplateData) substituted. Values without '{{' are returned unchanged.
+func expandMetadataTemplate(value string, data metadataTemplateData) (string, error) {
+
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// getAdditionalMetadata returns the labels and annotations of the AdditionalMetadata of the RolloutManager, with their template variables substituted. Values which cannot be expanded are returned unchanged: they are rejected by validateAdditionalMetadata before any resource is reconciled.
+func getAdditionalMetadata(cr rolloutsmanagerv1alpha1.RolloutManager) (map[string]string, map[string]string) {
+
+	labels, annotations := map[string]string{}, map[string]string{}
+
+	if cr.Spec.AdditionalMetadata == nil {
+		return labels, annotations
+	}
+
+	data := newMetadataTemplateData(cr)
+
+	for k, v := range cr.Spec.AdditionalMetadata.Labels {
+		if expanded, err := expandMetadataTemplate(v, data); err == nil {
+			v = expanded
+		}
+		labels[k] = v
+	}
+	for k, v := range cr.Spec.AdditionalMetadata.Annotations {
+		if expanded, err := expandMetadataTemplate(v, data); err == nil {
+			v = expanded
+		}
+		annotations[k] = v
+	}
+
+	return labels, annotations
+}
+
+// validateAdditionalMetadata verifies that the template variables in the values of the AdditionalMetadata of the RolloutManager can be expanded, and that the expanded label values are valid.
+func validateAdditionalMetadata(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if cr.Spec.AdditionalMetadata == nil {
+		return nil
+	}
+
+	data := newMetadataTemplateData(cr)
+
+	for k, v := range cr.Spec.AdditionalMetadata.Labels {
+		expanded, err := expandMetadataTemplate(v, data)
+		if err != nil {
+			return fmt.Errorf("the value of additional label '%s' is not a valid template: %w", k, err)
+		}
+		if errs := validation.IsValidLabelValue(expanded); len(errs) > 0 {
+			return fmt.Errorf("the value '%s' of additional label '%s' is invalid: %s", expanded, k, strings.Join(errs, "; "))
+		}
+	}
+	for k, v := range cr.Spec.AdditionalMetadata.Annotations {
+		if _, err := expandMetadataTemplate(v, data); err != nil {
+			return fmt.Errorf("the value of additional annotation '%s' is not a valid template: %w", k, err)
+		}
+	}
+
+	return nil
+}
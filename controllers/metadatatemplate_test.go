@@ -0,0 +1,91 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Additional metadata template tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+		cr.Spec.AdditionalMetadata = &rolloutsmanagerv1alpha1.ResourceMetadata{
+			Labels: map[string]string{
+				"monitoring.example.com/instance": "rollouts-{{ .Namespace }}",
+				"static":                          "value",
+			},
+			Annotations: map[string]string{
+				"external-dns.alpha.kubernetes.io/hostname": "{{ .Name }}.{{ .Namespace }}.example.com",
+				"example.com/version":                       "{{ .Version }}",
+			},
+		}
+	})
+
+	DescribeTable("newMetadataTemplateData should determine the version from the RolloutManager", func(image string, version string, expectedVersion string) {
+		cr.Spec.Image = image
+		cr.Spec.Version = version
+		Expect(newMetadataTemplateData(cr).Version).To(Equal(expectedVersion))
+	},
+		Entry("default image", "", "", DefaultArgoRolloutsVersion),
+		Entry("version", "", "v1.6.0", "v1.6.0"),
+		Entry("image with a registry port", "registry.example.com:5000/argo-rollouts", "", DefaultArgoRolloutsVersion),
+		Entry("digest", "quay.io/argoproj/argo-rollouts", "sha256:0123456789abcdef", ""),
+	)
+
+	It("should substitute the template variables in the labels and annotations of resources and pods", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+
+		for _, meta := range []map[string]string{deployment.Labels, deployment.Spec.Template.Labels} {
+			Expect(meta).To(HaveKeyWithValue("monitoring.example.com/instance", "rollouts-"+cr.Namespace))
+			Expect(meta).To(HaveKeyWithValue("static", "value"))
+		}
+		for _, meta := range []map[string]string{deployment.Annotations, deployment.Spec.Template.Annotations} {
+			Expect(meta).To(HaveKeyWithValue("external-dns.alpha.kubernetes.io/hostname", cr.Name+"."+cr.Namespace+".example.com"))
+			Expect(meta).To(HaveKeyWithValue("example.com/version", DefaultArgoRolloutsVersion))
+		}
+	})
+
+	DescribeTable("validateAdditionalMetadata should reject invalid templates", func(modify func(*rolloutsmanagerv1alpha1.ResourceMetadata), expectedError string) {
+		modify(cr.Spec.AdditionalMetadata)
+		Expect(validateAdditionalMetadata(cr)).To(MatchError(ContainSubstring(expectedError)))
+	},
+		Entry("syntax error", func(m *rolloutsmanagerv1alpha1.ResourceMetadata) { m.Annotations["a"] = "{{ .Name " }, "additional annotation 'a' is not a valid template"),
+		Entry("unknown variable", func(m *rolloutsmanagerv1alpha1.ResourceMetadata) { m.Labels["a"] = "{{ .Cluster }}" }, "additional label 'a' is not a valid template"),
+		Entry("invalid label value", func(m *rolloutsmanagerv1alpha1.ResourceMetadata) { m.Labels["a"] = "{{ .Name }}/{{ .Namespace }}" }, "of additional label 'a' is invalid"),
+	)
+
+	It("validateAdditionalMetadata should accept valid templates", func() {
+		Expect(validateAdditionalMetadata(cr)).To(Succeed())
+	})
+
+	It("should set the phase to Failure if a template is invalid", func() {
+		cr.Spec.AdditionalMetadata.Labels["invalid"] = "{{ .Unknown }}"
+
+		ctx := context.Background()
+		r := makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).ToNot(Succeed())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+		Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidAdditionalMetadata))
+	})
+})
@@ -0,0 +1,123 @@
+package rollouts
+
+import (
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// rolloutManagerAvailable reports whether each RolloutManager on the cluster is Available.
+// It is used by the RolloutManagerNotAvailable alert (see config/prometheus/rules.yaml): it intentionally has no phase/reason labels, since a new series (whenever the phase or reason changes) would restart how long the RolloutManager has been unavailable. The alert joins them from rolloutManagerStatusInfo instead.
+var rolloutManagerAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "argo_rollouts_manager_rolloutmanager_available",
+	Help: "Whether the RolloutManager is in the Available phase (1) or not (0)",
+}, []string{"namespace", "name"})
+
+// rolloutManagerStatusInfo reports the phase of each RolloutManager on the cluster, and the reason of its status condition.
+var rolloutManagerStatusInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "argo_rollouts_manager_rolloutmanager_status_info",
+	Help: "The phase of the RolloutManager, and the reason of its status condition, always 1",
+}, []string{"namespace", "name", "phase", "reason"})
+
+// rolloutManagerInfo reports the container images (and their version) that are deployed by each RolloutManager on the cluster, by component, so that the RolloutManagers of a fleet can be summarized without listing them in every namespace.
+var rolloutManagerInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "argo_rollouts_manager_rolloutmanager_info",
+	Help: "Information about the container images deployed by the RolloutManager, always 1",
+}, []string{"namespace", "name", "component", "image", "version"})
+
+// rolloutManagerLastAppliedTime reports when the spec of each RolloutManager was last applied successfully (see .status.lastAppliedTime), for example, when it was last upgraded.
+var rolloutManagerLastAppliedTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "argo_rollouts_manager_rolloutmanager_last_applied_timestamp_seconds",
+	Help: "The time at which the spec of the RolloutManager was last applied successfully, in seconds since the epoch",
+}, []string{"namespace", "name"})
+
+// rolloutManagerDriftCorrections counts the resources of each RolloutManager which were modified outside of the operator, and then reverted to their expected state by the operator.
+var rolloutManagerDriftCorrections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "argo_rollouts_manager_rolloutmanager_drift_corrections_total",
+	Help: "The number of times a resource of the RolloutManager was modified outside of the operator, and reverted to its expected state",
+}, []string{"namespace", "name", "kind"})
+
+// rolloutManagerRBACPermissionChanges counts the permissions which the operator added to, or removed from, the Roles/ClusterRoles of each RolloutManager, for example, after it was upgraded. See detectRBACRuleChanges.
+var rolloutManagerRBACPermissionChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "argo_rollouts_manager_rolloutmanager_rbac_permission_changes_total",
+	Help: "The number of permissions which the operator added to (change=added), or removed from (change=removed), the Roles/ClusterRoles of the RolloutManager",
+}, []string{"namespace", "name", "kind", "change"})
+
+// rolloutManagerConsecutiveFailures reports the number of consecutive failed reconciliations of each RolloutManager (see .status.failureCount), so that an alert can fire when a RolloutManager keeps failing.
+var rolloutManagerConsecutiveFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "argo_rollouts_manager_rolloutmanager_consecutive_failures",
+	Help: "The number of consecutive reconciliations of the RolloutManager that failed with an error, since the last successful one",
+}, []string{"namespace", "name"})
+
+func init() {
+	metrics.Registry.MustRegister(rolloutManagerAvailable, rolloutManagerStatusInfo, rolloutManagerInfo, rolloutManagerLastAppliedTime, rolloutManagerDriftCorrections, rolloutManagerRBACPermissionChanges, rolloutManagerConsecutiveFailures)
+}
+
+// updateRolloutManagerMetrics sets the metrics of the RolloutManager from its .status field.
+func updateRolloutManagerMetrics(rm rolloutsmanagerv1alpha1.RolloutManager) {
+
+	reason := ""
+	for _, condition := range rm.Status.Conditions {
+		if condition.Type == rolloutsmanagerv1alpha1.RolloutManagerConditionType {
+			reason = condition.Reason
+		}
+	}
+
+	available := 0.0
+	if rm.Status.Phase == rolloutsmanagerv1alpha1.PhaseAvailable {
+		available = 1.0
+	}
+
+	rolloutManagerAvailable.WithLabelValues(rm.Namespace, rm.Name).Set(available)
+
+	// The phase/reason (and image) labels may have changed since the last reconciliation, so remove the previous series first
+	labels := prometheus.Labels{"namespace": rm.Namespace, "name": rm.Name}
+	rolloutManagerStatusInfo.DeletePartialMatch(labels)
+	rolloutManagerInfo.DeletePartialMatch(labels)
+
+	rolloutManagerStatusInfo.WithLabelValues(rm.Namespace, rm.Name, string(rm.Status.Phase), reason).Set(1)
+
+	for _, relatedImage := range rm.Status.RelatedImages {
+		rolloutManagerInfo.WithLabelValues(rm.Namespace, rm.Name, relatedImage.Name, relatedImage.Image, getImageVersion(relatedImage.Image)).Set(1)
+	}
+
+	if rm.Status.LastAppliedTime != nil {
+		rolloutManagerLastAppliedTime.WithLabelValues(rm.Namespace, rm.Name).Set(float64(rm.Status.LastAppliedTime.Unix()))
+	}
+
+	rolloutManagerConsecutiveFailures.WithLabelValues(rm.Namespace, rm.Name).Set(float64(rm.Status.FailureCount))
+}
+
+// recordDriftCorrection counts a resource of the RolloutManager which was reverted to its expected state, after it was modified outside of the operator.
+func (r *RolloutManagerReconciler) recordDriftCorrection(rm rolloutsmanagerv1alpha1.RolloutManager, kind string) {
+	if r.dryRun {
+		return
+	}
+	rolloutManagerDriftCorrections.WithLabelValues(rm.Namespace, rm.Name, kind).Inc()
+}
+
+// getImageVersion returns the tag (or digest) of the container image, or "" if the image specifies neither.
+func getImageVersion(image string) string {
+	if _, digest, found := strings.Cut(image, "@"); found {
+		return digest
+	}
+	// A ':' before the last '/' separates the port of the registry, not the tag
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// deleteRolloutManagerMetrics removes the metrics of a RolloutManager that no longer exists.
+func deleteRolloutManagerMetrics(namespace string, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	rolloutManagerAvailable.DeletePartialMatch(labels)
+	rolloutManagerStatusInfo.DeletePartialMatch(labels)
+	rolloutManagerInfo.DeletePartialMatch(labels)
+	rolloutManagerLastAppliedTime.DeletePartialMatch(labels)
+	rolloutManagerDriftCorrections.DeletePartialMatch(labels)
+	rolloutManagerRBACPermissionChanges.DeletePartialMatch(labels)
+	rolloutManagerConsecutiveFailures.DeletePartialMatch(labels)
+}
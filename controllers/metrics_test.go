@@ -0,0 +1,143 @@
+package rollouts
+
+import (
+	"context"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("RolloutManager metrics tests", func() {
+	var a v1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		a = *makeTestRolloutManager()
+	})
+
+	AfterEach(func() {
+		deleteRolloutManagerMetrics(a.Namespace, a.Name)
+	})
+
+	// getStatusInfoMetric returns the value of the rolloutManagerStatusInfo metric with the given labels, and the number of series of the RolloutManager
+	getStatusInfoMetric := func(phase string, reason string) (float64, int) {
+		series := 0
+		ch := make(chan prometheus.Metric, 100)
+		rolloutManagerStatusInfo.Collect(ch)
+		close(ch)
+
+		value := -1.0
+		for m := range ch {
+			metric := &dto.Metric{}
+			Expect(m.Write(metric)).To(Succeed())
+
+			labels := map[string]string{}
+			for _, label := range metric.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] != a.Namespace || labels["name"] != a.Name {
+				continue
+			}
+			series++
+			if labels["phase"] == phase && labels["reason"] == reason {
+				value = metric.Gauge.GetValue()
+			}
+		}
+		return value, series
+	}
+
+	It("should report whether the RolloutManager is available, and the phase and reason of its status", func() {
+
+		By("setting a failed status")
+		a.Status.Phase = v1alpha1.PhaseFailure
+		a.Status.Conditions = []metav1.Condition{createCondition("error", v1alpha1.RolloutManagerReasonInvalidScoped)}
+		updateRolloutManagerMetrics(a)
+
+		Expect(testutil.ToFloat64(rolloutManagerAvailable.WithLabelValues(a.Namespace, a.Name))).To(Equal(0.0))
+		value, series := getStatusInfoMetric(string(v1alpha1.PhaseFailure), v1alpha1.RolloutManagerReasonInvalidScoped)
+		Expect(value).To(Equal(1.0))
+		Expect(series).To(Equal(1))
+
+		By("changing the reason of the failed status, which should replace the status series, but not the series of the available metric")
+		a.Status.Conditions = []metav1.Condition{createCondition("error", v1alpha1.RolloutManagerReasonErrorOccurred)}
+		updateRolloutManagerMetrics(a)
+
+		Expect(testutil.ToFloat64(rolloutManagerAvailable.WithLabelValues(a.Namespace, a.Name))).To(Equal(0.0))
+		value, series = getStatusInfoMetric(string(v1alpha1.PhaseFailure), v1alpha1.RolloutManagerReasonErrorOccurred)
+		Expect(value).To(Equal(1.0))
+		Expect(series).To(Equal(1))
+
+		By("setting an available status, which should replace the previous series")
+		a.Status.Phase = v1alpha1.PhaseAvailable
+		a.Status.Conditions = []metav1.Condition{createCondition("")}
+		updateRolloutManagerMetrics(a)
+
+		Expect(testutil.ToFloat64(rolloutManagerAvailable.WithLabelValues(a.Namespace, a.Name))).To(Equal(1.0))
+		value, series = getStatusInfoMetric(string(v1alpha1.PhaseAvailable), v1alpha1.RolloutManagerReasonSuccess)
+		Expect(value).To(Equal(1.0))
+		Expect(series).To(Equal(1))
+
+		By("deleting the metrics of the RolloutManager")
+		deleteRolloutManagerMetrics(a.Namespace, a.Name)
+		_, series = getStatusInfoMetric("", "")
+		Expect(series).To(Equal(0))
+		Expect(rolloutManagerAvailable.DeleteLabelValues(a.Namespace, a.Name)).To(BeFalse())
+	})
+
+	It("should report the images deployed by the RolloutManager, and when its spec was last applied", func() {
+		lastAppliedTime := metav1.Unix(1700000000, 0)
+		a.Status.RelatedImages = []v1alpha1.RelatedImage{{Name: DefaultArgoRolloutsResourceName, Image: "quay.io/argoproj/argo-rollouts:v1.6.0"}}
+		a.Status.LastAppliedTime = &lastAppliedTime
+		updateRolloutManagerMetrics(a)
+
+		Expect(testutil.ToFloat64(rolloutManagerInfo.WithLabelValues(a.Namespace, a.Name, DefaultArgoRolloutsResourceName, "quay.io/argoproj/argo-rollouts:v1.6.0", "v1.6.0"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(rolloutManagerLastAppliedTime.WithLabelValues(a.Namespace, a.Name))).To(Equal(1700000000.0))
+
+		By("replacing the series of the previous image once the RolloutManager is upgraded")
+		a.Status.RelatedImages[0].Image = "quay.io/argoproj/argo-rollouts:v1.7.0"
+		updateRolloutManagerMetrics(a)
+		Expect(testutil.ToFloat64(rolloutManagerInfo.WithLabelValues(a.Namespace, a.Name, DefaultArgoRolloutsResourceName, "quay.io/argoproj/argo-rollouts:v1.7.0", "v1.7.0"))).To(Equal(1.0))
+		Expect(rolloutManagerInfo.DeleteLabelValues(a.Namespace, a.Name, DefaultArgoRolloutsResourceName, "quay.io/argoproj/argo-rollouts:v1.6.0", "v1.6.0")).To(BeFalse())
+	})
+
+	It("should count the resources which were reverted to their expected state after being modified outside of the operator", func() {
+		ctx := context.Background()
+		r := makeTestReconciler(&a)
+		Expect(createNamespace(r, a.Namespace)).To(Succeed())
+
+		_, err := r.reconcileRolloutsRole(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+
+		role := &rbacv1.Role{}
+		Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, role)).To(Succeed())
+		role.Rules = nil
+		Expect(r.Client.Update(ctx, role)).To(Succeed())
+
+		By("not counting the updates of a dry run")
+		_, err = r.DetectDrift(ctx, client.ObjectKeyFromObject(&a))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(rolloutManagerDriftCorrections.WithLabelValues(a.Namespace, a.Name, "Role"))).To(Equal(0.0))
+
+		_, err = r.reconcileRolloutsRole(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(rolloutManagerDriftCorrections.WithLabelValues(a.Namespace, a.Name, "Role"))).To(Equal(1.0))
+
+		By("not counting the updates which follow a change of the expected state")
+		a.Spec.AdditionalMetadata = &v1alpha1.ResourceMetadata{Labels: map[string]string{"team": "rollouts"}}
+		_, err = r.reconcileRolloutsRole(ctx, a)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(rolloutManagerDriftCorrections.WithLabelValues(a.Namespace, a.Name, "Role"))).To(Equal(1.0))
+	})
+
+	It("getImageVersion should return the tag or the digest of the image", func() {
+		Expect(getImageVersion("quay.io/argoproj/argo-rollouts:v1.6.0")).To(Equal("v1.6.0"))
+		Expect(getImageVersion("registry:5000/argo-rollouts@sha256:abc")).To(Equal("sha256:abc"))
+		Expect(getImageVersion("registry:5000/argo-rollouts")).To(Equal(""))
+	})
+})
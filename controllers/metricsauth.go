@@ -0,0 +1,83 @@
+package rollouts
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+// WithMetricsAuthenticationAndAuthorization is a FilterProvider for the metrics server of the operator, which requires that requests carry a bearer token that is authenticated (via a TokenReview), and authorized to 'get' the requested path (via a SubjectAccessReview), by the Kubernetes API server. This is the same check that kube-rbac-proxy performs, so the same RBAC applies: the operator requires 'create' on tokenreviews and subjectaccessreviews, and clients require 'get' on the '/metrics' non-resource URL.
+func WithMetricsAuthenticationAndAuthorization(config *rest.Config, httpClient *http.Client) (metricsserver.Filter, error) {
+	clientset, err := kubernetes.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return newMetricsAuthFilter(clientset.AuthenticationV1(), clientset.AuthorizationV1()), nil
+}
+
+// newMetricsAuthFilter returns a metrics server Filter which authenticates and authorizes requests using the given clients. See WithMetricsAuthenticationAndAuthorization.
+func newMetricsAuthFilter(authenticationClient authenticationv1client.AuthenticationV1Interface, authorizationClient authorizationv1client.AuthorizationV1Interface) metricsserver.Filter {
+	return func(log logr.Logger, handler http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+
+			token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if !found || token == "" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			tokenReview, err := authenticationClient.TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+				Spec: authenticationv1.TokenReviewSpec{Token: token},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				log.Error(err, "unable to authenticate metrics request")
+				http.Error(w, "Authentication failed", http.StatusInternalServerError)
+				return
+			}
+			if !tokenReview.Status.Authenticated {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			user := tokenReview.Status.User
+			extra := map[string]authorizationv1.ExtraValue{}
+			for k, v := range user.Extra {
+				extra[k] = authorizationv1.ExtraValue(v)
+			}
+
+			subjectAccessReview, err := authorizationClient.SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					User:   user.Username,
+					Groups: user.Groups,
+					UID:    user.UID,
+					Extra:  extra,
+					NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+						Path: req.URL.Path,
+						Verb: "get",
+					},
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				log.Error(err, "unable to authorize metrics request")
+				http.Error(w, "Authorization failed", http.StatusInternalServerError)
+				return
+			}
+			if !subjectAccessReview.Status.Allowed {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			handler.ServeHTTP(w, req)
+		}), nil
+	}
+}
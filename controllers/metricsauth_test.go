@@ -0,0 +1,89 @@
+package rollouts
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Metrics authentication and authorization filter tests", func() {
+
+	const (
+		validToken     = "valid-token"
+		authorizedUser = "system:serviceaccount:monitoring:prometheus"
+	)
+
+	var (
+		handler              http.Handler
+		subjectAccessReviews []authorizationv1.SubjectAccessReview
+	)
+
+	BeforeEach(func() {
+		clientset := fake.NewSimpleClientset()
+
+		clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			tokenReview := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+			if tokenReview.Spec.Token == validToken {
+				tokenReview.Status.Authenticated = true
+				tokenReview.Status.User = authenticationv1.UserInfo{Username: authorizedUser, Groups: []string{"system:serviceaccounts"}}
+			}
+			return true, tokenReview, nil
+		})
+
+		subjectAccessReviews = nil
+		clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			subjectAccessReview := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			subjectAccessReviews = append(subjectAccessReviews, *subjectAccessReview)
+			subjectAccessReview.Status.Allowed = subjectAccessReview.Spec.User == authorizedUser && subjectAccessReview.Spec.NonResourceAttributes.Path == "/metrics"
+			return true, subjectAccessReview, nil
+		})
+
+		metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		var err error
+		handler, err = newMetricsAuthFilter(clientset.AuthenticationV1(), clientset.AuthorizationV1())(logr.Discard(), metricsHandler)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	request := func(path string, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	It("should reject requests without a bearer token", func() {
+		Expect(request("/metrics", "")).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject requests with a token that is not authenticated", func() {
+		Expect(request("/metrics", "invalid-token")).To(Equal(http.StatusUnauthorized))
+		Expect(subjectAccessReviews).To(BeEmpty())
+	})
+
+	It("should reject requests that are not authorized for the requested path", func() {
+		Expect(request("/debug/pprof", validToken)).To(Equal(http.StatusForbidden))
+	})
+
+	It("should serve requests that are authenticated and authorized", func() {
+		Expect(request("/metrics", validToken)).To(Equal(http.StatusOK))
+
+		Expect(subjectAccessReviews).To(HaveLen(1))
+		Expect(subjectAccessReviews[0].Spec.User).To(Equal(authorizedUser))
+		Expect(subjectAccessReviews[0].Spec.Groups).To(Equal([]string{"system:serviceaccounts"}))
+		Expect(subjectAccessReviews[0].Spec.NonResourceAttributes).To(Equal(&authorizationv1.NonResourceAttributes{Path: "/metrics", Verb: "get"}))
+	})
+})
@@ -0,0 +1,257 @@
+package rollouts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LifecycleWebhookURLsEnvName is an environment variable that can be used to set the comma-separated URLs of the lifecycle webhooks, instead of the --lifecycle-webhook-urls flag. See WebhookLifecycleNotifier.
+	LifecycleWebhookURLsEnvName = "LIFECYCLE_WEBHOOK_URLS"
+
+	// LifecycleWebhookCAFileEnvName is an environment variable that can be used to set the file containing the CA certificates of the lifecycle webhooks, instead of the --lifecycle-webhook-ca-file flag.
+	LifecycleWebhookCAFileEnvName = "LIFECYCLE_WEBHOOK_CA_FILE"
+
+	// LifecycleWebhookTimeoutEnvName is an environment variable that can be used to set the timeout of requests to the lifecycle webhooks, instead of the --lifecycle-webhook-timeout flag.
+	LifecycleWebhookTimeoutEnvName = "LIFECYCLE_WEBHOOK_TIMEOUT"
+
+	// DefaultLifecycleWebhookTimeout is the default timeout of requests to the lifecycle webhooks.
+	DefaultLifecycleWebhookTimeout = 10 * time.Second
+)
+
+// LifecycleEvent is a key event in the lifecycle of a RolloutManager, which is notified to external systems (for example, fleet automation or a CMDB).
+type LifecycleEvent string
+
+const (
+	// LifecycleEventCreated is notified when a new RolloutManager is reconciled for the first time.
+	LifecycleEventCreated LifecycleEvent = "created"
+
+	// LifecycleEventUpgraded is notified when the container images deployed for a RolloutManager change.
+	LifecycleEventUpgraded LifecycleEvent = "upgraded"
+
+	// LifecycleEventDegraded is notified when a RolloutManager which was Ready is no longer Ready.
+	LifecycleEventDegraded LifecycleEvent = "degraded"
+
+	// LifecycleEventDeleted is notified when a RolloutManager no longer exists.
+	LifecycleEventDeleted LifecycleEvent = "deleted"
+)
+
+// LifecycleNotification is the body of the requests sent to the lifecycle webhooks.
+type LifecycleNotification struct {
+	// Event is the lifecycle event of the RolloutManager.
+	Event LifecycleEvent `json:"event"`
+
+	// Time is the time at which the event was detected by the operator.
+	Time metav1.Time `json:"time"`
+
+	// RolloutManager is the RolloutManager of the event, as of the event.
+	RolloutManager LifecycleNotificationRolloutManager `json:"rolloutManager"`
+}
+
+// LifecycleNotificationRolloutManager describes the RolloutManager of a LifecycleNotification. Only the namespace and name are set for deleted RolloutManagers.
+type LifecycleNotificationRolloutManager struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels,omitempty"`
+
+	// Phase is the phase of the RolloutManager.
+	Phase rolloutsmanagerv1alpha1.RolloutControllerPhase `json:"phase,omitempty"`
+
+	// Reason and Message are those of the Ready condition of the RolloutManager.
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Images are the container images deployed for the RolloutManager, by component.
+	Images []rolloutsmanagerv1alpha1.RelatedImage `json:"images,omitempty"`
+
+	// PreviousImages are the container images that were deployed before an upgrade. They are only set for upgraded events.
+	PreviousImages []rolloutsmanagerv1alpha1.RelatedImage `json:"previousImages,omitempty"`
+}
+
+// LifecycleNotifier is notified of the lifecycle events of RolloutManagers. Notifications are best effort: a failure is logged, and does not fail (or retry) the reconciliation of the RolloutManager.
+type LifecycleNotifier interface {
+	Notify(ctx context.Context, notification LifecycleNotification) error
+}
+
+// WebhookLifecycleNotifier is a LifecycleNotifier which sends each LifecycleNotification to a list of HTTP(S) webhooks.
+type WebhookLifecycleNotifier struct {
+	URLs       []string
+	HTTPClient *http.Client
+}
+
+// NewWebhookLifecycleNotifier returns a WebhookLifecycleNotifier for the given URLs. If caFile is set, the certificates of the webhooks are verified against the CA certificates of the file, rather than those of the system.
+func NewWebhookLifecycleNotifier(urls []string, caFile string, timeout time.Duration) (*WebhookLifecycleNotifier, error) {
+
+	httpClient, err := newWebhookHTTPClient("lifecycle webhook", caFile, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookLifecycleNotifier{
+		URLs:       urls,
+		HTTPClient: httpClient,
+	}, nil
+}
+
+// ParseLifecycleWebhookURLs parses the comma-separated URLs of the lifecycle webhooks, from the --lifecycle-webhook-urls flag.
+func ParseLifecycleWebhookURLs(value string) ([]string, error) {
+
+	var res []string
+	for _, webhookURL := range strings.Split(value, ",") {
+		webhookURL = strings.TrimSpace(webhookURL)
+		if webhookURL == "" {
+			continue
+		}
+
+		parsed, err := url.Parse(webhookURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lifecycle webhook URL '%s': %w", webhookURL, err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid lifecycle webhook URL '%s': only absolute http and https URLs are supported", webhookURL)
+		}
+
+		res = append(res, webhookURL)
+	}
+
+	return res, nil
+}
+
+// Notify sends the notification to each webhook in turn, so that a failing webhook does not prevent the others from being notified. It returns the errors of all the webhooks which failed.
+func (n *WebhookLifecycleNotifier) Notify(ctx context.Context, notification LifecycleNotification) error {
+
+	requestBody, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, webhookURL := range n.URLs {
+		if err := n.send(ctx, webhookURL, requestBody); err != nil {
+			errs = append(errs, fmt.Errorf("lifecycle webhook %s: %w", webhookURL, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send posts the request body to a lifecycle webhook. Any 2xx status is a success.
+func (n *WebhookLifecycleNotifier) send(ctx context.Context, webhookURL string, requestBody []byte) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("returned status %d: %s", resp.StatusCode, string(responseBody))
+	}
+
+	return nil
+}
+
+// getLifecycleEvents returns the lifecycle events of the RolloutManager between its previous status, and its status as updated by the reconciliation.
+func getLifecycleEvents(previousStatus rolloutsmanagerv1alpha1.RolloutManagerStatus, rm rolloutsmanagerv1alpha1.RolloutManager) []LifecycleEvent {
+
+	// A RolloutManager without any status was not reconciled before
+	if previousStatus.ObservedGeneration == 0 && len(previousStatus.Conditions) == 0 {
+		return []LifecycleEvent{LifecycleEventCreated}
+	}
+
+	var res []LifecycleEvent
+
+	if len(previousStatus.RelatedImages) > 0 && len(rm.Status.RelatedImages) > 0 && !reflect.DeepEqual(previousStatus.RelatedImages, rm.Status.RelatedImages) {
+		res = append(res, LifecycleEventUpgraded)
+	}
+
+	if meta.IsStatusConditionTrue(previousStatus.Conditions, rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType) &&
+		!meta.IsStatusConditionTrue(rm.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType) {
+		res = append(res, LifecycleEventDegraded)
+	}
+
+	return res
+}
+
+// newLifecycleNotification returns the notification of a lifecycle event of the RolloutManager.
+func newLifecycleNotification(event LifecycleEvent, previousStatus rolloutsmanagerv1alpha1.RolloutManagerStatus, rm rolloutsmanagerv1alpha1.RolloutManager) LifecycleNotification {
+
+	notification := LifecycleNotification{
+		Event: event,
+		Time:  metav1.Now(),
+		RolloutManager: LifecycleNotificationRolloutManager{
+			Namespace: rm.Namespace,
+			Name:      rm.Name,
+			Labels:    rm.Labels,
+			Phase:     rm.Status.Phase,
+			Images:    rm.Status.RelatedImages,
+		},
+	}
+
+	if ready := meta.FindStatusCondition(rm.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType); ready != nil {
+		notification.RolloutManager.Reason = ready.Reason
+		notification.RolloutManager.Message = ready.Message
+	}
+
+	if event == LifecycleEventUpgraded {
+		notification.RolloutManager.PreviousImages = previousStatus.RelatedImages
+	}
+
+	return notification
+}
+
+// notifyLifecycleEvents notifies the LifecycleNotifier of the operator, if any, of the lifecycle events of the RolloutManager (see getLifecycleEvents), once its status was updated.
+func (r *RolloutManagerReconciler) notifyLifecycleEvents(ctx context.Context, previousStatus rolloutsmanagerv1alpha1.RolloutManagerStatus, rm rolloutsmanagerv1alpha1.RolloutManager) {
+
+	if r.LifecycleNotifier == nil || r.dryRun {
+		return
+	}
+
+	for _, event := range getLifecycleEvents(previousStatus, rm) {
+		r.notifyLifecycleEvent(ctx, newLifecycleNotification(event, previousStatus, rm))
+	}
+}
+
+// notifyRolloutManagerDeleted notifies the LifecycleNotifier of the operator, if any, that the RolloutManager no longer exists.
+func (r *RolloutManagerReconciler) notifyRolloutManagerDeleted(ctx context.Context, namespace string, name string) {
+
+	if r.LifecycleNotifier == nil || r.dryRun {
+		return
+	}
+
+	r.notifyLifecycleEvent(ctx, LifecycleNotification{
+		Event:          LifecycleEventDeleted,
+		Time:           metav1.Now(),
+		RolloutManager: LifecycleNotificationRolloutManager{Namespace: namespace, Name: name},
+	})
+}
+
+// notifyLifecycleEvent sends a notification to the LifecycleNotifier of the operator. Failures are only logged: the lifecycle events are not detected again, so the notification is not retried.
+func (r *RolloutManagerReconciler) notifyLifecycleEvent(ctx context.Context, notification LifecycleNotification) {
+
+	log.Info("Notifying lifecycle event of RolloutManager", "event", notification.Event, "namespace", notification.RolloutManager.Namespace, "name", notification.RolloutManager.Name)
+
+	if err := r.LifecycleNotifier.Notify(ctx, notification); err != nil {
+		log.Error(err, "unable to notify lifecycle event of RolloutManager", "event", notification.Event, "namespace", notification.RolloutManager.Namespace, "name", notification.RolloutManager.Name)
+	}
+}
@@ -0,0 +1,152 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Lifecycle notification tests", func() {
+
+	var (
+		ctx           context.Context
+		cr            rolloutsmanagerv1alpha1.RolloutManager
+		r             *RolloutManagerReconciler
+		req           reconcile.Request
+		mutex         sync.Mutex
+		notifications []LifecycleNotification
+		status        int
+		serverURL     string
+	)
+
+	receivedEvents := func() []LifecycleEvent {
+		mutex.Lock()
+		defer mutex.Unlock()
+		res := []LifecycleEvent{}
+		for _, notification := range notifications {
+			res = append(res, notification.Event)
+		}
+		return res
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+
+		notifications = nil
+		status = http.StatusNoContent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, httpReq *http.Request) {
+			defer GinkgoRecover()
+
+			var notification LifecycleNotification
+			Expect(json.NewDecoder(httpReq.Body).Decode(&notification)).To(Succeed())
+
+			mutex.Lock()
+			notifications = append(notifications, notification)
+			mutex.Unlock()
+
+			w.WriteHeader(status)
+		}))
+		DeferCleanup(server.Close)
+		serverURL = server.URL
+
+		notifier, err := NewWebhookLifecycleNotifier([]string{server.URL}, "", time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		r.LifecycleNotifier = notifier
+	})
+
+	It("should notify the webhook when a RolloutManager is created and deleted", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receivedEvents()).To(Equal([]LifecycleEvent{LifecycleEventCreated}))
+		Expect(notifications[0].RolloutManager.Namespace).To(Equal(cr.Namespace))
+		Expect(notifications[0].RolloutManager.Name).To(Equal(cr.Name))
+		Expect(notifications[0].RolloutManager.Images).ToNot(BeEmpty())
+
+		By("not notifying the webhook again when nothing changed")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receivedEvents()).To(HaveLen(1))
+
+		By("notifying the webhook once the RolloutManager is deleted")
+		Expect(r.Client.Delete(ctx, &cr)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receivedEvents()).To(Equal([]LifecycleEvent{LifecycleEventCreated, LifecycleEventDeleted}))
+	})
+
+	It("should not fail the reconciliation when the webhook fails, and notify the other webhooks", func() {
+		status = http.StatusInternalServerError
+
+		failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, httpReq *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		DeferCleanup(failingServer.Close)
+
+		notifier, err := NewWebhookLifecycleNotifier([]string{failingServer.URL, serverURL}, "", time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		r.LifecycleNotifier = notifier
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receivedEvents()).To(Equal([]LifecycleEvent{LifecycleEventCreated}))
+
+		err = notifier.Notify(ctx, LifecycleNotification{Event: LifecycleEventDegraded})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("status 503"))
+		Expect(err.Error()).To(ContainSubstring("status 500"))
+	})
+
+	It("getLifecycleEvents should detect upgrades and degradations of a RolloutManager", func() {
+		ready := metav1.Condition{Type: rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType, Status: metav1.ConditionTrue}
+		images := []rolloutsmanagerv1alpha1.RelatedImage{{Name: DefaultArgoRolloutsResourceName, Image: "quay.io/argoproj/argo-rollouts:v1.6.0"}}
+
+		previousStatus := rolloutsmanagerv1alpha1.RolloutManagerStatus{ObservedGeneration: 1, Conditions: []metav1.Condition{ready}, RelatedImages: images}
+		cr.Status = *previousStatus.DeepCopy()
+		Expect(getLifecycleEvents(previousStatus, cr)).To(BeEmpty())
+
+		cr.Status.RelatedImages = []rolloutsmanagerv1alpha1.RelatedImage{{Name: DefaultArgoRolloutsResourceName, Image: "quay.io/argoproj/argo-rollouts:v1.7.0"}}
+		cr.Status.Conditions[0].Status = metav1.ConditionFalse
+		Expect(getLifecycleEvents(previousStatus, cr)).To(Equal([]LifecycleEvent{LifecycleEventUpgraded, LifecycleEventDegraded}))
+
+		notification := newLifecycleNotification(LifecycleEventUpgraded, previousStatus, cr)
+		Expect(notification.RolloutManager.PreviousImages).To(Equal(images))
+		Expect(notification.RolloutManager.Images).To(Equal(cr.Status.RelatedImages))
+
+		Expect(getLifecycleEvents(rolloutsmanagerv1alpha1.RolloutManagerStatus{}, cr)).To(Equal([]LifecycleEvent{LifecycleEventCreated}))
+	})
+
+	It("ParseLifecycleWebhookURLs should only accept absolute http and https URLs", func() {
+		urls, err := ParseLifecycleWebhookURLs(" https://cmdb.example.com/hooks/rollouts, http://automation.platform.svc:8080/ ,")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(urls).To(Equal([]string{"https://cmdb.example.com/hooks/rollouts", "http://automation.platform.svc:8080/"}))
+
+		urls, err = ParseLifecycleWebhookURLs("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(urls).To(BeEmpty())
+
+		_, err = ParseLifecycleWebhookURLs("cmdb.example.com/hooks")
+		Expect(err).To(HaveOccurred())
+
+		_, err = ParseLifecycleWebhookURLs("ftp://cmdb.example.com/hooks")
+		Expect(err).To(HaveOccurred())
+	})
+})
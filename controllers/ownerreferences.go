@@ -0,0 +1,85 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// BlockOwnerDeletionEnvName is an environment variable that can be set to 'false', so that the owner references from the resources created by the operator to their RolloutManager do not set blockOwnerDeletion, instead of the --block-owner-deletion flag.
+const BlockOwnerDeletionEnvName = "BLOCK_OWNER_DELETION"
+
+// setControllerReference sets a controller owner reference from the object to the RolloutManager.
+//
+// The reference always sets 'controller', since the operator watches the resources that it owns via their controller reference, and uses it to adopt orphaned resources. It sets 'blockOwnerDeletion' unless DisableBlockOwnerDeletion is set: in that case the ownership is 'weak', and foreground deletion of the RolloutManager (or of its namespace) does not wait for its resources to be deleted first.
+func (r *RolloutManagerReconciler) setControllerReference(cr *rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error {
+	if err := controllerutil.SetControllerReference(cr, obj, r.Scheme); err != nil {
+		return err
+	}
+
+	if r.DisableBlockOwnerDeletion {
+		ownerReferences := obj.GetOwnerReferences()
+		for idx := range ownerReferences {
+			if ownerReferences[idx].UID == cr.UID {
+				ownerReferences[idx].BlockOwnerDeletion = nil
+			}
+		}
+		obj.SetOwnerReferences(ownerReferences)
+	}
+
+	return nil
+}
+
+// blocksOwnerDeletion returns true if the owner reference sets blockOwnerDeletion.
+func blocksOwnerDeletion(ownerReference metav1.OwnerReference) bool {
+	return ownerReference.BlockOwnerDeletion != nil && *ownerReference.BlockOwnerDeletion
+}
+
+// migrateOwnerReferences updates the owner references to the RolloutManager, of the resources in its namespace which were created by the operator, so that their blockOwnerDeletion matches the configuration of the operator (see setControllerReference).
+// This is required when the configuration changes, since the owner references are otherwise only set when the resources are created.
+func (r *RolloutManagerReconciler) migrateOwnerReferences(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	expectedBlockOwnerDeletion := !r.DisableBlockOwnerDeletion
+
+	for _, list := range adoptableResourceLists() {
+
+		// The component label differs between resources (e.g. the metrics Service), so only the part-of label is used
+		if err := r.Client.List(ctx, list, client.InNamespace(cr.Namespace), client.MatchingLabels{"app.kubernetes.io/part-of": DefaultArgoRolloutsResourceName}); err != nil {
+			return fmt.Errorf("failed to list %T: %w", list, err)
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+
+			obj, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+
+			owner := metav1.GetControllerOf(obj)
+			if owner == nil || owner.UID != cr.UID || blocksOwnerDeletion(*owner) == expectedBlockOwnerDeletion {
+				continue
+			}
+
+			if err := r.setControllerReference(&cr, obj); err != nil {
+				return err
+			}
+
+			log.Info("Updating blockOwnerDeletion of owner reference", "kind", fmt.Sprintf("%T", obj), "namespace", obj.GetNamespace(), "name", obj.GetName(), "blockOwnerDeletion", expectedBlockOwnerDeletion)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				return fmt.Errorf("failed to update owner reference of %s: %w", obj.GetName(), err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Owner reference tests", func() {
+	var (
+		ctx context.Context
+		rm  *rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+		req reconcile.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		rm = makeTestRolloutManager()
+		r = makeTestReconciler(rm)
+		Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}}
+	})
+
+	// expectOwnerReferences verifies the controller reference of the resources created by the operator
+	expectOwnerReferences := func(expectedBlockOwnerDeletion bool) {
+		for _, obj := range []client.Object{&corev1.ServiceAccount{}, &corev1.Service{}, &appsv1.Deployment{}} {
+			name := DefaultArgoRolloutsResourceName
+			if _, isService := obj.(*corev1.Service); isService {
+				name = DefaultArgoRolloutsMetricsServiceName
+			}
+			ExpectWithOffset(1, fetchObject(ctx, r.Client, rm.Namespace, name, obj)).To(Succeed())
+
+			owner := metav1.GetControllerOf(obj)
+			ExpectWithOffset(1, owner).ToNot(BeNil())
+			ExpectWithOffset(1, owner.Name).To(Equal(rm.Name))
+			ExpectWithOffset(1, blocksOwnerDeletion(*owner)).To(Equal(expectedBlockOwnerDeletion), "blockOwnerDeletion of %T", obj)
+		}
+	}
+
+	It("should set blockOwnerDeletion on the owner references by default", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		expectOwnerReferences(true)
+	})
+
+	It("should not set blockOwnerDeletion on the owner references if it is disabled", func() {
+		r.DisableBlockOwnerDeletion = true
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		expectOwnerReferences(false)
+	})
+
+	It("should migrate the owner references of existing resources when the configuration changes", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		expectOwnerReferences(true)
+
+		By("disabling blockOwnerDeletion")
+		r.DisableBlockOwnerDeletion = true
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		expectOwnerReferences(false)
+
+		By("enabling blockOwnerDeletion again")
+		r.DisableBlockOwnerDeletion = false
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		expectOwnerReferences(true)
+	})
+})
@@ -0,0 +1,232 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PodSecurityEnforceLabel is the namespace label which sets the Pod Security Standard that is enforced by the Pod Security admission controller on the pods of the namespace.
+	PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+	podSecurityLevelPrivileged = "privileged"
+	podSecurityLevelBaseline   = "baseline"
+	podSecurityLevelRestricted = "restricted"
+)
+
+// baselineCapabilities are the capabilities that may be added to containers by the 'baseline' Pod Security Standard.
+var baselineCapabilities = map[corev1.Capability]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
+// getPodSecurityLevel returns the Pod Security Standard enforced on the namespace, via its PodSecurityEnforceLabel: 'privileged', 'baseline' or 'restricted'.
+// If the namespace is not labeled, 'privileged' is returned: cluster-wide defaults of the Pod Security admission controller (set in its AdmissionConfiguration) are not visible to the operator.
+// If the cluster API is disabled, the namespace cannot be read, so 'privileged' is also returned: a Rollouts controller pod which is rejected by the Pod Security admission controller is reported by the ControllerDegraded condition.
+func (r *RolloutManagerReconciler) getPodSecurityLevel(ctx context.Context, namespace string) (string, error) {
+
+	if r.clusterAPIDisabled() {
+		return podSecurityLevelPrivileged, nil
+	}
+
+	ns, err := fetchObjectMetadata(ctx, r.Client, namespaceGVK, "", namespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return podSecurityLevelPrivileged, nil
+		}
+		return "", fmt.Errorf("failed to get the Namespace %s: %w", namespace, err)
+	}
+
+	switch level := ns.Labels[PodSecurityEnforceLabel]; level {
+	case podSecurityLevelBaseline, podSecurityLevelRestricted:
+		return level, nil
+	default:
+		return podSecurityLevelPrivileged, nil
+	}
+}
+
+// applyPodSecurityLevel modifies the pod spec of the Rollouts controller to comply with the Pod Security Standard enforced on its namespace.
+// The Rollouts controller container is always compliant with the 'restricted' standard, but the 'restricted' standard also requires a seccomp profile on containers injected by admission webhooks (for example, service mesh sidecars), so it is set at the pod level.
+func applyPodSecurityLevel(podSpec *corev1.PodSpec, level string) {
+
+	if level != podSecurityLevelRestricted {
+		return
+	}
+
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+
+	if podSpec.SecurityContext.SeccompProfile == nil {
+		podSpec.SecurityContext.SeccompProfile = &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		}
+	}
+}
+
+// detectPodSecurityViolations returns the reasons why the Rollouts controller pod, as generated for the RolloutManager, would not be admitted by the Pod Security admission controller, given the Pod Security Standard enforced on its namespace: for example, because of user-specified overrides.
+func (r *RolloutManagerReconciler) detectPodSecurityViolations(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) ([]string, error) {
+
+	level, err := r.getPodSecurityLevel(ctx, cr.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	podSpec := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{}).Spec.Template.Spec
+	applyPodSecurityLevel(&podSpec, level)
+
+	return podSecurityViolations(level, podSpec), nil
+}
+
+// podSecurityViolations checks the pod spec against the controls of the given Pod Security Standard (see https://kubernetes.io/docs/concepts/security/pod-security-standards/), and returns the reasons why it violates them, if any.
+func podSecurityViolations(level string, podSpec corev1.PodSpec) []string {
+
+	res := []string{}
+
+	if level != podSecurityLevelBaseline && level != podSecurityLevelRestricted {
+		return res
+	}
+
+	// Controls of the 'baseline' standard
+	if podSpec.HostNetwork {
+		res = append(res, "hostNetwork must not be set")
+	}
+	if podSpec.HostPID {
+		res = append(res, "hostPID must not be set")
+	}
+	if podSpec.HostIPC {
+		res = append(res, "hostIPC must not be set")
+	}
+	for _, volume := range podSpec.Volumes {
+		if volume.HostPath != nil {
+			res = append(res, fmt.Sprintf("volume '%s' must not be a hostPath volume", volume.Name))
+		}
+	}
+	podSecurityContext := podSpec.SecurityContext
+	if podSecurityContext == nil {
+		podSecurityContext = &corev1.PodSecurityContext{}
+	}
+	if podSecurityContext.SeccompProfile != nil && podSecurityContext.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		res = append(res, "the seccomp profile of the pod must not be Unconfined")
+	}
+
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+
+	for _, container := range containers {
+		prefix := fmt.Sprintf("container '%s': ", container.Name)
+
+		securityContext := container.SecurityContext
+		if securityContext == nil {
+			securityContext = &corev1.SecurityContext{}
+		}
+
+		if securityContext.Privileged != nil && *securityContext.Privileged {
+			res = append(res, prefix+"privileged must not be set")
+		}
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				res = append(res, fmt.Sprintf("%shostPort %d must not be set", prefix, port.HostPort))
+			}
+		}
+		if securityContext.SeccompProfile != nil && securityContext.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			res = append(res, prefix+"the seccomp profile must not be Unconfined")
+		}
+
+		var addedCapabilities, droppedCapabilities []corev1.Capability
+		if securityContext.Capabilities != nil {
+			addedCapabilities = securityContext.Capabilities.Add
+			droppedCapabilities = securityContext.Capabilities.Drop
+		}
+		for _, capability := range addedCapabilities {
+			if !baselineCapabilities[capability] || (level == podSecurityLevelRestricted && capability != "NET_BIND_SERVICE") {
+				res = append(res, fmt.Sprintf("%scapability %s must not be added", prefix, capability))
+			}
+		}
+
+		if level != podSecurityLevelRestricted {
+			continue
+		}
+
+		// Controls of the 'restricted' standard
+		if securityContext.AllowPrivilegeEscalation == nil || *securityContext.AllowPrivilegeEscalation {
+			res = append(res, prefix+"allowPrivilegeEscalation must be false")
+		}
+
+		runAsNonRoot := podSecurityContext.RunAsNonRoot
+		if securityContext.RunAsNonRoot != nil {
+			runAsNonRoot = securityContext.RunAsNonRoot
+		}
+		if runAsNonRoot == nil || !*runAsNonRoot {
+			res = append(res, prefix+"runAsNonRoot must be true")
+		}
+
+		runAsUser := podSecurityContext.RunAsUser
+		if securityContext.RunAsUser != nil {
+			runAsUser = securityContext.RunAsUser
+		}
+		if runAsUser != nil && *runAsUser == 0 {
+			res = append(res, prefix+"runAsUser must not be 0")
+		}
+
+		seccompProfile := podSecurityContext.SeccompProfile
+		if securityContext.SeccompProfile != nil {
+			seccompProfile = securityContext.SeccompProfile
+		}
+		if seccompProfile == nil || (seccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault && seccompProfile.Type != corev1.SeccompProfileTypeLocalhost) {
+			res = append(res, prefix+"the seccomp profile must be RuntimeDefault or Localhost")
+		}
+
+		dropsAll := false
+		for _, capability := range droppedCapabilities {
+			if capability == "ALL" {
+				dropsAll = true
+			}
+		}
+		if !dropsAll {
+			res = append(res, prefix+"capability ALL must be dropped")
+		}
+	}
+
+	if level == podSecurityLevelRestricted {
+		for _, volume := range podSpec.Volumes {
+			if !isRestrictedVolumeType(volume.VolumeSource) {
+				res = append(res, fmt.Sprintf("volume '%s' must be of a type allowed by the 'restricted' standard", volume.Name))
+			}
+		}
+	}
+
+	return res
+}
+
+// isRestrictedVolumeType returns true if the volume is of one of the types allowed by the 'restricted' Pod Security Standard.
+func isRestrictedVolumeType(volume corev1.VolumeSource) bool {
+	return volume.ConfigMap != nil || volume.CSI != nil || volume.DownwardAPI != nil || volume.EmptyDir != nil ||
+		volume.Ephemeral != nil || volume.PersistentVolumeClaim != nil || volume.Projected != nil || volume.Secret != nil
+}
+
+// createPodSecurityViolationCondition returns the PodSecurityViolation condition for the given violations.
+func createPodSecurityViolationCondition(podSecurityViolations []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerPodSecurityViolationConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonPodSecurityViolation,
+		Message: PodSecurityViolationMessage + strings.Join(podSecurityViolations, ", "),
+	}
+}
@@ -0,0 +1,147 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Pod Security tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+	})
+
+	// setPodSecurityLevel labels the namespace of the RolloutManager with the given Pod Security Standard
+	setPodSecurityLevel := func(level string) {
+		ns := &corev1.Namespace{}
+		if err := fetchObject(ctx, r.Client, "", cr.Namespace, ns); err != nil {
+			ExpectWithOffset(1, createNamespace(r, cr.Namespace)).To(Succeed())
+			ExpectWithOffset(1, fetchObject(ctx, r.Client, "", cr.Namespace, ns)).To(Succeed())
+		}
+		ns.Labels = map[string]string{PodSecurityEnforceLabel: level}
+		ExpectWithOffset(1, r.Client.Update(ctx, ns)).To(Succeed())
+	}
+
+	DescribeTable("getPodSecurityLevel should return the level enforced on the namespace", func(label string, expectedLevel string) {
+		setPodSecurityLevel(label)
+
+		level, err := r.getPodSecurityLevel(ctx, cr.Namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(level).To(Equal(expectedLevel))
+	},
+		Entry("restricted", "restricted", podSecurityLevelRestricted),
+		Entry("baseline", "baseline", podSecurityLevelBaseline),
+		Entry("privileged", "privileged", podSecurityLevelPrivileged),
+		Entry("not labeled", "", podSecurityLevelPrivileged),
+	)
+
+	It("should generate a Rollouts controller pod which complies with the restricted standard", func() {
+		podSpec := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{}).Spec.Template.Spec
+		applyPodSecurityLevel(&podSpec, podSecurityLevelRestricted)
+
+		Expect(podSpec.SecurityContext.SeccompProfile).To(Equal(&corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}))
+		Expect(podSecurityViolations(podSecurityLevelRestricted, podSpec)).To(BeEmpty())
+		Expect(podSecurityViolations(podSecurityLevelBaseline, podSpec)).To(BeEmpty())
+	})
+
+	It("podSecurityViolations should report the controls that are violated by the pod spec", func() {
+		podSpec := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{}).Spec.Template.Spec
+		podSpec.HostNetwork = true
+		podSpec.Containers[0].Ports[0].HostPort = 8090
+		podSpec.Containers[0].SecurityContext.Capabilities = &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN", "CHOWN"}}
+		podSpec.Containers[0].SecurityContext.AllowPrivilegeEscalation = nil
+
+		Expect(podSecurityViolations(podSecurityLevelPrivileged, podSpec)).To(BeEmpty())
+
+		Expect(podSecurityViolations(podSecurityLevelBaseline, podSpec)).To(Equal([]string{
+			"hostNetwork must not be set",
+			"container 'argo-rollouts': hostPort 8090 must not be set",
+			"container 'argo-rollouts': capability NET_ADMIN must not be added",
+		}))
+
+		Expect(podSecurityViolations(podSecurityLevelRestricted, podSpec)).To(Equal([]string{
+			"hostNetwork must not be set",
+			"container 'argo-rollouts': hostPort 8090 must not be set",
+			"container 'argo-rollouts': capability NET_ADMIN must not be added",
+			"container 'argo-rollouts': capability CHOWN must not be added",
+			"container 'argo-rollouts': allowPrivilegeEscalation must be false",
+			"container 'argo-rollouts': capability ALL must be dropped",
+		}))
+	})
+
+	It("should set and remove the PodSecurityViolation condition", func() {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+
+		rr := reconcileStatusResult{podSecurityViolations: []string{"hostNetwork must not be set"}}
+		Expect(updateStatusConditionOfRolloutManager(ctx, rr, &cr, r.Client, logf.Log)).To(Succeed())
+		Expect(cr.Status.Conditions).To(ContainElement(And(
+			HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerPodSecurityViolationConditionType),
+			HaveField("Status", metav1.ConditionTrue),
+			HaveField("Reason", rolloutsmanagerv1alpha1.RolloutManagerReasonPodSecurityViolation),
+			HaveField("Message", PodSecurityViolationMessage+"hostNetwork must not be set"),
+		)))
+
+		rr.podSecurityViolations = []string{}
+		Expect(updateStatusConditionOfRolloutManager(ctx, rr, &cr, r.Client, logf.Log)).To(Succeed())
+		Expect(cr.Status.Conditions).ToNot(ContainElement(HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerPodSecurityViolationConditionType)))
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+
+		var req reconcile.Request
+
+		BeforeEach(func() {
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+		})
+
+		It("should set the pod-level seccomp profile of the Rollouts controller in a restricted namespace, without reporting violations", func() {
+			setPodSecurityLevel(podSecurityLevelRestricted)
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.SecurityContext.SeccompProfile).To(Equal(&corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.Conditions).ToNot(ContainElement(HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerPodSecurityViolationConditionType)))
+
+			By("verifying that the Deployment is not updated again")
+			resourceVersion := deployment.ResourceVersion
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.ResourceVersion).To(Equal(resourceVersion))
+
+			By("removing the label from the namespace")
+			setPodSecurityLevel("")
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.SecurityContext.SeccompProfile).To(BeNil())
+		})
+	})
+})
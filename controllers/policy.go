@@ -0,0 +1,192 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// ResourcePolicyFileEnvName is an environment variable that can be used to set the file containing the resource policies of the operator, instead of the --resource-policy-file flag. See LoadResourcePolicies.
+const ResourcePolicyFileEnvName = "RESOURCE_POLICY_FILE"
+
+// ResourcePolicyFile is the content of the resource policy file of the operator.
+type ResourcePolicyFile struct {
+	Policies []ResourcePolicy `json:"policies"`
+}
+
+// ResourcePolicy is a set of rules that the resources rendered by the operator must satisfy. A resource which violates a rule of a policy is not applied, and the Degraded condition is set on its RolloutManager.
+type ResourcePolicy struct {
+	// Name identifies the policy in violations.
+	Name string `json:"name"`
+
+	// Kinds are the kinds of resources that the policy applies to (for example, 'Deployment'). If empty, the policy applies to all resources.
+	Kinds []string `json:"kinds,omitempty"`
+
+	// AllowedImageRegistries, if set, are the registries (optionally followed by a repository prefix, such as 'quay.io/argoproj') which the images of containers must be pulled from.
+	AllowedImageRegistries []string `json:"allowedImageRegistries,omitempty"`
+
+	// RequireResources, if true, requires that all containers set resource requests and limits.
+	RequireResources bool `json:"requireResources,omitempty"`
+
+	// RequiredLabels are the labels that resources must set.
+	RequiredLabels []string `json:"requiredLabels,omitempty"`
+}
+
+// LoadResourcePolicies reads and validates the resource policies of the operator from a YAML file, of the form:
+//
+//	policies:
+//	- name: approved-registries
+//	  allowedImageRegistries: ["registry.example.com"]
+//
+// This is called when the operator starts: an error is returned if the file is invalid.
+func LoadResourcePolicies(path string) ([]ResourcePolicy, error) {
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the resource policy file: %w", err)
+	}
+
+	var policyFile ResourcePolicyFile
+	if err := yaml.UnmarshalStrict(bytes, &policyFile); err != nil {
+		return nil, fmt.Errorf("failed to parse the resource policy file %s: %w", path, err)
+	}
+
+	if err := validateResourcePolicies(policyFile.Policies); err != nil {
+		return nil, fmt.Errorf("invalid resource policy file %s: %w", path, err)
+	}
+
+	return policyFile.Policies, nil
+}
+
+func validateResourcePolicies(policies []ResourcePolicy) error {
+
+	names := map[string]bool{}
+
+	for _, policy := range policies {
+		if policy.Name == "" {
+			return fmt.Errorf("a policy is missing a name")
+		}
+		if names[policy.Name] {
+			return fmt.Errorf("policy name '%s' is used more than once", policy.Name)
+		}
+		names[policy.Name] = true
+
+		if len(policy.AllowedImageRegistries) == 0 && !policy.RequireResources && len(policy.RequiredLabels) == 0 {
+			return fmt.Errorf("policy '%s' has no rules", policy.Name)
+		}
+		for _, registry := range policy.AllowedImageRegistries {
+			if registry == "" || strings.HasSuffix(registry, "/") {
+				return fmt.Errorf("policy '%s' has an invalid allowed image registry '%s'", policy.Name, registry)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resourcePolicyViolationError is returned when resources rendered for a RolloutManager violate the resource policies of the operator.
+type resourcePolicyViolationError struct {
+	violations []string
+}
+
+func (e *resourcePolicyViolationError) Error() string {
+	return "resources violate the resource policies of the operator: " + strings.Join(e.violations, ", ")
+}
+
+// checkResourcePolicies returns a resourcePolicyViolationError if the resource rendered by the operator violates the ResourcePolicies of the operator.
+func (r *RolloutManagerReconciler) checkResourcePolicies(obj client.Object) error {
+
+	if len(r.ResourcePolicies) == 0 {
+		return nil
+	}
+
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := apiutil.GVKForObject(obj, r.Scheme); err == nil {
+		kind = gvk.Kind
+	}
+
+	violations := []string{}
+	for _, policy := range r.ResourcePolicies {
+		for _, violation := range policy.check(kind, obj) {
+			violations = append(violations, fmt.Sprintf("%s: %s %s: %s", policy.Name, kind, obj.GetName(), violation))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &resourcePolicyViolationError{violations: violations}
+	}
+	return nil
+}
+
+// check returns the rules of the policy that are violated by the resource, if it applies to its kind.
+func (policy ResourcePolicy) check(kind string, obj client.Object) []string {
+
+	res := []string{}
+
+	if len(policy.Kinds) > 0 && !contains(policy.Kinds, kind) {
+		return res
+	}
+
+	for _, label := range policy.RequiredLabels {
+		if _, exists := obj.GetLabels()[label]; !exists {
+			res = append(res, fmt.Sprintf("label '%s' must be set", label))
+		}
+	}
+
+	var podSpec *corev1.PodSpec
+	if deployment, ok := obj.(*appsv1.Deployment); ok {
+		podSpec = &deployment.Spec.Template.Spec
+	}
+	if podSpec == nil {
+		return res
+	}
+
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range containers {
+		if len(policy.AllowedImageRegistries) > 0 && !isImageFromRegistries(container.Image, policy.AllowedImageRegistries) {
+			res = append(res, fmt.Sprintf("image '%s' of container '%s' must be from one of the registries %s", container.Image, container.Name, strings.Join(policy.AllowedImageRegistries, ", ")))
+		}
+		if policy.RequireResources && (len(container.Resources.Requests) == 0 || len(container.Resources.Limits) == 0) {
+			res = append(res, fmt.Sprintf("container '%s' must set resource requests and limits", container.Name))
+		}
+	}
+
+	return res
+}
+
+// isImageFromRegistries returns true if the image is pulled from one of the registries (or repository prefixes) of the list.
+func isImageFromRegistries(image string, registries []string) bool {
+	for _, registry := range registries {
+		if strings.HasPrefix(image, registry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareResource applies the ResourceTransformer of the operator, if any, to a resource rendered for the RolloutManager, then checks it against the ResourcePolicies of the operator. It is called before the resource is compared with the live resource and applied.
+func (r *RolloutManagerReconciler) prepareResource(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error {
+	if err := r.transformResource(ctx, cr, obj); err != nil {
+		return err
+	}
+	return r.checkResourcePolicies(obj)
+}
+
+// createDegradedCondition returns the Degraded condition for the given policy violations.
+func createDegradedCondition(policyViolations []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerDegradedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonPolicyViolation,
+		Message: PolicyViolationMessage + strings.Join(policyViolations, ", "),
+	}
+}
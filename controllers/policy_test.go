@@ -0,0 +1,133 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Resource policy tests", func() {
+
+	Context("LoadResourcePolicies", func() {
+
+		writePolicyFile := func(content string) string {
+			path := filepath.Join(GinkgoT().TempDir(), "policies.yaml")
+			Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+			return path
+		}
+
+		It("should load valid policies", func() {
+			policies, err := LoadResourcePolicies(writePolicyFile(`
+policies:
+- name: approved-registries
+  allowedImageRegistries: ["registry.example.com", "quay.io/argoproj"]
+- name: team-label
+  kinds: ["Deployment"]
+  requiredLabels: ["example.com/team"]
+`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policies).To(Equal([]ResourcePolicy{
+				{Name: "approved-registries", AllowedImageRegistries: []string{"registry.example.com", "quay.io/argoproj"}},
+				{Name: "team-label", Kinds: []string{"Deployment"}, RequiredLabels: []string{"example.com/team"}},
+			}))
+		})
+
+		DescribeTable("should reject invalid policies", func(content string, expectedError string) {
+			_, err := LoadResourcePolicies(writePolicyFile(content))
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+			Entry("unknown field", "policies:\n- name: a\n  requireResource: true\n", "unknown field"),
+			Entry("missing name", "policies:\n- requireResources: true\n", "missing a name"),
+			Entry("duplicate name", "policies:\n- name: a\n  requireResources: true\n- name: a\n  requireResources: true\n", "used more than once"),
+			Entry("no rules", "policies:\n- name: a\n", "has no rules"),
+			Entry("invalid registry", "policies:\n- name: a\n  allowedImageRegistries: [\"quay.io/\"]\n", "invalid allowed image registry"),
+		)
+	})
+
+	It("ResourcePolicy.check should return the violated rules, for the kinds of the policy", func() {
+		deployment := generateDesiredRolloutsDeployment(*makeTestRolloutManager(), corev1.ServiceAccount{})
+		deployment.Spec.Template.Spec.Containers[0].Image = "docker.io/argoproj/argo-rollouts:v1.7.2"
+
+		policy := ResourcePolicy{
+			Name:                   "p",
+			Kinds:                  []string{"Deployment"},
+			AllowedImageRegistries: []string{"quay.io/argoproj"},
+			RequireResources:       true,
+			RequiredLabels:         []string{"example.com/team"},
+		}
+		Expect(policy.check("Deployment", &deployment)).To(Equal([]string{
+			"label 'example.com/team' must be set",
+			"image 'docker.io/argoproj/argo-rollouts:v1.7.2' of container 'argo-rollouts' must be from one of the registries quay.io/argoproj",
+			"container 'argo-rollouts' must set resource requests and limits",
+		}))
+
+		Expect(policy.check("ServiceAccount", &corev1.ServiceAccount{})).To(BeEmpty())
+
+		By("verifying that a registry must match up to a path separator")
+		Expect(isImageFromRegistries("quay.io/argoproj/argo-rollouts:v1.7.2", []string{"quay.io/argoproj"})).To(BeTrue())
+		Expect(isImageFromRegistries("quay.io/argoproj-fork/argo-rollouts:v1.7.2", []string{"quay.io/argoproj"})).To(BeFalse())
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			cr  rolloutsmanagerv1alpha1.RolloutManager
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			cr = *makeTestRolloutManager()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+
+			r.ResourcePolicies = []ResourcePolicy{{Name: "approved-registries", Kinds: []string{"Deployment"}, AllowedImageRegistries: []string{"registry.example.com"}}}
+		})
+
+		It("should not apply resources which violate the policies, and set the Degraded condition until they are resolved", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).ToNot(Succeed())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			degraded := meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerDegradedConditionType)
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonPolicyViolation))
+			Expect(degraded.Message).To(ContainSubstring("approved-registries: Deployment argo-rollouts: image"))
+
+			reconciled := meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerConditionType)
+			Expect(reconciled.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonPolicyViolation))
+
+			By("resolving the violation")
+			cr.Spec.Image = "registry.example.com/argoproj/argo-rollouts"
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).To(Succeed())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerDegradedConditionType)).To(BeNil())
+		})
+	})
+})
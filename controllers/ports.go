@@ -0,0 +1,93 @@
+package rollouts
+
+import (
+	"fmt"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// DefaultRolloutsHealthzPort is the default port of the health check endpoint of the Rollouts controller
+	DefaultRolloutsHealthzPort int32 = 8080
+
+	// DefaultRolloutsMetricsPort is the default port of the metrics endpoint of the Rollouts controller
+	DefaultRolloutsMetricsPort int32 = 8090
+
+	// DefaultRolloutsMetricsPortName is the default name of the metrics port of the metrics Service, which is scraped by the ServiceMonitor
+	DefaultRolloutsMetricsPortName = "metrics"
+)
+
+// getRolloutsHealthzPort returns the port of the health check endpoint of the Rollouts controller, from .spec.ports.healthz.
+func getRolloutsHealthzPort(cr rolloutsmanagerv1alpha1.RolloutManager) int32 {
+	if cr.Spec.Ports != nil && cr.Spec.Ports.Healthz != 0 {
+		return cr.Spec.Ports.Healthz
+	}
+	return DefaultRolloutsHealthzPort
+}
+
+// getRolloutsMetricsPort returns the port of the metrics endpoint of the Rollouts controller, from .spec.ports.metrics.
+func getRolloutsMetricsPort(cr rolloutsmanagerv1alpha1.RolloutManager) int32 {
+	if cr.Spec.Ports != nil && cr.Spec.Ports.Metrics != 0 {
+		return cr.Spec.Ports.Metrics
+	}
+	return DefaultRolloutsMetricsPort
+}
+
+// getRolloutsMetricsPortName returns the name of the metrics port of the metrics Service, which is scraped by the ServiceMonitor, from .spec.monitoring.portName. The metrics port of the Rollouts controller container keeps its name.
+func getRolloutsMetricsPortName(cr rolloutsmanagerv1alpha1.RolloutManager) string {
+	if cr.Spec.Monitoring != nil && cr.Spec.Monitoring.PortName != "" {
+		return cr.Spec.Monitoring.PortName
+	}
+	return DefaultRolloutsMetricsPortName
+}
+
+// getRolloutsPortArgs returns the command arguments which configure the Rollouts controller to serve its health checks and metrics on the ports of the RolloutManager. No arguments are returned for the default ports.
+func getRolloutsPortArgs(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+	args := []string{}
+	if port := getRolloutsHealthzPort(cr); port != DefaultRolloutsHealthzPort {
+		args = append(args, fmt.Sprintf("--healthzPort=%d", port))
+	}
+	if port := getRolloutsMetricsPort(cr); port != DefaultRolloutsMetricsPort {
+		args = append(args, fmt.Sprintf("--metricsport=%d", port))
+	}
+	return args
+}
+
+// getRolloutsDNSPolicy returns the DNS policy of the Rollouts controller pod: pods on host networking need ClusterFirstWithHostNet to resolve cluster Services. Otherwise, the default DNS policy is used.
+func getRolloutsDNSPolicy(cr rolloutsmanagerv1alpha1.RolloutManager) corev1.DNSPolicy {
+	if cr.Spec.HostNetwork {
+		return corev1.DNSClusterFirstWithHostNet
+	}
+	return ""
+}
+
+// validateRolloutsPorts verifies that the health check and metrics endpoints of the Rollouts controller use different ports, that the extra ports are valid, and that the name of the metrics port of the metrics Service is valid.
+func validateRolloutsPorts(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+	if healthzPort, metricsPort := getRolloutsHealthzPort(cr), getRolloutsMetricsPort(cr); healthzPort == metricsPort {
+		return fmt.Errorf("the healthz and metrics ports of the Rollouts controller must be different, but both are %d", healthzPort)
+	}
+	if err := validateRolloutsExtraPorts(cr); err != nil {
+		return err
+	}
+	return validateRolloutsMetricsPortName(cr)
+}
+
+// validateRolloutsMetricsPortName verifies that the name of the metrics port of the metrics Service is a valid port name, which is not used by an extra port exposed via the metrics Service.
+func validateRolloutsMetricsPortName(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	portName := getRolloutsMetricsPortName(cr)
+	if errs := validation.IsValidPortName(portName); len(errs) > 0 {
+		return fmt.Errorf("invalid name of the metrics port '%s': %s", portName, strings.Join(errs, ", "))
+	}
+
+	for _, port := range getExtraServicePorts(cr, rolloutsmanagerv1alpha1.ExtraPortExposeMetricsService) {
+		if port.Name == portName {
+			return fmt.Errorf("the name '%s' of the metrics port is already used by an extra port exposed via the metrics Service", portName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Host network and port tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	It("should use the default ports and pod network if not specified", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.HostNetwork).To(BeFalse())
+		Expect(podSpec.DNSPolicy).To(BeEmpty())
+		Expect(podSpec.Containers[0].Ports).To(Equal([]corev1.ContainerPort{
+			{ContainerPort: 8080, Name: "healthz"},
+			{ContainerPort: 8090, Name: "metrics"},
+		}))
+		Expect(podSpec.Containers[0].Args).To(BeEmpty())
+	})
+
+	It("should set host networking and remap the ports of the Rollouts controller", func() {
+		cr.Spec.HostNetwork = true
+		cr.Spec.Ports = &rolloutsmanagerv1alpha1.RolloutManagerPortsSpec{Healthz: 18080, Metrics: 18090}
+		cr.Spec.ExtraCommandArgs = []string{"--loglevel", "debug"}
+
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.HostNetwork).To(BeTrue())
+		Expect(podSpec.DNSPolicy).To(Equal(corev1.DNSClusterFirstWithHostNet))
+
+		container := podSpec.Containers[0]
+		Expect(container.Ports).To(Equal([]corev1.ContainerPort{
+			{ContainerPort: 18080, Name: "healthz"},
+			{ContainerPort: 18090, Name: "metrics"},
+		}))
+		Expect(container.Args).To(Equal([]string{"--healthzPort=18080", "--metricsport=18090", "--loglevel", "debug"}))
+
+		By("verifying that the probes follow the remapped ports, via their names")
+		Expect(container.LivenessProbe.HTTPGet.Port).To(Equal(intstr.FromString("healthz")))
+		Expect(container.ReadinessProbe.HTTPGet.Port).To(Equal(intstr.FromString("metrics")))
+
+		By("verifying that the normalized form is consistent with the desired Deployment")
+		normalized, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalized).To(Equal(deployment))
+
+		By("verifying that the host ports defaulted by the API server are ignored")
+		live := *deployment.DeepCopy()
+		live.Spec.Template.Spec.Containers[0].Ports[0].HostPort = 18080
+		live.Spec.Template.Spec.Containers[0].Ports[1].HostPort = 18090
+		normalizedLive, err := normalizeDeployment(live, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalizedLive).To(Equal(normalized))
+	})
+
+	It("should ignore the DNS policy defaulted by the API server", func() {
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		normalized, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+		normalizedLive, err := normalizeDeployment(deployment, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalizedLive).To(Equal(normalized))
+	})
+
+	It("validateRolloutsPorts should reject identical healthz and metrics ports", func() {
+		Expect(validateRolloutsPorts(cr)).To(Succeed())
+
+		cr.Spec.Ports = &rolloutsmanagerv1alpha1.RolloutManagerPortsSpec{Metrics: 8080}
+		Expect(validateRolloutsPorts(cr)).To(MatchError(ContainSubstring("must be different, but both are 8080")))
+	})
+
+	DescribeTable("validateRolloutsPorts should validate the name of the metrics port", func(portName string, expectedErr string) {
+		cr.Spec.Monitoring = &rolloutsmanagerv1alpha1.RolloutManagerMonitoringSpec{PortName: portName}
+		cr.Spec.ExtraPorts = []rolloutsmanagerv1alpha1.RolloutManagerExtraPort{
+			{Name: "plugin", ContainerPort: 9000, Expose: rolloutsmanagerv1alpha1.ExtraPortExposeMetricsService},
+			{Name: "debug", ContainerPort: 6060, Expose: rolloutsmanagerv1alpha1.ExtraPortExposeService},
+		}
+
+		err := validateRolloutsPorts(cr)
+		if expectedErr == "" {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		}
+	},
+		Entry("default name", "", ""),
+		Entry("custom name", "https-metrics", ""),
+		Entry("name of an extra port which is not exposed via the metrics Service", "debug", ""),
+		Entry("invalid name", "metrics--x", "invalid name of the metrics port 'metrics--x'"),
+		Entry("name of an extra port exposed via the metrics Service", "plugin", "the name 'plugin' of the metrics port is already used by an extra port exposed via the metrics Service"),
+	)
+
+	It("should name the metrics port of the ServiceMonitor endpoints from .spec.monitoring.portName", func() {
+		Expect(rolloutsMetricsEndpoints(cr)[0].Port).To(Equal(DefaultRolloutsMetricsPortName))
+
+		cr.Spec.Monitoring = &rolloutsmanagerv1alpha1.RolloutManagerMonitoringSpec{PortName: "https-metrics"}
+		Expect(rolloutsMetricsEndpoints(cr)[0].Port).To(Equal("https-metrics"))
+
+		By("verifying that the metrics port of the container keeps its name")
+		deployment := generateDesiredRolloutsDeployment(cr, corev1.ServiceAccount{})
+		Expect(deployment.Spec.Template.Spec.Containers[0].Ports[1].Name).To(Equal("metrics"))
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+		})
+
+		It("should update the Deployment and the target port of the metrics Service when host networking and ports are enabled", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			cr.Spec.HostNetwork = true
+			cr.Spec.Ports = &rolloutsmanagerv1alpha1.RolloutManagerPortsSpec{Metrics: 18090}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.HostNetwork).To(BeTrue())
+			Expect(deployment.Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSClusterFirstWithHostNet))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Ports[1].ContainerPort).To(Equal(int32(18090)))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--metricsport=18090"))
+
+			service := &corev1.Service{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(8090)))
+			Expect(service.Spec.Ports[0].TargetPort).To(Equal(intstr.FromInt(18090)))
+		})
+
+		It("should rename the metrics port of the metrics Service and the ServiceMonitor when .spec.monitoring.portName changes", func() {
+			Expect(r.Client.Create(ctx, &crdv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: serviceMonitorsCRDName}})).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			service := &corev1.Service{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Name).To(Equal(DefaultRolloutsMetricsPortName))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			cr.Spec.Monitoring = &rolloutsmanagerv1alpha1.RolloutManagerMonitoringSpec{PortName: "https-metrics"}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Name).To(Equal("https-metrics"))
+			Expect(service.Spec.Ports[0].TargetPort).To(Equal(intstr.FromInt(8090)))
+
+			serviceMonitor := &monitoringv1.ServiceMonitor{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, serviceMonitor)).To(Succeed())
+			Expect(serviceMonitor.Spec.Endpoints).To(HaveLen(1))
+			Expect(serviceMonitor.Spec.Endpoints[0].Port).To(Equal("https-metrics"))
+		})
+
+		It("should set the phase to Failure if the ports are invalid", func() {
+			cr.Spec.Ports = &rolloutsmanagerv1alpha1.RolloutManagerPortsSpec{Healthz: 9000, Metrics: 9000}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidPorts))
+		})
+	})
+})
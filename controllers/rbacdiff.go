@@ -0,0 +1,207 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The snapshot of the permissions of the previous release, and their diff with the permissions of this release (see GetRBACPolicyRules), are generated by 'make rbac-diff'.
+//go:generate go run ../hack/rbac-diff -previous ../hack/rbac-diff/previous-release.json -output ../docs/rbac_changes.md
+
+const (
+	// RBACRulesChangedMessage is the prefix of the message of the RBACRulesChanged condition, which is followed by the permissions that were added to and removed from each Role/ClusterRole.
+	RBACRulesChangedMessage = "The operator changed the permissions granted by these Roles/ClusterRoles: "
+
+	// EventReasonRBACRulesChanged is the reason of Events recorded when the operator changes the permissions granted by a Role or ClusterRole of a RolloutManager.
+	EventReasonRBACRulesChanged = "RBACRulesChanged"
+)
+
+// GetRBACPolicyRules returns the PolicyRules of each Role/ClusterRole which the operator creates, by name: the Role/ClusterRole of the Rollouts controller, the aggregate ClusterRoles, and the rollout-user Role.
+func GetRBACPolicyRules() map[string][]rbacv1.PolicyRule {
+	return map[string][]rbacv1.PolicyRule{
+		DefaultArgoRolloutsResourceName:                         GetPolicyRules(),
+		DefaultArgoRolloutsResourceName + "-aggregate-to-admin": GetAggregateToAdminPolicyRules(),
+		DefaultArgoRolloutsResourceName + "-aggregate-to-edit":  GetAggregateToEditPolicyRules(),
+		DefaultArgoRolloutsResourceName + "-aggregate-to-view":  GetAggregateToViewPolicyRules(),
+		DefaultRolloutUserRoleName:                              GetRolloutUserPolicyRules(),
+	}
+}
+
+// GetPolicyRulesPermissions returns the permissions granted by the PolicyRules (see describeAccessReview), sorted and without duplicates, so that the permissions of two versions of the rules can be compared regardless of how they are grouped into rules.
+func GetPolicyRulesPermissions(rules []rbacv1.PolicyRule) []string {
+
+	permissions := map[string]bool{}
+	for _, rule := range rules {
+		for _, spec := range getPolicyRuleAccessReviews("", rule) {
+			permissions[describeAccessReview(spec)] = true
+		}
+	}
+
+	res := make([]string, 0, len(permissions))
+	for permission := range permissions {
+		res = append(res, permission)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// DiffPermissions returns the permissions which are in current but not in previous (added), and those which are in previous but not in current (removed). Both lists are sorted.
+func DiffPermissions(previous []string, current []string) (added []string, removed []string) {
+
+	previousSet := map[string]bool{}
+	for _, permission := range previous {
+		previousSet[permission] = true
+	}
+	currentSet := map[string]bool{}
+	for _, permission := range current {
+		currentSet[permission] = true
+	}
+
+	added, removed = []string{}, []string{}
+	for permission := range currentSet {
+		if !previousSet[permission] {
+			added = append(added, permission)
+		}
+	}
+	for permission := range previousSet {
+		if !currentSet[permission] {
+			removed = append(removed, permission)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// rbacRulesSnapshot is the state of a Role/ClusterRole of a RolloutManager, before it is reconciled. See detectRBACRuleChanges.
+type rbacRulesSnapshot struct {
+	kind        string
+	specHash    string
+	permissions []string
+}
+
+// getRolloutsRBACRoles returns the Roles/ClusterRoles of the RolloutManager whose permission changes are reported: the Role (or ClusterRole) of the Rollouts controller, and the aggregate ClusterRoles.
+// The rollout-user Roles are not included, as there is one in each watched namespace: their permissions are the same in all namespaces, and their changes are listed by 'make rbac-diff'.
+func (r *RolloutManagerReconciler) getRolloutsRBACRoles(cr rolloutsmanagerv1alpha1.RolloutManager) []client.Object {
+
+	res := []client.Object{}
+	if cr.Spec.NamespaceScoped {
+		res = append(res, &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: cr.Namespace}})
+	} else {
+		res = append(res, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName}})
+	}
+
+	if r.isAggregateClusterRolesManaged(cr) {
+		for _, suffix := range []string{"aggregate-to-admin", "aggregate-to-edit", "aggregate-to-view"} {
+			res = append(res, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", DefaultArgoRolloutsResourceName, suffix)}})
+		}
+	}
+	return res
+}
+
+// getRBACRulesSnapshots returns the state of the Roles/ClusterRoles of the RolloutManager (see getRolloutsRBACRoles) which exist on the cluster, by description.
+func (r *RolloutManagerReconciler) getRBACRulesSnapshots(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (map[string]rbacRulesSnapshot, error) {
+
+	res := map[string]rbacRulesSnapshot{}
+	for _, obj := range r.getRolloutsRBACRoles(cr) {
+		description := r.describeResource(cr, obj)
+		if err := fetchObject(ctx, r.Client, obj.GetNamespace(), obj.GetName(), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get the %s: %w", description, err)
+		}
+
+		var rules []rbacv1.PolicyRule
+		switch role := obj.(type) {
+		case *rbacv1.Role:
+			rules = role.Rules
+		case *rbacv1.ClusterRole:
+			rules = role.Rules
+		}
+
+		res[description] = rbacRulesSnapshot{
+			kind:        r.resourceKind(obj),
+			specHash:    obj.GetAnnotations()[SpecHashAnnotation],
+			permissions: GetPolicyRulesPermissions(rules),
+		}
+	}
+	return res, nil
+}
+
+// detectRBACRuleChanges returns the permissions which the operator added to or removed from the Roles/ClusterRoles of the RolloutManager, since their given previous state, for example, after the operator was upgraded. Each change is reported by an Event on the RolloutManager, and counted in the metrics of the operator.
+// A Role/ClusterRole is only reported if the operator changed its expected state (its SpecHashAnnotation): rules that were modified outside of the operator, and reverted by it, are drift corrections (see recordDriftCorrection). Roles/ClusterRoles which were created are not reported either.
+func (r *RolloutManagerReconciler) detectRBACRuleChanges(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, previous map[string]rbacRulesSnapshot) ([]string, error) {
+
+	current, err := r.getRBACRulesSnapshots(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make([]string, 0, len(current))
+	for description := range current {
+		descriptions = append(descriptions, description)
+	}
+	sort.Strings(descriptions)
+
+	changes := []string{}
+	for _, description := range descriptions {
+		before, ok := previous[description]
+		after := current[description]
+		if !ok || before.specHash == after.specHash {
+			continue
+		}
+
+		added, removed := DiffPermissions(before.permissions, after.permissions)
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+
+		change := description
+		if len(added) > 0 {
+			change += " (added: " + strings.Join(added, ", ") + ")"
+		}
+		if len(removed) > 0 {
+			change += " (removed: " + strings.Join(removed, ", ") + ")"
+		}
+
+		log.Info(fmt.Sprintf("The permissions granted by %s changed", description), "added", added, "removed", removed)
+		r.recordRBACRuleChange(cr, after.kind, change, len(added), len(removed))
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// recordRBACRuleChange records an Event on the RolloutManager for the change of the permissions of one of its Roles/ClusterRoles, and counts the permissions which were added and removed.
+func (r *RolloutManagerReconciler) recordRBACRuleChange(cr rolloutsmanagerv1alpha1.RolloutManager, kind string, change string, added int, removed int) {
+	if r.dryRun {
+		return
+	}
+
+	rolloutManagerRBACPermissionChanges.WithLabelValues(cr.Namespace, cr.Name, kind, "added").Add(float64(added))
+	rolloutManagerRBACPermissionChanges.WithLabelValues(cr.Namespace, cr.Name, kind, "removed").Add(float64(removed))
+
+	if r.Recorder != nil {
+		r.Recorder.Event(&cr, corev1.EventTypeNormal, EventReasonRBACRulesChanged, "The operator changed the permissions granted by "+change)
+	}
+}
+
+// createRBACRulesChangedCondition returns the RBACRulesChanged condition for the given changes of the permissions of the Roles/ClusterRoles.
+func createRBACRulesChangedCondition(changes []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerRBACRulesChangedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonRBACRulesChanged,
+		Message: RBACRulesChangedMessage + strings.Join(changes, "; "),
+	}
+}
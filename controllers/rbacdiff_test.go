@@ -0,0 +1,134 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("RBAC diff tests", func() {
+
+	var (
+		ctx      context.Context
+		cr       rolloutsmanagerv1alpha1.RolloutManager
+		r        *RolloutManagerReconciler
+		req      reconcile.Request
+		recorder *namespacedEventRecorder
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		recorder = &namespacedEventRecorder{}
+		r.Recorder = recorder
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+		DeferCleanup(deleteRolloutManagerMetrics, cr.Namespace, cr.Name)
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+	})
+
+	// setClusterRoleRules replaces the rules of the ClusterRole of the Rollouts controller, and its spec hash if it is non-empty, as if they had been applied by another version of the operator
+	setClusterRoleRules := func(rules []rbacv1.PolicyRule, specHash string) {
+		clusterRole := &rbacv1.ClusterRole{}
+		Expect(fetchObject(ctx, r.Client, "", DefaultArgoRolloutsResourceName, clusterRole)).To(Succeed())
+		clusterRole.Rules = rules
+		if specHash != "" {
+			clusterRole.Annotations[SpecHashAnnotation] = specHash
+		}
+		Expect(r.Client.Update(ctx, clusterRole)).To(Succeed())
+	}
+
+	getRolloutManager := func() *rolloutsmanagerv1alpha1.RolloutManager {
+		rm := &rolloutsmanagerv1alpha1.RolloutManager{}
+		Expect(r.Client.Get(ctx, req.NamespacedName, rm)).To(Succeed())
+		return rm
+	}
+
+	It("should report the permissions which were added and removed after an upgrade of the operator", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(meta.FindStatusCondition(getRolloutManager().Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerRBACRulesChangedConditionType)).To(BeNil())
+
+		By("reconciling the rules of a previous version of the operator, which lacked the first rule and granted an extra permission")
+		previousRules := append([]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list"}}}, GetPolicyRules()[1:]...)
+		setClusterRoleRules(previousRules, "previous-version")
+
+		added, removed := DiffPermissions(GetPolicyRulesPermissions(previousRules), GetPolicyRulesPermissions(GetPolicyRules()))
+		Expect(added).ToNot(BeEmpty())
+		Expect(removed).To(Equal([]string{"list nodes"}))
+
+		recorder.events = nil
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		condition := meta.FindStatusCondition(getRolloutManager().Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerRBACRulesChangedConditionType)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonRBACRulesChanged))
+		Expect(condition.Message).To(HavePrefix(RBACRulesChangedMessage + "ClusterRole argo-rollouts (added: " + added[0]))
+		Expect(condition.Message).To(HaveSuffix("(removed: list nodes)"))
+
+		Expect(recorder.events).To(ContainElement(cr.Namespace + "/*v1alpha1.RolloutManager " + EventReasonRBACRulesChanged))
+		Expect(testutil.ToFloat64(rolloutManagerRBACPermissionChanges.WithLabelValues(cr.Namespace, cr.Name, "ClusterRole", "added"))).To(Equal(float64(len(added))))
+		Expect(testutil.ToFloat64(rolloutManagerRBACPermissionChanges.WithLabelValues(cr.Namespace, cr.Name, "ClusterRole", "removed"))).To(Equal(1.0))
+
+		By("keeping the condition when the permissions are unchanged")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(meta.FindStatusCondition(getRolloutManager().Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerRBACRulesChangedConditionType).Message).To(Equal(condition.Message))
+		Expect(testutil.ToFloat64(rolloutManagerRBACPermissionChanges.WithLabelValues(cr.Namespace, cr.Name, "ClusterRole", "removed"))).To(Equal(1.0))
+	})
+
+	It("should not report rules which were modified outside of the operator, and reverted by it", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		setClusterRoleRules(GetPolicyRules()[1:], "")
+
+		recorder.events = nil
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		clusterRole := &rbacv1.ClusterRole{}
+		Expect(fetchObject(ctx, r.Client, "", DefaultArgoRolloutsResourceName, clusterRole)).To(Succeed())
+		Expect(clusterRole.Rules).To(Equal(GetPolicyRules()))
+
+		Expect(meta.FindStatusCondition(getRolloutManager().Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerRBACRulesChangedConditionType)).To(BeNil())
+		Expect(recorder.events).ToNot(ContainElement(cr.Namespace + "/*v1alpha1.RolloutManager " + EventReasonRBACRulesChanged))
+	})
+
+	It("GetPolicyRulesPermissions should return the permissions of the rules regardless of how they are grouped", func() {
+		grouped := []rbacv1.PolicyRule{{APIGroups: []string{"argoproj.io"}, Resources: []string{"rollouts", "experiments"}, Verbs: []string{"get", "list"}}}
+		split := []rbacv1.PolicyRule{
+			{APIGroups: []string{"argoproj.io"}, Resources: []string{"experiments"}, Verbs: []string{"list", "get"}},
+			{APIGroups: []string{"argoproj.io"}, Resources: []string{"rollouts"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{"argoproj.io"}, Resources: []string{"rollouts"}, Verbs: []string{"get"}},
+		}
+
+		Expect(GetPolicyRulesPermissions(grouped)).To(Equal([]string{"get experiments.argoproj.io", "get rollouts.argoproj.io", "list experiments.argoproj.io", "list rollouts.argoproj.io"}))
+		Expect(GetPolicyRulesPermissions(split)).To(Equal(GetPolicyRulesPermissions(grouped)))
+
+		added, removed := DiffPermissions(GetPolicyRulesPermissions(grouped), GetPolicyRulesPermissions(split))
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(BeEmpty())
+	})
+
+	It("GetRBACPolicyRules should return the rules of each Role/ClusterRole created by the operator", func() {
+		rules := GetRBACPolicyRules()
+		Expect(rules).To(HaveKeyWithValue(DefaultArgoRolloutsResourceName, GetPolicyRules()))
+		Expect(rules).To(HaveKeyWithValue(DefaultArgoRolloutsResourceName+"-aggregate-to-admin", GetAggregateToAdminPolicyRules()))
+		Expect(rules).To(HaveKeyWithValue(DefaultRolloutUserRoleName, GetRolloutUserPolicyRules()))
+		Expect(rules).To(HaveLen(5))
+	})
+})
@@ -0,0 +1,179 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RBACEscalationDeniedMessage is the prefix of the message of the RBACEscalationDenied condition, which is followed by the rules that the operator is not allowed to grant.
+const RBACEscalationDeniedMessage = "The operator is not allowed to grant these rules, as it does not hold them itself (nor the 'escalate' permission): "
+
+// AccessReviewer checks whether the operator is allowed to perform an action.
+type AccessReviewer interface {
+	Allowed(ctx context.Context, spec authorizationv1.SelfSubjectAccessReviewSpec) (bool, error)
+}
+
+// SelfSubjectAccessReviewer is an AccessReviewer which creates a SelfSubjectAccessReview for each action. Creating SelfSubjectAccessReviews does not require any permission.
+type SelfSubjectAccessReviewer struct {
+	Client client.Client
+}
+
+func (a *SelfSubjectAccessReviewer) Allowed(ctx context.Context, spec authorizationv1.SelfSubjectAccessReviewSpec) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{Spec: spec}
+	if err := a.Client.Create(ctx, review); err != nil {
+		return false, fmt.Errorf("failed to create SelfSubjectAccessReview: %w", err)
+	}
+	return review.Status.Allowed, nil
+}
+
+// rbacEscalationDeniedError is returned when the rules of a Role or ClusterRole rendered for a RolloutManager cannot be granted by the operator, so that the API server would reject it as a privilege escalation.
+type rbacEscalationDeniedError struct {
+	deniedRules []string
+}
+
+func (e *rbacEscalationDeniedError) Error() string {
+	return RBACEscalationDeniedMessage + strings.Join(e.deniedRules, "; ")
+}
+
+// checkRBACEscalation returns an rbacEscalationDeniedError if the resource is a Role or ClusterRole with rules that the operator is not allowed to grant: the API server only allows a Role/ClusterRole to be created or updated by a user which holds all of its rules, or which has the 'escalate' permission on it.
+// It is called before a Role/ClusterRole is created or updated (see applyResource), so that the rules which cannot be granted are reported, rather than the Forbidden error of the API server. It is skipped if the operator has no AccessReviewer.
+func (r *RolloutManagerReconciler) checkRBACEscalation(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error {
+
+	if r.AccessReviewer == nil {
+		return nil
+	}
+
+	var rules []rbacv1.PolicyRule
+	var resource string
+	switch role := obj.(type) {
+	case *rbacv1.Role:
+		rules, resource = role.Rules, "roles"
+	case *rbacv1.ClusterRole:
+		rules, resource = role.Rules, "clusterroles"
+	default:
+		return nil
+	}
+
+	escalate, err := r.AccessReviewer.Allowed(ctx, authorizationv1.SelfSubjectAccessReviewSpec{
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: obj.GetNamespace(),
+			Verb:      "escalate",
+			Group:     rbacv1.GroupName,
+			Resource:  resource,
+			Name:      obj.GetName(),
+		},
+	})
+	if err != nil || escalate {
+		return err
+	}
+
+	description := r.describeResource(cr, obj)
+
+	// Rules commonly share permissions (for example, the same verbs on several resources), so each permission is only reviewed once
+	reviewed := map[string]bool{}
+
+	deniedRules := []string{}
+	for i, rule := range rules {
+		denied := []string{}
+		for _, spec := range getPolicyRuleAccessReviews(obj.GetNamespace(), rule) {
+			permission := describeAccessReview(spec)
+			allowed, ok := reviewed[permission]
+			if !ok {
+				if allowed, err = r.AccessReviewer.Allowed(ctx, spec); err != nil {
+					return err
+				}
+				reviewed[permission] = allowed
+			}
+			if !allowed {
+				denied = append(denied, permission)
+			}
+		}
+		if len(denied) > 0 {
+			deniedRules = append(deniedRules, fmt.Sprintf("%s rule %d (%s)", description, i+1, strings.Join(denied, ", ")))
+		}
+	}
+
+	if len(deniedRules) > 0 {
+		return &rbacEscalationDeniedError{deniedRules: deniedRules}
+	}
+	return nil
+}
+
+// getPolicyRuleAccessReviews returns the permissions that a PolicyRule grants, in the namespace (or cluster-wide, if it is empty), as the specs of the SelfSubjectAccessReviews which check them.
+func getPolicyRuleAccessReviews(namespace string, rule rbacv1.PolicyRule) []authorizationv1.SelfSubjectAccessReviewSpec {
+
+	res := []authorizationv1.SelfSubjectAccessReviewSpec{}
+
+	for _, verb := range rule.Verbs {
+		for _, path := range rule.NonResourceURLs {
+			res = append(res, authorizationv1.SelfSubjectAccessReviewSpec{
+				NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: path, Verb: verb},
+			})
+		}
+
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				resource, subresource, _ := strings.Cut(resource, "/")
+
+				// A rule without resource names applies to all resources
+				names := rule.ResourceNames
+				if len(names) == 0 {
+					names = []string{""}
+				}
+
+				for _, name := range names {
+					res = append(res, authorizationv1.SelfSubjectAccessReviewSpec{
+						ResourceAttributes: &authorizationv1.ResourceAttributes{
+							Namespace:   namespace,
+							Verb:        verb,
+							Group:       group,
+							Resource:    resource,
+							Subresource: subresource,
+							Name:        name,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// describeAccessReview returns a description of the permission checked by a SelfSubjectAccessReview, such as 'create rollouts.argoproj.io' or 'get /metrics'.
+func describeAccessReview(spec authorizationv1.SelfSubjectAccessReviewSpec) string {
+
+	if spec.NonResourceAttributes != nil {
+		return fmt.Sprintf("%s %s", spec.NonResourceAttributes.Verb, spec.NonResourceAttributes.Path)
+	}
+
+	attributes := spec.ResourceAttributes
+	res := attributes.Resource
+	if attributes.Group != "" {
+		res += "." + attributes.Group
+	}
+	if attributes.Subresource != "" {
+		res += "/" + attributes.Subresource
+	}
+	if attributes.Name != "" {
+		res += " " + attributes.Name
+	}
+	return attributes.Verb + " " + res
+}
+
+// createRBACEscalationDeniedCondition returns the RBACEscalationDenied condition for the given rules that the operator is not allowed to grant.
+func createRBACEscalationDeniedCondition(deniedRules []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerRBACEscalationDeniedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonRBACEscalationDenied,
+		Message: RBACEscalationDeniedMessage + strings.Join(deniedRules, "; "),
+	}
+}
@@ -0,0 +1,122 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeAccessReviewer is an AccessReviewer which denies the permissions of the given descriptions (see describeAccessReview), and counts the reviews.
+type fakeAccessReviewer struct {
+	denied  map[string]bool
+	reviews int
+}
+
+func (a *fakeAccessReviewer) Allowed(ctx context.Context, spec authorizationv1.SelfSubjectAccessReviewSpec) (bool, error) {
+	a.reviews++
+	return !a.denied[describeAccessReview(spec)], nil
+}
+
+var _ = Describe("RBAC escalation tests", func() {
+
+	var (
+		ctx      context.Context
+		cr       rolloutsmanagerv1alpha1.RolloutManager
+		r        *RolloutManagerReconciler
+		req      reconcile.Request
+		reviewer *fakeAccessReviewer
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+
+		reviewer = &fakeAccessReviewer{denied: map[string]bool{
+			"escalate clusterroles.rbac.authorization.k8s.io argo-rollouts": true,
+			"create analysisruns.argoproj.io":                               true,
+			"update rollouts.argoproj.io/status":                            true,
+		}}
+		r.AccessReviewer = reviewer
+	})
+
+	It("should report the rules that the operator is not allowed to grant, instead of creating the ClusterRole, until it is allowed to", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+
+		err = fetchObject(ctx, r.Client, "", DefaultArgoRolloutsResourceName, &rbacv1.ClusterRole{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		condition := meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerRBACEscalationDeniedConditionType)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonRBACEscalationDenied))
+		Expect(condition.Message).To(HavePrefix(RBACEscalationDeniedMessage + "ClusterRole argo-rollouts rule "))
+		Expect(condition.Message).To(ContainSubstring("rule 2 (create analysisruns.argoproj.io)"))
+		Expect(condition.Message).To(ContainSubstring("rule 1 (update rollouts.argoproj.io/status)"))
+
+		reconciled := meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerConditionType)
+		Expect(reconciled.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonRBACEscalationDenied))
+
+		By("creating the ClusterRole once the operator is allowed to escalate")
+		reviewer.denied = map[string]bool{}
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, "", DefaultArgoRolloutsResourceName, &rbacv1.ClusterRole{})).To(Succeed())
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		Expect(meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerRBACEscalationDeniedConditionType)).To(BeNil())
+
+		By("not reviewing the rules of Roles and ClusterRoles which are unchanged")
+		reviews := reviewer.reviews
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reviewer.reviews).To(Equal(reviews))
+	})
+
+	It("should not review the rules of a Role when the operator is allowed to escalate", func() {
+		role := &rbacv1.Role{Rules: GetPolicyRules()}
+		role.Name = DefaultArgoRolloutsResourceName
+		role.Namespace = cr.Namespace
+
+		Expect(r.checkRBACEscalation(ctx, cr, role)).To(Succeed())
+		Expect(reviewer.reviews).To(Equal(1))
+	})
+
+	It("getPolicyRuleAccessReviews should return each permission granted by a rule", func() {
+		specs := getPolicyRuleAccessReviews(cr.Namespace, rbacv1.PolicyRule{
+			APIGroups:     []string{"", "apps"},
+			Resources:     []string{"configmaps", "deployments/scale"},
+			ResourceNames: []string{"argo-rollouts"},
+			Verbs:         []string{"get", "update"},
+		})
+
+		descriptions := []string{}
+		for _, spec := range specs {
+			Expect(spec.ResourceAttributes.Namespace).To(Equal(cr.Namespace))
+			descriptions = append(descriptions, describeAccessReview(spec))
+		}
+		Expect(descriptions).To(ConsistOf(
+			"get configmaps argo-rollouts", "get deployments/scale argo-rollouts", "get configmaps.apps argo-rollouts", "get deployments.apps/scale argo-rollouts",
+			"update configmaps argo-rollouts", "update deployments/scale argo-rollouts", "update configmaps.apps argo-rollouts", "update deployments.apps/scale argo-rollouts"))
+
+		specs = getPolicyRuleAccessReviews("", rbacv1.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}})
+		Expect(specs).To(HaveLen(1))
+		Expect(describeAccessReview(specs[0])).To(Equal("get /metrics"))
+	})
+})
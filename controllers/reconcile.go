@@ -2,8 +2,11 @@ package rollouts
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -19,128 +22,532 @@ type reconcileStatusResult struct {
 
 	// phase: if non-nil, .status.phase will be set to this value, after call to reconcileRolloutsManager
 	phase *rolloutsmanagerv1alpha1.RolloutControllerPhase
+
+	// relatedImages: if non-nil, .status.relatedImages will be set to this value, after call to reconcileRolloutsManager
+	relatedImages []rolloutsmanagerv1alpha1.RelatedImage
+
+	// conflictingInstallations: if non-nil, the ConflictingInstallationDetected condition will be set if it is non-empty (naming the resources), or removed if it is empty, after call to reconcileRolloutsManager
+	conflictingInstallations []string
+
+	// podSecurityViolations: if non-nil, the PodSecurityViolation condition will be set if it is non-empty (naming the violations), or removed if it is empty, after call to reconcileRolloutsManager
+	podSecurityViolations []string
+
+	// cloudIdentityProblems: if non-nil, the CloudIdentityNotConfigured condition will be set if it is non-empty (naming the problems), or removed if it is empty, after call to reconcileRolloutsManager
+	cloudIdentityProblems []string
+
+	// dashboardCertificateNotReadyReasons: if non-nil, the DashboardCertificateNotReady condition will be set if it is non-empty (naming the reasons why the certificate is not ready), or removed if it is empty, after call to reconcileRolloutsManager
+	dashboardCertificateNotReadyReasons []string
+
+	// clusterAPIRequirements: if non-nil, the ClusterAPIRequired condition will be set if it is non-empty (naming the features which require cluster-scoped resources), or removed if it is empty, after call to reconcileRolloutsManager
+	clusterAPIRequirements []string
+
+	// controllerCrashes: if non-nil, the ControllerCrashing condition will be set if it is non-empty (naming the crashes), or removed if it is empty, after call to reconcileRolloutsManager
+	controllerCrashes []string
+
+	// controllerDegradations: if non-nil, the ControllerDegraded condition will be set if it is non-empty (naming the causes for which the Rollouts controller pods cannot become ready, with controllerDegradationReason as its reason), or removed if it is empty, after call to reconcileRolloutsManager
+	controllerDegradations      []string
+	controllerDegradationReason string
+
+	// policyViolations: if non-nil, the Degraded condition will be set if it is non-empty (naming the violations), or removed if it is empty, after call to reconcileRolloutsManager
+	policyViolations []string
+
+	// rbacEscalationDenials: if non-nil, the RBACEscalationDenied condition will be set if it is non-empty (naming the rules that the operator is not allowed to grant), or removed if it is empty, after call to reconcileRolloutsManager
+	rbacEscalationDenials []string
+
+	// rbacRuleChanges: if non-empty, the RBACRulesChanged condition will be set (naming the permissions that were added and removed), after call to reconcileRolloutsManager. The condition is left untouched otherwise, so that it reports the last change.
+	rbacRuleChanges []string
+
+	// pprofExpirationTime: if non-nil, .status.pprofExpirationTime will be set to this value, after call to reconcileRolloutsManager. It is removed if the profiling endpoints are no longer requested.
+	pprofExpirationTime *metav1.Time
+
+	// controllerResourcesRecommendation: if non-nil, .status.controllerResourcesRecommendation will be set to this value if it names a VerticalPodAutoscaler, or removed if it is empty, after call to reconcileRolloutsManager
+	controllerResourcesRecommendation *rolloutsmanagerv1alpha1.RolloutManagerResourcesRecommendation
+
+	// removeComponentConditions: if true, the conditions of the components which are reconciled by their own controllers (see rolloutsComponents) are removed, after call to reconcileRolloutsManager, as the components are reconciled by reconcileRolloutsManager
+	removeComponentConditions bool
+
+	// appliedSpec: if non-nil, .status.lastAppliedSpec will be set to its snapshot (and .status.lastAppliedTime to the current time, if the snapshot changed), after call to reconcileRolloutsManager
+	appliedSpec *rolloutsmanagerv1alpha1.RolloutManagerSpec
+
+	// components: if non-nil, .status.components will be set to this value, after call to reconcileRolloutsManager
+	components []rolloutsmanagerv1alpha1.RolloutManagerComponentStatus
+
+	// recordFailure: if true, the failure of the reconciliation (reconcileErr) is recorded in .status.failureCount, .status.lastFailureTime and .status.lastError, or they are reset if reconcileErr is nil, after call to reconcileRolloutsManager (see setReconcileFailure)
+	recordFailure bool
+	reconcileErr  error
+
+	// requeueAfter: if non-zero, the RolloutManager will be reconciled again after this duration (for example, when the next backup is due), if it is sooner than the requeue interval for its phase (see nextRequeueAfter)
+	requeueAfter time.Duration
 }
 
 func (r *RolloutManagerReconciler) reconcileRolloutsManager(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (reconcileStatusResult, error) {
 
+	// A RolloutManager with a target namespace runs its Rollouts controller via a RolloutManager in that namespace, rather than reconciling the resources itself
+	if isTargetNamespaceEnabled(cr) {
+		return r.reconcileTargetNamespace(ctx, cr)
+	}
+
+	log.Info("removing target namespaces")
+	if err := r.removeTargetNamespaces(ctx, cr.Namespace, cr.Name, ""); err != nil {
+		log.Error(err, "failed to remove target namespaces.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	now := time.Now()
+	invalid, pprofExpirationTime, err := r.validateRolloutManager(ctx, &cr, now)
+	if err != nil {
+		return wrapCondition(createCondition(err.Error())), err
+	}
+	if invalid != nil {
+		return *invalid, nil
+	}
+
+	log.Info("detecting conflicting Argo Rollouts installations")
+	conflictingInstallations, err := r.detectConflictingInstallations(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to detect conflicting Argo Rollouts installations.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+	if len(conflictingInstallations) > 0 {
+		log.Info("Argo Rollouts resources which are not managed by the operator were found: these may conflict with the Rollouts controller of the RolloutManager", "resources", conflictingInstallations)
+	}
+
+	log.Info("adopting orphaned resources")
+	if err := r.adoptOrphanedResources(ctx, cr); err != nil {
+		log.Error(err, "failed to adopt orphaned resources.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	if err := r.migrateOwnerReferences(ctx, cr); err != nil {
+		log.Error(err, "failed to migrate owner references.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling Rollouts ServiceAccount")
+	sa, err := r.reconcileRolloutsServiceAccount(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to reconcile Rollout's ServiceAccount.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	cloudIdentityProblems := validateCloudIdentity(cr, *sa)
+	if len(cloudIdentityProblems) > 0 {
+		log.Info("the cloud identity of the Rollouts controller is not configured", "problems", cloudIdentityProblems)
+	}
+
+	// When the ComponentControllers feature is enabled, the RBAC, config and monitoring components are reconciled by their own controllers
+	componentControllers := r.FeatureGates.Enabled(ComponentControllers)
+
+	var rbacRuleChanges []string
+	if !componentControllers {
+		if rbacRuleChanges, err = r.reconcileRolloutsRBAC(ctx, cr, sa); err != nil {
+			return wrapCondition(createCondition(err.Error())), err
+		}
+	}
+
+	configRequeueAfter := time.Duration(0)
+	if !componentControllers {
+		configRequeueAfter, err = r.reconcileRolloutsConfig(ctx, cr)
+		if err != nil {
+			return wrapCondition(createCondition(err.Error())), err
+		}
+	}
+
+	log.Info("checking Rollouts controller pod against the Pod Security Standard of the namespace")
+	podSecurityViolations, err := r.detectPodSecurityViolations(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to check Rollouts controller pod against the Pod Security Standard of the namespace.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+	if len(podSecurityViolations) > 0 {
+		log.Info("the Rollouts controller pod violates the Pod Security Standard enforced on the namespace", "violations", podSecurityViolations)
+	}
+
+	log.Info("reconciling the cluster-wide proxy of the Rollouts controller")
+	deploymentCR, err := r.reconcileClusterProxy(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to reconcile the cluster-wide proxy of the Rollouts controller.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("verifying Rollouts controller image")
+	if err := r.verifyRolloutsImage(ctx, deploymentCR, *sa); err != nil {
+		if errors.Is(err, ErrImageNotFound) {
+			return *invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonImageNotFound), nil
+		}
+		log.Error(err, "failed to verify Rollouts controller image.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling Rollouts Deployment")
+	if err := r.reconcileRolloutsDeployment(ctx, deploymentCR, *sa); err != nil {
+		log.Error(err, "failed to reconcile Rollout's Deployment.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling Rollouts leader election Lease")
+	if err := r.reconcileRolloutsLeaderElectionLease(ctx, cr); err != nil {
+		log.Error(err, "failed to reconcile Rollout's leader election Lease.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling Rollouts VerticalPodAutoscaler")
+	if err := r.reconcileRolloutsVerticalPodAutoscaler(ctx, cr); err != nil {
+		log.Error(err, "failed to reconcile Rollout's VerticalPodAutoscaler.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling Rollouts FlowSchema")
+	if err := r.reconcileRolloutsFlowSchema(ctx, cr); err != nil {
+		log.Error(err, "failed to reconcile Rollout's FlowSchema.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling Rollouts dashboard")
+	dashboardCertificateNotReadyReasons, err := r.reconcileRolloutsDashboard(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to reconcile Rollout's dashboard.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reading Rollouts VerticalPodAutoscaler recommendation")
+	controllerResourcesRecommendation, err := r.getControllerResourcesRecommendation(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to read Rollout's VerticalPodAutoscaler recommendation.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	if !componentControllers {
+		if err := r.reconcileRolloutsMonitoring(ctx, cr); err != nil {
+			return wrapCondition(createCondition(err.Error())), err
+		}
+	}
+
+	restartRequeueAfter, err := r.controllerRestartRequeueAfter(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to determine when the deferred update of Rollout's Deployment is due.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling status of workloads")
+	rr, err := r.determineStatusPhase(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to reconcile status of workloads.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("checking Rollouts controller pods for crashes")
+	controllerCrashes, err := r.detectControllerCrashes(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to check Rollouts controller pods for crashes.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+	if len(controllerCrashes) > 0 {
+		log.Info("the Rollouts controller container is crashing", "crashes", controllerCrashes)
+		r.recordControllerCrashEvent(&cr, controllerCrashes)
+	}
+
+	log.Info("reading UIDs of Rollouts resources")
+	components, err := r.reconcileComponentStatuses(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to read UIDs of Rollout's resources.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	rr.requeueAfter = minRequeueAfter(minRequeueAfter(configRequeueAfter, restartRequeueAfter), pprofRequeueAfter(pprofExpirationTime, now))
+
+	rr.pprofExpirationTime = pprofExpirationTime
+
+	rr.relatedImages = getRelatedImages(cr)
+
+	rr.controllerResourcesRecommendation = controllerResourcesRecommendation
+
+	rr.conflictingInstallations = conflictingInstallations
+
+	rr.podSecurityViolations = podSecurityViolations
+
+	rr.cloudIdentityProblems = cloudIdentityProblems
+
+	rr.dashboardCertificateNotReadyReasons = dashboardCertificateNotReadyReasons
+
+	rr.clusterAPIRequirements = r.getClusterAPIRequirements(cr)
+
+	rr.controllerCrashes = controllerCrashes
+
+	rr.components = components
+
+	// All resources were rendered and applied, so none of them violate the resource policies
+	rr.policyViolations = []string{}
+
+	// All Roles and ClusterRoles were applied, so the operator is allowed to grant their rules
+	rr.rbacEscalationDenials = []string{}
+
+	rr.rbacRuleChanges = rbacRuleChanges
+
+	// The spec is only fully applied once no update of the Rollouts controller Deployment is deferred by the restart budget
+	if restartRequeueAfter == 0 {
+		rr.appliedSpec = &cr.Spec
+	}
+
+	rr.condition = createCondition("") // success
+
+	return rr, nil
+}
+
+// validateRolloutManager verifies that the resources of the RolloutManager can be reconciled. If they cannot, the returned reconcileStatusResult is non-nil, and should be set on the status of the RolloutManager.
+// It also returns the expiration time of the profiling endpoints of the Rollouts controller (see getPprofExpirationTime): if it has elapsed, they are removed from the spec of cr, so that the resources are rendered as if they were not requested.
+func (r *RolloutManagerReconciler) validateRolloutManager(ctx context.Context, cr *rolloutsmanagerv1alpha1.RolloutManager, now time.Time) (*reconcileStatusResult, *metav1.Time, error) {
+
 	log.Info("validating RolloutManager's scope")
-	if rr, err := validateRolloutsScope(cr, r.NamespaceScopedArgoRolloutsController); err != nil {
+	if rr, err := validateRolloutsScope(*cr, r.NamespaceScopedArgoRolloutsController); err != nil {
 		if invalidRolloutScope(err) {
 			rr.condition = createCondition(err.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidScoped)
-			return *rr, nil
+			return rr, nil, nil
 		}
 
 		if invalidRolloutNamespace(err) {
 			rr.condition = createCondition(err.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidNamespace)
-			return *rr, nil
+			return rr, nil, nil
 		}
 
 		log.Error(err, "failed to validate RolloutManager's scope.")
-		return wrapCondition(createCondition(err.Error())), err
+		return nil, nil, err
 	}
 
+	log.Info("validating Rollouts controller tuning")
+	if err := validateRolloutsControllerTuning(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidControllerTuning), nil, nil
+	}
+
+	log.Info("validating Rollouts controller high availability")
+	if err := validateRolloutsHA(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidHA), nil, nil
+	}
+
+	log.Info("validating Rollouts controller command arguments")
+	if err := validateRolloutsScopeArgs(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonUnsupportedCommandArgs), nil, nil
+	}
+	if err := validateRolloutsCommandArgs(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonUnsupportedCommandArgs), nil, nil
+	}
+	if err := validateRolloutsCommandArgConflicts(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonConflictingCommandArgs), nil, nil
+	}
+
+	log.Info("validating Rollouts controller file mounts")
+	if err := validateFileMounts(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidFileMounts), nil, nil
+	}
+
+	log.Info("validating Rollouts controller debug options")
+	pprofExpirationTime, err := getPprofExpirationTime(*cr, now)
+	if err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidDebug), nil, nil
+	}
+	if pprofExpirationTime != nil && !now.Before(pprofExpirationTime.Time) {
+		// The profiling endpoints expired: the resources are rendered as if they were not requested
+		log.Info("profiling endpoints of the Rollouts controller expired at " + pprofExpirationTime.UTC().Format(time.RFC3339))
+		cr.Spec.Debug = nil
+	}
+
+	log.Info("validating Rollouts controller ports")
+	if err := validateRolloutsPorts(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidPorts), nil, nil
+	}
+
+	log.Info("validating Rollouts controller client rate limit")
+	if err := validateRolloutsKubeClient(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidKubeClient), nil, nil
+	}
+
+	log.Info("validating additional metadata")
+	if err := validateAdditionalMetadata(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidAdditionalMetadata), nil, nil
+	}
+
+	log.Info("validating Rollouts controller images")
+	if err := validateRolloutsImages(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonUnsupportedImage), nil, nil
+	}
+
+	log.Info("validating Rollouts dashboard")
+	if err := validateDashboard(*cr); err != nil {
+		return invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidDashboard), nil, nil
+	}
+
+	// The cluster-scoped lock is only acquired once the RolloutManager is otherwise valid, so that it is not held by a RolloutManager which never deploys a Rollouts controller
 	log.Info("searching for existing RolloutManagers")
-	if res, err := checkForExistingRolloutManager(ctx, r.Client, cr); err != nil {
+	if res, err := r.checkForExistingRolloutManager(ctx, *cr); err != nil {
 		if multipleRolloutManagersExist(err) {
 
 			res.condition = createCondition(err.Error(), rolloutsmanagerv1alpha1.RolloutManagerReasonMultipleClusterScopedRolloutManager)
 
-			return *res, nil
+			return res, nil, nil
 		}
 		log.Error(err, "failed to validate multiple RolloutManagers.")
-		return wrapCondition(createCondition(err.Error())), err
+		return nil, nil, err
 	}
 
-	log.Info("reconciling Rollouts ServiceAccount")
-	sa, err := r.reconcileRolloutsServiceAccount(ctx, cr)
-	if err != nil {
-		log.Error(err, "failed to reconcile Rollout's ServiceAccount.")
-		return wrapCondition(createCondition(err.Error())), err
+	return nil, pprofExpirationTime, nil
+}
+
+// invalidRolloutManager returns the reconcileStatusResult of a RolloutManager which failed validation for the given reason.
+func invalidRolloutManager(err error, reason string) *reconcileStatusResult {
+	phaseFailure := rolloutsmanagerv1alpha1.PhaseFailure
+
+	return &reconcileStatusResult{
+		condition:         createCondition(err.Error(), reason),
+		rolloutController: &phaseFailure,
+		phase:             &phaseFailure,
+	}
+}
+
+// pprofRequeueAfter returns the duration after which the profiling endpoints of the Rollouts controller expire, or 0 if they are not enabled.
+func pprofRequeueAfter(pprofExpirationTime *metav1.Time, now time.Time) time.Duration {
+	if pprofExpirationTime == nil || !now.Before(pprofExpirationTime.Time) {
+		return 0
+	}
+	return pprofExpirationTime.Sub(now)
+}
+
+// reconcileRolloutsRBAC reconciles the Roles/ClusterRoles of the Rollouts controller, and their bindings to its ServiceAccount, as well as the rollout-user Roles.
+// It returns the changes of the permissions granted by the Roles/ClusterRoles, if the operator changed them (see detectRBACRuleChanges).
+func (r *RolloutManagerReconciler) reconcileRolloutsRBAC(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, sa *corev1.ServiceAccount) ([]string, error) {
+
+	if err := r.injectFault(reconcileStepRBAC, cr); err != nil {
+		return nil, err
 	}
 
 	var role *rbacv1.Role
 	var clusterRole *rbacv1.ClusterRole
 
+	previousRBACRules, err := r.getRBACRulesSnapshots(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to get Rollout's Roles/ClusterRoles.")
+		return nil, err
+	}
+
 	if cr.Spec.NamespaceScoped {
 		log.Info("reconciling Rollouts Roles")
 		role, err = r.reconcileRolloutsRole(ctx, cr)
 		if err != nil {
 			log.Error(err, "failed to reconcile Rollout's Role.")
-			return wrapCondition(createCondition(err.Error())), err
+			return nil, err
 		}
 	} else {
 		log.Info("reconciling Rollouts ClusterRoles")
 		clusterRole, err = r.reconcileRolloutsClusterRole(ctx, cr)
 		if err != nil {
 			log.Error(err, "failed to reconcile Rollout's ClusterRoles.")
-			return wrapCondition(createCondition(err.Error())), err
+			return nil, err
 		}
 	}
 
-	log.Info("reconciling aggregate-to-admin ClusterRole")
-	if err := r.reconcileRolloutsAggregateToAdminClusterRole(ctx, cr); err != nil {
-		log.Error(err, "failed to reconcile Rollout's aggregate-to-admin ClusterRoles.")
-		return wrapCondition(createCondition(err.Error())), err
-	}
+	// The aggregate ClusterRoles are shared by the RolloutManagers of the cluster, hence they are not deleted when skipped (they are deleted, as before, once the last RolloutManager is deleted)
+	if r.isAggregateClusterRolesManaged(cr) {
+		log.Info("reconciling aggregate-to-admin ClusterRole")
+		if err := r.reconcileRolloutsAggregateToAdminClusterRole(ctx, cr); err != nil {
+			log.Error(err, "failed to reconcile Rollout's aggregate-to-admin ClusterRoles.")
+			return nil, err
+		}
 
-	log.Info("reconciling aggregate-to-edit ClusterRole")
-	if err := r.reconcileRolloutsAggregateToEditClusterRole(ctx, cr); err != nil {
-		log.Error(err, "failed to reconcile Rollout's aggregate-to-edit ClusterRoles.")
-		return wrapCondition(createCondition(err.Error())), err
-	}
+		log.Info("reconciling aggregate-to-edit ClusterRole")
+		if err := r.reconcileRolloutsAggregateToEditClusterRole(ctx, cr); err != nil {
+			log.Error(err, "failed to reconcile Rollout's aggregate-to-edit ClusterRoles.")
+			return nil, err
+		}
 
-	log.Info("reconciling aggregate-to-view ClusterRole")
-	if err := r.reconcileRolloutsAggregateToViewClusterRole(ctx, cr); err != nil {
-		log.Error(err, "failed to reconcile Rollout's aggregate-to-view ClusterRoles.")
-		return wrapCondition(createCondition(err.Error())), err
+		log.Info("reconciling aggregate-to-view ClusterRole")
+		if err := r.reconcileRolloutsAggregateToViewClusterRole(ctx, cr); err != nil {
+			log.Error(err, "failed to reconcile Rollout's aggregate-to-view ClusterRoles.")
+			return nil, err
+		}
 	}
 
 	if cr.Spec.NamespaceScoped {
 		log.Info("reconciling Rollouts RoleBindings")
 		if err := r.reconcileRolloutsRoleBinding(ctx, cr, role, sa); err != nil {
 			log.Error(err, "failed to reconcile Rollout's RoleBindings.")
-			return wrapCondition(createCondition(err.Error())), err
+			return nil, err
 		}
 	} else {
 		log.Info("reconciling Rollouts ClusterRoleBinding")
 		if err := r.reconcileRolloutsClusterRoleBinding(ctx, clusterRole, sa, cr); err != nil {
 			log.Error(err, "failed to reconcile Rollout's ClusterRoleBinding.")
-			return wrapCondition(createCondition(err.Error())), err
+			return nil, err
 		}
 	}
 
+	log.Info("reconciling rollout-user Roles")
+	if err := r.reconcileRolloutUserRoles(ctx, cr); err != nil {
+		log.Error(err, "failed to reconcile rollout-user Roles.")
+		return nil, err
+	}
+
+	log.Info("detecting changes of the permissions of Rollouts Roles/ClusterRoles")
+	changes, err := r.detectRBACRuleChanges(ctx, cr, previousRBACRules)
+	if err != nil {
+		log.Error(err, "failed to detect changes of the permissions of Rollout's Roles/ClusterRoles.")
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// reconcileRolloutsConfig reconciles the Secret and the ConfigMap of the Rollouts controller (after restoring them from a backup, if requested), and their backups. It returns the duration after which the next backup is due, if any.
+func (r *RolloutManagerReconciler) reconcileRolloutsConfig(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error) {
+
+	if err := r.injectFault(reconcileStepConfig, cr); err != nil {
+		return 0, err
+	}
+
+	log.Info("restoring Rollouts configuration from backup, if requested")
+	if err := r.restoreBackupIfRequested(ctx, cr); err != nil {
+		log.Error(err, "failed to restore Rollout's configuration from backup.")
+		return 0, err
+	}
+
 	log.Info("reconciling Rollouts Secret")
 	if err := r.reconcileRolloutsSecrets(ctx, cr); err != nil {
 		log.Error(err, "failed to reconcile Rollout's Secret.")
-		return wrapCondition(createCondition(err.Error())), err
+		return 0, err
 	}
 
 	log.Info("reconciling ConfigMap for plugins")
 	if err := r.reconcileConfigMap(ctx, cr); err != nil {
 		log.Error(err, "failed to reconcile Rollout's ConfigMap.")
-		return wrapCondition(createCondition(err.Error())), err
+		return 0, err
 	}
 
-	log.Info("reconciling Rollouts Deployment")
-	if err := r.reconcileRolloutsDeployment(ctx, cr, *sa); err != nil {
-		log.Error(err, "failed to reconcile Rollout's Deployment.")
-		return wrapCondition(createCondition(err.Error())), err
+	log.Info("reconciling backup of Rollouts configuration")
+	requeueAfter, err := r.reconcileBackup(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to reconcile backup of Rollout's configuration.")
+		return 0, err
+	}
+
+	return requeueAfter, nil
+}
+
+// reconcileRolloutsMonitoring reconciles the metrics Service (and ServiceMonitor) of the Rollouts controller, and the Service of its extra ports.
+func (r *RolloutManagerReconciler) reconcileRolloutsMonitoring(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if err := r.injectFault(reconcileStepMonitoring, cr); err != nil {
+		return err
 	}
 
 	log.Info("reconciling Rollouts Metrics Service")
 	if err := r.reconcileRolloutsMetricsServiceAndMonitor(ctx, cr); err != nil {
 		log.Error(err, "failed to reconcile Rollout's Metrics Service.")
-		return wrapCondition(createCondition(err.Error())), err
+		return err
 	}
 
-	log.Info("reconciling status of workloads")
-	rr, err := r.determineStatusPhase(ctx, cr)
-	if err != nil {
-		log.Error(err, "failed to reconcile status of workloads.")
-		return wrapCondition(createCondition(err.Error())), err
+	log.Info("reconciling Rollouts extra ports Service")
+	if err := r.reconcileRolloutsExtraPortsService(ctx, cr); err != nil {
+		log.Error(err, "failed to reconcile Rollout's extra ports Service.")
+		return err
 	}
 
-	rr.condition = createCondition("") // success
-
-	return rr, nil
+	return nil
 }
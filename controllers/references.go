@@ -0,0 +1,153 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReferencedObjectsHashAnnotation is set on the pod template of the Rollouts controller Deployment, and contains a hash of the content of the Secrets and ConfigMaps that are referenced by the RolloutManager (via .spec.env and .spec.fileMounts), so that the Rollouts controller is restarted when one of them changes.
+const ReferencedObjectsHashAnnotation = "argo-rollouts.argoproj.io/referenced-objects-hash"
+
+// objectReference is a Secret or ConfigMap, in the namespace of the RolloutManager, that is referenced by the RolloutManager.
+type objectReference struct {
+	kind string
+	name string
+}
+
+// getReferencedObjects returns the Secrets and ConfigMaps that are referenced by the environment variables and the FileMounts of the RolloutManager, sorted and without duplicates.
+func getReferencedObjects(cr rolloutsmanagerv1alpha1.RolloutManager) []objectReference {
+
+	refs := map[objectReference]bool{}
+
+	for _, env := range cr.Spec.Env {
+		if env.ValueFrom == nil {
+			continue
+		}
+		if env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name != "" {
+			refs[objectReference{kind: "Secret", name: env.ValueFrom.SecretKeyRef.Name}] = true
+		}
+		if env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Name != "" {
+			refs[objectReference{kind: "ConfigMap", name: env.ValueFrom.ConfigMapKeyRef.Name}] = true
+		}
+	}
+
+	for _, fileMount := range cr.Spec.FileMounts {
+		if fileMount.Secret != "" {
+			refs[objectReference{kind: "Secret", name: fileMount.Secret}] = true
+		}
+		if fileMount.ConfigMap != "" {
+			refs[objectReference{kind: "ConfigMap", name: fileMount.ConfigMap}] = true
+		}
+	}
+
+	res := make([]objectReference, 0, len(refs))
+	for ref := range refs {
+		res = append(res, ref)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].kind != res[j].kind {
+			return res[i].kind < res[j].kind
+		}
+		return res[i].name < res[j].name
+	})
+	return res
+}
+
+// referencesObject returns true if the Secret or ConfigMap is referenced by the RolloutManager.
+func referencesObject(cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) bool {
+
+	if obj.GetNamespace() != cr.Namespace {
+		return false
+	}
+
+	var kind string
+	switch obj.(type) {
+	case *corev1.Secret:
+		kind = "Secret"
+	case *corev1.ConfigMap:
+		kind = "ConfigMap"
+	default:
+		return false
+	}
+
+	// The trusted CA bundle of the cluster-wide proxy is mounted once OpenShift has injected it (see reconcileClusterProxy)
+	if kind == "ConfigMap" && obj.GetName() == TrustedCABundleConfigMapName && isClusterProxyEnabled(cr) {
+		return true
+	}
+
+	for _, ref := range getReferencedObjects(cr) {
+		if ref.kind == kind && ref.name == obj.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// computeReferencedObjectsHash returns a hash of the content of the Secrets and ConfigMaps that are referenced by the RolloutManager, to be stored in the ReferencedObjectsHashAnnotation, or "" if there are none. Objects that do not exist are part of the hash as well, so that their creation restarts the Rollouts controller.
+func (r *RolloutManagerReconciler) computeReferencedObjectsHash(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (string, error) {
+
+	refs := getReferencedObjects(cr)
+	if len(refs) == 0 {
+		return "", nil
+	}
+
+	hasher := fnv.New64a()
+
+	for _, ref := range refs {
+
+		var obj client.Object
+		var data interface{}
+		switch ref.kind {
+		case "Secret":
+			secret := &corev1.Secret{}
+			obj, data = secret, &secret.Data
+		default:
+			configMap := &corev1.ConfigMap{}
+			obj, data = configMap, []interface{}{&configMap.Data, &configMap.BinaryData}
+		}
+
+		content := []byte("<missing>")
+		if err := fetchObject(ctx, r.Client, cr.Namespace, ref.name, obj); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", fmt.Errorf("failed to get the %s %s: %w", ref.kind, ref.name, err)
+			}
+		} else if content, err = json.Marshal(data); err != nil {
+			return "", fmt.Errorf("failed to hash the %s %s: %w", ref.kind, ref.name, err)
+		}
+
+		fmt.Fprintf(hasher, "%s/%s=", ref.kind, ref.name)
+		hasher.Write(content)
+		hasher.Write([]byte{0})
+	}
+
+	return strconv.FormatUint(hasher.Sum64(), 16), nil
+}
+
+// enqueueReferencingRolloutManagers returns the RolloutManagers that reference the Secret or ConfigMap, so that they are reconciled as soon as it changes.
+func (r *RolloutManagerReconciler) enqueueReferencingRolloutManagers(ctx context.Context, obj client.Object) []reconcile.Request {
+
+	var rolloutManagerList rolloutsmanagerv1alpha1.RolloutManagerList
+	if err := r.Client.List(ctx, &rolloutManagerList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.Error(err, "Unable to list RolloutManagers in enqueueReferencingRolloutManagers")
+		return []reconcile.Request{}
+	}
+
+	var res []reconcile.Request
+	for idx := range rolloutManagerList.Items {
+		rm := rolloutManagerList.Items[idx]
+		if referencesObject(rm, obj) {
+			res = append(res, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&rm)})
+		}
+	}
+	return res
+}
@@ -0,0 +1,134 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Referenced Secret and ConfigMap tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		cr.Spec.Env = []corev1.EnvVar{
+			{Name: "PLAIN", Value: "value"},
+			{Name: "FROM_SECRET", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "my-secret"}, Key: "token"}}},
+			{Name: "FROM_CONFIGMAP", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "my-configmap"}, Key: "level"}}},
+		}
+		cr.Spec.FileMounts = []rolloutsmanagerv1alpha1.RolloutManagerFileMount{
+			{Name: "ca", MountPath: "/etc/ca", ConfigMap: "my-configmap"},
+		}
+		r = makeTestReconciler(&cr)
+	})
+
+	It("getReferencedObjects should return the sorted, deduplicated Secrets and ConfigMaps of the env and file mounts", func() {
+		Expect(getReferencedObjects(cr)).To(Equal([]objectReference{
+			{kind: "ConfigMap", name: "my-configmap"},
+			{kind: "Secret", name: "my-secret"},
+		}))
+
+		Expect(getReferencedObjects(*makeTestRolloutManager())).To(BeEmpty())
+	})
+
+	It("referencesObject should only match referenced objects of the right kind in the same namespace", func() {
+		Expect(referencesObject(cr, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: cr.Namespace}})).To(BeTrue())
+		Expect(referencesObject(cr, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: cr.Namespace}})).To(BeFalse())
+		Expect(referencesObject(cr, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "other"}})).To(BeFalse())
+		Expect(referencesObject(cr, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: cr.Namespace}})).To(BeFalse())
+	})
+
+	It("computeReferencedObjectsHash should change when a referenced object is created or changed", func() {
+		hash, err := r.computeReferencedObjectsHash(ctx, *makeTestRolloutManager())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hash).To(BeEmpty())
+
+		missingHash, err := r.computeReferencedObjectsHash(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missingHash).ToNot(BeEmpty())
+
+		By("creating the referenced Secret")
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: cr.Namespace},
+			Data:       map[string][]byte{"token": []byte("one")},
+		}
+		Expect(r.Client.Create(ctx, secret)).To(Succeed())
+
+		createdHash, err := r.computeReferencedObjectsHash(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(createdHash).ToNot(Equal(missingHash))
+
+		By("verifying that the hash is stable")
+		Expect(r.computeReferencedObjectsHash(ctx, cr)).To(Equal(createdHash))
+
+		By("updating the referenced Secret")
+		secret.Data["token"] = []byte("two")
+		Expect(r.Client.Update(ctx, secret)).To(Succeed())
+
+		updatedHash, err := r.computeReferencedObjectsHash(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updatedHash).ToNot(Equal(createdHash))
+	})
+
+	It("enqueueReferencingRolloutManagers should only enqueue the RolloutManagers that reference the object", func() {
+		other := *makeTestRolloutManager()
+		other.Name = "other-rollouts-manager"
+		Expect(r.Client.Create(ctx, &other)).To(Succeed())
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: cr.Namespace}}
+		Expect(r.enqueueReferencingRolloutManagers(ctx, secret)).To(Equal([]reconcile.Request{
+			{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}},
+		}))
+
+		unrelated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: cr.Namespace}}
+		Expect(r.enqueueReferencingRolloutManagers(ctx, unrelated)).To(BeEmpty())
+	})
+
+	It("should update the pod template of the Rollouts controller Deployment when a referenced object changes", func() {
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-configmap", Namespace: cr.Namespace},
+			Data:       map[string]string{"level": "info"},
+		}
+		Expect(r.Client.Create(ctx, configMap)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		initialHash := deployment.Spec.Template.Annotations[ReferencedObjectsHashAnnotation]
+		Expect(initialHash).ToNot(BeEmpty())
+
+		configMap.Data["level"] = "debug"
+		Expect(r.Client.Update(ctx, configMap)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Annotations[ReferencedObjectsHashAnnotation]).ToNot(Equal(initialHash))
+	})
+})
@@ -0,0 +1,84 @@
+package rollouts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+)
+
+// getRolloutsImageFromEnv returns the image of the Rollouts controller that is set via the environment variables of the operator (ARGO_ROLLOUTS_IMAGE, then RELATED_IMAGE_ARGO_ROLLOUTS), or "" if none is set.
+func getRolloutsImageFromEnv() string {
+	if e := os.Getenv(ArgoRolloutsImageEnvName); e != "" {
+		return e
+	}
+	return os.Getenv(RelatedImageRolloutsEnvName)
+}
+
+// isDigestOnlyImages returns true if only images pinned by digest are allowed, via the DIGEST_ONLY_IMAGES environment variable.
+func isDigestOnlyImages() bool {
+	return strings.ToLower(os.Getenv(DigestOnlyImagesEnvName)) == "true"
+}
+
+// isDigestImage returns true if the image is pinned by digest (e.g. 'quay.io/argoproj/argo-rollouts@sha256:...').
+func isDigestImage(image string) bool {
+	return strings.Contains(image, "@")
+}
+
+// ResolveRelatedImages returns the related images of the operator: the images declared by the RELATED_IMAGE_* environment variables, and the default image of the Rollouts controller.
+// This is called when the operator starts: an error is returned if DIGEST_ONLY_IMAGES is enabled, but one of the images is not pinned by digest.
+func ResolveRelatedImages() ([]rolloutsmanagerv1alpha1.RelatedImage, error) {
+
+	var res []rolloutsmanagerv1alpha1.RelatedImage
+	for _, env := range os.Environ() {
+		name, value, found := strings.Cut(env, "=")
+		if !found || !strings.HasPrefix(name, RelatedImageEnvNamePrefix) || value == "" {
+			continue
+		}
+		res = append(res, rolloutsmanagerv1alpha1.RelatedImage{Name: name, Image: value})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	// The image that is used for RolloutManagers which do not specify one
+	res = append(res, rolloutsmanagerv1alpha1.RelatedImage{
+		Name:  DefaultArgoRolloutsResourceName,
+		Image: getRolloutsContainerImage(rolloutsmanagerv1alpha1.RolloutManager{}),
+	})
+
+	if isDigestOnlyImages() {
+		for _, relatedImage := range res {
+			if !isDigestImage(relatedImage.Image) {
+				return nil, fmt.Errorf("%s is enabled, but image '%s' of '%s' is not pinned by digest", DigestOnlyImagesEnvName, relatedImage.Image, relatedImage.Name)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// getRelatedImages returns the container images that are deployed for the RolloutManager, by component: these are set in .status.relatedImages.
+func getRelatedImages(cr rolloutsmanagerv1alpha1.RolloutManager) []rolloutsmanagerv1alpha1.RelatedImage {
+	return []rolloutsmanagerv1alpha1.RelatedImage{
+		{Name: DefaultArgoRolloutsResourceName, Image: getRolloutsContainerImage(cr)},
+	}
+}
+
+// validateRolloutsImages verifies that the container images that are deployed for the RolloutManager are pinned by digest, if DIGEST_ONLY_IMAGES is enabled.
+func validateRolloutsImages(cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if !isDigestOnlyImages() {
+		return nil
+	}
+
+	for _, relatedImage := range getRelatedImages(cr) {
+		if !isDigestImage(relatedImage.Image) {
+			return errors.New(UnsupportedImageNotDigest)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const testDigestImage = "quay.io/argoproj/argo-rollouts@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+var _ = Describe("Related images tests", func() {
+
+	// setEnv sets the environment variable for the duration of the test
+	setEnv := func(name, value string) {
+		prevVal, prevSet := os.LookupEnv(name)
+		Expect(os.Setenv(name, value)).To(Succeed())
+		DeferCleanup(func() {
+			if prevSet {
+				Expect(os.Setenv(name, prevVal)).To(Succeed())
+			} else {
+				Expect(os.Unsetenv(name)).To(Succeed())
+			}
+		})
+	}
+
+	BeforeEach(func() {
+		for _, name := range []string{ArgoRolloutsImageEnvName, RelatedImageRolloutsEnvName, DigestOnlyImagesEnvName, DefaultImageRegistryMirrorEnvName} {
+			setEnv(name, "")
+			Expect(os.Unsetenv(name)).To(Succeed())
+		}
+	})
+
+	Context("getRolloutsContainerImage", func() {
+
+		It("should use RELATED_IMAGE_ARGO_ROLLOUTS when no image is specified in the RolloutManager", func() {
+			setEnv(RelatedImageRolloutsEnvName, testDigestImage)
+			Expect(getRolloutsContainerImage(*makeTestRolloutManager())).To(Equal(testDigestImage))
+		})
+
+		It("should prefer ARGO_ROLLOUTS_IMAGE over RELATED_IMAGE_ARGO_ROLLOUTS", func() {
+			setEnv(RelatedImageRolloutsEnvName, testDigestImage)
+			setEnv(ArgoRolloutsImageEnvName, "quay.io/my-org/my-rollouts:my-tag")
+			Expect(getRolloutsContainerImage(*makeTestRolloutManager())).To(Equal("quay.io/my-org/my-rollouts:my-tag"))
+		})
+
+		It("should prefer the image of the RolloutManager over RELATED_IMAGE_ARGO_ROLLOUTS", func() {
+			setEnv(RelatedImageRolloutsEnvName, testDigestImage)
+			rm := makeTestRolloutManager()
+			rm.Spec.Image = "quay.io/my-org/my-rollouts"
+			rm.Spec.Version = "my-tag"
+			Expect(getRolloutsContainerImage(*rm)).To(Equal("quay.io/my-org/my-rollouts:my-tag"))
+		})
+	})
+
+	Context("ResolveRelatedImages", func() {
+
+		It("should return the RELATED_IMAGE_* environment variables, sorted by name, followed by the default Rollouts image", func() {
+			setEnv(RelatedImageEnvNamePrefix+"ZZZ_TEST", "quay.io/my-org/zzz:v1")
+			setEnv(RelatedImageEnvNamePrefix+"AAA_TEST", "quay.io/my-org/aaa:v1")
+
+			relatedImages, err := ResolveRelatedImages()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(relatedImages).To(Equal([]rolloutsmanagerv1alpha1.RelatedImage{
+				{Name: RelatedImageEnvNamePrefix + "AAA_TEST", Image: "quay.io/my-org/aaa:v1"},
+				{Name: RelatedImageEnvNamePrefix + "ZZZ_TEST", Image: "quay.io/my-org/zzz:v1"},
+				{Name: DefaultArgoRolloutsResourceName, Image: DefaultArgoRolloutsImage + ":" + DefaultArgoRolloutsVersion},
+			}))
+		})
+
+		It("should return an error in digest-only mode, if an image is not pinned by digest", func() {
+			setEnv(DigestOnlyImagesEnvName, "true")
+			setEnv(RelatedImageRolloutsEnvName, "quay.io/argoproj/argo-rollouts:v1.7.1")
+
+			_, err := ResolveRelatedImages()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should return an error in digest-only mode, if the default Rollouts image is not pinned by digest", func() {
+			setEnv(DigestOnlyImagesEnvName, "true")
+
+			_, err := ResolveRelatedImages()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should succeed in digest-only mode, if all images are pinned by digest", func() {
+			setEnv(DigestOnlyImagesEnvName, "True")
+			setEnv(RelatedImageRolloutsEnvName, testDigestImage)
+
+			relatedImages, err := ResolveRelatedImages()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(relatedImages).To(ContainElement(rolloutsmanagerv1alpha1.RelatedImage{Name: DefaultArgoRolloutsResourceName, Image: testDigestImage}))
+		})
+	})
+
+	Context("Reconcile", func() {
+
+		var (
+			ctx context.Context
+			rm  *rolloutsmanagerv1alpha1.RolloutManager
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			rm = makeTestRolloutManager()
+			setEnv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+			r = makeTestReconciler(rm)
+			Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+
+			req = reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      rm.Name,
+					Namespace: rm.Namespace,
+				},
+			}
+		})
+
+		It("should set the deployed images in .status.relatedImages", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, rm)).To(Succeed())
+			Expect(rm.Status.RelatedImages).To(Equal([]rolloutsmanagerv1alpha1.RelatedImage{
+				{Name: DefaultArgoRolloutsResourceName, Image: DefaultArgoRolloutsImage + ":" + DefaultArgoRolloutsVersion},
+			}))
+		})
+
+		It("should fail a RolloutManager whose image is not pinned by digest, in digest-only mode", func() {
+			setEnv(DigestOnlyImagesEnvName, "true")
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, rm)).To(Succeed())
+			Expect(rm.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(rm.Status.Conditions[0].Type == rolloutsmanagerv1alpha1.RolloutManagerConditionType &&
+				rm.Status.Conditions[0].Reason == rolloutsmanagerv1alpha1.RolloutManagerReasonUnsupportedImage &&
+				rm.Status.Conditions[0].Message == UnsupportedImageNotDigest &&
+				rm.Status.Conditions[0].Status == metav1.ConditionFalse).To(BeTrue())
+		})
+
+		It("should accept a RolloutManager whose image is pinned by digest, in digest-only mode", func() {
+			setEnv(DigestOnlyImagesEnvName, "true")
+			setEnv(RelatedImageRolloutsEnvName, testDigestImage)
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, rm)).To(Succeed())
+			Expect(rm.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess))
+			Expect(rm.Status.RelatedImages).To(ConsistOf(rolloutsmanagerv1alpha1.RelatedImage{Name: DefaultArgoRolloutsResourceName, Image: testDigestImage}))
+		})
+	})
+})
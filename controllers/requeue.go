@@ -0,0 +1,69 @@
+package rollouts
+
+import (
+	"math/rand"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// PendingRequeueInterval is the interval after which a RolloutManager is reconciled again, while its Rollouts controller is not yet available (it is Pending, Progressing or Degraded)
+	PendingRequeueInterval = 10 * time.Second
+
+	// AvailableRequeueInterval is the interval after which a RolloutManager is reconciled again, once its Rollouts controller is available (or reconciliation cannot proceed until the RolloutManager is changed). Changes to the RolloutManager and its resources are reconciled as soon as they occur: this only bounds the time until drift that is not reported by a watch is corrected.
+	AvailableRequeueInterval = 30 * time.Minute
+
+	// FailureRequeueBaseDelay and FailureRequeueMaxDelay bound the exponential backoff of a RolloutManager whose reconciliation fails repeatedly: the delay doubles with each consecutive failure, starting at FailureRequeueBaseDelay, up to FailureRequeueMaxDelay.
+	FailureRequeueBaseDelay = 5 * time.Second
+	FailureRequeueMaxDelay  = 10 * time.Minute
+
+	// requeueJitterFactor is the maximum fraction by which requeue intervals are randomly increased, so that the reconciliations of many RolloutManagers are spread out over time
+	requeueJitterFactor = 0.1
+)
+
+// withJitter returns the duration, randomly increased by up to requeueJitterFactor.
+func withJitter(duration time.Duration) time.Duration {
+	return duration + time.Duration(rand.Float64()*requeueJitterFactor*float64(duration))
+}
+
+// requeueIntervalForPhase returns the interval after which a successfully reconciled RolloutManager is reconciled again, based on the phase of its Rollouts controller: short while it is becoming available (a Degraded controller may recover without an event that triggers a reconciliation, for example once its image can be pulled), and long otherwise.
+func requeueIntervalForPhase(phase rolloutsmanagerv1alpha1.RolloutControllerPhase) time.Duration {
+	switch phase {
+	case rolloutsmanagerv1alpha1.PhasePending, rolloutsmanagerv1alpha1.PhaseProgressing, rolloutsmanagerv1alpha1.PhaseDegraded:
+		return PendingRequeueInterval
+	}
+	return AvailableRequeueInterval
+}
+
+// nextRequeueAfter returns the (jittered) requeue interval for the phase of the RolloutManager, or the requeue interval requested by the reconciliation (for example, when the next backup is due) if it is sooner.
+func nextRequeueAfter(phase rolloutsmanagerv1alpha1.RolloutControllerPhase, requestedRequeueAfter time.Duration) time.Duration {
+	requeueAfter := withJitter(requeueIntervalForPhase(phase))
+	if requestedRequeueAfter > 0 && requestedRequeueAfter < requeueAfter {
+		return requestedRequeueAfter
+	}
+	return requeueAfter
+}
+
+// jitteredRateLimiter adds jitter to the delays of a workqueue.RateLimiter, up to a maximum delay.
+type jitteredRateLimiter struct {
+	workqueue.RateLimiter
+	maxDelay time.Duration
+}
+
+func (j *jitteredRateLimiter) When(item interface{}) time.Duration {
+	delay := withJitter(j.RateLimiter.When(item))
+	if delay > j.maxDelay {
+		return j.maxDelay
+	}
+	return delay
+}
+
+// newFailureRateLimiter returns the rate limiter for RolloutManagers whose reconciliation failed: an exponential backoff per RolloutManager, from FailureRequeueBaseDelay up to FailureRequeueMaxDelay, with jitter. The backoff is reset once a reconciliation succeeds.
+func newFailureRateLimiter() workqueue.RateLimiter {
+	return &jitteredRateLimiter{
+		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(FailureRequeueBaseDelay, FailureRequeueMaxDelay),
+		maxDelay:    FailureRequeueMaxDelay,
+	}
+}
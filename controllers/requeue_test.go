@@ -0,0 +1,112 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// beWithinJitterOf succeeds if the duration is between the given duration and the given duration increased by requeueJitterFactor
+func beWithinJitterOf(duration time.Duration) OmegaMatcher {
+	return And(
+		BeNumerically(">=", duration),
+		BeNumerically("<=", duration+time.Duration(requeueJitterFactor*float64(duration))))
+}
+
+var _ = Describe("Requeue tests", func() {
+
+	Context("nextRequeueAfter", func() {
+
+		DescribeTable("should requeue based on the phase of the Rollouts controller", func(phase rolloutsmanagerv1alpha1.RolloutControllerPhase, expectedInterval time.Duration) {
+			Expect(nextRequeueAfter(phase, 0)).To(beWithinJitterOf(expectedInterval))
+		},
+			Entry("Pending", rolloutsmanagerv1alpha1.PhasePending, PendingRequeueInterval),
+			Entry("Progressing", rolloutsmanagerv1alpha1.PhaseProgressing, PendingRequeueInterval),
+			Entry("Degraded", rolloutsmanagerv1alpha1.PhaseDegraded, PendingRequeueInterval),
+			Entry("Available", rolloutsmanagerv1alpha1.PhaseAvailable, AvailableRequeueInterval),
+			Entry("Failure", rolloutsmanagerv1alpha1.PhaseFailure, AvailableRequeueInterval),
+			Entry("Unknown", rolloutsmanagerv1alpha1.PhaseUnknown, AvailableRequeueInterval),
+		)
+
+		It("should requeue after the requested interval, if it is sooner", func() {
+			Expect(nextRequeueAfter(rolloutsmanagerv1alpha1.PhaseAvailable, time.Minute)).To(Equal(time.Minute))
+			Expect(nextRequeueAfter(rolloutsmanagerv1alpha1.PhasePending, time.Hour)).To(beWithinJitterOf(PendingRequeueInterval))
+		})
+	})
+
+	Context("newFailureRateLimiter", func() {
+
+		It("should back off exponentially on repeated failures, up to the maximum delay, and reset once forgotten", func() {
+			rateLimiter := newFailureRateLimiter()
+			item := reconcile.Request{NamespacedName: types.NamespacedName{Name: "rollouts", Namespace: "test-ns"}}
+
+			Expect(rateLimiter.When(item)).To(beWithinJitterOf(FailureRequeueBaseDelay))
+			Expect(rateLimiter.When(item)).To(beWithinJitterOf(2 * FailureRequeueBaseDelay))
+			Expect(rateLimiter.When(item)).To(beWithinJitterOf(4 * FailureRequeueBaseDelay))
+
+			By("verifying that the backoff of other items is independent")
+			otherItem := reconcile.Request{NamespacedName: types.NamespacedName{Name: "other-rollouts", Namespace: "test-ns"}}
+			Expect(rateLimiter.When(otherItem)).To(beWithinJitterOf(FailureRequeueBaseDelay))
+
+			By("verifying that the delay is capped")
+			for i := 0; i < 20; i++ {
+				Expect(rateLimiter.When(item)).To(BeNumerically("<=", FailureRequeueMaxDelay))
+			}
+			Expect(rateLimiter.When(item)).To(Equal(FailureRequeueMaxDelay))
+
+			By("verifying that the backoff is reset once the item is forgotten")
+			rateLimiter.Forget(item)
+			Expect(rateLimiter.When(item)).To(beWithinJitterOf(FailureRequeueBaseDelay))
+		})
+	})
+
+	Context("Reconcile", func() {
+
+		It("should requeue with a short interval while the Rollouts controller is Pending, and a long interval once it is Available", func() {
+			ctx := context.Background()
+			rm := makeTestRolloutManager()
+			r := makeTestReconciler(rm)
+			Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+			defer os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}}
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("defaulting the replicas of the Rollouts controller Deployment, as the API server does")
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			deployment.Spec.Replicas = &[]int32{1}[0]
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			res, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(beWithinJitterOf(PendingRequeueInterval))
+
+			By("making the Rollouts controller Deployment available")
+			Expect(fetchObject(ctx, r.Client, rm.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			deployment.Status.Replicas = *deployment.Spec.Replicas
+			deployment.Status.UpdatedReplicas = *deployment.Spec.Replicas
+			deployment.Status.ReadyReplicas = *deployment.Spec.Replicas
+			Expect(r.Client.Status().Update(ctx, deployment)).To(Succeed())
+
+			res, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(beWithinJitterOf(AvailableRequeueInterval))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(rm), rm)).To(Succeed())
+			Expect(rm.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseAvailable))
+		})
+	})
+})
@@ -9,16 +9,18 @@ import (
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
-	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // Reconciles Rollouts ServiceAccount.
 func (r *RolloutManagerReconciler) reconcileRolloutsServiceAccount(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (*corev1.ServiceAccount, error) {
+	if err := r.injectFault(reconcileStepServiceAccount, cr); err != nil {
+		return nil, err
+	}
+
 	expectedServiceAccount := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      DefaultArgoRolloutsResourceName,
@@ -26,37 +28,13 @@ func (r *RolloutManagerReconciler) reconcileRolloutsServiceAccount(ctx context.C
 		},
 	}
 	setRolloutsLabelsAndAnnotationsToObject(&expectedServiceAccount.ObjectMeta, cr)
-
-	liveServiceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: expectedServiceAccount.Name, Namespace: expectedServiceAccount.Namespace}}
-	if err := fetchObject(ctx, r.Client, cr.Namespace, liveServiceAccount.Name, liveServiceAccount); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, fmt.Errorf("failed to get the ServiceAccount associated with %s: %w", liveServiceAccount.Name, err)
-		}
-
-		if err := controllerutil.SetControllerReference(&cr, expectedServiceAccount, r.Scheme); err != nil {
-			return nil, err
-		}
-
-		log.Info(fmt.Sprintf("Creating ServiceAccount %s", expectedServiceAccount.Name))
-		return expectedServiceAccount, r.Client.Create(ctx, expectedServiceAccount)
+	if err := r.prepareResource(ctx, cr, expectedServiceAccount); err != nil {
+		return nil, err
 	}
 
-	updateNeeded := false
-
-	normalizedLiveServiceAccount := liveServiceAccount.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveServiceAccount.ObjectMeta, cr)
-
-	if !reflect.DeepEqual(normalizedLiveServiceAccount.Labels, expectedServiceAccount.Labels) || !reflect.DeepEqual(normalizedLiveServiceAccount.Annotations, expectedServiceAccount.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of ServiceAccount %s do not match the expected state, hence updating it", liveServiceAccount.Name))
-
-		liveServiceAccount.Labels = combineStringMaps(liveServiceAccount.Labels, expectedServiceAccount.Labels)
-		liveServiceAccount.Annotations = combineStringMaps(liveServiceAccount.Annotations, expectedServiceAccount.Annotations)
-	}
-
-	if updateNeeded {
-		// Update if the Role already exists and needs to be modified
-		return liveServiceAccount, r.Client.Update(ctx, liveServiceAccount)
+	liveServiceAccount := &corev1.ServiceAccount{}
+	if _, err := r.applyResource(ctx, cr, expectedServiceAccount, liveServiceAccount, true, nil); err != nil {
+		return nil, err
 	}
 
 	return liveServiceAccount, nil
@@ -64,8 +42,6 @@ func (r *RolloutManagerReconciler) reconcileRolloutsServiceAccount(ctx context.C
 
 // Reconciles Rollouts Role.
 func (r *RolloutManagerReconciler) reconcileRolloutsRole(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (*rbacv1.Role, error) {
-	expectedPolicyRules := GetPolicyRules()
-
 	expectedRole := &rbacv1.Role{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      DefaultArgoRolloutsResourceName,
@@ -73,47 +49,16 @@ func (r *RolloutManagerReconciler) reconcileRolloutsRole(ctx context.Context, cr
 		},
 	}
 	setRolloutsLabelsAndAnnotationsToObject(&expectedRole.ObjectMeta, cr)
-
-	liveRole := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: expectedRole.Name, Namespace: expectedRole.Namespace}}
-
-	if err := fetchObject(ctx, r.Client, cr.Namespace, liveRole.Name, liveRole); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, fmt.Errorf("failed to reconcile the Role for the ServiceAccount associated with %s: %w", liveRole.Name, err)
-		}
-
-		if err = controllerutil.SetControllerReference(&cr, expectedRole, r.Scheme); err != nil {
-			return nil, err
-		}
-
-		log.Info(fmt.Sprintf("Creating Role %s", expectedRole.Name))
-		expectedRole.Rules = expectedPolicyRules
-		return expectedRole, r.Client.Create(ctx, expectedRole)
+	expectedRole.Rules = getRolloutsPolicyRules(cr)
+	if err := r.prepareResource(ctx, cr, expectedRole); err != nil {
+		return nil, err
 	}
 
-	updateNeeded := false
-
-	if !reflect.DeepEqual(liveRole.Rules, expectedPolicyRules) {
-		updateNeeded = true
-
-		log.Info(fmt.Sprintf("PolicyRules of Role %s do not match the expected state, hence updating it", liveRole.Name))
-		liveRole.Rules = expectedPolicyRules
-	}
-
-	normalizedLiveRole := liveRole.DeepCopy()
-
-	removeUserLabelsAndAnnotations(&normalizedLiveRole.ObjectMeta, cr)
-
-	if !reflect.DeepEqual(normalizedLiveRole.Labels, expectedRole.Labels) || !reflect.DeepEqual(normalizedLiveRole.Annotations, expectedRole.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of Role %s do not match the expected state, hence updating it", liveRole.Name))
-
-		liveRole.Labels = combineStringMaps(liveRole.Labels, expectedRole.Labels)
-		liveRole.Annotations = combineStringMaps(liveRole.Annotations, expectedRole.Annotations)
-	}
-
-	if updateNeeded {
-		// Update if the Role already exists and needs to be modified
-		return liveRole, r.Client.Update(ctx, liveRole)
+	liveRole := &rbacv1.Role{}
+	if _, err := r.applyResource(ctx, cr, expectedRole, liveRole, true, func() bool {
+		return updatePolicyRules(&liveRole.Rules, expectedRole.Rules, r.describeResource(cr, liveRole))
+	}); err != nil {
+		return nil, err
 	}
 
 	return liveRole, nil
@@ -121,48 +66,25 @@ func (r *RolloutManagerReconciler) reconcileRolloutsRole(ctx context.Context, cr
 
 // Reconciles Rollouts ClusterRole.
 func (r *RolloutManagerReconciler) reconcileRolloutsClusterRole(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (*rbacv1.ClusterRole, error) {
-	expectedPolicyRules := GetPolicyRules()
-
 	expectedClusterRole := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: DefaultArgoRolloutsResourceName,
 		},
 	}
 	setRolloutsLabelsAndAnnotationsToObject(&expectedClusterRole.ObjectMeta, cr)
-	liveClusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: expectedClusterRole.Name, Namespace: expectedClusterRole.Namespace}}
-	if err := fetchObject(ctx, r.Client, "", liveClusterRole.Name, liveClusterRole); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, fmt.Errorf("failed to Reconcile the ClusterRole for the ServiceAccount associated with %s: %w", liveClusterRole.Name, err)
-		}
-
-		log.Info(fmt.Sprintf("Creating ClusterRole %s", liveClusterRole.Name))
-		expectedClusterRole.Rules = expectedPolicyRules
-		return expectedClusterRole, r.Client.Create(ctx, expectedClusterRole)
-	}
-
-	updateNeeded := false
-
-	if !reflect.DeepEqual(liveClusterRole.Rules, expectedPolicyRules) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("PolicyRules of ClusterRole %s do not match the expected state, hence updating it", liveClusterRole.Name))
-		liveClusterRole.Rules = expectedPolicyRules
+	expectedClusterRole.Rules = getRolloutsPolicyRules(cr)
+	if err := r.prepareResource(ctx, cr, expectedClusterRole); err != nil {
+		return nil, err
 	}
 
-	normalizedLiveClusterRole := liveClusterRole.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveClusterRole.ObjectMeta, cr)
-
-	if !reflect.DeepEqual(normalizedLiveClusterRole.Labels, expectedClusterRole.Labels) || !reflect.DeepEqual(normalizedLiveClusterRole.Annotations, expectedClusterRole.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of Role %s do not match the expected state, hence updating it", liveClusterRole.Name))
-
-		liveClusterRole.Labels = combineStringMaps(liveClusterRole.Labels, expectedClusterRole.Labels)
-		liveClusterRole.Annotations = combineStringMaps(liveClusterRole.Annotations, expectedClusterRole.Annotations)
+	// A cluster-scoped resource cannot be owned by the (namespace-scoped) RolloutManager
+	liveClusterRole := &rbacv1.ClusterRole{}
+	if _, err := r.applyResource(ctx, cr, expectedClusterRole, liveClusterRole, false, func() bool {
+		return updatePolicyRules(&liveClusterRole.Rules, expectedClusterRole.Rules, r.describeResource(cr, liveClusterRole))
+	}); err != nil {
+		return nil, err
 	}
 
-	if updateNeeded {
-		// Update if the ClusterRole already exists and needs to be modified
-		return liveClusterRole, r.Client.Update(ctx, liveClusterRole)
-	}
 	return liveClusterRole, nil
 }
 
@@ -199,49 +121,16 @@ func (r *RolloutManagerReconciler) reconcileRolloutsRoleBinding(ctx context.Cont
 		},
 	}
 
-	// Fetch the RoleBinding if exists and store that in actualRoleBinding.
-	liveRoleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: expectedRoleBinding.Name, Namespace: expectedRoleBinding.Namespace}}
-	if err := fetchObject(ctx, r.Client, cr.Namespace, liveRoleBinding.Name, liveRoleBinding); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to get the RoleBinding associated with %s: %w", expectedRoleBinding.Name, err)
-		}
-
-		if err := controllerutil.SetControllerReference(&cr, expectedRoleBinding, r.Scheme); err != nil {
-			return err
-		}
-
-		log.Info(fmt.Sprintf("Creating RoleBinding %s", expectedRoleBinding.Name))
-		return r.Client.Create(ctx, expectedRoleBinding)
-	}
-
-	updateNeeded := false
-
-	// Reconcile if the RoleBinding already exists and modified.
-	if !reflect.DeepEqual(expectedRoleBinding.Subjects, liveRoleBinding.Subjects) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Subjects of RoleBinding %s do not match the expected state, hence updating it", liveRoleBinding.Name))
-		liveRoleBinding.Subjects = expectedRoleBinding.Subjects
-
-	}
-
-	normalizedLiveRoleBinding := liveRoleBinding.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveRoleBinding.ObjectMeta, cr)
-	if !reflect.DeepEqual(normalizedLiveRoleBinding.Labels, expectedRoleBinding.Labels) || !reflect.DeepEqual(normalizedLiveRoleBinding.Annotations, expectedRoleBinding.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of RoleBinding %s do not match the expected state, hence updating it", liveRoleBinding.Name))
-
-		liveRoleBinding.Labels = combineStringMaps(liveRoleBinding.Labels, expectedRoleBinding.Labels)
-		liveRoleBinding.Annotations = combineStringMaps(liveRoleBinding.Annotations, expectedRoleBinding.Annotations)
-	}
-
-	if updateNeeded {
-		// Update if the RoleBinding already exists and needs to be modified
-		if err := r.Client.Update(ctx, liveRoleBinding); err != nil {
-			return err
-		}
+	if err := r.prepareResource(ctx, cr, expectedRoleBinding); err != nil {
+		return err
 	}
 
-	return nil
+	// The RoleRef of a RoleBinding is immutable, hence only the Subjects are updated
+	liveRoleBinding := &rbacv1.RoleBinding{}
+	_, err := r.applyResource(ctx, cr, expectedRoleBinding, liveRoleBinding, true, func() bool {
+		return updateSubjects(&liveRoleBinding.Subjects, expectedRoleBinding.Subjects, r.describeResource(cr, liveRoleBinding))
+	})
+	return err
 }
 
 // Reconcile Rollouts ClusterRoleBinding.
@@ -276,48 +165,45 @@ func (r *RolloutManagerReconciler) reconcileRolloutsClusterRoleBinding(ctx conte
 		},
 	}
 
-	// Fetch the ClusterRoleBinding if exists and store that in actualClusterRoleBinding.
-	liveClusterRoleBinding := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: expectedClusterRoleBinding.Name}}
-	if err := fetchObject(ctx, r.Client, "", liveClusterRoleBinding.Name, liveClusterRoleBinding); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to get the ClusterRoleBinding associated with %s: %w", expectedClusterRoleBinding.Name, err)
-		}
-
-		log.Info(fmt.Sprintf("Creating ClusterRoleBinding %s", expectedClusterRoleBinding.Name))
-		return r.Client.Create(ctx, expectedClusterRoleBinding)
-	}
-
-	updateNeeded := false
-
-	if !reflect.DeepEqual(expectedClusterRoleBinding.Subjects, liveClusterRoleBinding.Subjects) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Subjects of ClusterRoleBinding %s do not match the expected state, hence updating it", expectedClusterRoleBinding.Name))
-		liveClusterRoleBinding.Subjects = expectedClusterRoleBinding.Subjects
+	if err := r.prepareResource(ctx, cr, expectedClusterRoleBinding); err != nil {
+		return err
 	}
 
-	normalizedLiveClusterRoleBinding := liveClusterRoleBinding.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveClusterRoleBinding.ObjectMeta, cr)
-	if !reflect.DeepEqual(normalizedLiveClusterRoleBinding.Labels, expectedClusterRoleBinding.Labels) || !reflect.DeepEqual(normalizedLiveClusterRoleBinding.Annotations, expectedClusterRoleBinding.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of ClusterRoleBinding %s do not match the expected state, hence updating it", liveClusterRoleBinding.Name))
+	liveClusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+	_, err := r.applyResource(ctx, cr, expectedClusterRoleBinding, liveClusterRoleBinding, false, func() bool {
+		return updateSubjects(&liveClusterRoleBinding.Subjects, expectedClusterRoleBinding.Subjects, r.describeResource(cr, liveClusterRoleBinding))
+	})
+	return err
+}
 
-		liveClusterRoleBinding.Labels = combineStringMaps(liveClusterRoleBinding.Labels, expectedClusterRoleBinding.Labels)
-		liveClusterRoleBinding.Annotations = combineStringMaps(liveClusterRoleBinding.Annotations, expectedClusterRoleBinding.Annotations)
+// updatePolicyRules sets the live PolicyRules of a (Cluster)Role to the expected ones, and returns true if they did not match.
+func updatePolicyRules(live *[]rbacv1.PolicyRule, expected []rbacv1.PolicyRule, description string) bool {
+	if equalNormalized(expected, *live) {
+		return false
 	}
+	log.Info(fmt.Sprintf("PolicyRules of %s do not match the expected state, hence updating it", description))
+	*live = expected
+	return true
+}
 
-	if updateNeeded {
-		// Update if the ClusterRoleBinding already exists and needs to be modified
-		if err := r.Client.Update(ctx, liveClusterRoleBinding); err != nil {
-			return err
-		}
+// updateSubjects sets the live Subjects of a (Cluster)RoleBinding to the expected ones, and returns true if they did not match.
+func updateSubjects(live *[]rbacv1.Subject, expected []rbacv1.Subject, description string) bool {
+	if equalNormalized(expected, *live) {
+		return false
 	}
-
-	return nil
+	log.Info(fmt.Sprintf("Subjects of %s do not match the expected state, hence updating it", description))
+	*live = expected
+	return true
 }
 
 // removeClusterScopedResourcesIfApplicable will remove the ClusterRole and ClusterRoleBinding that are created when a cluster-scoped RolloutManager is created.
 func (r *RolloutManagerReconciler) removeClusterScopedResourcesIfApplicable(ctx context.Context) error {
 
+	if r.clusterAPIDisabled() {
+		// No cluster-scoped resources are created when the cluster API is disabled
+		return nil
+	}
+
 	clusterRole := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: DefaultArgoRolloutsResourceName,
@@ -390,120 +276,48 @@ func (r *RolloutManagerReconciler) removeClusterScopedResourcesIfApplicable(ctx
 		}
 	}
 
+	// The ClusterRole and ClusterRoleBinding of the dashboard, if it was enabled (those of the same name which were not created by the operator are left alone)
+	for _, obj := range []client.Object{&rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{}} {
+		if err := fetchObject(ctx, r.Client, "", DefaultArgoRolloutsDashboardResourceName, obj); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		if !isOperatorDashboardResource(obj) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, obj); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		log.Info("deleted Rollouts dashboard " + r.resourceKind(obj) + " for RolloutManager that no longer exists")
+	}
+
 	return nil
 }
 
 // Reconciles aggregate-to-admin ClusterRole.
 func (r *RolloutManagerReconciler) reconcileRolloutsAggregateToAdminClusterRole(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
-
-	var aggregationType string = "aggregate-to-admin"
-	name := fmt.Sprintf("%s-%s", DefaultArgoRolloutsResourceName, aggregationType)
-
-	expectedPolicyRules := GetAggregateToAdminPolicyRules()
-
-	expectedClusterRole := &rbacv1.ClusterRole{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
-		},
-	}
-	setRolloutsAggregatedClusterRoleLabels(&expectedClusterRole.ObjectMeta, name, aggregationType)
-	setAdditionalRolloutsLabelsAndAnnotationsToObject(&expectedClusterRole.ObjectMeta, cr)
-
-	liveClusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: expectedClusterRole.Name}}
-	if err := fetchObject(ctx, r.Client, "", liveClusterRole.Name, liveClusterRole); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to reconcile the aggregated ClusterRole %s: %w", liveClusterRole.Name, err)
-		}
-
-		log.Info(fmt.Sprintf("Creating aggregated ClusterRole %s", liveClusterRole.Name))
-		expectedClusterRole.Rules = expectedPolicyRules
-		return r.Client.Create(ctx, expectedClusterRole)
-	}
-
-	updateNeeded := false
-
-	if !reflect.DeepEqual(liveClusterRole.Rules, expectedPolicyRules) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("PolicyRules of ClusterRole %s do not match the expected state, hence updating it", liveClusterRole.Name))
-		liveClusterRole.Rules = expectedPolicyRules
-	}
-
-	normalizedLiveClusterRole := liveClusterRole.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveClusterRole.ObjectMeta, cr)
-	if !reflect.DeepEqual(normalizedLiveClusterRole.Labels, expectedClusterRole.Labels) || !reflect.DeepEqual(normalizedLiveClusterRole.Annotations, expectedClusterRole.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of aggregated ClusterRole %s do not match the expected state, hence updating it", liveClusterRole.Name))
-
-		liveClusterRole.Labels = combineStringMaps(liveClusterRole.Labels, expectedClusterRole.Labels)
-		liveClusterRole.Annotations = combineStringMaps(liveClusterRole.Annotations, expectedClusterRole.Annotations)
-	}
-
-	if updateNeeded {
-		// Update if the aggregated ClusterRole already exists and needs to be modified
-		return r.Client.Update(ctx, liveClusterRole)
-	}
-	return nil
+	return r.reconcileRolloutsAggregatedClusterRole(ctx, cr, "aggregate-to-admin", GetAggregateToAdminPolicyRules())
 }
 
 // Reconciles aggregate-to-edit ClusterRole.
 func (r *RolloutManagerReconciler) reconcileRolloutsAggregateToEditClusterRole(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
-
-	var aggregationType string = "aggregate-to-edit"
-	name := fmt.Sprintf("%s-%s", DefaultArgoRolloutsResourceName, aggregationType)
-
-	expectedPolicyRules := GetAggregateToEditPolicyRules()
-
-	expectedClusterRole := &rbacv1.ClusterRole{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
-		},
-	}
-	setRolloutsAggregatedClusterRoleLabels(&expectedClusterRole.ObjectMeta, name, aggregationType)
-	setAdditionalRolloutsLabelsAndAnnotationsToObject(&expectedClusterRole.ObjectMeta, cr)
-
-	liveClusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: expectedClusterRole.Name}}
-	if err := fetchObject(ctx, r.Client, "", liveClusterRole.Name, liveClusterRole); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to reconcile the aggregated ClusterRole %s: %w", liveClusterRole.Name, err)
-		}
-
-		log.Info(fmt.Sprintf("Creating aggregated ClusterRole %s", expectedClusterRole.Name))
-		expectedClusterRole.Rules = expectedPolicyRules
-		return r.Client.Create(ctx, expectedClusterRole)
-	}
-
-	updateNeeded := false
-
-	if !reflect.DeepEqual(liveClusterRole.Rules, expectedPolicyRules) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("PolicyRules of ClusterRole %s do not match the expected state, hence updating it", liveClusterRole.Name))
-		liveClusterRole.Rules = expectedPolicyRules
-	}
-
-	normalizedLiveClusterRole := liveClusterRole.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveClusterRole.ObjectMeta, cr)
-	if !reflect.DeepEqual(normalizedLiveClusterRole.Labels, expectedClusterRole.Labels) || !reflect.DeepEqual(normalizedLiveClusterRole.Annotations, expectedClusterRole.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of aggregated ClusterRole %s do not match the expected state, hence updating it", liveClusterRole.Name))
-
-		liveClusterRole.Labels = combineStringMaps(liveClusterRole.Labels, expectedClusterRole.Labels)
-		liveClusterRole.Annotations = combineStringMaps(liveClusterRole.Annotations, expectedClusterRole.Annotations)
-	}
-
-	if updateNeeded {
-		// Update if the aggregated ClusterRole already exists and needs to be modified
-		return r.Client.Update(ctx, liveClusterRole)
-	}
-	return nil
+	return r.reconcileRolloutsAggregatedClusterRole(ctx, cr, "aggregate-to-edit", GetAggregateToEditPolicyRules())
 }
 
 // Reconciles aggregate-to-view ClusterRole.
 func (r *RolloutManagerReconciler) reconcileRolloutsAggregateToViewClusterRole(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+	return r.reconcileRolloutsAggregatedClusterRole(ctx, cr, "aggregate-to-view", GetAggregateToViewPolicyRules())
+}
 
-	var aggregationType string = "aggregate-to-view"
-	name := fmt.Sprintf("%s-%s", DefaultArgoRolloutsResourceName, aggregationType)
+// reconcileRolloutsAggregatedClusterRole reconciles the ClusterRole which aggregates the given PolicyRules to the user-facing ClusterRole of the aggregation type (for example, 'aggregate-to-admin').
+func (r *RolloutManagerReconciler) reconcileRolloutsAggregatedClusterRole(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, aggregationType string, expectedPolicyRules []rbacv1.PolicyRule) error {
 
-	expectedPolicyRules := GetAggregateToViewPolicyRules()
+	name := fmt.Sprintf("%s-%s", DefaultArgoRolloutsResourceName, aggregationType)
 
 	expectedClusterRole := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
@@ -512,66 +326,52 @@ func (r *RolloutManagerReconciler) reconcileRolloutsAggregateToViewClusterRole(c
 	}
 	setRolloutsAggregatedClusterRoleLabels(&expectedClusterRole.ObjectMeta, name, aggregationType)
 	setAdditionalRolloutsLabelsAndAnnotationsToObject(&expectedClusterRole.ObjectMeta, cr)
-
-	liveClusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: expectedClusterRole.Name, Namespace: expectedClusterRole.Namespace}}
-	if err := fetchObject(ctx, r.Client, "", liveClusterRole.Name, liveClusterRole); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to reconcile the aggregated ClusterRole %s: %w", liveClusterRole.Name, err)
-		}
-
-		log.Info(fmt.Sprintf("Creating aggregated ClusterRole %s", expectedClusterRole.Name))
-		expectedClusterRole.Rules = expectedPolicyRules
-		return r.Client.Create(ctx, expectedClusterRole)
-	}
-
-	updateNeeded := false
-
-	if !reflect.DeepEqual(liveClusterRole.Rules, expectedPolicyRules) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("PolicyRules of ClusterRole %s do not match the expected state, hence updating it", liveClusterRole.Name))
-		liveClusterRole.Rules = expectedPolicyRules
-	}
-
-	normalizedLiveClusterRole := liveClusterRole.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveClusterRole.ObjectMeta, cr)
-	if !reflect.DeepEqual(normalizedLiveClusterRole.Labels, expectedClusterRole.Labels) || !reflect.DeepEqual(normalizedLiveClusterRole.Annotations, expectedClusterRole.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of aggregated ClusterRole %s do not match the expected state, hence updating it", liveClusterRole.Name))
-
-		liveClusterRole.Labels = combineStringMaps(liveClusterRole.Labels, expectedClusterRole.Labels)
-		liveClusterRole.Annotations = combineStringMaps(liveClusterRole.Annotations, expectedClusterRole.Annotations)
-	}
-
-	if updateNeeded {
-		// Update if the aggregated ClusterRole already exists and needs to be modified
-		return r.Client.Update(ctx, liveClusterRole)
+	expectedClusterRole.Rules = expectedPolicyRules
+	if err := r.prepareResource(ctx, cr, expectedClusterRole); err != nil {
+		return err
 	}
 
-	return nil
+	liveClusterRole := &rbacv1.ClusterRole{}
+	_, err := r.applyResource(ctx, cr, expectedClusterRole, liveClusterRole, false, func() bool {
+		return updatePolicyRules(&liveClusterRole.Rules, expectedClusterRole.Rules, r.describeResource(cr, liveClusterRole))
+	})
+	return err
 }
 
 // reconcileRolloutsMetricsServiceAndMonitor reconciles the Rollouts Metrics Service and ServiceMonitor
 func (r *RolloutManagerReconciler) reconcileRolloutsMetricsServiceAndMonitor(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
 
-	reconciledSvc, err := r.reconcileRolloutsMetricsService(ctx, cr)
-	if err != nil {
-		return fmt.Errorf("unable to reconcile metrics service: %w", err)
-	}
-
-	// Checks if user is using the Prometheus operator by checking CustomResourceDefinition for ServiceMonitor
-	smCRD := &crdv1.CustomResourceDefinition{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: serviceMonitorsCRDName,
-		},
+	// The ServiceMonitor selects the metrics Service by name, whether it is managed by the operator or not
+	reconciledSvc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsMetricsServiceName, Namespace: cr.Namespace}}
+	if isResourceSkipped(cr, rolloutsmanagerv1alpha1.SkippableResourceMetricsService) {
+		if err := r.deleteSkippedResource(ctx, cr, reconciledSvc); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if reconciledSvc, err = r.reconcileRolloutsMetricsService(ctx, cr); err != nil {
+			return fmt.Errorf("unable to reconcile metrics service: %w", err)
+		}
 	}
 
-	if err := fetchObject(ctx, r.Client, smCRD.Namespace, smCRD.Name, smCRD); err != nil {
+	// Checks if user is using the Prometheus operator by checking CustomResourceDefinition for ServiceMonitor (or, if the cluster API is disabled, by listing ServiceMonitors)
+	if r.clusterAPIDisabled() {
+		if served, err := r.isAPIServedInNamespace(ctx, &monitoringv1.ServiceMonitorList{}, cr.Namespace); err != nil {
+			return fmt.Errorf("failed to list ServiceMonitors: %w", err)
+		} else if !served {
+			return nil
+		}
+	} else if _, err := fetchObjectMetadata(ctx, r.Client, customResourceDefinitionGVK, "", serviceMonitorsCRDName); err != nil {
 		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to get the ServiceMonitor %s : %s", smCRD.Name, err)
+			return fmt.Errorf("failed to get the ServiceMonitor %s : %s", serviceMonitorsCRDName, err)
 		}
 		return nil
 	}
 
+	if isResourceSkipped(cr, rolloutsmanagerv1alpha1.SkippableResourceServiceMonitor) {
+		return r.deleteSkippedResource(ctx, cr, &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: cr.Namespace}})
+	}
+
 	// Create ServiceMonitor for Rollouts metrics
 	existingServiceMonitor := &monitoringv1.ServiceMonitor{}
 	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, existingServiceMonitor); err != nil {
@@ -591,7 +391,7 @@ func (r *RolloutManagerReconciler) reconcileRolloutsMetricsServiceAndMonitor(ctx
 			"Namespace", existingServiceMonitor.Namespace, "Name", existingServiceMonitor.Name)
 
 		// Check if existing ServiceMonitor matches expected content
-		if !serviceMonitorMatches(existingServiceMonitor, reconciledSvc.Name) {
+		if !serviceMonitorMatches(existingServiceMonitor, reconciledSvc.Name, cr) {
 			log.Info("Updating existing ServiceMonitor instance",
 				"Namespace", existingServiceMonitor.Namespace, "Name", existingServiceMonitor.Name)
 
@@ -599,11 +399,7 @@ func (r *RolloutManagerReconciler) reconcileRolloutsMetricsServiceAndMonitor(ctx
 			existingServiceMonitor.Spec.Selector.MatchLabels = map[string]string{
 				"app.kubernetes.io/name": reconciledSvc.Name,
 			}
-			existingServiceMonitor.Spec.Endpoints = []monitoringv1.Endpoint{
-				{
-					Port: "metrics",
-				},
-			}
+			existingServiceMonitor.Spec.Endpoints = rolloutsMetricsEndpoints(cr)
 
 			if err := r.Client.Update(ctx, existingServiceMonitor); err != nil {
 				log.Error(err, "Error updating existing ServiceMonitor instance",
@@ -629,67 +425,77 @@ func (r *RolloutManagerReconciler) reconcileRolloutsMetricsService(ctx context.C
 	// overwrite the annotations for Rollouts Metrics Service
 	expectedSvc.ObjectMeta.Labels["app.kubernetes.io/name"] = DefaultArgoRolloutsMetricsServiceName
 	expectedSvc.ObjectMeta.Labels["app.kubernetes.io/component"] = "server"
+	expectedSvc.ObjectMeta.Labels[RolloutManagerLabel] = cr.Name
 
 	expectedSvc.Spec.Ports = []corev1.ServicePort{
 		{
-			Name:       "metrics",
+			Name:       getRolloutsMetricsPortName(cr),
 			Port:       8090,
 			Protocol:   corev1.ProtocolTCP,
-			TargetPort: intstr.FromInt(8090),
+			TargetPort: intstr.FromInt(int(getRolloutsMetricsPort(cr))),
 		},
 	}
+	expectedSvc.Spec.Ports = append(expectedSvc.Spec.Ports, getExtraServicePorts(cr, rolloutsmanagerv1alpha1.ExtraPortExposeMetricsService)...)
 
 	expectedSvc.Spec.Selector = map[string]string{
 		DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName,
 	}
+	setServiceIPFamilies(expectedSvc, cr.Spec.MetricsService)
+	setServiceType(expectedSvc, cr.Spec.MetricsService)
+	if err := r.prepareResource(ctx, cr, expectedSvc); err != nil {
+		return nil, err
+	}
 
-	liveService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: expectedSvc.Name, Namespace: expectedSvc.Namespace}}
-	if err := fetchObject(ctx, r.Client, cr.Namespace, liveService.Name, liveService); err != nil {
+	// The primary IP family and the cluster IP of a Service are immutable, hence the Service is recreated if they changed
+	liveService := &corev1.Service{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, expectedSvc.Name, liveService); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return nil, fmt.Errorf("failed to get the Service %s: %w", expectedSvc.Name, err)
 		}
-
-		if err := controllerutil.SetControllerReference(&cr, expectedSvc, r.Scheme); err != nil {
-			return nil, err
+	} else if servicePrimaryIPFamilyChanged(liveService, expectedSvc) || serviceHeadlessChanged(liveService, expectedSvc) {
+		log.Info(fmt.Sprintf("Primary IP family or cluster IP of metrics Service %s does not match the expected state, hence recreating it", liveService.Name))
+		if err := r.Client.Delete(ctx, liveService); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete the Service %s: %w", liveService.Name, err)
 		}
-
-		log.Info(fmt.Sprintf("Creating Service %s", expectedSvc.Name))
-		if err := r.Client.Create(ctx, expectedSvc); err != nil {
-			log.Error(err, "Error creating Service", "Name", expectedSvc.Name)
-			return nil, err
-		}
-		liveService = expectedSvc
-
+		setReplacedUIDAnnotation(&expectedSvc.ObjectMeta, liveService.UID)
 	}
 
-	updateNeeded := false
+	if _, err := r.applyResource(ctx, cr, expectedSvc, liveService, true, func() bool {
+		preserveServiceNodePorts(liveService, expectedSvc)
+		updateNeeded := updateServicePorts(liveService, expectedSvc, r.describeResource(cr, liveService))
 
-	if !reflect.DeepEqual(liveService.Spec.Ports, expectedSvc.Spec.Ports) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Ports of metrics Service %s do not match the expected state, hence updating it", liveService.Name))
-		liveService.Spec.Ports = expectedSvc.Spec.Ports
-	}
-
-	normalizedLiveService := liveService.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveService.ObjectMeta, cr)
-	if !reflect.DeepEqual(normalizedLiveService.Labels, expectedSvc.Labels) || !reflect.DeepEqual(normalizedLiveService.Annotations, expectedSvc.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of metrics Service %s do not match the expected state, hence updating it", liveService.Name))
-
-		liveService.Labels = combineStringMaps(liveService.Labels, expectedSvc.Labels)
-		liveService.Annotations = combineStringMaps(liveService.Annotations, expectedSvc.Annotations)
-	}
+		if liveService.Spec.Type != expectedSvc.Spec.Type {
+			updateNeeded = true
+			log.Info(fmt.Sprintf("Type of metrics Service %s does not match the expected state, hence updating it", liveService.Name))
+			liveService.Spec.Type = expectedSvc.Spec.Type
+		}
 
-	if updateNeeded {
-		// Update if the Service already exists and needs to be modified
-		if err := r.Client.Update(ctx, liveService); err != nil {
-			log.Error(err, "Error updating Ports of metrics Service", "Name", liveService.Name)
-			return liveService, err
+		if !serviceIPFamiliesMatch(liveService, expectedSvc) {
+			updateNeeded = true
+			log.Info(fmt.Sprintf("IP families of metrics Service %s do not match the expected state, hence updating it", liveService.Name))
+			if expectedSvc.Spec.IPFamilyPolicy != nil {
+				liveService.Spec.IPFamilyPolicy = expectedSvc.Spec.IPFamilyPolicy
+			}
+			if len(expectedSvc.Spec.IPFamilies) > 0 {
+				liveService.Spec.IPFamilies = expectedSvc.Spec.IPFamilies
+			}
 		}
+		return updateNeeded
+	}); err != nil {
+		return nil, err
 	}
 
 	return liveService, nil
+}
 
+// updateServicePorts sets the live Ports of a Service to the expected ones, and returns true if they did not match.
+func updateServicePorts(liveService *corev1.Service, expectedSvc *corev1.Service, description string) bool {
+	if equalNormalized(expectedSvc.Spec.Ports, liveService.Spec.Ports) {
+		return false
+	}
+	log.Info(fmt.Sprintf("Ports of %s do not match the expected state, hence updating it", description))
+	liveService.Spec.Ports = expectedSvc.Spec.Ports
+	return true
 }
 
 // Reconciles Secrets for Rollouts controller
@@ -700,68 +506,51 @@ func (r *RolloutManagerReconciler) reconcileRolloutsSecrets(ctx context.Context,
 			Name:      DefaultRolloutsNotificationSecretName,
 			Namespace: cr.Namespace,
 		},
-		Type: corev1.SecretTypeOpaque,
 	}
+	setManagedSecretOptions(expectedSecret, cr)
 
 	setRolloutsLabelsAndAnnotationsToObject(&expectedSecret.ObjectMeta, cr)
+	if err := r.prepareResource(ctx, cr, expectedSecret); err != nil {
+		return err
+	}
 
-	// If the Secret doesn't exist (or an unrelated error occurred)....
-	liveSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: expectedSecret.Name, Namespace: expectedSecret.Namespace}}
-	if err := fetchObject(ctx, r.Client, cr.Namespace, liveSecret.Name, liveSecret); err != nil {
-		if !apierrors.IsNotFound(err) { // unrelated error: return
-			return fmt.Errorf("failed to get the Secret %s: %w", liveSecret.Name, err)
-		}
-
-		if cr.Spec.SkipNotificationSecretDeployment {
-			// Secret does not exist, but SkipNotificationSecretDeployment is set to true, hence skipping the creation
-			return nil
+	deploySecret := !cr.Spec.SkipNotificationSecretDeployment
+	if deploySecret && r.FeatureGates.Enabled(NotificationSecretOnDemand) {
+		needed, err := r.isNotificationSecretNeeded(ctx, cr)
+		if err != nil {
+			return err
 		}
+		deploySecret = needed
+	}
 
-		// Secret does not exist (and SkipNotificationSecretDeployment is set to false) so create Secret
-		if err := controllerutil.SetControllerReference(&cr, expectedSecret, r.Scheme); err != nil {
+	if deploySecret {
+		if err := r.replaceNotificationSecretIfNeeded(ctx, cr, expectedSecret); err != nil {
 			return err
 		}
 
-		log.Info(fmt.Sprintf("Creating Secret %s", expectedSecret.Name))
-		return r.Client.Create(ctx, expectedSecret)
-
+		// Only the labels/annotations of the Secret are reconciled: its data is provided by users
+		_, err := r.applyResource(ctx, cr, expectedSecret, &corev1.Secret{}, true, nil)
+		return err
 	}
 
-	// If SkipNotificationSecretDeployment is true, and the secret exists (and is owned by us), delete it
-	if cr.Spec.SkipNotificationSecretDeployment {
-
-		// If the controller created/owns the Secret, delete it
-		controller := metav1.GetControllerOf(liveSecret)
-		if controller != nil && controller.Name == cr.Name {
-			log.Info(fmt.Sprintf("SkipNotificationSecretDeployment has been set to true, deleting secret %s", liveSecret.Name))
-			return r.Client.Delete(ctx, liveSecret)
+	liveSecret := &corev1.Secret{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, expectedSecret.Name, liveSecret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the Secret %s: %w", expectedSecret.Name, err)
 		}
 
-		// Otherwise, the secret exists, but the controller didn't create it, so just return (don't touch it)
+		// Secret does not exist, and it should not be deployed, hence there is nothing to do
 		return nil
 	}
 
-	// Otherwise, the Secret exists, so update it if the labels/annotations are inconsistent
-
-	updateNeeded := false
-
-	normalizedLiveSecret := liveSecret.DeepCopy()
-	removeUserLabelsAndAnnotations(&normalizedLiveSecret.ObjectMeta, cr)
-
-	if !reflect.DeepEqual(normalizedLiveSecret.Labels, expectedSecret.Labels) || !reflect.DeepEqual(normalizedLiveSecret.Annotations, expectedSecret.Annotations) {
-		updateNeeded = true
-		log.Info(fmt.Sprintf("Labels/Annotations of Secret %s do not match the expected state, hence updating it", liveSecret.Name))
-
-		liveSecret.Labels = combineStringMaps(liveSecret.Labels, expectedSecret.Labels)
-		liveSecret.Annotations = combineStringMaps(liveSecret.Annotations, expectedSecret.Annotations)
-	}
-
-	if updateNeeded {
-		// Update if the Secret already exists and needs to be modified
-		return r.Client.Update(ctx, liveSecret)
+	// If the secret should not be deployed (SkipNotificationSecretDeployment is true, or notifications are not configured), and it exists (and is owned by us), delete it
+	controller := metav1.GetControllerOf(liveSecret)
+	if controller != nil && controller.Name == cr.Name {
+		log.Info(fmt.Sprintf("Notification secret should not be deployed, deleting secret %s", liveSecret.Name))
+		return r.Client.Delete(ctx, liveSecret)
 	}
 
-	// secret found, do nothing
+	// Otherwise, the secret exists, but the controller didn't create it, so just return (don't touch it)
 	return nil
 }
 
@@ -1218,18 +1007,14 @@ func (r *RolloutManagerReconciler) createServiceMonitorIfAbsent(ctx context.Cont
 					"app.kubernetes.io/name": serviceMonitorLabel,
 				},
 			},
-			Endpoints: []monitoringv1.Endpoint{
-				{
-					Port: "metrics",
-				},
-			},
+			Endpoints: rolloutsMetricsEndpoints(rolloutManager),
 		},
 	}
 	log.Info("Creating a new ServiceMonitor instance",
 		"Namespace", serviceMonitor.Namespace, "Name", serviceMonitor.Name)
 
 	// Set the RolloutManager instance as the owner and controller
-	if err := controllerutil.SetControllerReference(&rolloutManager, serviceMonitor, r.Scheme); err != nil {
+	if err := r.setControllerReference(&rolloutManager, serviceMonitor); err != nil {
 		log.Error(err, "Error setting read role owner ref",
 			"Namespace", serviceMonitor.Namespace, "Name", serviceMonitor.Name, "RolloutManager Name", rolloutManager.Name)
 		return err
@@ -1246,7 +1031,7 @@ func (r *RolloutManagerReconciler) createServiceMonitorIfAbsent(ctx context.Cont
 
 }
 
-func serviceMonitorMatches(sm *monitoringv1.ServiceMonitor, matchLabel string) bool {
+func serviceMonitorMatches(sm *monitoringv1.ServiceMonitor, matchLabel string, cr rolloutsmanagerv1alpha1.RolloutManager) bool {
 	// Check if labels match
 	labels := sm.Spec.Selector.MatchLabels
 	if val, ok := labels["app.kubernetes.io/name"]; ok {
@@ -1258,9 +1043,21 @@ func serviceMonitorMatches(sm *monitoringv1.ServiceMonitor, matchLabel string) b
 	}
 
 	// Check if endpoints match
-	if len(sm.Spec.Endpoints) == 0 || sm.Spec.Endpoints[0].Port != "metrics" {
-		return false
-	}
+	return reflect.DeepEqual(sm.Spec.Endpoints, rolloutsMetricsEndpoints(cr))
+}
 
-	return true
+// rolloutsMetricsEndpoints returns the endpoints of the ServiceMonitor for the Rollouts controller metrics, which scrape the metrics port of the metrics Service (see getRolloutsMetricsPortName).
+// The RolloutManagerLabel of the metrics Service is added to the metrics as the RolloutManagerMetricsLabel, so that the metrics of multiple Rollouts controllers (for example, of namespace-scoped RolloutManagers) can be distinguished in dashboards.
+func rolloutsMetricsEndpoints(cr rolloutsmanagerv1alpha1.RolloutManager) []monitoringv1.Endpoint {
+	return []monitoringv1.Endpoint{
+		{
+			Port: getRolloutsMetricsPortName(cr),
+			RelabelConfigs: []*monitoringv1.RelabelConfig{
+				{
+					SourceLabels: []string{rolloutManagerServiceLabelMeta},
+					TargetLabel:  RolloutManagerMetricsLabel,
+				},
+			},
+		},
+	}
 }
@@ -1153,11 +1153,7 @@ func serviceMonitor() *monitoringv1.ServiceMonitor {
 					"app.kubernetes.io/name": DefaultArgoRolloutsMetricsServiceName,
 				},
 			},
-			Endpoints: []monitoringv1.Endpoint{
-				{
-					Port: "metrics",
-				},
-			},
+			Endpoints: rolloutsMetricsEndpoints(*makeTestRolloutManager()),
 		},
 	}
 	return sm
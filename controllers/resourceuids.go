@@ -0,0 +1,111 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventReasonResourceRecreatedExternally is the reason of the Warning Events recorded when a resource managed by the operator was deleted and recreated without the operator replacing it (for example, by another controller, or by a human).
+	EventReasonResourceRecreatedExternally = "ResourceRecreatedExternally"
+
+	// ReplacedUIDAnnotation is set by the operator on the resources that it deletes and recreates itself (for example, when an immutable field changes), and contains the UID of the resource that was replaced, so that the new UID is not reported as a recreation by another actor.
+	ReplacedUIDAnnotation = "argo-rollouts.argoproj.io/replaced-uid"
+)
+
+// trackedComponent is a resource managed by the operator for a RolloutManager, whose UID is recorded in .status.components
+type trackedComponent struct {
+	kind string
+	obj  client.Object
+}
+
+// getTrackedComponents returns the resources whose UIDs are recorded in .status.components: the resources of the Rollouts controller, in the namespace of the RolloutManager, and its ClusterRole and ClusterRoleBinding if it is cluster-scoped.
+func getTrackedComponents(cr rolloutsmanagerv1alpha1.RolloutManager) []trackedComponent {
+
+	res := []trackedComponent{
+		{kind: "ServiceAccount", obj: &corev1.ServiceAccount{}},
+	}
+
+	if cr.Spec.NamespaceScoped {
+		res = append(res,
+			trackedComponent{kind: "Role", obj: &rbacv1.Role{}},
+			trackedComponent{kind: "RoleBinding", obj: &rbacv1.RoleBinding{}})
+	} else {
+		res = append(res,
+			trackedComponent{kind: "ClusterRole", obj: &rbacv1.ClusterRole{}},
+			trackedComponent{kind: "ClusterRoleBinding", obj: &rbacv1.ClusterRoleBinding{}})
+	}
+
+	res = append(res,
+		trackedComponent{kind: "Deployment", obj: &appsv1.Deployment{}},
+		trackedComponent{kind: "Service", obj: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsMetricsServiceName}}},
+		trackedComponent{kind: "ConfigMap", obj: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsConfigMapName}}},
+		trackedComponent{kind: "Secret", obj: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationSecretName}}})
+
+	for _, component := range res {
+		if component.obj.GetName() == "" {
+			component.obj.SetName(DefaultArgoRolloutsResourceName)
+		}
+		if component.kind != "ClusterRole" && component.kind != "ClusterRoleBinding" {
+			component.obj.SetNamespace(cr.Namespace)
+		}
+	}
+
+	return res
+}
+
+// reconcileComponentStatuses returns the UIDs of the tracked resources of the RolloutManager which exist (see getTrackedComponents), to be recorded in .status.components.
+// A Warning Event is recorded for each resource whose UID differs from the one recorded in .status.components, unless the operator replaced the resource itself (see ReplacedUIDAnnotation): the resource was then deleted, and recreated, by another actor (or by the operator, after another actor deleted it).
+func (r *RolloutManagerReconciler) reconcileComponentStatuses(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) ([]rolloutsmanagerv1alpha1.RolloutManagerComponentStatus, error) {
+
+	previousUIDs := map[string]types.UID{}
+	for _, component := range cr.Status.Components {
+		previousUIDs[component.Kind+"/"+component.Name] = component.UID
+	}
+
+	res := []rolloutsmanagerv1alpha1.RolloutManagerComponentStatus{}
+
+	for _, component := range getTrackedComponents(cr) {
+
+		obj := component.obj
+		if err := fetchObject(ctx, r.Client, obj.GetNamespace(), obj.GetName(), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get the %s %s: %w", component.kind, obj.GetName(), err)
+		}
+
+		status := rolloutsmanagerv1alpha1.RolloutManagerComponentStatus{Kind: component.kind, Name: obj.GetName(), UID: obj.GetUID()}
+		res = append(res, status)
+
+		previousUID, exists := previousUIDs[status.Kind+"/"+status.Name]
+		if !exists || previousUID == status.UID || obj.GetAnnotations()[ReplacedUIDAnnotation] == string(previousUID) {
+			continue
+		}
+
+		message := fmt.Sprintf("%s %s was recreated outside of the operator: its UID changed from %s to %s", status.Kind, status.Name, previousUID, status.UID)
+		log.Info(message)
+		if r.Recorder != nil {
+			r.Recorder.Event(&cr, corev1.EventTypeWarning, EventReasonResourceRecreatedExternally, message)
+		}
+	}
+
+	return res, nil
+}
+
+// setReplacedUIDAnnotation records on a resource which is recreated by the operator the UID of the resource that it replaces (see ReplacedUIDAnnotation).
+func setReplacedUIDAnnotation(obj *metav1.ObjectMeta, replacedUID types.UID) {
+	if obj.Annotations == nil {
+		obj.Annotations = map[string]string{}
+	}
+	obj.Annotations[ReplacedUIDAnnotation] = string(replacedUID)
+}
@@ -0,0 +1,148 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Resource UID tracking tests", func() {
+
+	var (
+		ctx      context.Context
+		cr       rolloutsmanagerv1alpha1.RolloutManager
+		r        *RolloutManagerReconciler
+		req      reconcile.Request
+		recorder *namespacedEventRecorder
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		// The fake client does not assign UIDs, unlike the API server
+		created := 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetUID() == "" {
+					created++
+					obj.SetUID(types.UID(fmt.Sprintf("uid-%d", created)))
+				}
+				return client.Create(ctx, obj, opts...)
+			},
+		})
+
+		recorder = &namespacedEventRecorder{}
+		r.Recorder = recorder
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+	})
+
+	getComponentUID := func(kind string, name string) types.UID {
+		Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+		for _, component := range cr.Status.Components {
+			if component.Kind == kind && component.Name == name {
+				return component.UID
+			}
+		}
+		return ""
+	}
+
+	recreatedEvent := func() string {
+		return cr.Namespace + "/*v1alpha1.RolloutManager " + EventReasonResourceRecreatedExternally
+	}
+
+	It("should record the UIDs of the resources of the RolloutManager in its status", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+		var kinds []string
+		for _, component := range cr.Status.Components {
+			Expect(component.UID).ToNot(BeEmpty(), component.Kind)
+			kinds = append(kinds, component.Kind+"/"+component.Name)
+		}
+		Expect(kinds).To(ConsistOf(
+			"ServiceAccount/"+DefaultArgoRolloutsResourceName,
+			"ClusterRole/"+DefaultArgoRolloutsResourceName,
+			"ClusterRoleBinding/"+DefaultArgoRolloutsResourceName,
+			"Deployment/"+DefaultArgoRolloutsResourceName,
+			"Service/"+DefaultArgoRolloutsMetricsServiceName,
+			"ConfigMap/"+DefaultRolloutsConfigMapName,
+			"Secret/"+DefaultRolloutsNotificationSecretName))
+
+		By("reconciling again, and verifying that no recreation is reported")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.events).ToNot(ContainElement(recreatedEvent()))
+	})
+
+	It("should report a resource which was deleted outside of the operator", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		previousUID := getComponentUID("Deployment", DefaultArgoRolloutsResourceName)
+
+		By("deleting the Deployment, as another actor would")
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(r.Client.Delete(ctx, deployment)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getComponentUID("Deployment", DefaultArgoRolloutsResourceName)).ToNot(Equal(previousUID))
+		Expect(recorder.events).To(ContainElement(recreatedEvent()))
+	})
+
+	It("should not report a resource which was replaced by the operator", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		previousUID := getComponentUID("Secret", DefaultRolloutsNotificationSecretName)
+
+		By("changing the type of the Secrets, which requires the operator to replace the notification Secret")
+		cr.Spec.Secrets = &rolloutsmanagerv1alpha1.RolloutManagerSecretsSpec{Type: "argoproj.io/notifications"}
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		secret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsNotificationSecretName, secret)).To(Succeed())
+		Expect(secret.Type).To(Equal(corev1.SecretType("argoproj.io/notifications")))
+		Expect(secret.Annotations).To(HaveKeyWithValue(ReplacedUIDAnnotation, string(previousUID)))
+
+		Expect(getComponentUID("Secret", DefaultRolloutsNotificationSecretName)).To(Equal(secret.UID))
+		Expect(recorder.events).ToNot(ContainElement(recreatedEvent()))
+	})
+
+	It("should remove the resources which no longer exist from the status, without reporting them", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+		cr.Spec.SkipNotificationSecretDeployment = true
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getComponentUID("Secret", DefaultRolloutsNotificationSecretName)).To(BeEmpty())
+		Expect(getComponentUID("Deployment", DefaultArgoRolloutsResourceName)).ToNot(BeEmpty())
+		Expect(recorder.events).ToNot(ContainElement(recreatedEvent()))
+	})
+})
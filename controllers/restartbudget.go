@@ -0,0 +1,180 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LastControllerRestartAnnotation is set on the Rollouts controller Deployment when a RestartBudget is configured, and contains the time at which the operator last updated its pod template (restarting the Rollouts controller).
+	LastControllerRestartAnnotation = "argo-rollouts.argoproj.io/last-controller-restart"
+
+	// PendingControllerRestartAnnotation is set on the Rollouts controller Deployment while an update of its pod template is deferred by the RestartBudget, and contains the time at which the update was first deferred.
+	PendingControllerRestartAnnotation = "argo-rollouts.argoproj.io/pending-controller-restart"
+
+	// EventReasonControllerRestartDeferred is the reason of Events recorded when an update of the Rollouts controller Deployment is deferred by the RestartBudget.
+	EventReasonControllerRestartDeferred = "ControllerRestartDeferred"
+)
+
+// restartBudget is the parsed form of the RestartBudget of a RolloutManager.
+type restartBudget struct {
+	coalesceWindow time.Duration
+	minInterval    time.Duration
+}
+
+// getRestartBudget returns the RestartBudget of the RolloutManager, or nil if it is not configured.
+func getRestartBudget(cr rolloutsmanagerv1alpha1.RolloutManager) (*restartBudget, error) {
+
+	if cr.Spec.RestartBudget == nil {
+		return nil, nil
+	}
+
+	parse := func(field string, value string) (time.Duration, error) {
+		if value == "" {
+			return 0, nil
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid restart budget %s '%s': %w", field, value, err)
+		}
+		if duration < 0 {
+			return 0, fmt.Errorf("invalid restart budget %s '%s': must not be negative", field, value)
+		}
+		return duration, nil
+	}
+
+	coalesceWindow, err := parse("coalesceWindow", cr.Spec.RestartBudget.CoalesceWindow)
+	if err != nil {
+		return nil, err
+	}
+	minInterval, err := parse("minInterval", cr.Spec.RestartBudget.MinInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	if coalesceWindow == 0 && minInterval == 0 {
+		return nil, nil
+	}
+
+	return &restartBudget{coalesceWindow: coalesceWindow, minInterval: minInterval}, nil
+}
+
+// restartAllowedAt returns the time at which an update of the pod template of the live Deployment is allowed by the budget: once the update has been pending for the coalesce window, and the minimum interval since the last restart has elapsed.
+// pendingSince is the time at which the update was first deferred, or now if it was not.
+func (b restartBudget) restartAllowedAt(liveDeployment appsv1.Deployment, now time.Time) (allowedAt time.Time, pendingSince time.Time) {
+
+	pendingSince = now
+	if t, err := time.Parse(time.RFC3339, liveDeployment.Annotations[PendingControllerRestartAnnotation]); err == nil && !t.After(now) {
+		pendingSince = t
+	}
+
+	allowedAt = pendingSince.Add(b.coalesceWindow)
+
+	if lastRestart, err := time.Parse(time.RFC3339, liveDeployment.Annotations[LastControllerRestartAnnotation]); err == nil {
+		if t := lastRestart.Add(b.minInterval); t.After(allowedAt) {
+			allowedAt = t
+		}
+	}
+
+	return allowedAt, pendingSince
+}
+
+// recordControllerRestartDeferred records an Event on the RolloutManager when an update of the Rollouts controller Deployment is deferred by its RestartBudget.
+func (r *RolloutManagerReconciler) recordControllerRestartDeferred(cr *rolloutsmanagerv1alpha1.RolloutManager, allowedAt time.Time) {
+	message := fmt.Sprintf("Update of Deployment %s, which restarts the Rollouts controller, is deferred by the restart budget until %s", DefaultArgoRolloutsResourceName, allowedAt.UTC().Format(time.RFC3339))
+	log.Info(message, "namespace", cr.Namespace)
+
+	if r.Recorder != nil {
+		r.Recorder.Event(cr, corev1.EventTypeNormal, EventReasonControllerRestartDeferred, message)
+	}
+}
+
+// minRequeueAfter returns the sooner of two requested requeue intervals, ignoring zero (not requested) intervals.
+func minRequeueAfter(a time.Duration, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// deferControllerRestart returns true if an update of the live Rollouts controller Deployment which restarts the Rollouts controller must be deferred by the RestartBudget of the RolloutManager.
+// When an update is first deferred, the PendingControllerRestartAnnotation is set on the live Deployment (without applying the update), and an Event is recorded. The update is applied, along with any changes made in the meantime, once the RolloutManager is reconciled after the budget allows it (see controllerRestartRequeueAfter).
+func (r *RolloutManagerReconciler) deferControllerRestart(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, liveDeployment *appsv1.Deployment) (bool, error) {
+
+	if r.ignoreRestartBudget {
+		return false, nil
+	}
+
+	budget, err := getRestartBudget(cr)
+	if err != nil || budget == nil {
+		return false, err
+	}
+
+	now := time.Now()
+	allowedAt, pendingSince := budget.restartAllowedAt(*liveDeployment, now)
+	if !now.Before(allowedAt) {
+		return false, nil
+	}
+
+	if _, exists := liveDeployment.Annotations[PendingControllerRestartAnnotation]; !exists {
+		liveDeployment.Annotations = combineStringMaps(liveDeployment.Annotations, map[string]string{
+			PendingControllerRestartAnnotation: pendingSince.UTC().Format(time.RFC3339),
+		})
+		if err := r.Client.Update(ctx, liveDeployment); err != nil {
+			return false, fmt.Errorf("failed to update the Deployment %s: %w", liveDeployment.Name, err)
+		}
+		r.recordControllerRestartDeferred(&cr, allowedAt)
+	}
+
+	return true, nil
+}
+
+// setControllerRestartAnnotations records on the Rollouts controller Deployment that the operator is restarting the Rollouts controller, if the RolloutManager has a RestartBudget.
+func setControllerRestartAnnotations(cr rolloutsmanagerv1alpha1.RolloutManager, obj *metav1.ObjectMeta) {
+
+	delete(obj.Annotations, PendingControllerRestartAnnotation)
+
+	if budget, err := getRestartBudget(cr); err != nil || budget == nil {
+		delete(obj.Annotations, LastControllerRestartAnnotation)
+		return
+	}
+
+	obj.Annotations = combineStringMaps(obj.Annotations, map[string]string{
+		LastControllerRestartAnnotation: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// controllerRestartRequeueAfter returns the duration after which the RolloutManager should be reconciled again to apply an update of the Rollouts controller Deployment that is deferred by its RestartBudget, or zero if there is none.
+func (r *RolloutManagerReconciler) controllerRestartRequeueAfter(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (time.Duration, error) {
+
+	budget, err := getRestartBudget(cr)
+	if err != nil || budget == nil {
+		return 0, err
+	}
+
+	var deployment appsv1.Deployment
+	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get the Deployment %s: %w", DefaultArgoRolloutsResourceName, err)
+	}
+
+	if _, exists := deployment.Annotations[PendingControllerRestartAnnotation]; !exists {
+		return 0, nil
+	}
+
+	now := time.Now()
+	allowedAt, _ := budget.restartAllowedAt(deployment, now)
+	if requeueAfter := allowedAt.Sub(now); requeueAfter > time.Second {
+		return requeueAfter, nil
+	}
+	return time.Second, nil
+}
@@ -0,0 +1,225 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Restart budget tests", func() {
+
+	DescribeTable("getRestartBudget should parse the RestartBudget", func(spec *rolloutsmanagerv1alpha1.RolloutManagerRestartBudgetSpec, expected *restartBudget, expectedError string) {
+		cr := *makeTestRolloutManager()
+		cr.Spec.RestartBudget = spec
+
+		budget, err := getRestartBudget(cr)
+		if expectedError != "" {
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+			return
+		}
+		Expect(err).ToNot(HaveOccurred())
+		Expect(budget).To(Equal(expected))
+	},
+		Entry("not configured", nil, nil, ""),
+		Entry("empty", &rolloutsmanagerv1alpha1.RolloutManagerRestartBudgetSpec{}, nil, ""),
+		Entry("both durations", &rolloutsmanagerv1alpha1.RolloutManagerRestartBudgetSpec{CoalesceWindow: "30s", MinInterval: "10m"}, &restartBudget{coalesceWindow: 30 * time.Second, minInterval: 10 * time.Minute}, ""),
+		Entry("invalid duration", &rolloutsmanagerv1alpha1.RolloutManagerRestartBudgetSpec{MinInterval: "ten minutes"}, nil, "invalid restart budget minInterval 'ten minutes'"),
+		Entry("negative duration", &rolloutsmanagerv1alpha1.RolloutManagerRestartBudgetSpec{CoalesceWindow: "-1m"}, nil, "must not be negative"),
+	)
+
+	It("restartAllowedAt should wait for the coalesce window and the minimum interval since the last restart", func() {
+		budget := restartBudget{coalesceWindow: time.Minute, minInterval: 10 * time.Minute}
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		deployment := appsv1.Deployment{}
+		allowedAt, pendingSince := budget.restartAllowedAt(deployment, now)
+		Expect(pendingSince).To(Equal(now))
+		Expect(allowedAt).To(Equal(now.Add(time.Minute)))
+
+		By("counting the coalesce window from the first deferral")
+		deployment.Annotations = map[string]string{PendingControllerRestartAnnotation: "2024-01-01T11:59:30Z"}
+		allowedAt, _ = budget.restartAllowedAt(deployment, now)
+		Expect(allowedAt).To(Equal(now.Add(30 * time.Second)))
+
+		By("waiting for the minimum interval since the last restart")
+		deployment.Annotations[LastControllerRestartAnnotation] = "2024-01-01T11:55:00Z"
+		allowedAt, _ = budget.restartAllowedAt(deployment, now)
+		Expect(allowedAt).To(Equal(now.Add(5 * time.Minute)))
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx      context.Context
+			cr       rolloutsmanagerv1alpha1.RolloutManager
+			r        *RolloutManagerReconciler
+			req      reconcile.Request
+			recorder *namespacedEventRecorder
+		)
+
+		fetchDeployment := func() *appsv1.Deployment {
+			deployment := &appsv1.Deployment{}
+			ExpectWithOffset(1, fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			return deployment
+		}
+
+		updateRolloutManager := func(modify func(*rolloutsmanagerv1alpha1.RolloutManager)) {
+			ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			modify(&cr)
+			ExpectWithOffset(1, r.Client.Update(ctx, &cr)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			cr = *makeTestRolloutManager()
+			cr.Spec.RestartBudget = &rolloutsmanagerv1alpha1.RolloutManagerRestartBudgetSpec{MinInterval: "10m"}
+
+			r = makeTestReconciler(&cr)
+			recorder = &namespacedEventRecorder{}
+			r.Recorder = recorder
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetchDeployment().Annotations).To(HaveKey(LastControllerRestartAnnotation))
+		})
+
+		It("should defer updates of the pod template within the minimum interval, and apply them together once it has elapsed", func() {
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.Env = append(cr.Spec.Env, corev1.EnvVar{Name: "FIRST", Value: "1"})
+			})
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.controllerRestartRequeueAfter(ctx, cr)).To(BeNumerically("~", 10*time.Minute, 5*time.Second))
+
+			deployment := fetchDeployment()
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+			Expect(deployment.Annotations).To(HaveKey(PendingControllerRestartAnnotation))
+			Expect(recorder.events).To(ContainElement(cr.Namespace + "/*v1alpha1.RolloutManager " + EventReasonControllerRestartDeferred))
+
+			By("coalescing a second change, without recording another Event")
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.Env = append(cr.Spec.Env, corev1.EnvVar{Name: "SECOND", Value: "2"})
+			})
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetchDeployment().Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+			Expect(recorder.events).To(HaveLen(1))
+
+			By("simulating the elapse of the minimum interval")
+			deployment = fetchDeployment()
+			deployment.Annotations[LastControllerRestartAnnotation] = time.Now().Add(-11 * time.Minute).UTC().Format(time.RFC3339)
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment = fetchDeployment()
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElements(corev1.EnvVar{Name: "FIRST", Value: "1"}, corev1.EnvVar{Name: "SECOND", Value: "2"}))
+			Expect(deployment.Annotations).ToNot(HaveKey(PendingControllerRestartAnnotation))
+			lastRestart, err := time.Parse(time.RFC3339, deployment.Annotations[LastControllerRestartAnnotation])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastRestart).To(BeTemporally("~", time.Now(), 5*time.Second))
+		})
+
+		It("should not defer updates which do not restart the Rollouts controller", func() {
+			By("removing a label of the Deployment, which is not a label of its pod template")
+			deployment := fetchDeployment()
+			Expect(deployment.Labels).To(HaveKey("app.kubernetes.io/name"))
+			delete(deployment.Labels, "app.kubernetes.io/name")
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment = fetchDeployment()
+			Expect(deployment.Labels).To(HaveKey("app.kubernetes.io/name"))
+			Expect(deployment.Annotations).ToNot(HaveKey(PendingControllerRestartAnnotation))
+			Expect(recorder.events).To(BeEmpty())
+		})
+
+		It("should cancel a deferred update which is no longer needed", func() {
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.Env = append(cr.Spec.Env, corev1.EnvVar{Name: "FIRST", Value: "1"})
+			})
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetchDeployment().Annotations).To(HaveKey(PendingControllerRestartAnnotation))
+
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.Env = nil
+			})
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fetchDeployment().Annotations).ToNot(HaveKey(PendingControllerRestartAnnotation))
+			Expect(r.controllerRestartRequeueAfter(ctx, cr)).To(BeZero())
+		})
+
+		It("should apply deferred updates when the RestartBudget is removed", func() {
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.Env = append(cr.Spec.Env, corev1.EnvVar{Name: "FIRST", Value: "1"})
+			})
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.RestartBudget = nil
+			})
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := fetchDeployment()
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "FIRST", Value: "1"}))
+			Expect(deployment.Annotations).ToNot(HaveKey(PendingControllerRestartAnnotation))
+			Expect(deployment.Annotations).ToNot(HaveKey(LastControllerRestartAnnotation))
+		})
+
+		It("should report deferred updates as drift", func() {
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.Env = append(cr.Spec.Env, corev1.EnvVar{Name: "FIRST", Value: "1"})
+			})
+
+			drifts, err := r.DetectDrift(ctx, client.ObjectKeyFromObject(&cr))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifts).To(ContainElement(And(HaveField("Kind", "Deployment"), HaveField("Action", DriftActionUpdate))))
+			Expect(fetchDeployment().Annotations).ToNot(HaveKey(PendingControllerRestartAnnotation))
+		})
+
+		It("should not record the last restart when no RestartBudget is configured", func() {
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.RestartBudget = &rolloutsmanagerv1alpha1.RolloutManagerRestartBudgetSpec{MinInterval: "0s"}
+			})
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			// The Deployment is unchanged, so the annotation of the previous restart is kept until the next restart
+			Expect(fetchDeployment().Annotations).To(HaveKey(LastControllerRestartAnnotation))
+
+			updateRolloutManager(func(cr *rolloutsmanagerv1alpha1.RolloutManager) {
+				cr.Spec.Env = append(cr.Spec.Env, corev1.EnvVar{Name: "FIRST", Value: "1"})
+			})
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := fetchDeployment()
+			Expect(deployment.Annotations).ToNot(HaveKey(LastControllerRestartAnnotation))
+			Expect(metav1.HasAnnotation(deployment.ObjectMeta, PendingControllerRestartAnnotation)).To(BeFalse())
+		})
+	})
+})
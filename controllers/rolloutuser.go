@@ -0,0 +1,214 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// DefaultRolloutUserRoleName is the name of the Role, created in each watched namespace, that can be bound to the users of Rollouts
+	DefaultRolloutUserRoleName = "argo-rollouts-rollout-user"
+
+	// RolloutUserRoleOwnerLabel is set on each rollout-user Role, and contains the namespace of the RolloutManager that created it.
+	// Roles in other namespaces can't be owned by the RolloutManager, so this label is used to clean them up.
+	RolloutUserRoleOwnerLabel = "argo-rollouts.argoproj.io/rollout-user-role-owner"
+)
+
+// GetRolloutUserPolicyRules returns the PolicyRules of the rollout-user Role: these allow viewing and promoting Rollouts.
+func GetRolloutUserPolicyRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{
+				"argoproj.io",
+			},
+			Resources: []string{
+				"rollouts",
+			},
+			Verbs: []string{
+				"get",
+				"list",
+				"watch",
+				"patch",
+			},
+		},
+		{
+			APIGroups: []string{
+				"argoproj.io",
+			},
+			Resources: []string{
+				"rollouts/status",
+			},
+			Verbs: []string{
+				"patch",
+			},
+		},
+	}
+}
+
+func isRolloutUserRoleEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.RolloutUserRole != nil && cr.Spec.RolloutUserRole.Enabled
+}
+
+// getWatchedNamespaces returns the namespaces that are watched by the Rollouts controller: the namespace of the RolloutManager if it is namespace-scoped, otherwise all the namespaces of the cluster (except those being deleted).
+func (r *RolloutManagerReconciler) getWatchedNamespaces(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) ([]string, error) {
+
+	if cr.Spec.NamespaceScoped {
+		return []string{cr.Namespace}, nil
+	}
+
+	namespaceList := &metav1.PartialObjectMetadataList{}
+	namespaceList.SetGroupVersionKind(namespaceGVK.GroupVersion().WithKind("NamespaceList"))
+	if err := r.Client.List(ctx, namespaceList); err != nil {
+		return nil, fmt.Errorf("failed to list Namespaces: %w", err)
+	}
+
+	var res []string
+	for _, namespace := range namespaceList.Items {
+		if namespace.DeletionTimestamp != nil {
+			continue
+		}
+		res = append(res, namespace.Name)
+	}
+	return res, nil
+}
+
+// reconcileRolloutUserRoles creates the rollout-user Role in each namespace watched by the Rollouts controller if it is enabled, and deletes the Roles otherwise.
+func (r *RolloutManagerReconciler) reconcileRolloutUserRoles(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	if !isRolloutUserRoleEnabled(cr) {
+		return r.removeRolloutUserRoles(ctx, cr.Namespace)
+	}
+
+	namespaces, err := r.getWatchedNamespaces(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaces {
+		if err := r.reconcileRolloutUserRole(ctx, cr, namespace); err != nil {
+			// A namespace may be deleted between listing and reconciling it: its Roles are deleted along with it, so it is skipped rather than failing (and retrying) the reconciliation.
+			if isNamespaceDeletedError(err) {
+				log.Info(fmt.Sprintf("Skipping Role %s in namespace %s, as the namespace is being deleted", DefaultRolloutUserRoleName, namespace))
+				continue
+			}
+			return err
+		}
+	}
+
+	return r.removeStaleRolloutUserRoles(ctx, cr, namespaces)
+}
+
+// isNamespaceDeletedError returns true if the error was returned by the API server because the namespace of the request is being deleted, or no longer exists.
+func isNamespaceDeletedError(err error) bool {
+	return apierrors.HasStatusCause(err, corev1.NamespaceTerminatingCause) || apierrors.IsNotFound(err)
+}
+
+// removeStaleRolloutUserRoles deletes the rollout-user Roles of the RolloutManager in namespaces that are no longer watched by the Rollouts controller: namespaces being deleted, or all other namespaces if the RolloutManager became namespace-scoped.
+func (r *RolloutManagerReconciler) removeStaleRolloutUserRoles(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, watchedNamespaces []string) error {
+
+	watched := map[string]bool{}
+	for _, namespace := range watchedNamespaces {
+		watched[namespace] = true
+	}
+
+	var roleList rbacv1.RoleList
+	if err := r.Client.List(ctx, &roleList, append(r.namespaceListOptions(cr.Namespace), client.MatchingLabels{RolloutUserRoleOwnerLabel: cr.Namespace})...); err != nil {
+		return fmt.Errorf("failed to list rollout-user Roles: %w", err)
+	}
+
+	for idx := range roleList.Items {
+		role := roleList.Items[idx]
+
+		if role.Name != DefaultRolloutUserRoleName || watched[role.Namespace] || role.DeletionTimestamp != nil {
+			continue
+		}
+
+		log.Info(fmt.Sprintf("Deleting Role %s in namespace %s, as the namespace is no longer watched", role.Name, role.Namespace))
+		if err := r.Client.Delete(ctx, &role); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to delete the Role %s in namespace %s: %w", role.Name, role.Namespace, err)
+		}
+		r.recordTargetNamespaceEvent(&cr, &role, EventReasonResourceDeleted, fmt.Sprintf("Deleted Role %s in namespace %s, for RolloutManager %s in namespace %s", role.Name, role.Namespace, cr.Name, cr.Namespace))
+	}
+
+	return nil
+}
+
+// reconcileRolloutUserRole reconciles the rollout-user Role in a single namespace.
+func (r *RolloutManagerReconciler) reconcileRolloutUserRole(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, namespace string) error {
+
+	expectedPolicyRules := GetRolloutUserPolicyRules()
+
+	expectedRole := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultRolloutUserRoleName,
+			Namespace: namespace,
+		},
+	}
+	setRolloutsLabelsAndAnnotationsToObject(&expectedRole.ObjectMeta, cr)
+	expectedRole.Labels[RolloutUserRoleOwnerLabel] = cr.Namespace
+	expectedRole.Rules = expectedPolicyRules
+	if err := r.prepareResource(ctx, cr, expectedRole); err != nil {
+		return err
+	}
+
+	// Only the Role in the namespace of the RolloutManager can be owned by the RolloutManager
+	liveRole := &rbacv1.Role{}
+	result, err := r.applyResource(ctx, cr, expectedRole, liveRole, namespace == cr.Namespace, func() bool {
+		return updatePolicyRules(&liveRole.Rules, expectedRole.Rules, r.describeResource(cr, liveRole))
+	})
+	if err != nil {
+		return err
+	}
+
+	switch result {
+	case controllerutil.OperationResultCreated:
+		r.recordTargetNamespaceEvent(&cr, liveRole, EventReasonResourceCreated, fmt.Sprintf("Created Role %s in namespace %s, for RolloutManager %s in namespace %s", liveRole.Name, namespace, cr.Name, cr.Namespace))
+	case controllerutil.OperationResultUpdated:
+		r.recordTargetNamespaceEvent(&cr, liveRole, EventReasonResourceUpdated, fmt.Sprintf("Updated Role %s in namespace %s, for RolloutManager %s in namespace %s", liveRole.Name, namespace, cr.Name, cr.Namespace))
+	}
+
+	return nil
+}
+
+// removeRolloutUserRoles deletes the rollout-user Roles that were created for the RolloutManager in the given namespace, in all namespaces.
+func (r *RolloutManagerReconciler) removeRolloutUserRoles(ctx context.Context, rolloutManagerNamespace string) error {
+
+	var roleList rbacv1.RoleList
+	if err := r.Client.List(ctx, &roleList, append(r.namespaceListOptions(rolloutManagerNamespace), client.MatchingLabels{RolloutUserRoleOwnerLabel: rolloutManagerNamespace})...); err != nil {
+		return fmt.Errorf("failed to list rollout-user Roles: %w", err)
+	}
+
+	for idx := range roleList.Items {
+		role := roleList.Items[idx]
+
+		if role.Name != DefaultRolloutUserRoleName {
+			continue
+		}
+
+		log.Info(fmt.Sprintf("Deleting Role %s in namespace %s", role.Name, role.Namespace))
+		if err := r.Client.Delete(ctx, &role); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to delete the Role %s in namespace %s: %w", role.Name, role.Namespace, err)
+		}
+
+		// The RolloutManager may no longer exist, so the Event is only recorded on the Role, in namespaces other than that of the RolloutManager
+		if role.Namespace != rolloutManagerNamespace {
+			r.recordTargetNamespaceEvent(nil, &role, EventReasonResourceDeleted, fmt.Sprintf("Deleted Role %s in namespace %s, for RolloutManager in namespace %s", role.Name, role.Namespace, rolloutManagerNamespace))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package rollouts
+
+import (
+	"context"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("rollout-user Role tests", func() {
+	var ctx context.Context
+	var a v1alpha1.RolloutManager
+	var r *RolloutManagerReconciler
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		a = *makeTestRolloutManager()
+		a.Spec.RolloutUserRole = &v1alpha1.RolloutManagerRolloutUserRoleSpec{
+			Enabled: true,
+		}
+
+		r = makeTestReconciler(&a)
+		Expect(createNamespace(r, a.Namespace)).To(Succeed())
+		Expect(createNamespace(r, "team-a")).To(Succeed())
+		Expect(createNamespace(r, "team-b")).To(Succeed())
+	})
+
+	expectRoleExists := func(namespace string) *rbacv1.Role {
+		role := &rbacv1.Role{}
+		ExpectWithOffset(1, fetchObject(ctx, r.Client, namespace, DefaultRolloutUserRoleName, role)).To(Succeed())
+		ExpectWithOffset(1, role.Rules).To(Equal(GetRolloutUserPolicyRules()))
+		ExpectWithOffset(1, role.Labels).To(HaveKeyWithValue(RolloutUserRoleOwnerLabel, a.Namespace))
+		return role
+	}
+
+	expectRoleDoesNotExist := func(namespace string) {
+		err := fetchObject(ctx, r.Client, namespace, DefaultRolloutUserRoleName, &rbacv1.Role{})
+		ExpectWithOffset(1, apierrors.IsNotFound(err)).To(BeTrue())
+	}
+
+	It("should only create the Role in the namespace of a namespace-scoped RolloutManager", func() {
+		a.Spec.NamespaceScoped = true
+
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+
+		role := expectRoleExists(a.Namespace)
+		Expect(role.OwnerReferences).To(HaveLen(1))
+
+		expectRoleDoesNotExist("team-a")
+		expectRoleDoesNotExist("team-b")
+	})
+
+	It("should create the Role in all namespaces for a cluster-scoped RolloutManager, and delete them when disabled", func() {
+
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+
+		for _, namespace := range []string{a.Namespace, "team-a", "team-b"} {
+			expectRoleExists(namespace)
+		}
+
+		By("verifying that Roles outside the namespace of the RolloutManager are not owned by the RolloutManager")
+		Expect(expectRoleExists("team-a").OwnerReferences).To(BeEmpty())
+
+		By("disabling the Role")
+		a.Spec.RolloutUserRole.Enabled = false
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+
+		for _, namespace := range []string{a.Namespace, "team-a", "team-b"} {
+			expectRoleDoesNotExist(namespace)
+		}
+	})
+
+	It("should revert modifications of the Role rules", func() {
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+
+		role := expectRoleExists("team-a")
+		role.Rules = []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}
+		Expect(r.Client.Update(ctx, role)).To(Succeed())
+
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+		expectRoleExists("team-a")
+	})
+
+	It("should delete the Roles in all namespaces when the RolloutManager no longer exists", func() {
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+
+		Expect(r.removeRolloutUserRoles(ctx, a.Namespace)).To(Succeed())
+
+		for _, namespace := range []string{a.Namespace, "team-a", "team-b"} {
+			expectRoleDoesNotExist(namespace)
+		}
+	})
+
+	It("should delete the Role in a namespace that is being deleted", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c", Finalizers: []string{"example.com/finalizer"}}}
+		Expect(r.Client.Create(ctx, namespace)).To(Succeed())
+
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+		expectRoleExists("team-c")
+
+		By("starting the deletion of the namespace, which is blocked by its finalizer")
+		Expect(r.Client.Delete(ctx, namespace)).To(Succeed())
+
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+		expectRoleDoesNotExist("team-c")
+		expectRoleExists("team-a")
+	})
+
+	It("should delete the Roles in other namespaces when the RolloutManager becomes namespace-scoped", func() {
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+
+		a.Spec.NamespaceScoped = true
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+
+		expectRoleExists(a.Namespace)
+		expectRoleDoesNotExist("team-a")
+		expectRoleDoesNotExist("team-b")
+	})
+
+	It("should skip a namespace that is deleted while the Roles are reconciled, rather than fail", func() {
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetNamespace() == "team-a" {
+					err := apierrors.NewForbidden(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "roles"}, obj.GetName(), nil)
+					err.ErrStatus.Details.Causes = []metav1.StatusCause{{Type: corev1.NamespaceTerminatingCause}}
+					return err
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+
+		Expect(r.reconcileRolloutUserRoles(ctx, a)).To(Succeed())
+
+		expectRoleDoesNotExist("team-a")
+		expectRoleExists("team-b")
+	})
+})
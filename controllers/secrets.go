@@ -0,0 +1,123 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// setManagedSecretOptions sets the type and immutability of a Secret created by the operator, from .spec.secrets of the RolloutManager.
+func setManagedSecretOptions(secret *corev1.Secret, cr rolloutsmanagerv1alpha1.RolloutManager) {
+	secret.Type = corev1.SecretTypeOpaque
+	secret.Immutable = nil
+
+	if cr.Spec.Secrets == nil {
+		return
+	}
+	if cr.Spec.Secrets.Type != "" {
+		secret.Type = cr.Spec.Secrets.Type
+	}
+	if cr.Spec.Secrets.Immutable {
+		immutable := true
+		secret.Immutable = &immutable
+	}
+}
+
+// isSecretImmutable returns true if the data of the Secret cannot be changed.
+func isSecretImmutable(secret *corev1.Secret) bool {
+	return secret.Immutable != nil && *secret.Immutable
+}
+
+// secretOptionsMatch returns true if the live Secret has the type and immutability of the expected Secret. Neither can be changed in place (a Secret can be made immutable, but not reverted), so the Secret is replaced if they differ.
+func secretOptionsMatch(liveSecret *corev1.Secret, expectedSecret *corev1.Secret) bool {
+	return liveSecret.Type == expectedSecret.Type && isSecretImmutable(liveSecret) == isSecretImmutable(expectedSecret)
+}
+
+// replaceSecret deletes the live Secret and creates the replacement in its place, for changes which cannot be applied in place: the type or immutability of a Secret, and its data once it is immutable.
+func (r *RolloutManagerReconciler) replaceSecret(ctx context.Context, liveSecret *corev1.Secret, replacement *corev1.Secret) error {
+
+	// Only the metadata that is set by users and by the operator is carried over
+	replacement.ObjectMeta = metav1.ObjectMeta{
+		Name:            liveSecret.Name,
+		Namespace:       liveSecret.Namespace,
+		Labels:          replacement.Labels,
+		Annotations:     replacement.Annotations,
+		OwnerReferences: replacement.OwnerReferences,
+	}
+
+	log.Info(fmt.Sprintf("Replacing Secret %s, as it cannot be updated in place", liveSecret.Name))
+
+	// The preconditions ensure that a Secret which was modified in the meantime is not deleted: it is replaced on the next reconciliation
+	if err := r.Client.Delete(ctx, liveSecret, client.Preconditions{UID: &liveSecret.UID, ResourceVersion: &liveSecret.ResourceVersion}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the Secret %s: %w", liveSecret.Name, err)
+	}
+	if err := r.Client.Create(ctx, replacement); err != nil {
+		return fmt.Errorf("failed to create the Secret %s: %w", replacement.Name, err)
+	}
+	return nil
+}
+
+// replaceNotificationSecretIfNeeded replaces the notification Secret, if it is controlled by the RolloutManager and its type or immutability does not match .spec.secrets. Its data is provided by users, so it is carried over to the new Secret.
+func (r *RolloutManagerReconciler) replaceNotificationSecretIfNeeded(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, expectedSecret *corev1.Secret) error {
+
+	liveSecret := &corev1.Secret{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, expectedSecret.Name, liveSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the Secret %s: %w", expectedSecret.Name, err)
+	}
+
+	if secretOptionsMatch(liveSecret, expectedSecret) {
+		return nil
+	}
+
+	// A Secret which was not created by the operator is left as is
+	if controller := metav1.GetControllerOf(liveSecret); controller == nil || controller.UID != cr.UID {
+		return nil
+	}
+
+	replacement := expectedSecret.DeepCopy()
+	replacement.Labels = combineStringMaps(liveSecret.Labels, expectedSecret.Labels)
+	replacement.Annotations = combineStringMaps(liveSecret.Annotations, expectedSecret.Annotations)
+	replacement.Data = liveSecret.Data
+	setReplacedUIDAnnotation(&replacement.ObjectMeta, liveSecret.UID)
+	if err := r.setControllerReference(&cr, replacement); err != nil {
+		return err
+	}
+	// The replacement is in the expected state, so that it is not updated again by applyResource
+	setSpecHashAnnotationOfObject(replacement, computeSpecHash(expectedSecret))
+
+	return r.replaceSecret(ctx, liveSecret, replacement)
+}
+
+// isNotificationSecretNeeded returns true if the notification Secret should be created for the RolloutManager, when only created on demand (see NotificationSecretOnDemand): that is, if notifications are configured, via the notification ConfigMap in the namespace of the RolloutManager.
+// For existing installations, a notification Secret which already contains data is still needed, even without the ConfigMap, so that the credentials stored in it by users are not deleted: only the empty Secrets created by previous versions of the operator are removed.
+func (r *RolloutManagerReconciler) isNotificationSecretNeeded(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (bool, error) {
+
+	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsNotificationConfigMapName, &corev1.ConfigMap{}); err == nil {
+		return true, nil
+	} else if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get the ConfigMap %s: %w", DefaultRolloutsNotificationConfigMapName, err)
+	}
+
+	liveSecret := &corev1.Secret{}
+	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsNotificationSecretName, liveSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get the Secret %s: %w", DefaultRolloutsNotificationSecretName, err)
+	}
+
+	return len(liveSecret.Data) > 0 || len(liveSecret.StringData) > 0, nil
+}
+
+// isNotificationConfigMap returns true if the object is the notification ConfigMap of the Rollouts controller, whose creation or deletion changes whether the notification Secret is needed (see isNotificationSecretNeeded).
+func isNotificationConfigMap(obj client.Object) bool {
+	return obj.GetName() == DefaultRolloutsNotificationConfigMapName
+}
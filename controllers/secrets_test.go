@@ -0,0 +1,224 @@
+package rollouts
+
+import (
+	"context"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Secret type and immutability tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+	})
+
+	getNotificationSecret := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsNotificationSecretName, secret)).To(Succeed())
+		return secret
+	}
+
+	It("should create a mutable Opaque notification Secret by default", func() {
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+
+		secret := getNotificationSecret()
+		Expect(secret.Type).To(Equal(corev1.SecretTypeOpaque))
+		Expect(secret.Immutable).To(BeNil())
+	})
+
+	It("should replace the notification Secret when its type or immutability changes, keeping its data", func() {
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+
+		By("adding data and an annotation to the Secret, as users would")
+		secret := getNotificationSecret()
+		secret.Data = map[string][]byte{"slack-token": []byte("my-token")}
+		secret.Annotations["my-annotation"] = "my-value"
+		Expect(r.Client.Update(ctx, secret)).To(Succeed())
+
+		cr.Spec.Secrets = &rolloutsmanagerv1alpha1.RolloutManagerSecretsSpec{Type: "argoproj.io/notifications", Immutable: true}
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+
+		replaced := getNotificationSecret()
+		Expect(replaced.Type).To(Equal(corev1.SecretType("argoproj.io/notifications")))
+		Expect(isSecretImmutable(replaced)).To(BeTrue())
+		Expect(replaced.Data).To(Equal(secret.Data))
+		Expect(replaced.Annotations).To(HaveKeyWithValue("my-annotation", "my-value"))
+		Expect(metav1.GetControllerOf(replaced)).ToNot(BeNil())
+
+		By("not replacing the Secret again on the next reconciliation")
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(getNotificationSecret().ResourceVersion).To(Equal(replaced.ResourceVersion))
+	})
+
+	It("should not replace a notification Secret which was not created by the operator", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationSecretName, Namespace: cr.Namespace},
+			Type:       corev1.SecretTypeOpaque,
+		}
+		Expect(r.Client.Create(ctx, secret)).To(Succeed())
+
+		cr.Spec.Secrets = &rolloutsmanagerv1alpha1.RolloutManagerSecretsSpec{Immutable: true}
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(getNotificationSecret().Immutable).To(BeNil())
+	})
+
+	It("should replace an immutable backup Secret on each backup", func() {
+		cr.Spec.Backup = &rolloutsmanagerv1alpha1.RolloutManagerBackupSpec{Enabled: true, Schedule: "1h"}
+		cr.Spec.Secrets = &rolloutsmanagerv1alpha1.RolloutManagerSecretsSpec{Immutable: true}
+
+		_, err := r.reconcileBackup(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+
+		backupSecret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+		Expect(isSecretImmutable(backupSecret)).To(BeTrue())
+
+		By("marking the backup as older than the schedule")
+		backupSecret.Annotations[LastBackupTimeAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		backupSecret.Annotations["my-annotation"] = "my-value"
+		Expect(r.Client.Update(ctx, backupSecret)).To(Succeed())
+		previous := backupSecret.DeepCopy()
+
+		deletedSecrets := 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Delete: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				if _, isSecret := obj.(*corev1.Secret); isSecret {
+					deletedSecrets++
+				}
+				return client.Delete(ctx, obj, opts...)
+			},
+		})
+
+		_, err = r.reconcileBackup(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedSecrets).To(Equal(1))
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsBackupSecretName, backupSecret)).To(Succeed())
+		Expect(backupSecret.Annotations[LastBackupTimeAnnotation]).ToNot(Equal(previous.Annotations[LastBackupTimeAnnotation]))
+		Expect(backupSecret.Annotations).To(HaveKeyWithValue("my-annotation", "my-value"))
+		Expect(isSecretImmutable(backupSecret)).To(BeTrue())
+		Expect(backupSecret.OwnerReferences).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Notification Secret on demand tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		r.FeatureGates = FeatureGates{NotificationSecretOnDemand: true}
+	})
+
+	notificationSecretExists := func() bool {
+		err := fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsNotificationSecretName, &corev1.Secret{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).ToNot(HaveOccurred())
+		return true
+	}
+
+	notificationConfigMap := func() *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationConfigMapName, Namespace: cr.Namespace},
+			Data:       map[string]string{"service.slack": "token: $slack-token"},
+		}
+	}
+
+	It("should only create the notification Secret while the notification ConfigMap exists", func() {
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(notificationSecretExists()).To(BeFalse())
+
+		By("creating the notification ConfigMap")
+		configMap := notificationConfigMap()
+		Expect(r.Client.Create(ctx, configMap)).To(Succeed())
+
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(notificationSecretExists()).To(BeTrue())
+
+		By("deleting the notification ConfigMap, and verifying that the empty Secret is deleted")
+		Expect(r.Client.Delete(ctx, configMap)).To(Succeed())
+
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(notificationSecretExists()).To(BeFalse())
+	})
+
+	It("should delete the empty notification Secret created by a previous version of the operator", func() {
+		r.FeatureGates = FeatureGates{}
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(notificationSecretExists()).To(BeTrue())
+
+		r.FeatureGates = FeatureGates{NotificationSecretOnDemand: true}
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(notificationSecretExists()).To(BeFalse())
+	})
+
+	It("should keep a notification Secret which contains data, even without the notification ConfigMap", func() {
+		r.FeatureGates = FeatureGates{}
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsNotificationSecretName, secret)).To(Succeed())
+		secret.Data = map[string][]byte{"slack-token": []byte("my-token")}
+		Expect(r.Client.Update(ctx, secret)).To(Succeed())
+
+		r.FeatureGates = FeatureGates{NotificationSecretOnDemand: true}
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultRolloutsNotificationSecretName, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue("slack-token", []byte("my-token")))
+	})
+
+	It("should not delete an empty notification Secret which is not owned by the RolloutManager", func() {
+		Expect(r.Client.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultRolloutsNotificationSecretName, Namespace: cr.Namespace},
+		})).To(Succeed())
+
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(notificationSecretExists()).To(BeTrue())
+	})
+
+	It("should not create the notification Secret if SkipNotificationSecretDeployment is set, even if notifications are configured", func() {
+		Expect(r.Client.Create(ctx, notificationConfigMap())).To(Succeed())
+		cr.Spec.SkipNotificationSecretDeployment = true
+
+		Expect(r.reconcileRolloutsSecrets(ctx, cr)).To(Succeed())
+		Expect(notificationSecretExists()).To(BeFalse())
+	})
+
+	It("should reconcile the RolloutManagers in the namespace of the notification ConfigMap", func() {
+		configMap := notificationConfigMap()
+		Expect(isNotificationConfigMap(configMap)).To(BeTrue())
+		Expect(r.enqueueRolloutManagersInNamespace(ctx, configMap)).To(ConsistOf(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}))
+
+		configMap.Namespace = "other-namespace"
+		Expect(r.enqueueRolloutManagersInNamespace(ctx, configMap)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,36 @@
+package rollouts
+
+import (
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+)
+
+const (
+	// IstioInjectLabel is the pod label used to request Istio sidecar injection from the default Istio revision
+	IstioInjectLabel = "sidecar.istio.io/inject"
+
+	// IstioRevisionLabel is the pod label used to request Istio sidecar injection from a specific Istio revision
+	IstioRevisionLabel = "istio.io/rev"
+
+	// LinkerdInjectAnnotation is the pod annotation used to request Linkerd proxy injection
+	LinkerdInjectAnnotation = "linkerd.io/inject"
+)
+
+// setServiceMeshLabelsAndAnnotations adds the labels/annotations that are required by the service mesh injection webhooks, to the given pod template labels/annotations.
+func setServiceMeshLabelsAndAnnotations(cr rolloutsmanagerv1alpha1.RolloutManager, labels map[string]string, annotations map[string]string) {
+	if cr.Spec.ServiceMesh == nil {
+		return
+	}
+
+	if istio := cr.Spec.ServiceMesh.Istio; istio != nil && istio.Inject {
+		// The default Istio injector only handles pods without a revision label, so only one of the labels should be set.
+		if istio.Revision != "" {
+			labels[IstioRevisionLabel] = istio.Revision
+		} else {
+			labels[IstioInjectLabel] = "true"
+		}
+	}
+
+	if linkerd := cr.Spec.ServiceMesh.Linkerd; linkerd != nil && linkerd.Inject {
+		annotations[LinkerdInjectAnnotation] = "enabled"
+	}
+}
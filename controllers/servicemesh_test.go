@@ -0,0 +1,128 @@
+package rollouts
+
+import (
+	"context"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Service mesh injection tests", func() {
+	var ctx context.Context
+	var a v1alpha1.RolloutManager
+	var r *RolloutManagerReconciler
+	var sa *corev1.ServiceAccount
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		a = *makeTestRolloutManager()
+
+		r = makeTestReconciler(&a)
+		Expect(createNamespace(r, a.Namespace)).To(Succeed())
+
+		sa = &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DefaultArgoRolloutsResourceName,
+				Namespace: a.Namespace,
+			},
+		}
+		Expect(r.Client.Create(ctx, sa)).To(Succeed())
+	})
+
+	It("should not add any injection labels/annotations if ServiceMesh is not set", func() {
+		deployment := generateDesiredRolloutsDeployment(a, *sa)
+		Expect(deployment.Spec.Template.Labels).ToNot(HaveKey(IstioInjectLabel))
+		Expect(deployment.Spec.Template.Labels).ToNot(HaveKey(IstioRevisionLabel))
+		Expect(deployment.Spec.Template.Annotations).ToNot(HaveKey(LinkerdInjectAnnotation))
+	})
+
+	It("should add the Istio inject label to the pod template, but not to the selector", func() {
+		a.Spec.ServiceMesh = &v1alpha1.RolloutManagerServiceMeshSpec{
+			Istio: &v1alpha1.RolloutManagerIstioSpec{Inject: true},
+		}
+
+		deployment := generateDesiredRolloutsDeployment(a, *sa)
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue(IstioInjectLabel, "true"))
+		Expect(deployment.Spec.Template.Labels).ToNot(HaveKey(IstioRevisionLabel))
+		Expect(deployment.Spec.Selector.MatchLabels).ToNot(HaveKey(IstioInjectLabel))
+	})
+
+	It("should add only the Istio revision label if a revision is specified", func() {
+		a.Spec.ServiceMesh = &v1alpha1.RolloutManagerServiceMeshSpec{
+			Istio: &v1alpha1.RolloutManagerIstioSpec{Inject: true, Revision: "canary"},
+		}
+
+		deployment := generateDesiredRolloutsDeployment(a, *sa)
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue(IstioRevisionLabel, "canary"))
+		Expect(deployment.Spec.Template.Labels).ToNot(HaveKey(IstioInjectLabel))
+	})
+
+	It("should add the Linkerd inject annotation to the pod template", func() {
+		a.Spec.ServiceMesh = &v1alpha1.RolloutManagerServiceMeshSpec{
+			Linkerd: &v1alpha1.RolloutManagerLinkerdSpec{Inject: true},
+		}
+
+		deployment := generateDesiredRolloutsDeployment(a, *sa)
+		Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue(LinkerdInjectAnnotation, "enabled"))
+	})
+
+	When("service mesh injection is enabled, and the mesh has injected containers and volumes into the Deployment", func() {
+
+		BeforeEach(func() {
+			a.Spec.ServiceMesh = &v1alpha1.RolloutManagerServiceMeshSpec{
+				Istio: &v1alpha1.RolloutManagerIstioSpec{Inject: true},
+			}
+		})
+
+		injectMesh := func(deployment *appsv1.Deployment) {
+			deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, corev1.Container{
+				Name:  "istio-proxy",
+				Image: "docker.io/istio/proxyv2",
+			})
+			deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name: "istio-envoy",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			})
+		}
+
+		It("should ignore the injected containers and volumes when normalizing the Deployment", func() {
+			desiredDeployment := generateDesiredRolloutsDeployment(a, *sa)
+			normalizedDesired, err := normalizeDeployment(desiredDeployment, a)
+			Expect(err).ToNot(HaveOccurred())
+
+			injectedDeployment := desiredDeployment.DeepCopy()
+			injectMesh(injectedDeployment)
+
+			normalizedInjected, err := normalizeDeployment(*injectedDeployment, a)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(normalizedInjected).To(Equal(normalizedDesired))
+		})
+
+		It("should preserve the injected containers and volumes when updating the Deployment", func() {
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+			By("injecting the mesh containers/volumes, and modifying a field managed by the operator")
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			injectMesh(deployment)
+			deployment.Spec.Template.Spec.Containers[0].Image = "my-image"
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			By("calling reconcileRolloutsDeployment, which should revert the image, but keep the injected containers/volumes")
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(2))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(getRolloutsContainerImage(a)))
+			Expect(deployment.Spec.Template.Spec.Containers[1].Name).To(Equal("istio-proxy"))
+			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(3))
+			Expect(deployment.Spec.Template.Spec.Volumes[2].Name).To(Equal("istio-envoy"))
+		})
+	})
+})
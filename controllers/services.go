@@ -0,0 +1,74 @@
+package rollouts
+
+import (
+	"reflect"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// setServiceIPFamilies sets the IP family policy and IP families of the Service, if they are specified in the RolloutManagerServiceSpec. Otherwise they are left empty, and defaulted by the API server from the configuration of the cluster (which fails on IPv6-only clusters for Services that request IPv4, and vice versa).
+func setServiceIPFamilies(svc *corev1.Service, serviceSpec *rolloutsmanagerv1alpha1.RolloutManagerServiceSpec) {
+	if serviceSpec == nil {
+		return
+	}
+	svc.Spec.IPFamilyPolicy = serviceSpec.IPFamilyPolicy
+	svc.Spec.IPFamilies = serviceSpec.IPFamilies
+}
+
+// serviceIPFamiliesMatch returns true if the IP family policy and IP families of the live Service match those of the expected Service. The fields that are not set on the expected Service are defaulted by the API server, so they are ignored.
+func serviceIPFamiliesMatch(liveSvc *corev1.Service, expectedSvc *corev1.Service) bool {
+	if expectedSvc.Spec.IPFamilyPolicy != nil && !reflect.DeepEqual(liveSvc.Spec.IPFamilyPolicy, expectedSvc.Spec.IPFamilyPolicy) {
+		return false
+	}
+	if len(expectedSvc.Spec.IPFamilies) > 0 && !reflect.DeepEqual(liveSvc.Spec.IPFamilies, expectedSvc.Spec.IPFamilies) {
+		return false
+	}
+	return true
+}
+
+// servicePrimaryIPFamilyChanged returns true if the primary (first) IP family of the live Service differs from that of the expected Service. The primary IP family of a Service is immutable, so the Service must be recreated.
+func servicePrimaryIPFamilyChanged(liveSvc *corev1.Service, expectedSvc *corev1.Service) bool {
+	return len(liveSvc.Spec.IPFamilies) > 0 && len(expectedSvc.Spec.IPFamilies) > 0 && liveSvc.Spec.IPFamilies[0] != expectedSvc.Spec.IPFamilies[0]
+}
+
+// setServiceType sets the type of the Service, and its annotations, from the RolloutManagerServiceSpec. The Service is of type ClusterIP if no type is specified, so that a type set outside of the operator is reverted.
+func setServiceType(svc *corev1.Service, serviceSpec *rolloutsmanagerv1alpha1.RolloutManagerServiceSpec) {
+	svc.Spec.Type = corev1.ServiceTypeClusterIP
+	if serviceSpec == nil {
+		return
+	}
+
+	switch serviceSpec.Type {
+	case rolloutsmanagerv1alpha1.RolloutManagerServiceTypeLoadBalancer:
+		svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	case rolloutsmanagerv1alpha1.RolloutManagerServiceTypeHeadless:
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+
+	if len(serviceSpec.Annotations) > 0 && svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+	for key, value := range serviceSpec.Annotations {
+		svc.Annotations[key] = value
+	}
+}
+
+// serviceHeadlessChanged returns true if the live Service is headless and the expected Service is not, or vice versa. The cluster IP of a Service is immutable, so the Service must be recreated.
+func serviceHeadlessChanged(liveSvc *corev1.Service, expectedSvc *corev1.Service) bool {
+	return (liveSvc.Spec.ClusterIP == corev1.ClusterIPNone) != (expectedSvc.Spec.ClusterIP == corev1.ClusterIPNone)
+}
+
+// preserveServiceNodePorts copies the node ports allocated by the API server to the live Service of type LoadBalancer into the matching ports of the expected Service, so that they are not reallocated on each update.
+func preserveServiceNodePorts(liveSvc *corev1.Service, expectedSvc *corev1.Service) {
+	if expectedSvc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return
+	}
+	for i := range expectedSvc.Spec.Ports {
+		for _, livePort := range liveSvc.Spec.Ports {
+			if livePort.Name == expectedSvc.Spec.Ports[i].Name && livePort.Port == expectedSvc.Spec.Ports[i].Port {
+				expectedSvc.Spec.Ports[i].NodePort = livePort.NodePort
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package rollouts
+
+import (
+	"context"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Service IP family tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+	})
+
+	It("should leave the IP families of the metrics Service to the API server if they are not specified", func() {
+		svc, err := r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(svc.Spec.IPFamilyPolicy).To(BeNil())
+		Expect(svc.Spec.IPFamilies).To(BeEmpty())
+
+		By("verifying that the values defaulted by the API server are not reverted")
+		singleStack := corev1.IPFamilyPolicySingleStack
+		svc.Spec.IPFamilyPolicy = &singleStack
+		svc.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+		Expect(r.Client.Update(ctx, svc)).To(Succeed())
+
+		svc, err = r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*svc.Spec.IPFamilyPolicy).To(Equal(corev1.IPFamilyPolicySingleStack))
+		Expect(svc.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv4Protocol}))
+	})
+
+	It("should set and update the IP families of the metrics Service", func() {
+		singleStack := corev1.IPFamilyPolicySingleStack
+		cr.Spec.MetricsService = &rolloutsmanagerv1alpha1.RolloutManagerServiceSpec{
+			IPFamilyPolicy: &singleStack,
+			IPFamilies:     []corev1.IPFamily{corev1.IPv6Protocol},
+		}
+
+		_, err := r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+
+		svc := &corev1.Service{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, svc)).To(Succeed())
+		Expect(*svc.Spec.IPFamilyPolicy).To(Equal(corev1.IPFamilyPolicySingleStack))
+		Expect(svc.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv6Protocol}))
+
+		By("upgrading the Service to dual-stack")
+		requireDualStack := corev1.IPFamilyPolicyRequireDualStack
+		cr.Spec.MetricsService.IPFamilyPolicy = &requireDualStack
+		cr.Spec.MetricsService.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}
+
+		_, err = r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, svc)).To(Succeed())
+		Expect(*svc.Spec.IPFamilyPolicy).To(Equal(corev1.IPFamilyPolicyRequireDualStack))
+		Expect(svc.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}))
+	})
+
+	It("should recreate the metrics Service when its primary IP family changes", func() {
+		cr.Spec.MetricsService = &rolloutsmanagerv1alpha1.RolloutManagerServiceSpec{
+			IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+		}
+		_, err := r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+
+		deletedServices := 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Delete: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				if _, isService := obj.(*corev1.Service); isService {
+					deletedServices++
+				}
+				return client.Delete(ctx, obj, opts...)
+			},
+		})
+
+		By("updating the IP families without changing the primary IP family")
+		cr.Spec.MetricsService.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+		_, err = r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedServices).To(Equal(0))
+
+		By("changing the primary IP family")
+		cr.Spec.MetricsService.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol}
+		_, err = r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedServices).To(Equal(1))
+
+		svc := &corev1.Service{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, svc)).To(Succeed())
+		Expect(svc.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv6Protocol}))
+		Expect(svc.OwnerReferences).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Service type tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+	})
+
+	It("should create a ClusterIP metrics Service by default, and revert a type set outside of the operator", func() {
+		svc, err := r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+
+		svc.Spec.Type = corev1.ServiceTypeNodePort
+		Expect(r.Client.Update(ctx, svc)).To(Succeed())
+
+		svc, err = r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+	})
+
+	It("should create an internal LoadBalancer metrics Service, and keep the node ports allocated to it", func() {
+		cr.Spec.MetricsService = &rolloutsmanagerv1alpha1.RolloutManagerServiceSpec{
+			Type:        rolloutsmanagerv1alpha1.RolloutManagerServiceTypeLoadBalancer,
+			Annotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+		}
+
+		svc, err := r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(svc.Annotations).To(HaveKeyWithValue("service.beta.kubernetes.io/aws-load-balancer-internal", "true"))
+
+		By("allocating a node port, as the API server would")
+		svc.Spec.Ports[0].NodePort = 31090
+		Expect(r.Client.Update(ctx, svc)).To(Succeed())
+		resourceVersion := svc.ResourceVersion
+
+		svc, err = r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(svc.Spec.Ports[0].NodePort).To(Equal(int32(31090)))
+		Expect(svc.ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should recreate the metrics Service when it is switched to and from Headless", func() {
+		_, err := r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+
+		deletedServices := 0
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Delete: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				if _, isService := obj.(*corev1.Service); isService {
+					deletedServices++
+				}
+				return client.Delete(ctx, obj, opts...)
+			},
+		})
+
+		By("switching to Headless")
+		cr.Spec.MetricsService = &rolloutsmanagerv1alpha1.RolloutManagerServiceSpec{Type: rolloutsmanagerv1alpha1.RolloutManagerServiceTypeHeadless}
+		svc, err := r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedServices).To(Equal(1))
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(svc.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+
+		By("not recreating the Service on the next reconciliation")
+		_, err = r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedServices).To(Equal(1))
+
+		By("switching back to ClusterIP")
+		cr.Spec.MetricsService = nil
+		svc, err = r.reconcileRolloutsMetricsService(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedServices).To(Equal(2))
+		Expect(svc.Spec.ClusterIP).ToNot(Equal(corev1.ClusterIPNone))
+	})
+})
@@ -0,0 +1,25 @@
+package rollouts
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// GracefulShutdownTimeoutEnvName is an environment variable that can be used to set the graceful shutdown timeout of the operator, instead of the --graceful-shutdown-timeout flag.
+	GracefulShutdownTimeoutEnvName = "GRACEFUL_SHUTDOWN_TIMEOUT"
+
+	// DefaultGracefulShutdownTimeout is how long the operator waits, on SIGTERM, for in-flight reconciliations to complete before exiting.
+	// This should be lower than the terminationGracePeriodSeconds of the operator Pod.
+	DefaultGracefulShutdownTimeout = 30 * time.Second
+
+	// StatusUpdateTimeout is how long a write of the status of a RolloutManager may take, once its reconciliation has completed.
+	StatusUpdateTimeout = 10 * time.Second
+)
+
+// statusUpdateContext returns a context for writing the status of a RolloutManager, at the end of a reconciliation.
+//
+// The context is not cancelled when the reconciliation context is (for example, when the operator receives SIGTERM mid-reconcile), so that the status reflects the work that was already done, rather than being left partially written. It is instead bounded by StatusUpdateTimeout.
+func statusUpdateContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), StatusUpdateTimeout)
+}
@@ -0,0 +1,62 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Shutdown tests", func() {
+
+	It("should not cancel the status update context when the reconciliation context is cancelled, but bound it by a timeout", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		statusCtx, statusCancel := statusUpdateContext(ctx)
+		defer statusCancel()
+
+		Expect(statusCtx.Err()).ToNot(HaveOccurred())
+		_, hasDeadline := statusCtx.Deadline()
+		Expect(hasDeadline).To(BeTrue())
+
+		statusCancel()
+		Expect(statusCtx.Err()).To(HaveOccurred())
+	})
+
+	It("should write the status of the RolloutManager, even if the operator is shutting down mid-reconcile", func() {
+		rm := makeTestRolloutManager()
+		r := makeTestReconciler(rm)
+		Expect(createNamespace(r, rm.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, rm.Namespace)
+		defer os.Unsetenv(ClusterScopedArgoRolloutsNamespaces)
+
+		// The fake client ignores the context, so fail status updates with a cancelled context, as a real client would
+		r.StatusClient = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		})
+
+		By("reconciling with a context that is cancelled, as on SIGTERM")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: rm.Name, Namespace: rm.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.Client.Get(context.Background(), client.ObjectKeyFromObject(rm), rm)).To(Succeed())
+		Expect(rm.Status.Conditions).ToNot(BeEmpty())
+		Expect(rm.Status.Conditions[0].Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess))
+	})
+})
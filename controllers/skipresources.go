@@ -0,0 +1,42 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isResourceSkipped returns true if the RolloutManager specifies, via .spec.skipResources, that the operator should not manage the given kind of resource.
+func isResourceSkipped(cr rolloutsmanagerv1alpha1.RolloutManager, resource rolloutsmanagerv1alpha1.RolloutManagerSkippableResource) bool {
+	for _, skipped := range cr.Spec.SkipResources {
+		if skipped == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteSkippedResource deletes a namespaced resource which is skipped via .spec.skipResources, if it exists and was created by the operator for the RolloutManager (that is, the RolloutManager is its controller). Otherwise, the resource is managed by users, and is left untouched.
+func (r *RolloutManagerReconciler) deleteSkippedResource(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error {
+
+	if err := fetchObject(ctx, r.Client, cr.Namespace, obj.GetName(), obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the %s: %w", r.describeResource(cr, obj), err)
+	}
+
+	if !metav1.IsControlledBy(obj, &cr) {
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("%s is skipped by .spec.skipResources, hence deleting it", r.describeResource(cr, obj)))
+	if err := r.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the %s: %w", r.describeResource(cr, obj), err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Skip resources tests", func() {
+
+	var (
+		ctx context.Context
+		cr  rolloutsmanagerv1alpha1.RolloutManager
+		r   *RolloutManagerReconciler
+		req reconcile.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		Expect(r.Client.Create(ctx, &crdv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: serviceMonitorsCRDName}})).To(Succeed())
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+	})
+
+	setSkipResources := func(resources ...rolloutsmanagerv1alpha1.RolloutManagerSkippableResource) {
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		cr.Spec.SkipResources = resources
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+	}
+
+	It("should delete the metrics Service and ServiceMonitor created by the operator once they are skipped", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, &corev1.Service{})).To(Succeed())
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &monitoringv1.ServiceMonitor{})).To(Succeed())
+
+		setSkipResources(rolloutsmanagerv1alpha1.SkippableResourceMetricsService, rolloutsmanagerv1alpha1.SkippableResourceServiceMonitor)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, &corev1.Service{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		err = fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &monitoringv1.ServiceMonitor{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not modify nor delete a skipped metrics Service which is managed by users, and still create the ServiceMonitor for it", func() {
+		userService := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsMetricsServiceName, Namespace: cr.Namespace, Labels: map[string]string{"owner": "gitops"}},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "metrics", Port: 9090}}},
+		}
+		Expect(r.Client.Create(ctx, userService)).To(Succeed())
+
+		setSkipResources(rolloutsmanagerv1alpha1.SkippableResourceMetricsService)
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		liveService := &corev1.Service{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsMetricsServiceName, liveService)).To(Succeed())
+		Expect(liveService.Labels).To(Equal(userService.Labels))
+		Expect(liveService.Spec.Ports).To(Equal(userService.Spec.Ports))
+
+		sm := &monitoringv1.ServiceMonitor{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, sm)).To(Succeed())
+		Expect(sm.Spec.Selector.MatchLabels).To(HaveKeyWithValue("app.kubernetes.io/name", DefaultArgoRolloutsMetricsServiceName))
+	})
+
+	It("should not reconcile the aggregate ClusterRoles once they are skipped", func() {
+		setSkipResources(rolloutsmanagerv1alpha1.SkippableResourceAggregateClusterRoles)
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, aggregationType := range []string{"aggregate-to-admin", "aggregate-to-edit", "aggregate-to-view"} {
+			err := fetchObject(ctx, r.Client, "", DefaultArgoRolloutsResourceName+"-"+aggregationType, &rbacv1.ClusterRole{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}
+		Expect(fetchObject(ctx, r.Client, "", DefaultArgoRolloutsResourceName, &rbacv1.ClusterRole{})).To(Succeed())
+	})
+})
@@ -0,0 +1,64 @@
+package rollouts
+
+import (
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// DefaultStartupProbePeriodSeconds and DefaultStartupProbeFailureThreshold give the Rollouts controller 5 minutes to start, unless they are overridden by .spec.startupProbe.
+	DefaultStartupProbePeriodSeconds    = int32(10)
+	DefaultStartupProbeFailureThreshold = int32(30)
+)
+
+// rolloutsContainerStartupProbe returns the startup probe of the Rollouts controller container. It uses the health check endpoint of the liveness probe, which is only performed once the startup probe succeeded, so that the Rollouts controller is not restarted while it syncs its informers on a cold start.
+func rolloutsContainerStartupProbe(cr rolloutsmanagerv1alpha1.RolloutManager) *corev1.Probe {
+
+	periodSeconds := DefaultStartupProbePeriodSeconds
+	failureThreshold := DefaultStartupProbeFailureThreshold
+
+	if cr.Spec.StartupProbe != nil {
+		if cr.Spec.StartupProbe.PeriodSeconds > 0 {
+			periodSeconds = cr.Spec.StartupProbe.PeriodSeconds
+		}
+		if cr.Spec.StartupProbe.FailureThreshold > 0 {
+			failureThreshold = cr.Spec.StartupProbe.FailureThreshold
+		}
+	}
+
+	return &corev1.Probe{
+		FailureThreshold: failureThreshold,
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+				Port: intstr.FromString("healthz"),
+			},
+		},
+		PeriodSeconds:    periodSeconds,
+		SuccessThreshold: int32(1),
+		TimeoutSeconds:   int32(10),
+	}
+}
+
+// normalizeStartupProbe returns the fields of the startup probe of the Rollouts controller container which are set by the operator (see rolloutsContainerStartupProbe), or nil if the container has no HTTP startup probe.
+func normalizeStartupProbe(probe *corev1.Probe) *corev1.Probe {
+
+	if probe == nil || probe.ProbeHandler.HTTPGet == nil {
+		return nil
+	}
+
+	return &corev1.Probe{
+		FailureThreshold: probe.FailureThreshold,
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: probe.ProbeHandler.HTTPGet.Path,
+				Port: probe.ProbeHandler.HTTPGet.Port,
+			},
+		},
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		SuccessThreshold:    probe.SuccessThreshold,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+	}
+}
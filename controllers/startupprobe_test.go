@@ -0,0 +1,91 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Startup probe tests", func() {
+
+	var cr rolloutsmanagerv1alpha1.RolloutManager
+
+	BeforeEach(func() {
+		cr = *makeTestRolloutManager()
+	})
+
+	It("should give the Rollouts controller 5 minutes to start by default", func() {
+		probe := rolloutsContainer(cr).StartupProbe
+		Expect(probe).ToNot(BeNil())
+		Expect(probe.HTTPGet.Path).To(Equal("/healthz"))
+		Expect(probe.HTTPGet.Port).To(Equal(intstr.FromString("healthz")))
+		Expect(probe.PeriodSeconds * probe.FailureThreshold).To(Equal(int32(300)))
+	})
+
+	It("should use the period and failure threshold of the RolloutManager", func() {
+		cr.Spec.StartupProbe = &rolloutsmanagerv1alpha1.RolloutManagerStartupProbeSpec{PeriodSeconds: 15, FailureThreshold: 80}
+
+		probe := rolloutsContainer(cr).StartupProbe
+		Expect(probe.PeriodSeconds).To(Equal(int32(15)))
+		Expect(probe.FailureThreshold).To(Equal(int32(80)))
+
+		By("defaulting the fields which are not specified")
+		cr.Spec.StartupProbe = &rolloutsmanagerv1alpha1.RolloutManagerStartupProbeSpec{FailureThreshold: 90}
+		probe = rolloutsContainer(cr).StartupProbe
+		Expect(probe.PeriodSeconds).To(Equal(DefaultStartupProbePeriodSeconds))
+		Expect(probe.FailureThreshold).To(Equal(int32(90)))
+	})
+
+	It("should add the startup probe to an existing Rollouts controller Deployment, and keep it up to date", func() {
+		ctx := context.Background()
+		r := makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		reconcileAndGetDeployment := func() *appsv1.Deployment {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			return deployment
+		}
+
+		By("removing the startup probe, as a previous version of the operator would have created the Deployment")
+		deployment := reconcileAndGetDeployment()
+		deployment.Spec.Template.Spec.Containers[0].StartupProbe = nil
+		Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+		deployment = reconcileAndGetDeployment()
+		Expect(deployment.Spec.Template.Spec.Containers[0].StartupProbe).To(Equal(rolloutsContainerStartupProbe(cr)))
+
+		By("updating the failure threshold in the RolloutManager")
+		Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+		cr.Spec.StartupProbe = &rolloutsmanagerv1alpha1.RolloutManagerStartupProbeSpec{FailureThreshold: 120}
+		Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+		deployment = reconcileAndGetDeployment()
+		Expect(deployment.Spec.Template.Spec.Containers[0].StartupProbe.FailureThreshold).To(Equal(int32(120)))
+	})
+
+	It("normalizeStartupProbe should ignore the fields defaulted by the API server", func() {
+		probe := rolloutsContainerStartupProbe(cr)
+		defaulted := probe.DeepCopy()
+		defaulted.HTTPGet.Scheme = corev1.URISchemeHTTP
+		defaulted.TerminationGracePeriodSeconds = nil
+
+		Expect(normalizeStartupProbe(defaulted)).To(Equal(normalizeStartupProbe(probe)))
+		Expect(normalizeStartupProbe(nil)).To(BeNil())
+	})
+})
@@ -2,16 +2,47 @@ package rollouts
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
-// determineStatusPhase calculates and returns RolloutManager's current .status.phase and .status.rolloutcontroller, both based on Deployment status.
+// ControllerDegradedMessage is the prefix of the message of the ControllerDegraded condition, which is followed by the causes for which the Rollouts controller pods cannot become ready.
+const ControllerDegradedMessage = "The Rollouts controller cannot become available: "
+
+// degradedContainerWaitingReasons are the reasons of a waiting container which are not resolved without an intervention (or only after a backoff), so that they are reported as a Degraded phase rather than as a pod that is still starting.
+var degradedContainerWaitingReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"CrashLoopBackOff":           true,
+}
+
+// controllerDegradation is a cause for which the Rollouts controller pods cannot become ready.
+type controllerDegradation struct {
+	reason      string
+	description string
+}
+
+// determineStatusPhase calculates and returns RolloutManager's current .status.phase and .status.rolloutcontroller, both based on Deployment status (and on the Rollouts controller pods, while the Deployment is not available).
 func (r *RolloutManagerReconciler) determineStatusPhase(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (reconcileStatusResult, error) {
 
 	status := rolloutsmanagerv1alpha1.PhaseUnknown
+	degradations := []controllerDegradation{}
 
 	deploy := &appsv1.Deployment{}
 	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deploy); err != nil {
@@ -26,15 +57,36 @@ func (r *RolloutManagerReconciler) determineStatusPhase(ctx context.Context, cr
 		// Deployment exists
 
 		if deploy.Spec.Replicas != nil {
-			status = rolloutsmanagerv1alpha1.PhasePending
-			if deploy.Status.ReadyReplicas == *deploy.Spec.Replicas {
+			if deploymentRolledOut(deploy) {
 				status = rolloutsmanagerv1alpha1.PhaseAvailable
+			} else {
+				var err error
+				if degradations, err = r.detectControllerDegradations(ctx, cr, deploy); err != nil {
+					return reconcileStatusResult{}, err
+				}
+
+				switch {
+				case len(degradations) > 0:
+					status = rolloutsmanagerv1alpha1.PhaseDegraded
+				case deploymentInitialRollout(deploy, cr.Status.Phase):
+					status = rolloutsmanagerv1alpha1.PhasePending
+				default:
+					status = rolloutsmanagerv1alpha1.PhaseProgressing
+				}
 			}
 		}
 	}
 
 	var res reconcileStatusResult
 
+	res.controllerDegradations = []string{}
+	for _, degradation := range degradations {
+		res.controllerDegradations = append(res.controllerDegradations, degradation.description)
+	}
+	if len(degradations) > 0 {
+		res.controllerDegradationReason = degradations[0].reason
+	}
+
 	if cr.Status.RolloutController != status {
 		res.rolloutController = &status
 	}
@@ -45,3 +97,299 @@ func (r *RolloutManagerReconciler) determineStatusPhase(ctx context.Context, cr
 
 	return res, nil
 }
+
+// deploymentRolledOut returns true if all of the pods of the Deployment are ready, and up to date with its latest pod template.
+func deploymentRolledOut(deploy *appsv1.Deployment) bool {
+	replicas := *deploy.Spec.Replicas
+
+	return deploy.Status.ObservedGeneration >= deploy.Generation &&
+		deploy.Status.UpdatedReplicas == replicas &&
+		deploy.Status.Replicas == replicas &&
+		deploy.Status.ReadyReplicas == replicas
+}
+
+// deploymentInitialRollout returns true if the Deployment is rolling out its first pods: it was never updated, none of its pods are available yet, and the RolloutManager was never available. An update (or a scale-up) of an existing Deployment is reported as Progressing instead.
+func deploymentInitialRollout(deploy *appsv1.Deployment, previousPhase rolloutsmanagerv1alpha1.RolloutControllerPhase) bool {
+
+	if previousPhase == rolloutsmanagerv1alpha1.PhaseAvailable || previousPhase == rolloutsmanagerv1alpha1.PhaseProgressing {
+		return false
+	}
+
+	if revision, err := strconv.Atoi(deploy.Annotations[deploymentRevisionAnnotation]); err == nil && revision > 1 {
+		return false
+	}
+
+	return deploy.Status.AvailableReplicas == 0
+}
+
+// detectControllerDegradations returns the causes for which the pods of the Rollouts controller Deployment cannot become ready: a failure reported by the Deployment (its progress deadline was exceeded, or its pods could not be created), a pod which cannot be scheduled, or a container which cannot be started (for example, because its image cannot be pulled).
+func (r *RolloutManagerReconciler) detectControllerDegradations(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, deploy *appsv1.Deployment) ([]controllerDegradation, error) {
+
+	res := []controllerDegradation{}
+
+	for _, condition := range deploy.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded" {
+			res = append(res, controllerDegradation{reason: condition.Reason, description: fmt.Sprintf("Deployment %s exceeded its progress deadline", deploy.Name)})
+		}
+		if condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue {
+			res = append(res, controllerDegradation{reason: condition.Reason, description: describeDegradation(fmt.Sprintf("Deployment %s cannot create its pods", deploy.Name), condition.Reason, condition.Message)})
+		}
+	}
+
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, client.InNamespace(cr.Namespace), client.MatchingLabels{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName}); err != nil {
+		return nil, fmt.Errorf("failed to list the Rollouts controller pods: %w", err)
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable {
+				res = append(res, controllerDegradation{reason: condition.Reason, description: describeDegradation(fmt.Sprintf("pod %s cannot be scheduled", pod.Name), "", condition.Message)})
+			}
+		}
+
+		for _, containerStatus := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			if waiting := containerStatus.State.Waiting; waiting != nil && degradedContainerWaitingReasons[waiting.Reason] {
+				res = append(res, controllerDegradation{reason: waiting.Reason, description: describeDegradation(fmt.Sprintf("container %s of pod %s is waiting", containerStatus.Name, pod.Name), waiting.Reason, waiting.Message)})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// describeDegradation returns the description of a cause of a Degraded phase, followed by its reason and message, if any.
+func describeDegradation(description string, reason string, message string) string {
+	if reason != "" {
+		description += fmt.Sprintf(" (%s)", reason)
+	}
+	if message = strings.Join(strings.Fields(message), " "); message != "" {
+		description += ": " + message
+	}
+	return description
+}
+
+// createControllerDegradedCondition returns the ControllerDegraded condition for the given causes, with the given reason.
+func createControllerDegradedCondition(reason string, degradations []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerControllerDegradedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: ControllerDegradedMessage + strings.Join(degradations, ", "),
+	}
+}
+
+// setReadinessConditions sets the kstatus conditions (Ready, Reconciling and Stalled) on the RolloutManager status, based on its Reconciled condition (and the conditions of its components, see rolloutsComponents) and phase. Returns true if the conditions were changed.
+func setReadinessConditions(rm *rolloutsmanagerv1alpha1.RolloutManager) bool {
+
+	var reconciled *metav1.Condition
+	for i := range rm.Status.Conditions {
+		if rm.Status.Conditions[i].Type == rolloutsmanagerv1alpha1.RolloutManagerConditionType {
+			reconciled = &rm.Status.Conditions[i]
+			break
+		}
+	}
+
+	// A failure of a component which is reconciled by its own controller (see rolloutsComponents) is reported like a failure of the RolloutManager
+	if reconciled == nil || reconciled.Status == metav1.ConditionTrue {
+		for i := range rm.Status.Conditions {
+			if isComponentConditionType(rm.Status.Conditions[i].Type) && rm.Status.Conditions[i].Status != metav1.ConditionTrue {
+				reconciled = &rm.Status.Conditions[i]
+				break
+			}
+		}
+	}
+
+	ready := metav1.Condition{
+		Type:   rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType,
+		Status: metav1.ConditionUnknown,
+		Reason: string(rolloutsmanagerv1alpha1.PhaseUnknown),
+	}
+	var reconciling, stalled *metav1.Condition
+
+	if reconciled != nil && reconciled.Status != metav1.ConditionTrue {
+
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = reconciled.Reason
+		ready.Message = reconciled.Message
+
+		abnormal := metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  reconciled.Reason,
+			Message: reconciled.Message,
+		}
+
+		// An unexpected error is retried by the operator, while any other failure requires the RolloutManager to be corrected by the user
+		if reconciled.Reason == rolloutsmanagerv1alpha1.RolloutManagerReasonErrorOccurred {
+			abnormal.Type = rolloutsmanagerv1alpha1.RolloutManagerReconcilingConditionType
+			reconciling = &abnormal
+		} else {
+			abnormal.Type = rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType
+			stalled = &abnormal
+		}
+
+	} else {
+
+		switch rm.Status.Phase {
+		case rolloutsmanagerv1alpha1.PhaseAvailable:
+			ready.Status = metav1.ConditionTrue
+			ready.Reason = string(rolloutsmanagerv1alpha1.PhaseAvailable)
+
+		case rolloutsmanagerv1alpha1.PhasePending:
+			ready.Status = metav1.ConditionFalse
+			ready.Reason = string(rolloutsmanagerv1alpha1.PhasePending)
+			ready.Message = "Waiting for the Rollouts controller Deployment to become available"
+
+			reconciling = &metav1.Condition{
+				Type:    rolloutsmanagerv1alpha1.RolloutManagerReconcilingConditionType,
+				Status:  metav1.ConditionTrue,
+				Reason:  ready.Reason,
+				Message: ready.Message,
+			}
+
+		case rolloutsmanagerv1alpha1.PhaseProgressing:
+			ready.Status = metav1.ConditionFalse
+			ready.Reason = string(rolloutsmanagerv1alpha1.PhaseProgressing)
+			ready.Message = "Waiting for the rollout of the Rollouts controller Deployment to complete"
+
+			reconciling = &metav1.Condition{
+				Type:    rolloutsmanagerv1alpha1.RolloutManagerReconcilingConditionType,
+				Status:  metav1.ConditionTrue,
+				Reason:  ready.Reason,
+				Message: ready.Message,
+			}
+
+		case rolloutsmanagerv1alpha1.PhaseDegraded:
+			ready.Status = metav1.ConditionFalse
+			ready.Reason = string(rolloutsmanagerv1alpha1.PhaseDegraded)
+			ready.Message = "The Rollouts controller cannot become available"
+			for _, condition := range rm.Status.Conditions {
+				if condition.Type == rolloutsmanagerv1alpha1.RolloutManagerControllerDegradedConditionType {
+					ready.Reason = condition.Reason
+					ready.Message = condition.Message
+				}
+			}
+
+			stalled = &metav1.Condition{
+				Type:    rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType,
+				Status:  metav1.ConditionTrue,
+				Reason:  ready.Reason,
+				Message: ready.Message,
+			}
+
+		case rolloutsmanagerv1alpha1.PhaseFailure:
+			ready.Status = metav1.ConditionFalse
+			ready.Reason = string(rolloutsmanagerv1alpha1.PhaseFailure)
+			ready.Message = "The Rollouts controller Deployment does not exist"
+		}
+
+		// A crash of the Rollouts controller is the most likely reason why it is not available, so it is reported instead
+		for _, condition := range rm.Status.Conditions {
+			if condition.Type == rolloutsmanagerv1alpha1.RolloutManagerControllerCrashingConditionType && ready.Status != metav1.ConditionTrue {
+				ready.Status = metav1.ConditionFalse
+				ready.Reason = condition.Reason
+				ready.Message = condition.Message
+				for _, abnormal := range []*metav1.Condition{reconciling, stalled} {
+					if abnormal != nil {
+						abnormal.Reason = condition.Reason
+						abnormal.Message = condition.Message
+					}
+				}
+			}
+		}
+	}
+
+	changed, conditions := insertOrUpdateConditionsInSlice(ready, rm.Status.Conditions)
+
+	for conditionType, condition := range map[string]*metav1.Condition{
+		rolloutsmanagerv1alpha1.RolloutManagerReconcilingConditionType: reconciling,
+		rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType:     stalled,
+	} {
+		var conditionChanged bool
+		if condition != nil {
+			conditionChanged, conditions = insertOrUpdateConditionsInSlice(*condition, conditions)
+		} else {
+			conditionChanged, conditions = removeConditionFromSlice(conditionType, conditions)
+		}
+		changed = changed || conditionChanged
+	}
+
+	rm.Status.Conditions = conditions
+
+	return changed
+}
+
+// setReconcileFailure records the failure of a reconciliation of the RolloutManager on its status: .status.failureCount is incremented, and .status.lastFailureTime and .status.lastError are set to the time and error of the failure. If the reconciliation succeeded (reconcileErr is nil), they are reset. It returns true if the status changed.
+func setReconcileFailure(rm *rolloutsmanagerv1alpha1.RolloutManager, reconcileErr error) bool {
+
+	if reconcileErr == nil {
+		if rm.Status.FailureCount == 0 && rm.Status.LastFailureTime == nil && rm.Status.LastError == "" {
+			return false
+		}
+		rm.Status.FailureCount = 0
+		rm.Status.LastFailureTime = nil
+		rm.Status.LastError = ""
+		return true
+	}
+
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	rm.Status.FailureCount++
+	rm.Status.LastFailureTime = &now
+	rm.Status.LastError = reconcileErr.Error()
+	return true
+}
+
+// isReconcileFailureUpdate returns true if an update of a RolloutManager was written by a failed reconciliation: it changed the fields of its status which record the failures of its reconciliations (see setReconcileFailure), but not its generation (that is, its spec) or its metadata.
+// Such updates do not trigger a reconciliation, so that a failed reconciliation is retried with a backoff, rather than immediately. This includes the update written by the first failed reconciliation, which usually also changes the phase and the conditions of the RolloutManager: these are set by the same reconciliation, so they do not need to be reconciled again.
+// Updates of the status which do not record a failure (for example, the conditions of components, see rolloutsComponents) are not matched.
+func isReconcileFailureUpdate(e event.UpdateEvent) bool {
+
+	oldRM, oldOK := e.ObjectOld.(*rolloutsmanagerv1alpha1.RolloutManager)
+	newRM, newOK := e.ObjectNew.(*rolloutsmanagerv1alpha1.RolloutManager)
+	if !oldOK || !newOK {
+		return false
+	}
+
+	// A change of the spec is always reconciled
+	if oldRM.Generation != newRM.Generation {
+		return false
+	}
+
+	// A change of the metadata (for example, of an annotation which requests a restore of the backup, or the deletion of the RolloutManager) is always reconciled
+	if !equality.Semantic.DeepEqual(oldRM.Labels, newRM.Labels) || !equality.Semantic.DeepEqual(oldRM.Annotations, newRM.Annotations) ||
+		!equality.Semantic.DeepEqual(oldRM.Finalizers, newRM.Finalizers) || !oldRM.DeletionTimestamp.Equal(newRM.DeletionTimestamp) {
+		return false
+	}
+
+	return oldRM.Status.FailureCount != newRM.Status.FailureCount || oldRM.Status.LastError != newRM.Status.LastError ||
+		!oldRM.Status.LastFailureTime.Equal(newRM.Status.LastFailureTime)
+}
+
+// setLastAppliedSpec sets .status.lastAppliedSpec to the snapshot of the spec that was successfully applied, and .status.lastAppliedTime to the current time if the snapshot changed. It returns true if the status changed.
+func setLastAppliedSpec(rm *rolloutsmanagerv1alpha1.RolloutManager, appliedSpec rolloutsmanagerv1alpha1.RolloutManagerSpec) bool {
+
+	// Fields are marshalled in a stable order, and empty fields are omitted, so that equivalent specs have the same snapshot
+	snapshot, err := json.Marshal(appliedSpec)
+	if err != nil {
+		log.Error(err, "unable to marshal the applied spec of RolloutManager")
+		return false
+	}
+
+	if string(snapshot) == rm.Status.LastAppliedSpec {
+		return false
+	}
+
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	rm.Status.LastAppliedSpec = string(snapshot)
+	rm.Status.LastAppliedTime = &now
+	return true
+}
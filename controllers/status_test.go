@@ -2,12 +2,20 @@ package rollouts
 
 import (
 	"context"
+	"errors"
+	"os"
+	"time"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var _ = Describe("RolloutManager Test", func() {
@@ -55,6 +63,8 @@ var _ = Describe("RolloutManager Test", func() {
 		Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhasePending))
 
 		By("When deployment exists and required number of replicas are up and running.")
+		deploy.Status.Replicas = 1
+		deploy.Status.UpdatedReplicas = 1
 		deploy.Status.ReadyReplicas = 1
 		deploy.Spec.Replicas = &requiredReplicas
 
@@ -65,6 +75,411 @@ var _ = Describe("RolloutManager Test", func() {
 
 		Expect(*rr.rolloutController).To(Equal(rolloutsmanagerv1alpha1.PhaseAvailable))
 		Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseAvailable))
+		Expect(rr.controllerDegradations).To(BeEmpty())
 
 	})
+
+	Context("when the Rollouts controller Deployment is not available", func() {
+
+		var (
+			ctx    context.Context
+			a      *rolloutsmanagerv1alpha1.RolloutManager
+			r      *RolloutManagerReconciler
+			deploy *appsv1.Deployment
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			a = makeTestRolloutManager()
+			r = makeTestReconciler(a)
+			Expect(createNamespace(r, a.Namespace)).To(Succeed())
+
+			replicas := int32(1)
+			deploy = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        DefaultArgoRolloutsResourceName,
+					Namespace:   a.Namespace,
+					Annotations: map[string]string{deploymentRevisionAnnotation: "1"},
+				},
+				Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+			}
+			Expect(r.Client.Create(ctx, deploy)).To(Succeed())
+		})
+
+		createControllerPod := func(status corev1.PodStatus) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      DefaultArgoRolloutsResourceName + "-abc",
+					Namespace: a.Namespace,
+					Labels:    map[string]string{DefaultRolloutsSelectorKey: DefaultArgoRolloutsResourceName},
+				},
+				Status: status,
+			}
+			Expect(r.Client.Create(ctx, pod)).To(Succeed())
+		}
+
+		It("should report Progressing while an update of the Deployment is rolled out", func() {
+			deploy.Annotations[deploymentRevisionAnnotation] = "2"
+			Expect(r.Client.Update(ctx, deploy)).To(Succeed())
+			deploy.Status = appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, ReadyReplicas: 1, AvailableReplicas: 1}
+			Expect(r.Client.Status().Update(ctx, deploy)).To(Succeed())
+
+			rr, err := r.determineStatusPhase(ctx, *a)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseProgressing))
+
+			By("reporting Progressing as well when a pod of a Deployment which was available is restarting")
+			deploy.Annotations[deploymentRevisionAnnotation] = "1"
+			Expect(r.Client.Update(ctx, deploy)).To(Succeed())
+			deploy.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1}
+			Expect(r.Client.Status().Update(ctx, deploy)).To(Succeed())
+
+			a.Status.Phase = rolloutsmanagerv1alpha1.PhaseAvailable
+			rr, err = r.determineStatusPhase(ctx, *a)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseProgressing))
+		})
+
+		It("should report Degraded, with the reason, when the image of the Rollouts controller cannot be pulled", func() {
+			createControllerPod(corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  DefaultArgoRolloutsResourceName,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+				}},
+			})
+
+			rr, err := r.determineStatusPhase(ctx, *a)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseDegraded))
+			Expect(rr.controllerDegradationReason).To(Equal("ImagePullBackOff"))
+			Expect(rr.controllerDegradations).To(Equal([]string{
+				"container argo-rollouts of pod argo-rollouts-abc is waiting (ImagePullBackOff): Back-off pulling image",
+			}))
+
+			By("setting the ControllerDegraded and readiness conditions")
+			Expect(updateStatusConditionOfRolloutManager(ctx, rr, a, r.Client, log)).To(Succeed())
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(a), a)).To(Succeed())
+
+			var ready, degraded *metav1.Condition
+			for i := range a.Status.Conditions {
+				switch a.Status.Conditions[i].Type {
+				case rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType:
+					ready = &a.Status.Conditions[i]
+				case rolloutsmanagerv1alpha1.RolloutManagerControllerDegradedConditionType:
+					degraded = &a.Status.Conditions[i]
+				}
+			}
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Reason).To(Equal("ImagePullBackOff"))
+			Expect(degraded.Message).To(HavePrefix(ControllerDegradedMessage))
+			Expect(ready).ToNot(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("ImagePullBackOff"))
+		})
+
+		It("should report Degraded when the pods of the Rollouts controller cannot be scheduled", func() {
+			createControllerPod(corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/3 nodes are available: 3 Insufficient cpu.",
+				}},
+			})
+
+			rr, err := r.determineStatusPhase(ctx, *a)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseDegraded))
+			Expect(rr.controllerDegradationReason).To(Equal(corev1.PodReasonUnschedulable))
+			Expect(rr.controllerDegradations).To(Equal([]string{
+				"pod argo-rollouts-abc cannot be scheduled: 0/3 nodes are available: 3 Insufficient cpu.",
+			}))
+		})
+
+		It("should report Pending, rather than Degraded, while the first pod is starting", func() {
+			createControllerPod(corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  DefaultArgoRolloutsResourceName,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+				}},
+			})
+
+			rr, err := r.determineStatusPhase(ctx, *a)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhasePending))
+			Expect(rr.controllerDegradations).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("setReadinessConditions tests", func() {
+
+	findCondition := func(rm rolloutsmanagerv1alpha1.RolloutManager, conditionType string) *metav1.Condition {
+		for i := range rm.Status.Conditions {
+			if rm.Status.Conditions[i].Type == conditionType {
+				return &rm.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	DescribeTable("should set the kstatus conditions based on the Reconciled condition and the phase",
+		func(reconciled metav1.Condition, phase rolloutsmanagerv1alpha1.RolloutControllerPhase, expectedReady metav1.ConditionStatus, expectedReason string, expectReconciling bool, expectStalled bool) {
+
+			rm := makeTestRolloutManager()
+			rm.Status.Phase = phase
+			rm.Status.Conditions = []metav1.Condition{reconciled}
+
+			Expect(setReadinessConditions(rm)).To(BeTrue())
+
+			ready := findCondition(*rm, rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType)
+			Expect(ready).ToNot(BeNil())
+			Expect(ready.Status).To(Equal(expectedReady))
+			Expect(ready.Reason).To(Equal(expectedReason))
+
+			Expect(findCondition(*rm, rolloutsmanagerv1alpha1.RolloutManagerReconcilingConditionType) != nil).To(Equal(expectReconciling))
+			Expect(findCondition(*rm, rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType) != nil).To(Equal(expectStalled))
+
+			By("verifying that the conditions are unchanged when called again")
+			Expect(setReadinessConditions(rm)).To(BeFalse())
+		},
+		Entry("when the RolloutManager is available", createCondition(""), rolloutsmanagerv1alpha1.PhaseAvailable,
+			metav1.ConditionTrue, string(rolloutsmanagerv1alpha1.PhaseAvailable), false, false),
+		Entry("when the Rollouts controller is pending", createCondition(""), rolloutsmanagerv1alpha1.PhasePending,
+			metav1.ConditionFalse, string(rolloutsmanagerv1alpha1.PhasePending), true, false),
+		Entry("when the Rollouts controller is progressing", createCondition(""), rolloutsmanagerv1alpha1.PhaseProgressing,
+			metav1.ConditionFalse, string(rolloutsmanagerv1alpha1.PhaseProgressing), true, false),
+		Entry("when the Rollouts controller is degraded", createCondition(""), rolloutsmanagerv1alpha1.PhaseDegraded,
+			metav1.ConditionFalse, string(rolloutsmanagerv1alpha1.PhaseDegraded), false, true),
+		Entry("when the Rollouts controller Deployment does not exist", createCondition(""), rolloutsmanagerv1alpha1.PhaseFailure,
+			metav1.ConditionFalse, string(rolloutsmanagerv1alpha1.PhaseFailure), false, false),
+		Entry("when the phase is not yet known", createCondition(""), rolloutsmanagerv1alpha1.RolloutControllerPhase(""),
+			metav1.ConditionUnknown, string(rolloutsmanagerv1alpha1.PhaseUnknown), false, false),
+		Entry("when the RolloutManager is invalid", createCondition(UnsupportedImageNotDigest, rolloutsmanagerv1alpha1.RolloutManagerReasonUnsupportedImage), rolloutsmanagerv1alpha1.PhaseFailure,
+			metav1.ConditionFalse, rolloutsmanagerv1alpha1.RolloutManagerReasonUnsupportedImage, false, true),
+		Entry("when an unexpected error occurred", createCondition("an error"), rolloutsmanagerv1alpha1.PhaseAvailable,
+			metav1.ConditionFalse, rolloutsmanagerv1alpha1.RolloutManagerReasonErrorOccurred, true, false),
+	)
+
+	It("should remove the Reconciling and Stalled conditions once they are no longer true", func() {
+		rm := makeTestRolloutManager()
+		rm.Status.Phase = rolloutsmanagerv1alpha1.PhasePending
+		rm.Status.Conditions = []metav1.Condition{createCondition(UnsupportedImageNotDigest, rolloutsmanagerv1alpha1.RolloutManagerReasonUnsupportedImage)}
+		Expect(setReadinessConditions(rm)).To(BeTrue())
+		Expect(findCondition(*rm, rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType)).ToNot(BeNil())
+
+		rm.Status.Conditions[0] = createCondition("")
+		Expect(setReadinessConditions(rm)).To(BeTrue())
+		Expect(findCondition(*rm, rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType)).To(BeNil())
+		Expect(findCondition(*rm, rolloutsmanagerv1alpha1.RolloutManagerReconcilingConditionType)).ToNot(BeNil())
+
+		rm.Status.Phase = rolloutsmanagerv1alpha1.PhaseAvailable
+		Expect(setReadinessConditions(rm)).To(BeTrue())
+		Expect(findCondition(*rm, rolloutsmanagerv1alpha1.RolloutManagerReconcilingConditionType)).To(BeNil())
+		Expect(findCondition(*rm, rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType).Status).To(Equal(metav1.ConditionTrue))
+	})
+})
+
+var _ = Describe("Last applied spec tests", func() {
+
+	It("setLastAppliedSpec should only update the time when the snapshot changes", func() {
+		rm := makeTestRolloutManager()
+		rm.Spec.NamespaceScoped = true
+
+		Expect(setLastAppliedSpec(rm, rm.Spec)).To(BeTrue())
+		Expect(rm.Status.LastAppliedSpec).To(Equal(`{"namespaceScoped":true}`))
+		Expect(rm.Status.LastAppliedTime).ToNot(BeNil())
+
+		lastAppliedTime := metav1.NewTime(rm.Status.LastAppliedTime.Add(-time.Hour))
+		rm.Status.LastAppliedTime = &lastAppliedTime
+		Expect(setLastAppliedSpec(rm, rm.Spec)).To(BeFalse())
+		Expect(rm.Status.LastAppliedTime).To(Equal(&lastAppliedTime))
+
+		rm.Spec.Env = []corev1.EnvVar{{Name: "A", Value: "1"}}
+		Expect(setLastAppliedSpec(rm, rm.Spec)).To(BeTrue())
+		Expect(rm.Status.LastAppliedSpec).To(Equal(`{"env":[{"name":"A","value":"1"}],"namespaceScoped":true}`))
+		Expect(rm.Status.LastAppliedTime.Time).To(BeTemporally(">", lastAppliedTime.Time))
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx context.Context
+			cr  rolloutsmanagerv1alpha1.RolloutManager
+			r   *RolloutManagerReconciler
+			req reconcile.Request
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			cr = *makeTestRolloutManager()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+		})
+
+		It("should set the last applied spec once it was successfully applied", func() {
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.LastAppliedSpec).To(Equal("{}"))
+			Expect(cr.Status.LastAppliedTime).ToNot(BeNil())
+
+			By("verifying that an invalid spec is not recorded")
+			cr.Spec.Ports = &rolloutsmanagerv1alpha1.RolloutManagerPortsSpec{Healthz: 9000, Metrics: 9000}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.LastAppliedSpec).To(Equal("{}"))
+
+			By("verifying that an update deferred by the restart budget is not recorded until it is applied")
+			cr.Spec.Ports = nil
+			cr.Spec.Env = []corev1.EnvVar{{Name: "A", Value: "1"}}
+			cr.Spec.RestartBudget = &rolloutsmanagerv1alpha1.RolloutManagerRestartBudgetSpec{CoalesceWindow: "1h"}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.LastAppliedSpec).To(Equal("{}"))
+
+			cr.Spec.RestartBudget = nil
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+			Expect(cr.Status.LastAppliedSpec).To(Equal(`{"env":[{"name":"A","value":"1"}]}`))
+		})
+	})
+})
+
+var _ = Describe("Reconcile failure tests", func() {
+
+	It("setReconcileFailure should count the consecutive failures, and reset them on success", func() {
+		rm := makeTestRolloutManager()
+
+		Expect(setReconcileFailure(rm, nil)).To(BeFalse())
+
+		Expect(setReconcileFailure(rm, errors.New("first"))).To(BeTrue())
+		Expect(setReconcileFailure(rm, errors.New("second"))).To(BeTrue())
+		Expect(rm.Status.FailureCount).To(Equal(int32(2)))
+		Expect(rm.Status.LastFailureTime).ToNot(BeNil())
+		Expect(rm.Status.LastError).To(Equal("second"))
+
+		Expect(setReconcileFailure(rm, nil)).To(BeTrue())
+		Expect(rm.Status.FailureCount).To(BeZero())
+		Expect(rm.Status.LastFailureTime).To(BeNil())
+		Expect(rm.Status.LastError).To(BeEmpty())
+	})
+
+	It("isReconcileFailureUpdate should only match the updates which record a failure", func() {
+		oldRM := makeTestRolloutManager()
+		oldRM.ResourceVersion = "1"
+
+		newRM := oldRM.DeepCopy()
+		newRM.ResourceVersion = "2"
+		Expect(setReconcileFailure(newRM, errors.New("failure"))).To(BeTrue())
+		Expect(isReconcileFailureUpdate(event.UpdateEvent{ObjectOld: oldRM, ObjectNew: newRM})).To(BeTrue())
+
+		By("verifying that the update of the first failed reconciliation, which also changes the phase and conditions, is matched, so that it is retried with a backoff")
+		failureWithConditionUpdate := newRM.DeepCopy()
+		failureWithConditionUpdate.Status.Phase = rolloutsmanagerv1alpha1.PhaseFailure
+		failureWithConditionUpdate.Status.Conditions = []metav1.Condition{createCondition("failure", rolloutsmanagerv1alpha1.RolloutManagerReasonErrorOccurred)}
+		Expect(isReconcileFailureUpdate(event.UpdateEvent{ObjectOld: oldRM, ObjectNew: failureWithConditionUpdate})).To(BeTrue())
+
+		By("verifying that an update which also changes the generation (that is, the spec), or the metadata, is not matched")
+		specUpdate := newRM.DeepCopy()
+		specUpdate.Spec.NamespaceScoped = true
+		specUpdate.Generation = oldRM.Generation + 1
+		Expect(isReconcileFailureUpdate(event.UpdateEvent{ObjectOld: oldRM, ObjectNew: specUpdate})).To(BeFalse())
+
+		annotationUpdate := newRM.DeepCopy()
+		annotationUpdate.Annotations = map[string]string{RestoreBackupAnnotation: "1"}
+		Expect(isReconcileFailureUpdate(event.UpdateEvent{ObjectOld: oldRM, ObjectNew: annotationUpdate})).To(BeFalse())
+
+		By("verifying that an update of the status which does not record a failure, such as a condition of a component, is not matched")
+		statusUpdate := oldRM.DeepCopy()
+		statusUpdate.ResourceVersion = "2"
+		statusUpdate.Status.Conditions = []metav1.Condition{{Type: rolloutsmanagerv1alpha1.RolloutManagerConfigReconciledConditionType, Status: metav1.ConditionFalse, Reason: rolloutsmanagerv1alpha1.RolloutManagerReasonErrorOccurred}}
+		Expect(isReconcileFailureUpdate(event.UpdateEvent{ObjectOld: oldRM, ObjectNew: statusUpdate})).To(BeFalse())
+
+		By("verifying that an update which does not change the failures is not matched")
+		Expect(isReconcileFailureUpdate(event.UpdateEvent{ObjectOld: oldRM, ObjectNew: oldRM.DeepCopy()})).To(BeFalse())
+	})
+
+	Context("when reconciling a RolloutManager", func() {
+		var (
+			ctx      context.Context
+			cr       rolloutsmanagerv1alpha1.RolloutManager
+			r        *RolloutManagerReconciler
+			req      reconcile.Request
+			injector *stepFaultInjector
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			cr = *makeTestRolloutManager()
+			r = makeTestReconciler(&cr)
+			Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+			injector = &stepFaultInjector{faults: map[reconcileStep][]error{}}
+			r.faultInjector = injector
+
+			os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+			DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+			req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}
+		})
+
+		It("should record the consecutive failed reconciliations in the status, until a reconciliation succeeds", func() {
+			injector.faults[reconcileStepDeployment] = []error{errors.New("first failure"), errors.New("second failure")}
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).To(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			Expect(cr.Status.FailureCount).To(Equal(int32(1)))
+			Expect(cr.Status.LastError).To(Equal("first failure"))
+			Expect(cr.Status.LastFailureTime).ToNot(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			Expect(cr.Status.FailureCount).To(Equal(int32(2)))
+			Expect(cr.Status.LastError).To(Equal("second failure"))
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			Expect(cr.Status.FailureCount).To(BeZero())
+			Expect(cr.Status.LastError).To(BeEmpty())
+			Expect(cr.Status.LastFailureTime).To(BeNil())
+		})
+
+		It("should not count an invalid RolloutManager as a failed reconciliation", func() {
+			cr.Spec.Ports = &rolloutsmanagerv1alpha1.RolloutManagerPortsSpec{Healthz: 9000, Metrics: 9000}
+			Expect(r.Client.Update(ctx, &cr)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, req.NamespacedName, &cr)).To(Succeed())
+			Expect(cr.Status.Phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(cr.Status.FailureCount).To(BeZero())
+		})
+	})
 })
@@ -0,0 +1,74 @@
+package rollouts
+
+import (
+	"context"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// StrictNamespaceScopedEnvName is the environment variable which enables the strict namespace-scoped mode of the operator, when set to 'true' (see RolloutManagerReconciler.StrictNamespaceScoped). It requires NAMESPACE_SCOPED_ARGO_ROLLOUTS to be 'true'.
+	StrictNamespaceScopedEnvName = "STRICT_NAMESPACE_SCOPED"
+
+	// WatchNamespacesEnvName is the comma-separated list of the namespaces whose RolloutManagers are reconciled in the strict namespace-scoped mode. If it is not set, only the namespace of the operator is watched.
+	WatchNamespacesEnvName = "WATCH_NAMESPACES"
+)
+
+// clusterAPIDisabled returns true if the operator must not make any request to cluster-scoped APIs (Namespaces, ClusterRoles, ClusterRoleBindings, CustomResourceDefinitions, FlowSchemas and the cluster-wide Proxy of OpenShift), nor list resources across all namespaces, so that it only requires namespace-level permissions. See StrictNamespaceScoped.
+func (r *RolloutManagerReconciler) clusterAPIDisabled() bool {
+	return r.NamespaceScopedArgoRolloutsController && r.StrictNamespaceScoped
+}
+
+// namespaceListOptions returns the options which restrict a List of namespaced resources, otherwise across all namespaces, to the given namespace when the cluster API is disabled.
+func (r *RolloutManagerReconciler) namespaceListOptions(namespace string) []client.ListOption {
+	if r.clusterAPIDisabled() {
+		return []client.ListOption{client.InNamespace(namespace)}
+	}
+	return nil
+}
+
+// isAggregateClusterRolesManaged returns true if the aggregate ClusterRoles are reconciled for the RolloutManager: they are not if they are skipped, or if the cluster API is disabled.
+func (r *RolloutManagerReconciler) isAggregateClusterRolesManaged(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return !r.clusterAPIDisabled() && !isResourceSkipped(cr, rolloutsmanagerv1alpha1.SkippableResourceAggregateClusterRoles)
+}
+
+// isAPIServedInNamespace returns true if the resources of the list are served by the API server, by listing them in the namespace. When the cluster API is disabled, it is used in place of checking that their CustomResourceDefinition exists.
+func (r *RolloutManagerReconciler) isAPIServedInNamespace(ctx context.Context, list client.ObjectList, namespace string) (bool, error) {
+	if err := r.Client.List(ctx, list, client.InNamespace(namespace), client.Limit(1)); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// getClusterAPIRequirements returns the features configured by the RolloutManager which require cluster-scoped resources, if the cluster API is disabled: these features are not reconciled.
+func (r *RolloutManagerReconciler) getClusterAPIRequirements(cr rolloutsmanagerv1alpha1.RolloutManager) []string {
+
+	res := []string{}
+	if !r.clusterAPIDisabled() {
+		return res
+	}
+
+	if cr.Spec.FlowControl != nil {
+		res = append(res, ".spec.flowControl (requires a FlowSchema)")
+	}
+
+	return res
+}
+
+// createClusterAPIRequiredCondition returns the ClusterAPIRequired condition for the given features.
+func createClusterAPIRequiredCondition(clusterAPIRequirements []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    rolloutsmanagerv1alpha1.RolloutManagerClusterAPIRequiredConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonClusterAPIRequired,
+		Message: ClusterAPIRequiredMessage + strings.Join(clusterAPIRequirements, ", "),
+	}
+}
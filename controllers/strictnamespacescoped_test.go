@@ -0,0 +1,195 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	flowcontrolv1beta3 "k8s.io/api/flowcontrol/v1beta3"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// namespacedOnlyClient records the requests which require cluster-level permissions: requests for cluster-scoped resources, and lists across all namespaces. The requests are still passed to the wrapped client.
+type namespacedOnlyClient struct {
+	client.Client
+
+	clusterRequests []string
+}
+
+// clusterScopedKinds are the kinds of the cluster-scoped resources that the operator may request
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                true,
+	"ClusterRole":              true,
+	"ClusterRoleBinding":       true,
+	"CustomResourceDefinition": true,
+	"FlowSchema":               true,
+	"Proxy":                    true,
+}
+
+func (c *namespacedOnlyClient) recordClusterScoped(verb string, obj client.Object) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	Expect(err).ToNot(HaveOccurred())
+	if clusterScopedKinds[gvk.Kind] {
+		c.clusterRequests = append(c.clusterRequests, fmt.Sprintf("%s %s %s", verb, gvk.Kind, obj.GetName()))
+	}
+}
+
+func (c *namespacedOnlyClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	obj.SetName(key.Name)
+	c.recordClusterScoped("get", obj)
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *namespacedOnlyClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Namespace == "" {
+		gvk, err := apiutil.GVKForObject(list, c.Scheme())
+		Expect(err).ToNot(HaveOccurred())
+		c.clusterRequests = append(c.clusterRequests, "list "+gvk.Kind)
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *namespacedOnlyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.recordClusterScoped("create", obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *namespacedOnlyClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.recordClusterScoped("update", obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *namespacedOnlyClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.recordClusterScoped("delete", obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+var _ = Describe("Strict namespace-scoped mode tests", func() {
+
+	var (
+		ctx        context.Context
+		cr         *rolloutsmanagerv1alpha1.RolloutManager
+		r          *RolloutManagerReconciler
+		req        reconcile.Request
+		fakeClient client.Client
+		recorder   *namespacedOnlyClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = makeTestRolloutManager()
+		cr.Spec.NamespaceScoped = true
+
+		r = makeTestReconciler(cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+		r.NamespaceScopedArgoRolloutsController = true
+		r.StrictNamespaceScoped = true
+
+		fakeClient = r.Client
+		recorder = &namespacedOnlyClient{Client: fakeClient}
+		r.Client = recorder
+
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)}
+	})
+
+	aggregateClusterRoleNames := []string{
+		DefaultArgoRolloutsResourceName + "-aggregate-to-admin",
+		DefaultArgoRolloutsResourceName + "-aggregate-to-edit",
+		DefaultArgoRolloutsResourceName + "-aggregate-to-view",
+	}
+
+	It("should only be enabled for a namespace-scoped operator", func() {
+		Expect(r.clusterAPIDisabled()).To(BeTrue())
+
+		r.NamespaceScopedArgoRolloutsController = false
+		Expect(r.clusterAPIDisabled()).To(BeFalse())
+
+		r.NamespaceScopedArgoRolloutsController = true
+		r.StrictNamespaceScoped = false
+		Expect(r.clusterAPIDisabled()).To(BeFalse())
+	})
+
+	It("should reconcile the Rollouts controller without making any request which requires cluster-level permissions", func() {
+		cr.Spec.RolloutUserRole = &rolloutsmanagerv1alpha1.RolloutManagerRolloutUserRoleSpec{Enabled: true}
+		Expect(fakeClient.Update(ctx, cr)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.clusterRequests).To(BeEmpty())
+
+		Expect(fetchObject(ctx, fakeClient, cr.Namespace, DefaultArgoRolloutsResourceName, &rbacv1.Role{})).To(Succeed())
+		Expect(fetchObject(ctx, fakeClient, cr.Namespace, DefaultArgoRolloutsResourceName, &rbacv1.RoleBinding{})).To(Succeed())
+		Expect(fetchObject(ctx, fakeClient, cr.Namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})).To(Succeed())
+		Expect(fetchObject(ctx, fakeClient, cr.Namespace, DefaultRolloutUserRoleName, &rbacv1.Role{})).To(Succeed())
+
+		By("verifying that the aggregate ClusterRoles were not created")
+		for _, name := range aggregateClusterRoleNames {
+			Expect(apierrors.IsNotFound(fetchObject(ctx, fakeClient, "", name, &rbacv1.ClusterRole{}))).To(BeTrue(), name)
+		}
+
+		Expect(fakeClient.Get(ctx, req.NamespacedName, cr)).To(Succeed())
+		Expect(meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerClusterAPIRequiredConditionType)).To(BeNil())
+	})
+
+	It("should not make any request which requires cluster-level permissions once the RolloutManager is deleted", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeClient.Delete(ctx, cr)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.clusterRequests).To(BeEmpty())
+	})
+
+	It("should report the features which require cluster-scoped resources in the ClusterAPIRequired condition, rather than reconciling them", func() {
+		cr.Spec.FlowControl = &rolloutsmanagerv1alpha1.RolloutManagerFlowControlSpec{PriorityLevel: "workload-high"}
+		Expect(fakeClient.Update(ctx, cr)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.clusterRequests).To(BeEmpty())
+
+		Expect(apierrors.IsNotFound(fetchObject(ctx, fakeClient, "", getFlowSchemaName(cr.Namespace), &flowcontrolv1beta3.FlowSchema{}))).To(BeTrue())
+
+		Expect(fakeClient.Get(ctx, req.NamespacedName, cr)).To(Succeed())
+		condition := meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerClusterAPIRequiredConditionType)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonClusterAPIRequired))
+		Expect(condition.Message).To(Equal(ClusterAPIRequiredMessage + ".spec.flowControl (requires a FlowSchema)"))
+
+		By("removing the flow control, and verifying that the condition is removed")
+		cr.Spec.FlowControl = nil
+		Expect(fakeClient.Update(ctx, cr)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeClient.Get(ctx, req.NamespacedName, cr)).To(Succeed())
+		Expect(meta.FindStatusCondition(cr.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerClusterAPIRequiredConditionType)).To(BeNil())
+	})
+
+	It("should still manage the aggregate ClusterRoles when the strict mode is disabled", func() {
+		r.StrictNamespaceScoped = false
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.clusterRequests).ToNot(BeEmpty())
+
+		for _, name := range aggregateClusterRoleNames {
+			Expect(fetchObject(ctx, fakeClient, "", name, &rbacv1.ClusterRole{})).To(Succeed(), name)
+		}
+	})
+})
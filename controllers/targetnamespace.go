@@ -0,0 +1,454 @@
+package rollouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// TargetNamespaceOwnerLabel is set on the target namespace of a RolloutManager (see .spec.targetNamespace), and on the resources that the operator creates in it, and contains the namespace of that RolloutManager. Along with the RolloutManagerLabel (its name), it identifies the RolloutManager.
+	// Namespaces are cluster-scoped, so they can't be owned by the RolloutManager: these labels are used to clean them up instead.
+	TargetNamespaceOwnerLabel = "argo-rollouts.argoproj.io/target-namespace-owner"
+
+	// targetNamespaceLabelsAnnotation is set on the target namespace, and contains the (comma-separated) keys of the labels of .spec.targetNamespace.labels which were set on it, so that they are removed once they are removed from the spec.
+	targetNamespaceLabelsAnnotation = "argo-rollouts.argoproj.io/target-namespace-labels"
+)
+
+// reservedTargetNamespaceLabelKeys are the labels of the target namespace which are set by the operator or the API server, which may not be overridden via .spec.targetNamespace.labels.
+var reservedTargetNamespaceLabelKeys = []string{
+	TargetNamespaceOwnerLabel,
+	RolloutManagerLabel,
+	corev1.LabelMetadataName,
+}
+
+func isTargetNamespaceEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.TargetNamespace != nil
+}
+
+// targetNamespaceOwnerLabels returns the labels which identify the target namespace (and the resources in it) of the given RolloutManager.
+func targetNamespaceOwnerLabels(rolloutManagerNamespace string, rolloutManagerName string) map[string]string {
+	return map[string]string{
+		TargetNamespaceOwnerLabel: rolloutManagerNamespace,
+		RolloutManagerLabel:       rolloutManagerName,
+	}
+}
+
+// isTargetNamespaceResourceOf returns true if the object was created by the operator in (or as) the target namespace of the RolloutManager.
+func isTargetNamespaceResourceOf(obj client.Object, cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	labels := obj.GetLabels()
+	return labels[TargetNamespaceOwnerLabel] == cr.Namespace && labels[RolloutManagerLabel] == cr.Name
+}
+
+// validateTargetNamespace verifies that the target namespace of the RolloutManager can be reconciled: it is only supported by cluster-scoped RolloutManagers, since the Rollouts controller of a namespace-scoped RolloutManager only watches its own namespace.
+func validateTargetNamespace(cr rolloutsmanagerv1alpha1.RolloutManager, namespaceScopedArgoRolloutsController bool) error {
+
+	if cr.Spec.NamespaceScoped || namespaceScopedArgoRolloutsController {
+		return errors.New("targetNamespace is only supported by a cluster-scoped RolloutManager")
+	}
+
+	name := cr.Spec.TargetNamespace.Name
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("targetNamespace '%s' is not a valid namespace name: %s", name, strings.Join(errs, "; "))
+	}
+	if name == cr.Namespace {
+		return fmt.Errorf("targetNamespace '%s' must differ from the namespace of the RolloutManager", name)
+	}
+
+	for k, v := range cr.Spec.TargetNamespace.Labels {
+		for _, reserved := range reservedTargetNamespaceLabelKeys {
+			if k == reserved {
+				return fmt.Errorf("the label '%s' of targetNamespace is set by the operator, and may not be overridden", k)
+			}
+		}
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("the key of the label '%s' of targetNamespace is invalid: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("the value '%s' of the label '%s' of targetNamespace is invalid: %s", v, k, strings.Join(errs, "; "))
+		}
+	}
+
+	return nil
+}
+
+// reconcileTargetNamespace reconciles a RolloutManager which sets .spec.targetNamespace, instead of its resources: the target namespace (with its labels and ResourceQuota) is created, along with a RolloutManager of the same name and spec in it, which deploys the Rollouts controller there. The phase and condition of that RolloutManager are returned, to be reported on this one.
+// The resources which were previously created for the RolloutManager in its own namespace, and the target namespaces which it no longer targets, are removed.
+func (r *RolloutManagerReconciler) reconcileTargetNamespace(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (reconcileStatusResult, error) {
+
+	log.Info("validating RolloutManager's target namespace")
+	if err := validateTargetNamespace(cr, r.NamespaceScopedArgoRolloutsController); err != nil {
+		return *invalidRolloutManager(err, rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidTargetNamespace), nil
+	}
+
+	targetNamespace := cr.Spec.TargetNamespace.Name
+
+	log.Info("removing stale target namespaces")
+	if err := r.removeTargetNamespaces(ctx, cr.Namespace, cr.Name, targetNamespace); err != nil {
+		log.Error(err, "failed to remove stale target namespaces.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling target namespace")
+	namespace, owned, err := r.reconcileTargetNamespaceObject(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to reconcile target namespace.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+	if !owned {
+		return *invalidRolloutManager(fmt.Errorf("targetNamespace '%s' already exists, and was not created by the operator for this RolloutManager", targetNamespace), rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidTargetNamespace), nil
+	}
+	if namespace.DeletionTimestamp != nil {
+		// The namespace is recreated once it is gone: the Pending phase is requeued shortly (see requeueIntervalForPhase)
+		phasePending := rolloutsmanagerv1alpha1.PhasePending
+		return reconcileStatusResult{
+			condition:         createCondition(fmt.Sprintf("targetNamespace '%s' is being deleted, and is recreated once it is gone", targetNamespace), rolloutsmanagerv1alpha1.RolloutManagerReasonNamespaceTerminating),
+			rolloutController: &phasePending,
+			phase:             &phasePending,
+		}, nil
+	}
+
+	log.Info("reconciling target namespace ResourceQuota")
+	if err := r.reconcileTargetNamespaceQuota(ctx, cr); err != nil {
+		log.Error(err, "failed to reconcile target namespace ResourceQuota.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	log.Info("reconciling RolloutManager in target namespace")
+	targetRolloutManager, err := r.reconcileTargetNamespaceRolloutManager(ctx, cr)
+	if err != nil {
+		log.Error(err, "failed to reconcile RolloutManager in target namespace.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	// The Rollouts controller now runs in the target namespace, so the resources which were created in the namespace of the RolloutManager are removed
+	log.Info("removing resources of the RolloutManager in its own namespace")
+	if err := r.removeRolloutManagerResources(ctx, cr); err != nil {
+		log.Error(err, "failed to remove resources of the RolloutManager in its own namespace.")
+		return wrapCondition(createCondition(err.Error())), err
+	}
+
+	return targetNamespaceStatus(*targetRolloutManager), nil
+}
+
+// reconcileTargetNamespaceObject creates the target namespace of the RolloutManager, or updates its labels. It returns false if the namespace already exists, but was not created by the operator for the RolloutManager: such a namespace is left untouched.
+func (r *RolloutManagerReconciler) reconcileTargetNamespaceObject(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (*corev1.Namespace, bool, error) {
+
+	name := cr.Spec.TargetNamespace.Name
+
+	namespace := &corev1.Namespace{}
+	if err := fetchObject(ctx, r.Client, "", name, namespace); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, false, fmt.Errorf("failed to get the target namespace %s: %w", name, err)
+		}
+
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		setTargetNamespaceLabels(namespace, cr)
+
+		log.Info(fmt.Sprintf("Creating target namespace %s", name))
+		if err := r.Client.Create(ctx, namespace); err != nil {
+			return nil, false, fmt.Errorf("failed to create the target namespace %s: %w", name, err)
+		}
+		r.recordTargetNamespaceEvent(&cr, namespace, EventReasonResourceCreated, fmt.Sprintf("Created target namespace %s, for RolloutManager %s in namespace %s", name, cr.Name, cr.Namespace))
+		return namespace, true, nil
+	}
+
+	if !isTargetNamespaceResourceOf(namespace, cr) {
+		return namespace, false, nil
+	}
+
+	if namespace.DeletionTimestamp != nil || !setTargetNamespaceLabels(namespace, cr) {
+		return namespace, true, nil
+	}
+
+	log.Info(fmt.Sprintf("Updating labels of target namespace %s", name))
+	if err := r.Client.Update(ctx, namespace); err != nil {
+		return nil, false, fmt.Errorf("failed to update the target namespace %s: %w", name, err)
+	}
+	r.recordTargetNamespaceEvent(&cr, namespace, EventReasonResourceUpdated, fmt.Sprintf("Updated labels of target namespace %s, for RolloutManager %s in namespace %s", name, cr.Name, cr.Namespace))
+
+	return namespace, true, nil
+}
+
+// setTargetNamespaceLabels sets the labels of .spec.targetNamespace.labels, and the labels which identify the RolloutManager, on the target namespace, and removes the labels which were previously set from .spec.targetNamespace.labels, but no longer are (see targetNamespaceLabelsAnnotation). Other labels (for example, set by other controllers) are kept. It returns true if the namespace changed.
+func setTargetNamespaceLabels(namespace *corev1.Namespace, cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+
+	labels := map[string]string{}
+	for k, v := range namespace.Labels {
+		labels[k] = v
+	}
+
+	expectedLabels := cr.Spec.TargetNamespace.Labels
+
+	for _, key := range splitList(namespace.Annotations[targetNamespaceLabelsAnnotation]) {
+		if _, exists := expectedLabels[key]; key != "" && !exists {
+			delete(labels, key)
+		}
+	}
+
+	keys := []string{}
+	for k, v := range expectedLabels {
+		labels[k] = v
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for k, v := range targetNamespaceOwnerLabels(cr.Namespace, cr.Name) {
+		labels[k] = v
+	}
+
+	changed := false
+	if !reflect.DeepEqual(labels, namespace.Labels) {
+		namespace.Labels = labels
+		changed = true
+	}
+
+	if appliedKeys := strings.Join(keys, ","); namespace.Annotations[targetNamespaceLabelsAnnotation] != appliedKeys {
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+		namespace.Annotations[targetNamespaceLabelsAnnotation] = appliedKeys
+		changed = true
+	}
+
+	return changed
+}
+
+// reconcileTargetNamespaceQuota creates the ResourceQuota of the target namespace if .spec.targetNamespace.quota is set, or updates its hard limits, and deletes it otherwise. A ResourceQuota of the same name which was not created by the operator is left untouched.
+func (r *RolloutManagerReconciler) reconcileTargetNamespaceQuota(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	namespace := cr.Spec.TargetNamespace.Name
+	expectedHard := cr.Spec.TargetNamespace.Quota
+
+	quota := &corev1.ResourceQuota{}
+	if err := fetchObject(ctx, r.Client, namespace, DefaultArgoRolloutsResourceName, quota); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the ResourceQuota %s of the target namespace %s: %w", DefaultArgoRolloutsResourceName, namespace, err)
+		}
+
+		if len(expectedHard) == 0 {
+			return nil
+		}
+
+		quota = &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DefaultArgoRolloutsResourceName,
+				Namespace: namespace,
+				Labels:    targetNamespaceOwnerLabels(cr.Namespace, cr.Name),
+			},
+			Spec: corev1.ResourceQuotaSpec{Hard: expectedHard.DeepCopy()},
+		}
+
+		log.Info(fmt.Sprintf("Creating ResourceQuota %s in target namespace %s", quota.Name, namespace))
+		if err := r.Client.Create(ctx, quota); err != nil {
+			return fmt.Errorf("failed to create the ResourceQuota %s of the target namespace %s: %w", quota.Name, namespace, err)
+		}
+		r.recordTargetNamespaceEvent(&cr, quota, EventReasonResourceCreated, fmt.Sprintf("Created ResourceQuota %s in namespace %s, for RolloutManager %s in namespace %s", quota.Name, namespace, cr.Name, cr.Namespace))
+		return nil
+	}
+
+	if !isTargetNamespaceResourceOf(quota, cr) {
+		log.Info(fmt.Sprintf("ResourceQuota %s in target namespace %s was not created by the operator, and is left unchanged", quota.Name, namespace))
+		return nil
+	}
+
+	if len(expectedHard) == 0 {
+		log.Info(fmt.Sprintf("Deleting ResourceQuota %s in target namespace %s", quota.Name, namespace))
+		if err := r.Client.Delete(ctx, quota); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete the ResourceQuota %s of the target namespace %s: %w", quota.Name, namespace, err)
+		}
+		r.recordTargetNamespaceEvent(&cr, quota, EventReasonResourceDeleted, fmt.Sprintf("Deleted ResourceQuota %s in namespace %s, for RolloutManager %s in namespace %s", quota.Name, namespace, cr.Name, cr.Namespace))
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(quota.Spec.Hard, expectedHard) {
+		return nil
+	}
+
+	quota.Spec.Hard = expectedHard.DeepCopy()
+	log.Info(fmt.Sprintf("Updating ResourceQuota %s in target namespace %s", quota.Name, namespace))
+	if err := r.Client.Update(ctx, quota); err != nil {
+		return fmt.Errorf("failed to update the ResourceQuota %s of the target namespace %s: %w", quota.Name, namespace, err)
+	}
+	r.recordTargetNamespaceEvent(&cr, quota, EventReasonResourceUpdated, fmt.Sprintf("Updated ResourceQuota %s in namespace %s, for RolloutManager %s in namespace %s", quota.Name, namespace, cr.Name, cr.Namespace))
+
+	return nil
+}
+
+// reconcileTargetNamespaceRolloutManager creates the RolloutManager in the target namespace, with the name and spec (except targetNamespace) of the given RolloutManager, or updates its spec.
+func (r *RolloutManagerReconciler) reconcileTargetNamespaceRolloutManager(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (*rolloutsmanagerv1alpha1.RolloutManager, error) {
+
+	namespace := cr.Spec.TargetNamespace.Name
+
+	expectedSpec := cr.Spec.DeepCopy()
+	expectedSpec.TargetNamespace = nil
+
+	targetRolloutManager := &rolloutsmanagerv1alpha1.RolloutManager{}
+	if err := fetchObject(ctx, r.Client, namespace, cr.Name, targetRolloutManager); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get the RolloutManager %s in the target namespace %s: %w", cr.Name, namespace, err)
+		}
+
+		targetRolloutManager = &rolloutsmanagerv1alpha1.RolloutManager{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cr.Name,
+				Namespace: namespace,
+				Labels:    targetNamespaceOwnerLabels(cr.Namespace, cr.Name),
+			},
+			Spec: *expectedSpec,
+		}
+
+		log.Info(fmt.Sprintf("Creating RolloutManager %s in target namespace %s", cr.Name, namespace))
+		if err := r.Client.Create(ctx, targetRolloutManager); err != nil {
+			return nil, fmt.Errorf("failed to create the RolloutManager %s in the target namespace %s: %w", cr.Name, namespace, err)
+		}
+		r.recordTargetNamespaceEvent(&cr, targetRolloutManager, EventReasonResourceCreated, fmt.Sprintf("Created RolloutManager %s in namespace %s, for RolloutManager %s in namespace %s", cr.Name, namespace, cr.Name, cr.Namespace))
+		return targetRolloutManager, nil
+	}
+
+	if isTargetNamespaceResourceOf(targetRolloutManager, cr) && reflect.DeepEqual(targetRolloutManager.Spec, *expectedSpec) {
+		return targetRolloutManager, nil
+	}
+
+	// The target namespace is managed by the operator, so the spec of its RolloutManager is always that of the given RolloutManager
+	if targetRolloutManager.Labels == nil {
+		targetRolloutManager.Labels = map[string]string{}
+	}
+	for k, v := range targetNamespaceOwnerLabels(cr.Namespace, cr.Name) {
+		targetRolloutManager.Labels[k] = v
+	}
+	targetRolloutManager.Spec = *expectedSpec
+
+	log.Info(fmt.Sprintf("Updating RolloutManager %s in target namespace %s", cr.Name, namespace))
+	if err := r.Client.Update(ctx, targetRolloutManager); err != nil {
+		return nil, fmt.Errorf("failed to update the RolloutManager %s in the target namespace %s: %w", cr.Name, namespace, err)
+	}
+	r.recordTargetNamespaceEvent(&cr, targetRolloutManager, EventReasonResourceUpdated, fmt.Sprintf("Updated RolloutManager %s in namespace %s, for RolloutManager %s in namespace %s", cr.Name, namespace, cr.Name, cr.Namespace))
+
+	return targetRolloutManager, nil
+}
+
+// targetNamespaceStatus returns the reconcileStatusResult of a RolloutManager which sets .spec.targetNamespace, from the status of the RolloutManager in its target namespace: its phase and condition, with the message prefixed by the name of that RolloutManager.
+func targetNamespaceStatus(targetRolloutManager rolloutsmanagerv1alpha1.RolloutManager) reconcileStatusResult {
+
+	condition := meta.FindStatusCondition(targetRolloutManager.Status.Conditions, rolloutsmanagerv1alpha1.RolloutManagerConditionType)
+
+	// Until the RolloutManager in the target namespace has reconciled its current spec, its status is out of date
+	if targetRolloutManager.Status.Phase == "" || condition == nil || condition.ObservedGeneration != targetRolloutManager.Generation {
+		phasePending := rolloutsmanagerv1alpha1.PhasePending
+		return reconcileStatusResult{
+			condition:         createCondition(fmt.Sprintf("waiting for RolloutManager %s in namespace %s to be reconciled", targetRolloutManager.Name, targetRolloutManager.Namespace), rolloutsmanagerv1alpha1.RolloutManagerReasonErrorOccurred),
+			rolloutController: &phasePending,
+			phase:             &phasePending,
+		}
+	}
+
+	phase := targetRolloutManager.Status.Phase
+	rolloutController := targetRolloutManager.Status.RolloutController
+
+	rr := reconcileStatusResult{
+		phase:             &phase,
+		rolloutController: &rolloutController,
+		relatedImages:     targetRolloutManager.Status.RelatedImages,
+	}
+
+	if condition.Reason == rolloutsmanagerv1alpha1.RolloutManagerReasonSuccess {
+		rr.condition = createCondition("")
+	} else {
+		rr.condition = createCondition(fmt.Sprintf("RolloutManager %s in namespace %s: %s", targetRolloutManager.Name, targetRolloutManager.Namespace, condition.Message), condition.Reason)
+	}
+
+	return rr
+}
+
+// removeRolloutManagerResources deletes the resources which were created by the operator for the RolloutManager in its own namespace (and the rollout-user Roles and FlowSchema of the RolloutManager), once it runs the Rollouts controller in its target namespace instead. Resources which are not owned by the RolloutManager are left untouched.
+func (r *RolloutManagerReconciler) removeRolloutManagerResources(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	for _, list := range adoptableResourceLists() {
+
+		if err := r.Client.List(ctx, list, client.InNamespace(cr.Namespace), client.MatchingLabels{"app.kubernetes.io/part-of": DefaultArgoRolloutsResourceName}); err != nil {
+			return fmt.Errorf("failed to list %T: %w", list, err)
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+
+			obj, ok := item.(client.Object)
+			if !ok || !metav1.IsControlledBy(obj, &cr) || obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+
+			log.Info("Deleting resource of the RolloutManager, as it runs the Rollouts controller in its target namespace", "kind", fmt.Sprintf("%T", obj), "namespace", obj.GetNamespace(), "name", obj.GetName())
+			if err := r.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete %s: %w", obj.GetName(), err)
+			}
+		}
+	}
+
+	if err := r.removeRolloutUserRoles(ctx, cr.Namespace); err != nil {
+		return err
+	}
+
+	return r.removeRolloutsFlowSchema(ctx, cr.Namespace)
+}
+
+// removeTargetNamespaces deletes the target namespaces which were created by the operator for the given RolloutManager (see reconcileTargetNamespace), except the namespace named by keep (if any): along with everything in them, including their RolloutManager and its Rollouts controller.
+func (r *RolloutManagerReconciler) removeTargetNamespaces(ctx context.Context, rolloutManagerNamespace string, rolloutManagerName string, keep string) error {
+
+	if r.clusterAPIDisabled() {
+		// No target namespace is created when the cluster API is disabled
+		return nil
+	}
+
+	namespaceList := &metav1.PartialObjectMetadataList{}
+	namespaceList.SetGroupVersionKind(namespaceGVK.GroupVersion().WithKind("NamespaceList"))
+	if err := r.Client.List(ctx, namespaceList, client.MatchingLabels(targetNamespaceOwnerLabels(rolloutManagerNamespace, rolloutManagerName))); err != nil {
+		return fmt.Errorf("failed to list target namespaces: %w", err)
+	}
+
+	for _, namespace := range namespaceList.Items {
+
+		if namespace.Name == keep || namespace.DeletionTimestamp != nil {
+			continue
+		}
+
+		log.Info(fmt.Sprintf("Deleting target namespace %s of RolloutManager %s in namespace %s", namespace.Name, rolloutManagerName, rolloutManagerNamespace))
+		if err := r.Client.Delete(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace.Name}}); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to delete the target namespace %s: %w", namespace.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// enqueueTargetNamespaceOwner returns the RolloutManager which created the given RolloutManager in its target namespace (if any), so that it reports the phase and condition of the latter whenever they change.
+func enqueueTargetNamespaceOwner(_ context.Context, obj client.Object) []reconcile.Request {
+
+	labels := obj.GetLabels()
+	if labels[TargetNamespaceOwnerLabel] == "" || labels[RolloutManagerLabel] == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: labels[TargetNamespaceOwnerLabel], Name: labels[RolloutManagerLabel]}}}
+}
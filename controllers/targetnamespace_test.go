@@ -0,0 +1,270 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("target namespace tests", func() {
+
+	const targetNamespace = "argo-rollouts-controller"
+
+	var (
+		ctx      context.Context
+		cr       v1alpha1.RolloutManager
+		r        *RolloutManagerReconciler
+		recorder *namespacedEventRecorder
+		targetRM types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		cr.Spec.TargetNamespace = &v1alpha1.RolloutManagerTargetNamespaceSpec{
+			Name:   targetNamespace,
+			Labels: map[string]string{"team": "platform"},
+			Quota:  corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+		}
+
+		r = makeTestReconciler(&cr)
+		recorder = &namespacedEventRecorder{}
+		r.Recorder = recorder
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		// Only the target namespace may run a cluster-scoped Rollouts controller
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, targetNamespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		targetRM = types.NamespacedName{Namespace: targetNamespace, Name: cr.Name}
+	})
+
+	reconcileRolloutManager := func(key types.NamespacedName) v1alpha1.RolloutManager {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+
+		rm := v1alpha1.RolloutManager{}
+		ExpectWithOffset(1, r.Client.Get(ctx, key, &rm)).To(Succeed())
+		return rm
+	}
+
+	updateSpec := func(update func(*v1alpha1.RolloutManager)) {
+		ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		update(&cr)
+		ExpectWithOffset(1, r.Client.Update(ctx, &cr)).To(Succeed())
+	}
+
+	expectDeploymentExists := func(namespace string, exists bool) {
+		err := fetchObject(ctx, r.Client, namespace, DefaultArgoRolloutsResourceName, &appsv1.Deployment{})
+		if exists {
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		} else {
+			ExpectWithOffset(1, apierrors.IsNotFound(err)).To(BeTrue())
+		}
+	}
+
+	It("should create the target namespace with its labels and ResourceQuota, and a RolloutManager in it which deploys the Rollouts controller, and report its phase", func() {
+
+		rm := reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+
+		By("verifying that the target namespace is labelled with the RolloutManager")
+		namespace := &corev1.Namespace{}
+		Expect(fetchObject(ctx, r.Client, "", targetNamespace, namespace)).To(Succeed())
+		Expect(namespace.Labels).To(HaveKeyWithValue("team", "platform"))
+		Expect(namespace.Labels).To(HaveKeyWithValue(TargetNamespaceOwnerLabel, cr.Namespace))
+		Expect(namespace.Labels).To(HaveKeyWithValue(RolloutManagerLabel, cr.Name))
+		Expect(recorder.events).To(ContainElement(cr.Namespace + "/*v1alpha1.RolloutManager " + EventReasonResourceCreated))
+
+		By("verifying that the ResourceQuota is created")
+		quota := &corev1.ResourceQuota{}
+		Expect(fetchObject(ctx, r.Client, targetNamespace, DefaultArgoRolloutsResourceName, quota)).To(Succeed())
+		Expect(quota.Spec.Hard.Pods().String()).To(Equal("10"))
+
+		By("verifying that the RolloutManager in the target namespace has the same spec, without the target namespace")
+		target := &v1alpha1.RolloutManager{}
+		Expect(r.Client.Get(ctx, targetRM, target)).To(Succeed())
+		Expect(target.Spec.TargetNamespace).To(BeNil())
+		Expect(target.Labels).To(HaveKeyWithValue(TargetNamespaceOwnerLabel, cr.Namespace))
+
+		By("verifying that the RolloutManager waits for the RolloutManager in the target namespace")
+		Expect(rm.Status.Phase).To(Equal(v1alpha1.PhasePending))
+		Expect(rm.Status.Conditions[0].Message).To(ContainSubstring("waiting for RolloutManager " + cr.Name + " in namespace " + targetNamespace))
+
+		By("reconciling the RolloutManager in the target namespace, which deploys the Rollouts controller there")
+		reconciledTarget := reconcileRolloutManager(targetRM)
+		Expect(reconciledTarget.Status.Conditions[0].Reason).To(Equal(v1alpha1.RolloutManagerReasonSuccess))
+		expectDeploymentExists(targetNamespace, true)
+		expectDeploymentExists(cr.Namespace, false)
+
+		By("verifying that the RolloutManager reports the phase of the RolloutManager in the target namespace")
+		Expect(enqueueTargetNamespaceOwner(ctx, &reconciledTarget)).To(ConsistOf(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)}))
+		rm = reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+		Expect(rm.Status.Phase).To(Equal(reconciledTarget.Status.Phase))
+		Expect(rm.Status.Conditions[0].Reason).To(Equal(v1alpha1.RolloutManagerReasonSuccess))
+	})
+
+	It("should update the labels and ResourceQuota of the target namespace, and keep the labels which it did not set", func() {
+
+		reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+
+		namespace := &corev1.Namespace{}
+		Expect(fetchObject(ctx, r.Client, "", targetNamespace, namespace)).To(Succeed())
+		namespace.Labels["set-by-another-controller"] = "true"
+		Expect(r.Client.Update(ctx, namespace)).To(Succeed())
+
+		By("replacing the labels, and removing the quota")
+		updateSpec(func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.TargetNamespace.Labels = map[string]string{"environment": "production"}
+			rm.Spec.TargetNamespace.Quota = nil
+		})
+		reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+
+		Expect(fetchObject(ctx, r.Client, "", targetNamespace, namespace)).To(Succeed())
+		Expect(namespace.Labels).ToNot(HaveKey("team"))
+		Expect(namespace.Labels).To(HaveKeyWithValue("environment", "production"))
+		Expect(namespace.Labels).To(HaveKeyWithValue("set-by-another-controller", "true"))
+		Expect(namespace.Labels).To(HaveKeyWithValue(TargetNamespaceOwnerLabel, cr.Namespace))
+
+		err := fetchObject(ctx, r.Client, targetNamespace, DefaultArgoRolloutsResourceName, &corev1.ResourceQuota{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("verifying that the spec of the RolloutManager in the target namespace is updated")
+		updateSpec(func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.Version = "v1.6.0"
+		})
+		reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+
+		target := &v1alpha1.RolloutManager{}
+		Expect(r.Client.Get(ctx, targetRM, target)).To(Succeed())
+		Expect(target.Spec.Version).To(Equal("v1.6.0"))
+	})
+
+	It("should leave a namespace which was not created by the operator for the RolloutManager untouched", func() {
+
+		Expect(createNamespace(r, targetNamespace)).To(Succeed())
+		Expect(r.Client.Create(ctx, &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: targetNamespace}})).To(Succeed())
+
+		rm := reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+		Expect(rm.Status.Phase).To(Equal(v1alpha1.PhaseFailure))
+		Expect(rm.Status.Conditions[0].Reason).To(Equal(v1alpha1.RolloutManagerReasonInvalidTargetNamespace))
+		Expect(rm.Status.Conditions[0].Message).To(ContainSubstring("was not created by the operator"))
+
+		namespace := &corev1.Namespace{}
+		Expect(fetchObject(ctx, r.Client, "", targetNamespace, namespace)).To(Succeed())
+		Expect(namespace.Labels).ToNot(HaveKey(TargetNamespaceOwnerLabel))
+
+		err := r.Client.Get(ctx, targetRM, &v1alpha1.RolloutManager{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("verifying that the namespace is not deleted along with the RolloutManager")
+		Expect(r.Client.Delete(ctx, &cr)).To(Succeed())
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetchObject(ctx, r.Client, "", targetNamespace, namespace)).To(Succeed())
+	})
+
+	It("should delete the target namespace once the RolloutManager no longer targets it, and deploy the Rollouts controller in its own namespace", func() {
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, targetNamespace+","+cr.Namespace)
+
+		reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+		reconcileRolloutManager(targetRM)
+		expectDeploymentExists(targetNamespace, true)
+
+		By("removing the target namespace from the spec")
+		updateSpec(func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.TargetNamespace = nil
+		})
+		reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+
+		err := fetchObject(ctx, r.Client, "", targetNamespace, &corev1.Namespace{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("reconciling the RolloutManager, once the RolloutManager in the target namespace no longer holds the cluster-scoped lock")
+		Expect(r.Client.Delete(ctx, &v1alpha1.RolloutManager{ObjectMeta: metav1.ObjectMeta{Name: targetRM.Name, Namespace: targetRM.Namespace}})).To(Succeed())
+		rm := reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+		Expect(rm.Status.Conditions[0].Reason).To(Equal(v1alpha1.RolloutManagerReasonSuccess))
+		expectDeploymentExists(cr.Namespace, true)
+	})
+
+	It("should remove the Rollouts controller from the namespace of the RolloutManager once it targets a namespace, and let the RolloutManager in the target namespace take over the cluster-scoped lock", func() {
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, targetNamespace+","+cr.Namespace)
+
+		By("deploying the Rollouts controller in the namespace of the RolloutManager")
+		updateSpec(func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.TargetNamespace = nil
+		})
+		reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+		expectDeploymentExists(cr.Namespace, true)
+
+		lease := &coordinationv1.Lease{}
+		Expect(fetchObject(ctx, r.Client, r.clusterScopedLockNamespace(), ClusterScopedLockLeaseName, lease)).To(Succeed())
+		Expect(*lease.Spec.HolderIdentity).To(Equal(client.ObjectKeyFromObject(&cr).String()))
+
+		By("targeting a namespace")
+		updateSpec(func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.TargetNamespace = &v1alpha1.RolloutManagerTargetNamespaceSpec{Name: targetNamespace}
+		})
+		reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+		expectDeploymentExists(cr.Namespace, false)
+
+		target := reconcileRolloutManager(targetRM)
+		Expect(target.Status.Conditions[0].Reason).To(Equal(v1alpha1.RolloutManagerReasonSuccess))
+		expectDeploymentExists(targetNamespace, true)
+
+		Expect(fetchObject(ctx, r.Client, r.clusterScopedLockNamespace(), ClusterScopedLockLeaseName, lease)).To(Succeed())
+		Expect(*lease.Spec.HolderIdentity).To(Equal(targetRM.String()))
+	})
+
+	It("should delete the target namespace when the RolloutManager is deleted", func() {
+
+		reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+		Expect(fetchObject(ctx, r.Client, "", targetNamespace, &corev1.Namespace{})).To(Succeed())
+
+		Expect(r.Client.Delete(ctx, &cr)).To(Succeed())
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fetchObject(ctx, r.Client, "", targetNamespace, &corev1.Namespace{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	DescribeTable("should reject an invalid target namespace", func(update func(*v1alpha1.RolloutManager), expectedMessage string) {
+
+		updateSpec(update)
+
+		rm := reconcileRolloutManager(client.ObjectKeyFromObject(&cr))
+		Expect(rm.Status.Phase).To(Equal(v1alpha1.PhaseFailure))
+		Expect(rm.Status.Conditions[0].Reason).To(Equal(v1alpha1.RolloutManagerReasonInvalidTargetNamespace))
+		Expect(rm.Status.Conditions[0].Message).To(ContainSubstring(expectedMessage))
+
+		err := fetchObject(ctx, r.Client, "", targetNamespace, &corev1.Namespace{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	},
+		Entry("of a namespace-scoped RolloutManager", func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.NamespaceScoped = true
+		}, "only supported by a cluster-scoped RolloutManager"),
+		Entry("which is the namespace of the RolloutManager", func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.TargetNamespace.Name = rm.Namespace
+		}, "must differ from the namespace of the RolloutManager"),
+		Entry("with a label which is set by the operator", func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.TargetNamespace.Labels[TargetNamespaceOwnerLabel] = "another-namespace"
+		}, "is set by the operator"),
+		Entry("with an invalid label value", func(rm *v1alpha1.RolloutManager) {
+			rm.Spec.TargetNamespace.Labels["team"] = "platform team"
+		}, "is invalid"),
+	)
+})
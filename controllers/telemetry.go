@@ -0,0 +1,245 @@
+package rollouts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TelemetryURLEnvName is an environment variable that can be used to set the URL that usage reports are sent to, instead of the --telemetry-url flag. Telemetry is disabled unless it is set. See TelemetryReporter.
+	TelemetryURLEnvName = "TELEMETRY_URL"
+
+	// TelemetryIntervalEnvName is an environment variable that can be used to set the interval between usage reports, instead of the --telemetry-interval flag.
+	TelemetryIntervalEnvName = "TELEMETRY_INTERVAL"
+
+	// DefaultTelemetryInterval is the default interval between usage reports.
+	DefaultTelemetryInterval = 24 * time.Hour
+
+	// telemetryTimeout is the timeout of requests to the telemetry endpoint.
+	telemetryTimeout = 30 * time.Second
+
+	// telemetryInstallationNamespace is the namespace whose UID identifies the cluster in usage reports, once hashed: it exists on every cluster, and is never recreated.
+	telemetryInstallationNamespace = "kube-system"
+)
+
+// OperatorVersion is the version of the operator, which is set at build time via '-ldflags -X'.
+var OperatorVersion = "dev"
+
+// TelemetryReport is the body of the requests sent to the telemetry endpoint. It is anonymized: it does not contain the names, namespaces, labels, images or values of the fields of RolloutManagers, only counts.
+type TelemetryReport struct {
+	// InstallationID is a hash of the UID of the kube-system namespace, so that the reports of a cluster can be told apart from those of other clusters, without identifying the cluster. It is empty if the namespace could not be read.
+	InstallationID string `json:"installationID,omitempty"`
+
+	// Time is the time at which the report was generated.
+	Time metav1.Time `json:"time"`
+
+	// OperatorVersion is the version of the operator.
+	OperatorVersion string `json:"operatorVersion"`
+
+	// RolloutManagers is the number of RolloutManagers on the cluster.
+	RolloutManagers int `json:"rolloutManagers"`
+
+	// NamespaceScoped and ClusterScoped are the number of namespace-scoped and cluster-scoped RolloutManagers.
+	NamespaceScoped int `json:"namespaceScoped"`
+	ClusterScoped   int `json:"clusterScoped"`
+
+	// ControllerVersions is the number of RolloutManagers by version of the Rollouts controller (the tag of its image, or 'digest' if the image is pinned by digest).
+	ControllerVersions map[string]int `json:"controllerVersions,omitempty"`
+
+	// Features is the number of RolloutManagers which set each field of .spec (for example, 'ha' or 'trafficRouting').
+	Features map[string]int `json:"features,omitempty"`
+
+	// FeatureGates is the state of the feature gates of the operator.
+	FeatureGates map[Feature]bool `json:"featureGates,omitempty"`
+}
+
+// TelemetryReporter periodically sends an anonymized TelemetryReport of the usage of the operator to a configurable endpoint, so that its maintainers can prioritize their work. It is only created when a telemetry URL is configured: telemetry is disabled by default.
+// It runs as a Runnable of the Manager, and only on the leader, so that each cluster is reported once per interval. Reports are best effort: a failure is logged, and the report is sent again at the next interval.
+type TelemetryReporter struct {
+	// Client is used to read the RolloutManagers, and the namespace which identifies the installation.
+	Client client.Reader
+
+	URL          string
+	Interval     time.Duration
+	FeatureGates FeatureGates
+	HTTPClient   *http.Client
+}
+
+// NewTelemetryReporter returns a TelemetryReporter which sends reports to the given URL, at the given interval.
+func NewTelemetryReporter(k8sClient client.Reader, telemetryURL string, interval time.Duration, featureGates FeatureGates) (*TelemetryReporter, error) {
+
+	parsed, err := url.Parse(telemetryURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid telemetry URL '%s': %w", telemetryURL, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid telemetry URL '%s': only absolute http and https URLs are supported", telemetryURL)
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid telemetry interval %s: it must be positive", interval)
+	}
+
+	return &TelemetryReporter{
+		Client:       k8sClient,
+		URL:          telemetryURL,
+		Interval:     interval,
+		FeatureGates: featureGates,
+		HTTPClient:   &http.Client{Timeout: telemetryTimeout},
+	}, nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so that only the leader sends reports.
+func (t *TelemetryReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start sends a report once the operator is started, and then at each interval, until the context is done.
+func (t *TelemetryReporter) Start(ctx context.Context) error {
+
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Report(ctx); err != nil {
+			log.Error(err, "unable to send the telemetry report", "url", t.URL)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report generates a report, and sends it to the telemetry endpoint. Any 2xx status is a success.
+func (t *TelemetryReporter) Report(ctx context.Context) error {
+
+	report, err := buildTelemetryReport(ctx, t.Client, t.FeatureGates)
+	if err != nil {
+		return err
+	}
+
+	requestBody, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("telemetry endpoint returned status %d: %s", resp.StatusCode, string(responseBody))
+	}
+
+	return nil
+}
+
+// buildTelemetryReport returns the usage report of the RolloutManagers on the cluster.
+func buildTelemetryReport(ctx context.Context, k8sClient client.Reader, featureGates FeatureGates) (TelemetryReport, error) {
+
+	report := TelemetryReport{
+		Time:               metav1.Now(),
+		OperatorVersion:    OperatorVersion,
+		ControllerVersions: map[string]int{},
+		Features:           map[string]int{},
+		FeatureGates:       map[Feature]bool{},
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := k8sClient.Get(ctx, types.NamespacedName{Name: telemetryInstallationNamespace}, namespace); err != nil {
+		log.Info(fmt.Sprintf("Reporting telemetry without an installation ID, as namespace %s could not be read: %v", telemetryInstallationNamespace, err))
+	} else {
+		hash := sha256.Sum256([]byte(namespace.UID))
+		report.InstallationID = hex.EncodeToString(hash[:16])
+	}
+
+	var rolloutManagers rolloutsmanagerv1alpha1.RolloutManagerList
+	if err := k8sClient.List(ctx, &rolloutManagers); err != nil {
+		return TelemetryReport{}, fmt.Errorf("failed to list RolloutManagers: %w", err)
+	}
+
+	for _, rolloutManager := range rolloutManagers.Items {
+		report.RolloutManagers++
+		if rolloutManager.Spec.NamespaceScoped {
+			report.NamespaceScoped++
+		} else {
+			report.ClusterScoped++
+		}
+
+		version := newMetadataTemplateData(rolloutManager).Version
+		if version == "" {
+			version = "digest"
+		}
+		report.ControllerVersions[version]++
+
+		features, err := getSpecFeatures(rolloutManager.Spec)
+		if err != nil {
+			return TelemetryReport{}, err
+		}
+		for _, feature := range features {
+			report.Features[feature]++
+		}
+	}
+
+	for feature := range defaultFeatureGates {
+		report.FeatureGates[feature] = featureGates.Enabled(feature)
+	}
+
+	return report, nil
+}
+
+// getSpecFeatures returns the (JSON) names of the fields of the spec of a RolloutManager which are set to a non-zero value, sorted. Only the names of the fields are returned, not their values.
+func getSpecFeatures(spec rolloutsmanagerv1alpha1.RolloutManagerSpec) ([]string, error) {
+
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the spec of a RolloutManager: %w", err)
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(specJSON, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the spec of a RolloutManager: %w", err)
+	}
+
+	var res []string
+	for field, value := range fields {
+		if value == nil || reflect.ValueOf(value).IsZero() {
+			continue
+		}
+		if valueOf := reflect.ValueOf(value); (valueOf.Kind() == reflect.Map || valueOf.Kind() == reflect.Slice) && valueOf.Len() == 0 {
+			continue
+		}
+		res = append(res, field)
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
@@ -0,0 +1,117 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Telemetry tests", func() {
+
+	var (
+		ctx context.Context
+		r   *RolloutManagerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		clusterScoped := makeTestRolloutManager()
+		clusterScoped.Spec.Version = "v1.7.0"
+		clusterScoped.Spec.VerifyImage = true
+
+		namespaceScoped := makeTestRolloutManager()
+		namespaceScoped.Name = "namespace-scoped"
+		namespaceScoped.Namespace = "team-a"
+		namespaceScoped.Spec.NamespaceScoped = true
+		namespaceScoped.Spec.Image = "registry.example.com/argo-rollouts@sha256:1234"
+
+		r = makeTestReconciler(clusterScoped, namespaceScoped)
+		Expect(r.Client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "kube-system-uid"}})).To(Succeed())
+	})
+
+	It("buildTelemetryReport should only report counts, without identifying the RolloutManagers", func() {
+		report, err := buildTelemetryReport(ctx, r.Client, FeatureGates{ComponentControllers: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.InstallationID).To(HaveLen(32))
+		Expect(report.InstallationID).ToNot(ContainSubstring("kube-system-uid"))
+		Expect(report.OperatorVersion).To(Equal(OperatorVersion))
+		Expect(report.RolloutManagers).To(Equal(2))
+		Expect(report.ClusterScoped).To(Equal(1))
+		Expect(report.NamespaceScoped).To(Equal(1))
+		Expect(report.ControllerVersions).To(Equal(map[string]int{"v1.7.0": 1, "digest": 1}))
+		Expect(report.Features).To(Equal(map[string]int{"verifyImage": 1, "version": 1, "namespaceScoped": 1, "image": 1}))
+		Expect(report.FeatureGates).To(HaveKeyWithValue(ComponentControllers, true))
+
+		reportJSON, err := json.Marshal(report)
+		Expect(err).ToNot(HaveOccurred())
+		for _, identifying := range []string{"team-a", "namespace-scoped", "registry.example.com", testNamespace} {
+			Expect(string(reportJSON)).ToNot(ContainSubstring(identifying))
+		}
+	})
+
+	It("getSpecFeatures should ignore the fields which are not set", func() {
+		spec := rolloutsmanagerv1alpha1.RolloutManagerSpec{
+			Env:              []corev1.EnvVar{},
+			ExtraCommandArgs: []string{"--loglevel", "debug"},
+			NodePlacement:    &rolloutsmanagerv1alpha1.RolloutsNodePlacementSpec{NodeSelector: map[string]string{}},
+			Version:          "v1.7.0",
+		}
+
+		features, err := getSpecFeatures(spec)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(features).To(Equal([]string{"extraCommandArgs", "version"}))
+	})
+
+	Context("TelemetryReporter", func() {
+
+		It("should send the report to the telemetry endpoint", func() {
+			var received TelemetryReport
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				body, _ := io.ReadAll(req.Body)
+				if req.Method != http.MethodPost || json.Unmarshal(body, &received) != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			DeferCleanup(server.Close)
+
+			reporter, err := NewTelemetryReporter(r.Client, server.URL, time.Hour, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reporter.NeedLeaderElection()).To(BeTrue())
+
+			Expect(reporter.Report(ctx)).To(Succeed())
+			Expect(received.RolloutManagers).To(Equal(2))
+		})
+
+		It("should return an error if the telemetry endpoint fails", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			DeferCleanup(server.Close)
+
+			reporter, err := NewTelemetryReporter(r.Client, server.URL, time.Hour, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reporter.Report(ctx)).To(MatchError(ContainSubstring("status 503")))
+		})
+
+		It("should reject an invalid URL or interval", func() {
+			_, err := NewTelemetryReporter(r.Client, "telemetry.example.com/report", time.Hour, nil)
+			Expect(err).To(HaveOccurred())
+
+			_, err = NewTelemetryReporter(r.Client, "https://telemetry.example.com/report", 0, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,55 @@
+package rollouts
+
+import (
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// getRolloutsPolicyRules returns the PolicyRules of the Role/ClusterRole of the Rollouts controller of the RolloutManager: the default rules (see GetPolicyRules), followed by the rules of the traffic routing providers enabled in .spec.trafficRouting.
+func getRolloutsPolicyRules(cr rolloutsmanagerv1alpha1.RolloutManager) []rbacv1.PolicyRule {
+	return append(GetPolicyRules(), getTrafficRoutingPolicyRules(cr.Spec.TrafficRouting)...)
+}
+
+// getTrafficRoutingPolicyRules returns the PolicyRules which grant the Rollouts controller read access to the resources that the status checks of the enabled traffic routing providers read (for example, the status of an Ingress, when verifying the weights of the AWS Load Balancer Controller).
+// The default rules only grant the access required to update the traffic routing resources, so that these checks would otherwise fail with Forbidden errors.
+func getTrafficRoutingPolicyRules(spec *rolloutsmanagerv1alpha1.RolloutManagerTrafficRoutingSpec) []rbacv1.PolicyRule {
+
+	if spec == nil {
+		return nil
+	}
+
+	var res []rbacv1.PolicyRule
+	addRule := func(apiGroups []string, resources ...string) {
+		res = append(res, rbacv1.PolicyRule{
+			APIGroups: apiGroups,
+			Resources: resources,
+			Verbs:     []string{"get", "list", "watch"},
+		})
+	}
+
+	// ALB and NGINX both route traffic via Ingresses, so their rule is only added once
+	if spec.ALB || spec.Nginx {
+		addRule([]string{"networking.k8s.io", "extensions"}, "ingresses", "ingresses/status")
+	}
+	if spec.ALB {
+		addRule([]string{""}, "endpoints", "services")
+		addRule([]string{"elbv2.k8s.aws"}, "targetgroupbindings")
+	}
+	if spec.Istio {
+		addRule([]string{"networking.istio.io"}, "virtualservices", "virtualservices/status", "destinationrules", "destinationrules/status")
+	}
+	if spec.SMI {
+		addRule([]string{"split.smi-spec.io"}, "trafficsplits")
+	}
+	if spec.AppMesh {
+		addRule([]string{"appmesh.k8s.aws"}, "virtualservices", "virtualservices/status", "virtualrouters", "virtualrouters/status", "virtualnodes", "virtualnodes/status")
+	}
+	if spec.Traefik {
+		addRule([]string{"traefik.containo.us", "traefik.io"}, "traefikservices")
+	}
+	if spec.APISIX {
+		addRule([]string{"apisix.apache.org"}, "apisixroutes", "apisixroutes/status")
+	}
+
+	return res
+}
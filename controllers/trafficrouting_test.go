@@ -0,0 +1,86 @@
+package rollouts
+
+import (
+	"context"
+	"os"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Traffic routing permissions tests", func() {
+
+	allProviders := &rolloutsmanagerv1alpha1.RolloutManagerTrafficRoutingSpec{
+		ALB:     true,
+		Nginx:   true,
+		Istio:   true,
+		SMI:     true,
+		AppMesh: true,
+		Traefik: true,
+		APISIX:  true,
+	}
+
+	It("should only grant the default permissions if no traffic routing provider is enabled", func() {
+		cr := *makeTestRolloutManager()
+		Expect(getRolloutsPolicyRules(cr)).To(Equal(GetPolicyRules()))
+
+		cr.Spec.TrafficRouting = &rolloutsmanagerv1alpha1.RolloutManagerTrafficRoutingSpec{}
+		Expect(getRolloutsPolicyRules(cr)).To(Equal(GetPolicyRules()))
+	})
+
+	It("should grant read access to the resources of the enabled traffic routing providers", func() {
+		permissions := GetPolicyRulesPermissions(getTrafficRoutingPolicyRules(&rolloutsmanagerv1alpha1.RolloutManagerTrafficRoutingSpec{ALB: true}))
+		Expect(permissions).To(ContainElements(
+			"get ingresses.networking.k8s.io/status",
+			"watch ingresses.extensions",
+			"list endpoints",
+			"watch targetgroupbindings.elbv2.k8s.aws",
+		))
+		Expect(permissions).ToNot(ContainElement(ContainSubstring("istio")))
+
+		permissions = GetPolicyRulesPermissions(getTrafficRoutingPolicyRules(&rolloutsmanagerv1alpha1.RolloutManagerTrafficRoutingSpec{Istio: true}))
+		Expect(permissions).To(ConsistOf(
+			"get destinationrules.networking.istio.io", "list destinationrules.networking.istio.io", "watch destinationrules.networking.istio.io",
+			"get destinationrules.networking.istio.io/status", "list destinationrules.networking.istio.io/status", "watch destinationrules.networking.istio.io/status",
+			"get virtualservices.networking.istio.io", "list virtualservices.networking.istio.io", "watch virtualservices.networking.istio.io",
+			"get virtualservices.networking.istio.io/status", "list virtualservices.networking.istio.io/status", "watch virtualservices.networking.istio.io/status",
+		))
+
+		By("only adding the Ingress rule once if both ALB and NGINX are enabled")
+		rules := getTrafficRoutingPolicyRules(&rolloutsmanagerv1alpha1.RolloutManagerTrafficRoutingSpec{ALB: true, Nginx: true})
+		Expect(rules).To(HaveLen(3))
+		Expect(rules[0].Resources).To(Equal([]string{"ingresses", "ingresses/status"}))
+	})
+
+	It("should only grant permissions which the operator holds, so that they can be granted without escalation", func() {
+		bytes, err := os.ReadFile("../config/rbac/role.yaml")
+		Expect(err).ToNot(HaveOccurred())
+
+		operatorRole := &rbacv1.ClusterRole{}
+		Expect(yaml.Unmarshal(bytes, operatorRole)).To(Succeed())
+
+		operatorPermissions := GetPolicyRulesPermissions(operatorRole.Rules)
+		Expect(operatorPermissions).To(ContainElements(GetPolicyRulesPermissions(getTrafficRoutingPolicyRules(allProviders))))
+	})
+
+	It("should update the Role of the Rollouts controller when traffic routing providers are enabled and disabled", func() {
+		ctx := context.Background()
+		cr := *makeTestRolloutManager()
+		r := makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		cr.Spec.TrafficRouting = &rolloutsmanagerv1alpha1.RolloutManagerTrafficRoutingSpec{Istio: true}
+		role, err := r.reconcileRolloutsRole(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(role.Rules).To(Equal(append(GetPolicyRules(), getTrafficRoutingPolicyRules(cr.Spec.TrafficRouting)...)))
+		Expect(GetPolicyRulesPermissions(role.Rules)).To(ContainElement("get virtualservices.networking.istio.io/status"))
+
+		cr.Spec.TrafficRouting = nil
+		role, err = r.reconcileRolloutsRole(ctx, cr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(role.Rules).To(Equal(GetPolicyRules()))
+	})
+})
@@ -0,0 +1,195 @@
+package rollouts
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// ResourceTransformerURLEnvName is an environment variable that can be used to set the URL of the resource transformer webhook, instead of the --resource-transformer-url flag. See WebhookResourceTransformer.
+	ResourceTransformerURLEnvName = "RESOURCE_TRANSFORMER_URL"
+
+	// ResourceTransformerCAFileEnvName is an environment variable that can be used to set the file containing the CA certificates of the resource transformer webhook, instead of the --resource-transformer-ca-file flag.
+	ResourceTransformerCAFileEnvName = "RESOURCE_TRANSFORMER_CA_FILE"
+
+	// ResourceTransformerTimeoutEnvName is an environment variable that can be used to set the timeout of requests to the resource transformer webhook, instead of the --resource-transformer-timeout flag.
+	ResourceTransformerTimeoutEnvName = "RESOURCE_TRANSFORMER_TIMEOUT"
+
+	// DefaultResourceTransformerTimeout is the default timeout of requests to the resource transformer webhook.
+	DefaultResourceTransformerTimeout = 10 * time.Second
+
+	// maxResourceTransformResponseSize is the maximum size of the responses of the resource transformer webhook.
+	maxResourceTransformResponseSize = 3 * 1024 * 1024
+)
+
+// ResourceTransformer mutates the resources rendered by the operator for a RolloutManager, before they are compared with the live resources and applied. This allows organization-specific changes (for example, labels, sidecars or proxy settings) without forking the operator.
+// Transform is called on every reconciliation, so it must be deterministic: otherwise the resources are updated on every reconciliation. The object has its apiVersion and kind set.
+type ResourceTransformer interface {
+	Transform(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error
+}
+
+// ResourceTransformRequest is the body of the requests sent to the resource transformer webhook.
+type ResourceTransformRequest struct {
+	// RolloutManager is the RolloutManager that the resource belongs to.
+	RolloutManager ResourceTransformRolloutManager `json:"rolloutManager"`
+
+	// Object is the resource rendered by the operator.
+	Object client.Object `json:"object"`
+}
+
+// ResourceTransformRolloutManager identifies the RolloutManager of a ResourceTransformRequest.
+type ResourceTransformRolloutManager struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// ResourceTransformResponse is the body of the responses of the resource transformer webhook.
+type ResourceTransformResponse struct {
+	// Object is the transformed resource. If it is not set, the resource is left unchanged.
+	Object json.RawMessage `json:"object,omitempty"`
+}
+
+// WebhookResourceTransformer is a ResourceTransformer which sends each resource to an HTTP(S) webhook, as a ResourceTransformRequest, and replaces it with the object of the ResourceTransformResponse.
+type WebhookResourceTransformer struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewWebhookResourceTransformer returns a WebhookResourceTransformer for the given URL. If caFile is set, the certificate of the webhook is verified against the CA certificates of the file, rather than those of the system.
+func NewWebhookResourceTransformer(url string, caFile string, timeout time.Duration) (*WebhookResourceTransformer, error) {
+
+	httpClient, err := newWebhookHTTPClient("resource transformer", caFile, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookResourceTransformer{
+		URL:        url,
+		HTTPClient: httpClient,
+	}, nil
+}
+
+// newWebhookHTTPClient returns the HTTP client used to call a webhook of the operator (described by 'webhook', for error messages). If caFile is set, the certificate of the webhook is verified against the CA certificates of the file, rather than those of the system.
+func newWebhookHTTPClient(webhook string, caFile string, timeout time.Duration) (*http.Client, error) {
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if caFile != "" {
+		caBytes, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA file of the %s: %w", webhook, err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("no PEM certificates were found in the CA file of the %s %s", webhook, caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: certPool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+func (t *WebhookResourceTransformer) Transform(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error {
+
+	requestBody, err := json.Marshal(ResourceTransformRequest{
+		RolloutManager: ResourceTransformRolloutManager{
+			Namespace: cr.Namespace,
+			Name:      cr.Name,
+			Labels:    cr.Labels,
+		},
+		Object: obj,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResourceTransformResponseSize))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		message := string(responseBody)
+		if len(message) > 256 {
+			message = message[:256] + "..."
+		}
+		return fmt.Errorf("resource transformer webhook returned status %d: %s", resp.StatusCode, message)
+	}
+
+	var response ResourceTransformResponse
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return fmt.Errorf("invalid response from resource transformer webhook: %w", err)
+	}
+
+	if len(response.Object) == 0 {
+		return nil
+	}
+
+	// The object is reset before decoding, so that fields which were removed by the webhook are removed from the object
+	reflect.ValueOf(obj).Elem().Set(reflect.Zero(reflect.TypeOf(obj).Elem()))
+
+	decoder := json.NewDecoder(bytes.NewReader(response.Object))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return fmt.Errorf("invalid object in response from resource transformer webhook: %w", err)
+	}
+
+	return nil
+}
+
+// transformResource applies the ResourceTransformer of the operator, if any, to a resource rendered for the RolloutManager. The transformer may not change the kind, namespace or name of the resource.
+func (r *RolloutManagerReconciler) transformResource(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager, obj client.Object) error {
+
+	if r.ResourceTransformer == nil {
+		return nil
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return err
+	}
+	namespace, name := obj.GetNamespace(), obj.GetName()
+
+	// The apiVersion and kind are set for the transformer, and cleared afterwards, since the rendered resources do not otherwise set them
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	err = r.ResourceTransformer.Transform(ctx, cr, obj)
+	transformedGVK := obj.GetObjectKind().GroupVersionKind()
+	obj.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+
+	if err != nil {
+		return fmt.Errorf("failed to transform %s %s: %w", gvk.Kind, name, err)
+	}
+
+	if transformedGVK != gvk || obj.GetNamespace() != namespace || obj.GetName() != name {
+		return fmt.Errorf("failed to transform %s %s: the resource transformer may not change the apiVersion, kind, namespace or name of resources", gvk.Kind, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package rollouts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Resource transformer tests", func() {
+
+	var (
+		ctx     context.Context
+		cr      rolloutsmanagerv1alpha1.RolloutManager
+		r       *RolloutManagerReconciler
+		req     reconcile.Request
+		handler http.HandlerFunc
+	)
+
+	// transformingHandler returns a webhook handler which applies the given function to the unstructured object of each request.
+	transformingHandler := func(transform func(request map[string]interface{}, obj *unstructured.Unstructured)) http.HandlerFunc {
+		return func(w http.ResponseWriter, httpReq *http.Request) {
+			defer GinkgoRecover()
+
+			var request map[string]interface{}
+			Expect(json.NewDecoder(httpReq.Body).Decode(&request)).To(Succeed())
+
+			obj := &unstructured.Unstructured{Object: request["object"].(map[string]interface{})}
+			transform(request, obj)
+
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{"object": obj.Object})).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cr = *makeTestRolloutManager()
+		r = makeTestReconciler(&cr)
+		Expect(createNamespace(r, cr.Namespace)).To(Succeed())
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, cr.Namespace)
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
+
+		req = reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, httpReq *http.Request) {
+			handler(w, httpReq)
+		}))
+		DeferCleanup(server.Close)
+
+		transformer, err := NewWebhookResourceTransformer(server.URL, "", time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		r.ResourceTransformer = transformer
+	})
+
+	It("should apply the resources transformed by the webhook, and not update them again on the next reconciliation", func() {
+		handler = transformingHandler(func(request map[string]interface{}, obj *unstructured.Unstructured) {
+			Expect(request["rolloutManager"]).To(HaveKeyWithValue("name", cr.Name))
+
+			labels := obj.GetLabels()
+			labels["example.com/team"] = "payments"
+			obj.SetLabels(labels)
+
+			if obj.GetKind() == "Deployment" {
+				containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+				containers = append(containers, map[string]interface{}{"name": "proxy", "image": "example.com/proxy:v1"})
+				Expect(unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers")).To(Succeed())
+			}
+		})
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.Labels).To(HaveKeyWithValue("example.com/team", "payments"))
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(2))
+		Expect(deployment.Spec.Template.Spec.Containers[1].Name).To(Equal("proxy"))
+
+		sa := &corev1.ServiceAccount{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, sa)).To(Succeed())
+		Expect(sa.Labels).To(HaveKeyWithValue("example.com/team", "payments"))
+
+		By("reconciling again, and verifying that the resources were not updated")
+		resourceVersion := deployment.ResourceVersion
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should leave the resources unchanged if the webhook does not return an object", func() {
+		handler = func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		}
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+	})
+
+	DescribeTable("should fail the reconciliation if the transformation fails", func(handlerFunc http.HandlerFunc, expectedError string) {
+		handler = handlerFunc
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+
+		Expect(fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, &corev1.ServiceAccount{})).ToNot(Succeed())
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(&cr), &cr)).To(Succeed())
+		Expect(cr.Status.Conditions).To(ContainElement(HaveField("Message", ContainSubstring(expectedError))))
+	},
+		Entry("the webhook returns an error", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "denied by policy", http.StatusForbidden)
+		}), "returned status 403: denied by policy"),
+		Entry("the webhook renames the resource", transformingHandler(func(_ map[string]interface{}, obj *unstructured.Unstructured) {
+			obj.SetName("renamed")
+		}), "may not change the apiVersion, kind, namespace or name"),
+		Entry("the webhook returns an unknown field", transformingHandler(func(_ map[string]interface{}, obj *unstructured.Unstructured) {
+			obj.Object["unknownField"] = "value"
+		}), "unknown field"),
+	)
+})
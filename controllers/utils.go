@@ -2,16 +2,22 @@ package rollouts
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"os"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -21,6 +27,14 @@ const (
 	UnsupportedRolloutManagerClusterScoped          = "when Subscription has environment variable NAMESPACE_SCOPED_ARGO_ROLLOUTS set to True, there may not exist any cluster-scoped RolloutManagers: in this case, only namespace-scoped RolloutManager resources are supported"
 	UnsupportedRolloutManagerNamespaceScoped        = "when Subscription has environment variable NAMESPACE_SCOPED_ARGO_ROLLOUTS set to False, there may not exist any namespace-scoped RolloutManagers: only a single cluster-scoped RolloutManager is supported"
 	UnsupportedRolloutManagerClusterScopedNamespace = "Namespace is not specified in CLUSTER_SCOPED_ARGO_ROLLOUTS_NAMESPACES environment variable of Subscription resource. If you wish to install a cluster-scoped Argo Rollouts instance outside the default namespace, ensure it is defined in CLUSTER_SCOPED_ARGO_ROLLOUTS_NAMESPACES"
+	UnsupportedImageNotDigest                       = "Subscription has environment variable DIGEST_ONLY_IMAGES set to True: only images pinned by digest (e.g. 'quay.io/argoproj/argo-rollouts@sha256:...') are supported"
+
+	ConflictingInstallationMessage      = "Argo Rollouts resources which are not managed by the operator (for example, from a Helm or kubectl install) were found, which may conflict with the Rollouts controller of this RolloutManager, since two Rollouts controllers that reconcile the same Rollouts cause nondeterministic behaviour: "
+	PodSecurityViolationMessage         = "The Rollouts controller pod violates the Pod Security Standard enforced on the namespace of this RolloutManager (via the pod-security.kubernetes.io/enforce label), so it will not be admitted: "
+	CloudIdentityNotConfiguredMessage   = "The Rollouts controller is configured to use a cloud workload identity, but it is not configured on the argo-rollouts ServiceAccount, so requests to the cloud provider APIs (for example, by AnalysisRuns or traffic routers) will fail: "
+	ClusterAPIRequiredMessage           = "The operator runs in strict namespace-scoped mode (STRICT_NAMESPACE_SCOPED), so it makes no requests to cluster-scoped APIs: these features of this RolloutManager require cluster-scoped resources, so they are not reconciled: "
+	DashboardCertificateNotReadyMessage = "The TLS certificate of the dashboard is requested from cert-manager, but it is not ready, so the dashboard Ingress is not yet created: "
+	PolicyViolationMessage              = "Resources rendered for this RolloutManager violate the resource policies of the operator, so they were not applied: "
 )
 
 // pluginItem is a clone of PluginItem from "github.com/argoproj/argo-rollouts/utils/plugin/types"
@@ -40,6 +54,11 @@ func setRolloutsLabelsAndAnnotationsToObject(obj *metav1.ObjectMeta, cr rollouts
 
 func setAdditionalRolloutsLabelsAndAnnotationsToObject(obj *metav1.ObjectMeta, cr rolloutsmanagerv1alpha1.RolloutManager) {
 
+	// The recommended labels may be overridden by the AdditionalMetadata and the common labels
+	if recommendedLabels := getRecommendedLabels(cr); len(recommendedLabels) > 0 {
+		obj.Labels = combineStringMaps(obj.Labels, recommendedLabels)
+	}
+
 	if cr.Spec.AdditionalMetadata != nil {
 		if obj.Labels == nil {
 			obj.Labels = map[string]string{}
@@ -47,14 +66,24 @@ func setAdditionalRolloutsLabelsAndAnnotationsToObject(obj *metav1.ObjectMeta, c
 		if obj.Annotations == nil {
 			obj.Annotations = map[string]string{}
 		}
-		for k, v := range cr.Spec.AdditionalMetadata.Labels {
+		additionalLabels, additionalAnnotations := getAdditionalMetadata(cr)
+		for k, v := range additionalLabels {
 			obj.Labels[k] = v
 		}
-		for k, v := range cr.Spec.AdditionalMetadata.Annotations {
+		for k, v := range additionalAnnotations {
 			obj.Annotations[k] = v
 		}
 	}
 
+	if commonLabels := getCommonLabels(); len(commonLabels) > 0 {
+		if obj.Labels == nil {
+			obj.Labels = map[string]string{}
+		}
+		for k, v := range commonLabels {
+			obj.Labels[k] = v
+		}
+	}
+
 }
 
 func setRolloutsLabelsAndAnnotations(obj *metav1.ObjectMeta) {
@@ -74,6 +103,23 @@ func fetchObject(ctx context.Context, client client.Client, namespace string, na
 	return client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
 }
 
+var (
+	// customResourceDefinitionGVK and namespaceGVK are used to retrieve the metadata of CustomResourceDefinitions and Namespaces, via fetchObjectMetadata
+	customResourceDefinitionGVK = crdv1.SchemeGroupVersion.WithKind("CustomResourceDefinition")
+	namespaceGVK                = corev1.SchemeGroupVersion.WithKind("Namespace")
+)
+
+// fetchObjectMetadata retrieves only the metadata of the object with the given GroupVersionKind, namespace and name.
+// This is used where the operator only checks the existence or metadata of an object (e.g. CustomResourceDefinitions and Namespaces), so that the full objects are not decoded, nor cached by the informers of the client.
+func fetchObjectMetadata(ctx context.Context, k8sClient client.Client, gvk schema.GroupVersionKind, namespace string, name string) (*metav1.PartialObjectMetadata, error) {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gvk)
+	if err := fetchObject(ctx, k8sClient, namespace, name, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
 // Appends the map `add` to the given map `src` and return the result.
 func appendStringMap(src map[string]string, add map[string]string) map[string]string {
 	res := src
@@ -176,6 +222,26 @@ func combineImageTag(img string, tag string) string {
 	return img
 }
 
+// applyImageRegistryMirror replaces the registry of the given default image with the mirror of DEFAULT_IMAGE_REGISTRY_MIRROR, if it is set.
+// Images which are specified by the user (in the RolloutManager, or via ARGO_ROLLOUTS_IMAGE) should not be passed to this function.
+func applyImageRegistryMirror(image string) string {
+
+	mirror := strings.TrimSuffix(strings.TrimSpace(os.Getenv(DefaultImageRegistryMirrorEnvName)), "/")
+	if mirror == "" {
+		return image
+	}
+
+	// The first component of the image is its registry if it is a host name (e.g. 'quay.io'): otherwise, the image is from Docker Hub and has no registry component
+	repository := image
+	if idx := strings.Index(image, "/"); idx != -1 {
+		if registry := image[:idx]; strings.ContainsAny(registry, ".:") || registry == "localhost" {
+			repository = image[idx+1:]
+		}
+	}
+
+	return mirror + "/" + repository
+}
+
 // contains returns true if a string is part of the given slice.
 func contains(s []string, g string) bool {
 	for _, a := range s {
@@ -274,9 +340,9 @@ func splitList(s string) []string {
 	return elems
 }
 
-// checkForExistingRolloutManager will return error if more than one cluster-scoped RolloutManagers are created.
+// checkForExistingRolloutManager will return error if another cluster-scoped RolloutManager holds the cluster-scoped lock (see acquireClusterScopedLock),
 // because only one cluster-scoped or all namespace-scoped RolloutManagers are supported.
-func checkForExistingRolloutManager(ctx context.Context, k8sClient client.Client, cr rolloutsmanagerv1alpha1.RolloutManager) (*reconcileStatusResult, error) {
+func (r *RolloutManagerReconciler) checkForExistingRolloutManager(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (*reconcileStatusResult, error) {
 
 	// if it is namespace-scoped then return no error
 	// because multiple namespace-scoped RolloutManagers are allowed if validateRolloutsScope check is passed earlier.
@@ -284,39 +350,27 @@ func checkForExistingRolloutManager(ctx context.Context, k8sClient client.Client
 		return nil, nil
 	}
 
-	// get the list of all RolloutManagers available across all namespaces
-	rolloutManagerList := rolloutsmanagerv1alpha1.RolloutManagerList{}
-	if err := k8sClient.List(ctx, &rolloutManagerList); err != nil {
-		return nil, fmt.Errorf("failed to get the list of RolloutManager CRs from cluster: %w", err)
+	// The first cluster-scoped RolloutManager to acquire the lock is reconciled, while the others are set to failure, naming the holder of the lock
+	holder, err := r.acquireClusterScopedLock(ctx, cr)
+	if err != nil {
+		return nil, err
 	}
 
-	// if there are more than one RolloutManagers available, then check if any cluster-scoped RolloutManager exists,
-	// if yes then return error for this CR, because only one cluster-scoped RolloutManagers is supported
-	for _, rolloutManager := range rolloutManagerList.Items {
+	if holder != nil {
 
-		// if current RolloutManager is being iterated, then skip it, because we are looking for other cluster-scoped RolloutManagers.
-		if rolloutManager.Name == cr.Name && rolloutManager.Namespace == cr.Namespace {
-			continue
-		}
+		phaseFailure := rolloutsmanagerv1alpha1.PhaseFailure
 
-		// if there is a another cluster-scoped RolloutManager available in cluster then skip reconciliation of this one and set status to failure.
-		if !rolloutManager.Spec.NamespaceScoped {
-
-			phaseFailure := rolloutsmanagerv1alpha1.PhaseFailure
-
-			return &reconcileStatusResult{
-				rolloutController: &phaseFailure,
-				phase:             &phaseFailure,
-			}, errors.New(UnsupportedRolloutManagerConfiguration)
-
-		}
+		return &reconcileStatusResult{
+			rolloutController: &phaseFailure,
+			phase:             &phaseFailure,
+		}, errors.New(MultipleClusterScopedRolloutManagersMessage(*holder))
 	}
 
 	return nil, nil
 }
 
 func multipleRolloutManagersExist(err error) bool {
-	return err.Error() == UnsupportedRolloutManagerConfiguration
+	return strings.HasPrefix(err.Error(), UnsupportedRolloutManagerConfiguration)
 }
 
 func invalidRolloutScope(err error) bool {
@@ -331,7 +385,11 @@ func invalidRolloutNamespace(err error) bool {
 // updateStatusConditionOfRolloutManager calls Set Condition of RolloutManager status
 func updateStatusConditionOfRolloutManager(ctx context.Context, rr reconcileStatusResult, rm *rolloutsmanagerv1alpha1.RolloutManager, k8sClient client.Client, log logr.Logger) error {
 
+	// The conditions reflect the generation of the RolloutManager that was reconciled
+	rr.condition.ObservedGeneration = rm.Generation
+
 	changed, newConditions := insertOrUpdateConditionsInSlice(rr.condition, rm.Status.Conditions)
+	rm.Status.Conditions = newConditions
 
 	if rr.phase != nil && *rr.phase != rm.Status.Phase {
 		rm.Status.Phase = *rr.phase
@@ -343,9 +401,88 @@ func updateStatusConditionOfRolloutManager(ctx context.Context, rr reconcileStat
 		changed = true
 	}
 
-	if changed {
-		rm.Status.Conditions = newConditions
+	if rr.relatedImages != nil && !reflect.DeepEqual(rr.relatedImages, rm.Status.RelatedImages) {
+		rm.Status.RelatedImages = rr.relatedImages
+		changed = true
+	}
+
+	if rr.conflictingInstallations != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerConflictingInstallationConditionType, rr.conflictingInstallations, createConflictingInstallationCondition) {
+		changed = true
+	}
+
+	if rr.podSecurityViolations != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerPodSecurityViolationConditionType, rr.podSecurityViolations, createPodSecurityViolationCondition) {
+		changed = true
+	}
+
+	if rr.cloudIdentityProblems != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerCloudIdentityNotConfiguredConditionType, rr.cloudIdentityProblems, createCloudIdentityNotConfiguredCondition) {
+		changed = true
+	}
+
+	if rr.dashboardCertificateNotReadyReasons != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerDashboardCertificateNotReadyConditionType, rr.dashboardCertificateNotReadyReasons, createDashboardCertificateNotReadyCondition) {
+		changed = true
+	}
+
+	if rr.clusterAPIRequirements != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerClusterAPIRequiredConditionType, rr.clusterAPIRequirements, createClusterAPIRequiredCondition) {
+		changed = true
+	}
+
+	if rr.controllerCrashes != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerControllerCrashingConditionType, rr.controllerCrashes, createControllerCrashingCondition) {
+		changed = true
+	}
+
+	if rr.controllerDegradations != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerControllerDegradedConditionType, rr.controllerDegradations, func(degradations []string) metav1.Condition {
+		return createControllerDegradedCondition(rr.controllerDegradationReason, degradations)
+	}) {
+		changed = true
+	}
 
+	if rr.policyViolations != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerDegradedConditionType, rr.policyViolations, createDegradedCondition) {
+		changed = true
+	}
+
+	if rr.rbacEscalationDenials != nil && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerRBACEscalationDeniedConditionType, rr.rbacEscalationDenials, createRBACEscalationDeniedCondition) {
+		changed = true
+	}
+
+	if len(rr.rbacRuleChanges) > 0 && setOrRemoveCondition(rm, rolloutsmanagerv1alpha1.RolloutManagerRBACRulesChangedConditionType, rr.rbacRuleChanges, createRBACRulesChangedCondition) {
+		changed = true
+	}
+
+	if rr.appliedSpec != nil && setLastAppliedSpec(rm, *rr.appliedSpec) {
+		changed = true
+	}
+
+	if rr.controllerResourcesRecommendation != nil && setControllerResourcesRecommendation(rm, *rr.controllerResourcesRecommendation) {
+		changed = true
+	}
+
+	if rr.components != nil && !reflect.DeepEqual(rr.components, rm.Status.Components) {
+		rm.Status.Components = rr.components
+		changed = true
+	}
+
+	if rr.recordFailure && setReconcileFailure(rm, rr.reconcileErr) {
+		changed = true
+	}
+
+	if setPprofExpirationTime(rm, rr.pprofExpirationTime) {
+		changed = true
+	}
+
+	if rr.removeComponentConditions && removeComponentConditions(rm) {
+		changed = true
+	}
+
+	if setReadinessConditions(rm) {
+		changed = true
+	}
+
+	if rm.Status.ObservedGeneration != rm.Generation {
+		rm.Status.ObservedGeneration = rm.Generation
+		changed = true
+	}
+
+	if changed {
 		if err := k8sClient.Status().Update(ctx, rm); err != nil {
 			log.Error(err, "unable to update RolloutManager status condition")
 			return err
@@ -354,6 +491,19 @@ func updateStatusConditionOfRolloutManager(ctx context.Context, rr reconcileStat
 	return nil
 }
 
+// setOrRemoveCondition sets the condition returned by newCondition on the RolloutManager if items (for example, the conflicting resources) is non-empty, or removes the condition of the given type if it is empty. It returns true if the conditions of the RolloutManager changed.
+func setOrRemoveCondition(rm *rolloutsmanagerv1alpha1.RolloutManager, conditionType string, items []string, newCondition func([]string) metav1.Condition) bool {
+	var conditionChanged bool
+	if len(items) > 0 {
+		condition := newCondition(items)
+		condition.ObservedGeneration = rm.Generation
+		conditionChanged, rm.Status.Conditions = insertOrUpdateConditionsInSlice(condition, rm.Status.Conditions)
+	} else {
+		conditionChanged, rm.Status.Conditions = removeConditionFromSlice(conditionType, rm.Status.Conditions)
+	}
+	return conditionChanged
+}
+
 // insertOrUpdateConditionsInSlice is a generic function for inserting/updating metav1.Condition into a slice of []metav1.Condition
 func insertOrUpdateConditionsInSlice(newCondition metav1.Condition, existingConditions []metav1.Condition) (bool, []metav1.Condition) {
 
@@ -384,12 +534,30 @@ func insertOrUpdateConditionsInSlice(newCondition metav1.Condition, existingCond
 		newCondition.LastTransitionTime = now
 		existingConditions[index] = newCondition
 		changed = true
+
+	} else if existingConditions[index].ObservedGeneration != newCondition.ObservedGeneration {
+
+		// The condition is unchanged, only the generation that it reflects
+		existingConditions[index].ObservedGeneration = newCondition.ObservedGeneration
+		changed = true
 	}
 
 	return changed, existingConditions
 
 }
 
+// removeConditionFromSlice removes the condition of the given type from the slice, if present. Returns true if it was removed.
+func removeConditionFromSlice(conditionType string, existingConditions []metav1.Condition) (bool, []metav1.Condition) {
+
+	for i, condition := range existingConditions {
+		if condition.Type == conditionType {
+			return true, append(existingConditions[:i:i], existingConditions[i+1:]...)
+		}
+	}
+
+	return false, existingConditions
+}
+
 // wrapCondition is a utility function which returns an empty reconcileStatusResult containing only the condition
 func wrapCondition(cond metav1.Condition) reconcileStatusResult {
 	return reconcileStatusResult{
@@ -440,6 +608,44 @@ func createCondition(message string, reason ...string) metav1.Condition {
 	}
 }
 
+// hasExpectedLabelsAndAnnotations returns true if the live object has all of the expected labels and annotations, with the expected values. Other labels and annotations of the live object (for example, those added by users) are ignored.
+func hasExpectedLabelsAndAnnotations(live metav1.ObjectMeta, expected metav1.ObjectMeta) bool {
+	for k, v := range expected.Labels {
+		if liveValue, exists := live.Labels[k]; !exists || liveValue != v {
+			return false
+		}
+	}
+	for k, v := range expected.Annotations {
+		if liveValue, exists := live.Annotations[k]; !exists || liveValue != v {
+			return false
+		}
+	}
+	return true
+}
+
+// computeSpecHash returns a hash of the expected state of a resource, to be stored in the SpecHashAnnotation of the resource.
+func computeSpecHash(expected client.Object) string {
+	bytes, err := json.Marshal(expected)
+	if err != nil {
+		// An empty hash never matches, so the resource is compared and updated as usual
+		log.Error(err, "unable to compute the hash of the expected state", "name", expected.GetName())
+		return ""
+	}
+	hasher := fnv.New64a()
+	hasher.Write(bytes)
+	return strconv.FormatUint(hasher.Sum64(), 16)
+}
+
+// setSpecHashAnnotation sets the SpecHashAnnotation of the object to the given hash.
+func setSpecHashAnnotation(obj *metav1.ObjectMeta, hash string) {
+	obj.Annotations = combineStringMaps(obj.Annotations, map[string]string{SpecHashAnnotation: hash})
+}
+
+// specHashMatches returns true if the object was last updated by the operator to the expected state with the given hash.
+func specHashMatches(obj metav1.ObjectMeta, hash string) bool {
+	return hash != "" && obj.Annotations[SpecHashAnnotation] == hash
+}
+
 // removeUserLabelsAndAnnotations will remove any miscellaneous labels/annotations from obj, that are not used or expected by argo-rollouts-manager. For example, if a user added a label, "my-key": "my-value", to annotations of a Role that is created by our operator, this function would remove that label from 'obj'.
 func removeUserLabelsAndAnnotations(obj *metav1.ObjectMeta, cr rolloutsmanagerv1alpha1.RolloutManager) {
 
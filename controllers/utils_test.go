@@ -3,19 +3,23 @@ package rollouts
 import (
 	"context"
 	"os"
+	"time"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	logger "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -95,10 +99,13 @@ var _ = Describe("updateStatusConditionOfRolloutManager tests", func() {
 			Expect(updateStatusConditionOfRolloutManager(ctx, rsr, &rolloutsManager, k8sClient, logger.FromContext(ctx))).To(Succeed())
 
 			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutsManager), &rolloutsManager)).To(Succeed())
-			Expect(rolloutsManager.Status.Conditions).To(HaveLen(1))
+			Expect(rolloutsManager.Status.Conditions[0].Type).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerConditionType))
 			Expect(rolloutsManager.Status.Conditions[0].Message).To(Equal(newCondition.Message))
 			Expect(rolloutsManager.Status.Conditions[0].Reason).To(Equal(newCondition.Reason))
 
+			By("verifying that the Ready condition is also set")
+			Expect(rolloutsManager.Status.Conditions).To(ContainElement(HaveField("Type", rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType)))
+
 			// Verify whether an error condition is set when len(reason) > 1.
 			if len(reason) > 1 {
 				Expect(newCondition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonErrorOccurred))
@@ -117,6 +124,7 @@ var _ = Describe("checkForExistingRolloutManager tests", func() {
 	var (
 		ctx             context.Context
 		k8sClient       client.WithWatch
+		r               *RolloutManagerReconciler
 		rolloutsManager rolloutsmanagerv1alpha1.RolloutManager
 	)
 
@@ -131,25 +139,49 @@ var _ = Describe("checkForExistingRolloutManager tests", func() {
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "test-rm-1",
 				Namespace: "test-ns-1",
+				UID:       "test-rm-1-uid",
 			},
 			Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
 				NamespaceScoped: false,
 			},
 		}
 		k8sClient = fake.NewClientBuilder().WithScheme(s).WithStatusSubresource(&rolloutsManager).Build()
+		r = &RolloutManagerReconciler{Client: k8sClient, Scheme: s, ClusterScopedLockNamespace: "operator-ns"}
+
+		os.Setenv(ClusterScopedArgoRolloutsNamespaces, "test-ns-1,test-ns-2")
+		DeferCleanup(os.Unsetenv, ClusterScopedArgoRolloutsNamespaces)
 	})
 
+	newClusterScopedRolloutManager := func() rolloutsmanagerv1alpha1.RolloutManager {
+		return rolloutsmanagerv1alpha1.RolloutManager{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-rm-2",
+				Namespace: "test-ns-2",
+				UID:       "test-rm-2-uid",
+			},
+			Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
+				NamespaceScoped: false,
+			},
+		}
+	}
+
 	When("A single cluster-scoped RolloutsManager is created.", func() {
 
-		It("should not return any error, as it is a valid use case.", func() {
+		It("should not return any error, as it is a valid use case, and acquire the cluster-scoped lock.", func() {
 
 			By("Create only one RolloutManager.")
 			Expect(k8sClient.Create(ctx, &rolloutsManager)).To(Succeed())
 
 			By("Verify there is no error returned.")
-			rr, err := checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager)
+			rr, err := r.checkForExistingRolloutManager(ctx, rolloutsManager)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(rr).To(BeNil())
+
+			By("Verify that the RolloutManager holds the lock.")
+			lease := &coordinationv1.Lease{}
+			Expect(fetchObject(ctx, k8sClient, "operator-ns", ClusterScopedLockLeaseName, lease)).To(Succeed())
+			Expect(*lease.Spec.HolderIdentity).To(Equal("test-ns-1/test-rm-1"))
+			Expect(lease.Annotations).To(HaveKeyWithValue(clusterScopedLockHolderUIDAnnotation, "test-rm-1-uid"))
 		})
 	})
 
@@ -162,117 +194,204 @@ var _ = Describe("checkForExistingRolloutManager tests", func() {
 			Expect(k8sClient.Create(ctx, &rolloutsManager)).To(Succeed())
 
 			By("1st RM: Verify there is no error returned, as only one RolloutsManager is created yet.")
-			rr, err := checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager)
+			rr, err := r.checkForExistingRolloutManager(ctx, rolloutsManager)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(rr).To(BeNil())
 
 			By("2nd RM: Create namespace-scoped RolloutsManager.")
-			rolloutsManager2 := rolloutsmanagerv1alpha1.RolloutManager{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-rm-2",
-					Namespace: "test-ns-2",
-				},
-				Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
-					NamespaceScoped: true,
-				},
-			}
+			rolloutsManager2 := newClusterScopedRolloutManager()
+			rolloutsManager2.Spec.NamespaceScoped = true
 			Expect(k8sClient.Create(ctx, &rolloutsManager2)).To(Succeed())
 
 			By("2nd RM: Verify there is no error returned, as all namespace-scoped RolloutsManagers are created.")
-			rr, err = checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager2)
+			rr, err = r.checkForExistingRolloutManager(ctx, rolloutsManager2)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(rr).To(BeNil())
 
 			By("1st RM: Recheck and it should still work, as all namespace-scoped RolloutsManagers are created.")
 			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: rolloutsManager.Name, Namespace: rolloutsManager.Namespace}, &rolloutsManager)).To(Succeed())
-			rr, err = checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager)
+			rr, err = r.checkForExistingRolloutManager(ctx, rolloutsManager)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(rr).To(BeNil())
+
+			By("Verify that the cluster-scoped lock is not acquired by namespace-scoped RolloutManagers.")
+			lease := &coordinationv1.Lease{}
+			Expect(apierrors.IsNotFound(fetchObject(ctx, k8sClient, "operator-ns", ClusterScopedLockLeaseName, lease))).To(BeTrue())
 		})
 	})
 
 	When("Multiple cluster-scoped RolloutsManagers are created.", func() {
 
-		It("should return error for all of them, because only one cluster-scoped RolloutsManagers is allowed.", func() {
+		It("should only return error for the RolloutManagers which do not hold the cluster-scoped lock, naming the one which holds it.", func() {
 
 			By("1st RM: Create cluster-scoped RolloutsManager.")
 			Expect(k8sClient.Create(ctx, &rolloutsManager)).To(Succeed())
 
 			By("1st RM: Verify there is no error returned, as only one RolloutsManager is created yet.")
-			rr, err := checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager)
+			rr, err := r.checkForExistingRolloutManager(ctx, rolloutsManager)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(rr).To(BeNil())
 
 			By("2nd RM: Create another cluster-scoped RolloutsManager.")
-			rolloutsManager2 := rolloutsmanagerv1alpha1.RolloutManager{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-rm-2",
-					Namespace: "test-ns-2",
-				},
-				Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
-					NamespaceScoped: false,
-				},
-			}
+			rolloutsManager2 := newClusterScopedRolloutManager()
 			Expect(k8sClient.Create(ctx, &rolloutsManager2)).To(Succeed())
 
-			By("2nd RM: It should return error.")
-			rr, err = checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager2)
+			By("2nd RM: It should return error, naming the 1st RolloutManager.")
+			rr, err = r.checkForExistingRolloutManager(ctx, rolloutsManager2)
 			Expect(err).To(HaveOccurred())
 			Expect(multipleRolloutManagersExist(err)).To(BeTrue())
+			Expect(err.Error()).To(Equal(MultipleClusterScopedRolloutManagersMessage(types.NamespacedName{Namespace: "test-ns-1", Name: "test-rm-1"})))
 			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
 			Expect(*rr.rolloutController).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
 
-			By("1st RM: Recheck 1st RolloutsManager and it should also have error now. since multiple RolloutsManagers are created.")
-			rr, err = checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager)
+			By("1st RM: Recheck 1st RolloutsManager and it should still work, as it holds the lock.")
+			rr, err = r.checkForExistingRolloutManager(ctx, rolloutsManager)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rr).To(BeNil())
+		})
+
+		It("should let another cluster-scoped RolloutManager take over the lock, when the RolloutManager which holds it is deleted.", func() {
+
+			By("1st RM: Create cluster-scoped RolloutsManager, which acquires the lock.")
+			Expect(k8sClient.Create(ctx, &rolloutsManager)).To(Succeed())
+			rr, err := r.checkForExistingRolloutManager(ctx, rolloutsManager)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rr).To(BeNil())
+
+			By("2nd RM: Create another cluster-scoped RolloutsManager, which does not.")
+			rolloutsManager2 := newClusterScopedRolloutManager()
+			Expect(k8sClient.Create(ctx, &rolloutsManager2)).To(Succeed())
+			_, err = r.checkForExistingRolloutManager(ctx, rolloutsManager2)
 			Expect(err).To(HaveOccurred())
 			Expect(multipleRolloutManagersExist(err)).To(BeTrue())
-			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
-			Expect(*rr.rolloutController).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+
+			By("1st RM: Delete the RolloutsManager which holds the lock.")
+			Expect(k8sClient.Delete(ctx, &rolloutsManager)).To(Succeed())
+
+			By("2nd RM: Verify it works now, as it took over the lock.")
+			rr, err = r.checkForExistingRolloutManager(ctx, rolloutsManager2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rr).To(BeNil())
+
+			lease := &coordinationv1.Lease{}
+			Expect(fetchObject(ctx, k8sClient, "operator-ns", ClusterScopedLockLeaseName, lease)).To(Succeed())
+			Expect(*lease.Spec.HolderIdentity).To(Equal("test-ns-2/test-rm-2"))
+			Expect(*lease.Spec.LeaseTransitions).To(Equal(int32(1)))
+
+			By("1st RM: Recreate the 1st RolloutManager with the same name, and verify that it does not inherit the lock.")
+			rolloutsManager.ResourceVersion = ""
+			rolloutsManager.UID = "test-rm-1-new-uid"
+			Expect(k8sClient.Create(ctx, &rolloutsManager)).To(Succeed())
+
+			_, err = r.checkForExistingRolloutManager(ctx, rolloutsManager)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(MultipleClusterScopedRolloutManagersMessage(types.NamespacedName{Namespace: "test-ns-2", Name: "test-rm-2"})))
 		})
 
-		It("should return error when multiple cluster-scoped RolloutsManagers are created, and when one of them is deleted other one should start working.", func() {
+		It("should let another cluster-scoped RolloutManager take over the lock, when the RolloutManager which holds it failed validation.", func() {
 
-			By("1st RM: Create cluster-scoped RolloutsManager.")
+			By("1st RM: Create cluster-scoped RolloutsManager, which acquires the lock.")
+			rolloutsManager.Generation = 1
 			Expect(k8sClient.Create(ctx, &rolloutsManager)).To(Succeed())
+			_, err := r.checkForExistingRolloutManager(ctx, rolloutsManager)
+			Expect(err).ToNot(HaveOccurred())
 
-			By("1st RM: Verify there is no error returned, as only one RolloutsManager is created yet.")
-			rr, err := checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager)
+			By("2nd RM: Create another cluster-scoped RolloutsManager, which does not.")
+			rolloutsManager2 := newClusterScopedRolloutManager()
+			Expect(k8sClient.Create(ctx, &rolloutsManager2)).To(Succeed())
+			_, err = r.checkForExistingRolloutManager(ctx, rolloutsManager2)
+			Expect(err).To(HaveOccurred())
+			Expect(multipleRolloutManagersExist(err)).To(BeTrue())
+
+			By("1st RM: Report that a previous generation of the RolloutManager which holds the lock failed validation, which does not release it.")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutsManager), &rolloutsManager)).To(Succeed())
+			invalidCondition := createCondition("invalid", rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidControllerTuning)
+			invalidCondition.ObservedGeneration = rolloutsManager.Generation - 1
+			rolloutsManager.Status.Phase = rolloutsmanagerv1alpha1.PhaseFailure
+			rolloutsManager.Status.Conditions = []metav1.Condition{invalidCondition}
+			Expect(k8sClient.Status().Update(ctx, &rolloutsManager)).To(Succeed())
+
+			_, err = r.checkForExistingRolloutManager(ctx, rolloutsManager2)
+			Expect(err).To(HaveOccurred())
+			Expect(multipleRolloutManagersExist(err)).To(BeTrue())
+
+			By("1st RM: Report that the current generation failed validation.")
+			rolloutsManager.Status.Conditions[0].ObservedGeneration = rolloutsManager.Generation
+			Expect(k8sClient.Status().Update(ctx, &rolloutsManager)).To(Succeed())
+
+			By("2nd RM: Verify it works now, as it took over the lock.")
+			rr, err := r.checkForExistingRolloutManager(ctx, rolloutsManager2)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(rr).To(BeNil())
 
-			By("2nd RM: Create another cluster-scoped RolloutsManager.")
-			rolloutsManager2 := rolloutsmanagerv1alpha1.RolloutManager{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-rm-2",
-					Namespace: "test-ns-2",
-				},
-				Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
-					NamespaceScoped: false,
+			lease := &coordinationv1.Lease{}
+			Expect(fetchObject(ctx, k8sClient, "operator-ns", ClusterScopedLockLeaseName, lease)).To(Succeed())
+			Expect(*lease.Spec.HolderIdentity).To(Equal("test-ns-2/test-rm-2"))
+
+			By("1st RM: Verify that it does not get the lock back once it is valid again.")
+			_, err = r.checkForExistingRolloutManager(ctx, rolloutsManager)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(MultipleClusterScopedRolloutManagersMessage(types.NamespacedName{Namespace: "test-ns-2", Name: "test-rm-2"})))
+		})
+
+		It("should not acquire the lock for a cluster-scoped RolloutManager which fails validation.", func() {
+
+			By("1st RM: Create cluster-scoped RolloutsManager, whose dashboard is invalid.")
+			rolloutsManager.Spec.Dashboard = &rolloutsmanagerv1alpha1.RolloutManagerDashboardSpec{
+				Enabled: true,
+				Ingress: &rolloutsmanagerv1alpha1.RolloutManagerDashboardIngressSpec{
+					Enabled: true,
+					TLS: &rolloutsmanagerv1alpha1.RolloutManagerDashboardIngressTLSSpec{
+						Enabled:     true,
+						CertManager: &rolloutsmanagerv1alpha1.RolloutManagerDashboardCertManagerSpec{IssuerRef: rolloutsmanagerv1alpha1.RolloutManagerCertManagerIssuerRef{Name: "issuer"}},
+					},
 				},
 			}
+			Expect(k8sClient.Create(ctx, &rolloutsManager)).To(Succeed())
+
+			rr, _, err := r.validateRolloutManager(ctx, &rolloutsManager, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rr).ToNot(BeNil())
+			Expect(rr.condition.Reason).To(Equal(rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidDashboard))
+
+			lease := &coordinationv1.Lease{}
+			Expect(apierrors.IsNotFound(fetchObject(ctx, k8sClient, "operator-ns", ClusterScopedLockLeaseName, lease))).To(BeTrue())
+
+			By("2nd RM: Create another, valid, cluster-scoped RolloutsManager, which acquires the lock.")
+			rolloutsManager2 := newClusterScopedRolloutManager()
 			Expect(k8sClient.Create(ctx, &rolloutsManager2)).To(Succeed())
 
-			By("2nd RM: It should return error.")
-			rr, err = checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager2)
-			Expect(err).To(HaveOccurred())
-			Expect(multipleRolloutManagersExist(err)).To(BeTrue())
-			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
-			Expect(*rr.rolloutController).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+			rr, _, err = r.validateRolloutManager(ctx, &rolloutsManager2, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rr).To(BeNil())
 
-			By("1st RM: Recheck 1st RolloutsManager and it should also have error now. since multiple RolloutsManagers are created.")
-			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: rolloutsManager2.Name, Namespace: rolloutsManager2.Namespace}, &rolloutsManager2)).To(Succeed())
+			Expect(fetchObject(ctx, k8sClient, "operator-ns", ClusterScopedLockLeaseName, lease)).To(Succeed())
+			Expect(*lease.Spec.HolderIdentity).To(Equal("test-ns-2/test-rm-2"))
+		})
 
-			rr, err = checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager)
-			Expect(err).To(HaveOccurred())
-			Expect(multipleRolloutManagersExist(err)).To(BeTrue())
-			Expect(*rr.phase).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
-			Expect(*rr.rolloutController).To(Equal(rolloutsmanagerv1alpha1.PhaseFailure))
+		It("should let exactly one of the cluster-scoped RolloutManagers acquire the lock, when they race to create it.", func() {
 
-			By("2nd RM: Delete one RolloutsManager.")
-			Expect(k8sClient.Delete(ctx, &rolloutsManager2)).To(Succeed())
+			rolloutsManager2 := newClusterScopedRolloutManager()
+			Expect(k8sClient.Create(ctx, &rolloutsManager)).To(Succeed())
+			Expect(k8sClient.Create(ctx, &rolloutsManager2)).To(Succeed())
 
-			By("1st RM: Verify it works now, as only one RolloutsManager is available.")
-			rr, err = checkForExistingRolloutManager(ctx, k8sClient, rolloutsManager)
+			By("creating the lock for the 2nd RolloutManager once the 1st one found that it does not exist, as a concurrent reconciliation would")
+			r.Client = interceptor.NewClient(k8sClient, interceptor.Funcs{
+				Create: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if lease, isLease := obj.(*coordinationv1.Lease); isLease && *lease.Spec.HolderIdentity == "test-ns-1/test-rm-1" {
+						racingLease := lease.DeepCopy()
+						setClusterScopedLockHolder(racingLease, rolloutsManager2)
+						Expect(client.Create(ctx, racingLease)).To(Succeed())
+					}
+					return client.Create(ctx, obj, opts...)
+				},
+			})
+
+			_, err := r.checkForExistingRolloutManager(ctx, rolloutsManager)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(MultipleClusterScopedRolloutManagersMessage(types.NamespacedName{Namespace: "test-ns-2", Name: "test-rm-2"})))
+
+			rr, err := r.checkForExistingRolloutManager(ctx, rolloutsManager2)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(rr).To(BeNil())
 		})
@@ -301,6 +420,27 @@ var _ = Describe("combineStringMaps tests", func() {
 	)
 })
 
+var _ = Describe("applyImageRegistryMirror tests", func() {
+
+	AfterEach(func() {
+		os.Unsetenv(DefaultImageRegistryMirrorEnvName)
+	})
+
+	DescribeTable("should replace the registry of the image with the mirror", func(mirror string, image string, expectedImage string) {
+		if mirror != "" {
+			os.Setenv(DefaultImageRegistryMirrorEnvName, mirror)
+		}
+		Expect(applyImageRegistryMirror(image)).To(Equal(expectedImage))
+	},
+		Entry("mirror is not set", "", "quay.io/argoproj/argo-rollouts", "quay.io/argoproj/argo-rollouts"),
+		Entry("image with a registry", "mirror.example.com", "quay.io/argoproj/argo-rollouts", "mirror.example.com/argoproj/argo-rollouts"),
+		Entry("mirror with a path and a trailing slash", "mirror.example.com/quay/", "quay.io/argoproj/argo-rollouts", "mirror.example.com/quay/argoproj/argo-rollouts"),
+		Entry("image with a registry port", "mirror.example.com", "registry.local:5000/argoproj/argo-rollouts", "mirror.example.com/argoproj/argo-rollouts"),
+		Entry("image with a localhost registry", "mirror.example.com", "localhost/argo-rollouts", "mirror.example.com/argo-rollouts"),
+		Entry("Docker Hub image, without a registry", "mirror.example.com", "argoproj/argo-rollouts", "mirror.example.com/argoproj/argo-rollouts"),
+	)
+})
+
 var _ = Describe("validateRolloutsScope tests", func() {
 
 	var (
@@ -553,6 +693,37 @@ var _ = Describe("insertOrUpdateConditionsInSlice tests", func() {
 		})
 	})
 
+	Context("when the condition exists, and only its observed generation is changed", func() {
+		It("should update the observed generation without changing the last transition time, and return true", func() {
+			lastTransitionTime := metav1.NewTime(metav1.Now().Add(-time.Hour)).Rfc3339Copy()
+			existingConditions = []metav1.Condition{
+				{
+					Type:               rolloutsmanagerv1alpha1.RolloutManagerConditionType,
+					Status:             metav1.ConditionTrue,
+					Reason:             "test reason",
+					Message:            "test message",
+					ObservedGeneration: 1,
+					LastTransitionTime: lastTransitionTime,
+				},
+			}
+			newCondition = metav1.Condition{
+				Type:               rolloutsmanagerv1alpha1.RolloutManagerConditionType,
+				Status:             metav1.ConditionTrue,
+				Reason:             "test reason",
+				Message:            "test message",
+				ObservedGeneration: 2,
+			}
+			changed, conditions := insertOrUpdateConditionsInSlice(newCondition, existingConditions)
+			Expect(changed).To(BeTrue())
+			Expect(conditions).To(HaveLen(1))
+			Expect(conditions[0].ObservedGeneration).To(Equal(int64(2)))
+			Expect(conditions[0].LastTransitionTime).To(Equal(lastTransitionTime))
+
+			changed, _ = insertOrUpdateConditionsInSlice(newCondition, conditions)
+			Expect(changed).To(BeFalse())
+		})
+	})
+
 	Context("when there is another unrelated condition in the list", func() {
 		It("should not remove the unrelated condition and return true", func() {
 			newCondition := metav1.Condition{
@@ -588,6 +759,27 @@ var _ = Describe("insertOrUpdateConditionsInSlice tests", func() {
 
 })
 
+var _ = Describe("removeConditionFromSlice tests", func() {
+	It("should remove only the condition of the given type, and return true if it was present", func() {
+		conditions := []metav1.Condition{
+			{Type: rolloutsmanagerv1alpha1.RolloutManagerConditionType},
+			{Type: rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType},
+			{Type: rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType},
+		}
+
+		removed, res := removeConditionFromSlice(rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType, conditions)
+		Expect(removed).To(BeTrue())
+		Expect(res).To(Equal([]metav1.Condition{
+			{Type: rolloutsmanagerv1alpha1.RolloutManagerConditionType},
+			{Type: rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType},
+		}))
+
+		removed, res = removeConditionFromSlice(rolloutsmanagerv1alpha1.RolloutManagerStalledConditionType, res)
+		Expect(removed).To(BeFalse())
+		Expect(res).To(HaveLen(2))
+	})
+})
+
 var _ = Describe("isMergable tests", func() {
 	DescribeTable("checking for duplicate arguments", func(extraArgs, cmd []string, expectedErr bool) {
 		err := isMergable(extraArgs, cmd)
@@ -626,7 +818,17 @@ var _ = Describe("setAdditionalRolloutsLabelsAndAnnotationsToObject tests", func
 	})
 
 	Context("when AdditionalMetadata is nil", func() {
-		It("should not modify labels and annotations", func() {
+		It("should only add the recommended labels", func() {
+			setAdditionalRolloutsLabelsAndAnnotationsToObject(obj, cr)
+			Expect(obj.Labels).To(Equal(map[string]string{
+				ManagedByLabel: ManagedByLabelValue,
+				VersionLabel:   DefaultArgoRolloutsVersion,
+			}))
+			Expect(obj.Annotations).To(BeNil())
+		})
+
+		It("should not modify labels and annotations if the recommended labels are skipped", func() {
+			cr.Spec.SkipRecommendedLabels = true
 			setAdditionalRolloutsLabelsAndAnnotationsToObject(obj, cr)
 			Expect(obj.Labels).To(BeNil())
 			Expect(obj.Annotations).To(BeNil())
@@ -777,3 +979,70 @@ func createNamespace(r *RolloutManagerReconciler, n string) error {
 	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: n}}
 	return r.Client.Create(context.Background(), ns)
 }
+
+var _ = Describe("fetchObjectMetadata tests", func() {
+	It("should retrieve only the metadata of an object, and return NotFound if it does not exist", func() {
+		ctx := context.Background()
+		r := makeTestReconciler()
+
+		crd := &crdv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{
+			Name:   serviceMonitorsCRDName,
+			Labels: map[string]string{"my-key": "my-value"},
+		}}
+		Expect(r.Client.Create(ctx, crd)).To(Succeed())
+
+		metadata, err := fetchObjectMetadata(ctx, r.Client, customResourceDefinitionGVK, "", serviceMonitorsCRDName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metadata.Name).To(Equal(serviceMonitorsCRDName))
+		Expect(metadata.Labels).To(Equal(crd.Labels))
+
+		_, err = fetchObjectMetadata(ctx, r.Client, customResourceDefinitionGVK, "", "does-not-exist.example.com")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("hasExpectedLabelsAndAnnotations tests", func() {
+
+	expected := metav1.ObjectMeta{
+		Labels:      map[string]string{"app.kubernetes.io/name": DefaultArgoRolloutsResourceName},
+		Annotations: map[string]string{},
+	}
+
+	DescribeTable("test hasExpectedLabelsAndAnnotations", func(live metav1.ObjectMeta, expectedResult bool) {
+		Expect(hasExpectedLabelsAndAnnotations(live, expected)).To(Equal(expectedResult))
+	},
+		Entry("live object has the expected labels, and nil annotations", metav1.ObjectMeta{
+			Labels: map[string]string{"app.kubernetes.io/name": DefaultArgoRolloutsResourceName},
+		}, true),
+		Entry("live object has additional user labels and annotations", metav1.ObjectMeta{
+			Labels:      map[string]string{"app.kubernetes.io/name": DefaultArgoRolloutsResourceName, "my-key": "my-value"},
+			Annotations: map[string]string{"my-annotation": "my-value", SpecHashAnnotation: "1234"},
+		}, true),
+		Entry("live object has an expected label with a different value", metav1.ObjectMeta{
+			Labels: map[string]string{"app.kubernetes.io/name": "something-else"},
+		}, false),
+		Entry("live object is missing an expected label", metav1.ObjectMeta{}, false),
+	)
+})
+
+var _ = Describe("spec hash tests", func() {
+
+	It("should only match the hash of the same expected state", func() {
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: DefaultArgoRolloutsResourceName, Namespace: "test-ns"}}
+		setRolloutsLabelsAndAnnotations(&sa.ObjectMeta)
+
+		hash := computeSpecHash(sa)
+		Expect(hash).ToNot(BeEmpty())
+		Expect(computeSpecHash(sa.DeepCopy())).To(Equal(hash))
+
+		Expect(specHashMatches(sa.ObjectMeta, hash)).To(BeFalse())
+		setSpecHashAnnotation(&sa.ObjectMeta, hash)
+		Expect(specHashMatches(sa.ObjectMeta, hash)).To(BeTrue())
+
+		changedSA := sa.DeepCopy()
+		changedSA.Labels["my-key"] = "my-value"
+		Expect(computeSpecHash(changedSA)).ToNot(Equal(hash))
+
+		Expect(specHashMatches(metav1.ObjectMeta{}, "")).To(BeFalse())
+	})
+})
@@ -0,0 +1,281 @@
+package rollouts
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	verticalPodAutoscalersCRDName = "verticalpodautoscalers.autoscaling.k8s.io"
+
+	// VPAUpdateModeOff is the VerticalPodAutoscaler update mode in which recommendations are computed, but never applied to the pods
+	VPAUpdateModeOff = "Off"
+
+	// DefaultVPAUpdateMode is the update mode of the VerticalPodAutoscaler, if none is specified in the RolloutManager
+	DefaultVPAUpdateMode = "Auto"
+)
+
+// verticalPodAutoscalerGVK is the GroupVersionKind of the VerticalPodAutoscaler. The VerticalPodAutoscaler is an optional add-on, so it is handled as an unstructured object.
+var verticalPodAutoscalerGVK = schema.GroupVersionKind{
+	Group:   "autoscaling.k8s.io",
+	Version: "v1",
+	Kind:    "VerticalPodAutoscaler",
+}
+
+// isVPAEnabled returns true if the RolloutManager requests a VerticalPodAutoscaler for the Rollouts controller.
+func isVPAEnabled(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.VPA != nil && cr.Spec.VPA.Enabled
+}
+
+// getVPAUpdateMode returns the update mode of the VerticalPodAutoscaler of the Rollouts controller.
+func getVPAUpdateMode(cr rolloutsmanagerv1alpha1.RolloutManager) string {
+	if cr.Spec.VPA == nil || cr.Spec.VPA.Mode == "" {
+		return DefaultVPAUpdateMode
+	}
+	return cr.Spec.VPA.Mode
+}
+
+// isVPAManagingResources returns true if the resource requests/limits of the Rollouts controller are updated by a VerticalPodAutoscaler.
+func isVPAManagingResources(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return isVPAEnabled(cr) && getVPAUpdateMode(cr) != VPAUpdateModeOff
+}
+
+func newVerticalPodAutoscaler() *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(verticalPodAutoscalerGVK)
+	return vpa
+}
+
+// generateDesiredVerticalPodAutoscaler returns the VerticalPodAutoscaler that targets the Rollouts controller Deployment.
+func generateDesiredVerticalPodAutoscaler(cr rolloutsmanagerv1alpha1.RolloutManager) *unstructured.Unstructured {
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      DefaultArgoRolloutsResourceName,
+		Namespace: cr.Namespace,
+	}
+	setRolloutsLabelsAndAnnotationsToObject(&objectMeta, cr)
+
+	vpa := newVerticalPodAutoscaler()
+	vpa.SetName(objectMeta.Name)
+	vpa.SetNamespace(objectMeta.Namespace)
+	vpa.SetLabels(objectMeta.Labels)
+	vpa.SetAnnotations(objectMeta.Annotations)
+
+	vpa.Object["spec"] = map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"name":       DefaultArgoRolloutsResourceName,
+		},
+		"updatePolicy": map[string]interface{}{
+			"updateMode": getVPAUpdateMode(cr),
+		},
+	}
+
+	return vpa
+}
+
+// isVerticalPodAutoscalerInstalled returns true if the VerticalPodAutoscaler is installed on the cluster, by checking CustomResourceDefinition for VerticalPodAutoscaler (or, if the cluster API is disabled, by listing VerticalPodAutoscalers in the namespace)
+func (r *RolloutManagerReconciler) isVerticalPodAutoscalerInstalled(ctx context.Context, namespace string) (bool, error) {
+	if r.clusterAPIDisabled() {
+		vpaList := &unstructured.UnstructuredList{}
+		vpaList.SetGroupVersionKind(verticalPodAutoscalerGVK.GroupVersion().WithKind(verticalPodAutoscalerGVK.Kind + "List"))
+		served, err := r.isAPIServedInNamespace(ctx, vpaList, namespace)
+		if err != nil {
+			return false, fmt.Errorf("failed to list VerticalPodAutoscalers in namespace %s: %w", namespace, err)
+		}
+		return served, nil
+	}
+
+	if _, err := fetchObjectMetadata(ctx, r.Client, customResourceDefinitionGVK, "", verticalPodAutoscalersCRDName); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get the CustomResourceDefinition %s: %w", verticalPodAutoscalersCRDName, err)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// reconcileRolloutsVerticalPodAutoscaler creates/updates the VerticalPodAutoscaler of the Rollouts controller if it is enabled, and deletes it otherwise. Only the VerticalPodAutoscaler owned by the RolloutManager is updated or deleted.
+func (r *RolloutManagerReconciler) reconcileRolloutsVerticalPodAutoscaler(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) error {
+
+	installed, err := r.isVerticalPodAutoscalerInstalled(ctx, cr.Namespace)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		if isVPAEnabled(cr) {
+			log.Info("VerticalPodAutoscaler is enabled, but the VerticalPodAutoscaler CRD is not installed on the cluster: skipping creation of VerticalPodAutoscaler")
+		}
+		return nil
+	}
+
+	liveVPA := newVerticalPodAutoscaler()
+	liveVPAExists := true
+	if err := fetchObject(ctx, r.Client, cr.Namespace, DefaultArgoRolloutsResourceName, liveVPA); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the VerticalPodAutoscaler %s: %w", DefaultArgoRolloutsResourceName, err)
+		}
+		liveVPAExists = false
+	}
+
+	// A VerticalPodAutoscaler of the same name which was not created by the operator (see getControllerResourcesRecommendation) is neither updated, nor deleted
+	if liveVPAExists && !metav1.IsControlledBy(liveVPA, &cr) {
+		if isVPAEnabled(cr) {
+			log.Info(fmt.Sprintf("VerticalPodAutoscaler %s already exists, and is not managed by the operator: skipping its reconciliation", liveVPA.GetName()))
+		}
+		return nil
+	}
+
+	if !isVPAEnabled(cr) {
+		if liveVPAExists {
+			log.Info(fmt.Sprintf("Deleting VerticalPodAutoscaler %s, as it is no longer enabled", liveVPA.GetName()))
+			if err := r.Client.Delete(ctx, liveVPA); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete the VerticalPodAutoscaler %s: %w", liveVPA.GetName(), err)
+			}
+		}
+		return nil
+	}
+
+	expectedVPA := generateDesiredVerticalPodAutoscaler(cr)
+
+	if !liveVPAExists {
+		if err := r.setControllerReference(&cr, expectedVPA); err != nil {
+			return err
+		}
+
+		log.Info(fmt.Sprintf("Creating VerticalPodAutoscaler %s", expectedVPA.GetName()))
+		return r.Client.Create(ctx, expectedVPA)
+	}
+
+	if !reflect.DeepEqual(liveVPA.Object["spec"], expectedVPA.Object["spec"]) {
+		log.Info(fmt.Sprintf("Spec of VerticalPodAutoscaler %s does not match the expected state, hence updating it", liveVPA.GetName()))
+		liveVPA.Object["spec"] = expectedVPA.Object["spec"]
+		return r.Client.Update(ctx, liveVPA)
+	}
+
+	return nil
+}
+
+// isVPARecommendationReported returns true if the RolloutManager requests the recommendation of the VerticalPodAutoscaler of the Rollouts controller in its status.
+func isVPARecommendationReported(cr rolloutsmanagerv1alpha1.RolloutManager) bool {
+	return cr.Spec.VPA != nil && cr.Spec.VPA.ReportRecommendation
+}
+
+// getControllerResourcesRecommendation returns the resource recommendation for the Rollouts controller container of the VerticalPodAutoscaler which targets the Rollouts controller Deployment, whether or not it was created by the operator.
+// An empty recommendation is returned if it is not requested, or not available: in that case, it is removed from the status of the RolloutManager.
+func (r *RolloutManagerReconciler) getControllerResourcesRecommendation(ctx context.Context, cr rolloutsmanagerv1alpha1.RolloutManager) (*rolloutsmanagerv1alpha1.RolloutManagerResourcesRecommendation, error) {
+
+	if !isVPARecommendationReported(cr) {
+		return &rolloutsmanagerv1alpha1.RolloutManagerResourcesRecommendation{}, nil
+	}
+
+	installed, err := r.isVerticalPodAutoscalerInstalled(ctx, cr.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !installed {
+		log.Info("reporting of the VerticalPodAutoscaler recommendation is enabled, but the VerticalPodAutoscaler CRD is not installed on the cluster")
+		return &rolloutsmanagerv1alpha1.RolloutManagerResourcesRecommendation{}, nil
+	}
+
+	vpaList := &unstructured.UnstructuredList{}
+	vpaList.SetGroupVersionKind(verticalPodAutoscalerGVK.GroupVersion().WithKind(verticalPodAutoscalerGVK.Kind + "List"))
+	if err := r.Client.List(ctx, vpaList, client.InNamespace(cr.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list VerticalPodAutoscalers in namespace %s: %w", cr.Namespace, err)
+	}
+
+	// Sort by name, so that the same VerticalPodAutoscaler is reported if there are several
+	sort.Slice(vpaList.Items, func(i, j int) bool {
+		return vpaList.Items[i].GetName() < vpaList.Items[j].GetName()
+	})
+
+	for _, vpa := range vpaList.Items {
+		targetKind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+		targetName, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+		if targetKind != "Deployment" || targetName != DefaultArgoRolloutsResourceName {
+			continue
+		}
+
+		recommendation, err := getContainerRecommendation(vpa, getRolloutsContainerName(cr))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the recommendation of VerticalPodAutoscaler %s: %w", vpa.GetName(), err)
+		}
+		if recommendation != nil {
+			return recommendation, nil
+		}
+	}
+
+	return &rolloutsmanagerv1alpha1.RolloutManagerResourcesRecommendation{}, nil
+}
+
+// getContainerRecommendation returns the recommendation of the VerticalPodAutoscaler for the given container, from its .status.recommendation, or nil if there is none.
+func getContainerRecommendation(vpa unstructured.Unstructured, containerName string) (*rolloutsmanagerv1alpha1.RolloutManagerResourcesRecommendation, error) {
+
+	containerRecommendations, _, err := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range containerRecommendations {
+		containerRecommendation, ok := item.(map[string]interface{})
+		if !ok || containerRecommendation["containerName"] != containerName {
+			continue
+		}
+
+		res := &rolloutsmanagerv1alpha1.RolloutManagerResourcesRecommendation{VerticalPodAutoscaler: vpa.GetName()}
+		for field, resourceList := range map[string]*corev1.ResourceList{
+			"target":     &res.Target,
+			"lowerBound": &res.LowerBound,
+			"upperBound": &res.UpperBound,
+		} {
+			values, _, err := unstructured.NestedStringMap(containerRecommendation, field)
+			if err != nil {
+				return nil, err
+			}
+			for name, value := range values {
+				quantity, err := resource.ParseQuantity(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s of resource %s: %w", field, name, err)
+				}
+				if *resourceList == nil {
+					*resourceList = corev1.ResourceList{}
+				}
+				(*resourceList)[corev1.ResourceName(name)] = quantity
+			}
+		}
+		return res, nil
+	}
+
+	return nil, nil
+}
+
+// setControllerResourcesRecommendation sets the resource recommendation of the VerticalPodAutoscaler of the Rollouts controller on the status of the RolloutManager, or removes it if the recommendation is empty. It returns true if the status changed.
+func setControllerResourcesRecommendation(rm *rolloutsmanagerv1alpha1.RolloutManager, recommendation rolloutsmanagerv1alpha1.RolloutManagerResourcesRecommendation) bool {
+
+	if recommendation.VerticalPodAutoscaler == "" {
+		if rm.Status.ControllerResourcesRecommendation == nil {
+			return false
+		}
+		rm.Status.ControllerResourcesRecommendation = nil
+		return true
+	}
+
+	if rm.Status.ControllerResourcesRecommendation != nil && equality.Semantic.DeepEqual(*rm.Status.ControllerResourcesRecommendation, recommendation) {
+		return false
+	}
+	rm.Status.ControllerResourcesRecommendation = &recommendation
+	return true
+}
@@ -0,0 +1,241 @@
+package rollouts
+
+import (
+	"context"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("VerticalPodAutoscaler tests", func() {
+	var ctx context.Context
+	var a v1alpha1.RolloutManager
+	var r *RolloutManagerReconciler
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		a = *makeTestRolloutManager()
+		a.Spec.VPA = &v1alpha1.RolloutManagerVPASpec{
+			Enabled: true,
+		}
+
+		r = makeTestReconciler(&a)
+		Expect(createNamespace(r, a.Namespace)).To(Succeed())
+	})
+
+	getVPA := func() (*unstructured.Unstructured, error) {
+		vpa := newVerticalPodAutoscaler()
+		return vpa, fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, vpa)
+	}
+
+	It("should not create a VerticalPodAutoscaler if the VerticalPodAutoscaler CRD does not exist", func() {
+		Expect(r.reconcileRolloutsVerticalPodAutoscaler(ctx, a)).To(Succeed())
+
+		_, err := getVPA()
+		Expect(err).To(HaveOccurred())
+	})
+
+	When("the VerticalPodAutoscaler CRD exists", func() {
+
+		BeforeEach(func() {
+			vpaCRD := &crdv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: verticalPodAutoscalersCRDName,
+				},
+			}
+			Expect(r.Client.Create(ctx, vpaCRD)).To(Succeed())
+		})
+
+		It("should create a VerticalPodAutoscaler that targets the Rollouts controller Deployment, using the Auto mode by default", func() {
+			Expect(r.reconcileRolloutsVerticalPodAutoscaler(ctx, a)).To(Succeed())
+
+			vpa, err := getVPA()
+			Expect(err).ToNot(HaveOccurred())
+
+			targetRef, _, _ := unstructured.NestedStringMap(vpa.Object, "spec", "targetRef")
+			Expect(targetRef).To(Equal(map[string]string{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"name":       DefaultArgoRolloutsResourceName,
+			}))
+
+			updateMode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+			Expect(updateMode).To(Equal(DefaultVPAUpdateMode))
+
+			Expect(vpa.GetOwnerReferences()).To(HaveLen(1))
+			Expect(vpa.GetOwnerReferences()[0].Name).To(Equal(a.Name))
+		})
+
+		It("should update the VerticalPodAutoscaler when the mode is changed", func() {
+			Expect(r.reconcileRolloutsVerticalPodAutoscaler(ctx, a)).To(Succeed())
+
+			a.Spec.VPA.Mode = "Initial"
+			Expect(r.reconcileRolloutsVerticalPodAutoscaler(ctx, a)).To(Succeed())
+
+			vpa, err := getVPA()
+			Expect(err).ToNot(HaveOccurred())
+
+			updateMode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+			Expect(updateMode).To(Equal("Initial"))
+		})
+
+		It("should delete the VerticalPodAutoscaler when it is disabled", func() {
+			Expect(r.reconcileRolloutsVerticalPodAutoscaler(ctx, a)).To(Succeed())
+
+			a.Spec.VPA.Enabled = false
+			Expect(r.reconcileRolloutsVerticalPodAutoscaler(ctx, a)).To(Succeed())
+
+			_, err := getVPA()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should neither update, nor delete, a VerticalPodAutoscaler which was not created by the operator", func() {
+			By("creating a VerticalPodAutoscaler of the same name, as a user would")
+			userVPA := generateDesiredVerticalPodAutoscaler(a)
+			Expect(unstructured.SetNestedField(userVPA.Object, VPAUpdateModeOff, "spec", "updatePolicy", "updateMode")).To(Succeed())
+			Expect(r.Client.Create(ctx, userVPA)).To(Succeed())
+
+			Expect(r.reconcileRolloutsVerticalPodAutoscaler(ctx, a)).To(Succeed())
+
+			vpa, err := getVPA()
+			Expect(err).ToNot(HaveOccurred())
+			updateMode, _, _ := unstructured.NestedString(vpa.Object, "spec", "updatePolicy", "updateMode")
+			Expect(updateMode).To(Equal(VPAUpdateModeOff))
+			Expect(vpa.GetOwnerReferences()).To(BeEmpty())
+
+			a.Spec.VPA.Enabled = false
+			Expect(r.reconcileRolloutsVerticalPodAutoscaler(ctx, a)).To(Succeed())
+
+			_, err = getVPA()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("reporting the recommendation of the VerticalPodAutoscaler", func() {
+
+			BeforeEach(func() {
+				a.Spec.VPA = &v1alpha1.RolloutManagerVPASpec{
+					ReportRecommendation: true,
+				}
+			})
+
+			createRecommendingVPA := func(name string, targetName string) {
+				vpa := newVerticalPodAutoscaler()
+				vpa.SetName(name)
+				vpa.SetNamespace(a.Namespace)
+				vpa.Object["spec"] = map[string]interface{}{
+					"targetRef": map[string]interface{}{
+						"apiVersion": "apps/v1",
+						"kind":       "Deployment",
+						"name":       targetName,
+					},
+				}
+				vpa.Object["status"] = map[string]interface{}{
+					"recommendation": map[string]interface{}{
+						"containerRecommendations": []interface{}{
+							map[string]interface{}{
+								"containerName": DefaultRolloutsContainerName,
+								"target":        map[string]interface{}{"cpu": "25m", "memory": "262144k"},
+								"lowerBound":    map[string]interface{}{"cpu": "15m", "memory": "131072k"},
+								"upperBound":    map[string]interface{}{"cpu": "100m", "memory": "500Mi"},
+							},
+						},
+					},
+				}
+				Expect(r.Client.Create(ctx, vpa)).To(Succeed())
+			}
+
+			It("should return the recommendation of a VerticalPodAutoscaler targeting the Rollouts controller Deployment, even if it is not created by the operator", func() {
+				createRecommendingVPA("other-deployment", "other")
+				createRecommendingVPA("my-vpa", DefaultArgoRolloutsResourceName)
+
+				recommendation, err := r.getControllerResourcesRecommendation(ctx, a)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(recommendation.VerticalPodAutoscaler).To(Equal("my-vpa"))
+				Expect(recommendation.Target).To(Equal(corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("25m"),
+					corev1.ResourceMemory: resource.MustParse("262144k"),
+				}))
+				Expect(recommendation.LowerBound.Cpu().String()).To(Equal("15m"))
+				Expect(recommendation.UpperBound.Memory().String()).To(Equal("500Mi"))
+
+				By("verifying that the recommendation is set on the status, and removed once it is no longer requested")
+				Expect(setControllerResourcesRecommendation(&a, *recommendation)).To(BeTrue())
+				Expect(setControllerResourcesRecommendation(&a, *recommendation)).To(BeFalse())
+				Expect(a.Status.ControllerResourcesRecommendation.VerticalPodAutoscaler).To(Equal("my-vpa"))
+
+				a.Spec.VPA.ReportRecommendation = false
+				recommendation, err = r.getControllerResourcesRecommendation(ctx, a)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(setControllerResourcesRecommendation(&a, *recommendation)).To(BeTrue())
+				Expect(a.Status.ControllerResourcesRecommendation).To(BeNil())
+			})
+
+			It("should return an empty recommendation if no VerticalPodAutoscaler has a recommendation for the Rollouts controller container", func() {
+				a.Spec.ContainerName = "rollouts-controller"
+				createRecommendingVPA("my-vpa", DefaultArgoRolloutsResourceName)
+
+				recommendation, err := r.getControllerResourcesRecommendation(ctx, a)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*recommendation).To(BeZero())
+			})
+		})
+	})
+
+	When("the resources of the Rollouts controller container are modified outside of the operator", func() {
+
+		var sa *corev1.ServiceAccount
+
+		BeforeEach(func() {
+			sa = &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      DefaultArgoRolloutsResourceName,
+					Namespace: a.Namespace,
+				},
+			}
+			Expect(r.Client.Create(ctx, sa)).To(Succeed())
+		})
+
+		modifyResources := func() corev1.ResourceRequirements {
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+
+			modifiedResources := corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("250m"),
+				},
+			}
+			deployment.Spec.Template.Spec.Containers[0].Resources = modifiedResources
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			Expect(r.reconcileRolloutsDeployment(ctx, a, *sa)).To(Succeed())
+			return modifiedResources
+		}
+
+		It("should not revert the resources while the VerticalPodAutoscaler manages them", func() {
+			modifiedResources := modifyResources()
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Resources).To(Equal(modifiedResources))
+		})
+
+		It("should revert the resources if the VerticalPodAutoscaler is in Off mode", func() {
+			a.Spec.VPA.Mode = VPAUpdateModeOff
+			modifyResources()
+
+			deployment := &appsv1.Deployment{}
+			Expect(fetchObject(ctx, r.Client, a.Namespace, DefaultArgoRolloutsResourceName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Resources).To(Equal(defaultRolloutsContainerResources()))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// rbac-diff generates the diff of the permissions granted by the Roles/ClusterRoles of the operator, between the previous release (as recorded in a snapshot) and the current code, so that security reviewers can see exactly which permissions changed.
+//
+// It is run by 'make rbac-diff' (see the go:generate directive in controllers/rbacdiff.go). When a release is cut, 'make rbac-diff-snapshot' records the permissions of the release as the snapshot of the previous release.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	rollouts "github.com/argoproj-labs/argo-rollouts-manager/controllers"
+)
+
+func main() {
+	previousPath := flag.String("previous", "", "path of the snapshot of the permissions of the previous release")
+	outputPath := flag.String("output", "", "path of the generated markdown diff of the permissions")
+	updateSnapshot := flag.Bool("update-snapshot", false, "replace the snapshot of the previous release with the permissions of the current code, instead of generating the diff")
+	flag.Parse()
+
+	if err := run(*previousPath, *outputPath, *updateSnapshot); err != nil {
+		fmt.Fprintln(os.Stderr, "rbac-diff:", err)
+		os.Exit(1)
+	}
+}
+
+func run(previousPath string, outputPath string, updateSnapshot bool) error {
+
+	if previousPath == "" {
+		return fmt.Errorf("-previous is required")
+	}
+
+	current := map[string][]string{}
+	for name, rules := range rollouts.GetRBACPolicyRules() {
+		current[name] = rollouts.GetPolicyRulesPermissions(rules)
+	}
+
+	if updateSnapshot {
+		data, err := json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(previousPath, append(data, '\n'), 0644)
+	}
+
+	if outputPath == "" {
+		return fmt.Errorf("-output is required, unless -update-snapshot is set")
+	}
+
+	data, err := os.ReadFile(previousPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the snapshot of the previous release: %w", err)
+	}
+	previous := map[string][]string{}
+	if err := json.Unmarshal(data, &previous); err != nil {
+		return fmt.Errorf("failed to parse the snapshot of the previous release %s: %w", previousPath, err)
+	}
+
+	return os.WriteFile(outputPath, []byte(renderDiff(previous, current)), 0644)
+}
+
+// renderDiff returns the markdown document which lists the permissions that were added and removed, for each Role/ClusterRole.
+func renderDiff(previous map[string][]string, current map[string][]string) string {
+
+	names := []string{}
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("<!-- Generated by 'make rbac-diff': DO NOT EDIT. -->\n\n")
+	sb.WriteString("# RBAC changes since the previous release\n\n")
+	sb.WriteString("The permissions granted by the Roles/ClusterRoles that the operator creates, which were added or removed since the previous release of the operator. ")
+	sb.WriteString("After an upgrade, the operator also reports the changes that it applied on the cluster with the `RBACRulesChanged` condition of each RolloutManager (see [RBAC changes](usage/getting_started.md#rbac-changes)).\n")
+
+	changed := false
+	for _, name := range names {
+		added, removed := rollouts.DiffPermissions(previous[name], current[name])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		changed = true
+
+		fmt.Fprintf(&sb, "\n## %s\n", name)
+		if _, ok := previous[name]; !ok {
+			sb.WriteString("\nThis Role/ClusterRole is new.\n")
+		} else if _, ok := current[name]; !ok {
+			sb.WriteString("\nThis Role/ClusterRole is no longer created.\n")
+		}
+		writePermissions(&sb, "Added", added)
+		writePermissions(&sb, "Removed", removed)
+	}
+
+	if !changed {
+		sb.WriteString("\nThe permissions are unchanged.\n")
+	}
+
+	return sb.String()
+}
+
+func writePermissions(sb *strings.Builder, title string, permissions []string) {
+	if len(permissions) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n%s:\n\n", title)
+	for _, permission := range permissions {
+		fmt.Fprintf(sb, "- `%s`\n", permission)
+	}
+}
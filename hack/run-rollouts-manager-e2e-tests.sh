@@ -21,7 +21,16 @@ set -ex
 
 if [ "$NAMESPACE_SCOPED_ARGO_ROLLOUTS" == "true" ]; then
 
-  go test -v -p=1 -timeout=30m -race -count=1 -coverprofile=coverage.out ./tests/e2e/namespace-scoped
+  if [ -n "$E2E_PARALLEL_PROCS" ]; then
+
+    # Run the tests with multiple Ginkgo parallel processes: each process uses its own namespaces (see fixture.NamespaceName)
+    go run github.com/onsi/ginkgo/v2/ginkgo -v --procs="$E2E_PARALLEL_PROCS" --timeout=30m --race --coverprofile=coverage.out ./tests/e2e/namespace-scoped
+
+  else
+
+    go test -v -p=1 -timeout=30m -race -count=1 -coverprofile=coverage.out ./tests/e2e/namespace-scoped
+
+  fi
 
 else
 
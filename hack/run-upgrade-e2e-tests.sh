@@ -0,0 +1,47 @@
+#!/bin/bash
+
+# Runs the upgrade e2e tests (tests/e2e/upgrade):
+# - installs the previous version of the operator on the cluster, from its manifests
+# - runs the tests, which create RolloutManagers and then install the current version of the operator
+#
+# Environment variables:
+# - PREVIOUS_VERSION (required): the git ref (e.g. a release tag) of the previous version of the operator
+# - PREVIOUS_IMG: the image of the previous version of the operator (defaults to quay.io/argoprojlabs/argo-rollouts-manager:$PREVIOUS_VERSION)
+# - IMG (required): the image of the current version of the operator, which must have been built and pushed (e.g. via 'make docker-build docker-push')
+
+SCRIPTPATH="$(
+  cd -- "$(dirname "$0")" >/dev/null 2>&1 || exit
+  pwd -P
+)"
+
+cd "$SCRIPTPATH/.."
+
+set -o pipefail
+
+if [ -z "$PREVIOUS_VERSION" ]; then
+  echo "PREVIOUS_VERSION must be set to the git ref of the previous version of the operator"
+  exit 1
+fi
+
+if [ -z "$IMG" ]; then
+  echo "IMG must be set to the image of the current version of the operator"
+  exit 1
+fi
+
+PREVIOUS_IMG=${PREVIOUS_IMG:-quay.io/argoprojlabs/argo-rollouts-manager:$PREVIOUS_VERSION}
+
+set -ex
+
+PREVIOUS_VERSION_DIR=$(mktemp -d)
+trap 'rm -rf "$PREVIOUS_VERSION_DIR"' EXIT
+
+# Install the previous version of the operator, using the manifests of that version
+git clone --depth 1 --branch "$PREVIOUS_VERSION" https://github.com/argoproj-labs/argo-rollouts-manager "$PREVIOUS_VERSION_DIR"
+make -C "$PREVIOUS_VERSION_DIR" deploy IMG="$PREVIOUS_IMG"
+
+kubectl rollout status -n argo-rollouts-manager-system deployment/argo-rollouts-manager-controller-manager --timeout=5m
+
+# The tests install the current version of the operator, once the RolloutManagers have been reconciled by the previous version
+export E2E_UPGRADE_COMMAND="make deploy IMG=$IMG"
+
+go test -v -p=1 -timeout=30m -count=1 ./tests/e2e/upgrade
@@ -0,0 +1,186 @@
+package declarative
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	rmv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	controllers "github.com/argoproj-labs/argo-rollouts-manager/controllers"
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
+	rmFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/rolloutmanager"
+)
+
+const (
+	// DeclarativeTestsClusterEnv should be set to 'true' to run the scenarios against the cluster of the current kubeconfig, with the operator already running (e.g. via 'make start-e2e-namespace-scoped').
+	// Otherwise, the scenarios are run against envtest (which requires KUBEBUILDER_ASSETS, see 'make test-declarative'), with the RolloutManager controller running in this process.
+	DeclarativeTestsClusterEnv = "DECLARATIVE_TESTS_CLUSTER"
+
+	scenariosDir = "scenarios"
+
+	rolloutManagerName = "declarative-rollouts-manager"
+)
+
+var _ = Describe("Declarative RolloutManager scenarios", Ordered, func() {
+
+	var (
+		ctx       context.Context
+		cancel    context.CancelFunc
+		k8sClient client.Client
+		testEnv   *envtest.Environment
+		isCluster bool
+	)
+
+	// Invalid scenarios are reported by the harness tests
+	scenarios, _ := LoadScenarios(scenariosDir)
+
+	BeforeAll(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+
+		isCluster = os.Getenv(DeclarativeTestsClusterEnv) == "true"
+
+		if isCluster {
+			var err error
+			k8sClient, _, err = fixture.GetE2ETestKubeClient()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fixture.EnsureCleanSlate()).To(Succeed())
+			return
+		}
+
+		if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+			Skip("KUBEBUILDER_ASSETS is not set: run the declarative scenarios via 'make test-declarative', or set " + DeclarativeTestsClusterEnv + "=true to run them against a cluster")
+		}
+
+		k8sClient, testEnv = startEnvtest(ctx)
+	})
+
+	AfterAll(func() {
+		if cancel != nil {
+			cancel()
+		}
+		if testEnv != nil {
+			Expect(testEnv.Stop()).To(Succeed())
+		}
+	})
+
+	for _, scenario := range scenarios {
+
+		scenario := scenario
+
+		It(scenario.ID()+": "+scenario.Name, func() {
+
+			namespace := fixture.NamespaceName("declarative-" + scenario.ID())
+
+			By("creating the namespace " + namespace)
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: fixture.TestNamespaceLabels(),
+			}})).To(Succeed())
+
+			rolloutManager := rmv1alpha1.RolloutManager{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      rolloutManagerName,
+					Namespace: namespace,
+				},
+			}
+
+			for i, step := range scenario.Steps {
+
+				if i == 0 {
+					By("creating the RolloutManager")
+					rolloutManager.Spec = step.RolloutManager
+					Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+				} else {
+					By("updating the RolloutManager, for step " + stepName(i))
+					Eventually(func() error {
+						if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutManager), &rolloutManager); err != nil {
+							return err
+						}
+						rolloutManager.Spec = step.RolloutManager
+						return k8sClient.Update(ctx, &rolloutManager)
+					}, "30s", "1s").Should(Succeed())
+				}
+
+				if step.Phase != "" && isCluster {
+					Eventually(rolloutManager, "3m", "1s").Should(rmFixture.HavePhase(step.Phase))
+				}
+
+				for _, expected := range step.Assert {
+					expected := expected
+					Eventually(func() error {
+						return AssertObject(ctx, k8sClient, expected, namespace)
+					}, "2m", "1s").Should(Succeed(), "step "+stepName(i))
+				}
+
+				for _, absent := range step.Errors {
+					absent := absent
+					Eventually(func() error {
+						return AssertObjectAbsent(ctx, k8sClient, absent, namespace)
+					}, "2m", "1s").Should(Succeed(), "step "+stepName(i))
+				}
+			}
+		})
+	}
+})
+
+// startEnvtest starts an API server via envtest, and the RolloutManager controller (in namespace-scoped mode) against it.
+func startEnvtest(ctx context.Context) (client.Client, *envtest.Environment) {
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	cfg, err := testEnv.Start()
+	Expect(err).ToNot(HaveOccurred())
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(rmv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(monitoringv1.AddToScheme(scheme))
+	utilruntime.Must(crdv1.AddToScheme(scheme))
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: server.Options{BindAddress: "0"},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect((&controllers.RolloutManagerReconciler{
+		Client:                                mgr.GetClient(),
+		Scheme:                                mgr.GetScheme(),
+		OpenShiftRoutePluginLocation:          controllers.DefaultOpenShiftRoutePluginURL,
+		NamespaceScopedArgoRolloutsController: true,
+	}).SetupWithManager(mgr)).To(Succeed())
+
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(ctx)).To(Succeed())
+	}()
+
+	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	Expect(err).ToNot(HaveOccurred())
+
+	return k8sClient, testEnv
+}
+
+func stepName(i int) string {
+	return strconv.Itoa(i + 1)
+}
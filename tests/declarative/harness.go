@@ -0,0 +1,186 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	rmv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Scenario is a declarative test of the RolloutManager: a RolloutManager is created (and then updated) with the .spec of each step, and the resources of the step are asserted after each of them.
+// Scenarios are read from the YAML files of the scenarios directory: see docs/e2e-tests/usage.md for the format.
+type Scenario struct {
+	// Name describes the scenario
+	Name string `json:"name"`
+
+	// Steps are run in order, against the same RolloutManager
+	Steps []ScenarioStep `json:"steps"`
+
+	// file is the path of the file that the scenario was read from
+	file string
+}
+
+// ScenarioStep is a step of a Scenario.
+type ScenarioStep struct {
+	// RolloutManager is the .spec of the RolloutManager for this step. It replaces the .spec of the previous step.
+	RolloutManager rmv1alpha1.RolloutManagerSpec `json:"rolloutManager"`
+
+	// Phase is the expected .status.phase of the RolloutManager. It is only asserted against real clusters: the Rollouts controller Pods are never started by envtest.
+	Phase rmv1alpha1.RolloutControllerPhase `json:"phase,omitempty"`
+
+	// Assert contains (partial) objects which should exist, and contain at least the given fields.
+	// The namespace of namespaced objects defaults to the namespace of the RolloutManager.
+	Assert []map[string]interface{} `json:"assert,omitempty"`
+
+	// Errors contains objects which should not exist.
+	Errors []map[string]interface{} `json:"errors,omitempty"`
+}
+
+// LoadScenarios reads all of the scenarios of the given directory, sorted by file name.
+func LoadScenarios(dir string) ([]Scenario, error) {
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var res []Scenario
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var scenario Scenario
+		if err := yaml.UnmarshalStrict(data, &scenario); err != nil {
+			return nil, fmt.Errorf("unable to parse scenario %s: %w", file, err)
+		}
+		if len(scenario.Steps) == 0 {
+			return nil, fmt.Errorf("scenario %s has no steps", file)
+		}
+		scenario.file = file
+		res = append(res, scenario)
+	}
+	return res, nil
+}
+
+// ID returns a unique identifier for the scenario, based on its file name, which is suitable as a namespace name.
+func (s Scenario) ID() string {
+	return strings.TrimSuffix(filepath.Base(s.file), filepath.Ext(s.file))
+}
+
+// toUnstructured converts a (partial) object of a scenario to an Unstructured, defaulting the namespace of namespaced objects.
+func toUnstructured(k8sClient client.Client, obj map[string]interface{}, namespace string) (*unstructured.Unstructured, error) {
+
+	res := &unstructured.Unstructured{Object: obj}
+	if res.GetAPIVersion() == "" || res.GetKind() == "" || res.GetName() == "" {
+		return nil, fmt.Errorf("apiVersion, kind and metadata.name are required: %v", obj)
+	}
+
+	if res.GetNamespace() == "" {
+		namespaced, err := k8sClient.IsObjectNamespaced(res)
+		if err != nil {
+			return nil, err
+		}
+		if namespaced {
+			res = res.DeepCopy()
+			res.SetNamespace(namespace)
+		}
+	}
+	return res, nil
+}
+
+// AssertObject verifies that the given (partial) object exists, and that it contains at least the given fields.
+func AssertObject(ctx context.Context, k8sClient client.Client, expected map[string]interface{}, namespace string) error {
+
+	expectedObj, err := toUnstructured(k8sClient, expected, namespace)
+	if err != nil {
+		return err
+	}
+
+	actualObj := &unstructured.Unstructured{}
+	actualObj.SetGroupVersionKind(expectedObj.GroupVersionKind())
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(expectedObj), actualObj); err != nil {
+		return fmt.Errorf("unable to get %s %s: %w", expectedObj.GetKind(), expectedObj.GetName(), err)
+	}
+
+	if err := isSubset(expectedObj.Object, actualObj.Object, ""); err != nil {
+		return fmt.Errorf("%s %s does not match: %w", expectedObj.GetKind(), expectedObj.GetName(), err)
+	}
+	return nil
+}
+
+// AssertObjectAbsent verifies that the given object does not exist.
+func AssertObjectAbsent(ctx context.Context, k8sClient client.Client, expected map[string]interface{}, namespace string) error {
+
+	expectedObj, err := toUnstructured(k8sClient, expected, namespace)
+	if err != nil {
+		return err
+	}
+
+	actualObj := &unstructured.Unstructured{}
+	actualObj.SetGroupVersionKind(expectedObj.GroupVersionKind())
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(expectedObj), actualObj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return fmt.Errorf("%s %s should not exist", expectedObj.GetKind(), expectedObj.GetName())
+}
+
+// isSubset returns nil if 'expected' is a subset of 'actual': maps should contain at least the expected keys, and lists should have the same length, with each element being a subset of the actual element.
+// This is the same matching that is used by kuttl asserts.
+func isSubset(expected, actual interface{}, path string) error {
+
+	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+		// Numbers may be decoded with different types (e.g. int64 and float64)
+		if fmt.Sprint(expected) == fmt.Sprint(actual) {
+			return nil
+		}
+		return fmt.Errorf("%s: expected %v, but was %v", path, expected, actual)
+	}
+
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue := actual.(map[string]interface{})
+		for key, value := range expectedValue {
+			actualElement, exists := actualValue[key]
+			if !exists {
+				return fmt.Errorf("%s.%s: expected %v, but was not set", path, key, value)
+			}
+			if err := isSubset(value, actualElement, path+"."+key); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case []interface{}:
+		actualValue := actual.([]interface{})
+		if len(expectedValue) != len(actualValue) {
+			return fmt.Errorf("%s: expected %d elements, but was %d: %v", path, len(expectedValue), len(actualValue), actualValue)
+		}
+		for i := range expectedValue {
+			if err := isSubset(expectedValue[i], actualValue[i], fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("%s: expected %v, but was %v", path, expected, actual)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package declarative
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Declarative harness tests", func() {
+
+	It("should load all of the scenarios", func() {
+		scenarios, err := LoadScenarios(scenariosDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scenarios).ToNot(BeEmpty())
+
+		ids := map[string]bool{}
+		for _, scenario := range scenarios {
+			Expect(scenario.Name).ToNot(BeEmpty())
+			Expect(ids).ToNot(HaveKey(scenario.ID()), "scenario IDs should be unique")
+			ids[scenario.ID()] = true
+		}
+	})
+
+	DescribeTable("isSubset should match objects in the same way as kuttl", func(expected, actual string, match bool) {
+
+		var expectedObj, actualObj map[string]interface{}
+		Expect(yaml.Unmarshal([]byte(expected), &expectedObj)).To(Succeed())
+		Expect(yaml.Unmarshal([]byte(actual), &actualObj)).To(Succeed())
+
+		err := isSubset(expectedObj, actualObj, "")
+		if match {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("identical objects", "{a: 1, b: [x]}", "{a: 1, b: [x]}", true),
+		Entry("additional keys in the actual object", "{a: {b: 1}}", "{a: {b: 1, c: 2}, d: 3}", true),
+		Entry("missing key in the actual object", "{a: {b: 1, c: 2}}", "{a: {b: 1}}", false),
+		Entry("different value", "{a: x}", "{a: y}", false),
+		Entry("different type", "{a: '1'}", "{a: 1}", true),
+		Entry("list elements matched as subsets", "{a: [{name: x}]}", "{a: [{name: x, value: y}]}", true),
+		Entry("list of a different length", "{a: [x]}", "{a: [x, y]}", false),
+		Entry("list in a different order", "{a: [x, y]}", "{a: [y, x]}", false),
+	)
+})
@@ -20,13 +20,18 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-var _ = Describe("Cluster-scoped RolloutManager tests", func() {
+// Only a single cluster-scoped RolloutManager is supported on the cluster, and only in the namespaces of CLUSTER_SCOPED_ARGO_ROLLOUTS_NAMESPACES, so these tests cannot run in parallel.
+var _ = Describe("Cluster-scoped RolloutManager tests", Serial, func() {
 
 	// Add the tests which are designed to run in both cluster-scoped and namespace-scoped modes.
 	utils.RunRolloutsTests(false)
 
+	// Add the tests in which RolloutManagers of both scopes exist at the same time.
+	utils.RunCoexistenceTests(false)
+
 	Context("Testing cluster-scoped RolloutManager behaviour", func() {
 
 		// Use slightly different NodePorts from default, to avoid conflicting with any other Services using NodePorts on the cluster. This is not an issue when running E2E tests via GitHub actions, but is more likely to be an issue when running against, e.g. a large cluster like default OpenShift.
@@ -76,7 +81,7 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 					"foo-label2": "bar-label2",
 				},
 			}
-			rolloutsManager, err := utils.CreateRolloutManagerWithMetadata(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace, false, metadata)
+			rolloutsManager, err := utils.CreateRolloutManagerWithMetadata(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace(), false, metadata)
 			Expect(err).ToNot(HaveOccurred())
 
 			By("Verify that RolloutManager is successfully created.")
@@ -150,7 +155,7 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			nsName1, nsName2 := "test-rom-ns-1", "test-ro-ns-2"
 
 			By("1st RM: Create cluster-scoped RolloutManager in a namespace.")
-			rolloutsManagerCl, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace, false)
+			rolloutsManagerCl, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace(), false)
 			Expect(err).ToNot(HaveOccurred())
 
 			By("1st RM: Verify that RolloutManager is successfully created.")
@@ -160,7 +165,7 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			Eventually(rolloutsManagerCl, "1m", "1s").Should(rmFixture.HaveSuccessCondition())
 
 			By("1st RM: Create and validate Rollout in 1st namespace.")
-			utils.ValidateArgoRolloutsResources(ctx, k8sClient, fixture.TestE2ENamespace, testServiceNodePort_31000, testServiceNodePort_32000)
+			utils.ValidateArgoRolloutsResources(ctx, k8sClient, fixture.TestE2ENamespace(), testServiceNodePort_31000, testServiceNodePort_32000)
 
 			By("2nd RM: Create 2nd namespace.")
 			Expect(utils.CreateNamespace(ctx, k8sClient, nsName1)).To(Succeed())
@@ -173,7 +178,7 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			Eventually(rolloutsManagerNs, "1m", "1s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseFailure))
 
 			By("2nd RM: Verify that Status.Condition is having error message.")
-			Eventually(rolloutsManagerNs, "1m", "1s").Should(rmFixture.HaveCondition(
+			Eventually(rolloutsManagerNs, "1m", "1s").Should(rmFixture.HaveExactCondition(
 				metav1.Condition{
 					Type:    rmv1alpha1.RolloutManagerConditionType,
 					Status:  metav1.ConditionFalse,
@@ -212,16 +217,15 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			Now when another cluster-scoped RolloutManager is created in 2nd namespace, it should not be accepted by operator,
 			since there in an existing RolloutManager watching entire cluster,
 			but Rollouts controller of 1st namespace should still be able to reconcile Rollout CR of 2nd namespace and failed RolloutManager of 2nd namespace should not cause any issues.
-			When cluster-scoped RolloutManager is reconciled again it should also have error, because only one cluster-scoped or all namespace-scoped RolloutManagers are supported.
-			but Rollouts controller deployed in 1st namespace should still work and reconcile Rollout CR created in any namespace,
-			it means even both cluster-scoped RolloutManagers are having error in status, but Rollout controller created before error occurred should still work normally.
+			When 1st cluster-scoped RolloutManager is reconciled again it should still work, because it holds the cluster-scoped lock,
+			and Rollouts controller deployed in 1st namespace should still reconcile Rollout CR created in any namespace.
 		*/
 		It("After creating cluster-scoped RolloutManager in a namespace, another cluster-scoped RolloutManager should not be allowed.", func() {
 
 			nsName1, nsName2 := "test-rom-ns-1", "test-ro-ns-2"
 
 			By("1st RM: Create cluster-scoped RolloutManager in 1st namespace.")
-			rolloutsManagerCl, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace, false)
+			rolloutsManagerCl, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace(), false)
 			Expect(err).ToNot(HaveOccurred())
 
 			By("1st RM: Verify that RolloutManager is successfully created.")
@@ -231,7 +235,7 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			Eventually(rolloutsManagerCl, "1m", "1s").Should(rmFixture.HaveSuccessCondition())
 
 			By("1st RM: Create and validate Rollout in 1st namespace.")
-			utils.ValidateArgoRolloutsResources(ctx, k8sClient, fixture.TestE2ENamespace, testServiceNodePort_31000, testServiceNodePort_32000)
+			utils.ValidateArgoRolloutsResources(ctx, k8sClient, fixture.TestE2ENamespace(), testServiceNodePort_31000, testServiceNodePort_32000)
 
 			By("2nd RM: Create 2nd namespace.")
 			Expect(utils.CreateNamespace(ctx, k8sClient, nsName1)).To(Succeed())
@@ -243,19 +247,19 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			By("2nd RM: Verify that RolloutManager is not working.")
 			Eventually(rolloutsManagerCl2, "1m", "1s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseFailure))
 
-			By("2nd RM: Verify that Status.Condition is having error message.")
-			Eventually(rolloutsManagerCl2, "1m", "1s").Should(rmFixture.HaveCondition(
+			By("2nd RM: Verify that Status.Condition is having error message, naming the 1st RolloutManager.")
+			Eventually(rolloutsManagerCl2, "1m", "1s").Should(rmFixture.HaveExactCondition(
 				metav1.Condition{
 					Type:    rmv1alpha1.RolloutManagerConditionType,
 					Status:  metav1.ConditionFalse,
 					Reason:  rmv1alpha1.RolloutManagerReasonMultipleClusterScopedRolloutManager,
-					Message: controllers.UnsupportedRolloutManagerConfiguration,
+					Message: controllers.MultipleClusterScopedRolloutManagersMessage(types.NamespacedName{Namespace: rolloutsManagerCl.Namespace, Name: rolloutsManagerCl.Name}),
 				}))
 
 			By("2nd RM: Create and validate Rollout in 2nd namespace.")
 			utils.ValidateArgoRolloutsResources(ctx, k8sClient, nsName1, testServiceNodePort_31001, testServiceNodePort_32001)
 
-			By("1st RM: Update first RolloutManager, after reconciliation it should also stop working.")
+			By("1st RM: Update first RolloutManager, after reconciliation it should still work.")
 			err = k8s.UpdateWithoutConflict(ctx, &rolloutsManagerCl, k8sClient, func(obj client.Object) {
 				goObj, ok := obj.(*rmv1alpha1.RolloutManager)
 				Expect(ok).To(BeTrue())
@@ -264,17 +268,9 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			})
 			Expect(err).ToNot(HaveOccurred())
 
-			By("1st RM: Verify that now first RolloutManager is also not working.")
-			Eventually(rolloutsManagerCl, "1m", "1s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseFailure))
-
-			By("1st RM: Verify that Status.Condition is now having error message.")
-			Eventually(rolloutsManagerCl, "3m", "1s").Should(rmFixture.HaveCondition(
-				metav1.Condition{
-					Type:    rmv1alpha1.RolloutManagerConditionType,
-					Status:  metav1.ConditionFalse,
-					Reason:  rmv1alpha1.RolloutManagerReasonMultipleClusterScopedRolloutManager,
-					Message: controllers.UnsupportedRolloutManagerConfiguration,
-				}))
+			By("1st RM: Verify that first RolloutManager is still working, as it holds the cluster-scoped lock.")
+			Consistently(rolloutsManagerCl, "20s", "5s").Should(rmFixture.HaveSuccessCondition())
+			Expect(rolloutsManagerCl).To(rmFixture.HavePhase(rmv1alpha1.PhaseAvailable))
 
 			By("1st RM: Create 3rd namespace.")
 			Expect(utils.CreateNamespace(ctx, k8sClient, nsName2)).To(Succeed())
@@ -299,7 +295,7 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			Eventually(rolloutsManager, "1m", "5s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseFailure))
 
 			By("Verify that Status.Condition is now having error message.")
-			Eventually(rolloutsManager, "1m", "1s").Should(rmFixture.HaveCondition(
+			Eventually(rolloutsManager, "1m", "1s").Should(rmFixture.HaveExactCondition(
 				metav1.Condition{
 					Type:    rmv1alpha1.RolloutManagerConditionType,
 					Status:  metav1.ConditionFalse,
@@ -308,9 +304,9 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 				}))
 		})
 
-		It("After creating 2 cluster-scoped RolloutManager in a namespace, delete 1st RolloutManager and verify it removes the Failed status of 2nd RolloutManager", func() {
+		It("After creating 2 cluster-scoped RolloutManager in a namespace, delete 1st RolloutManager and verify that the 2nd RolloutManager takes over the cluster-scoped lock", func() {
 			By("1st RM: Create cluster-scoped RolloutManager in a namespace.")
-			rolloutsManagerCl, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace, false)
+			rolloutsManagerCl, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace(), false)
 			Expect(err).ToNot(HaveOccurred())
 
 			By("1st RM: Verify that RolloutManager is successfully created.")
@@ -320,28 +316,22 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 			Eventually(rolloutsManagerCl, "1m", "1s").Should(rmFixture.HaveSuccessCondition())
 
 			By("2nd RM: Create cluster-scoped RolloutManager in a namespace.")
-			rolloutsManagerCl2, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-2", fixture.TestE2ENamespace, false)
+			rolloutsManagerCl2, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-2", fixture.TestE2ENamespace(), false)
 			Expect(err).ToNot(HaveOccurred())
 
 			By("2nd RM: Verify that RolloutManager is not working.")
 			Eventually(rolloutsManagerCl2, "1m", "1s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseFailure))
 
-			By("1st RM: Verify that Status.Condition is now having error message.")
-			Eventually(rolloutsManagerCl, "3m", "1s").Should(rmFixture.HaveCondition(
-				metav1.Condition{
-					Type:    rmv1alpha1.RolloutManagerConditionType,
-					Status:  metav1.ConditionFalse,
-					Reason:  rmv1alpha1.RolloutManagerReasonMultipleClusterScopedRolloutManager,
-					Message: controllers.UnsupportedRolloutManagerConfiguration,
-				}))
+			By("1st RM: Verify that Status.Condition is still having success condition.")
+			Consistently(rolloutsManagerCl, "20s", "5s").Should(rmFixture.HaveSuccessCondition())
 
-			By("2nd RM: Verify that Status.Condition is now having error message.")
-			Eventually(rolloutsManagerCl2, "3m", "1s").Should(rmFixture.HaveCondition(
+			By("2nd RM: Verify that Status.Condition is now having error message, naming the 1st RolloutManager.")
+			Eventually(rolloutsManagerCl2, "3m", "1s").Should(rmFixture.HaveExactCondition(
 				metav1.Condition{
 					Type:    rmv1alpha1.RolloutManagerConditionType,
 					Status:  metav1.ConditionFalse,
 					Reason:  rmv1alpha1.RolloutManagerReasonMultipleClusterScopedRolloutManager,
-					Message: controllers.UnsupportedRolloutManagerConfiguration,
+					Message: controllers.MultipleClusterScopedRolloutManagersMessage(types.NamespacedName{Namespace: rolloutsManagerCl.Namespace, Name: rolloutsManagerCl.Name}),
 				}))
 
 			By("1st RM: Delete first RolloutManager.")
@@ -352,7 +342,7 @@ var _ = Describe("Cluster-scoped RolloutManager tests", func() {
 		})
 
 		It("Verify that deleting the RolloutManager should delete the '*aggregate*' ClusterRoles", func() {
-			rolloutsManagerCl, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace, false)
+			rolloutsManagerCl, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace(), false)
 			Expect(err).ToNot(HaveOccurred())
 
 			By("Verify that RolloutManager is successfully created.")
@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -9,10 +10,27 @@ import (
 
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
 )
 
-var _ = BeforeSuite(func() {
+var _ = SynchronizedBeforeSuite(func(ctx context.Context) []byte {
+	// The cluster is prepared by the first process, and its kubeconfig is shared with the others
+	kubeconfigPath, err := fixture.SetupE2ECluster(ctx)
+	Expect(err).ToNot(HaveOccurred())
+	return []byte(kubeconfigPath)
+}, func(kubeconfigPath []byte) {
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true), zap.Level(zapcore.DebugLevel)))
+
+	fixture.UseKubeconfig(string(kubeconfigPath))
+
+	serverVersion, err := fixture.ServerVersion()
+	Expect(err).ToNot(HaveOccurred())
+	GinkgoWriter.Println("Running against Kubernetes", serverVersion)
+})
+
+var _ = SynchronizedAfterSuite(func() {}, func(ctx context.Context) {
+	Expect(fixture.TeardownE2ECluster(ctx)).To(Succeed())
 })
 
 func TestClusterScoped(t *testing.T) {
@@ -0,0 +1,119 @@
+package e2e
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
+	rolloutManagerFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/rolloutmanager"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+
+	controllers "github.com/argoproj-labs/argo-rollouts-manager/controllers"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This file contains tests in which namespace-scoped and cluster-scoped RolloutManagers exist on the cluster at the same time.
+// The operator only supports the scope it was started with (see NAMESPACE_SCOPED_ARGO_ROLLOUTS), so these tests verify that the RolloutManager of the other scope is rejected, without interfering with the accepted one.
+// As of this writing, this function is called from the 'tests/e2e/(cluster-scoped/namespace-scoped)' packages, with the scope of the operator.
+func RunCoexistenceTests(namespaceScopedParam bool) {
+
+	testType := "cluster-scoped"
+	expectedRejectionMessage := controllers.UnsupportedRolloutManagerNamespaceScoped
+	if namespaceScopedParam {
+		testType = "namespace-scoped"
+		expectedRejectionMessage = controllers.UnsupportedRolloutManagerClusterScoped
+	}
+
+	Context("RolloutManager coexistence tests - "+testType, func() {
+
+		var (
+			k8sClient         client.Client
+			ctx               context.Context
+			acceptedRM        rolloutsmanagerv1alpha1.RolloutManager
+			rejectedRM        rolloutsmanagerv1alpha1.RolloutManager
+			acceptedResources []string
+		)
+
+		BeforeEach(func() {
+			Expect(fixture.EnsureCleanSlate()).To(Succeed())
+
+			var err error
+			k8sClient, _, err = fixture.GetE2ETestKubeClient()
+			Expect(err).ToNot(HaveOccurred())
+			ctx = context.Background()
+
+			rejectedNamespace := fixture.NamespaceName("test-coexistence-ns")
+			Expect(CreateNamespace(ctx, k8sClient, rejectedNamespace)).To(Succeed())
+
+			By("creating a RolloutManager of each scope at the same time: the RolloutManager with the scope of the operator is accepted")
+			acceptedRM, err = CreateRolloutManager(ctx, k8sClient, "accepted-rollouts-manager", fixture.TestE2ENamespace(), namespaceScopedParam)
+			Expect(err).ToNot(HaveOccurred())
+
+			rejectedRM, err = CreateRolloutManager(ctx, k8sClient, "rejected-rollouts-manager", rejectedNamespace, !namespaceScopedParam)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(acceptedRM, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+			Eventually(acceptedRM, "1m", "1s").Should(rolloutManagerFixture.HaveSuccessCondition())
+
+			By("verifying that the RolloutManager with the other scope is rejected")
+			Eventually(rejectedRM, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseFailure))
+			Eventually(rejectedRM, "1m", "1s").Should(rolloutManagerFixture.HaveExactCondition(
+				metav1.Condition{
+					Type:    rolloutsmanagerv1alpha1.RolloutManagerConditionType,
+					Status:  metav1.ConditionFalse,
+					Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonInvalidScoped,
+					Message: expectedRejectionMessage,
+				}))
+
+			acceptedResources, err = rolloutManagerFixture.GetOwnedResources(ctx, acceptedRM)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(acceptedResources).ToNot(BeEmpty())
+		})
+
+		It("should not create any resources for the rejected RolloutManager, so that its RBAC does not clash with that of the accepted RolloutManager", func() {
+
+			Consistently(func() ([]string, error) {
+				return rolloutManagerFixture.GetOwnedResources(ctx, rejectedRM)
+			}, "20s", "5s").Should(BeEmpty())
+
+			By("verifying that the resources of the accepted RolloutManager are unchanged")
+			Expect(rolloutManagerFixture.GetOwnedResources(ctx, acceptedRM)).To(ContainElements(acceptedResources))
+			Eventually(acceptedRM, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+		})
+
+		It("should not affect the accepted RolloutManager when the rejected RolloutManager is deleted first", func() {
+
+			By("deleting the rejected RolloutManager")
+			Expect(k8sClient.Delete(ctx, &rejectedRM)).To(Succeed())
+
+			By("verifying that the accepted RolloutManager, and its resources, are unaffected")
+			Consistently(acceptedRM, "20s", "5s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+			Expect(rolloutManagerFixture.GetOwnedResources(ctx, acceptedRM)).To(ContainElements(acceptedResources))
+
+			By("deleting the accepted RolloutManager, and verifying that all of its resources are deleted")
+			Expect(k8sClient.Delete(ctx, &acceptedRM)).To(Succeed())
+			Eventually(func() ([]string, error) {
+				return rolloutManagerFixture.GetOwnedResources(ctx, acceptedRM)
+			}, "60s", "1s").Should(BeEmpty())
+		})
+
+		It("should delete all of the resources of the accepted RolloutManager when it is deleted first, without the rejected RolloutManager taking over", func() {
+
+			By("deleting the accepted RolloutManager, and verifying that all of its resources are deleted")
+			Expect(k8sClient.Delete(ctx, &acceptedRM)).To(Succeed())
+			Eventually(func() ([]string, error) {
+				return rolloutManagerFixture.GetOwnedResources(ctx, acceptedRM)
+			}, "60s", "1s").Should(BeEmpty())
+
+			By("verifying that the rejected RolloutManager is still rejected, and has not created any resources")
+			Consistently(rejectedRM, "20s", "5s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseFailure))
+			Expect(rolloutManagerFixture.GetOwnedResources(ctx, rejectedRM)).To(BeEmpty())
+		})
+	})
+}
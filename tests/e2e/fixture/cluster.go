@@ -0,0 +1,211 @@
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	// E2EKubeconfigEnv is the path of the kubeconfig of an existing cluster to run the tests against. If neither it nor E2EKindNodeImageEnv is set, the cluster of the current kubeconfig (KUBECONFIG, or ~/.kube/config) is used.
+	E2EKubeconfigEnv = "E2E_KUBECONFIG"
+
+	// E2EKindNodeImageEnv is the node image of a kind cluster to run the tests against (for example, 'kindest/node:v1.27.3'), which allows the tests to target different Kubernetes versions. The cluster is created by SetupE2ECluster, unless it already exists.
+	E2EKindNodeImageEnv = "E2E_KIND_NODE_IMAGE"
+
+	// E2EKindClusterNameEnv is the name of the kind cluster of E2EKindNodeImageEnv. Defaults to defaultKindClusterName.
+	E2EKindClusterNameEnv = "E2E_KIND_CLUSTER_NAME"
+
+	// E2EKindDeleteClusterEnv should be set to 'true' for TeardownE2ECluster to delete the kind cluster. Otherwise, it is kept, so that it can be reused by the next run.
+	E2EKindDeleteClusterEnv = "E2E_KIND_DELETE_CLUSTER"
+
+	defaultKindClusterName = "argo-rollouts-manager-e2e"
+)
+
+// kubeconfigPath is the kubeconfig of the cluster that the tests run against, if it is not the current kubeconfig. See UseKubeconfig.
+var kubeconfigPath string
+
+// SetupE2ECluster prepares the cluster that the tests run against, according to the environment variables of this file, and returns the path of its kubeconfig (or an empty string, for the current kubeconfig), which should be passed to UseKubeconfig.
+// When running in parallel, it should only be called by the first Ginkgo process (via SynchronizedBeforeSuite), and the kubeconfig shared with the other processes.
+func SetupE2ECluster(ctx context.Context) (string, error) {
+
+	if nodeImage := os.Getenv(E2EKindNodeImageEnv); nodeImage != "" {
+		return setupKindCluster(ctx, kindClusterName(), nodeImage)
+	}
+
+	return os.Getenv(E2EKubeconfigEnv), nil
+}
+
+// UseKubeconfig sets the kubeconfig of the cluster that the clients of the fixture connect to. An empty path selects the current kubeconfig.
+func UseKubeconfig(path string) {
+	kubeconfigPath = path
+}
+
+// TeardownE2ECluster deletes the kind cluster created by SetupE2ECluster, if E2EKindDeleteClusterEnv is 'true'.
+func TeardownE2ECluster(ctx context.Context) error {
+
+	if os.Getenv(E2EKindNodeImageEnv) == "" || os.Getenv(E2EKindDeleteClusterEnv) != "true" {
+		return nil
+	}
+
+	_, err := runKind(ctx, "delete", "cluster", "--name", kindClusterName())
+	return err
+}
+
+// ServerVersion returns the version of the Kubernetes API server of the cluster.
+func ServerVersion() (*version.Version, error) {
+	discoveryClient, err := GetDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return version.ParseGeneric(info.GitVersion)
+}
+
+// ServerVersionAtLeast returns true if the version of the Kubernetes API server is at least major.minor.
+func ServerVersionAtLeast(major uint, minor uint) (bool, error) {
+	serverVersion, err := ServerVersion()
+	if err != nil {
+		return false, err
+	}
+	return serverVersion.AtLeast(version.MajorMinor(major, minor)), nil
+}
+
+// SkipUnlessServerVersionAtLeast skips the current test if the version of the Kubernetes API server is lower than major.minor, for tests of behaviours which depend on the Kubernetes version.
+func SkipUnlessServerVersionAtLeast(major uint, minor uint) {
+	atLeast, err := ServerVersionAtLeast(major, minor)
+	if err != nil {
+		Fail(fmt.Sprintf("unable to get the version of the Kubernetes API server: %v", err))
+	}
+	if !atLeast {
+		Skip(fmt.Sprintf("requires Kubernetes %d.%d or later", major, minor))
+	}
+}
+
+// IsAPIServed returns true if the cluster serves the given kind in the given group version (for example, 'policy/v1' and 'PodDisruptionBudget').
+func IsAPIServed(groupVersion string, kind string) (bool, error) {
+	discoveryClient, err := GetDiscoveryClient()
+	if err != nil {
+		return false, err
+	}
+
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Kind == kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func kindClusterName() string {
+	if name := os.Getenv(E2EKindClusterNameEnv); name != "" {
+		return name
+	}
+	return defaultKindClusterName
+}
+
+// setupKindCluster creates the kind cluster with the given node image, unless it already exists, and returns the path of its kubeconfig. An existing cluster is only reused if its Kubernetes version matches the version of the node image.
+func setupKindCluster(ctx context.Context, name string, nodeImage string) (string, error) {
+
+	clusters, err := runKind(ctx, "get", "clusters")
+	if err != nil {
+		return "", err
+	}
+
+	exists := false
+	for _, cluster := range strings.Fields(clusters) {
+		if cluster == name {
+			exists = true
+		}
+	}
+
+	if !exists {
+		GinkgoWriter.Println("Creating kind cluster", name, "with node image", nodeImage)
+		if _, err := runKind(ctx, "create", "cluster", "--name", name, "--image", nodeImage, "--wait", "5m"); err != nil {
+			return "", err
+		}
+	}
+
+	kubeconfig, err := runKind(ctx, "get", "kubeconfig", "--name", name)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(os.TempDir(), name+".kubeconfig")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		return "", fmt.Errorf("unable to write the kubeconfig of kind cluster '%s': %w", name, err)
+	}
+
+	if exists {
+		UseKubeconfig(path)
+		if err := verifyKindClusterVersion(name, nodeImage); err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
+}
+
+// verifyKindClusterVersion returns an error if the version of the Kubernetes API server does not match the tag of the node image, for example when an existing cluster was created with another image.
+func verifyKindClusterVersion(name string, nodeImage string) error {
+
+	tag := nodeImage
+	if i := strings.LastIndex(tag, ":"); i >= 0 {
+		tag = tag[i+1:]
+	}
+	tag, _, _ = strings.Cut(tag, "@")
+
+	imageVersion, err := version.ParseGeneric(tag)
+	if err != nil {
+		// The tag of the image is not a version (for example, a custom image): the version of the cluster cannot be verified
+		return nil
+	}
+
+	serverVersion, err := ServerVersion()
+	if err != nil {
+		return err
+	}
+
+	if serverVersion.Major() != imageVersion.Major() || serverVersion.Minor() != imageVersion.Minor() {
+		return fmt.Errorf("kind cluster '%s' already exists with Kubernetes %s, but node image '%s' was requested: delete the cluster, or set %s", name, serverVersion, nodeImage, E2EKindClusterNameEnv)
+	}
+
+	return nil
+}
+
+// runKind runs the kind CLI with the given arguments, and returns its output.
+func runKind(ctx context.Context, args ...string) (string, error) {
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "kind", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("'kind %s' failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -22,6 +24,7 @@ import (
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	apps "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -30,13 +33,49 @@ import (
 )
 
 const (
-	TestE2ENamespace = "argo-rollouts"
-	LabelsKey        = "app"
-	LabelsValue      = "test-argo-app"
+	// defaultTestE2ENamespace is the name of the namespace in which most tests create their RolloutManager (see TestE2ENamespace)
+	defaultTestE2ENamespace = "argo-rollouts"
+	LabelsKey               = "app"
+	LabelsValue             = "test-argo-app"
+
+	// ParallelProcessLabel is set on the namespaces created by the tests, and contains the number of the Ginkgo parallel process that created them.
+	// This allows each process to only clean up its own namespaces.
+	ParallelProcessLabel = "e2e-parallel-process"
+
+	// openShiftRouteAPIGroup is only served by OpenShift clusters, and is used to detect them
+	openShiftRouteAPIGroup = "route.openshift.io"
 )
 
 var NamespaceLabels = map[string]string{LabelsKey: LabelsValue}
 
+// TestE2ENamespace returns the name of the namespace in which the tests of the current Ginkgo parallel process create their RolloutManager.
+func TestE2ENamespace() string {
+	return NamespaceName(defaultTestE2ENamespace)
+}
+
+// NamespaceName returns a namespace name, based on the given name, which is unique to the current Ginkgo parallel process: this allows tests to run in parallel without conflicting on the namespaces they create.
+// The first process uses the given name unchanged, so that Serial tests (which always run on the first process) can use well-known namespace names, e.g. those of CLUSTER_SCOPED_ARGO_ROLLOUTS_NAMESPACES.
+func NamespaceName(name string) string {
+	if process := GinkgoParallelProcess(); process > 1 {
+		return fmt.Sprintf("%s-%d", name, process)
+	}
+	return name
+}
+
+// TestNamespaceLabels returns the labels that should be set on the namespaces created by the tests of the current Ginkgo parallel process.
+func TestNamespaceLabels() map[string]string {
+	return map[string]string{
+		LabelsKey:            LabelsValue,
+		ParallelProcessLabel: strconv.Itoa(GinkgoParallelProcess()),
+	}
+}
+
+// IsRunningInParallel returns true if the tests are run by more than one Ginkgo parallel process.
+func IsRunningInParallel() bool {
+	suiteConfig, _ := GinkgoConfiguration()
+	return suiteConfig.ParallelTotal > 1
+}
+
 type Cleaner struct {
 	cxt       context.Context
 	k8sClient client.Client
@@ -67,14 +106,24 @@ func EnsureCleanSlate() error {
 	}
 
 	// create default namespace used for Rollouts controller
-	err = cleaner.ensureRolloutNamespaceExists(TestE2ENamespace)
+	err = cleaner.ensureRolloutNamespaceExists(TestE2ENamespace())
 	if err != nil {
 		return err
 	}
 
-	err = cleaner.deleteRolloutsClusterRoles()
-	if err != nil {
-		return err
+	// The Rollouts ClusterRoles are shared by all of the RolloutManagers on the cluster, so when running in parallel, only delete them if no other process is using them.
+	deleteClusterRoles := true
+	if IsRunningInParallel() {
+		if deleteClusterRoles, err = cleaner.noRolloutManagersExist(); err != nil {
+			return err
+		}
+	}
+
+	if deleteClusterRoles {
+		err = cleaner.deleteRolloutsClusterRoles()
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -96,6 +145,28 @@ func (cleaner *Cleaner) ensureRolloutNamespaceExists(namespaceParam string) erro
 	return nil
 }
 
+// noRolloutManagersExist returns true if there are no RolloutManagers on the cluster, except those in namespaces that are being deleted.
+func (cleaner *Cleaner) noRolloutManagersExist() (bool, error) {
+	var rmList rolloutsmanagerv1alpha1.RolloutManagerList
+	if err := cleaner.k8sClient.List(cleaner.cxt, &rmList); err != nil {
+		return false, err
+	}
+
+	for _, rm := range rmList.Items {
+		var namespace corev1.Namespace
+		if err := cleaner.k8sClient.Get(cleaner.cxt, client.ObjectKey{Name: rm.Namespace}, &namespace); err != nil {
+			if apierr.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		if namespace.DeletionTimestamp == nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (cleaner *Cleaner) deleteRolloutsClusterRoles() error {
 	crList := rbacv1.ClusterRoleList{}
 	if err := cleaner.k8sClient.List(cleaner.cxt, &crList, &client.ListOptions{}); err != nil {
@@ -161,6 +232,16 @@ func GetDynamicClient() (*dynamic.DynamicClient, error) {
 	return dynamic.NewForConfig(config)
 }
 
+// GetDiscoveryClient returns a client for the discovery of the APIs of the cluster.
+func GetDiscoveryClient() (*discovery.DiscoveryClient, error) {
+	config, err := getSystemKubeConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return discovery.NewDiscoveryClientForConfig(config)
+}
+
 func GetE2ETestKubeClient() (client.Client, *runtime.Scheme, error) {
 	config, err := getSystemKubeConfig()
 	if err != nil {
@@ -175,6 +256,26 @@ func GetE2ETestKubeClient() (client.Client, *runtime.Scheme, error) {
 	return k8sClient, scheme, nil
 }
 
+// IsOpenShift returns true if the tests are running against an OpenShift cluster, which is detected by the presence of the OpenShift Route API.
+func IsOpenShift() (bool, error) {
+	discoveryClient, err := GetDiscoveryClient()
+	if err != nil {
+		return false, err
+	}
+
+	apiGroups, err := discoveryClient.ServerGroups()
+	if err != nil {
+		return false, err
+	}
+
+	for _, apiGroup := range apiGroups.Groups {
+		if apiGroup.Name == openShiftRouteAPIGroup {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // getKubeClient returns a controller-runtime Client for accessing K8s API resources used by the controller.
 func getKubeClient(config *rest.Config) (client.Client, *runtime.Scheme, error) {
 
@@ -207,6 +308,10 @@ func getKubeClient(config *rest.Config) (client.Client, *runtime.Scheme, error)
 		return nil, nil, err
 	}
 
+	if err := authorizationv1.AddToScheme(scheme); err != nil {
+		return nil, nil, err
+	}
+
 	k8sClient, err := client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, nil, err
@@ -216,12 +321,13 @@ func getKubeClient(config *rest.Config) (client.Client, *runtime.Scheme, error)
 
 }
 
-// Retrieve the system-level Kubernetes config (e.g. ~/.kube/config or service account config from volume)
+// Retrieve the system-level Kubernetes config (e.g. ~/.kube/config or service account config from volume), or the kubeconfig of the cluster selected via UseKubeconfig
 func getSystemKubeConfig() (*rest.Config, error) {
 
 	overrides := clientcmd.ConfigOverrides{}
 
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfigPath
 	clientConfig := clientcmd.NewInteractiveDeferredLoadingClientConfig(loadingRules, &overrides, os.Stdin)
 
 	restConfig, err := clientConfig.ClientConfig()
@@ -264,5 +370,17 @@ func listE2ETestNamespaces(ctx context.Context, k8sClient client.Client) (corev1
 	if err != nil {
 		return nsList, fmt.Errorf("unable to fetch list of test namespace: %w", err)
 	}
+
+	// only return the namespaces of the current Ginkgo parallel process: namespaces without the process label are returned to the first process
+	process := strconv.Itoa(GinkgoParallelProcess())
+	var res []corev1.Namespace
+	for _, namespace := range nsList.Items {
+		namespaceProcess, exists := namespace.Labels[ParallelProcessLabel]
+		if namespaceProcess == process || (!exists && process == "1") {
+			res = append(res, namespace)
+		}
+	}
+	nsList.Items = res
+
 	return nsList, nil
 }
@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/gomega"
+	matcher "github.com/onsi/gomega/types"
+)
+
+// The matchers of this file are applied to the arguments of a container (for example, 'deployment.Spec.Template.Spec.Containers[0].Args'), and are flag-aware:
+// - the order of the flags is ignored,
+// - '--flag=value' and '--flag value' are equivalent, as are '-flag' and '--flag' (as for the 'flag' package of Go),
+// so that tests do not break when the operator changes the order or the form of the arguments that it adds.
+
+// HaveContainerArg checks that the container arguments contain the given flag (for example, '--namespaced' or '--loglevel'), with any value.
+func HaveContainerArg(flag string) matcher.GomegaMatcher {
+	return WithTransform(func(args []string) []string {
+		var flags []string
+		for _, arg := range normalizeContainerArgs(args) {
+			name, _, _ := strings.Cut(arg, "=")
+			flags = append(flags, name)
+		}
+		return flags
+	}, ContainElement(normalizeFlag(flag)))
+}
+
+// HaveArgPair checks that the container arguments contain the given flag with the given value (for example, '--loglevel' and 'error'), whether they are passed as '--loglevel=error' or '--loglevel error'.
+func HaveArgPair(flag string, value string) matcher.GomegaMatcher {
+	return WithTransform(normalizeContainerArgs, ContainElement(normalizeFlag(flag)+"="+value))
+}
+
+// ConsistOfContainerArgs checks that the container arguments contain exactly the given flags and values, in any order. For example, ["--loglevel", "error", "--namespaced"] matches ["--namespaced", "--loglevel=error"].
+func ConsistOfContainerArgs(expected ...string) matcher.GomegaMatcher {
+	return WithTransform(normalizeContainerArgs, ConsistOf(normalizeContainerArgs(expected)))
+}
+
+// normalizeContainerArgs returns the container arguments in the '--flag' or '--flag=value' form: a flag followed by an argument which is not a flag is parsed as a flag with a value.
+// The arguments that are not preceded by a flag (positional arguments) are returned as is.
+func normalizeContainerArgs(args []string) []string {
+
+	res := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !isContainerArgFlag(arg) {
+			res = append(res, arg)
+			continue
+		}
+
+		if name, value, found := strings.Cut(arg, "="); found {
+			res = append(res, normalizeFlag(name)+"="+value)
+			continue
+		}
+
+		if i+1 < len(args) && !isContainerArgFlag(args[i+1]) {
+			res = append(res, normalizeFlag(arg)+"="+args[i+1])
+			i++
+			continue
+		}
+
+		res = append(res, normalizeFlag(arg))
+	}
+
+	return res
+}
+
+// normalizeFlag returns the flag with a '--' prefix, whether it was specified with '-', '--', or without a prefix.
+func normalizeFlag(flag string) string {
+	return "--" + strings.TrimLeft(flag, "-")
+}
+
+// isContainerArgFlag returns true if the argument is a flag, rather than a value: values may start with '-' if they are negative numbers.
+func isContainerArgFlag(arg string) bool {
+	if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
@@ -3,9 +3,12 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/gomega"
 	matcher "github.com/onsi/gomega/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,6 +47,30 @@ func UpdateWithoutConflict(ctx context.Context, obj client.Object, k8sClient cli
 	return err
 }
 
+// DeleteAndWaitForRecreation deletes the given object, then waits for it to be recreated (as an object with the same name/namespace, but a different UID) within the given timeout.
+// This is used to verify that the operator recovers from the deletion of the resources that it manages.
+func DeleteAndWaitForRecreation(ctx context.Context, obj client.Object, k8sClient client.Client, timeout time.Duration) error {
+
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return err
+	}
+	originalUID := obj.GetUID()
+
+	if err := k8sClient.Delete(ctx, obj); err != nil {
+		return err
+	}
+
+	return wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return obj.GetUID() != originalUID, nil
+	})
+}
+
 func HaveLabel(keyParam, valueParam string, k8sClient client.Client) matcher.GomegaMatcher {
 
 	return WithTransform(func(k8sObject client.Object) bool {
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// OperatorMetricsURLEnvName is an environment variable that can be used to specify the URL of the metrics endpoint of the operator under test
+	OperatorMetricsURLEnvName = "E2E_OPERATOR_METRICS_URL"
+
+	// defaultOperatorMetricsURL is the metrics endpoint of an operator started with 'make run' (or the start-e2e targets)
+	defaultOperatorMetricsURL = "http://localhost:8080/metrics"
+)
+
+// GetOperatorMetrics retrieves and parses the metrics exposed by the operator.
+func GetOperatorMetrics() (map[string]*dto.MetricFamily, error) {
+
+	url := os.Getenv(OperatorMetricsURLEnvName)
+	if url == "" {
+		url = defaultOperatorMetricsURL
+	}
+
+	resp, err := http.Get(url) // #nosec G107
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve operator metrics from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code when retrieving operator metrics from %s: %d", url, resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// GetGaugeValue returns the value of the first gauge of the given metric family that has all of the given labels.
+func GetGaugeValue(families map[string]*dto.MetricFamily, name string, labels map[string]string) (float64, error) {
+
+	metric, err := findMetric(families, name, labels)
+	if err != nil {
+		return 0, err
+	}
+	if metric.Gauge == nil {
+		return 0, fmt.Errorf("metric %s is not a gauge", name)
+	}
+	return metric.Gauge.GetValue(), nil
+}
+
+// GetHistogramAverage returns the average of the observations of the first histogram of the given metric family that has all of the given labels.
+func GetHistogramAverage(families map[string]*dto.MetricFamily, name string, labels map[string]string) (float64, error) {
+
+	metric, err := findMetric(families, name, labels)
+	if err != nil {
+		return 0, err
+	}
+	if metric.Histogram == nil {
+		return 0, fmt.Errorf("metric %s is not a histogram", name)
+	}
+	if metric.Histogram.GetSampleCount() == 0 {
+		return 0, nil
+	}
+	return metric.Histogram.GetSampleSum() / float64(metric.Histogram.GetSampleCount()), nil
+}
+
+// GetCounterSum returns the sum of the counters of the given metric family that have all of the given labels, or 0 if there are none.
+func GetCounterSum(families map[string]*dto.MetricFamily, name string, labels map[string]string) (float64, error) {
+
+	family, exists := families[name]
+	if !exists {
+		return 0, nil
+	}
+
+	res := 0.0
+	for _, metric := range family.Metric {
+		if !hasLabels(metric, labels) {
+			continue
+		}
+		if metric.Counter == nil {
+			return 0, fmt.Errorf("metric %s is not a counter", name)
+		}
+		res += metric.Counter.GetValue()
+	}
+	return res, nil
+}
+
+func findMetric(families map[string]*dto.MetricFamily, name string, labels map[string]string) (*dto.Metric, error) {
+
+	family, exists := families[name]
+	if !exists {
+		return nil, fmt.Errorf("metric %s was not found", name)
+	}
+
+	for _, metric := range family.Metric {
+		if hasLabels(metric, labels) {
+			return metric, nil
+		}
+	}
+
+	return nil, fmt.Errorf("metric %s with labels %v was not found", name, labels)
+}
+
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	for key, value := range labels {
+		found := false
+		for _, label := range metric.Label {
+			if label.GetName() == key && label.GetValue() == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
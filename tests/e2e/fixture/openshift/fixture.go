@@ -0,0 +1,168 @@
+package openshift
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	controllers "github.com/argoproj-labs/argo-rollouts-manager/controllers"
+)
+
+const (
+	// SCCAnnotation is set by OpenShift on each Pod, and contains the name of the SecurityContextConstraints that admitted the Pod.
+	SCCAnnotation = "openshift.io/scc"
+
+	// UIDRangeAnnotation is set by OpenShift on each Namespace, and contains the range of UIDs that are assigned to the Pods of the Namespace (e.g. '1000650000/10000').
+	UIDRangeAnnotation = "openshift.io/sa.scc.uid-range"
+)
+
+var routeGVR = schema.GroupVersionResource{
+	Group:    "route.openshift.io",
+	Version:  "v1",
+	Resource: "routes",
+}
+
+var rolloutGVR = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "rollouts",
+}
+
+// CreateRoute creates an OpenShift Route which sends all of its traffic to the given Service.
+func CreateRoute(ctx context.Context, name, namespace, serviceName string) error {
+
+	routeStr := `
+apiVersion: route.openshift.io/v1
+kind: Route
+metadata:
+  name: ` + name + `
+  namespace: ` + namespace + `
+spec:
+  port:
+    targetPort: 8080
+  to:
+    kind: Service
+    name: ` + serviceName + `
+    weight: 100`
+
+	return createFromYAML(ctx, routeGVR, namespace, routeStr)
+}
+
+// GetRouteServiceWeights returns the weight of each Service of the Route (from .spec.to and .spec.alternateBackends), by Service name.
+func GetRouteServiceWeights(ctx context.Context, name, namespace string) (map[string]int64, error) {
+
+	dynclient, err := fixture.GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	route, err := dynclient.Resource(routeGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	backends := []interface{}{}
+	if to, exists, err := unstructured.NestedMap(route.Object, "spec", "to"); err != nil {
+		return nil, err
+	} else if exists {
+		backends = append(backends, to)
+	}
+	if alternateBackends, exists, err := unstructured.NestedSlice(route.Object, "spec", "alternateBackends"); err != nil {
+		return nil, err
+	} else if exists {
+		backends = append(backends, alternateBackends...)
+	}
+
+	res := map[string]int64{}
+	for _, backend := range backends {
+		backendMap, ok := backend.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected Route backend: %v", backend)
+		}
+		name, _, _ := unstructured.NestedString(backendMap, "name")
+		weight, _, _ := unstructured.NestedInt64(backendMap, "weight")
+		res[name] = weight
+	}
+	return res, nil
+}
+
+// CreateCanaryRolloutWithRoute creates a Rollout which uses the OpenShift Route plugin to shift traffic between the stable and canary Services, by setting the weight of the canary Service to canaryWeight and then pausing.
+func CreateCanaryRolloutWithRoute(ctx context.Context, name, namespace, stableService, canaryService, routeName string, canaryWeight int) error {
+
+	rolloutStr := `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: ` + name + `
+  namespace: ` + namespace + `
+spec:
+  replicas: 1
+  revisionHistoryLimit: 2
+  selector:
+    matchLabels:
+      app: test-argo-app
+  strategy:
+    canary:
+      stableService: ` + stableService + `
+      canaryService: ` + canaryService + `
+      trafficRouting:
+        plugins:
+          ` + controllers.OpenShiftRolloutPluginName + `:
+            routes:
+            - ` + routeName + `
+            namespace: ` + namespace + `
+      steps:
+      - setWeight: ` + fmt.Sprintf("%d", canaryWeight) + `
+      - pause: {}
+  template:
+    metadata:
+      labels:
+        app: test-argo-app
+    spec:
+      containers:
+      - image: nginxinc/nginx-unprivileged@sha256:0569e319d06556564ad40882ed35231461d06bec788b5aec00b83b6e9f3ced1a
+        name: webserver-simple
+        ports:
+        - containerPort: 8080
+          name: http
+          protocol: TCP
+        resources: {}`
+
+	return createFromYAML(ctx, rolloutGVR, namespace, rolloutStr)
+}
+
+// UpdateRolloutRevision modifies the Pod template of the Rollout, which starts a new rollout of the given revision.
+func UpdateRolloutRevision(ctx context.Context, name, namespace, revision string) error {
+
+	dynclient, err := fixture.GetDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"e2e-revision":%q}}}}}`, revision)
+
+	_, err = dynclient.Resource(rolloutGVR).Namespace(namespace).Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+func createFromYAML(ctx context.Context, gvr schema.GroupVersionResource, namespace, objStr string) error {
+
+	dynclient, err := fixture.GetDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	var un unstructured.Unstructured
+	if err := yaml.UnmarshalStrict([]byte(objStr), &un, yaml.DisallowUnknownFields); err != nil {
+		return err
+	}
+
+	_, err = dynclient.Resource(gvr).Namespace(namespace).Create(ctx, &un, metav1.CreateOptions{})
+	return err
+}
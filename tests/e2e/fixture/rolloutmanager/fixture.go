@@ -3,10 +3,19 @@ package rolloutmanager
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
 	. "github.com/onsi/gomega"
 	matcher "github.com/onsi/gomega/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+
+	controllers "github.com/argoproj-labs/argo-rollouts-manager/controllers"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,17 +53,18 @@ func HavePhase(phase rolloutsmanagerv1alpha1.RolloutControllerPhase) matcher.Gom
 	})
 }
 
-func HaveCondition(expected metav1.Condition) matcher.GomegaMatcher {
+// HaveExactCondition checks that the RolloutManager has a condition with the type, status, reason and message of the expected condition.
+func HaveExactCondition(expected metav1.Condition) matcher.GomegaMatcher {
 	return fetchRolloutManager(func(app rolloutsmanagerv1alpha1.RolloutManager) bool {
 
 		if len(app.Status.Conditions) == 0 {
-			fmt.Println("HaveCondition: Conditions is nil")
+			fmt.Println("HaveExactCondition: Conditions is nil")
 			return false
 		}
 
 		for _, condition := range app.Status.Conditions {
 			if condition.Type == expected.Type {
-				fmt.Println("HaveCondition:", "expected: ", expected, "actual: ", condition)
+				fmt.Println("HaveExactCondition:", "expected: ", expected, "actual: ", condition)
 				return condition.Type == expected.Type &&
 					condition.Status == expected.Status &&
 					condition.Reason == expected.Reason &&
@@ -92,3 +102,193 @@ func HaveSuccessCondition() matcher.GomegaMatcher {
 		return false
 	})
 }
+
+// HaveReadyCondition checks that the RolloutManager has a Ready condition with the given status, and that its status reflects the current generation of the RolloutManager (the kstatus conventions).
+func HaveReadyCondition(status metav1.ConditionStatus) matcher.GomegaMatcher {
+	return fetchRolloutManager(func(app rolloutsmanagerv1alpha1.RolloutManager) bool {
+
+		if app.Status.ObservedGeneration != app.Generation {
+			fmt.Println("HaveReadyCondition: observedGeneration", app.Status.ObservedGeneration, "does not match generation", app.Generation)
+			return false
+		}
+
+		for _, condition := range app.Status.Conditions {
+			if condition.Type == rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType {
+				fmt.Println("HaveReadyCondition:", "expected: ", status, "actual: ", condition)
+				return condition.Status == status && condition.ObservedGeneration == app.Generation
+			}
+		}
+
+		fmt.Println("HaveReadyCondition: Ready condition is not set")
+		return false
+	})
+}
+
+// HaveCondition checks that the RolloutManager has a condition of the given type, with the given status and reason, whatever its message. An empty reason matches any reason.
+func HaveCondition(conditionType string, status metav1.ConditionStatus, reason string) matcher.GomegaMatcher {
+	return fetchRolloutManager(func(app rolloutsmanagerv1alpha1.RolloutManager) bool {
+
+		for _, condition := range app.Status.Conditions {
+			if condition.Type == conditionType {
+				fmt.Println("HaveCondition:", "expected: ", conditionType, status, reason, "actual: ", condition)
+				return condition.Status == status && (reason == "" || condition.Reason == reason)
+			}
+		}
+
+		fmt.Println("HaveCondition:", conditionType, "condition is not set")
+		return false
+	})
+}
+
+// Component is a component of a RolloutManager, whose readiness is reported in the status of the RolloutManager. See HaveComponentReady.
+type Component string
+
+const (
+	// ComponentController is the Rollouts controller (its Deployment and Pods).
+	ComponentController Component = "Controller"
+
+	// ComponentRBAC, ComponentConfig and ComponentMonitoring are the resources which are reconciled by the component controllers, when the ComponentControllers feature gate is enabled.
+	ComponentRBAC       Component = "RBAC"
+	ComponentConfig     Component = "Config"
+	ComponentMonitoring Component = "Monitoring"
+)
+
+// componentConditionTypes are the conditions which report whether each component (other than the Rollouts controller) was reconciled.
+var componentConditionTypes = map[Component]string{
+	ComponentRBAC:       rolloutsmanagerv1alpha1.RolloutManagerRBACReconciledConditionType,
+	ComponentConfig:     rolloutsmanagerv1alpha1.RolloutManagerConfigReconciledConditionType,
+	ComponentMonitoring: rolloutsmanagerv1alpha1.RolloutManagerMonitoringReconciledConditionType,
+}
+
+// HaveComponentReady checks that the given component of the RolloutManager is ready, according to its status:
+// - ComponentController: the Rollouts controller is Available, and is neither degraded nor crashing.
+// - ComponentRBAC, ComponentConfig and ComponentMonitoring: the condition of the component (for example, RBACReconciled) is True. If the component is not reconciled by its own controller (the ComponentControllers feature gate is disabled), it is reconciled with the RolloutManager, and so the Reconciled condition must be True instead.
+func HaveComponentReady(component Component) matcher.GomegaMatcher {
+	return fetchRolloutManager(func(app rolloutsmanagerv1alpha1.RolloutManager) bool {
+
+		conditionStatus := func(conditionType string) (metav1.ConditionStatus, bool) {
+			for _, condition := range app.Status.Conditions {
+				if condition.Type == conditionType {
+					return condition.Status, true
+				}
+			}
+			return metav1.ConditionUnknown, false
+		}
+
+		if component == ComponentController {
+			degraded, _ := conditionStatus(rolloutsmanagerv1alpha1.RolloutManagerControllerDegradedConditionType)
+			crashing, _ := conditionStatus(rolloutsmanagerv1alpha1.RolloutManagerControllerCrashingConditionType)
+			fmt.Println("HaveComponentReady:", component, "rolloutController: ", app.Status.RolloutController, "degraded: ", degraded, "crashing: ", crashing)
+			return app.Status.RolloutController == rolloutsmanagerv1alpha1.PhaseAvailable && degraded != metav1.ConditionTrue && crashing != metav1.ConditionTrue
+		}
+
+		conditionType, exists := componentConditionTypes[component]
+		if !exists {
+			fmt.Println("HaveComponentReady: unknown component", component)
+			return false
+		}
+
+		status, found := conditionStatus(conditionType)
+		if !found {
+			status, found = conditionStatus(rolloutsmanagerv1alpha1.RolloutManagerConditionType)
+		}
+		fmt.Println("HaveComponentReady:", component, "status: ", status, "found: ", found)
+		return found && status == metav1.ConditionTrue
+	})
+}
+
+// OwnedResourceLabelKey is set by the operator (with the value controllers.DefaultArgoRolloutsResourceName) on the resources that it creates for a RolloutManager.
+const OwnedResourceLabelKey = "app.kubernetes.io/part-of"
+
+// GetOwnedResources returns the resources which are owned by the given RolloutManager, as '(kind) (namespace)/(name)' strings. A resource is owned by the RolloutManager if:
+// - it has an owner reference to the RolloutManager (e.g. the ServiceMonitor), or
+// - it has the OwnedResourceLabelKey label, which the operator sets on the resources that it creates.
+//
+// All of the resource kinds of the cluster are discovered, so that the resource kinds which are added to the operator are covered without changes to the tests. Cluster-scoped resources are only searched for cluster-scoped RolloutManagers.
+//
+// This is used to verify that no resources remain after a RolloutManager is deleted, and so the UID of the RolloutManager should be set (for example, by creating it).
+func GetOwnedResources(ctx context.Context, rolloutManager rolloutsmanagerv1alpha1.RolloutManager) ([]string, error) {
+
+	if rolloutManager.UID == "" {
+		return nil, fmt.Errorf("the UID of RolloutManager '%s' is not set", rolloutManager.Name)
+	}
+
+	discoveryClient, err := fixture.GetDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := fixture.GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Aggregated APIs (e.g. metrics.k8s.io) may be unavailable: the resources of the other APIs are still returned in this case
+	resourceLists, err := discoveryClient.ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	ownedLabelSelector := OwnedResourceLabelKey + "=" + controllers.DefaultArgoRolloutsResourceName
+
+	res := []string{}
+	for _, resourceList := range resourceLists {
+
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, apiResource := range resourceList.APIResources {
+
+			// Skip subresources, and the resources which cannot be listed
+			if strings.Contains(apiResource.Name, "/") || !slices.Contains(apiResource.Verbs, "list") {
+				continue
+			}
+
+			if !apiResource.Namespaced && rolloutManager.Spec.NamespaceScoped {
+				continue
+			}
+
+			resourceClient := dynamicClient.Resource(groupVersion.WithResource(apiResource.Name))
+
+			var list *unstructured.UnstructuredList
+			if apiResource.Namespaced {
+				// Resources of the namespace may be owned via an owner reference, rather than the label, so all of them are listed
+				list, err = resourceClient.Namespace(rolloutManager.Namespace).List(ctx, metav1.ListOptions{})
+			} else {
+				list, err = resourceClient.List(ctx, metav1.ListOptions{LabelSelector: ownedLabelSelector})
+			}
+			if err != nil {
+				if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err) {
+					continue
+				}
+				return nil, fmt.Errorf("unable to list %s: %w", apiResource.Name, err)
+			}
+
+			for _, item := range list.Items {
+				if isOwnedBy(item, rolloutManager) {
+					res = append(res, fmt.Sprintf("%s %s/%s", apiResource.Kind, item.GetNamespace(), item.GetName()))
+				}
+			}
+		}
+	}
+
+	sort.Strings(res)
+
+	return res, nil
+}
+
+func isOwnedBy(obj unstructured.Unstructured, rolloutManager rolloutsmanagerv1alpha1.RolloutManager) bool {
+
+	if obj.GetLabels()[OwnedResourceLabelKey] == controllers.DefaultArgoRolloutsResourceName {
+		return true
+	}
+
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.UID == rolloutManager.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -2,11 +2,13 @@ package rollouts
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/yaml"
 )
 
@@ -54,6 +56,113 @@ spec:
 	return rolloutStr
 }
 
+// CanaryRolloutSteps is the number of steps of the Rollouts created by CreateCanaryArgoRollout
+const CanaryRolloutSteps = 3
+
+func buildCanaryRolloutResource(name, namespace, image string) string {
+
+	rolloutStr := `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: ` + name + `
+  namespace: ` + namespace + `
+spec:
+  replicas: 2
+  revisionHistoryLimit: 2
+  selector:
+    matchLabels:
+      app: test-argo-app
+  strategy:
+    canary:
+      steps:
+      - setWeight: 50
+      - pause:
+          duration: 10s
+      - setWeight: 100
+  template:
+    metadata:
+      labels:
+        app: test-argo-app
+    spec:
+      containers:
+      - image: ` + image + `
+        name: webserver-simple
+        ports:
+        - containerPort: 8080
+          name: http
+          protocol: TCP
+        resources: {}`
+
+	return rolloutStr
+}
+
+// CreateCanaryArgoRollout creates a Rollout with a canary strategy of CanaryRolloutSteps steps, which includes a timed pause (so that the Rollout progresses without manual promotion).
+func CreateCanaryArgoRollout(ctx context.Context, name, namespace, image string) error {
+
+	dynclient, err := fixture.GetDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	var un unstructured.Unstructured
+	if err := yaml.UnmarshalStrict([]byte(buildCanaryRolloutResource(name, namespace, image)), &un, yaml.DisallowUnknownFields); err != nil {
+		return err
+	}
+
+	_, err = dynclient.Resource(rolloutGVR).Namespace(namespace).Create(ctx, &un, metav1.CreateOptions{})
+	return err
+}
+
+// UpdateArgoRolloutImage sets the image of the (first) container of the Rollout, which starts a new rollout.
+func UpdateArgoRolloutImage(ctx context.Context, name, namespace, image string) error {
+
+	dynclient, err := fixture.GetDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	patch := fmt.Sprintf(`[{"op":"replace","path":"/spec/template/spec/containers/0/image","value":%q}]`, image)
+
+	_, err = dynclient.Resource(rolloutGVR).Namespace(namespace).Patch(ctx, name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// GetCurrentStepIndex returns the .status.currentStepIndex of the Rollout, or -1 if it is not set.
+func GetCurrentStepIndex(ctx context.Context, name, namespace string) (int64, error) {
+
+	rollout, err := GetArgoRollout(ctx, name, namespace)
+	if err != nil {
+		return -1, err
+	}
+
+	currentStepIndex, exists, err := unstructured.NestedInt64(rollout.Object, "status", "currentStepIndex")
+	if err != nil || !exists {
+		return -1, err
+	}
+	return currentStepIndex, nil
+}
+
+// IsFullyPromoted returns true if the stable ReplicaSet of the Rollout is the ReplicaSet of its current Pod template, i.e. the last rollout has completed.
+func IsFullyPromoted(ctx context.Context, name, namespace string) (bool, error) {
+
+	rollout, err := GetArgoRollout(ctx, name, namespace)
+	if err != nil {
+		return false, err
+	}
+
+	stableRS, _, err := unstructured.NestedString(rollout.Object, "status", "stableRS")
+	if err != nil {
+		return false, err
+	}
+	currentPodHash, _, err := unstructured.NestedString(rollout.Object, "status", "currentPodHash")
+	if err != nil {
+		return false, err
+	}
+
+	return stableRS != "" && stableRS == currentPodHash, nil
+}
+
 func CreateArgoRollout(ctx context.Context, name, namespace, activeService, previewService string) (string, error) {
 
 	dynclient, err := fixture.GetDynamicClient()
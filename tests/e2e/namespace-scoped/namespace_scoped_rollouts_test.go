@@ -28,6 +28,9 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 	// Add the tests which are designed to run in both cluster-scoped and namespace-scoped modes.
 	utils.RunRolloutsTests(true)
 
+	// Add the tests in which RolloutManagers of both scopes exist at the same time.
+	utils.RunCoexistenceTests(true)
+
 	Context("Testing namespace-scoped RolloutManager behaviour", func() {
 
 		// Use slightly different NodePorts from default, to avoid conflicting with any other Services using NodePorts on the cluster. This is not an issue when running E2E tests via GitHub actions, but is more likely to be an issue when running against, e.g. a large cluster like default OpenShift.
@@ -63,7 +66,7 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 		*/
 		It("After creating namespace-scoped RolloutManager in a namespace, operator should create appropriate K8s resources and watch argo rollouts CR in same namespace.", func() {
 
-			nsName := "test-rom-ns"
+			nsName := fixture.NamespaceName("test-rom-ns")
 
 			By("Create a namespace for RolloutManager.")
 			Expect(utils.CreateNamespace(ctx, k8sClient, nsName)).To(Succeed())
@@ -96,10 +99,10 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 		*/
 		It("After creating namespace-scoped RolloutManager in a namespace, another namespace-scoped RolloutManager in different namespace should also work.", func() {
 
-			nsName1 := "test-rom-ns"
+			nsName1 := fixture.NamespaceName("test-rom-ns")
 
 			By("1st RM: Create namespace-scoped RolloutManager in 1st namespace.")
-			rolloutsManagerNs1, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace, true)
+			rolloutsManagerNs1, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace(), true)
 			Expect(err).ToNot(HaveOccurred())
 
 			By("1st RM: Verify that RolloutManager is successfully created.")
@@ -111,7 +114,7 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 			By("1st RM: Verify argo Rollouts controller of 1st namespace is able to reconcile CR created in 1st namespace.")
 
 			By("1st RM: Create and validate rollouts.")
-			utils.ValidateArgoRolloutsResources(ctx, k8sClient, fixture.TestE2ENamespace, testServiceNodePort_31000, testServiceNodePort_32000)
+			utils.ValidateArgoRolloutsResources(ctx, k8sClient, fixture.TestE2ENamespace(), testServiceNodePort_31000, testServiceNodePort_32000)
 
 			By("2nd RM: Create 2nd namespace.")
 			Expect(utils.CreateNamespace(ctx, k8sClient, nsName1)).To(Succeed())
@@ -184,7 +187,7 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 		*/
 		It("After creating namespace-scoped RolloutManager in a namespace, operator should create appropriate K8s resources, but it should not watch argo rollouts CR in other namespace.", func() {
 
-			nsName1, nsName2 := "test-rom-ns", "test-ro-ns"
+			nsName1, nsName2 := fixture.NamespaceName("test-rom-ns"), fixture.NamespaceName("test-ro-ns")
 
 			By("1st NS: Create a namespace for RolloutManager.")
 			Expect(utils.CreateNamespace(ctx, k8sClient, nsName1)).To(Succeed())
@@ -245,10 +248,10 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 		*/
 		It("Should allow namespace-scoped RolloutManager, but not cluster-scoped.", func() {
 
-			nsName := "test-rom-ns"
+			nsName := fixture.NamespaceName("test-rom-ns")
 
 			By("1st RM: Create namespace-scoped RolloutManager in 1st namespace.")
-			rolloutsManagerNs, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace, true)
+			rolloutsManagerNs, err := utils.CreateRolloutManager(ctx, k8sClient, "test-rollouts-manager-1", fixture.TestE2ENamespace(), true)
 			Expect(err).ToNot(HaveOccurred())
 
 			By("1st RM: Verify that RolloutManager is successfully created in 1st namespace.")
@@ -268,7 +271,7 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 			Eventually(rolloutsManagerCl, "1m", "1s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseFailure))
 
 			By("2nd RM: Verify that Status.Condition is having error message.")
-			Eventually(rolloutsManagerCl, "1m", "1s").Should(rmFixture.HaveCondition(
+			Eventually(rolloutsManagerCl, "1m", "1s").Should(rmFixture.HaveExactCondition(
 				metav1.Condition{
 					Type:    rmv1alpha1.RolloutManagerConditionType,
 					Status:  metav1.ConditionFalse,
@@ -291,7 +294,7 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 			Eventually(rolloutsManagerNs, "1m", "1s").Should(rmFixture.HaveSuccessCondition())
 
 			By("1st RM: Create Rollout CR in 1st namespace and ensure it is reconciled.")
-			utils.ValidateArgoRolloutsResources(ctx, k8sClient, fixture.TestE2ENamespace, testServiceNodePort_31000, testServiceNodePort_32000)
+			utils.ValidateArgoRolloutsResources(ctx, k8sClient, fixture.TestE2ENamespace(), testServiceNodePort_31000, testServiceNodePort_32000)
 
 			By("2nd RM: Create Rollout in 2nd namespace and it should not be reconciled as 2nd RolloutManager failed.")
 
@@ -324,7 +327,7 @@ var _ = Describe("Namespace-scoped RolloutManager tests", func() {
 			In this test, we specify some additional labels and annotations to the Rollout Manager, and expect them to be set on all generated resources.
 		*/
 		It("Should create resources with additional metadata when provided", func() {
-			nsName := "test-rom-ns"
+			nsName := fixture.NamespaceName("test-rom-ns")
 
 			By("Create a namespace for RolloutManager.")
 			Expect(utils.CreateNamespace(ctx, k8sClient, nsName)).To(Succeed())
@@ -0,0 +1,192 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	utils "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e"
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/k8s"
+	openshiftFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/openshift"
+	rmFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/rolloutmanager"
+	rolloutFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/rollouts"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rmv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+
+	controllers "github.com/argoproj-labs/argo-rollouts-manager/controllers"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("OpenShift-specific RolloutManager tests", func() {
+
+	var (
+		ctx            context.Context
+		k8sClient      client.Client
+		nsName         string
+		rolloutManager rmv1alpha1.RolloutManager
+	)
+
+	BeforeEach(func() {
+		isOpenShift, err := fixture.IsOpenShift()
+		Expect(err).ToNot(HaveOccurred())
+		if !isOpenShift {
+			Skip("the OpenShift tests can only be run against an OpenShift cluster")
+		}
+
+		Expect(fixture.EnsureCleanSlate()).To(Succeed())
+
+		k8sClient, _, err = fixture.GetE2ETestKubeClient()
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx = context.Background()
+
+		nsName = fixture.NamespaceName("test-openshift-ns")
+		Expect(utils.CreateNamespace(ctx, k8sClient, nsName)).To(Succeed())
+
+		By("creating a namespace-scoped RolloutManager")
+		rolloutManager, err = utils.CreateRolloutManager(ctx, k8sClient, "test-openshift-rollouts-manager", nsName, true)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(rolloutManager, "3m", "1s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseAvailable))
+	})
+
+	/*
+		On OpenShift, Pods are admitted by SecurityContextConstraints (SCC), which assign a UID from the range of the Namespace to each Pod.
+		The Rollouts controller should not require any privileges beyond those of the default 'restricted' SCC.
+	*/
+	It("should run the Rollouts controller under the restricted SCC, with a UID from the range of the namespace", func() {
+
+		By("retrieving the UID range assigned to the namespace by OpenShift")
+		namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: nsName}}
+		Eventually(func() string {
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&namespace), &namespace)).To(Succeed())
+			return namespace.Annotations[openshiftFixture.UIDRangeAnnotation]
+		}, "30s", "1s").ShouldNot(BeEmpty())
+		uidRange := strings.Split(namespace.Annotations[openshiftFixture.UIDRangeAnnotation], "/")
+		Expect(uidRange).To(HaveLen(2))
+		uidRangeStart, err := strconv.ParseInt(uidRange[0], 10, 64)
+		Expect(err).ToNot(HaveOccurred())
+		uidRangeSize, err := strconv.ParseInt(uidRange[1], 10, 64)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("retrieving the Pod of the Rollouts controller")
+		var pod corev1.Pod
+		Eventually(func() bool {
+			var podList corev1.PodList
+			if err := k8sClient.List(ctx, &podList, client.InNamespace(nsName), client.MatchingLabels{controllers.DefaultRolloutsSelectorKey: controllers.DefaultArgoRolloutsResourceName}); err != nil {
+				GinkgoWriter.Println("unable to list Pods:", err)
+				return false
+			}
+			for _, item := range podList.Items {
+				if item.Status.Phase == corev1.PodRunning {
+					pod = item
+					return true
+				}
+			}
+			return false
+		}, "3m", "1s").Should(BeTrue())
+
+		By("verifying that the Pod was admitted by the restricted SCC")
+		Expect(pod.Annotations).To(HaveKey(openshiftFixture.SCCAnnotation))
+		Expect(pod.Annotations[openshiftFixture.SCCAnnotation]).To(HavePrefix("restricted"))
+
+		By("verifying that the Pod runs as non-root, with a UID from the range of the namespace")
+		Expect(pod.Spec.SecurityContext).ToNot(BeNil())
+		Expect(pod.Spec.SecurityContext.RunAsNonRoot).ToNot(BeNil())
+		Expect(*pod.Spec.SecurityContext.RunAsNonRoot).To(BeTrue())
+
+		Expect(pod.Spec.Containers).To(HaveLen(1))
+		runAsUser := pod.Spec.SecurityContext.RunAsUser
+		if containerSecurityContext := pod.Spec.Containers[0].SecurityContext; containerSecurityContext != nil && containerSecurityContext.RunAsUser != nil {
+			runAsUser = containerSecurityContext.RunAsUser
+		}
+		Expect(runAsUser).ToNot(BeNil(), "OpenShift should assign a UID to the Pod")
+		Expect(*runAsUser).To(BeNumerically(">=", uidRangeStart))
+		Expect(*runAsUser).To(BeNumerically("<", uidRangeStart+uidRangeSize))
+	})
+
+	It("should configure the OpenShift Route traffic router plugin, and allow the Rollouts controller to manage Routes", func() {
+
+		By("verifying that the ConfigMap contains the OpenShift Route plugin")
+		configMap := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultRolloutsConfigMapName, Namespace: nsName}}
+		Eventually(&configMap, "30s", "1s").Should(k8s.ExistByName(k8sClient))
+
+		var trafficRouterPlugins []map[string]string
+		Expect(yaml.Unmarshal([]byte(configMap.Data[controllers.TrafficRouterPluginConfigMapKey]), &trafficRouterPlugins)).To(Succeed())
+		Expect(trafficRouterPlugins).To(ContainElement(HaveKeyWithValue("name", controllers.OpenShiftRolloutPluginName)))
+
+		By("verifying that the ServiceAccount of the Rollouts controller is allowed to manage Routes in the namespace")
+		for _, verb := range []string{"get", "list", "watch", "create", "update", "patch"} {
+			sar := &authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					User: fmt.Sprintf("system:serviceaccount:%s:%s", nsName, controllers.DefaultArgoRolloutsResourceName),
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: nsName,
+						Verb:      verb,
+						Group:     "route.openshift.io",
+						Resource:  "routes",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, sar)).To(Succeed())
+			Expect(sar.Status.Allowed).To(BeTrue(), "Rollouts controller should be allowed to "+verb+" Routes")
+		}
+	})
+
+	/*
+		In this test, a canary Rollout uses the OpenShift Route plugin to shift traffic between the stable and canary Services.
+		When a new revision is rolled out, the weight of the canary Service in the Route should be updated by the plugin.
+	*/
+	It("should shift the traffic of an OpenShift Route during a canary Rollout", func() {
+
+		const (
+			stableServiceName = "rollout-canary-stable"
+			canaryServiceName = "rollout-canary-canary"
+			routeName         = "rollout-canary-route"
+			canaryWeight      = 20
+		)
+
+		By("creating the stable and canary Services, and the Route")
+		for _, serviceName := range []string{stableServiceName, canaryServiceName} {
+			service := corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: nsName},
+				Spec: corev1.ServiceSpec{
+					Selector: fixture.NamespaceLabels,
+					Ports:    []corev1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 8080}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &service)).To(Succeed())
+		}
+		Expect(openshiftFixture.CreateRoute(ctx, routeName, nsName, stableServiceName)).To(Succeed())
+
+		By("creating a canary Rollout that uses the OpenShift Route plugin")
+		Expect(openshiftFixture.CreateCanaryRolloutWithRoute(ctx, utils.RolloutsName, nsName, stableServiceName, canaryServiceName, routeName, canaryWeight)).To(Succeed())
+
+		Eventually(func() (bool, error) {
+			return rolloutFixture.HasStatusPhase(ctx, utils.RolloutsName, nsName, "Healthy")
+		}, "3m", "1s").Should(BeTrue())
+
+		By("rolling out a new revision, and verifying that the weight of the canary Service is set in the Route")
+		Expect(openshiftFixture.UpdateRolloutRevision(ctx, utils.RolloutsName, nsName, "2")).To(Succeed())
+
+		Eventually(func() (bool, error) {
+			return rolloutFixture.HasStatusPhase(ctx, utils.RolloutsName, nsName, "Paused")
+		}, "3m", "1s").Should(BeTrue())
+
+		Eventually(func() (map[string]int64, error) {
+			return openshiftFixture.GetRouteServiceWeights(ctx, routeName, nsName)
+		}, "1m", "1s").Should(Equal(map[string]int64{
+			stableServiceName: 100 - canaryWeight,
+			canaryServiceName: canaryWeight,
+		}))
+	})
+})
@@ -3,6 +3,7 @@ package e2e
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -11,9 +12,11 @@ import (
 
 	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/k8s"
 	rolloutManagerFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/rolloutmanager"
+	rolloutFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/rollouts"
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	rolloutsmanagerv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
 
@@ -54,7 +57,7 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 			rolloutManager = rolloutsmanagerv1alpha1.RolloutManager{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "basic-rollouts-manager",
-					Namespace: fixture.TestE2ENamespace,
+					Namespace: fixture.TestE2ENamespace(),
 				},
 				Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
 					NamespaceScoped: namespaceScopedParam,
@@ -69,9 +72,34 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 				By("waiting for phase to be \"Available\"")
 				Eventually(rolloutManager, "60s", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
 
+				By("verifying that each component is reported as ready")
+				Eventually(rolloutManager, "30s", "1s").Should(SatisfyAll(
+					rolloutManagerFixture.HaveCondition(rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType, metav1.ConditionTrue, ""),
+					rolloutManagerFixture.HaveComponentReady(rolloutManagerFixture.ComponentController),
+					rolloutManagerFixture.HaveComponentReady(rolloutManagerFixture.ComponentRBAC),
+					rolloutManagerFixture.HaveComponentReady(rolloutManagerFixture.ComponentConfig),
+					rolloutManagerFixture.HaveComponentReady(rolloutManagerFixture.ComponentMonitoring),
+				))
+
 				By("Verify that expected resources are created.")
 				ValidateArgoRolloutManagerResources(ctx, rolloutManager, k8sClient, namespaceScopedParam)
 			})
+
+			It("should report readiness via the kstatus conventions, for the current generation", func() {
+				Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+
+				By("waiting for the Ready condition to be True")
+				Eventually(rolloutManager, "60s", "1s").Should(rolloutManagerFixture.HaveReadyCondition(metav1.ConditionTrue))
+
+				By("updating the spec of the RolloutManager, and verifying that the status reflects the new generation")
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutManager), &rolloutManager)).To(Succeed())
+				previousGeneration := rolloutManager.Generation
+				rolloutManager.Spec.ExtraCommandArgs = []string{"--loglevel", "info"}
+				Expect(k8sClient.Update(ctx, &rolloutManager)).To(Succeed())
+				Expect(rolloutManager.Generation).To(BeNumerically(">", previousGeneration))
+
+				Eventually(rolloutManager, "60s", "1s").Should(rolloutManagerFixture.HaveReadyCondition(metav1.ConditionTrue))
+			})
 		})
 
 		When("A RolloutManager is deleted", func() {
@@ -79,64 +107,38 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 				Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
 				Eventually(rolloutManager, "60s", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
 
-				Expect(k8sClient.Delete(ctx, &rolloutManager)).To(Succeed())
-
-				By("deleting the service account")
-				Eventually(&corev1.ServiceAccount{
-					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: rolloutManager.Namespace},
-				}, "10s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
+				By("verifying that the resources owned by the RolloutManager are discovered")
+				ownedResources, err := rolloutManagerFixture.GetOwnedResources(ctx, rolloutManager)
+				Expect(err).ToNot(HaveOccurred())
 
+				expectedResources := []string{
+					"ServiceAccount " + rolloutManager.Namespace + "/" + controllers.DefaultArgoRolloutsResourceName,
+					"Deployment " + rolloutManager.Namespace + "/" + controllers.DefaultArgoRolloutsResourceName,
+					"Service " + rolloutManager.Namespace + "/" + controllers.DefaultArgoRolloutsMetricsServiceName,
+					"Secret " + rolloutManager.Namespace + "/" + controllers.DefaultRolloutsNotificationSecretName,
+					"ConfigMap " + rolloutManager.Namespace + "/" + controllers.DefaultRolloutsConfigMapName,
+					"ServiceMonitor " + rolloutManager.Namespace + "/" + controllers.DefaultArgoRolloutsResourceName,
+				}
 				if namespaceScopedParam {
-					By("deleting the role")
-					Eventually(&rbacv1.Role{
-						ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: rolloutManager.Namespace},
-					}, "10s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
-
-					By("deleting the role binding")
-					Eventually(&rbacv1.RoleBinding{
-						ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: rolloutManager.Namespace},
-					}, "10s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
-
+					expectedResources = append(expectedResources,
+						"Role "+rolloutManager.Namespace+"/"+controllers.DefaultArgoRolloutsResourceName,
+						"RoleBinding "+rolloutManager.Namespace+"/"+controllers.DefaultArgoRolloutsResourceName)
 				} else {
-					By("deleting the cluster role")
-					Eventually(&rbacv1.ClusterRole{
-						ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName},
-					}, "10s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
-
-					By("deleting the cluster role binding")
-					Eventually(&rbacv1.ClusterRoleBinding{
-						ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName},
-					}, "10s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
-
-					By("deleting three aggregate cluster roles")
-					clusterRoleSuffixes := []string{"aggregate-to-admin", "aggregate-to-edit", "aggregate-to-view"}
-					for _, suffix := range clusterRoleSuffixes {
-						clusterRoleName := "argo-rollouts-" + suffix
-						Eventually(&rbacv1.ClusterRole{
-							ObjectMeta: metav1.ObjectMeta{Name: clusterRoleName},
-						}, "30s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
+					expectedResources = append(expectedResources,
+						"ClusterRole /"+controllers.DefaultArgoRolloutsResourceName,
+						"ClusterRoleBinding /"+controllers.DefaultArgoRolloutsResourceName)
+					for _, suffix := range []string{"aggregate-to-admin", "aggregate-to-edit", "aggregate-to-view"} {
+						expectedResources = append(expectedResources, "ClusterRole /argo-rollouts-"+suffix)
 					}
 				}
+				Expect(ownedResources).To(ContainElements(expectedResources))
 
-				By("deleting the deployment")
-				Eventually(&appsv1.Deployment{
-					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: rolloutManager.Namespace},
-				}, "10s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
-
-				By("deleting the service")
-				Eventually(&corev1.Service{
-					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsMetricsServiceName, Namespace: rolloutManager.Namespace},
-				}, "10s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
-
-				By("deleting the secret")
-				Eventually(&corev1.Secret{
-					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultRolloutsNotificationSecretName, Namespace: rolloutManager.Namespace},
-				}, "30s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
+				Expect(k8sClient.Delete(ctx, &rolloutManager)).To(Succeed())
 
-				By("deleting the serviceMonitor")
-				Eventually(&monitoringv1.ServiceMonitor{
-					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: rolloutManager.Namespace},
-				}, "30s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
+				By("verifying that no resources owned by the RolloutManager remain")
+				Eventually(func() ([]string, error) {
+					return rolloutManagerFixture.GetOwnedResources(ctx, rolloutManager)
+				}, "60s", "1s").Should(BeEmpty())
 
 			})
 		})
@@ -166,7 +168,7 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 					expectedContainerArgs = []string{"--loglevel", "error"}
 				}
 
-				Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(Equal(expectedContainerArgs))
+				Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(k8s.ConsistOfContainerArgs(expectedContainerArgs...))
 
 				By("updating the deployment when the argument in the RolloutManager is updated")
 
@@ -193,7 +195,55 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 				Eventually(func() []string {
 					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&deployment), &deployment)).To(Succeed())
 					return deployment.Spec.Template.Spec.Containers[0].Args
-				}, "10s", "1s").Should(Equal(expectedContainerArgs))
+				}, "10s", "1s").Should(SatisfyAll(
+					k8s.ConsistOfContainerArgs(expectedContainerArgs...),
+					k8s.HaveArgPair("--logformat", "text"),
+					Not(k8s.HaveContainerArg("--loglevel")),
+				))
+			})
+		})
+
+		When("A RolloutManager specifies an extra argument which is already added by the operator", func() {
+			It("should reject the RolloutManager until the argument is removed", func() {
+				rolloutManager.Spec.KubeClient = &rolloutsmanagerv1alpha1.RolloutManagerKubeClientSpec{QPS: 100}
+				rolloutManager.Spec.ExtraCommandArgs = []string{"--qps", "50", "--loglevel", "error"}
+				Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+
+				By("verifying that the conflict is reported in the Reconciled condition")
+				Eventually(rolloutManager, "1m", "1s").Should(rolloutManagerFixture.HaveExactCondition(metav1.Condition{
+					Type:    rolloutsmanagerv1alpha1.RolloutManagerConditionType,
+					Status:  metav1.ConditionFalse,
+					Reason:  rolloutsmanagerv1alpha1.RolloutManagerReasonConflictingCommandArgs,
+					Message: "the following command arguments are already added by the operator, from the fields of the RolloutManager, and must be removed from extraCommandArgs: --qps (added by the operator as '--qps=100')",
+				}))
+				Expect(rolloutManager).To(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseFailure))
+
+				deployment := appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: rolloutManager.Namespace},
+				}
+				Consistently(&deployment, "10s", "1s").ShouldNot(k8s.ExistByName(k8sClient))
+
+				By("removing the conflicting argument, and verifying that the operator and extra arguments are passed in a deterministic order")
+				err := k8s.UpdateWithoutConflict(ctx, &rolloutManager, k8sClient, func(obj client.Object) {
+					goObj, ok := obj.(*rolloutsmanagerv1alpha1.RolloutManager)
+					Expect(ok).To(BeTrue())
+					goObj.Spec.ExtraCommandArgs = []string{"--loglevel", "error"}
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(rolloutManager, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+
+				var expectedContainerArgs []string
+				if namespaceScopedParam {
+					expectedContainerArgs = []string{"--namespaced", "--qps=100", "--loglevel", "error"}
+				} else {
+					expectedContainerArgs = []string{"--qps=100", "--loglevel", "error"}
+				}
+				Eventually(func() []string {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&deployment), &deployment); err != nil {
+						return nil
+					}
+					return deployment.Spec.Template.Spec.Containers[0].Args
+				}, "30s", "1s").Should(Equal(expectedContainerArgs))
 			})
 		})
 
@@ -255,7 +305,14 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 				rolloutManager.Spec.Version = "latest"
 
 				Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
-				Eventually(rolloutManager, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhasePending))
+
+				// busybox is not a Rollouts controller: its container exits immediately, and is restarted with a backoff
+				Eventually(rolloutManager, "2m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseDegraded))
+				Expect(rolloutManager).To(SatisfyAll(
+					rolloutManagerFixture.HaveCondition(rolloutsmanagerv1alpha1.RolloutManagerControllerDegradedConditionType, metav1.ConditionTrue, ""),
+					rolloutManagerFixture.HaveCondition(rolloutsmanagerv1alpha1.RolloutManagerReadyConditionType, metav1.ConditionFalse, ""),
+					Not(rolloutManagerFixture.HaveComponentReady(rolloutManagerFixture.ComponentController)),
+				))
 
 				deployment := appsv1.Deployment{
 					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: rolloutManager.Namespace},
@@ -285,7 +342,7 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 				expectedServiceMonitor := &monitoringv1.ServiceMonitor{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      controllers.DefaultArgoRolloutsResourceName,
-						Namespace: fixture.TestE2ENamespace,
+						Namespace: fixture.TestE2ENamespace(),
 					},
 					Spec: monitoringv1.ServiceMonitorSpec{
 						Selector: metav1.LabelSelector{
@@ -296,6 +353,12 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 						Endpoints: []monitoringv1.Endpoint{
 							{
 								Port: "metrics",
+								RelabelConfigs: []*monitoringv1.RelabelConfig{
+									{
+										SourceLabels: []string{"__meta_kubernetes_service_label_argo_rollouts_argoproj_io_rolloutmanager"},
+										TargetLabel:  controllers.RolloutManagerMetricsLabel,
+									},
+								},
 							},
 						},
 					},
@@ -305,7 +368,7 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 				sm := &monitoringv1.ServiceMonitor{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      controllers.DefaultArgoRolloutsResourceName,
-						Namespace: fixture.TestE2ENamespace,
+						Namespace: fixture.TestE2ENamespace(),
 					},
 				}
 
@@ -391,7 +454,7 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 				rolloutsManager := rolloutsmanagerv1alpha1.RolloutManager{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "basic-rollouts-manager-with-metadata",
-						Namespace: fixture.TestE2ENamespace,
+						Namespace: fixture.TestE2ENamespace(),
 					},
 					Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
 						AdditionalMetadata: &rolloutsmanagerv1alpha1.ResourceMetadata{
@@ -455,7 +518,7 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 				rmWithResources := rolloutsmanagerv1alpha1.RolloutManager{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "basic-rollouts-manager-with-resources",
-						Namespace: fixture.TestE2ENamespace,
+						Namespace: fixture.TestE2ENamespace(),
 					},
 					Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
 						ControllerResources: &corev1.ResourceRequirements{
@@ -514,7 +577,7 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 			rolloutsManager := rolloutsmanagerv1alpha1.RolloutManager{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "basic-rollouts-manager-with-skip-notification-secret",
-					Namespace: fixture.TestE2ENamespace,
+					Namespace: fixture.TestE2ENamespace(),
 				},
 				Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
 					NamespaceScoped:                  namespaceScopedParam,
@@ -555,13 +618,241 @@ func RunRolloutsTests(namespaceScopedParam bool) {
 			Entry("skipNotification is initially false, then set to true", false),
 		)
 
+		When("the traffic router plugins of the Rollouts ConfigMap are modified", func() {
+
+			/*
+				The RolloutManager does not yet have a field for configuring plugins: the operator configures the OpenShift Route plugin in the Rollouts ConfigMap, and preserves the plugins that are added to it by users.
+				This test verifies that:
+				- the ConfigMap contains the OpenShift Route plugin
+				- a (dummy) plugin added by the user, with a local file location, is preserved
+				- modifications/removal of the OpenShift Route plugin are reverted by the operator
+				Init containers for downloading plugins, checksum verification, and restarting the Rollouts controller when the plugins change are not covered, as the operator does not support them yet.
+			*/
+			It("should preserve the plugins added by the user, and revert modifications of the OpenShift Route plugin", func() {
+
+				const (
+					dummyPluginName     = "e2e-test/dummy"
+					dummyPluginLocation = "file:///home/argo-rollouts/plugin-bin/dummy"
+				)
+
+				type pluginItem struct {
+					Name     string `json:"name"`
+					Location string `json:"location"`
+				}
+
+				getPlugins := func(configMap *corev1.ConfigMap) []pluginItem {
+					var plugins []pluginItem
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)).To(Succeed())
+					Expect(yaml.Unmarshal([]byte(configMap.Data[controllers.TrafficRouterPluginConfigMapKey]), &plugins)).To(Succeed())
+					return plugins
+				}
+
+				setPlugins := func(configMap *corev1.ConfigMap, plugins []pluginItem) {
+					pluginsBytes, err := yaml.Marshal(plugins)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(k8s.UpdateWithoutConflict(ctx, configMap, k8sClient, func(obj client.Object) {
+						goObj, ok := obj.(*corev1.ConfigMap)
+						Expect(ok).To(BeTrue())
+						goObj.Data[controllers.TrafficRouterPluginConfigMapKey] = string(pluginsBytes)
+					})).To(Succeed())
+				}
+
+				Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+				Eventually(rolloutManager, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+
+				By("verifying that the ConfigMap contains the OpenShift Route plugin")
+				configMap := corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultRolloutsConfigMapName, Namespace: rolloutManager.Namespace},
+				}
+				Eventually(&configMap, "10s", "1s").Should(k8s.ExistByName(k8sClient))
+
+				plugins := getPlugins(&configMap)
+				Expect(plugins).To(HaveLen(1))
+				Expect(plugins[0].Name).To(Equal(controllers.OpenShiftRolloutPluginName))
+				Expect(plugins[0].Location).ToNot(BeEmpty())
+				openShiftPlugin := plugins[0]
+
+				By("adding a dummy plugin with a local file location, and verifying that it is preserved")
+				dummyPlugin := pluginItem{Name: dummyPluginName, Location: dummyPluginLocation}
+				setPlugins(&configMap, []pluginItem{dummyPlugin, openShiftPlugin})
+				Consistently(func() []pluginItem {
+					return getPlugins(&configMap)
+				}, "10s", "1s").Should(ConsistOf(dummyPlugin, openShiftPlugin))
+
+				By("modifying the location of the OpenShift Route plugin, and verifying that it is reverted")
+				setPlugins(&configMap, []pluginItem{dummyPlugin, {Name: controllers.OpenShiftRolloutPluginName, Location: "file:///tmp/modified"}})
+				Eventually(func() []pluginItem {
+					return getPlugins(&configMap)
+				}, "30s", "1s").Should(ConsistOf(dummyPlugin, openShiftPlugin))
+
+				By("removing the OpenShift Route plugin, and verifying that it is added back")
+				setPlugins(&configMap, []pluginItem{dummyPlugin})
+				Eventually(func() []pluginItem {
+					return getPlugins(&configMap)
+				}, "30s", "1s").Should(ConsistOf(dummyPlugin, openShiftPlugin))
+			})
+		})
+
+		When("the image of a canary Rollout is updated", func() {
+
+			/*
+				This test verifies that the Rollouts controller installed by the operator is functional (its RBAC and configuration are sufficient), rather than only verifying that the expected resources exist:
+				- a Rollout with a canary strategy is created, in the namespace of the RolloutManager
+				- the image of the Rollout is updated
+				- the Rollouts controller should advance through each of the steps of the canary strategy, until the new revision is fully promoted
+			*/
+			It("should advance the Rollout through each of the canary steps", func() {
+
+				const (
+					initialImage = "nginxinc/nginx-unprivileged@sha256:0569e319d06556564ad40882ed35231461d06bec788b5aec00b83b6e9f3ced1a"
+					updatedImage = "nginxinc/nginx-unprivileged:1.27-alpine"
+				)
+
+				Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+				Eventually(rolloutManager, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+
+				By("creating a canary Rollout, and waiting for it to be Healthy")
+				Expect(rolloutFixture.CreateCanaryArgoRollout(ctx, RolloutsName, rolloutManager.Namespace, initialImage)).To(Succeed())
+
+				Eventually(func() (bool, error) {
+					return rolloutFixture.HasStatusPhase(ctx, RolloutsName, rolloutManager.Namespace, "Healthy")
+				}, "3m", "1s").Should(BeTrue())
+
+				By("updating the image of the Rollout")
+				Expect(rolloutFixture.UpdateArgoRolloutImage(ctx, RolloutsName, rolloutManager.Namespace, updatedImage)).To(Succeed())
+
+				By("verifying that the Rollouts controller pauses the Rollout at the pause step")
+				Eventually(func() (int64, error) {
+					return rolloutFixture.GetCurrentStepIndex(ctx, RolloutsName, rolloutManager.Namespace)
+				}, "2m", "1s").Should(Equal(int64(1)))
+
+				By("verifying that the Rollouts controller advances through the remaining steps, and fully promotes the new revision")
+				Eventually(func() (int64, error) {
+					return rolloutFixture.GetCurrentStepIndex(ctx, RolloutsName, rolloutManager.Namespace)
+				}, "2m", "1s").Should(Equal(int64(rolloutFixture.CanaryRolloutSteps)))
+
+				Eventually(func() (bool, error) {
+					return rolloutFixture.IsFullyPromoted(ctx, RolloutsName, rolloutManager.Namespace)
+				}, "2m", "1s").Should(BeTrue())
+
+				Eventually(func() (bool, error) {
+					return rolloutFixture.HasStatusPhase(ctx, RolloutsName, rolloutManager.Namespace, "Healthy")
+				}, "3m", "1s").Should(BeTrue())
+			})
+		})
+
+		DescribeTable("when a resource managed by the RolloutManager is deleted, it should be recreated by the operator", func(obj client.Object) {
+
+			Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+			Eventually(rolloutManager, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+			Eventually(obj, "30s", "1s").Should(k8s.ExistByName(k8sClient))
+
+			By("deleting the resource, and waiting for the operator to recreate it")
+			Expect(k8s.DeleteAndWaitForRecreation(ctx, obj, k8sClient, 30*time.Second)).To(Succeed())
+
+			By("verifying that the RolloutManager remains Available, and that all of the resources are as expected")
+			Eventually(rolloutManager, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+			ValidateArgoRolloutManagerResources(ctx, rolloutManager, k8sClient, namespaceScopedParam)
+		},
+			Entry("Deployment", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: fixture.TestE2ENamespace()},
+			}),
+			Entry("ServiceAccount", &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: fixture.TestE2ENamespace()},
+			}),
+			Entry("Role or ClusterRole", func() client.Object {
+				if namespaceScopedParam {
+					return &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: fixture.TestE2ENamespace()}}
+				}
+				return &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName}}
+			}()),
+			Entry("RoleBinding or ClusterRoleBinding", func() client.Object {
+				if namespaceScopedParam {
+					return &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: fixture.TestE2ENamespace()}}
+				}
+				return &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName}}
+			}()),
+			Entry("metrics Service", &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsMetricsServiceName, Namespace: fixture.TestE2ENamespace()},
+			}),
+			Entry("ServiceMonitor", &monitoringv1.ServiceMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: fixture.TestE2ENamespace()},
+			}),
+			Entry("ConfigMap", &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultRolloutsConfigMapName, Namespace: fixture.TestE2ENamespace()},
+			}),
+			Entry("notification Secret", &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultRolloutsNotificationSecretName, Namespace: fixture.TestE2ENamespace()},
+			}),
+		)
+
+		When("every field of the Rollouts Deployment spec that is managed by the operator is modified", func() {
+			It("should revert each of the fields to the expected value", func() {
+
+				Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+				Eventually(rolloutManager, "1m", "1s").Should(rolloutManagerFixture.HavePhase(rolloutsmanagerv1alpha1.PhaseAvailable))
+
+				deployment := appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: rolloutManager.Namespace},
+				}
+				Eventually(&deployment, "10s", "1s").Should(k8s.ExistByName(k8sClient))
+				expectedSpec := deployment.Spec.DeepCopy()
+
+				By("modifying each of the fields of the Deployment spec")
+				err := k8s.UpdateWithoutConflict(ctx, &deployment, k8sClient, func(obj client.Object) {
+					goObj, ok := obj.(*appsv1.Deployment)
+					Expect(ok).To(BeTrue())
+
+					goObj.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+
+					runAsUser := int64(1234)
+					podSpec := &goObj.Spec.Template.Spec
+					podSpec.ServiceAccountName = "default"
+					podSpec.NodeSelector = map[string]string{"drifted": "true"}
+					podSpec.Tolerations = []corev1.Toleration{{Key: "drifted", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+					podSpec.SecurityContext = &corev1.PodSecurityContext{RunAsUser: &runAsUser}
+					podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{Name: "drifted", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+
+					container := &podSpec.Containers[0]
+					container.Image = "quay.io/prometheus/busybox:latest"
+					container.Args = append(container.Args, "--loglevel", "debug")
+					container.Env = append(container.Env, corev1.EnvVar{Name: "DRIFTED", Value: "true"})
+					container.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}
+
+					goObj.Spec.Template.Labels["drifted"] = "true"
+					if goObj.Spec.Template.Annotations == nil {
+						goObj.Spec.Template.Annotations = map[string]string{}
+					}
+					goObj.Spec.Template.Annotations["drifted"] = "true"
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				By("verifying that each field is reverted to the expected value")
+				Eventually(func() appsv1.DeploymentSpec {
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&deployment), &deployment)).To(Succeed())
+					return deployment.Spec
+				}, "30s", "1s").Should(SatisfyAll(
+					WithTransform(func(spec appsv1.DeploymentSpec) appsv1.DeploymentStrategy { return spec.Strategy }, Equal(expectedSpec.Strategy)),
+					WithTransform(func(spec appsv1.DeploymentSpec) *metav1.LabelSelector { return spec.Selector }, Equal(expectedSpec.Selector)),
+					WithTransform(func(spec appsv1.DeploymentSpec) map[string]string { return spec.Template.Labels }, Equal(expectedSpec.Template.Labels)),
+					WithTransform(func(spec appsv1.DeploymentSpec) map[string]string { return spec.Template.Annotations }, Equal(expectedSpec.Template.Annotations)),
+					WithTransform(func(spec appsv1.DeploymentSpec) string { return spec.Template.Spec.ServiceAccountName }, Equal(expectedSpec.Template.Spec.ServiceAccountName)),
+					WithTransform(func(spec appsv1.DeploymentSpec) map[string]string { return spec.Template.Spec.NodeSelector }, Equal(expectedSpec.Template.Spec.NodeSelector)),
+					WithTransform(func(spec appsv1.DeploymentSpec) []corev1.Toleration { return spec.Template.Spec.Tolerations }, Equal(expectedSpec.Template.Spec.Tolerations)),
+					WithTransform(func(spec appsv1.DeploymentSpec) *corev1.PodSecurityContext { return spec.Template.Spec.SecurityContext }, Equal(expectedSpec.Template.Spec.SecurityContext)),
+					WithTransform(func(spec appsv1.DeploymentSpec) []corev1.Volume { return spec.Template.Spec.Volumes }, Equal(expectedSpec.Template.Spec.Volumes)),
+					WithTransform(func(spec appsv1.DeploymentSpec) []corev1.Container { return spec.Template.Spec.Containers }, Equal(expectedSpec.Template.Spec.Containers)),
+				))
+			})
+		})
+
 		When("A RolloutManager is deleted but the notification secret is owned by another controller", func() {
 			It("should not delete the secret", func() {
 
 				rolloutsManager := rolloutsmanagerv1alpha1.RolloutManager{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "basic-rollouts-manager-without-secret",
-						Namespace: fixture.TestE2ENamespace,
+						Namespace: fixture.TestE2ENamespace(),
 					},
 					Spec: rolloutsmanagerv1alpha1.RolloutManagerSpec{
 						NamespaceScoped:                  namespaceScopedParam,
@@ -0,0 +1,142 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	utils "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e"
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
+	metricsFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/metrics"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rmv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+)
+
+const (
+	// The following environment variables can be used to configure the scale test, and the SLOs it asserts.
+
+	// scaleRolloutManagersEnvName is the number of namespace-scoped RolloutManagers to create
+	scaleRolloutManagersEnvName = "E2E_SCALE_ROLLOUT_MANAGERS"
+
+	// scaleMaxAvailableDurationEnvName is the maximum time for all RolloutManagers to become Available
+	scaleMaxAvailableDurationEnvName = "E2E_SCALE_MAX_AVAILABLE_DURATION"
+
+	// scaleMaxAverageReconcileEnvName is the maximum average duration of a single reconciliation, as reported by the operator metrics
+	scaleMaxAverageReconcileEnvName = "E2E_SCALE_MAX_AVERAGE_RECONCILE_DURATION"
+
+	// scaleMaxMemoryMiBEnvName is the maximum resident memory of the operator process, in MiB
+	scaleMaxMemoryMiBEnvName = "E2E_SCALE_MAX_MEMORY_MIB"
+)
+
+// getEnvInt returns the value of the given environment variable as an int, or the default value if it is not set.
+func getEnvInt(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	res, err := strconv.Atoi(value)
+	Expect(err).ToNot(HaveOccurred(), "invalid value for "+name)
+	return res
+}
+
+// getEnvDuration returns the value of the given environment variable as a duration, or the default value if it is not set.
+func getEnvDuration(name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	res, err := time.ParseDuration(value)
+	Expect(err).ToNot(HaveOccurred(), "invalid value for "+name)
+	return res
+}
+
+// The scale tests measure the resources used by the operator, so they should not run in parallel with other tests.
+var _ = Describe("RolloutManager scale tests", Serial, func() {
+
+	var (
+		err       error
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	BeforeEach(func() {
+		Expect(fixture.EnsureCleanSlate()).To(Succeed())
+
+		k8sClient, _, err = fixture.GetE2ETestKubeClient()
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx = context.Background()
+	})
+
+	/*
+		In this test, N namespace-scoped RolloutManagers are created, each in its own namespace.
+		The test then verifies that:
+		- all of the RolloutManagers become Available within the expected time
+		- the average reconciliation duration reported by the operator is within the expected bounds
+		- the memory used by the operator is within the expected bounds
+		This test requires the operator to run in namespace-scoped mode, with its metrics endpoint reachable from the test (see E2E_OPERATOR_METRICS_URL).
+	*/
+	It("should reconcile many namespace-scoped RolloutManagers within the SLOs", func() {
+
+		numRolloutManagers := getEnvInt(scaleRolloutManagersEnvName, 20)
+		maxAvailableDuration := getEnvDuration(scaleMaxAvailableDurationEnvName, 5*time.Minute)
+		maxAverageReconcileDuration := getEnvDuration(scaleMaxAverageReconcileEnvName, time.Second)
+		maxMemoryMiB := getEnvInt(scaleMaxMemoryMiBEnvName, 512)
+
+		By(fmt.Sprintf("creating %d namespace-scoped RolloutManagers, each in its own namespace", numRolloutManagers))
+
+		start := time.Now()
+
+		var rolloutManagers []rmv1alpha1.RolloutManager
+		for i := 0; i < numRolloutManagers; i++ {
+			nsName := fmt.Sprintf("scale-ns-%d", i)
+			Expect(utils.CreateNamespace(ctx, k8sClient, nsName)).To(Succeed())
+
+			rolloutManager, err := utils.CreateRolloutManager(ctx, k8sClient, "scale-rollouts-manager", nsName, true)
+			Expect(err).ToNot(HaveOccurred())
+			rolloutManagers = append(rolloutManagers, rolloutManager)
+		}
+
+		By("waiting for all the RolloutManagers to become Available")
+		Eventually(func() int {
+			available := 0
+			for idx := range rolloutManagers {
+				rolloutManager := rolloutManagers[idx]
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutManager), &rolloutManager); err != nil {
+					GinkgoWriter.Println("unable to get RolloutManager:", err)
+					continue
+				}
+				if rolloutManager.Status.Phase == rmv1alpha1.PhaseAvailable {
+					available++
+				}
+			}
+			GinkgoWriter.Printf("%d/%d RolloutManagers are Available\n", available, len(rolloutManagers))
+			return available
+		}, maxAvailableDuration, "5s").Should(Equal(numRolloutManagers), "all RolloutManagers should become Available within the SLO")
+
+		GinkgoWriter.Printf("All %d RolloutManagers became Available in %v\n", numRolloutManagers, time.Since(start))
+
+		By("retrieving the operator metrics")
+		metrics, err := metricsFixture.GetOperatorMetrics()
+		Expect(err).ToNot(HaveOccurred())
+
+		By("verifying the average reconciliation duration is within the SLO")
+		averageReconcileSeconds, err := metricsFixture.GetHistogramAverage(metrics, "controller_runtime_reconcile_time_seconds", map[string]string{"controller": "rolloutmanager"})
+		Expect(err).ToNot(HaveOccurred())
+		GinkgoWriter.Printf("Average reconciliation duration: %.3fs\n", averageReconcileSeconds)
+		Expect(averageReconcileSeconds).To(BeNumerically("<=", maxAverageReconcileDuration.Seconds()))
+
+		By("verifying the operator memory usage is within the SLO")
+		residentMemoryBytes, err := metricsFixture.GetGaugeValue(metrics, "process_resident_memory_bytes", nil)
+		Expect(err).ToNot(HaveOccurred())
+		GinkgoWriter.Printf("Operator resident memory: %.1fMiB\n", residentMemoryBytes/(1024*1024))
+		Expect(residentMemoryBytes).To(BeNumerically("<=", float64(maxMemoryMiB)*1024*1024))
+	})
+})
@@ -0,0 +1,326 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	utils "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e"
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/k8s"
+	metricsFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/metrics"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rmv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	controllers "github.com/argoproj-labs/argo-rollouts-manager/controllers"
+)
+
+const (
+	// The following environment variables can be used to configure the soak test, and the thresholds it asserts.
+
+	// soakDurationEnvName is how long the RolloutManagers should be updated for. The soak test is skipped if it is not set.
+	soakDurationEnvName = "E2E_SOAK_DURATION"
+
+	// soakRolloutManagersEnvName is the number of namespace-scoped RolloutManagers to update
+	soakRolloutManagersEnvName = "E2E_SOAK_ROLLOUT_MANAGERS"
+
+	// soakUpdateIntervalEnvName is the time between two updates of each RolloutManager
+	soakUpdateIntervalEnvName = "E2E_SOAK_UPDATE_INTERVAL"
+
+	// soakAlternateVersionEnvName is the version of the Rollouts controller image that is alternated with the default version, when the image is flipped
+	soakAlternateVersionEnvName = "E2E_SOAK_ALTERNATE_VERSION"
+
+	// soakMaxMemoryGrowthMiBEnvName is the maximum growth of the resident memory of the operator process, in MiB, between the end of the first round of updates and the end of the test
+	soakMaxMemoryGrowthMiBEnvName = "E2E_SOAK_MAX_MEMORY_GROWTH_MIB"
+
+	// soakMaxPhaseTransitionsPerUpdateEnvName is the maximum average number of .status.phase transitions of a RolloutManager, per update of its .spec
+	soakMaxPhaseTransitionsPerUpdateEnvName = "E2E_SOAK_MAX_PHASE_TRANSITIONS_PER_UPDATE"
+
+	// soakMaxWritesPerUpdateEnvName is the maximum average number of write requests (POST/PUT/PATCH/DELETE) sent by the operator to the API server, per update of a RolloutManager .spec
+	soakMaxWritesPerUpdateEnvName = "E2E_SOAK_MAX_WRITES_PER_UPDATE"
+)
+
+// getEnvInt returns the value of the given environment variable as an int, or the default value if it is not set.
+func getEnvInt(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	res, err := strconv.Atoi(value)
+	Expect(err).ToNot(HaveOccurred(), "invalid value for "+name)
+	return res
+}
+
+// getEnvDuration returns the value of the given environment variable as a duration, or the default value if it is not set.
+func getEnvDuration(name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	res, err := time.ParseDuration(value)
+	Expect(err).ToNot(HaveOccurred(), "invalid value for "+name)
+	return res
+}
+
+// phaseMonitor polls the RolloutManagers in the background, and records the transitions of their .status.phase, as well as each time that one of them is in the Failure phase.
+type phaseMonitor struct {
+	mutex       sync.Mutex
+	transitions int
+	failures    []string
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+func startPhaseMonitor(ctx context.Context, k8sClient client.Client, rolloutManagers []rmv1alpha1.RolloutManager) *phaseMonitor {
+	monitor := &phaseMonitor{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer GinkgoRecover()
+		defer close(monitor.done)
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		lastPhases := map[string]rmv1alpha1.RolloutControllerPhase{}
+
+		for {
+			for idx := range rolloutManagers {
+				rolloutManager := rolloutManagers[idx]
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutManager), &rolloutManager); err != nil {
+					GinkgoWriter.Println("unable to get RolloutManager:", err)
+					continue
+				}
+
+				phase := rolloutManager.Status.Phase
+				if lastPhase, exists := lastPhases[rolloutManager.Namespace]; exists && lastPhase != phase {
+					monitor.recordTransition()
+				}
+				lastPhases[rolloutManager.Namespace] = phase
+
+				if phase == rmv1alpha1.PhaseFailure {
+					monitor.recordFailure(fmt.Sprintf("%s: RolloutManager in namespace %s is in phase %s", time.Now().Format(time.RFC3339), rolloutManager.Namespace, phase))
+				}
+			}
+
+			select {
+			case <-monitor.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return monitor
+}
+
+func (monitor *phaseMonitor) recordTransition() {
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	monitor.transitions++
+}
+
+func (monitor *phaseMonitor) recordFailure(failure string) {
+	GinkgoWriter.Println(failure)
+
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	monitor.failures = append(monitor.failures, failure)
+}
+
+// Stop stops the monitor, and returns the number of phase transitions and the failures that were recorded.
+func (monitor *phaseMonitor) Stop() (int, []string) {
+	close(monitor.stop)
+	<-monitor.done
+
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	return monitor.transitions, monitor.failures
+}
+
+// getOperatorWritesAndMemory returns the total number of write requests sent by the operator to the API server, and its resident memory in bytes, as reported by its metrics.
+func getOperatorWritesAndMemory() (float64, float64) {
+	metrics, err := metricsFixture.GetOperatorMetrics()
+	Expect(err).ToNot(HaveOccurred())
+
+	writes := 0.0
+	for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+		requests, err := metricsFixture.GetCounterSum(metrics, "rest_client_requests_total", map[string]string{"method": method})
+		Expect(err).ToNot(HaveOccurred())
+		writes += requests
+	}
+
+	residentMemoryBytes, err := metricsFixture.GetGaugeValue(metrics, "process_resident_memory_bytes", nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	return writes, residentMemoryBytes
+}
+
+// churnRolloutManager modifies the .spec of the RolloutManager for the given iteration: the Rollouts controller image, environment variables and additional metadata are updated in turn.
+func churnRolloutManager(rolloutManager *rmv1alpha1.RolloutManager, iteration int, alternateVersion string) {
+	switch iteration % 3 {
+	case 0:
+		if rolloutManager.Spec.Version == alternateVersion {
+			rolloutManager.Spec.Image = ""
+			rolloutManager.Spec.Version = ""
+		} else {
+			rolloutManager.Spec.Image = controllers.DefaultArgoRolloutsImage
+			rolloutManager.Spec.Version = alternateVersion
+		}
+	case 1:
+		rolloutManager.Spec.Env = []corev1.EnvVar{{Name: "SOAK_ITERATION", Value: strconv.Itoa(iteration)}}
+	case 2:
+		rolloutManager.Spec.AdditionalMetadata = &rmv1alpha1.ResourceMetadata{
+			Labels:      map[string]string{"soak-iteration": strconv.Itoa(iteration)},
+			Annotations: map[string]string{"soak-iteration": strconv.Itoa(iteration)},
+		}
+	}
+}
+
+// The soak test measures the resources used by the operator over a long period, so it should not run in parallel with other tests.
+var _ = Describe("RolloutManager soak tests", Serial, func() {
+
+	var (
+		err       error
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	BeforeEach(func() {
+		if os.Getenv(soakDurationEnvName) == "" {
+			Skip(soakDurationEnvName + " is not set: the soak test is only run when its duration is specified")
+		}
+
+		Expect(fixture.EnsureCleanSlate()).To(Succeed())
+
+		k8sClient, _, err = fixture.GetE2ETestKubeClient()
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx = context.Background()
+	})
+
+	/*
+		In this test, N namespace-scoped RolloutManagers are created, and are then repeatedly updated (image flips, environment variable churn and metadata churn) for the duration of the test.
+		The test then verifies that:
+		- the memory used by the operator does not grow beyond the expected bounds, after the first round of updates
+		- the .status.phase of the RolloutManagers does not flap: it is never Failure, and does not change more than expected for each update
+		- the number of write requests sent by the operator to the API server, for each update, is within the expected bounds
+		This test requires the operator to run in namespace-scoped mode, with its metrics endpoint reachable from the test (see E2E_OPERATOR_METRICS_URL).
+	*/
+	It("should not leak memory, flap status or amplify writes while RolloutManagers are repeatedly updated", func() {
+
+		soakDuration := getEnvDuration(soakDurationEnvName, 0)
+		numRolloutManagers := getEnvInt(soakRolloutManagersEnvName, 5)
+		updateInterval := getEnvDuration(soakUpdateIntervalEnvName, 30*time.Second)
+		alternateVersion := os.Getenv(soakAlternateVersionEnvName)
+		if alternateVersion == "" {
+			alternateVersion = "v1.7.0"
+		}
+		maxMemoryGrowthMiB := getEnvInt(soakMaxMemoryGrowthMiBEnvName, 64)
+		maxPhaseTransitionsPerUpdate := getEnvInt(soakMaxPhaseTransitionsPerUpdateEnvName, 2)
+		maxWritesPerUpdate := getEnvInt(soakMaxWritesPerUpdateEnvName, 15)
+
+		By(fmt.Sprintf("creating %d namespace-scoped RolloutManagers, each in its own namespace", numRolloutManagers))
+
+		var rolloutManagers []rmv1alpha1.RolloutManager
+		for i := 0; i < numRolloutManagers; i++ {
+			nsName := fmt.Sprintf("soak-ns-%d", i)
+			Expect(utils.CreateNamespace(ctx, k8sClient, nsName)).To(Succeed())
+
+			rolloutManager, err := utils.CreateRolloutManager(ctx, k8sClient, "soak-rollouts-manager", nsName, true)
+			Expect(err).ToNot(HaveOccurred())
+			rolloutManagers = append(rolloutManagers, rolloutManager)
+		}
+
+		waitForAllAvailable := func() {
+			Eventually(func() int {
+				available := 0
+				for idx := range rolloutManagers {
+					rolloutManager := rolloutManagers[idx]
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutManager), &rolloutManager); err != nil {
+						GinkgoWriter.Println("unable to get RolloutManager:", err)
+						continue
+					}
+					if rolloutManager.Status.Phase == rmv1alpha1.PhaseAvailable {
+						available++
+					}
+				}
+				return available
+			}, "5m", "5s").Should(Equal(numRolloutManagers), "all RolloutManagers should be Available")
+		}
+
+		By("waiting for all the RolloutManagers to become Available")
+		waitForAllAvailable()
+
+		initialWrites, _ := getOperatorWritesAndMemory()
+
+		monitor := startPhaseMonitor(ctx, k8sClient, rolloutManagers)
+
+		By(fmt.Sprintf("updating the RolloutManagers every %v, for %v", updateInterval, soakDuration))
+
+		start := time.Now()
+		updates := 0
+		baselineMemoryBytes := 0.0
+		maxMemoryBytes := 0.0
+
+		for iteration := 0; time.Since(start) < soakDuration; iteration++ {
+
+			for idx := range rolloutManagers {
+				Expect(k8s.UpdateWithoutConflict(ctx, &rolloutManagers[idx], k8sClient, func(obj client.Object) {
+					churnRolloutManager(obj.(*rmv1alpha1.RolloutManager), iteration, alternateVersion)
+				})).To(Succeed())
+				updates++
+			}
+
+			time.Sleep(updateInterval)
+
+			_, residentMemoryBytes := getOperatorWritesAndMemory()
+			if residentMemoryBytes > maxMemoryBytes {
+				maxMemoryBytes = residentMemoryBytes
+			}
+
+			// The first round of updates (one of each kind) warms up the caches of the operator: memory growth is measured from the end of it
+			if iteration == 2 {
+				baselineMemoryBytes = residentMemoryBytes
+			}
+
+			GinkgoWriter.Printf("%v: %d updates, operator resident memory: %.1fMiB\n", time.Since(start).Round(time.Second), updates, residentMemoryBytes/(1024*1024))
+		}
+
+		By("waiting for all the RolloutManagers to become Available after the last update")
+		waitForAllAvailable()
+
+		transitions, failures := monitor.Stop()
+		finalWrites, finalMemoryBytes := getOperatorWritesAndMemory()
+
+		Expect(updates).To(BeNumerically(">", 0))
+
+		By("verifying that the RolloutManagers were never in the Failure phase")
+		Expect(failures).To(BeEmpty())
+
+		By("verifying that the phase of the RolloutManagers did not flap")
+		GinkgoWriter.Printf("Phase transitions: %d, for %d updates\n", transitions, updates)
+		Expect(float64(transitions) / float64(updates)).To(BeNumerically("<=", float64(maxPhaseTransitionsPerUpdate)))
+
+		By("verifying that the writes to the API server were not amplified")
+		writesPerUpdate := (finalWrites - initialWrites) / float64(updates)
+		GinkgoWriter.Printf("Write requests: %.0f, for %d updates (%.1f per update)\n", finalWrites-initialWrites, updates, writesPerUpdate)
+		Expect(writesPerUpdate).To(BeNumerically("<=", float64(maxWritesPerUpdate)))
+
+		if baselineMemoryBytes > 0 {
+			By("verifying that the memory used by the operator did not grow")
+			GinkgoWriter.Printf("Operator resident memory: baseline %.1fMiB, max %.1fMiB, final %.1fMiB\n", baselineMemoryBytes/(1024*1024), maxMemoryBytes/(1024*1024), finalMemoryBytes/(1024*1024))
+			Expect(finalMemoryBytes - baselineMemoryBytes).To(BeNumerically("<=", float64(maxMemoryGrowthMiB)*1024*1024))
+		}
+	})
+})
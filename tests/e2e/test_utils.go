@@ -36,7 +36,7 @@ func CreateNamespace(ctx context.Context, k8sClient client.Client, name string)
 	return k8sClient.Create(ctx,
 		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
 			Name:   name,
-			Labels: fixture.NamespaceLabels,
+			Labels: fixture.TestNamespaceLabels(),
 		}})
 }
 
@@ -64,6 +64,11 @@ func CreateRolloutManagerWithMetadata(ctx context.Context, k8sClient client.Clie
 
 // Create Service used by Rollout
 func CreateService(ctx context.Context, k8sClient client.Client, name, namespace string, nodePort int32) (corev1.Service, error) {
+	if fixture.IsRunningInParallel() {
+		// NodePorts are shared by the whole cluster, so let Kubernetes allocate them, to avoid conflicts between the parallel processes
+		nodePort = 0
+	}
+
 	service := corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -348,6 +353,7 @@ func validateService(k8sClient client.Client, rolloutsManager rmv1alpha1.Rollout
 	Expect(service.Labels["app.kubernetes.io/name"]).To(Equal(controllers.DefaultArgoRolloutsMetricsServiceName))
 	Expect(service.Labels["app.kubernetes.io/part-of"]).To(Equal(controllers.DefaultArgoRolloutsResourceName))
 	Expect(service.Labels["app.kubernetes.io/component"]).To(Equal("server"))
+	Expect(service.Labels[controllers.RolloutManagerLabel]).To(Equal(rolloutsManager.Name))
 	expectMetadataOnObjectMeta(&service.ObjectMeta, rolloutsManager.Spec.AdditionalMetadata)
 
 	By("Verify that ClusterRoleBinding has correct Ports.")
@@ -445,6 +451,7 @@ func validateLabels(object *metav1.ObjectMeta) {
 	Expect(object.Labels["app.kubernetes.io/name"]).To(Equal(controllers.DefaultArgoRolloutsResourceName))
 	Expect(object.Labels["app.kubernetes.io/part-of"]).To(Equal(controllers.DefaultArgoRolloutsResourceName))
 	Expect(object.Labels["app.kubernetes.io/component"]).To(Equal(controllers.DefaultArgoRolloutsResourceName))
+	Expect(object.Labels[controllers.ManagedByLabel]).To(Equal(controllers.ManagedByLabelValue))
 }
 
 func validateAggregateLabels(object *metav1.ObjectMeta, aggregationType string) {
@@ -0,0 +1,313 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	utils "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e"
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture"
+	"github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/k8s"
+	rmFixture "github.com/argoproj-labs/argo-rollouts-manager/tests/e2e/fixture/rolloutmanager"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	rmv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+
+	controllers "github.com/argoproj-labs/argo-rollouts-manager/controllers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// The following environment variables are used to configure the upgrade test, and are set by hack/run-upgrade-e2e-tests.sh.
+
+	// upgradeCommandEnvName is the shell command that upgrades the operator on the cluster to the current version (e.g. 'make deploy IMG=...'). It is run from the root of the repository.
+	upgradeCommandEnvName = "E2E_UPGRADE_COMMAND"
+
+	// upgradeOperatorNamespaceEnvName is the namespace of the operator Deployment
+	upgradeOperatorNamespaceEnvName = "E2E_UPGRADE_OPERATOR_NAMESPACE"
+
+	// upgradeOperatorDeploymentEnvName is the name of the operator Deployment
+	upgradeOperatorDeploymentEnvName = "E2E_UPGRADE_OPERATOR_DEPLOYMENT"
+
+	// the operator namespace and Deployment name, when installed via 'make deploy'
+	defaultOperatorNamespace  = "argo-rollouts-manager-system"
+	defaultOperatorDeployment = "argo-rollouts-manager-controller-manager"
+
+	// repositoryRoot is the root of the repository, relative to this package
+	repositoryRoot = "../../.."
+
+	rolloutManagersCRDName = "rolloutmanagers.argoproj.io"
+)
+
+func getEnvOrDefault(name string, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// availabilityMonitor polls the Rollouts controller Deployments in the background, and records each time that one of them has no available replica.
+type availabilityMonitor struct {
+	mutex    sync.Mutex
+	failures []string
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func startAvailabilityMonitor(ctx context.Context, k8sClient client.Client, namespaces []string) *availabilityMonitor {
+	monitor := &availabilityMonitor{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer GinkgoRecover()
+		defer close(monitor.done)
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			for _, namespace := range namespaces {
+				var deployment appsv1.Deployment
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: namespace}, &deployment); err != nil {
+					monitor.recordFailure(fmt.Sprintf("%s: unable to get Rollouts Deployment in namespace %s: %v", time.Now().Format(time.RFC3339), namespace, err))
+					continue
+				}
+				if deployment.Status.AvailableReplicas < 1 {
+					monitor.recordFailure(fmt.Sprintf("%s: Rollouts Deployment in namespace %s has no available replica", time.Now().Format(time.RFC3339), namespace))
+				}
+			}
+
+			select {
+			case <-monitor.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return monitor
+}
+
+func (monitor *availabilityMonitor) recordFailure(failure string) {
+	GinkgoWriter.Println(failure)
+
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	monitor.failures = append(monitor.failures, failure)
+}
+
+// Stop stops the monitor, and returns the failures that were recorded.
+func (monitor *availabilityMonitor) Stop() []string {
+	close(monitor.stop)
+	<-monitor.done
+
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	return monitor.failures
+}
+
+// getSchemaProperties returns the names of the properties of the given field (e.g. 'spec') in the schema of the v1alpha1 version of the CRD.
+func getSchemaProperties(crd crdv1.CustomResourceDefinition, field string) []string {
+	var res []string
+	for _, version := range crd.Spec.Versions {
+		if version.Name != rmv1alpha1.GroupVersion.Version || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		for name := range version.Schema.OpenAPIV3Schema.Properties[field].Properties {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// childResourceName returns the name of the child resource of the given type, created by the RolloutManager.
+func childResourceName(obj client.Object) string {
+	switch obj.(type) {
+	case *corev1.Secret:
+		return controllers.DefaultRolloutsNotificationSecretName
+	case *corev1.Service:
+		return controllers.DefaultArgoRolloutsMetricsServiceName
+	default:
+		return controllers.DefaultArgoRolloutsResourceName
+	}
+}
+
+// The upgrade tests reinstall the operator, so they cannot run in parallel with other tests.
+var _ = Describe("RolloutManager upgrade tests", Serial, func() {
+
+	var (
+		err       error
+		ctx       context.Context
+		k8sClient client.Client
+	)
+
+	BeforeEach(func() {
+		if os.Getenv(upgradeCommandEnvName) == "" {
+			Skip(upgradeCommandEnvName + " is not set: the upgrade tests should be run via hack/run-upgrade-e2e-tests.sh")
+		}
+
+		Expect(fixture.EnsureCleanSlate()).To(Succeed())
+
+		k8sClient, _, err = fixture.GetE2ETestKubeClient()
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx = context.Background()
+	})
+
+	/*
+		In this test, the previous version of the operator is expected to already be installed on the cluster (via its manifests).
+		RolloutManagers are created and reconciled by the previous version, then the current version of the operator is installed.
+		The test then verifies that:
+		- the Rollouts controller remains available for the whole duration of the upgrade
+		- the child resources are migrated (updated in place, rather than recreated) by the current version
+		- the RolloutManager CRD is migrated to the current schema, and existing RolloutManagers can use the new fields
+	*/
+	It("should migrate the child resources of existing RolloutManagers, and the CRD schema, without Rollouts controller downtime", func() {
+
+		operatorNamespace := getEnvOrDefault(upgradeOperatorNamespaceEnvName, defaultOperatorNamespace)
+		operatorDeploymentName := getEnvOrDefault(upgradeOperatorDeploymentEnvName, defaultOperatorDeployment)
+
+		namespaces := []string{"upgrade-ns-1", "upgrade-ns-2"}
+
+		By("creating namespace-scoped RolloutManagers, which are reconciled by the previous version of the operator")
+		var rolloutManagers []rmv1alpha1.RolloutManager
+		for _, nsName := range namespaces {
+			Expect(utils.CreateNamespace(ctx, k8sClient, nsName)).To(Succeed())
+
+			rolloutManager, err := utils.CreateRolloutManager(ctx, k8sClient, "upgrade-rollouts-manager", nsName, true)
+			Expect(err).ToNot(HaveOccurred())
+			rolloutManagers = append(rolloutManagers, rolloutManager)
+		}
+
+		for _, rolloutManager := range rolloutManagers {
+			Eventually(rolloutManager, "3m", "1s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseAvailable))
+		}
+
+		By("waiting for the Rollouts controller of each RolloutManager to be available")
+		for _, nsName := range namespaces {
+			Eventually(func() int32 {
+				var deployment appsv1.Deployment
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: nsName}, &deployment); err != nil {
+					return 0
+				}
+				return deployment.Status.AvailableReplicas
+			}, "3m", "1s").Should(BeNumerically(">=", 1))
+		}
+
+		By("recording the UIDs of the child resources created by the previous version")
+		childResources := func() []client.Object {
+			return []client.Object{
+				&appsv1.Deployment{},
+				&corev1.ServiceAccount{},
+				&corev1.Service{},
+				&corev1.Secret{},
+				&rbacv1.Role{},
+				&rbacv1.RoleBinding{},
+			}
+		}
+		childResourceUIDs := map[string]string{}
+		for _, nsName := range namespaces {
+			for _, obj := range childResources() {
+				name := childResourceName(obj)
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: nsName}, obj)).To(Succeed())
+				childResourceUIDs[fmt.Sprintf("%T/%s/%s", obj, nsName, name)] = string(obj.GetUID())
+			}
+		}
+
+		By("upgrading the operator to the current version, while monitoring the availability of the Rollouts controllers")
+		monitor := startAvailabilityMonitor(ctx, k8sClient, namespaces)
+
+		cmd := exec.Command("bash", "-c", os.Getenv(upgradeCommandEnvName))
+		cmd.Dir, err = filepath.Abs(repositoryRoot)
+		Expect(err).ToNot(HaveOccurred())
+		cmd.Stdout = GinkgoWriter
+		cmd.Stderr = GinkgoWriter
+		Expect(cmd.Run()).To(Succeed())
+
+		By("waiting for the upgraded operator Deployment to be rolled out")
+		Eventually(func() bool {
+			var deployment appsv1.Deployment
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: operatorDeploymentName, Namespace: operatorNamespace}, &deployment); err != nil {
+				GinkgoWriter.Println("unable to get operator Deployment:", err)
+				return false
+			}
+			replicas := int32(1)
+			if deployment.Spec.Replicas != nil {
+				replicas = *deployment.Spec.Replicas
+			}
+			return deployment.Status.ObservedGeneration == deployment.Generation &&
+				deployment.Status.UpdatedReplicas == replicas &&
+				deployment.Status.AvailableReplicas == replicas &&
+				deployment.Status.Replicas == replicas
+		}, "5m", "2s").Should(BeTrue())
+
+		By("verifying that the RolloutManagers are reconciled successfully by the current version")
+		for _, rolloutManager := range rolloutManagers {
+			Eventually(rolloutManager, "3m", "1s").Should(rmFixture.HavePhase(rmv1alpha1.PhaseAvailable))
+			Eventually(rolloutManager, "1m", "1s").Should(rmFixture.HaveSuccessCondition())
+
+			utils.ValidateArgoRolloutManagerResources(ctx, rolloutManager, k8sClient, true)
+		}
+
+		By("verifying that the Rollouts controllers stayed available during the upgrade")
+		// Keep monitoring for a short time after the upgrade, as the current version may roll out a new version of the Rollouts Deployment
+		time.Sleep(30 * time.Second)
+		Expect(monitor.Stop()).To(BeEmpty(), "Rollouts controller should not have any downtime during the upgrade")
+
+		By("verifying that the child resources were updated in place, rather than recreated")
+		for _, nsName := range namespaces {
+			for _, obj := range childResources() {
+				name := childResourceName(obj)
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: nsName}, obj)).To(Succeed())
+				key := fmt.Sprintf("%T/%s/%s", obj, nsName, name)
+				Expect(string(obj.GetUID())).To(Equal(childResourceUIDs[key]), key+" should not be recreated")
+			}
+		}
+
+		By("verifying that the RolloutManager CRD on the cluster has been migrated to the current schema")
+		expectedCRDBytes, err := os.ReadFile(filepath.Join(repositoryRoot, "config", "crd", "bases", "argoproj.io_rolloutmanagers.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		var expectedCRD crdv1.CustomResourceDefinition
+		Expect(yaml.Unmarshal(expectedCRDBytes, &expectedCRD)).To(Succeed())
+
+		var liveCRD crdv1.CustomResourceDefinition
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: rolloutManagersCRDName}, &liveCRD)).To(Succeed())
+
+		for _, field := range []string{"spec", "status"} {
+			expectedProperties := getSchemaProperties(expectedCRD, field)
+			Expect(expectedProperties).ToNot(BeEmpty())
+			Expect(getSchemaProperties(liveCRD, field)).To(ContainElements(expectedProperties), "CRD ."+field+" schema should contain the fields of the current version")
+		}
+		Expect(liveCRD.Status.StoredVersions).To(ConsistOf(rmv1alpha1.GroupVersion.Version))
+
+		By("verifying that existing RolloutManagers can be updated with fields introduced by the current version")
+		rolloutManager := rolloutManagers[0]
+		Expect(k8s.UpdateWithoutConflict(ctx, &rolloutManager, k8sClient, func(obj client.Object) {
+			rm, ok := obj.(*rmv1alpha1.RolloutManager)
+			Expect(ok).To(BeTrue())
+			rm.Spec.RolloutUserRole = &rmv1alpha1.RolloutManagerRolloutUserRoleSpec{Enabled: true}
+		})).To(Succeed())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutManager), &rolloutManager)).To(Succeed())
+		Expect(rolloutManager.Spec.RolloutUserRole).ToNot(BeNil(), "new field should not be pruned by the CRD schema")
+		Expect(rolloutManager.Spec.RolloutUserRole.Enabled).To(BeTrue())
+
+		Eventually(&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultRolloutUserRoleName, Namespace: rolloutManager.Namespace}}, "1m", "1s").Should(k8s.ExistByName(k8sClient))
+	})
+})
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"context"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// faultInjectingClient fails the writes of the given verbs and kinds with the injected errors, so that the error paths of the reconcilers can be exercised against a real API server. The other requests are passed to the wrapped client.
+type faultInjectingClient struct {
+	client.Client
+
+	mutex  sync.Mutex
+	faults map[fault]error
+}
+
+// fault identifies the writes which fail: for example, {verb: "create", kind: "Deployment"}.
+type fault struct {
+	verb string
+	kind string
+}
+
+func newFaultInjectingClient(c client.Client) *faultInjectingClient {
+	return &faultInjectingClient{Client: c, faults: map[fault]error{}}
+}
+
+// Inject fails the writes of the given verb and kind with err, until the faults are cleared.
+func (c *faultInjectingClient) Inject(verb string, kind string, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.faults[fault{verb: verb, kind: kind}] = err
+}
+
+// Clear removes all of the injected faults.
+func (c *faultInjectingClient) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.faults = map[fault]error{}
+}
+
+func (c *faultInjectingClient) injectedFault(verb string, obj client.Object) error {
+
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.faults[fault{verb: verb, kind: gvk.Kind}]
+}
+
+func (c *faultInjectingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.injectedFault("create", obj); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *faultInjectingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.injectedFault("update", obj); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *faultInjectingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.injectedFault("patch", obj); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *faultInjectingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.injectedFault("delete", obj); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
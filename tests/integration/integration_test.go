@@ -0,0 +1,234 @@
+package integration
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rmv1alpha1 "github.com/argoproj-labs/argo-rollouts-manager/api/v1alpha1"
+	controllers "github.com/argoproj-labs/argo-rollouts-manager/controllers"
+)
+
+var _ = Describe("RolloutManager controller integration tests", func() {
+
+	var (
+		namespace      string
+		rolloutManager rmv1alpha1.RolloutManager
+	)
+
+	// managedResources returns the namespaced resources which are created by each of the sub-reconcilers, for the RolloutManager.
+	managedResources := func() []client.Object {
+		objectMeta := func(name string) metav1.ObjectMeta {
+			return metav1.ObjectMeta{Name: name, Namespace: namespace}
+		}
+		return []client.Object{
+			&corev1.ServiceAccount{ObjectMeta: objectMeta(controllers.DefaultArgoRolloutsResourceName)},
+			&rbacv1.Role{ObjectMeta: objectMeta(controllers.DefaultArgoRolloutsResourceName)},
+			&rbacv1.RoleBinding{ObjectMeta: objectMeta(controllers.DefaultArgoRolloutsResourceName)},
+			&corev1.ConfigMap{ObjectMeta: objectMeta(controllers.DefaultRolloutsConfigMapName)},
+			&corev1.Secret{ObjectMeta: objectMeta(controllers.DefaultRolloutsNotificationSecretName)},
+			&corev1.Service{ObjectMeta: objectMeta(controllers.DefaultArgoRolloutsMetricsServiceName)},
+			&appsv1.Deployment{ObjectMeta: objectMeta(controllers.DefaultArgoRolloutsResourceName)},
+		}
+	}
+
+	// reconciledCondition returns the Reconciled condition of the RolloutManager, or an empty condition if it is not set.
+	reconciledCondition := func() metav1.Condition {
+		rm := rmv1alpha1.RolloutManager{}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutManager), &rm); err != nil {
+			return metav1.Condition{}
+		}
+		if condition := meta.FindStatusCondition(rm.Status.Conditions, rmv1alpha1.RolloutManagerConditionType); condition != nil {
+			return *condition
+		}
+		return metav1.Condition{}
+	}
+
+	haveReconciledStatus := func(status metav1.ConditionStatus, messageSubstring string) OmegaMatcher {
+		return SatisfyAll(
+			WithTransform(func(condition metav1.Condition) metav1.ConditionStatus { return condition.Status }, Equal(status)),
+			WithTransform(func(condition metav1.Condition) string { return condition.Message }, ContainSubstring(messageSubstring)),
+		)
+	}
+
+	BeforeEach(func() {
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "integration-"}}
+		Expect(k8sClient.Create(ctx, &ns)).To(Succeed())
+		namespace = ns.Name
+
+		rolloutManager = rmv1alpha1.RolloutManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "integration-rollouts-manager", Namespace: namespace},
+			Spec:       rmv1alpha1.RolloutManagerSpec{NamespaceScoped: true},
+		}
+
+		DeferCleanup(func() {
+			faults.Clear()
+			// envtest does not run the namespace controller, so the namespace is never fully deleted: its RolloutManager is deleted first, so that it is no longer reconciled
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &rolloutManager))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &ns)).To(Succeed())
+		})
+	})
+
+	When("a RolloutManager is created", func() {
+
+		It("should create the resources of each sub-reconciler, owned by the RolloutManager, and report success", func() {
+			Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+
+			Eventually(reconciledCondition, "30s", "250ms").Should(haveReconciledStatus(metav1.ConditionTrue, ""))
+
+			for _, obj := range managedResources() {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed(), client.ObjectKeyFromObject(obj).String())
+
+				ownerRef := metav1.GetControllerOf(obj)
+				Expect(ownerRef).ToNot(BeNil(), client.ObjectKeyFromObject(obj).String())
+				Expect(ownerRef.UID).To(Equal(rolloutManager.UID))
+			}
+
+			for _, suffix := range []string{"aggregate-to-admin", "aggregate-to-edit", "aggregate-to-view"} {
+				clusterRole := rbacv1.ClusterRole{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: controllers.DefaultArgoRolloutsResourceName + "-" + suffix}, &clusterRole)).To(Succeed())
+			}
+		})
+	})
+
+	When("the resources of a RolloutManager drift", func() {
+
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+			Eventually(reconciledCondition, "30s", "250ms").Should(haveReconciledStatus(metav1.ConditionTrue, ""))
+		})
+
+		It("should revert the changes to the Deployment", func() {
+			deployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: namespace}}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&deployment), &deployment)).To(Succeed())
+			expectedContainers := deployment.Spec.Template.Spec.Containers
+
+			deployment.Spec.Template.Spec.Containers[0].Image = "quay.io/prometheus/busybox:latest"
+			deployment.Spec.Template.Spec.Containers[0].Args = append(deployment.Spec.Template.Spec.Containers[0].Args, "--loglevel", "debug")
+			Expect(k8sClient.Update(ctx, &deployment)).To(Succeed())
+
+			Eventually(func() []corev1.Container {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&deployment), &deployment)).To(Succeed())
+				return deployment.Spec.Template.Spec.Containers
+			}, "30s", "250ms").Should(Equal(expectedContainers))
+		})
+
+		It("should revert the changes to the Role", func() {
+			role := rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: namespace}}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&role), &role)).To(Succeed())
+			expectedRules := role.Rules
+
+			role.Rules = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}
+			Expect(k8sClient.Update(ctx, &role)).To(Succeed())
+
+			Eventually(func() []rbacv1.PolicyRule {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&role), &role)).To(Succeed())
+				return role.Rules
+			}, "30s", "250ms").Should(Equal(expectedRules))
+		})
+
+		It("should revert the changes to the metrics Service", func() {
+			service := corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsMetricsServiceName, Namespace: namespace}}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&service), &service)).To(Succeed())
+			expectedPorts := service.Spec.Ports
+
+			service.Spec.Ports[0].TargetPort = intstr.FromInt(9999)
+			Expect(k8sClient.Update(ctx, &service)).To(Succeed())
+
+			Eventually(func() []corev1.ServicePort {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&service), &service)).To(Succeed())
+				return service.Spec.Ports
+			}, "30s", "250ms").Should(Equal(expectedPorts))
+		})
+
+		It("should recreate the resources which are deleted", func() {
+			for _, obj := range managedResources() {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+				originalUID := obj.GetUID()
+
+				Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+
+				Eventually(func() types.UID {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+						return originalUID
+					}
+					return obj.GetUID()
+				}, "30s", "250ms").ShouldNot(Equal(originalUID), client.ObjectKeyFromObject(obj).String())
+			}
+		})
+	})
+
+	When("the writes of a sub-reconciler fail", func() {
+
+		It("should report the failure in the Reconciled condition, and recover once the writes succeed", func() {
+			faults.Inject("create", "Deployment", errors.New("injected failure to create the Deployment"))
+
+			Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+
+			Eventually(reconciledCondition, "30s", "250ms").Should(haveReconciledStatus(metav1.ConditionFalse, "injected failure to create the Deployment"))
+
+			By("verifying that the resources of the sub-reconcilers which ran before the failure were created")
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: namespace}, &corev1.ServiceAccount{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: namespace}, &appsv1.Deployment{})).ToNot(Succeed())
+
+			By("clearing the failure, and verifying that the reconciliation is retried")
+			faults.Clear()
+
+			Eventually(reconciledCondition, "60s", "250ms").Should(haveReconciledStatus(metav1.ConditionTrue, ""))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: namespace}, &appsv1.Deployment{})).To(Succeed())
+		})
+
+		It("should report a failure to correct drift, and correct it once the writes succeed", func() {
+			Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+			Eventually(reconciledCondition, "30s", "250ms").Should(haveReconciledStatus(metav1.ConditionTrue, ""))
+
+			faults.Inject("update", "Role", errors.New("injected failure to update the Role"))
+
+			role := rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: namespace}}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&role), &role)).To(Succeed())
+			expectedRules := role.Rules
+			role.Rules = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}
+			Expect(k8sClient.Update(ctx, &role)).To(Succeed())
+
+			Eventually(reconciledCondition, "30s", "250ms").Should(haveReconciledStatus(metav1.ConditionFalse, "injected failure to update the Role"))
+
+			faults.Clear()
+
+			Eventually(reconciledCondition, "60s", "250ms").Should(haveReconciledStatus(metav1.ConditionTrue, ""))
+			Eventually(func() []rbacv1.PolicyRule {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&role), &role)).To(Succeed())
+				return role.Rules
+			}, "30s", "250ms").Should(Equal(expectedRules))
+		})
+	})
+
+	When("a RolloutManager is deleted", func() {
+
+		It("should no longer reconcile its resources", func() {
+			Expect(k8sClient.Create(ctx, &rolloutManager)).To(Succeed())
+			Eventually(reconciledCondition, "30s", "250ms").Should(haveReconciledStatus(metav1.ConditionTrue, ""))
+
+			Expect(k8sClient.Delete(ctx, &rolloutManager)).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKeyFromObject(&rolloutManager), &rmv1alpha1.RolloutManager{})
+			}, "30s", "250ms").ShouldNot(Succeed())
+
+			// envtest does not run the garbage collector, so the owned resources remain: they are no longer reverted to the state of the RolloutManager
+			serviceAccount := corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: controllers.DefaultArgoRolloutsResourceName, Namespace: namespace}}
+			Expect(k8sClient.Delete(ctx, &serviceAccount)).To(Succeed())
+			Consistently(func() error {
+				return k8sClient.Get(ctx, client.ObjectKeyFromObject(&serviceAccount), &serviceAccount)
+			}, "5s", "250ms").ShouldNot(Succeed())
+		})
+	})
+})